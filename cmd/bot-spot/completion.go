@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"main/internal/config"
+	commands "main/internal/services/trading"
+)
+
+// completionCmd affiche le script de complétion d'un shell (completion bash|zsh|powershell
+// [--name=PROGRAMME]). Les scripts délèguent la recherche des propositions au programme lui-même
+// (completion complete "LIGNE"), si bien qu'elles suivent toujours le registre des commandes, les
+// alias de bot.conf et les cycles en cours.
+func completionCmd(string) {
+	args := commands.GetAllArgs()
+	program := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	var shell string
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--name="); ok {
+			program = value
+		}
+		if arg == "--completion" && i+1 < len(args) {
+			shell = args[i+1]
+		}
+	}
+
+	switch shell {
+	case "bash":
+		fmt.Print(strings.ReplaceAll(bashCompletion, "PROGRAM", program))
+	case "zsh":
+		fmt.Print(strings.ReplaceAll(zshCompletion, "PROGRAM", program))
+	case "powershell":
+		fmt.Print(strings.ReplaceAll(powershellCompletion, "PROGRAM", program))
+	case "complete":
+		line := ""
+		for i, arg := range args {
+			if arg == "complete" && i+1 < len(args) {
+				line = args[i+1]
+			}
+		}
+		for _, candidate := range completeLine(line, config.AliasSettings()) {
+			fmt.Println(candidate)
+		}
+	default:
+		fmt.Println("Usage: completion bash|zsh|powershell [--name=PROGRAMME]")
+		fmt.Println("  bash:       source <(bot-spot completion bash)")
+		fmt.Println("  zsh:        source <(bot-spot completion zsh)")
+		fmt.Println("  powershell: bot-spot completion powershell | Out-String | Invoke-Expression")
+		os.Exit(1)
+	}
+}
+
+// completeLine retourne les propositions pour le dernier mot de la ligne saisie (nom du
+// programme compris). Le dernier mot est vide si la ligne se termine par un espace.
+func completeLine(line string, aliases map[string]string) []string {
+	words := strings.Fields(line)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(words) == 0 && strings.Contains(current, "="):
+		// --cancel=<TAB>: valeurs de la commande
+		name, _, _ := strings.Cut(current, "=")
+		if command := findCommandArg(name); command != nil {
+			for _, value := range commandValues(command) {
+				candidates = append(candidates, name+"="+value)
+			}
+		}
+
+	case len(words) == 0:
+		candidates = commandNames(aliases)
+
+	default:
+		first := words[0]
+		if definition, ok := aliases[strings.ToLower(first)]; ok && findCommandWord(first) == nil {
+			if fields := strings.Fields(definition); len(fields) > 0 {
+				first = fields[0]
+			}
+		}
+		command := findCommandArg(first)
+		if command == nil {
+			return nil
+		}
+
+		previous := words[len(words)-1]
+		switch {
+		case previous == "--exchange":
+			candidates = exchangeNames
+		case len(words) == 1 && command.value != "" && !strings.HasPrefix(first, "-"):
+			// cancel <TAB>: la valeur suit le nom de la commande
+			candidates = append(commandValues(command), commandFlags(command)...)
+		default:
+			candidates = commandFlags(command)
+		}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// commandValues retourne les valeurs proposées après "=" pour une commande
+func commandValues(command *cliCommand) []string {
+	switch command.value {
+	case "cycle":
+		return completionCycleIDs()
+	case "exchange":
+		return exchangeNames
+	}
+	return nil
+}
+
+// bashCompletion découpe les mots au signe "=": les propositions "--cancel=42" sont ramenées à
+// la partie qui suit le signe
+const bashCompletion = `# Complétion bash de PROGRAM: source <(PROGRAM completion bash)
+_PROGRAM_completion() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local candidates=($(PROGRAM completion complete "$line" 2>/dev/null))
+    if [[ "${line##* }" == *=* ]]; then
+        candidates=("${candidates[@]#*=}")
+        if [[ "$cur" == "=" ]]; then
+            candidates=("${candidates[@]/#/=}")
+        fi
+    fi
+    COMPREPLY=("${candidates[@]}")
+}
+complete -F _PROGRAM_completion PROGRAM
+`
+
+const zshCompletion = `#compdef PROGRAM
+# Complétion zsh de PROGRAM: source <(PROGRAM completion zsh)
+_PROGRAM_completion() {
+    local -a candidates
+    candidates=("${(@f)$(PROGRAM completion complete "${BUFFER[1,CURSOR]}" 2>/dev/null)}")
+    compadd -Q -- "${candidates[@]}"
+}
+compdef _PROGRAM_completion PROGRAM
+`
+
+const powershellCompletion = `# Complétion PowerShell de PROGRAM: PROGRAM completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName 'PROGRAM', 'PROGRAM.exe' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $line = $commandAst.ToString()
+    $length = [Math]::Min($cursorPosition - $commandAst.Extent.StartOffset, $line.Length)
+    $line = $line.Substring(0, $length)
+    if ($wordToComplete -eq '') { $line += ' ' }
+    & 'PROGRAM' completion complete "$line" 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"main/internal/config"
 	"main/internal/database"
+	"main/internal/i18n"
 	commands "main/internal/services/trading"
 )
 
@@ -14,50 +16,135 @@ func menu() {
 	fmt.Println("")
 	fmt.Println("Cryptomancien - Neodream - BOT SPOT - v5.0.0 - alpha")
 	fmt.Println("")
-	fmt.Println("--new            -n      Start new cycle")
-	fmt.Println("--update         -u      Update running cycles")
-	fmt.Println("--server         -s      Start local server")
-	fmt.Println("--server         -s -complete      Start server with completed cycles only")
-	fmt.Println("--stats          -st     Start statistics server (visualization and comparison)")
-	fmt.Println("--cancel         -c      Cancel cycle by id - Example: -c=123")
-	fmt.Println("--plan                   Configure and manage scheduled tasks for WINDOWS")
-	fmt.Println("--plan           -plan start   Start the scheduler daemon")
-	fmt.Println("--plan           -plan stop    Stop the scheduler daemon")
-	fmt.Println("--plan           -plan status  Check scheduler status")
-	fmt.Println("--remove-task    -plan -rt     Supprimer une tâche planifiée")
-	fmt.Println("--remove-all     -plan -ra     Supprimer toutes les tâches planifiées")
+	menuLine("--new            -n", "menu.new")
+	menuLine("--update         -u", "menu.update")
+	menuLine("--simulate-update", "menu.simulate_update")
+	menuLine("--server         -s", "menu.server")
+	menuLine("--server         -s -complete", "menu.server_complete")
+	menuLine("--server         -s -readonly", "menu.server_readonly")
+	menuLine("--stats          -st", "menu.stats")
+	menuLine("--cancel         -c", "menu.cancel")
+	menuLine("--cancel-stale --max-age-days=N", "menu.cancel_stale")
+	menuLine("--set-sell-price", "menu.set_sell_price")
+	menuLine("--average-down", "menu.average_down")
+	menuLine("--close-now", "menu.close_now")
+	menuLine("--pause=ID", "menu.pause")
+	menuLine("--resume=ID", "menu.resume")
+	menuLine("--import", "menu.import")
+	menuLine("--archive", "menu.archive")
+	menuLine("--tax-report", "menu.tax_report")
+	menuLine("--report", "menu.report")
+	menuLine("--snapshot", "menu.snapshot")
+	menuLine("--webhook-test", "menu.webhook_test")
+	menuLine("--push-keys", "menu.push_keys")
+	menuLine("--check-order-ids", "menu.check_order_ids")
+	menuLine("--dedupe", "menu.dedupe")
+	menuLine("--orphans", "menu.orphans")
+	menuLine("--check", "menu.check")
+	menuLine("--status", "menu.status")
+	menuLine("--watch", "menu.watch")
+	menuLine("--override-loss-limit", "menu.override_loss_limit")
+	menuLine("--setup", "menu.setup")
+	menuLine("--set-secret EXCHANGE", "menu.set_secret")
+	menuLine("--balance", "menu.balance")
+	menuLine("--time-check", "menu.time_check")
+	menuLine("--validate-config", "menu.validate_config")
+	menuLine("--fsck", "menu.fsck")
+	menuLine("--plan", "menu.plan")
+	menuLine("--plan           -plan start", "menu.plan_start")
+	menuLine("--plan           -plan stop", "menu.plan_stop")
+	menuLine("--plan           -plan status", "menu.plan_status")
+	menuLine("--remove-task    -plan -rt", "menu.remove_task")
+	menuLine("--remove-all     -plan -ra", "menu.remove_all")
+	menuLine("--plan           -plan export FICHIER.yaml", "menu.plan_export")
+	menuLine("--plan           -plan import FICHIER.yaml", "menu.plan_import")
+	menuLine("completion bash|zsh|powershell", "menu.completion")
 	fmt.Println("")
-	fmt.Println("Options additionnelles:")
-	fmt.Println("-exchangebinance        Utiliser Binance pour cette commande")
-	fmt.Println("-exchangemexc           Utiliser MEXC pour cette commande")
-	fmt.Println("-exchangekucoin         Utiliser KuCoin pour cette commande")
-	fmt.Println("-exchangeokx            Utiliser OKX pour cette commande")
-	fmt.Println("-exchangekraken         Utiliser Kraken pour cette commande")
+	fmt.Println(i18n.T("menu.options"))
+	menuLine("-exchangebinance", "menu.opt_binance")
+	menuLine("-exchangemexc", "menu.opt_mexc")
+	menuLine("-exchangekucoin", "menu.opt_kucoin")
+	menuLine("-exchangeokx", "menu.opt_okx")
+	menuLine("-exchangekraken", "menu.opt_kraken")
+	menuLine("--max", "menu.opt_max")
+	menuLine("--ladder=N --ladder-step=P", "menu.opt_ladder")
+	menuLine("--pair=BTC/USDC", "menu.opt_pair")
+	menuLine("--addr=ADRESSE", "menu.opt_addr")
+	menuLine("--port=PORT", "menu.opt_port")
+	menuLine("--lang=fr|en", "menu.opt_lang")
+	menuLine("--config=DOSSIER", "menu.opt_config")
+	menuLine("--profile=NOM", "menu.opt_profile")
+	menuLine("--latency-report", "menu.opt_latency_report")
 	fmt.Println("")
-	fmt.Println("Exemples:")
-	fmt.Println("-n -exchangemexc        Démarrer un nouveau cycle sur MEXC")
-	fmt.Println("-u -exchangebinance     Mettre à jour les cycles sur Binance")
-	fmt.Println("-n -exchangekucoin      Démarrer un nouveau cycle sur KuCoin")
-	fmt.Println("-n -exchangeokx         Démarrer un nouveau cycle sur OKX")
-	fmt.Println("-n -exchangekraken      Démarrer un nouveau cycle sur Kraken")
-	fmt.Println("-plan                   Configurer le planificateur de tâches")
+	fmt.Println(i18n.T("menu.examples"))
+	menuLine("-n -exchangemexc", "menu.ex_new_mexc")
+	menuLine("-u -exchangebinance", "menu.ex_update_binance")
+	menuLine("-n -exchangekucoin", "menu.ex_new_kucoin")
+	menuLine("-n -exchangeokx", "menu.ex_new_okx")
+	menuLine("-n -exchangekraken", "menu.ex_new_kraken")
+	menuLine("-c=group:42", "menu.ex_cancel_group")
+	menuLine("--cancel-stale --max-age-days=5 -exchangekucoin", "menu.ex_cancel_stale")
+	menuLine("-s --addr=0.0.0.0 --port=9000", "menu.ex_server_lan")
+	menuLine("--import --exchange=binance --since=2024-01-01 --dry-run", "menu.ex_import")
+	menuLine("--archive --before=2023-01-01 --dry-run", "menu.ex_archive")
+	menuLine("--tax-report --year=2024 --output=2086.csv", "menu.ex_tax_report")
+	menuLine("--report --period=7j --output=rapport.md --notify", "menu.ex_report")
+	menuLine("--balance --json", "menu.ex_balance_json")
+	menuLine("--status --json", "menu.ex_status_json")
+	menuLine("--watch --interval=60", "menu.ex_watch")
+	menuLine("--simulate-update --json", "menu.ex_simulate_update_json")
+	menuLine("-plan", "menu.ex_plan")
+	menuLine("--lang=en -u", "menu.ex_lang")
+	menuLine("--profile=agressif -plan start", "menu.ex_profile")
+	menuLine("-u --latency-report", "menu.ex_latency_report")
+	menuLine("new --exchange binance", "menu.ex_new_style")
+	menuLine("source <(bot-spot completion bash)", "menu.ex_completion")
 	fmt.Println("")
 }
 
+// menuLine affiche une option du menu suivie de sa description dans la langue choisie
+func menuLine(option, key string) {
+	fmt.Printf("%-24s %s\n", option, i18n.T(key))
+}
+
 func initialize() {
 	// Charger la configuration
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialiser la base de données
+	// Initialiser la base de données, sauvegardée à l'ouverture
+	database.SetBackupCount(cfg.DatabaseBackupCount)
 	database.InitDatabase()
 
 	// Passer la configuration aux commandes
 	commands.SetConfig(cfg)
 }
 
+// checkSetSecretCommand exécute --set-secret avant le chargement de la configuration,
+// dont les clés peuvent justement être absentes
+func checkSetSecretCommand() bool {
+	args := commands.GetAllArgs()
+	for i, arg := range args {
+		if arg != "--set-secret" && !strings.HasPrefix(arg, "--set-secret=") {
+			continue
+		}
+
+		// --set-secret=binance, --set-secret binance ou --set-secret -exchangebinance
+		_, exchange, _ := strings.Cut(arg, "=")
+		if exchange == "" && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			exchange = args[i+1]
+		}
+		if exchange == "" {
+			exchange = extractExchangeFromArgs()
+		}
+		commands.SetSecret(exchange)
+		return true
+	}
+	return false
+}
+
 func extractExchangeFromArgs() string {
 	// Patterns pour reconnaître les exchanges en arguments
 	exchangePatterns := map[string]string{
@@ -69,6 +156,11 @@ func extractExchangeFromArgs() string {
 
 	// Parcourir tous les arguments
 	for _, arg := range commands.GetAllArgs() {
+		// --exchange=binance
+		if value, ok := strings.CutPrefix(arg, "--exchange="); ok {
+			return strings.ToUpper(value)
+		}
+
 		// Supprimer les tirets au début
 		cleanArg := strings.TrimLeft(arg, "-")
 
@@ -84,9 +176,54 @@ func extractExchangeFromArgs() string {
 	return ""
 }
 
+// setupLanguage choisit la langue des messages: --lang=xx, sinon LANGUAGE (environnement ou bot.conf)
+func setupLanguage() {
+	lang := config.LanguageSetting()
+	for _, arg := range commands.GetAllArgs() {
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			lang = value
+		}
+	}
+	if err := i18n.SetLanguage(lang); err != nil {
+		log.Printf("Warning: %v, using %s", err, i18n.DefaultLanguage)
+	}
+}
+
+// setupProfile choisit le répertoire de configuration (--config=DIR ou --profile=NOM) avant toute
+// lecture de bot.conf
+func setupProfile() {
+	if err := config.SelectFromArgs(commands.GetAllArgs()); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// printActiveProfile rappelle le profil actif et son répertoire sur la sortie d'erreur, qui reste
+// hors des sorties JSON
+func printActiveProfile() {
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Fprintf(os.Stderr, i18n.T("profile.active")+"\n", profile, config.Dir())
+	}
+}
+
 func main() {
-	// Vérifier d'abord si c'est une commande liée au planificateur
-	if checkPlannerSubCommand() {
+	// Répertoire de configuration du profil, dont bot.conf fournit la langue
+	setupProfile()
+
+	// Les messages sont traduits dès le démarrage, y compris le menu et le planificateur
+	setupLanguage()
+	printActiveProfile()
+
+	// Résoudre les alias de bot.conf et la forme courte "bot-spot new --exchange binance"
+	expandArgs()
+
+	// Latence et erreurs des requêtes aux exchanges, affichées à la fin de l'exécution
+	if commands.LatencyReportRequested() {
+		defer commands.LatencyReport()
+	}
+
+	// Le planificateur, la complétion, la consultation des soldes et de l'horloge et l'enregistrement
+	// des clés API n'ouvrent pas la base de données et ne dépendent pas d'une configuration valide
+	if runCommand(true) {
 		return
 	}
 
@@ -94,63 +231,8 @@ func main() {
 	initialize()
 	defer database.CloseDatabase()
 
-	// Rechercher les commandes dans tous les arguments
-	args := commands.GetAllArgs()
-
-	// Variable pour indiquer si une commande a été trouvée et exécutée
-	commandFound := false
-
-	// Vérifier quelle commande est présente
-	for _, arg := range args {
-		// Vérifier d'abord les formes avec "=" comme "-c=4" ou "--cancel=4"
-		if strings.HasPrefix(arg, "-c=") || strings.HasPrefix(arg, "--cancel=") {
-			// Extraire l'exchange spécifié dans les arguments
-			exchange := extractExchangeFromArgs()
-			// Passer l'argument complet à CancelWithExchange
-			commands.CancelWithExchange(exchange, arg)
-			commandFound = true
-			return
-		}
-
-		// Puis vérifier les commandes régulières
-		switch arg {
-		case "--new", "-n":
-			// Extraire l'exchange spécifié dans les arguments (s'il y en a un)
-			exchange := extractExchangeFromArgs()
-			commands.NewWithExchange(exchange)
-			commandFound = true
-			return
-
-		case "--update", "-u":
-			exchange := extractExchangeFromArgs()
-			commands.UpdateWithExchange(exchange)
-			commandFound = true
-			return
-
-		case "--cancel", "-c":
-			// Cette branche gère le cas où "-c" est un argument séparé
-			// Ce qui est différent de "-c=4"
-			exchange := extractExchangeFromArgs()
-			// Passer l'argument complet à CancelWithExchange
-			commands.CancelWithExchange(exchange, arg)
-			commandFound = true
-			return
-
-		case "--server", "-s":
-			commands.Server()
-			commandFound = true
-			return
-
-		case "--stats", "-st":
-			// Nouvelle commande pour lancer le serveur de statistiques
-			commands.StatsServer()
-			commandFound = true
-			return
-		}
-	}
-
-	// Si aucune commande reconnue n'est trouvée, afficher le menu
-	if !commandFound {
+	// Exécuter la première commande reconnue dans les arguments, sinon afficher le menu
+	if !runCommand(false) {
 		menu()
 	}
 }
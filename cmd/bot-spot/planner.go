@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"main/internal/config"
+	"main/internal/i18n"
 	"main/internal/scheduler"
 	commands "main/internal/services/trading"
 	"main/internal/types" // Import du package types contenant TaskConfig
@@ -24,12 +25,12 @@ import (
 
 // plannerCmd gère la commande de planification interactive
 func plannerCmd() {
-	fmt.Println("=== Configuration du planificateur de tâches ===")
+	fmt.Println(i18n.T("planner.setup_heading"))
 
 	// Initialiser la configuration et le logger
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement de la configuration: %v\n", err)
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
 		return
 	}
 
@@ -44,15 +45,18 @@ func plannerCmd() {
 	// Charger les tâches existantes
 	err = sched.LoadTasksFromConfig()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement des tâches: %v\n", err)
+		fmt.Printf(i18n.T("planner.tasks_load_error"), err)
 	}
 
 	// Afficher les tâches existantes
 	displayExistingTasks(sched)
 
-	// Demander à l'utilisateur s'il veut ajouter une nouvelle tâche
+	// Proposer de modifier les heures calmes
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("\nVoulez-vous configurer une nouvelle tâche planifiée ? (o/n)")
+	editQuietHoursInteractive(sched, reader)
+
+	// Demander à l'utilisateur s'il veut ajouter une nouvelle tâche
+	fmt.Println(i18n.T("planner.ask_new_task"))
 	response, _ := reader.ReadString('\n')
 	response = strings.TrimSpace(strings.ToLower(response))
 
@@ -77,11 +81,11 @@ func plannerCmd() {
 func displayExistingTasks(sched *scheduler.Scheduler) {
 	tasks := sched.GetAllTasks()
 	if len(tasks) == 0 {
-		fmt.Println("\nAucune tâche planifiée n'est configurée actuellement.")
+		fmt.Println(i18n.T("planner.no_tasks_nl"))
 		return
 	}
 
-	fmt.Println("\nTâches planifiées existantes:")
+	fmt.Println(i18n.T("planner.existing_tasks"))
 	for i, task := range tasks {
 		// Formater l'intervalle pour l'affichage
 		intervalStr := ""
@@ -102,12 +106,12 @@ func displayExistingTasks(sched *scheduler.Scheduler) {
 			intervalStr = formatIntervalToString(value, unit)
 		}
 
-		statusStr := "Activée"
+		statusStr := i18n.T("planner.task_enabled")
 		if !task.Enabled {
-			statusStr = "Désactivée"
+			statusStr = i18n.T("planner.task_disabled")
 		}
 
-		fmt.Printf("%d. %s - %s - Intervalle: %s - État: %s\n",
+		fmt.Printf(i18n.T("planner.task_line"),
 			i+1,
 			task.Name,
 			task.Type,
@@ -118,20 +122,34 @@ func displayExistingTasks(sched *scheduler.Scheduler) {
 		switch task.Type {
 		case "update":
 			if task.Exchange != "" {
-				fmt.Printf("   Exchange spécifique: %s\n", task.Exchange)
+				fmt.Printf(i18n.T("planner.task_exchange_specific"), task.Exchange)
+			}
+		case "cancel_stale":
+			if task.Exchange != "" {
+				fmt.Printf(i18n.T("planner.task_exchange_specific"), task.Exchange)
 			}
+			fmt.Printf(i18n.T("planner.task_max_age_days"), task.MaxAgeDays)
 		case "new":
 			if task.Exchange != "" {
 				fmt.Printf("   Exchange: %s", task.Exchange)
 
 				// Afficher les paramètres personnalisés
 				customParams := []string{}
+				if task.Pair != "" {
+					customParams = append(customParams, "Pair: "+task.Pair)
+				}
 				if task.BuyOffset != 0 {
 					customParams = append(customParams, fmt.Sprintf("BuyOffset: %.2f", task.BuyOffset))
 				}
 				if task.SellOffset != 0 {
 					customParams = append(customParams, fmt.Sprintf("SellOffset: %.2f", task.SellOffset))
 				}
+				if task.BuyOffsetPercent != 0 {
+					customParams = append(customParams, fmt.Sprintf("BuyOffsetPercent: %.2f%%", task.BuyOffsetPercent))
+				}
+				if task.SellOffsetPercent != 0 {
+					customParams = append(customParams, fmt.Sprintf("SellOffsetPercent: %.2f%%", task.SellOffsetPercent))
+				}
 				if task.Percent != 0 {
 					customParams = append(customParams, fmt.Sprintf("Percent: %.2f", task.Percent))
 				}
@@ -143,15 +161,106 @@ func displayExistingTasks(sched *scheduler.Scheduler) {
 			}
 
 			if task.SpecificTime != "" {
-				fmt.Printf("   Heure d'exécution: %s\n", task.SpecificTime)
+				fmt.Printf(i18n.T("planner.task_time"), task.SpecificTime)
 			}
 		}
 
+		if task.JitterSeconds > 0 {
+			fmt.Printf(i18n.T("planner.task_jitter"), task.JitterSeconds)
+		}
+
 		if !task.NextScheduledAt.IsZero() && task.NextScheduledAt.After(time.Now()) {
-			fmt.Printf("   Prochaine exécution: %s\n",
-				task.NextScheduledAt.Format("02/01/2006 15:04:05"))
+			fmt.Printf(i18n.T("planner.task_next_run"),
+				task.NextScheduledAt.Format(i18n.DateTimeLayout()+":05"))
 		} else {
-			fmt.Printf("   Prochaine exécution: [À calculer au démarrage]\n")
+			fmt.Print(i18n.T("planner.task_next_run_pending"))
+		}
+	}
+
+	if quiet := sched.QuietHours(); quiet.Enabled() {
+		fmt.Printf(i18n.T("planner.quiet_hours"), quiet)
+	} else {
+		fmt.Print(i18n.T("planner.quiet_hours_none"))
+	}
+
+	displayRecentRuns(recentRunsShown)
+}
+
+// editQuietHoursInteractive propose de modifier la plage des heures calmes et l'enregistre
+func editQuietHoursInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
+	fmt.Print(i18n.T("planner.ask_quiet_hours_change"))
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "o" && response != "oui" && response != "y" && response != "yes" {
+		return
+	}
+
+	fmt.Print(i18n.T("planner.ask_quiet_hours"))
+	value, _ := reader.ReadString('\n')
+	quiet, err := types.ParseQuietHours(value)
+	if err != nil {
+		fmt.Printf(i18n.T("planner.invalid_quiet_hours"), err)
+		return
+	}
+
+	if err := sched.SetQuietHours(quiet); err != nil {
+		fmt.Printf(i18n.T("planner.quiet_hours_save_error"), err)
+		return
+	}
+	if quiet.Enabled() {
+		fmt.Printf(i18n.T("planner.quiet_hours_saved"), quiet)
+	} else {
+		fmt.Println(i18n.T("planner.quiet_hours_disabled"))
+	}
+}
+
+// recentRunsShown est le nombre d'exécutions affichées par -plan et -plan status
+const recentRunsShown = 10
+
+// displayRecentRuns affiche les dernières exécutions enregistrées par le daemon
+func displayRecentRuns(limit int) {
+	runs, err := scheduler.ReadTaskHistory("", limit)
+	if err != nil {
+		fmt.Printf(i18n.T("planner.history_read_error"), err)
+		return
+	}
+
+	fmt.Println(i18n.T("planner.history_heading"))
+	if len(runs) == 0 {
+		fmt.Println(i18n.T("planner.history_empty"))
+		return
+	}
+
+	for _, run := range runs {
+		outcome := i18n.T("planner.run_success")
+		if !run.Success {
+			outcome = i18n.T("planner.run_failed")
+		} else if run.Skipped {
+			outcome = i18n.T("planner.run_skipped")
+		}
+
+		fmt.Printf(i18n.T("planner.history_line"),
+			run.StartedAt.Format(i18n.DateTimeLayout()+":05"),
+			run.Task,
+			outcome,
+			run.Duration.Round(100*time.Millisecond))
+
+		if run.Error != "" {
+			fmt.Printf(i18n.T("planner.history_error"), run.Error)
+		}
+		if len(run.CycleIDs) > 0 {
+			ids := make([]string, 0, len(run.CycleIDs))
+			for _, id := range run.CycleIDs {
+				ids = append(ids, strconv.Itoa(int(id)))
+			}
+			fmt.Printf(i18n.T("planner.history_cycles"), strings.Join(ids, ", "))
+		}
+		if len(run.CancelledIDs) > 0 {
+			ids := make([]string, 0, len(run.CancelledIDs))
+			for _, id := range run.CancelledIDs {
+				ids = append(ids, strconv.Itoa(int(id)))
+			}
+			fmt.Printf(i18n.T("planner.history_cancelled"), strings.Join(ids, ", "))
 		}
 	}
 }
@@ -182,6 +291,17 @@ func checkPlannerSubCommand() bool {
 				case "-ra":
 					removeAllTasksCmd()
 					return true
+				case "export", "import":
+					path := "tasks.yaml"
+					if i+2 < len(args) && !strings.HasPrefix(args[i+2], "-") {
+						path = args[i+2]
+					}
+					if subCommand == "export" {
+						exportTasksCmd(path)
+					} else {
+						importTasksCmd(path)
+					}
+					return true
 				case "daemon":
 					// Cette option est utilisée en interne pour le mode daemon
 					runPlannerDaemon()
@@ -202,9 +322,15 @@ func checkPlannerSubCommand() bool {
 	return false
 }
 
+// Fichiers de suivi du daemon, dans le répertoire de configuration: deux profils ont chacun le leur
+const (
+	plannerPIDFile     = "planner.pid"
+	plannerExeInfoFile = "planner_exe.info" // nom de l'exécutable et PID, pour l'arrêt par nom
+)
+
 // startPlannerDaemon démarre le planificateur en tant que daemon
 func startPlannerDaemon() {
-	fmt.Println("Démarrage du planificateur en tant que daemon...")
+	fmt.Println(i18n.T("planner.daemon_starting"))
 
 	// Sous Windows, créer un exécutable dédié au lieu d'utiliser go run
 	var cmd *exec.Cmd
@@ -212,7 +338,7 @@ func startPlannerDaemon() {
 	// Détecter le chemin de l'exécutable actuel
 	exePath, err := os.Executable()
 	if err != nil {
-		fmt.Printf("Erreur lors de la détection du chemin de l'exécutable: %v\n", err)
+		fmt.Printf(i18n.T("planner.executable_error"), err)
 		// Fallback au go run standard si on ne peut pas déterminer le chemin
 		cmd = exec.Command("go", "run", ".", "-plan-daemon")
 	} else {
@@ -229,9 +355,9 @@ func startPlannerDaemon() {
 	}
 
 	// Rediriger la sortie vers un fichier log
-	logFile, err := os.OpenFile("planner.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	logFile, err := os.OpenFile(config.Path(scheduler.LogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		fmt.Printf("Erreur lors de la création du fichier log: %v\n", err)
+		fmt.Printf(i18n.T("planner.log_file_error"), err)
 		return
 	}
 	cmd.Stdout = logFile
@@ -240,38 +366,38 @@ func startPlannerDaemon() {
 	// Démarrer en arrière-plan
 	err = cmd.Start()
 	if err != nil {
-		fmt.Printf("Erreur lors du démarrage du daemon: %v\n", err)
+		fmt.Printf(i18n.T("planner.daemon_start_error"), err)
 		return
 	}
 
 	// Enregistrer le PID dans le fichier
-	pidFile, err := os.Create("planner.pid")
+	pidFile, err := os.Create(config.Path(plannerPIDFile))
 	if err != nil {
-		fmt.Printf("Erreur lors de la création du fichier PID: %v\n", err)
+		fmt.Printf(i18n.T("planner.pid_create_error"), err)
 		return
 	}
 
 	_, err = fmt.Fprintf(pidFile, "%d", cmd.Process.Pid)
 	pidFile.Close()
 	if err != nil {
-		fmt.Printf("Erreur lors de l'écriture du PID: %v\n", err)
+		fmt.Printf(i18n.T("planner.pid_write_error"), err)
 		return
 	}
 
 	// Astuce supplémentaire: enregistrer également le nom de l'exécutable
 	// pour faciliter la recherche du processus lors de l'arrêt
-	exeInfoFile, _ := os.Create("planner_exe.info")
+	exeInfoFile, _ := os.Create(config.Path(plannerExeInfoFile))
 	if exeInfoFile != nil {
 		fmt.Fprintf(exeInfoFile, "%s\n%d", filepath.Base(exePath), cmd.Process.Pid)
 		exeInfoFile.Close()
 	}
 
-	fmt.Println("Planificateur démarré avec succès (PID:", cmd.Process.Pid, ")")
+	fmt.Printf(i18n.T("planner.daemon_started"), cmd.Process.Pid)
 }
 
 // stopPlannerDaemon arrête le daemon du planificateur
 func stopPlannerDaemon() {
-	fmt.Println("Arrêt du planificateur...")
+	fmt.Println(i18n.T("planner.stopping"))
 
 	// Stratégie en plusieurs phases pour trouver et arrêter le processus
 
@@ -279,7 +405,7 @@ func stopPlannerDaemon() {
 	pidFound := false
 	var pid int
 
-	if pidData, err := os.ReadFile("planner.pid"); err == nil {
+	if pidData, err := os.ReadFile(config.Path(plannerPIDFile)); err == nil {
 		if tmpPid, err := strconv.Atoi(strings.TrimSpace(string(pidData))); err == nil {
 			pid = tmpPid
 			pidFound = true
@@ -288,16 +414,16 @@ func stopPlannerDaemon() {
 
 	// 2. Si le PID est trouvé, essayer de l'arrêter
 	if pidFound {
-		fmt.Printf("Tentative d'arrêt du processus avec PID %d...\n", pid)
+		fmt.Printf(i18n.T("planner.stopping_pid"), pid)
 
 		if runtime.GOOS == "windows" {
 			cmd := exec.Command("taskkill", "/F", "/PID", strconv.Itoa(pid))
 			if err := cmd.Run(); err == nil {
-				fmt.Println("Planificateur arrêté avec succès.")
+				fmt.Println(i18n.T("planner.stopped_ok"))
 				cleanupPlannerFiles()
 				return
 			} else {
-				fmt.Printf("Impossible d'arrêter le processus %d: %v\n", pid, err)
+				fmt.Printf(i18n.T("planner.stop_pid_error"), pid, err)
 				// Continuer avec les autres méthodes
 			}
 		} else {
@@ -305,7 +431,7 @@ func stopPlannerDaemon() {
 			process, err := os.FindProcess(pid)
 			if err == nil {
 				if err := process.Signal(syscall.SIGTERM); err == nil {
-					fmt.Println("Planificateur arrêté avec succès.")
+					fmt.Println(i18n.T("planner.stopped_ok"))
 					cleanupPlannerFiles()
 					return
 				}
@@ -314,12 +440,12 @@ func stopPlannerDaemon() {
 	}
 
 	// 3. Rechercher par nom de processus (bot-spot ou similaire)
-	fmt.Println("Recherche du processus planificateur par nom...")
+	fmt.Println(i18n.T("planner.search_by_name"))
 
 	if runtime.GOOS == "windows" {
 		// Lire le nom de l'exécutable dans le fichier info si disponible
 		var processName string
-		if infoData, err := os.ReadFile("planner_exe.info"); err == nil {
+		if infoData, err := os.ReadFile(config.Path(plannerExeInfoFile)); err == nil {
 			lines := strings.Split(string(infoData), "\n")
 			if len(lines) > 0 {
 				processName = strings.TrimSpace(lines[0])
@@ -352,7 +478,7 @@ func stopPlannerDaemon() {
 								// Essayer de tuer ce processus
 								killCmd := exec.Command("taskkill", "/F", "/PID", pidStr)
 								if err := killCmd.Run(); err == nil {
-									fmt.Printf("Processus %s avec PID %s arrêté avec succès.\n", processName, pidStr)
+									fmt.Printf(i18n.T("planner.process_stopped"), processName, pidStr)
 									pidFound = true
 								}
 							}
@@ -369,7 +495,7 @@ func stopPlannerDaemon() {
 			if err == nil {
 				lines := strings.Split(string(output), "\n")
 				if len(lines) > 1 {
-					fmt.Println("Processus go.exe trouvés. Vous devrez peut-être les arrêter manuellement:")
+					fmt.Println(i18n.T("planner.go_processes_found"))
 					for i, line := range lines {
 						if i == 0 || line == "" { // Ignorer l'en-tête et les lignes vides
 							continue
@@ -383,37 +509,38 @@ func stopPlannerDaemon() {
 		// Pour les systèmes Unix
 		cmd := exec.Command("pkill", "-f", "bot-spot")
 		if err := cmd.Run(); err == nil {
-			fmt.Println("Processus planificateur arrêté avec succès.")
+			fmt.Println(i18n.T("planner.process_stopped_ok"))
 			pidFound = true
 		}
 	}
 
 	if pidFound {
 		cleanupPlannerFiles()
-		fmt.Println("Planificateur arrêté avec succès.")
+		fmt.Println(i18n.T("planner.stopped_ok"))
 	} else {
-		fmt.Println("Impossible de trouver ou d'arrêter le planificateur.")
-		fmt.Println("Vous devrez peut-être l'arrêter manuellement via le Gestionnaire des tâches.")
+		fmt.Println(i18n.T("planner.stop_not_found"))
+		fmt.Println(i18n.T("planner.stop_manually"))
 	}
 }
 
 func cleanupPlannerFiles() {
 	// Supprimer les fichiers de suivi
-	os.Remove("planner.pid")
-	os.Remove("planner_exe.info")
+	os.Remove(config.Path(plannerPIDFile))
+	os.Remove(config.Path(plannerExeInfoFile))
 }
 
 // checkPlannerStatus vérifie si le planificateur est en cours d'exécution
 func checkPlannerStatus() {
-	pidData, err := os.ReadFile("planner.pid")
+	pidData, err := os.ReadFile(config.Path(plannerPIDFile))
 	if err != nil {
-		fmt.Println("Statut: Le planificateur n'est pas en cours d'exécution.")
+		fmt.Println(i18n.T("planner.status_stopped"))
+		displayRecentRuns(recentRunsShown)
 		return
 	}
 
 	pid, err := strconv.Atoi(strings.TrimSpace(string(pidData)))
 	if err != nil {
-		fmt.Printf("Erreur lors de la lecture du PID: %v\n", err)
+		fmt.Printf(i18n.T("planner.pid_read_error"), err)
 		return
 	}
 
@@ -437,17 +564,19 @@ func checkPlannerStatus() {
 	}
 
 	if exists {
-		fmt.Printf("Statut: Le planificateur est en cours d'exécution (PID: %d)\n", pid)
+		fmt.Printf(i18n.T("planner.status_running"), pid)
 	} else {
-		fmt.Println("Statut: Le planificateur n'est pas en cours d'exécution (PID périmé).")
-		os.Remove("planner.pid") // Nettoyer le fichier PID obsolète
+		fmt.Println(i18n.T("planner.status_stale_pid"))
+		os.Remove(config.Path(plannerPIDFile)) // Nettoyer le fichier PID obsolète
 	}
+
+	displayRecentRuns(recentRunsShown)
 }
 
 // runPlannerDaemon démarre le planificateur en mode daemon
 func runPlannerDaemon() {
 	// Configurer la journalisation
-	logFile, err := os.OpenFile("planner_daemon.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	logFile, err := os.OpenFile(config.Path(scheduler.DaemonLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return // En mode daemon, on ne peut pas afficher d'erreur
 	}
@@ -455,7 +584,7 @@ func runPlannerDaemon() {
 	log.Println("Démarrage du planificateur en mode daemon")
 
 	// Initialiser la configuration et le logger
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
 		log.Printf("Erreur lors du chargement de la configuration: %v\n", err)
 		return
@@ -479,12 +608,24 @@ func runPlannerDaemon() {
 	sched.Start()
 	log.Println("Planificateur démarré avec succès")
 
-	// Créer un canal pour capturer les signaux d'interruption
+	// Créer un canal pour capturer les signaux d'interruption et de rechargement
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
-	// Attendre le signal d'interruption
-	<-sigChan
+	// Recharger bot.conf sur SIGHUP, s'arrêter sur les autres signaux
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+
+		newCfg, err := config.Reload()
+		if err != nil {
+			log.Printf("Erreur lors du rechargement de la configuration, conservation de la précédente: %v\n", err)
+			continue
+		}
+		sched.SetConfig(newCfg)
+		log.Println("Configuration rechargée")
+	}
 
 	// Arrêter le planificateur
 	sched.Stop()
@@ -513,19 +654,19 @@ func formatIntervalToString(value int, unit types.TimeUnit) string {
 	switch unit {
 	case types.Minutes:
 		if value == 1 {
-			return "1 minute"
+			return i18n.T("planner.interval_minute")
 		}
-		return fmt.Sprintf("%d minutes", value)
+		return i18n.T("planner.interval_minutes", value)
 	case types.Hours:
 		if value == 1 {
-			return "1 heure"
+			return i18n.T("planner.interval_hour")
 		}
-		return fmt.Sprintf("%d heures", value)
+		return i18n.T("planner.interval_hours", value)
 	case types.Days:
 		if value == 1 {
-			return "1 jour"
+			return i18n.T("planner.interval_day")
 		}
-		return fmt.Sprintf("%d jours", value)
+		return i18n.T("planner.interval_days", value)
 	default:
 		return fmt.Sprintf("%d %s", value, unit)
 	}
@@ -533,11 +674,13 @@ func formatIntervalToString(value int, unit types.TimeUnit) string {
 
 func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 	// 1. Définir le type de tâche
-	fmt.Println("\n=== Configuration d'une nouvelle tâche ===")
-	fmt.Println("Types de tâches disponibles:")
-	fmt.Println("1. Mise à jour des cycles (update)")
-	fmt.Println("2. Création d'un nouveau cycle (new)")
-	fmt.Print("Choisissez le type de tâche (1 ou 2): ")
+	fmt.Println(i18n.T("planner.new_task_heading"))
+	fmt.Println(i18n.T("planner.task_types"))
+	fmt.Println(i18n.T("planner.task_type_update"))
+	fmt.Println(i18n.T("planner.task_type_new"))
+	fmt.Println(i18n.T("planner.task_type_snapshot"))
+	fmt.Println(i18n.T("planner.task_type_cancel_stale"))
+	fmt.Print(i18n.T("planner.ask_task_type"))
 
 	typeChoice, _ := reader.ReadString('\n')
 	typeChoice = strings.TrimSpace(typeChoice)
@@ -548,21 +691,30 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 		taskType = "update"
 	case "2":
 		taskType = "new"
+	case "3":
+		taskType = "snapshot"
+	case "4":
+		taskType = "cancel_stale"
 	default:
-		fmt.Println("Choix invalide. Configuration annulée.")
+		fmt.Println(i18n.T("planner.invalid_choice_cancelled"))
 		return
 	}
 
 	// 2. Définir le nom de la tâche
-	fmt.Print("\nNom de la tâche: ")
+	fmt.Print(i18n.T("planner.ask_task_name"))
 	taskName, _ := reader.ReadString('\n')
 	taskName = strings.TrimSpace(taskName)
 
 	if taskName == "" {
 		// Utiliser un nom par défaut basé sur le type
-		if taskType == "update" {
+		switch taskType {
+		case "update":
 			taskName = "update-cycles-auto"
-		} else {
+		case "snapshot":
+			taskName = "portfolio-snapshot-auto"
+		case "cancel_stale":
+			taskName = "cancel-stale-auto"
+		default:
 			taskName = "new-cycle-auto"
 		}
 	}
@@ -571,11 +723,11 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 	var intervalValue int
 	var intervalUnit types.TimeUnit
 
-	fmt.Println("\nDéfinir l'intervalle d'exécution:")
-	fmt.Println("1. Minutes")
-	fmt.Println("2. Heures")
-	fmt.Println("3. Jours")
-	fmt.Print("Choisissez l'unité (1-3): ")
+	fmt.Println(i18n.T("planner.interval_heading"))
+	fmt.Println(i18n.T("planner.unit_minutes"))
+	fmt.Println(i18n.T("planner.unit_hours"))
+	fmt.Println(i18n.T("planner.unit_days"))
+	fmt.Print(i18n.T("planner.ask_unit"))
 
 	unitChoice, _ := reader.ReadString('\n')
 	unitChoice = strings.TrimSpace(unitChoice)
@@ -583,17 +735,17 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 	switch unitChoice {
 	case "1":
 		intervalUnit = types.Minutes
-		fmt.Print("Intervalle en minutes: ")
+		fmt.Print(i18n.T("planner.ask_minutes"))
 	case "2":
 		intervalUnit = types.Hours
-		fmt.Print("Intervalle en heures: ")
+		fmt.Print(i18n.T("planner.ask_hours"))
 	case "3":
 		intervalUnit = types.Days
-		fmt.Print("Intervalle en jours: ")
+		fmt.Print(i18n.T("planner.ask_days"))
 	default:
-		fmt.Println("Unité invalide, utilisation des minutes par défaut.")
+		fmt.Println(i18n.T("planner.invalid_unit"))
 		intervalUnit = types.Minutes
-		fmt.Print("Intervalle en minutes: ")
+		fmt.Print(i18n.T("planner.ask_minutes"))
 	}
 
 	intervalStr, _ := reader.ReadString('\n')
@@ -602,26 +754,26 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 	if val, err := strconv.Atoi(intervalStr); err == nil {
 		intervalValue = val
 	} else {
-		fmt.Println("Valeur invalide, utilisation de 5 par défaut.")
+		fmt.Println(i18n.T("planner.invalid_interval"))
 		intervalValue = 5
 	}
 
 	// 4. Définir une heure spécifique (optionnel)
 	var specificTime string
 	if intervalUnit == types.Days {
-		fmt.Print("\nVoulez-vous définir une heure spécifique pour l'exécution? (o/n): ")
+		fmt.Print(i18n.T("planner.ask_specific_time"))
 		response, _ := reader.ReadString('\n')
 		response = strings.TrimSpace(strings.ToLower(response))
 
 		if response == "o" || response == "oui" || response == "y" || response == "yes" {
-			fmt.Print("Entrez l'heure au format HH:MM (ex: 09:30): ")
+			fmt.Print(i18n.T("planner.ask_time"))
 			specificTime, _ = reader.ReadString('\n')
 			specificTime = strings.TrimSpace(specificTime)
 
 			// Valider le format de l'heure
 			matched, _ := regexp.MatchString(`^([01]?[0-9]|2[0-3]):[0-5][0-9]$`, specificTime)
 			if !matched {
-				fmt.Println("Format d'heure invalide, aucune heure spécifique ne sera définie.")
+				fmt.Println(i18n.T("planner.invalid_time"))
 				specificTime = ""
 			}
 		}
@@ -629,19 +781,23 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 
 	// 5. Choisir l'exchange et les paramètres personnalisés
 	var exchangeName string
-	var buyOffset, sellOffset, percent float64
+	var buyOffset, sellOffset, buyOffsetPercent, sellOffsetPercent, percent float64
 
-	fmt.Print("\nSpécifier un exchange particulier? (o/n): ")
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
+	// L'instantané couvre toujours l'ensemble des exchanges
+	response := ""
+	if taskType != "snapshot" {
+		fmt.Print(i18n.T("planner.ask_specific_exchange"))
+		response, _ = reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+	}
 
 	if response == "o" || response == "oui" || response == "y" || response == "yes" {
-		fmt.Println("\nExchanges disponibles:")
+		fmt.Println(i18n.T("planner.exchanges_heading"))
 		fmt.Println("1. BINANCE")
 		fmt.Println("2. MEXC")
 		fmt.Println("3. KUCOIN")
 		fmt.Println("4. KRAKEN")
-		fmt.Print("Choisissez un exchange (1-4): ")
+		fmt.Print(i18n.T("planner.ask_exchange"))
 
 		exchangeChoice, _ := reader.ReadString('\n')
 		exchangeChoice = strings.TrimSpace(exchangeChoice)
@@ -656,19 +812,19 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 		case "4":
 			exchangeName = "KRAKEN"
 		default:
-			fmt.Println("Choix invalide, aucun exchange spécifique ne sera défini.")
+			fmt.Println(i18n.T("planner.invalid_exchange"))
 			exchangeName = ""
 		}
 
 		// Si un exchange est spécifié et que le type est "new", proposer de personnaliser les paramètres
 		if exchangeName != "" && taskType == "new" {
-			fmt.Print("\nVoulez-vous personnaliser les paramètres de trading (BUY_OFFSET, SELL_OFFSET, PERCENT)? (o/n): ")
+			fmt.Print(i18n.T("planner.ask_custom_params"))
 			response, _ := reader.ReadString('\n')
 			response = strings.TrimSpace(strings.ToLower(response))
 
 			if response == "o" || response == "oui" || response == "y" || response == "yes" {
 				// BUY_OFFSET
-				fmt.Print("BUY_OFFSET (laissez vide pour utiliser la valeur par défaut): ")
+				fmt.Print(i18n.T("planner.ask_buy_offset"))
 				buyOffsetStr, _ := reader.ReadString('\n')
 				buyOffsetStr = strings.TrimSpace(buyOffsetStr)
 
@@ -676,12 +832,12 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 					if val, err := strconv.ParseFloat(buyOffsetStr, 64); err == nil {
 						buyOffset = val
 					} else {
-						fmt.Println("Valeur invalide, utilisation de la valeur par défaut.")
+						fmt.Println(i18n.T("planner.invalid_value_default"))
 					}
 				}
 
 				// SELL_OFFSET
-				fmt.Print("SELL_OFFSET (laissez vide pour utiliser la valeur par défaut): ")
+				fmt.Print(i18n.T("planner.ask_sell_offset"))
 				sellOffsetStr, _ := reader.ReadString('\n')
 				sellOffsetStr = strings.TrimSpace(sellOffsetStr)
 
@@ -689,12 +845,37 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 					if val, err := strconv.ParseFloat(sellOffsetStr, 64); err == nil {
 						sellOffset = val
 					} else {
-						fmt.Println("Valeur invalide, utilisation de la valeur par défaut.")
+						fmt.Println(i18n.T("planner.invalid_value_default"))
+					}
+				}
+
+				// BUY_OFFSET_PERCENT et SELL_OFFSET_PERCENT: offsets en % du prix, l'offset absolu en plancher
+				fmt.Print(i18n.T("planner.ask_buy_offset_percent"))
+				buyOffsetPercentStr, _ := reader.ReadString('\n')
+				buyOffsetPercentStr = strings.TrimSpace(buyOffsetPercentStr)
+
+				if buyOffsetPercentStr != "" {
+					if val, err := strconv.ParseFloat(buyOffsetPercentStr, 64); err == nil {
+						buyOffsetPercent = val
+					} else {
+						fmt.Println(i18n.T("planner.invalid_value_default"))
+					}
+				}
+
+				fmt.Print(i18n.T("planner.ask_sell_offset_percent"))
+				sellOffsetPercentStr, _ := reader.ReadString('\n')
+				sellOffsetPercentStr = strings.TrimSpace(sellOffsetPercentStr)
+
+				if sellOffsetPercentStr != "" {
+					if val, err := strconv.ParseFloat(sellOffsetPercentStr, 64); err == nil {
+						sellOffsetPercent = val
+					} else {
+						fmt.Println(i18n.T("planner.invalid_value_default"))
 					}
 				}
 
 				// PERCENT
-				fmt.Print("PERCENT (laissez vide pour utiliser la valeur par défaut): ")
+				fmt.Print(i18n.T("planner.ask_percent"))
 				percentStr, _ := reader.ReadString('\n')
 				percentStr = strings.TrimSpace(percentStr)
 
@@ -702,13 +883,61 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 					if val, err := strconv.ParseFloat(percentStr, 64); err == nil {
 						percent = val
 					} else {
-						fmt.Println("Valeur invalide, utilisation de la valeur par défaut.")
+						fmt.Println(i18n.T("planner.invalid_value_default"))
 					}
 				}
 			}
 		}
 	}
 
+	// Âge au-delà duquel une tâche "cancel_stale" annule les achats, indépendant de BUY_MAX_DAYS
+	var maxAgeDays int
+	if taskType == "cancel_stale" {
+		for maxAgeDays <= 0 {
+			fmt.Print(i18n.T("planner.ask_max_age_days"))
+			value, _ := reader.ReadString('\n')
+			if days, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && days > 0 {
+				maxAgeDays = days
+			} else {
+				fmt.Println(i18n.T("planner.invalid_max_age_days"))
+			}
+		}
+	}
+
+	// Paire négociée par une tâche "new" sur un exchange précis, celle de l'exchange par défaut
+	var pair, quoteAsset string
+	if exchangeName != "" && taskType == "new" {
+		cfg, _ := config.Get()
+		pair = cfg.DefaultPair(exchangeName)
+		fmt.Printf(i18n.T("planner.ask_pair"), pair)
+		pairStr, _ := reader.ReadString('\n')
+		if pairStr = strings.TrimSpace(pairStr); pairStr != "" {
+			if parsed, _, err := config.ParsePair(pairStr); err == nil {
+				pair = parsed
+			} else {
+				fmt.Println(i18n.T("planner.invalid_value_default"))
+			}
+		}
+		if pair != cfg.DefaultPair(exchangeName) {
+			fmt.Printf(i18n.T("planner.pair_not_traded"), pair, exchangeName, cfg.DefaultPair(exchangeName))
+		}
+		_, quoteAsset, _ = config.ParsePair(pair)
+	}
+
+	// 6. Délai aléatoire ajouté à chaque exécution (optionnel)
+	var jitterSeconds int
+	fmt.Print(i18n.T("planner.ask_jitter"))
+	jitterStr, _ := reader.ReadString('\n')
+	jitterStr = strings.TrimSpace(jitterStr)
+
+	if jitterStr != "" {
+		if val, err := strconv.Atoi(jitterStr); err == nil && val >= 0 {
+			jitterSeconds = val
+		} else {
+			fmt.Println(i18n.T("planner.invalid_jitter"))
+		}
+	}
+
 	// Créer la configuration de la tâche
 	// Convertir types.TimeUnit vers scheduler.TimeUnit
 	var schedIntervalUnit types.TimeUnit
@@ -732,6 +961,13 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 		SellOffset:    sellOffset,
 		Percent:       percent,
 		Enabled:       true,
+
+		BuyOffsetPercent:  buyOffsetPercent,
+		SellOffsetPercent: sellOffsetPercent,
+		JitterSeconds:     jitterSeconds,
+		Pair:              pair,
+		QuoteAsset:        quoteAsset,
+		MaxAgeDays:        maxAgeDays,
 	}
 
 	// Créer la fonction appropriée pour la tâche
@@ -741,6 +977,10 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 		taskFn = sched.CreateUpdateTask()
 	case "new":
 		taskFn = sched.CreateNewCycleTask()
+	case "snapshot":
+		taskFn = sched.CreateSnapshotTask()
+	case "cancel_stale":
+		taskFn = sched.CreateCancelStaleTask()
 	}
 
 	// Ajouter la tâche
@@ -749,40 +989,55 @@ func addNewTaskInteractive(sched *scheduler.Scheduler, reader *bufio.Reader) {
 	// Sauvegarder la tâche dans la configuration (persistance)
 	err := sched.SaveTasksToConfig()
 	if err != nil {
-		fmt.Printf("Erreur lors de la sauvegarde de la tâche: %v\n", err)
+		fmt.Printf(i18n.T("planner.task_save_error"), err)
 	}
 
-	fmt.Printf("\nTâche '%s' ajoutée avec succès.\n", taskConfig.Name)
-	fmt.Printf("La tâche sera exécutée tous les %s.\n",
+	fmt.Printf(i18n.T("planner.task_added"), taskConfig.Name)
+	fmt.Printf(i18n.T("planner.task_every"),
 		formatIntervalToString(taskConfig.IntervalValue, intervalUnit))
 
 	if taskConfig.SpecificTime != "" {
-		fmt.Printf("Exécution à %s tous les jours.\n", taskConfig.SpecificTime)
+		fmt.Printf(i18n.T("planner.task_daily_at"), taskConfig.SpecificTime)
+	}
+	if taskConfig.JitterSeconds > 0 {
+		fmt.Printf(i18n.T("planner.task_jitter_summary"), taskConfig.JitterSeconds)
+	}
+	if taskConfig.Type == "cancel_stale" {
+		fmt.Printf(i18n.T("planner.task_max_age_days_summary"), taskConfig.MaxAgeDays)
 	}
 
 	// Afficher un résumé des paramètres personnalisés si définis
 	if taskConfig.Type == "new" {
-		fmt.Println("\nParamètres de trading définis:")
+		fmt.Println(i18n.T("planner.custom_params_heading"))
+		if taskConfig.Pair != "" {
+			fmt.Printf(i18n.T("planner.custom_pair"), taskConfig.Pair)
+		}
 		if taskConfig.BuyOffset != 0 {
 			fmt.Printf("- BuyOffset: %.2f\n", taskConfig.BuyOffset)
 		}
 		if taskConfig.SellOffset != 0 {
 			fmt.Printf("- SellOffset: %.2f\n", taskConfig.SellOffset)
 		}
+		if taskConfig.BuyOffsetPercent != 0 {
+			fmt.Printf("- BuyOffsetPercent: %.2f%%\n", taskConfig.BuyOffsetPercent)
+		}
+		if taskConfig.SellOffsetPercent != 0 {
+			fmt.Printf("- SellOffsetPercent: %.2f%%\n", taskConfig.SellOffsetPercent)
+		}
 		if taskConfig.Percent != 0 {
-			fmt.Printf("- Pourcentage USDC: %.2f%%\n", taskConfig.Percent)
+			fmt.Printf(i18n.T("planner.custom_percent"), taskConfig.Percent)
 		}
 	}
 }
 
 // removeTaskCmd gère la commande pour supprimer une tâche planifiée
 func removeTaskCmd() {
-	fmt.Println("=== Suppression d'une tâche planifiée ===")
+	fmt.Println(i18n.T("planner.remove_heading"))
 
 	// Initialiser la configuration et le logger
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement de la configuration: %v\n", err)
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
 		return
 	}
 
@@ -797,22 +1052,22 @@ func removeTaskCmd() {
 	// Charger les tâches existantes
 	err = sched.LoadTasksFromConfig()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement des tâches: %v\n", err)
+		fmt.Printf(i18n.T("planner.tasks_load_error"), err)
 	}
 
 	// Afficher les tâches existantes
 	tasks := sched.GetAllTasks()
 
 	if len(tasks) == 0 {
-		fmt.Println("Aucune tâche planifiée n'est configurée actuellement.")
+		fmt.Println(i18n.T("planner.no_tasks"))
 		return
 	}
 
-	fmt.Println("\nTâches planifiées existantes:")
+	fmt.Println(i18n.T("planner.existing_tasks"))
 	for i, task := range tasks {
-		statusStr := "Activée"
+		statusStr := i18n.T("planner.task_enabled")
 		if !task.Enabled {
-			statusStr = "Désactivée"
+			statusStr = i18n.T("planner.task_disabled")
 		}
 
 		// Afficher plus de détails sur l'intervalle
@@ -822,7 +1077,7 @@ func removeTaskCmd() {
 			intervalStr = formatIntervalToString(task.IntervalValue, task.IntervalUnit)
 		}
 
-		fmt.Printf("%d. %s - %s - Intervalle: %s - État: %s\n",
+		fmt.Printf(i18n.T("planner.task_line"),
 			i+1,
 			task.Name,
 			task.Type,
@@ -835,12 +1090,21 @@ func removeTaskCmd() {
 
 			// Afficher les paramètres personnalisés
 			customParams := []string{}
+			if task.Pair != "" {
+				customParams = append(customParams, "Pair: "+task.Pair)
+			}
 			if task.BuyOffset != 0 {
 				customParams = append(customParams, fmt.Sprintf("BuyOffset: %.2f", task.BuyOffset))
 			}
 			if task.SellOffset != 0 {
 				customParams = append(customParams, fmt.Sprintf("SellOffset: %.2f", task.SellOffset))
 			}
+			if task.BuyOffsetPercent != 0 {
+				customParams = append(customParams, fmt.Sprintf("BuyOffsetPercent: %.2f%%", task.BuyOffsetPercent))
+			}
+			if task.SellOffsetPercent != 0 {
+				customParams = append(customParams, fmt.Sprintf("SellOffsetPercent: %.2f%%", task.SellOffsetPercent))
+			}
 			if task.Percent != 0 {
 				customParams = append(customParams, fmt.Sprintf("Percent: %.2f", task.Percent))
 			}
@@ -852,24 +1116,24 @@ func removeTaskCmd() {
 		}
 
 		if task.SpecificTime != "" {
-			fmt.Printf("   Heure d'exécution: %s\n", task.SpecificTime)
+			fmt.Printf(i18n.T("planner.task_time"), task.SpecificTime)
 		}
 	}
 
 	// Demander à l'utilisateur quelle tâche supprimer
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("\nEntrez le numéro de la tâche à supprimer (ou 0 pour annuler): ")
+	fmt.Print(i18n.T("planner.ask_remove_number"))
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 
 	taskNum, err := strconv.Atoi(input)
 	if err != nil || taskNum < 0 || taskNum > len(tasks) {
-		fmt.Println("Numéro de tâche invalide.")
+		fmt.Println(i18n.T("planner.invalid_task_number"))
 		return
 	}
 
 	if taskNum == 0 {
-		fmt.Println("Opération annulée.")
+		fmt.Println(i18n.T("planner.cancelled"))
 		return
 	}
 
@@ -877,31 +1141,31 @@ func removeTaskCmd() {
 	taskToRemove := tasks[taskNum-1]
 
 	// Demander confirmation
-	fmt.Printf("\nÊtes-vous sûr de vouloir supprimer la tâche '%s' ? (o/n): ", taskToRemove.Name)
+	fmt.Printf(i18n.T("planner.confirm_remove"), taskToRemove.Name)
 	confirm, _ := reader.ReadString('\n')
 	confirm = strings.TrimSpace(strings.ToLower(confirm))
 
 	if confirm != "o" && confirm != "oui" && confirm != "y" && confirm != "yes" {
-		fmt.Println("Suppression annulée.")
+		fmt.Println(i18n.T("planner.remove_cancelled"))
 		return
 	}
 
 	err = sched.RemoveTask(taskToRemove.Name)
 	if err != nil {
-		fmt.Printf("Erreur lors de la suppression de la tâche: %v\n", err)
+		fmt.Printf(i18n.T("planner.remove_error"), err)
 	} else {
-		fmt.Printf("Tâche '%s' supprimée avec succès.\n", taskToRemove.Name)
+		fmt.Printf(i18n.T("planner.removed"), taskToRemove.Name)
 	}
 }
 
 // removeAllTasksCmd supprime toutes les tâches planifiées
 func removeAllTasksCmd() {
-	fmt.Println("=== Suppression de toutes les tâches planifiées ===")
+	fmt.Println(i18n.T("planner.remove_all_heading"))
 
 	// Initialiser la configuration et le logger
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement de la configuration: %v\n", err)
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
 		return
 	}
 
@@ -916,30 +1180,30 @@ func removeAllTasksCmd() {
 	// Charger les tâches existantes
 	err = sched.LoadTasksFromConfig()
 	if err != nil {
-		fmt.Printf("Erreur lors du chargement des tâches: %v\n", err)
+		fmt.Printf(i18n.T("planner.tasks_load_error"), err)
 	}
 
 	// Afficher les tâches existantes
 	tasks := sched.GetAllTasks()
 
 	if len(tasks) == 0 {
-		fmt.Println("Aucune tâche planifiée n'est configurée actuellement.")
+		fmt.Println(i18n.T("planner.no_tasks"))
 		return
 	}
 
-	fmt.Println("\nTâches planifiées qui seront supprimées:")
+	fmt.Println(i18n.T("planner.remove_all_list"))
 	for i, task := range tasks {
 		fmt.Printf("%d. %s - %s\n", i+1, task.Name, task.Type)
 	}
 
 	// Demander confirmation
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nVous êtes sur le point de supprimer toutes les tâches planifiées (%d). Êtes-vous sûr ? (o/n): ", len(tasks))
+	fmt.Printf(i18n.T("planner.confirm_remove_all"), len(tasks))
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(strings.ToLower(input))
 
 	if input != "o" && input != "oui" && input != "y" && input != "yes" {
-		fmt.Println("Opération annulée.")
+		fmt.Println(i18n.T("planner.cancelled"))
 		return
 	}
 
@@ -952,39 +1216,95 @@ func removeAllTasksCmd() {
 	for _, name := range taskNames {
 		err = sched.RemoveTask(name)
 		if err != nil {
-			fmt.Printf("Erreur lors de la suppression de la tâche '%s': %v\n", name, err)
+			fmt.Printf(i18n.T("planner.remove_named_error"), name, err)
 		}
 	}
 
-	fmt.Println("Toutes les tâches planifiées ont été supprimées.")
+	fmt.Println(i18n.T("planner.removed_all"))
 
 	// Vérifier que le fichier tasks.conf est vide ou a bien la valeur TASKS_COUNT=0
-	tasksConfigFile := "tasks.conf"
+	tasksConfigFile := config.Path("tasks.conf")
 	content := "# Configuration des tâches planifiées\n# Format: TASK_[index]_[property]=[value]\n\nTASKS_COUNT=0\n"
 	err = os.WriteFile(tasksConfigFile, []byte(content), 0644)
 	if err != nil {
-		fmt.Printf("Erreur lors de la mise à jour du fichier de configuration: %v\n", err)
+		fmt.Printf(i18n.T("planner.config_update_error"), err)
+	}
+}
+
+// exportTasksCmd écrit les tâches de tasks.conf dans un fichier YAML portable (-plan export)
+func exportTasksCmd(path string) {
+	cfg, err := config.Get()
+	if err != nil {
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
+		return
+	}
+
+	tasks := cfg.GetScheduledTasks()
+	content, err := scheduler.ExportTasksYAML(tasks)
+	if err != nil {
+		fmt.Printf(i18n.T("planner.export_error"), err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		fmt.Printf(i18n.T("planner.export_error"), err)
+		os.Exit(1)
+	}
+	fmt.Printf(i18n.T("planner.exported"), len(tasks), path)
+}
+
+// importTasksCmd remplace les tâches de tasks.conf par celles d'un fichier YAML (-plan import)
+func importTasksCmd(path string) {
+	cfg, err := config.Get()
+	if err != nil {
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf(i18n.T("planner.import_error"), path, err)
+		os.Exit(1)
+	}
+	tasks, err := scheduler.ParseTasksYAML(content)
+	if err != nil {
+		fmt.Printf(i18n.T("planner.import_error"), path, err)
+		os.Exit(1)
+	}
+
+	log := logger.NewLogger(logger.LogConfig{
+		Level:  "info",
+		Format: "text",
+	})
+	sched := scheduler.NewScheduler(cfg, log)
+	if err := sched.ReplaceTasks(tasks); err != nil {
+		fmt.Printf(i18n.T("planner.config_update_error"), err)
+		os.Exit(1)
+	}
+
+	fmt.Printf(i18n.T("planner.imported"), len(tasks), path)
+	for i, task := range tasks {
+		fmt.Printf("%d. %s - %s (%s)\n", i+1, task.Name, task.Type, formatIntervalToString(task.IntervalValue, task.IntervalUnit))
 	}
 }
 
 // startSchedulerInteractive démarre le planificateur et attend l'interruption de l'utilisateur
 /*func startSchedulerInteractive(sched *scheduler.Scheduler) {
-	fmt.Println("\nDémarrage du planificateur de tâches...")
-	fmt.Println("Le planificateur s'exécutera en arrière-plan.")
-	fmt.Println("Les commandes seront exécutées aux intervalles configurés.")
-	fmt.Println("Appuyez sur Ctrl+C pour arrêter le planificateur.")
+	fmt.Println(i18n.T("planner.starting"))
+	fmt.Println(i18n.T("planner.runs_in_background"))
+	fmt.Println(i18n.T("planner.runs_at_intervals"))
+	fmt.Println(i18n.T("planner.press_ctrl_c"))
 
 	// Afficher les tâches qui vont être exécutées
 	tasks := sched.GetAllTasks()
 	enabledTasks := 0
 
-	fmt.Println("\nTâches planifiées qui seront exécutées:")
+	fmt.Println(i18n.T("planner.tasks_to_run"))
 	for _, task := range tasks {
 		if task.Enabled {
 			enabledTasks++
-			nextRun := "inconnue"
+			nextRun := i18n.T("planner.next_run_unknown")
 			if !task.NextScheduledAt.IsZero() {
-				nextRun = task.NextScheduledAt.Format("02/01/2006 15:04:05")
+				nextRun = task.NextScheduledAt.Format(i18n.DateTimeLayout() + ":05")
 			}
 			var typesIntervalUnit types.TimeUnit
 			switch task.IntervalUnit {
@@ -995,7 +1315,7 @@ func removeAllTasksCmd() {
 			case scheduler.Days:
 				typesIntervalUnit = types.Days
 			}
-			fmt.Printf("- %s (%s) - Prochaine exécution: %s\n",
+			fmt.Printf(i18n.T("planner.task_to_run"),
 				task.Name,
 				formatIntervalToString(task.IntervalValue, typesIntervalUnit),
 				nextRun)
@@ -1003,7 +1323,7 @@ func removeAllTasksCmd() {
 	}
 
 	if enabledTasks == 0 {
-		fmt.Println("Aucune tâche active. Utilisez 'go run . -plan' pour configurer des tâches.")
+		fmt.Println(i18n.T("planner.no_active_tasks"))
 		return
 	}
 
@@ -1017,7 +1337,7 @@ func removeAllTasksCmd() {
 	// Attendre le signal d'interruption
 	<-sigChan
 
-	fmt.Println("\nArrêt du planificateur...")
+	fmt.Println(i18n.T("planner.stopping_nl"))
 	sched.Stop()
-	fmt.Println("Planificateur arrêté.")
+	fmt.Println(i18n.T("planner.stopped"))
 }*/
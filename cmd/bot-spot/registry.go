@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/database"
+	commands "main/internal/services/trading"
+	"main/internal/types"
+)
+
+// cliCommand décrit une commande du bot: ses noms, ses options et son exécution. Le registre
+// sert à la fois à l'exécution (main), à la résolution des alias et à la complétion.
+type cliCommand struct {
+	names       []string // forme longue d'abord ("--new"), puis les formes courtes
+	value       string   // valeur attendue après "=": "cycle" (ID de cycle) ou "" si aucune
+	flags       []string // options propres à la commande
+	subcommands []string // sous-commandes (--plan start, --completion bash...)
+	exchange    bool     // accepte -exchangeXXX et --exchange=XXX
+	early       bool     // exécutée avant l'ouverture de la base de données
+	hidden      bool     // usage interne, absente de la complétion
+	run         func(arg string)
+}
+
+// globalFlags sont les options acceptées par toutes les commandes
+var globalFlags = []string{"--lang=fr", "--lang=en", "--latency-report"}
+
+// exchangeNames sont les exchanges proposés par -exchangeXXX et --exchange=XXX
+var exchangeNames = []string{"binance", "mexc", "kucoin", "kraken"}
+
+// cliCommands est le registre des commandes, dans leur ordre de priorité
+var cliCommands []cliCommand
+
+func init() {
+	cliCommands = []cliCommand{
+		{names: []string{"--completion"}, subcommands: []string{"bash", "zsh", "powershell"}, early: true, run: completionCmd},
+		{names: []string{"--plan", "-plan"}, subcommands: []string{"start", "stop", "status", "export", "import", "-rt", "-ra"}, early: true,
+			run: func(string) { checkPlannerSubCommand() }},
+		{names: []string{"-plan-daemon"}, early: true, hidden: true, run: func(string) { runPlannerDaemon() }},
+		{names: []string{"--balance"}, flags: []string{"--json"}, early: true, run: func(string) { loadConfigOnly(); commands.Balance() }},
+		{names: []string{"--time-check"}, early: true, run: func(string) { loadConfigOnly(); commands.TimeCheck() }},
+		{names: []string{"--validate-config"}, early: true, run: func(string) { commands.ValidateConfig() }},
+		{names: []string{"--fsck"}, early: true, run: func(string) { commands.Fsck() }},
+		{names: []string{"--set-secret"}, value: "exchange", exchange: true, early: true, run: func(string) { checkSetSecretCommand() }},
+		{names: []string{"--setup"}, early: true, run: func(string) { setupCmd() }},
+
+		{names: []string{"--new", "-n"}, flags: []string{"--max", "--ladder=", "--ladder-step=", "--pair="}, exchange: true, run: func(string) {
+			err := commands.NewWithExchange(extractExchangeFromArgs())
+			if errors.Is(err, commands.ErrCycleSkipped) {
+				// Signaler au planificateur qu'il ne s'agit pas d'une erreur
+				database.CloseDatabase()
+				os.Exit(types.ExitCodeSkipped)
+			}
+		}},
+		{names: []string{"--update", "-u"}, exchange: true, run: func(string) { commands.UpdateWithExchange(extractExchangeFromArgs()) }},
+		{names: []string{"--cancel", "-c"}, value: "cycle", exchange: true, run: func(arg string) {
+			commands.CancelWithExchange(extractExchangeFromArgs(), arg)
+		}},
+		{names: []string{"--cancel-stale"}, flags: []string{"--max-age-days="}, exchange: true, run: func(string) {
+			commands.CancelStale(extractExchangeFromArgs())
+		}},
+		{names: []string{"--server", "-s"}, flags: []string{"-readonly", "--addr=", "--port="}, run: func(string) { commands.Server() }},
+		{names: []string{"--set-sell-price"}, flags: []string{"--id=", "--price="}, run: func(string) { commands.SetSellPrice() }},
+		{names: []string{"--average-down"}, flags: []string{"--id=", "--usdc="}, run: func(string) { commands.AverageDown() }},
+		{names: []string{"--close-now"}, flags: []string{"--id=", "--yes"}, run: func(string) { commands.CloseNow() }},
+		{names: []string{"--pause"}, value: "cycle", run: commands.PauseOrResume},
+		{names: []string{"--resume"}, value: "cycle", run: commands.PauseOrResume},
+		{names: []string{"--import"}, flags: []string{"--since=", "--dry-run"}, exchange: true, run: func(string) { commands.Import(extractExchangeFromArgs()) }},
+		{names: []string{"--archive"}, flags: []string{"--before=", "--dry-run"}, run: func(string) { commands.Archive() }},
+		{names: []string{"--tax-report"}, flags: []string{"--year=", "--output="}, run: func(string) { commands.TaxReport() }},
+		{names: []string{"--report"}, flags: []string{"--period=", "--output=", "--notify"}, run: func(string) { commands.Report() }},
+		{names: []string{"--snapshot"}, run: func(string) { commands.Snapshot() }},
+		{names: []string{"--webhook-test"}, run: func(string) { commands.WebhookTest() }},
+		{names: []string{"--push-keys"}, run: func(string) { commands.PushKeys() }},
+		{names: []string{"--check-order-ids"}, run: func(string) { commands.CheckOrderIds() }},
+		{names: []string{"--dedupe"}, run: func(string) { commands.Dedupe() }},
+		{names: []string{"--orphans"}, exchange: true, run: func(string) { commands.Orphans(extractExchangeFromArgs()) }},
+		{names: []string{"--simulate-update"}, flags: []string{"--json"}, run: func(string) { commands.SimulateUpdate() }},
+		{names: []string{"--check"}, run: func(string) { commands.Check() }},
+		{names: []string{"--status"}, flags: []string{"--json"}, run: func(string) { commands.Status() }},
+		{names: []string{"--watch"}, flags: []string{"--interval="}, run: func(string) { commands.Watch() }},
+		{names: []string{"--override-loss-limit"}, run: func(string) { commands.OverrideLossLimit() }},
+		{names: []string{"--stats", "-st"}, run: func(string) { commands.StatsServer() }},
+	}
+}
+
+// matches indique si l'argument désigne la commande: "--cancel", "-c" ou, pour les commandes
+// qui attendent une valeur, "--cancel=42"
+func (c cliCommand) matches(arg string) bool {
+	for _, name := range c.names {
+		if arg == name || (c.value != "" && strings.HasPrefix(arg, name+"=")) {
+			return true
+		}
+	}
+	return false
+}
+
+// word retourne le nom de la commande sans tirets, tel qu'il s'écrit en premier argument
+// ("new" pour "--new")
+func (c cliCommand) word() string {
+	return strings.TrimLeft(c.names[0], "-")
+}
+
+// runCommand exécute la première commande du registre trouvée dans les arguments parmi celles
+// qui s'exécutent avant (early) ou après l'ouverture de la base de données
+func runCommand(early bool) bool {
+	for _, arg := range commands.GetAllArgs() {
+		for _, command := range cliCommands {
+			if command.early == early && command.matches(arg) {
+				command.run(arg)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadConfigOnly charge la configuration sans ouvrir la base de données
+func loadConfigOnly() {
+	cfg, err := config.Get()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	commands.SetConfig(cfg)
+}
+
+// normalizeArgs réécrit les arguments de la forme courte "bot-spot nb" ou "bot-spot cancel 42":
+// un alias de bot.conf est remplacé par sa définition, puis un premier argument sans tiret qui
+// nomme une commande prend sa forme longue ("new" devient "--new", "cancel 42" devient
+// "--cancel=42"), et "--exchange binance" devient "--exchange=binance"
+func normalizeArgs(args []string, aliases map[string]string) []string {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if definition, ok := aliases[strings.ToLower(args[0])]; ok && findCommandWord(args[0]) == nil {
+			args = append(strings.Fields(definition), args[1:]...)
+		}
+	}
+
+	var normalized []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i == 0 {
+			if command := findCommandWord(arg); command != nil {
+				arg = command.names[0]
+				if command.value != "" && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+					arg += "=" + args[i+1]
+					i++
+				}
+			}
+		}
+
+		// --exchange binance s'écrit --exchange=binance, la forme que lisent les commandes
+		if arg == "--exchange" && i+1 < len(args) {
+			arg = "--exchange=" + args[i+1]
+			i++
+		}
+		normalized = append(normalized, arg)
+	}
+	return normalized
+}
+
+// findCommandWord retourne la commande dont le nom sans tirets est word, nil sinon
+func findCommandWord(word string) *cliCommand {
+	for i := range cliCommands {
+		if !cliCommands[i].hidden && cliCommands[i].word() == strings.ToLower(word) {
+			return &cliCommands[i]
+		}
+	}
+	return nil
+}
+
+// expandArgs applique normalizeArgs aux arguments du programme, avec les alias de bot.conf
+func expandArgs() {
+	aliases := config.AliasSettings()
+	for _, name := range sortedKeys(aliases) {
+		if findCommandWord(name) != nil {
+			log.Printf("Warning: alias %q ignored, it shadows the %s command\n", name, name)
+		}
+	}
+	os.Args = append([]string{os.Args[0]}, normalizeArgs(os.Args[1:], aliases)...)
+}
+
+// sortedKeys retourne les clés d'une map dans l'ordre alphabétique
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commandNames retourne les noms proposés en premier argument: commandes sous leur forme
+// courte et longue, puis alias
+func commandNames(aliases map[string]string) []string {
+	var names []string
+	for _, command := range cliCommands {
+		if command.hidden {
+			continue
+		}
+		names = append(names, command.word())
+		names = append(names, command.names...)
+	}
+	for _, alias := range sortedKeys(aliases) {
+		if findCommandWord(alias) == nil {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// commandFlags retourne les options proposées après une commande
+func commandFlags(command *cliCommand) []string {
+	flags := append([]string(nil), command.flags...)
+	if command.exchange {
+		for _, exchange := range exchangeNames {
+			flags = append(flags, "-exchange"+exchange, "--exchange="+exchange)
+		}
+	}
+	flags = append(flags, command.subcommands...)
+	return append(flags, globalFlags...)
+}
+
+// findCommandArg retourne la commande désignée par un argument, sous n'importe quelle forme
+func findCommandArg(arg string) *cliCommand {
+	if command := findCommandWord(arg); command != nil {
+		return command
+	}
+	for i := range cliCommands {
+		if cliCommands[i].matches(arg) {
+			return &cliCommands[i]
+		}
+	}
+	return nil
+}
+
+// completionCycleIDs retourne les IDs des cycles en cours, proposés pour --cancel, --pause et
+// --resume
+func completionCycleIDs() []string {
+	database.InitDatabase()
+	defer database.CloseDatabase()
+
+	cycles, err := database.GetRepository().FindByStatus("buy", "sell", database.StatusCancelPending)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		ids = append(ids, fmt.Sprint(cycle.IdInt))
+	}
+	return ids
+}
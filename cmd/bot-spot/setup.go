@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/i18n"
+	"main/internal/scheduler"
+	commands "main/internal/services/trading"
+	"main/pkg/logger"
+)
+
+// setupDefault regroupe les réglages conseillés par l'assistant pour un exchange
+type setupDefault struct {
+	buyOffset  float64
+	sellOffset float64
+	percent    float64
+}
+
+// setupExchanges sont les exchanges proposés par l'assistant, avec les réglages de
+// bot.conf.example proposés lorsqu'ils ne sont pas encore définis
+var setupExchanges = []struct {
+	name     string
+	defaults setupDefault
+}{
+	{"BINANCE", setupDefault{buyOffset: -500, sellOffset: 500, percent: 4}},
+	{"MEXC", setupDefault{buyOffset: -250, sellOffset: 250, percent: 4}},
+	{"KUCOIN", setupDefault{buyOffset: -250, sellOffset: 250, percent: 7}},
+	{"KRAKEN", setupDefault{buyOffset: -300, sellOffset: 300, percent: 5}},
+}
+
+// setupCmd est l'assistant de première configuration (--setup): exchanges activés, clés API
+// vérifiées en direct, offsets et pourcentage, puis une première tâche planifiée facultative.
+// Les valeurs déjà présentes dans bot.conf sont proposées par défaut et le fichier n'est
+// réécrit qu'une fois, après confirmation.
+func setupCmd() {
+	fmt.Println(i18n.T("setup.heading"))
+
+	created, err := config.CreateConfigFileIfNotExists()
+	if err != nil {
+		fmt.Printf(i18n.T("setup.config_error"), err)
+		os.Exit(1)
+	}
+	current, err := config.ReadConfigValues()
+	if err != nil {
+		fmt.Printf(i18n.T("setup.config_error"), err)
+		os.Exit(1)
+	}
+	if created {
+		fmt.Printf(i18n.T("setup.config_created"), config.ConfigFilename)
+	} else {
+		fmt.Printf(i18n.T("setup.config_existing"), config.ConfigFilename)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	values := make(map[string]string)
+	var enabled []string
+
+	for _, ex := range setupExchanges {
+		fmt.Printf(i18n.T("setup.exchange_heading"), ex.name)
+
+		keyName, secretName := ex.name+"_API_KEY", ex.name+"_SECRET_KEY"
+		wasEnabled := current[keyName] != ""
+		if !askYesNo(reader, fmt.Sprintf(i18n.T("setup.ask_enable"), ex.name), wasEnabled) {
+			// Un exchange sans clé API est désactivé
+			if wasEnabled {
+				values[keyName], values[secretName] = "", ""
+				fmt.Printf(i18n.T("setup.exchange_disabled"), ex.name)
+			}
+			continue
+		}
+
+		if !setupKeys(reader, ex.name, current, values) {
+			if wasEnabled {
+				enabled = append(enabled, ex.name)
+			}
+			continue
+		}
+		setupTradingParams(reader, ex.name, ex.defaults, current, values)
+		enabled = append(enabled, ex.name)
+	}
+
+	if len(enabled) == 0 {
+		fmt.Println(i18n.T("setup.no_exchange"))
+	} else {
+		values["EXCHANGE"] = setupMainExchange(reader, enabled, current["EXCHANGE"])
+	}
+
+	if len(values) == 0 {
+		fmt.Println(i18n.T("setup.nothing_changed"))
+		return
+	}
+
+	fmt.Println(i18n.T("setup.summary_heading"))
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %s=%s\n", key, maskSetupValue(key, values[key]))
+	}
+
+	if !askYesNo(reader, fmt.Sprintf(i18n.T("setup.ask_save"), config.ConfigFilename), true) {
+		fmt.Println(i18n.T("setup.cancelled"))
+		return
+	}
+	if err := config.SetConfigValues(values); err != nil {
+		fmt.Printf(i18n.T("setup.config_error"), err)
+		os.Exit(1)
+	}
+	fmt.Printf(i18n.T("setup.saved"), config.ConfigFilename)
+
+	if len(enabled) > 0 && askYesNo(reader, i18n.T("setup.ask_task"), false) {
+		setupFirstTask(reader)
+	}
+
+	fmt.Println(i18n.T("setup.done"))
+}
+
+// setupKeys demande les clés API d'un exchange et les vérifie en direct avant de les retenir.
+// Une saisie vide conserve les clés déjà configurées, qui sont alors vérifiées à leur tour.
+// Retourne faux si aucune clé valide n'a été retenue: bot.conf garde alors ses valeurs.
+func setupKeys(reader *bufio.Reader, exchange string, current, values map[string]string) bool {
+	keyName, secretName := exchange+"_API_KEY", exchange+"_SECRET_KEY"
+
+	secretPrompt := secretName + ": "
+	if exchange == "KUCOIN" {
+		secretPrompt = "KUCOIN_SECRET_KEY (format SECRET_KEY:PassPhrase): "
+	}
+
+	for {
+		hasKeys := current[keyName] != "" && current[secretName] != ""
+		if hasKeys {
+			fmt.Println(i18n.T("setup.keys_keep"))
+		} else {
+			fmt.Println(i18n.T("setup.keys_hidden"))
+		}
+
+		apiKey, err := config.ReadSecretInput(keyName + ": ")
+		if err != nil {
+			fmt.Printf(i18n.T("setup.input_error"), err)
+			return false
+		}
+
+		entered := apiKey != ""
+		var secretKey string
+		if entered {
+			if secretKey, err = config.ReadSecretInput(secretPrompt); err != nil {
+				fmt.Printf(i18n.T("setup.input_error"), err)
+				return false
+			}
+		} else if hasKeys {
+			// Clés existantes: résoudre les références env: et keychain: pour les vérifier
+			apiKey, err = config.ResolveSecretValue(keyName, current[keyName])
+			if err == nil {
+				secretKey, err = config.ResolveSecretValue(secretName, current[secretName])
+			}
+			if err != nil {
+				fmt.Printf(i18n.T("setup.keys_invalid"), exchange, err)
+				if askYesNo(reader, i18n.T("setup.ask_retry_keys"), true) {
+					continue
+				}
+				fmt.Printf(i18n.T("setup.keys_unchanged"), exchange)
+				return false
+			}
+		}
+
+		if apiKey == "" || secretKey == "" {
+			fmt.Printf(i18n.T("setup.keys_missing"), exchange)
+			if askYesNo(reader, i18n.T("setup.ask_retry_keys"), true) {
+				continue
+			}
+			fmt.Printf(i18n.T("setup.keys_unchanged"), exchange)
+			return false
+		}
+
+		fmt.Printf(i18n.T("setup.keys_checking"), exchange)
+		if err := commands.CheckExchangeKeys(exchange, apiKey, secretKey); err != nil {
+			fmt.Printf(i18n.T("setup.keys_invalid"), exchange, err)
+			if askYesNo(reader, i18n.T("setup.ask_retry_keys"), true) {
+				continue
+			}
+			fmt.Printf(i18n.T("setup.keys_unchanged"), exchange)
+			return false
+		}
+		fmt.Printf(i18n.T("setup.keys_valid"), exchange)
+
+		if !entered {
+			return true
+		}
+
+		// Nouvelles clés: magasin d'identifiants du système si possible, sinon en clair dans bot.conf
+		if askYesNo(reader, i18n.T("setup.ask_keychain"), true) {
+			keyErr := config.WriteKeychain(keyName, apiKey)
+			if keyErr == nil {
+				keyErr = config.WriteKeychain(secretName, secretKey)
+			}
+			if keyErr == nil {
+				values[keyName], values[secretName] = config.KeychainReference(keyName), config.KeychainReference(secretName)
+				return true
+			}
+			fmt.Printf(i18n.T("setup.keychain_error"), keyErr)
+		}
+		values[keyName], values[secretName] = apiKey, secretKey
+		return true
+	}
+}
+
+// setupTradingParams demande les offsets et le pourcentage de capital d'un exchange, en
+// proposant la valeur de bot.conf ou, à défaut, le réglage conseillé pour l'exchange
+func setupTradingParams(reader *bufio.Reader, exchange string, defaults setupDefault, current, values map[string]string) {
+	fmt.Println(i18n.T("setup.params_explanation"))
+
+	params := []struct {
+		suffix   string
+		prompt   string
+		fallback float64
+		valid    func(float64) bool
+		invalid  string
+	}{
+		{"BUY_OFFSET", "setup.ask_buy_offset", defaults.buyOffset, func(v float64) bool { return v <= 0 }, "setup.invalid_buy_offset"},
+		{"SELL_OFFSET", "setup.ask_sell_offset", defaults.sellOffset, func(v float64) bool { return v >= 0 }, "setup.invalid_sell_offset"},
+		{"PERCENT", "setup.ask_percent", defaults.percent, func(v float64) bool { return v > 0 && v <= 100 }, "setup.invalid_percent"},
+	}
+
+	for _, param := range params {
+		key := exchange + "_" + param.suffix
+		def := param.fallback
+		if existing, err := strconv.ParseFloat(strings.TrimSpace(current[key]), 64); err == nil {
+			def = existing
+		}
+
+		for {
+			fmt.Printf(i18n.T(param.prompt), key, strconv.FormatFloat(def, 'f', -1, 64))
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(input)
+			if input == "" {
+				values[key] = strconv.FormatFloat(def, 'f', -1, 64)
+				break
+			}
+			value, err := strconv.ParseFloat(input, 64)
+			if err != nil || !param.valid(value) {
+				fmt.Println(i18n.T(param.invalid))
+				continue
+			}
+			values[key] = strconv.FormatFloat(value, 'f', -1, 64)
+			break
+		}
+	}
+}
+
+// setupMainExchange choisit l'exchange principal (EXCHANGE) parmi les exchanges activés
+func setupMainExchange(reader *bufio.Reader, enabled []string, current string) string {
+	def := enabled[0]
+	for _, ex := range enabled {
+		if strings.EqualFold(ex, current) {
+			def = ex
+		}
+	}
+	if len(enabled) == 1 {
+		return def
+	}
+
+	for {
+		fmt.Printf(i18n.T("setup.ask_main_exchange"), strings.Join(enabled, ", "), def)
+		input, _ := reader.ReadString('\n')
+		input = strings.ToUpper(strings.TrimSpace(input))
+		if input == "" {
+			return def
+		}
+		for _, ex := range enabled {
+			if ex == input {
+				return ex
+			}
+		}
+		fmt.Printf(i18n.T("setup.invalid_main_exchange"), input)
+	}
+}
+
+// setupFirstTask recharge la configuration enregistrée et propose une première tâche planifiée
+func setupFirstTask(reader *bufio.Reader) {
+	cfg, err := config.Reload()
+	if err != nil {
+		fmt.Printf(i18n.T("planner.config_load_error"), err)
+		return
+	}
+
+	sched := scheduler.NewScheduler(cfg, logger.NewLogger(logger.LogConfig{
+		Level:  "info",
+		Format: "text",
+	}))
+	if err := sched.LoadTasksFromConfig(); err != nil {
+		fmt.Printf(i18n.T("planner.tasks_load_error"), err)
+	}
+	addNewTaskInteractive(sched, reader)
+}
+
+// askYesNo pose une question fermée; une réponse vide retourne def
+func askYesNo(reader *bufio.Reader, prompt string, def bool) bool {
+	suffix := i18n.T("setup.yes_no_default_no")
+	if def {
+		suffix = i18n.T("setup.yes_no_default_yes")
+	}
+	fmt.Print(prompt + suffix)
+
+	response, _ := reader.ReadString('\n')
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "":
+		return def
+	case "o", "oui", "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// maskSetupValue masque les clés saisies en clair dans le récapitulatif
+func maskSetupValue(key, value string) string {
+	if value == "" || strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "keychain:") {
+		return value
+	}
+	if strings.HasSuffix(key, "_API_KEY") || strings.HasSuffix(key, "_SECRET_KEY") {
+		return "********"
+	}
+	return value
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"main/internal/fx"
+	"main/internal/i18n"
 	"main/internal/types"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -18,6 +22,33 @@ import (
 // ConfigFilename est le nom du fichier de configuration principal
 const ConfigFilename = "bot.conf"
 
+// maxLadderCount borne le nombre de tranches d'un achat échelonné (LADDER_COUNT)
+const maxLadderCount = 10
+
+// maxRecvWindowMs est la fenêtre de validité maximale des requêtes signées acceptée par Binance
+// et MEXC (<EXCHANGE>_RECV_WINDOW_MS)
+const maxRecvWindowMs = 60000
+
+// RecvWindowSupported indique si l'exchange accepte une fenêtre de validité des requêtes signées
+// (recvWindow): Binance et MEXC. KuCoin et Kraken appliquent la leur.
+func RecvWindowSupported(exchange string) bool {
+	return exchange == "BINANCE" || exchange == "MEXC"
+}
+
+// Événements pouvant déclencher une notification de bureau (DESKTOP_NOTIFY_EVENTS)
+const (
+	DesktopEventTaskFailed     = "task_failed"     // Échec d'une tâche planifiée
+	DesktopEventCycleCompleted = "cycle_completed" // Vente exécutée, cycle complété
+	DesktopEventLossLimit      = "loss_limit"      // Limite de pertes quotidienne atteinte
+)
+
+// Priorité entre l'offset en pourcentage et l'offset absolu quand les deux sont définis
+// (OFFSET_PRECEDENCE)
+const (
+	OffsetPrecedencePercent  = "percent"  // Offset en %, l'offset absolu sert de plancher
+	OffsetPrecedenceAbsolute = "absolute" // Offset absolu seul, le pourcentage est ignoré
+)
+
 type ExchangeConfig struct {
 	Name                   string
 	APIKey                 string
@@ -31,7 +62,119 @@ type ExchangeConfig struct {
 	SellAccuPriceDeviation float64 // Pourcentage de déviation pour l'accumulation
 	AdaptiveOrder          bool    // Activation du calcul adaptatif d'ordres
 	MinLockedRatio         float64 // Ratio minimal pour appliquer la formule adaptative
-	Enabled                bool
+	// Plafonds de l'accumulation: part du profit réalisé de l'exchange utilisable (100 = tout le profit)
+	// et valeur maximale d'une seule accumulation (0 = illimitée)
+	MaxAccumulationPercentOfProfit float64
+	MaxSingleAccumulationUSDC      float64
+	// Nombre de pannes consécutives (transport, HTTP 5xx, maintenance) avant de suspendre les appels à l'exchange (0 = désactivé)
+	CircuitBreakerThreshold int
+	// Écart en pourcentage appliqué au prix pour rester maker (0 = valeurs historiques)
+	MakerBufferPercent float64
+	// Limites d'exposition des cycles ouverts (0 = illimité)
+	MaxOpenExposureUSDC float64
+	MaxOpenCycles       int
+	// Ordres d'achat et de vente en post-only, repoussés d'un tick s'ils seraient exécutés immédiatement
+	PostOnly        bool
+	PostOnlyRetries int
+	// Au seuil BuyMaxPriceDeviation, replacer l'achat sous le prix actuel au lieu d'annuler le cycle
+	RepriceInsteadOfCancel bool
+	MaxReprices            int // Nombre maximal de replacements par cycle avant annulation
+	// Transmettre BuyMaxDays à l'exchange comme date d'expiration des achats, s'il la supporte
+	UseExchangeExpiry bool
+	// Vente en OCO (limite + stop-limit de protection) sur les exchanges qui la supportent (Binance)
+	UseOCO              bool
+	OCOStopLossPercent  float64 // Déclenchement du stop, en % sous le prix d'achat
+	OCOStopLimitPercent float64 // Limite du stop, en % sous le prix de déclenchement
+	// Taux de frais du compte (0.001 = 0.1%), utilisés pour les estimations quand les frais réels sont inconnus
+	MakerFeeRate float64
+	TakerFeeRate float64
+	// Lire le niveau de frais réel du compte au démarrage, sur les exchanges qui l'exposent (Binance, Kraken)
+	FetchFeeRates bool
+	// Pertes réalisées maximales sur la journée UTC avant de suspendre les nouveaux ordres (0 = désactivé)
+	DailyMaxLossUSDC float64
+	// Achat d'un nouveau cycle réparti en LadderCount tranches, chacune LadderStepPercent % sous la précédente
+	LadderCount       int
+	LadderStepPercent float64
+	// Écart maximal (en %) entre le prix de vente et la meilleure vente du carnet avant de le
+	// rapprocher du marché, frais toujours couverts (0 = désactivé)
+	SellMaxAboveAskPercent float64
+	// Prix à partir duquel le BTC accumulé est revendu (absolu, ou en % au-dessus du prix de
+	// vente d'origine de l'accumulation; zéro = désactivé)
+	AccuSellTriggerPrice PriceTrigger
+	// Profit net minimum d'un cycle, en USDC et en % du montant d'achat (le plus élevé s'applique,
+	// 0 = désactivé), et relèvement maximal du prix de vente pour l'atteindre (en % du prix d'achat)
+	MinProfitUSDC             float64
+	MinProfitPercent          float64
+	MinProfitMaxMarkupPercent float64
+	// Offsets en % du prix (0 = désactivés): le plus grand de l'offset en % et de l'offset absolu
+	// s'applique, selon OffsetPrecedence quand les deux sont définis
+	BuyOffsetPercent  float64
+	SellOffsetPercent float64
+	OffsetPrecedence  string
+	// Soldes jamais engagés par le bot (fonds détenus hors du bot sur le même compte): le solde
+	// utilisable est le solde libre diminué de la réserve
+	ReserveBTC  float64
+	ReserveUSDC float64
+	// Délai des requêtes HTTP en secondes et fenêtre de validité des requêtes signées en
+	// millisecondes (Binance, MEXC); 0 = valeurs par défaut du client et de l'exchange
+	HTTPTimeoutSeconds int
+	RecvWindowMs       int
+	Enabled            bool
+}
+
+// PriceTrigger est un seuil de prix exprimé en valeur absolue (95000) ou en pourcentage
+// au-dessus d'un prix de référence (5%)
+type PriceTrigger struct {
+	Value   float64
+	Percent bool
+}
+
+// Price retourne le prix de déclenchement pour le prix de référence donné (0 = désactivé)
+func (t PriceTrigger) Price(reference float64) float64 {
+	if t.Value <= 0 {
+		return 0
+	}
+	if t.Percent {
+		return reference * (1 + t.Value/100)
+	}
+	return t.Value
+}
+
+// String retourne le seuil sous la forme acceptée dans bot.conf
+func (t PriceTrigger) String() string {
+	value := strconv.FormatFloat(t.Value, 'f', -1, 64)
+	if t.Percent {
+		return value + "%"
+	}
+	return value
+}
+
+// ParsePriceTrigger lit un seuil de prix absolu (95000) ou relatif (5%)
+func ParsePriceTrigger(value string) (PriceTrigger, error) {
+	value = strings.TrimSpace(value)
+	trigger := PriceTrigger{Percent: strings.HasSuffix(value, "%")}
+	number, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+	if err != nil {
+		return PriceTrigger{}, fmt.Errorf("invalid price trigger %q", value)
+	}
+	trigger.Value = number
+	return trigger, nil
+}
+
+// defaultFeeRates contient les taux maker et taker du niveau de base de chaque exchange
+var defaultFeeRates = map[string][2]float64{
+	"BINANCE": {0.001, 0.001},
+	"MEXC":    {0, 0.0005},
+	"KUCOIN":  {0.001, 0.001},
+	"KRAKEN":  {0.0026, 0.004},
+}
+
+// DefaultFeeRates retourne les taux maker et taker du niveau de base d'un exchange (0.1% si inconnu)
+func DefaultFeeRates(exchange string) (maker, taker float64) {
+	if rates, ok := defaultFeeRates[strings.ToUpper(exchange)]; ok {
+		return rates[0], rates[1]
+	}
+	return 0.001, 0.001
 }
 
 // Config contient toutes les configurations de l'application
@@ -41,25 +184,283 @@ type Config struct {
 	Exchanges        map[string]ExchangeConfig
 
 	// Paramètres globaux par défaut
-	DefaultPercent                float64
-	DefaultBuyMaxDays             int
-	DefaultBuyMaxPriceDeviation   float64
-	DefaultAccumulation           bool    // Valeur par défaut pour l'accumulation
-	DefaultSellAccuPriceDeviation float64 // Valeur par défaut pour la déviation d'accumulation
-	DefaultAdaptiveOrder          bool
-	DefaultMinLockedRatio         float64
+	DefaultPercent                 float64
+	DefaultBuyMaxDays              int
+	DefaultBuyMaxPriceDeviation    float64
+	DefaultAccumulation            bool    // Valeur par défaut pour l'accumulation
+	DefaultSellAccuPriceDeviation  float64 // Valeur par défaut pour la déviation d'accumulation
+	DefaultAdaptiveOrder           bool
+	DefaultMinLockedRatio          float64
+	DefaultCircuitBreakerThreshold int
+	DefaultMakerBufferPercent      float64
+	DefaultMaxOpenExposureUSDC     float64
+	DefaultMaxOpenCycles           int
+	DefaultPostOnly                bool
+	DefaultPostOnlyRetries         int
+	DefaultRepriceInsteadOfCancel  bool
+	DefaultMaxReprices             int
+	DefaultUseExchangeExpiry       bool
+	DefaultUseOCO                  bool
+	DefaultOCOStopLossPercent      float64
+	DefaultOCOStopLimitPercent     float64
+	DefaultFetchFeeRates           bool
+	DefaultDailyMaxLossUSDC        float64
+	// Plafonds par défaut de l'accumulation
+	DefaultMaxAccumulationPercentOfProfit float64
+	DefaultMaxSingleAccumulationUSDC      float64
+	// Répartition par défaut de l'achat en tranches
+	DefaultLadderCount       int
+	DefaultLadderStepPercent float64
+	// Écart maximal par défaut entre le prix de vente et la meilleure vente du carnet
+	DefaultSellMaxAboveAskPercent float64
+	// Seuil par défaut de revente du BTC accumulé
+	DefaultAccuSellTriggerPrice PriceTrigger
+	// Profit net minimum par défaut et relèvement maximal du prix de vente pour l'atteindre
+	DefaultMinProfitUSDC             float64
+	DefaultMinProfitPercent          float64
+	DefaultMinProfitMaxMarkupPercent float64
+
+	// Pertes réalisées maximales sur la journée UTC, tous exchanges confondus (0 = désactivé)
+	DailyMaxLossUSDC float64
+
+	// Écart maximal (en %) entre le prix BTC d'un exchange et la médiane des autres exchanges, ou
+	// son dernier prix fiable, avant de l'ignorer pour l'exécution (0 = désactivé)
+	PriceSanityMaxDeviation float64
+	// Conserver le dernier prix fiable de chaque exchange entre les exécutions
+	PriceSanityPersist bool
+
+	// Paramètres des serveurs web (tableau de bord et statistiques)
+	ServerAddr  string // Adresse d'écoute des serveurs (localhost par défaut)
+	ServerPort  int    // Port du tableau de bord
+	StatsPort   int    // Port du serveur de statistiques
+	TLSCertFile string // Chemin du certificat TLS (optionnel)
+	TLSKeyFile  string // Chemin de la clé privée TLS (optionnel)
+	AuthToken   string // Jeton partagé protégeant l'accès aux serveurs web
+	// Laisse les pages en lecture accessibles sans jeton (les actions restent protégées)
+	AuthPublicRead bool
+	// Tableau de bord en lecture seule: actions masquées et requêtes de modification refusées (403)
+	ServerReadOnly bool
+	// Nombre de cycles affichés par page sur le tableau de bord
+	DashboardPageSize int
+	// Intervalle de rafraîchissement automatique du tableau de bord en secondes (0 = désactivé)
+	DashboardRefreshSeconds int
+	// Devise d'affichage des profits à côté de l'USDC (vide = USDC seulement) et source des taux
+	DisplayCurrency string
+	FXSourceURL     string
+
+	// Instantanés du portefeuille (courbe de valeur du compte sur le serveur de statistiques)
+	SnapshotOnUpdate bool // Enregistrer un instantané à la fin de chaque mise à jour
+	// Au-delà de ce nombre de jours, un seul instantané par jour est conservé (0 = tout garder)
+	SnapshotFullResolutionDays int
+
+	// Historique du prix du BTC par exchange (graphique du tableau de bord): un point au plus
+	// toutes les PriceHistoryIntervalMinutes, conservé PriceHistoryRetentionHours (0 = désactivé)
+	PriceHistoryIntervalMinutes int
+	PriceHistoryRetentionHours  int
+
+	// Sauvegardes automatiques de la base de données faites à l'ouverture, avant toute écriture,
+	// et utilisées pour récupérer une base corrompue (nombre conservé, 0 = désactivées)
+	DatabaseBackupCount int
+	// Réponses brutes des ordres exécutées conservées sur les cycles pour audit: --archive les
+	// supprime des cycles créés il y a plus de ce nombre de jours (0 = tout garder)
+	OrderSnapshotRetentionDays int
+
+	// Paire négociée sur Kraken (XBT/USDC, XBT/USDT), résolue auprès d'AssetPairs au démarrage
+	KrakenPair string
+
+	// Notifications webhook (JSON signé HMAC envoyé à chaque événement de trading)
+	WebhookURLs   []string // URLs appelées en POST
+	WebhookEvents []string // Événements transmis (vide = tous)
+	WebhookSecret string   // Clé de signature HMAC-SHA256 (en-tête X-Bot-Signature)
+	// Échecs de livraison consécutifs avant de désactiver une URL (0 = jamais désactivée)
+	WebhookMaxFailures int
+
+	// Commandes externes lancées à chaque événement de trading, l'événement en JSON sur l'entrée standard
+	Hooks []Hook
+
+	// Notifications de bureau (toast Windows) pour les événements du planificateur
+	DesktopNotifications bool
+	DesktopNotifyEvents  []string // Événements affichés (vide = tous)
+
+	// Notifications Web Push envoyées aux navigateurs abonnés depuis le tableau de bord
+	WebPush WebPush
+
+	// Langue des messages et des pages web (fr, en)
+	Language string
 
 	// Autres paramètres potentiels
-	Environment string
-	LogLevel    string
+	Environment    string
+	LogLevel       string
+	LogFormat      string // Format des logs de trading: text (couleurs) ou json
+	DaemonLogLevel string // Niveau de log des commandes lancées par le planificateur
+	// Nombre d'exécutions de tâches conservées dans l'historique du planificateur
+	SchedulerHistorySize int
+	// Consommation de la limite de requêtes d'un exchange (en %) signalée en fin de mise à jour
+	RateLimitWarnPercent float64
+
+	// Erreurs et avertissements relevés par la dernière validation
+	problems []Problem
+}
+
+// Configuration partagée, chargée une seule fois par Get()
+var (
+	sharedMu     sync.Mutex
+	sharedOnce   sync.Once
+	sharedConfig *Config
+	sharedErr    error
+
+	// Variables définies par bot.conf (et non par l'environnement du processus),
+	// avec la valeur lue, pour pouvoir les rafraîchir lors d'un Reload()
+	envFromFile = make(map[string]string)
+)
+
+// Get retourne la configuration partagée. bot.conf n'est lu qu'au premier appel;
+// utilisez Reload() pour prendre en compte une modification du fichier.
+func Get() (*Config, error) {
+	sharedOnce.Do(func() {
+		cfg, err := LoadConfig()
+		sharedMu.Lock()
+		sharedConfig, sharedErr = cfg, err
+		sharedMu.Unlock()
+	})
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	return sharedConfig, sharedErr
 }
 
-// LoadConfig charge la configuration depuis le fichier et l'environnement
+// Set remplace la configuration partagée sans lire bot.conf (tests, simulations)
+func Set(c *Config) {
+	sharedOnce.Do(func() {})
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	sharedConfig, sharedErr = c, nil
+}
+
+// Reload relit bot.conf et remplace la configuration partagée. En cas d'erreur,
+// la configuration précédente est conservée.
+func Reload() (*Config, error) {
+	// Les appels suivants à Get() ne doivent plus déclencher de chargement
+	sharedOnce.Do(func() {})
+
+	cfg, err := LoadConfig()
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if err != nil {
+		if sharedConfig == nil {
+			sharedErr = err
+		}
+		return sharedConfig, err
+	}
+	sharedConfig, sharedErr = cfg, nil
+	return cfg, nil
+}
+
+// loadEnvFile charge bot.conf dans l'environnement. Comme godotenv.Load, les
+// variables déjà définies par le processus restent prioritaires; les valeurs
+// issues d'un chargement précédent du fichier sont en revanche mises à jour.
+func loadEnvFile() error {
+	values, err := godotenv.Read(Path(ConfigFilename))
+	if err != nil {
+		return err
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	// Retirer les variables supprimées du fichier depuis le dernier chargement
+	for key, loaded := range envFromFile {
+		if _, stillDefined := values[key]; !stillDefined {
+			if current, _ := os.LookupEnv(key); current == loaded {
+				os.Unsetenv(key)
+			}
+			delete(envFromFile, key)
+		}
+	}
+
+	for key, value := range values {
+		current, exists := os.LookupEnv(key)
+		loaded, fromFile := envFromFile[key]
+		// Une variable définie ou modifiée en dehors du fichier est prioritaire
+		if exists && (!fromFile || current != loaded) {
+			continue
+		}
+		os.Setenv(key, value)
+		envFromFile[key] = value
+	}
+
+	return nil
+}
+
+// LanguageSetting retourne la langue configurée (LANGUAGE de l'environnement ou de bot.conf)
+// sans valider le reste de la configuration, pour traduire les messages dès le démarrage
+func LanguageSetting() string {
+	if lang := os.Getenv("LANGUAGE"); lang != "" {
+		return lang
+	}
+	if values, err := godotenv.Read(Path(ConfigFilename)); err == nil && values["LANGUAGE"] != "" {
+		return values["LANGUAGE"]
+	}
+	return i18n.DefaultLanguage
+}
+
+// AliasSettings retourne les alias de commandes définis par les clés ALIAS_NOM de bot.conf ou de
+// l'environnement (ALIAS_NB=new --exchange binance définit l'alias nb), sans valider le reste de
+// la configuration. L'environnement l'emporte sur le fichier.
+func AliasSettings() map[string]string {
+	values, err := godotenv.Read(Path(ConfigFilename))
+	if err != nil {
+		values = make(map[string]string)
+	}
+	for _, variable := range os.Environ() {
+		if key, value, ok := strings.Cut(variable, "="); ok && strings.HasPrefix(key, "ALIAS_") {
+			values[key] = value
+		}
+	}
+	return parseAliases(values)
+}
+
+// parseAliases extrait les alias des clés ALIAS_NOM, le nom de l'alias étant en minuscules
+func parseAliases(values map[string]string) map[string]string {
+	aliases := make(map[string]string)
+	for key, value := range values {
+		name, ok := strings.CutPrefix(key, "ALIAS_")
+		value = strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		aliases[strings.ToLower(name)] = value
+	}
+	return aliases
+}
+
+// LoadConfig charge la configuration depuis le fichier et l'environnement et affiche les
+// avertissements de validation. Préférez Get(), qui ne relit pas le fichier à chaque appel.
 func LoadConfig() (*Config, error) {
+	config, problems, err := loadConfig()
+	for _, problem := range problems {
+		if problem.Warning {
+			log.Printf("Warning: %s\n", problem)
+		}
+	}
+	return config, err
+}
+
+// CheckConfig charge la configuration sans rien afficher et retourne toutes les erreurs et tous
+// les avertissements relevés, avec leur ligne dans bot.conf (--validate-config)
+func CheckConfig() ([]Problem, error) {
+	_, problems, err := loadConfig()
+	return problems, err
+}
+
+// loadConfig charge et valide la configuration, et retourne les problèmes relevés
+func loadConfig() (*Config, []Problem, error) {
 	// S'assurer que le fichier de configuration existe
 	created, err := CreateConfigFileIfNotExists()
 	if err != nil {
-		return nil, fmt.Errorf("error creating config file: %w", err)
+		return nil, nil, fmt.Errorf("error creating config file: %w", err)
 	}
 
 	// Si le fichier vient d'être créé, informer l'utilisateur et sortir sans erreur
@@ -71,10 +472,11 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Charger le fichier de configuration
-	err = godotenv.Load(ConfigFilename)
+	err = loadEnvFile()
 	if err != nil {
-		return nil, fmt.Errorf("error loading config file: %w", err)
+		return nil, nil, fmt.Errorf("error loading config file: %w", err)
 	}
+	takeLoadWarnings()
 
 	// Exchanges supportés
 	supportedExchanges := []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"}
@@ -84,25 +486,103 @@ func LoadConfig() (*Config, error) {
 
 	// Récupérer les valeurs par défaut globales
 	defaultPercent := getEnvFloat("DEFAULT_PERCENT", 5)
+
+	// Offsets d'achat et de vente par défaut, absolus (USDC) et en % du prix (0 = désactivés)
+	defaultBuyOffset := getEnvFloat("DEFAULT_BUY_OFFSET", -700)
+	defaultSellOffset := getEnvFloat("DEFAULT_SELL_OFFSET", 700)
+	defaultBuyOffsetPercent := getEnvFloat("DEFAULT_BUY_OFFSET_PERCENT", 0)
+	defaultSellOffsetPercent := getEnvFloat("DEFAULT_SELL_OFFSET_PERCENT", 0)
+	defaultOffsetPrecedence := getEnvString("DEFAULT_OFFSET_PRECEDENCE", "")
 	defaultBuyMaxDays := getEnvInt("DEFAULT_BUY_MAX_DAYS", 0)
 	defaultBuyMaxPriceDeviation := getEnvFloat("DEFAULT_BUY_MAX_PRICE_DEVIATION", 0)
 
 	// Récupérer les valeurs par défaut pour l'accumulation
 	defaultAccumulation := getEnvBool("DEFAULT_ACCUMULATION", false)
 	defaultSellAccuPriceDeviation := getEnvFloat("DEFAULT_SELL_ACCU_PRICE_DEVIATION", 10.0)
+	defaultMaxAccumulationPercentOfProfit := getEnvFloat("DEFAULT_MAX_ACCUMULATION_PERCENT_OF_PROFIT", 100)
+	defaultMaxSingleAccumulationUSDC := getEnvFloat("DEFAULT_MAX_SINGLE_ACCUMULATION_USDC", 0)
 
 	// Récupérer les valeurs par défaut pour les ordres adaptatifs
 	defaultAdaptiveOrder := getEnvBool("DEFAULT_ADAPTIVE_ORDER", false)
 	defaultMinLockedRatio := getEnvFloat("DEFAULT_MIN_LOCKED_RATIO", 0.1)
 
+	// Seuil par défaut du disjoncteur par exchange
+	defaultCircuitBreakerThreshold := getEnvInt("DEFAULT_CIRCUIT_BREAKER_THRESHOLD", 3)
+
+	// Écart maker par défaut (0 = 0.1% pour le prix de vente minimum, 0.2% pour CreateMakerOrder)
+	defaultMakerBufferPercent := getEnvFloat("DEFAULT_MAKER_BUFFER_PERCENT", 0)
+
+	// Limites d'exposition par défaut des cycles ouverts (0 = illimité)
+	defaultMaxOpenExposureUSDC := getEnvFloat("DEFAULT_MAX_OPEN_EXPOSURE_USDC", 0)
+	defaultMaxOpenCycles := getEnvInt("DEFAULT_MAX_OPEN_CYCLES", 0)
+
+	// Ordres post-only par défaut et nombre de nouveaux essais un tick plus loin
+	defaultPostOnly := getEnvBool("DEFAULT_POST_ONLY", true)
+	defaultPostOnlyRetries := getEnvInt("DEFAULT_POST_ONLY_RETRIES", 3)
+
+	// Replacement des achats trop éloignés du prix au lieu de leur annulation
+	defaultRepriceInsteadOfCancel := getEnvBool("DEFAULT_REPRICE_INSTEAD_OF_CANCEL", false)
+	defaultMaxReprices := getEnvInt("DEFAULT_MAX_REPRICES", 3)
+
+	// Expiration des achats gérée par l'exchange (good-till-date)
+	defaultUseExchangeExpiry := getEnvBool("DEFAULT_USE_EXCHANGE_EXPIRY", false)
+
+	// Ventes OCO protégées par un stop-limit
+	defaultUseOCO := getEnvBool("DEFAULT_USE_OCO", false)
+	defaultOCOStopLossPercent := getEnvFloat("DEFAULT_OCO_STOP_LOSS_PERCENT", 5)
+	defaultOCOStopLimitPercent := getEnvFloat("DEFAULT_OCO_STOP_LIMIT_PERCENT", 0.5)
+
+	// Lecture du niveau de frais réel du compte au démarrage
+	defaultFetchFeeRates := getEnvBool("DEFAULT_FETCH_FEE_RATES", false)
+
+	// Limite quotidienne de pertes réalisées par exchange (0 = désactivée)
+	defaultDailyMaxLossUSDC := getEnvFloat("DEFAULT_DAILY_MAX_LOSS_USDC", 0)
+
+	// Achat en tranches échelonnées (1 = un seul ordre)
+	defaultLadderCount := getEnvInt("DEFAULT_LADDER_COUNT", 1)
+	defaultLadderStepPercent := getEnvFloat("DEFAULT_LADDER_STEP_PERCENT", 0.5)
+
+	// Prix de vente rapproché de la meilleure vente du carnet au-delà de cet écart (0 = désactivé)
+	defaultSellMaxAboveAskPercent := getEnvFloat("DEFAULT_SELL_MAX_ABOVE_ASK_PERCENT", 0)
+
+	// Revente du BTC accumulé au-delà d'un prix absolu ou d'un % au-dessus de la vente d'origine (0 = désactivée)
+	defaultAccuSellTriggerPrice := getEnvPriceTrigger("DEFAULT_ACCU_SELL_TRIGGER_PRICE", PriceTrigger{})
+
+	// Profit net minimum d'un cycle (0 = désactivé) et relèvement maximal du prix de vente
+	defaultMinProfitUSDC := getEnvFloat("DEFAULT_MIN_PROFIT_USDC", 0)
+	defaultMinProfitPercent := getEnvFloat("DEFAULT_MIN_PROFIT_PERCENT", 0)
+	defaultMinProfitMaxMarkupPercent := getEnvFloat("DEFAULT_MIN_PROFIT_MAX_MARKUP_PERCENT", 5)
+
 	for _, ex := range supportedExchanges {
+		// Les clés peuvent référencer une variable d'environnement (env:NOM) ou le magasin d'identifiants (keychain:NOM)
+		apiKey, err := resolveSecret(fmt.Sprintf("%s_API_KEY", ex))
+		if err != nil {
+			return nil, nil, err
+		}
+		secretKey, err := resolveSecret(fmt.Sprintf("%s_SECRET_KEY", ex))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		defaultMakerFeeRate, defaultTakerFeeRate := DefaultFeeRates(ex)
+
+		buyOffsetPercent := getEnvFloat(fmt.Sprintf("%s_BUY_OFFSET_PERCENT", ex), defaultBuyOffsetPercent)
+		sellOffsetPercent := getEnvFloat(fmt.Sprintf("%s_SELL_OFFSET_PERCENT", ex), defaultSellOffsetPercent)
+
 		// Récupérer les paramètres spécifiques à l'exchange, avec repli sur les valeurs par défaut
 		exchangeConfigs[ex] = ExchangeConfig{
 			Name:       ex,
-			APIKey:     getEnvString(fmt.Sprintf("%s_API_KEY", ex), ""),
-			SecretKey:  getEnvString(fmt.Sprintf("%s_SECRET_KEY", ex), ""),
-			BuyOffset:  getEnvFloat(fmt.Sprintf("%s_BUY_OFFSET", ex), -700),
-			SellOffset: getEnvFloat(fmt.Sprintf("%s_SELL_OFFSET", ex), 700),
+			APIKey:     apiKey,
+			SecretKey:  secretKey,
+			BuyOffset:  absoluteOffset(ex, "BUY_OFFSET", defaultBuyOffset, buyOffsetPercent),
+			SellOffset: absoluteOffset(ex, "SELL_OFFSET", defaultSellOffset, sellOffsetPercent),
+
+			BuyOffsetPercent:  buyOffsetPercent,
+			SellOffsetPercent: sellOffsetPercent,
+			OffsetPrecedence: strings.ToLower(getEnvString(
+				fmt.Sprintf("%s_OFFSET_PRECEDENCE", ex),
+				defaultOffsetPrecedence,
+			)),
 
 			// Utiliser les paramètres spécifiques de l'exchange ou les valeurs par défaut
 			Percent:    getEnvFloat(fmt.Sprintf("%s_PERCENT", ex), defaultPercent),
@@ -121,6 +601,14 @@ func LoadConfig() (*Config, error) {
 				fmt.Sprintf("%s_SELL_ACCU_PRICE_DEVIATION", ex),
 				defaultSellAccuPriceDeviation,
 			),
+			MaxAccumulationPercentOfProfit: getEnvFloat(
+				fmt.Sprintf("%s_MAX_ACCUMULATION_PERCENT_OF_PROFIT", ex),
+				defaultMaxAccumulationPercentOfProfit,
+			),
+			MaxSingleAccumulationUSDC: getEnvFloat(
+				fmt.Sprintf("%s_MAX_SINGLE_ACCUMULATION_USDC", ex),
+				defaultMaxSingleAccumulationUSDC,
+			),
 
 			// Nouveaux paramètres pour le calcul adaptatif des ordres
 			AdaptiveOrder: getEnvBool(
@@ -132,13 +620,120 @@ func LoadConfig() (*Config, error) {
 				defaultMinLockedRatio,
 			),
 
-			Enabled: getEnvString(fmt.Sprintf("%s_API_KEY", ex), "") != "",
+			CircuitBreakerThreshold: getEnvInt(
+				fmt.Sprintf("%s_CIRCUIT_BREAKER_THRESHOLD", ex),
+				defaultCircuitBreakerThreshold,
+			),
+
+			MakerBufferPercent: getEnvFloat(
+				fmt.Sprintf("%s_MAKER_BUFFER_PERCENT", ex),
+				defaultMakerBufferPercent,
+			),
+
+			MaxOpenExposureUSDC: getEnvFloat(
+				fmt.Sprintf("%s_MAX_OPEN_EXPOSURE_USDC", ex),
+				defaultMaxOpenExposureUSDC,
+			),
+
+			MaxOpenCycles: getEnvInt(
+				fmt.Sprintf("%s_MAX_OPEN_CYCLES", ex),
+				defaultMaxOpenCycles,
+			),
+
+			PostOnly: getEnvBool(
+				fmt.Sprintf("%s_POST_ONLY", ex),
+				defaultPostOnly,
+			),
+			PostOnlyRetries: getEnvInt(
+				fmt.Sprintf("%s_POST_ONLY_RETRIES", ex),
+				defaultPostOnlyRetries,
+			),
+
+			RepriceInsteadOfCancel: getEnvBool(
+				fmt.Sprintf("%s_REPRICE_INSTEAD_OF_CANCEL", ex),
+				defaultRepriceInsteadOfCancel,
+			),
+			MaxReprices: getEnvInt(
+				fmt.Sprintf("%s_MAX_REPRICES", ex),
+				defaultMaxReprices,
+			),
+			UseExchangeExpiry: getEnvBool(
+				fmt.Sprintf("%s_USE_EXCHANGE_EXPIRY", ex),
+				defaultUseExchangeExpiry,
+			),
+
+			UseOCO: getEnvBool(fmt.Sprintf("%s_USE_OCO", ex), defaultUseOCO),
+			OCOStopLossPercent: getEnvFloat(
+				fmt.Sprintf("%s_OCO_STOP_LOSS_PERCENT", ex),
+				defaultOCOStopLossPercent,
+			),
+			OCOStopLimitPercent: getEnvFloat(
+				fmt.Sprintf("%s_OCO_STOP_LIMIT_PERCENT", ex),
+				defaultOCOStopLimitPercent,
+			),
+
+			// Taux de frais: niveau de base de l'exchange par défaut
+			MakerFeeRate:  getEnvFloat(fmt.Sprintf("%s_MAKER_FEE_RATE", ex), defaultMakerFeeRate),
+			TakerFeeRate:  getEnvFloat(fmt.Sprintf("%s_TAKER_FEE_RATE", ex), defaultTakerFeeRate),
+			FetchFeeRates: getEnvBool(fmt.Sprintf("%s_FETCH_FEE_RATES", ex), defaultFetchFeeRates),
+
+			DailyMaxLossUSDC: getEnvFloat(
+				fmt.Sprintf("%s_DAILY_MAX_LOSS_USDC", ex),
+				defaultDailyMaxLossUSDC,
+			),
+
+			LadderCount: getEnvInt(fmt.Sprintf("%s_LADDER_COUNT", ex), defaultLadderCount),
+			LadderStepPercent: getEnvFloat(
+				fmt.Sprintf("%s_LADDER_STEP_PERCENT", ex),
+				defaultLadderStepPercent,
+			),
+
+			SellMaxAboveAskPercent: getEnvFloat(
+				fmt.Sprintf("%s_SELL_MAX_ABOVE_ASK_PERCENT", ex),
+				defaultSellMaxAboveAskPercent,
+			),
+
+			AccuSellTriggerPrice: getEnvPriceTrigger(
+				fmt.Sprintf("%s_ACCU_SELL_TRIGGER_PRICE", ex),
+				defaultAccuSellTriggerPrice,
+			),
+
+			MinProfitUSDC: getEnvFloat(
+				fmt.Sprintf("%s_MIN_PROFIT_USDC", ex),
+				defaultMinProfitUSDC,
+			),
+			MinProfitPercent: getEnvFloat(
+				fmt.Sprintf("%s_MIN_PROFIT_PERCENT", ex),
+				defaultMinProfitPercent,
+			),
+			MinProfitMaxMarkupPercent: getEnvFloat(
+				fmt.Sprintf("%s_MIN_PROFIT_MAX_MARKUP_PERCENT", ex),
+				defaultMinProfitMaxMarkupPercent,
+			),
+
+			ReserveBTC:  getEnvFloat(fmt.Sprintf("%s_RESERVE_BTC", ex), 0),
+			ReserveUSDC: getEnvFloat(fmt.Sprintf("%s_RESERVE_USDC", ex), 0),
+
+			HTTPTimeoutSeconds: getEnvInt(fmt.Sprintf("%s_HTTP_TIMEOUT_SECONDS", ex), 0),
+			RecvWindowMs:       getEnvInt(fmt.Sprintf("%s_RECV_WINDOW_MS", ex), 0),
+
+			Enabled: apiKey != "",
 		}
 	}
 
 	// Obtenir le nom de l'exchange principal
 	mainExchangeName := getEnvString("EXCHANGE", "BINANCE")
 
+	// La clé de signature des webhooks accepte aussi les références env: et keychain:
+	webhookSecret, err := resolveSecret("WEBHOOK_SECRET")
+	if err != nil {
+		return nil, nil, err
+	}
+	webPushKey, err := resolveSecret("WEB_PUSH_PRIVATE_KEY")
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Créer et valider la configuration
 	config := &Config{
 		MainExchangeName: strings.ToUpper(mainExchangeName),
@@ -153,69 +748,502 @@ func LoadConfig() (*Config, error) {
 		DefaultAdaptiveOrder:          defaultAdaptiveOrder,
 		DefaultMinLockedRatio:         defaultMinLockedRatio,
 
-		Environment: getEnvString("ENVIRONMENT", "production"),
-		LogLevel:    getEnvString("LOG_LEVEL", "info"),
+		DefaultCircuitBreakerThreshold: defaultCircuitBreakerThreshold,
+		DefaultMakerBufferPercent:      defaultMakerBufferPercent,
+		DefaultMaxOpenExposureUSDC:     defaultMaxOpenExposureUSDC,
+		DefaultMaxOpenCycles:           defaultMaxOpenCycles,
+		DefaultPostOnly:                defaultPostOnly,
+		DefaultPostOnlyRetries:         defaultPostOnlyRetries,
+		DefaultRepriceInsteadOfCancel:  defaultRepriceInsteadOfCancel,
+		DefaultMaxReprices:             defaultMaxReprices,
+		DefaultUseExchangeExpiry:       defaultUseExchangeExpiry,
+		DefaultUseOCO:                  defaultUseOCO,
+		DefaultOCOStopLossPercent:      defaultOCOStopLossPercent,
+		DefaultOCOStopLimitPercent:     defaultOCOStopLimitPercent,
+		DefaultFetchFeeRates:           defaultFetchFeeRates,
+		DefaultDailyMaxLossUSDC:        defaultDailyMaxLossUSDC,
+
+		DefaultMaxAccumulationPercentOfProfit: defaultMaxAccumulationPercentOfProfit,
+		DefaultMaxSingleAccumulationUSDC:      defaultMaxSingleAccumulationUSDC,
+
+		DefaultLadderCount:       defaultLadderCount,
+		DefaultLadderStepPercent: defaultLadderStepPercent,
+
+		DefaultSellMaxAboveAskPercent: defaultSellMaxAboveAskPercent,
+
+		DefaultAccuSellTriggerPrice: defaultAccuSellTriggerPrice,
+
+		DefaultMinProfitUSDC:             defaultMinProfitUSDC,
+		DefaultMinProfitPercent:          defaultMinProfitPercent,
+		DefaultMinProfitMaxMarkupPercent: defaultMinProfitMaxMarkupPercent,
+
+		DailyMaxLossUSDC: getEnvFloat("DAILY_MAX_LOSS_USDC", 0),
+
+		PriceSanityMaxDeviation: getEnvFloat("PRICE_SANITY_MAX_DEVIATION", 10),
+		PriceSanityPersist:      getEnvBool("PRICE_SANITY_PERSIST", true),
+
+		ServerAddr:  getEnvString("SERVER_ADDR", "localhost"),
+		ServerPort:  getEnvInt("SERVER_PORT", 8080),
+		StatsPort:   getEnvInt("STATS_PORT", 8081),
+		TLSCertFile: getEnvString("SERVER_TLS_CERT", ""),
+		TLSKeyFile:  getEnvString("SERVER_TLS_KEY", ""),
+		AuthToken:   getEnvString("SERVER_AUTH_TOKEN", ""),
+
+		AuthPublicRead: getEnvBool("SERVER_AUTH_PUBLIC_READ", false),
+
+		ServerReadOnly: getEnvBool("SERVER_READ_ONLY", false),
+
+		DashboardPageSize: getEnvInt("DASHBOARD_PAGE_SIZE", 50),
+
+		DashboardRefreshSeconds: getEnvInt("DASHBOARD_REFRESH_SECONDS", 30),
+
+		DisplayCurrency: strings.ToUpper(strings.TrimSpace(getEnvString("DISPLAY_CURRENCY", ""))),
+		FXSourceURL:     getEnvString("FX_SOURCE_URL", fx.DefaultSourceURL),
+
+		SnapshotOnUpdate:           getEnvBool("SNAPSHOT_ON_UPDATE", true),
+		SnapshotFullResolutionDays: getEnvInt("SNAPSHOT_FULL_RESOLUTION_DAYS", 90),
+
+		PriceHistoryIntervalMinutes: getEnvInt("PRICE_HISTORY_INTERVAL_MINUTES", 5),
+		PriceHistoryRetentionHours:  getEnvInt("PRICE_HISTORY_RETENTION_HOURS", 168),
+
+		DatabaseBackupCount: getEnvInt("DB_BACKUP_COUNT", 5),
+
+		OrderSnapshotRetentionDays: getEnvInt("ORDER_SNAPSHOT_RETENTION_DAYS", 0),
+
+		KrakenPair: strings.ToUpper(getEnvString("KRAKEN_PAIR", "XBT/USDC")),
+
+		WebhookURLs:        getEnvList("WEBHOOK_URLS"),
+		WebhookEvents:      getEnvList("WEBHOOK_EVENTS"),
+		WebhookSecret:      webhookSecret,
+		WebhookMaxFailures: getEnvInt("WEBHOOK_MAX_FAILURES", 5),
+
+		Hooks: loadHooks(),
+
+		DesktopNotifications: getEnvBool("DESKTOP_NOTIFICATIONS", false),
+		DesktopNotifyEvents:  getEnvList("DESKTOP_NOTIFY_EVENTS"),
+
+		WebPush: WebPush{
+			PublicKey:  getEnvString("WEB_PUSH_PUBLIC_KEY", ""),
+			PrivateKey: webPushKey,
+			Subject:    getEnvString("WEB_PUSH_SUBJECT", ""),
+			Events:     getEnvList("WEB_PUSH_EVENTS"),
+		},
+
+		Language: i18n.Normalize(getEnvString("LANGUAGE", i18n.DefaultLanguage)),
+
+		Environment:    getEnvString("ENVIRONMENT", "production"),
+		LogLevel:       getEnvString("LOG_LEVEL", "info"),
+		LogFormat:      strings.ToLower(getEnvString("LOG_FORMAT", "text")),
+		DaemonLogLevel: getEnvString("DAEMON_LOG_LEVEL", "warn"),
+
+		SchedulerHistorySize: getEnvInt("SCHEDULER_HISTORY_SIZE", 200),
+		RateLimitWarnPercent: getEnvFloat("RATE_LIMIT_WARN_PERCENT", 80),
 	}
 
-	// Validation de base
-	if err := config.Validate(); err != nil {
-		return nil, err
+	// Validation de base, puis recherche des clés inconnues (fautes de frappe) de bot.conf
+	config.Validate()
+	config.problems = append(takeLoadWarnings(), config.problems...)
+	if values, err := godotenv.Read(Path(ConfigFilename)); err == nil {
+		config.checkUnknownKeys(values)
 	}
+	locateProblems(config.problems)
 
-	return config, nil
+	if err := config.validationError(); err != nil {
+		return nil, config.problems, err
+	}
+	return config, config.problems, nil
 }
 
-// Validate vérifie que la configuration est valide
+// Validate vérifie que la configuration est valide. Les valeurs hors limites sont corrigées avec
+// un avertissement; toutes les erreurs sont relevées avant de renvoyer une ValidationError.
+// Problems() retourne ensuite les erreurs et les avertissements.
 func (c *Config) Validate() error {
+	c.problems = nil
+
 	// Validation de l'exchange principal
 	c.MainExchangeName = strings.ToUpper(c.MainExchangeName)
 
 	// Vérifier que l'exchange principal est valide et a une configuration
 	mainExchangeConfig, exists := c.Exchanges[c.MainExchangeName]
 	if !exists {
-		log.Printf("Warning: Exchange %s is not supported, using BINANCE\n", c.MainExchangeName)
+		c.warnf("EXCHANGE=%s is not supported, using BINANCE", c.MainExchangeName)
 		c.MainExchangeName = "BINANCE"
 		mainExchangeConfig = c.Exchanges["BINANCE"]
 	}
 
 	// Validation des clés API de l'exchange principal
 	if mainExchangeConfig.APIKey == "" || mainExchangeConfig.SecretKey == "" {
-		return fmt.Errorf("%s_API_KEY and %s_SECRET_KEY are required", c.MainExchangeName, c.MainExchangeName)
+		c.errorf("%s_API_KEY and %s_SECRET_KEY are required", c.MainExchangeName, c.MainExchangeName)
 	}
 
-	// Validation des paramètres de trading pour chaque exchange
-	for name, exchange := range c.Exchanges {
+	// Validation des paramètres de trading pour chaque exchange, dans un ordre stable
+	names := make([]string, 0, len(c.Exchanges))
+	for name := range c.Exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		exchange := c.Exchanges[name]
+
+		// Un exchange activé a besoin de ses deux clés
+		if (exchange.APIKey == "") != (exchange.SecretKey == "") {
+			c.errorf("%s_API_KEY and %s_SECRET_KEY must be set together", name, name)
+		}
+		if name == "KUCOIN" && exchange.SecretKey != "" && !strings.Contains(exchange.SecretKey, ":") {
+			c.errorf("KUCOIN_SECRET_KEY must contain the passphrase: SECRET_KEY:PassPhrase")
+		}
+
 		// Vérifier les paramètres de pourcentage
 		if exchange.Percent <= 0 || exchange.Percent > 100 {
-			return fmt.Errorf("%s_PERCENT must be between 0 and 100", name)
+			c.errorf("%s_PERCENT must be between 0 and 100", name)
 		}
 
 		// Validation des paramètres d'annulation automatique
 		if exchange.BuyMaxDays < 0 {
-			log.Printf("Warning: %s_BUY_MAX_DAYS cannot be negative, setting to 0 (disabled)\n", name)
+			c.warnf("%s_BUY_MAX_DAYS cannot be negative, setting to 0 (disabled)", name)
 			exchange.BuyMaxDays = 0
 		}
 
 		if exchange.BuyMaxPriceDeviation < 0 {
-			log.Printf("Warning: %s_BUY_MAX_PRICE_DEVIATION cannot be negative, setting to 0 (disabled)\n", name)
+			c.warnf("%s_BUY_MAX_PRICE_DEVIATION cannot be negative, setting to 0 (disabled)", name)
 			exchange.BuyMaxPriceDeviation = 0
 		}
 
 		// Validation des paramètres d'accumulation
 		if exchange.SellAccuPriceDeviation < 0 {
-			log.Printf("Warning: %s_SELL_ACCU_PRICE_DEVIATION cannot be negative, setting to 10 (default)\n", name)
+			c.warnf("%s_SELL_ACCU_PRICE_DEVIATION cannot be negative, setting to 10 (default)", name)
 			exchange.SellAccuPriceDeviation = 10.0
 		}
+		if exchange.MaxAccumulationPercentOfProfit <= 0 || exchange.MaxAccumulationPercentOfProfit > 100 {
+			c.warnf("%s_MAX_ACCUMULATION_PERCENT_OF_PROFIT must be between 0 and 100, setting to 100 (default)", name)
+			exchange.MaxAccumulationPercentOfProfit = 100
+		}
+		if exchange.MaxSingleAccumulationUSDC < 0 {
+			c.warnf("%s_MAX_SINGLE_ACCUMULATION_USDC cannot be negative, setting to 0 (unlimited)", name)
+			exchange.MaxSingleAccumulationUSDC = 0
+		}
 
-		// Ajuster les offsets
+		if exchange.CircuitBreakerThreshold < 0 {
+			c.warnf("%s_CIRCUIT_BREAKER_THRESHOLD cannot be negative, setting to 0 (disabled)", name)
+			exchange.CircuitBreakerThreshold = 0
+		}
+
+		if exchange.MakerBufferPercent < 0 || exchange.MakerBufferPercent >= 100 {
+			c.warnf("%s_MAKER_BUFFER_PERCENT must be between 0 and 100, setting to 0 (default)", name)
+			exchange.MakerBufferPercent = 0
+		}
+
+		if exchange.MaxOpenExposureUSDC < 0 {
+			c.warnf("%s_MAX_OPEN_EXPOSURE_USDC cannot be negative, setting to 0 (unlimited)", name)
+			exchange.MaxOpenExposureUSDC = 0
+		}
+
+		if exchange.MaxOpenCycles < 0 {
+			c.warnf("%s_MAX_OPEN_CYCLES cannot be negative, setting to 0 (unlimited)", name)
+			exchange.MaxOpenCycles = 0
+		}
+
+		if exchange.PostOnlyRetries < 0 {
+			c.warnf("%s_POST_ONLY_RETRIES cannot be negative, setting to 3 (default)", name)
+			exchange.PostOnlyRetries = 3
+		}
+
+		if exchange.MaxReprices < 0 {
+			c.warnf("%s_MAX_REPRICES cannot be negative, setting to 0 (always cancel)", name)
+			exchange.MaxReprices = 0
+		}
+
+		if exchange.OCOStopLossPercent <= 0 || exchange.OCOStopLossPercent >= 100 {
+			c.warnf("%s_OCO_STOP_LOSS_PERCENT must be between 0 and 100, setting to 5 (default)", name)
+			exchange.OCOStopLossPercent = 5
+		}
+
+		if exchange.OCOStopLimitPercent < 0 || exchange.OCOStopLimitPercent >= 100 {
+			c.warnf("%s_OCO_STOP_LIMIT_PERCENT must be between 0 and 100, setting to 0.5 (default)", name)
+			exchange.OCOStopLimitPercent = 0.5
+		}
+
+		defaultMakerFeeRate, defaultTakerFeeRate := DefaultFeeRates(name)
+		// Un taux maker négatif est une remise (MEXC en verse sur certaines paires)
+		if exchange.MakerFeeRate <= -0.01 || exchange.MakerFeeRate >= 0.1 {
+			c.warnf("%s_MAKER_FEE_RATE must be between -0.01 and 0.1, setting to %g (default)", name, defaultMakerFeeRate)
+			exchange.MakerFeeRate = defaultMakerFeeRate
+		}
+		if exchange.TakerFeeRate < 0 || exchange.TakerFeeRate >= 0.1 {
+			c.warnf("%s_TAKER_FEE_RATE must be between 0 and 0.1, setting to %g (default)", name, defaultTakerFeeRate)
+			exchange.TakerFeeRate = defaultTakerFeeRate
+		}
+
+		if exchange.DailyMaxLossUSDC < 0 {
+			c.warnf("%s_DAILY_MAX_LOSS_USDC cannot be negative, setting to 0 (disabled)", name)
+			exchange.DailyMaxLossUSDC = 0
+		}
+
+		if exchange.LadderCount < 1 || exchange.LadderCount > maxLadderCount {
+			c.warnf("%s_LADDER_COUNT must be between 1 and %d, setting to 1 (single order)", name, maxLadderCount)
+			exchange.LadderCount = 1
+		}
+		if exchange.LadderStepPercent <= 0 || exchange.LadderStepPercent >= 100 {
+			c.warnf("%s_LADDER_STEP_PERCENT must be between 0 and 100, setting to 0.5 (default)", name)
+			exchange.LadderStepPercent = 0.5
+		}
+		if exchange.SellMaxAboveAskPercent < 0 {
+			c.warnf("%s_SELL_MAX_ABOVE_ASK_PERCENT cannot be negative, setting to 0 (disabled)", name)
+			exchange.SellMaxAboveAskPercent = 0
+		}
+		if exchange.AccuSellTriggerPrice.Value < 0 {
+			c.warnf("%s_ACCU_SELL_TRIGGER_PRICE cannot be negative, setting to 0 (disabled)", name)
+			exchange.AccuSellTriggerPrice = PriceTrigger{}
+		}
+		if exchange.MinProfitUSDC < 0 {
+			c.warnf("%s_MIN_PROFIT_USDC cannot be negative, setting to 0 (disabled)", name)
+			exchange.MinProfitUSDC = 0
+		}
+		if exchange.MinProfitPercent < 0 {
+			c.warnf("%s_MIN_PROFIT_PERCENT cannot be negative, setting to 0 (disabled)", name)
+			exchange.MinProfitPercent = 0
+		}
+		if exchange.MinProfitMaxMarkupPercent < 0 {
+			c.warnf("%s_MIN_PROFIT_MAX_MARKUP_PERCENT cannot be negative, setting to 0 (no markup)", name)
+			exchange.MinProfitMaxMarkupPercent = 0
+		}
+		if exchange.ReserveBTC < 0 {
+			c.warnf("%s_RESERVE_BTC cannot be negative, setting to 0 (no reserve)", name)
+			exchange.ReserveBTC = 0
+		}
+		if exchange.ReserveUSDC < 0 {
+			c.warnf("%s_RESERVE_USDC cannot be negative, setting to 0 (no reserve)", name)
+			exchange.ReserveUSDC = 0
+		}
+		if exchange.HTTPTimeoutSeconds < 0 {
+			c.warnf("%s_HTTP_TIMEOUT_SECONDS cannot be negative, setting to 0 (client default)", name)
+			exchange.HTTPTimeoutSeconds = 0
+		}
+		switch {
+		case exchange.RecvWindowMs < 0:
+			c.warnf("%s_RECV_WINDOW_MS cannot be negative, setting to 0 (exchange default)", name)
+			exchange.RecvWindowMs = 0
+		case exchange.RecvWindowMs > 0 && !RecvWindowSupported(name):
+			c.warnf("%s_RECV_WINDOW_MS has no effect: only Binance and MEXC accept a recvWindow", name)
+			exchange.RecvWindowMs = 0
+		case exchange.RecvWindowMs > maxRecvWindowMs:
+			c.warnf("%s_RECV_WINDOW_MS cannot exceed %d, setting to %d", name, maxRecvWindowMs, maxRecvWindowMs)
+			exchange.RecvWindowMs = maxRecvWindowMs
+		}
+
+		if exchange.RepriceInsteadOfCancel && exchange.BuyMaxPriceDeviation == 0 {
+			c.warnf("%s_REPRICE_INSTEAD_OF_CANCEL has no effect without %s_BUY_MAX_PRICE_DEVIATION", name, name)
+		}
+		if exchange.UseExchangeExpiry && exchange.BuyMaxDays == 0 {
+			c.warnf("%s_USE_EXCHANGE_EXPIRY has no effect without %s_BUY_MAX_DAYS", name, name)
+		}
+
+		// Ajuster les offsets: l'achat se place sous le marché, la vente au-dessus de l'achat
+		if exchange.BuyOffset > 0 {
+			c.warnf("%s_BUY_OFFSET must be negative (below the market price), using %g", name, -exchange.BuyOffset)
+		}
+		if exchange.BuyOffsetPercent > 0 {
+			c.warnf("%s_BUY_OFFSET_PERCENT must be negative (below the market price), using %g", name, -exchange.BuyOffsetPercent)
+		}
+		if exchange.SellOffsetPercent < 0 {
+			c.warnf("%s_SELL_OFFSET_PERCENT must be positive (above the purchase price), using %g", name, -exchange.SellOffsetPercent)
+		}
+		if exchange.SellOffset < 0 {
+			c.warnf("%s_SELL_OFFSET must be positive (above the purchase price), using %g", name, -exchange.SellOffset)
+		} else if exchange.SellOffset == 0 && exchange.SellOffsetPercent == 0 && exchange.Enabled {
+			c.warnf("%s_SELL_OFFSET is 0: cycles would sell at their purchase price and lose the fees", name)
+		}
 		exchange.BuyOffset = -math.Abs(exchange.BuyOffset)
 		exchange.SellOffset = math.Abs(exchange.SellOffset)
+		exchange.BuyOffsetPercent = -math.Abs(exchange.BuyOffsetPercent)
+		exchange.SellOffsetPercent = math.Abs(exchange.SellOffsetPercent)
+
+		// Offsets en %: sans priorité explicite, un offset absolu différent rend la configuration ambiguë
+		switch exchange.OffsetPrecedence {
+		case "", OffsetPrecedencePercent, OffsetPrecedenceAbsolute:
+		default:
+			c.errorf("%s_OFFSET_PRECEDENCE=%s is invalid (expected %s or %s)",
+				name, exchange.OffsetPrecedence, OffsetPrecedencePercent, OffsetPrecedenceAbsolute)
+		}
+		for _, offset := range []struct {
+			setting  string
+			percent  float64
+			absolute float64
+		}{
+			{"BUY_OFFSET", exchange.BuyOffsetPercent, exchange.BuyOffset},
+			{"SELL_OFFSET", exchange.SellOffsetPercent, exchange.SellOffset},
+		} {
+			if math.Abs(offset.percent) >= 50 {
+				c.errorf("%s_%s_PERCENT must be below 50%%, got %g", name, offset.setting, math.Abs(offset.percent))
+			}
+			if offset.percent != 0 && offset.absolute != 0 && exchange.OffsetPrecedence == "" && exchange.Enabled {
+				c.errorf("%s_%s_PERCENT and %s_%s are both set: add %s_OFFSET_PRECEDENCE=%s (absolute offset used as a floor) or %s (percentage ignored)",
+					name, offset.setting, name, offset.setting, name, OffsetPrecedencePercent, OffsetPrecedenceAbsolute)
+			}
+		}
+		if exchange.OffsetPrecedence == OffsetPrecedenceAbsolute {
+			exchange.BuyOffsetPercent, exchange.SellOffsetPercent = 0, 0
+		}
 
 		// Mettre à jour la configuration
 		c.Exchanges[name] = exchange
 	}
 
-	return nil
+	// Validation des paramètres des serveurs web
+	if c.ServerPort <= 0 || c.ServerPort > 65535 {
+		c.errorf("SERVER_PORT must be between 1 and 65535")
+	}
+	if c.StatsPort <= 0 || c.StatsPort > 65535 {
+		c.errorf("STATS_PORT must be between 1 and 65535")
+	}
+	if c.ServerPort == c.StatsPort {
+		c.errorf("SERVER_PORT and STATS_PORT must be different (both %d)", c.ServerPort)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		c.errorf("SERVER_TLS_CERT and SERVER_TLS_KEY must be set together")
+	}
+	if c.AuthPublicRead && c.AuthToken == "" {
+		c.warnf("SERVER_AUTH_PUBLIC_READ has no effect without SERVER_AUTH_TOKEN")
+	}
+	if c.DailyMaxLossUSDC < 0 {
+		c.warnf("DAILY_MAX_LOSS_USDC cannot be negative, using 0 (disabled)")
+		c.DailyMaxLossUSDC = 0
+	}
+	if c.PriceSanityMaxDeviation < 0 {
+		c.warnf("PRICE_SANITY_MAX_DEVIATION cannot be negative, using 0 (disabled)")
+		c.PriceSanityMaxDeviation = 0
+	}
+	if c.DashboardPageSize <= 0 {
+		c.warnf("DASHBOARD_PAGE_SIZE must be positive, using 50")
+		c.DashboardPageSize = 50
+	}
+	if c.DashboardRefreshSeconds < 0 {
+		c.warnf("DASHBOARD_REFRESH_SECONDS cannot be negative, using 0 (no automatic refresh)")
+		c.DashboardRefreshSeconds = 0
+	}
+	switch {
+	case c.DisplayCurrency == "":
+	case c.DisplayCurrency == "USD" || c.DisplayCurrency == "USDC":
+		c.warnf("DISPLAY_CURRENCY %s is the same as USDC, no conversion will be shown", c.DisplayCurrency)
+		c.DisplayCurrency = ""
+	case !isCurrencyCode(c.DisplayCurrency):
+		c.warnf("DISPLAY_CURRENCY %q is not a currency code (expected e.g. EUR), no conversion will be shown", c.DisplayCurrency)
+		c.DisplayCurrency = ""
+	}
+	if !strings.HasPrefix(c.FXSourceURL, "http://") && !strings.HasPrefix(c.FXSourceURL, "https://") {
+		c.warnf("FX_SOURCE_URL %q is not an http(s) URL, using %s", c.FXSourceURL, fx.DefaultSourceURL)
+		c.FXSourceURL = fx.DefaultSourceURL
+	}
+
+	if c.SnapshotFullResolutionDays < 0 {
+		c.warnf("SNAPSHOT_FULL_RESOLUTION_DAYS cannot be negative, using 0 (keep all snapshots)")
+		c.SnapshotFullResolutionDays = 0
+	}
+	if c.PriceHistoryIntervalMinutes < 1 {
+		c.warnf("PRICE_HISTORY_INTERVAL_MINUTES must be at least 1, using 5")
+		c.PriceHistoryIntervalMinutes = 5
+	}
+	if c.PriceHistoryRetentionHours < 0 {
+		c.warnf("PRICE_HISTORY_RETENTION_HOURS cannot be negative, using 0 (price history disabled)")
+		c.PriceHistoryRetentionHours = 0
+	}
+	if c.DatabaseBackupCount < 0 {
+		c.warnf("DB_BACKUP_COUNT cannot be negative, using 0 (no automatic backups)")
+		c.DatabaseBackupCount = 0
+	}
+	if c.OrderSnapshotRetentionDays < 0 {
+		c.warnf("ORDER_SNAPSHOT_RETENTION_DAYS cannot be negative, using 0 (keep all order snapshots)")
+		c.OrderSnapshotRetentionDays = 0
+	}
+
+	if base, quote, _ := strings.Cut(c.KrakenPair, "/"); (base != "XBT" && base != "BTC") || (quote != "USDC" && quote != "USDT") {
+		c.warnf("KRAKEN_PAIR %q is not supported (expected XBT/USDC or XBT/USDT), using XBT/USDC", c.KrakenPair)
+		c.KrakenPair = "XBT/USDC"
+	}
+
+	for _, url := range c.WebhookURLs {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			c.errorf("WEBHOOK_URLS: %q is not an http(s) URL", url)
+		}
+	}
+	if c.WebhookMaxFailures < 0 {
+		c.warnf("WEBHOOK_MAX_FAILURES cannot be negative, using 0 (never disable)")
+		c.WebhookMaxFailures = 0
+	}
+	c.validateHooks()
+
+	for _, event := range c.DesktopNotifyEvents {
+		if !isDesktopEvent(event) {
+			c.warnf("DESKTOP_NOTIFY_EVENTS contains unknown event %q (expected %s, %s or %s)",
+				event, DesktopEventTaskFailed, DesktopEventCycleCompleted, DesktopEventLossLimit)
+		}
+	}
+	c.validateWebPush()
+
+	if !i18n.Supported(c.Language) {
+		c.warnf("LANGUAGE %q is not supported, using %s", c.Language, i18n.DefaultLanguage)
+		c.Language = i18n.DefaultLanguage
+	}
+
+	if c.SchedulerHistorySize <= 0 {
+		c.warnf("SCHEDULER_HISTORY_SIZE must be positive, using 200")
+		c.SchedulerHistorySize = 200
+	}
+	if c.RateLimitWarnPercent <= 0 || c.RateLimitWarnPercent > 100 {
+		c.warnf("RATE_LIMIT_WARN_PERCENT must be between 0 and 100, using 80")
+		c.RateLimitWarnPercent = 80
+	}
+
+	// Validation du format de log
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		c.warnf("LOG_FORMAT %q is not supported, using text", c.LogFormat)
+		c.LogFormat = "text"
+	}
+
+	return c.validationError()
+}
+
+// TLSEnabled indique si les serveurs web doivent être servis en HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// DesktopNotifies indique si l'événement doit être affiché en notification de bureau
+func (c *Config) DesktopNotifies(event string) bool {
+	if c == nil || !c.DesktopNotifications {
+		return false
+	}
+	if len(c.DesktopNotifyEvents) == 0 {
+		return true
+	}
+	for _, allowed := range c.DesktopNotifyEvents {
+		if strings.EqualFold(allowed, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDesktopEvent indique si l'événement est connu des notifications de bureau
+func isDesktopEvent(event string) bool {
+	switch strings.ToLower(event) {
+	case DesktopEventTaskFailed, DesktopEventCycleCompleted, DesktopEventLossLimit:
+		return true
+	}
+	return false
+}
+
+// isCurrencyCode indique si la valeur a la forme d'un code de devise ISO 4217 (EUR, GBP...)
+func isCurrencyCode(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
 }
 
 // GetExchangeConfig retourne la configuration d'un exchange spécifique
@@ -248,6 +1276,18 @@ func (c *Config) SecretKey() string {
 	return c.Exchanges[c.MainExchangeName].SecretKey
 }
 
+// BuyOffsetAt retourne l'offset d'achat (négatif) pour le prix du marché donné: BUY_OFFSET_PERCENT %
+// du prix, sans descendre sous BUY_OFFSET, ou BUY_OFFSET seul sans pourcentage
+func (e ExchangeConfig) BuyOffsetAt(price float64) float64 {
+	return -math.Max(math.Abs(e.BuyOffset), math.Abs(e.BuyOffsetPercent)*price/100)
+}
+
+// SellOffsetAt retourne l'offset de vente pour le prix d'achat donné: SELL_OFFSET_PERCENT % du prix
+// d'achat, sans descendre sous SELL_OFFSET, ou SELL_OFFSET seul sans pourcentage
+func (e ExchangeConfig) SellOffsetAt(buyPrice float64) float64 {
+	return math.Max(math.Abs(e.SellOffset), math.Abs(e.SellOffsetPercent)*buyPrice/100)
+}
+
 // BuyOffset retourne l'offset d'achat de l'exchange principal
 func (c *Config) BuyOffset() float64 {
 	return c.Exchanges[c.MainExchangeName].BuyOffset
@@ -285,6 +1325,7 @@ func (c *Config) SellAccuPriceDeviation() float64 {
 
 // Fonctions utilitaires (getEnvString, getEnvFloat, getEnvInt, getEnvBool)
 func getEnvString(key, defaultValue string) string {
+	noteKey(key)
 	value := os.Getenv(key)
 	if value == "" {
 		return defaultValue
@@ -293,6 +1334,7 @@ func getEnvString(key, defaultValue string) string {
 }
 
 func getEnvFloat(key string, defaultValue float64) float64 {
+	noteKey(key)
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
 		return defaultValue
@@ -300,7 +1342,36 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 
 	value, err := strconv.ParseFloat(valueStr, 64)
 	if err != nil {
-		log.Printf("Warning: Could not parse %s as float, using default: %f\n", key, defaultValue)
+		noteLoadWarning(key, "Could not parse %s as float, using default: %f", key, defaultValue)
+		return defaultValue
+	}
+
+	return value
+}
+
+// absoluteOffset lit l'offset absolu setting (BUY_OFFSET, SELL_OFFSET) d'un exchange. Avec un
+// offset en pourcentage, il ne sert que de plancher: défini ni pour l'exchange ni par défaut, il
+// vaut 0 au lieu de la valeur historique
+func absoluteOffset(ex, setting string, defaultValue, percent float64) float64 {
+	key := fmt.Sprintf("%s_%s", ex, setting)
+	value := getEnvFloat(key, defaultValue)
+	if percent != 0 && os.Getenv(key) == "" && os.Getenv("DEFAULT_"+setting) == "" {
+		return 0
+	}
+	return value
+}
+
+// getEnvPriceTrigger lit un seuil de prix absolu (95000) ou relatif (5%)
+func getEnvPriceTrigger(key string, defaultValue PriceTrigger) PriceTrigger {
+	noteKey(key)
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := ParsePriceTrigger(valueStr)
+	if err != nil {
+		noteLoadWarning(key, "Could not parse %s as price or percent, using default: %s", key, defaultValue)
 		return defaultValue
 	}
 
@@ -308,6 +1379,7 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 }
 
 func getEnvInt(key string, defaultValue int) int {
+	noteKey(key)
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
 		return defaultValue
@@ -315,14 +1387,27 @@ func getEnvInt(key string, defaultValue int) int {
 
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		log.Printf("Warning: Could not parse %s as int, using default: %d\n", key, defaultValue)
+		noteLoadWarning(key, "Could not parse %s as int, using default: %d", key, defaultValue)
 		return defaultValue
 	}
 
 	return value
 }
 
+// getEnvList lit une liste de valeurs séparées par des virgules (vide si non définie)
+func getEnvList(key string) []string {
+	noteKey(key)
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
+	noteKey(key)
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
 		return defaultValue
@@ -330,7 +1415,7 @@ func getEnvBool(key string, defaultValue bool) bool {
 
 	value, err := strconv.ParseBool(valueStr)
 	if err != nil {
-		log.Printf("Warning: Could not parse %s as bool, using default: %v\n", key, defaultValue)
+		noteLoadWarning(key, "Could not parse %s as bool, using default: %v", key, defaultValue)
 		return defaultValue
 	}
 
@@ -349,7 +1434,7 @@ func (c *Config) MinLockedRatio() float64 {
 
 // CreateConfigFileIfNotExists crée le fichier de configuration s'il n'existe pas
 func CreateConfigFileIfNotExists() (bool, error) {
-	if _, err := os.Stat(ConfigFilename); errors.Is(err, os.ErrNotExist) {
+	if _, err := os.Stat(Path(ConfigFilename)); errors.Is(err, os.ErrNotExist) {
 		// Vérifier si le fichier d'exemple existe
 		exampleFile := "bot.conf.example"
 		if _, err := os.Stat(exampleFile); err != nil {
@@ -364,7 +1449,7 @@ func CreateConfigFileIfNotExists() (bool, error) {
 		}
 
 		// Écrire le contenu dans le nouveau fichier de configuration
-		err = os.WriteFile(ConfigFilename, content, 0644)
+		err = os.WriteFile(Path(ConfigFilename), content, 0644)
 		if err != nil {
 			return false, fmt.Errorf("failed to create config file: %w", err)
 		}
@@ -395,6 +1480,14 @@ BINANCE_BUY_OFFSET=-500
 # Offset de vente: décalage en $ par rapport au prix d'achat (valeur positive)
 BINANCE_SELL_OFFSET=500
 
+# Offsets en % du prix (facultatifs): achat BUY_OFFSET_PERCENT % sous le prix actuel, vente
+# SELL_OFFSET_PERCENT % au-dessus du prix d'achat. Avec un offset absolu également défini,
+# OFFSET_PRECEDENCE=percent garde le plus grand des deux (l'offset absolu sert de plancher),
+# OFFSET_PRECEDENCE=absolute ignore le pourcentage
+# BINANCE_BUY_OFFSET_PERCENT=-0.8
+# BINANCE_SELL_OFFSET_PERCENT=0.8
+# BINANCE_OFFSET_PRECEDENCE=percent
+
 # Pourcentage du capital disponible à utiliser pour chaque cycle (1-100)
 BINANCE_PERCENT=4
 
@@ -423,6 +1516,18 @@ BINANCE_ADAPTIVE_ORDER=false
 # et inférieur à (100% - MIN_LOCKED_RATIO). Ainsi si le BTC monte vite, vous éviter d'acheter trop fort trop haut.
 BINANCE_MIN_LOCKED_RATIO=0.1
 
+# Réserve que le bot n'engage jamais (fonds détenus hors du bot sur le même compte, 0 = aucune):
+# le solde utilisable pour les achats et les ventes est le solde libre diminué de la réserve
+# BINANCE_RESERVE_BTC=0.01
+# BINANCE_RESERVE_USDC=100
+
+# Délai des requêtes HTTP en secondes (0 = défaut du client: 10s Binance, 15s MEXC et KuCoin,
+# 30s Kraken) et fenêtre de validité des requêtes signées en millisecondes (Binance et MEXC
+# uniquement, 60000 au plus, 0 = 5000, défaut de l'exchange). À augmenter sur une connexion lente
+# ou instable; les valeurs effectives sont affichées par --check
+# BINANCE_HTTP_TIMEOUT_SECONDS=20
+# BINANCE_RECV_WINDOW_MS=10000
+
 # ----- Mexc -----
 MEXC_BUY_OFFSET=-250
 MEXC_SELL_OFFSET=250
@@ -464,9 +1569,89 @@ DEFAULT_BUY_MAX_DAYS=0
 DEFAULT_BUY_MAX_PRICE_DEVIATION=0
 DEFAULT_ACCUMULATION=false
 DEFAULT_SELL_ACCU_PRICE_DEVIATION=10
+# Plafonds de l'accumulation: part maximale du profit réalisé de l'exchange consacrée aux accumulations (en %)
+# et valeur maximale d'une seule accumulation (en USDC, 0 = illimitée). Un cycle qui dépasse un plafond n'est pas accumulé.
+# Peut être surchargé par exchange: BINANCE_MAX_ACCUMULATION_PERCENT_OF_PROFIT=50, BINANCE_MAX_SINGLE_ACCUMULATION_USDC=100
+DEFAULT_MAX_ACCUMULATION_PERCENT_OF_PROFIT=100
+DEFAULT_MAX_SINGLE_ACCUMULATION_USDC=0
+# Nombre d'échecs API consécutifs avant de suspendre un exchange pour la mise à jour en cours (0 = désactivé)
+# Peut être surchargé par exchange: KRAKEN_CIRCUIT_BREAKER_THRESHOLD=5
+DEFAULT_CIRCUIT_BREAKER_THRESHOLD=3
+# Écart en % au-dessus/en dessous du prix pour placer des ordres maker (0 = valeurs historiques:
+# 0.1% pour le prix de vente minimum, 0.2% pour les ordres maker). Toujours au moins un tick de prix.
+# Peut être surchargé par exchange: BINANCE_MAKER_BUFFER_PERCENT=0.01
+DEFAULT_MAKER_BUFFER_PERCENT=0
+# Limites des cycles ouverts (achat ou vente en cours) par exchange (0 = illimité)
+# Au-delà de l'exposition maximale, le nouvel ordre est réduit ou refusé
+# Peut être surchargé par exchange: BINANCE_MAX_OPEN_EXPOSURE_USDC=2000, BINANCE_MAX_OPEN_CYCLES=10
+DEFAULT_MAX_OPEN_EXPOSURE_USDC=0
+DEFAULT_MAX_OPEN_CYCLES=0
+# Ordres d'achat et de vente en post-only (LIMIT_MAKER): refusés s'ils seraient exécutés immédiatement
+# Le prix est alors éloigné d'un tick et l'ordre renvoyé, au plus DEFAULT_POST_ONLY_RETRIES fois
+# Peut être surchargé par exchange: KRAKEN_POST_ONLY=false, BINANCE_POST_ONLY_RETRIES=5
+DEFAULT_POST_ONLY=true
+DEFAULT_POST_ONLY_RETRIES=3
+# Quand le prix dépasse le seuil BUY_MAX_PRICE_DEVIATION, replacer l'ordre d'achat au prix actuel + BUY_OFFSET
+# (le prix de vente suit du même écart) au lieu d'annuler le cycle, au plus DEFAULT_MAX_REPRICES fois par cycle
+# Peut être surchargé par exchange: MEXC_REPRICE_INSTEAD_OF_CANCEL=true, MEXC_MAX_REPRICES=5
+DEFAULT_REPRICE_INSTEAD_OF_CANCEL=false
+DEFAULT_MAX_REPRICES=3
+# Faire expirer les ordres d'achat par l'exchange lui-même après BUY_MAX_DAYS jours (KuCoin, Kraken),
+# même si le bot est arrêté. Les exchanges sans expiration d'ordre (Binance, MEXC) gardent l'annulation par le bot.
+# Peut être surchargé par exchange: KRAKEN_USE_EXCHANGE_EXPIRY=true
+DEFAULT_USE_EXCHANGE_EXPIRY=false
+# Vente OCO (Binance uniquement): l'ordre de vente est accompagné d'un stop-limit de protection,
+# déclenché DEFAULT_OCO_STOP_LOSS_PERCENT % sous le prix d'achat, avec une limite DEFAULT_OCO_STOP_LIMIT_PERCENT %
+# sous ce déclenchement. L'exécution d'une jambe annule l'autre. Les autres exchanges placent une vente simple.
+# Peut être surchargé par exchange: BINANCE_USE_OCO=true, BINANCE_OCO_STOP_LOSS_PERCENT=3
+DEFAULT_USE_OCO=false
+DEFAULT_OCO_STOP_LOSS_PERCENT=5
+DEFAULT_OCO_STOP_LIMIT_PERCENT=0.5
+# Taux de frais maker et taker (0.001 = 0.1%) utilisés pour estimer les frais quand l'exchange ne les fournit pas
+# Valeurs par défaut du niveau de base: Binance et KuCoin 0.001/0.001, MEXC 0/0.0005, Kraken 0.0026/0.004
+# Se règlent par exchange: BINANCE_MAKER_FEE_RATE=0.00075, BINANCE_TAKER_FEE_RATE=0.00075
+# Un taux maker négatif est une remise reversée par l'exchange (ex: MEXC_MAKER_FEE_RATE=-0.0001)
+# DEFAULT_FETCH_FEE_RATES=true lit au démarrage le niveau réel du compte (Binance, Kraken), qui remplace ces taux
+DEFAULT_FETCH_FEE_RATES=false
+# Limite quotidienne de pertes réalisées (cycles complétés à perte sur la journée UTC, en USDC, 0 = désactivée)
+# Une fois dépassée, aucun nouvel ordre d'achat ou de vente n'est placé jusqu'au lendemain ou jusqu'à --override-loss-limit
+# Peut être surchargé par exchange: KRAKEN_DAILY_MAX_LOSS_USDC=50; DAILY_MAX_LOSS_USDC s'applique à tous les exchanges cumulés
+DEFAULT_DAILY_MAX_LOSS_USDC=0
+DAILY_MAX_LOSS_USDC=0
+
+# Contrôle des prix BTC: un prix qui s'écarte de plus de ce pourcentage de la médiane des autres
+# exchanges (ou, sans elle, du dernier prix fiable de moins de 6 heures) est ignoré pour l'exécution:
+# aucune annulation, accumulation ni vente n'est décidée sur cet exchange (0 = désactivé)
+PRICE_SANITY_MAX_DEVIATION=10
+# Conserver le dernier prix fiable de chaque exchange entre les exécutions (price_guard.json)
+PRICE_SANITY_PERSIST=true
+
+# Achat en tranches: chaque nouveau cycle place DEFAULT_LADDER_COUNT ordres d'achat, chacun
+# DEFAULT_LADDER_STEP_PERCENT % sous le précédent (ex: 3 et 0.5 pour -0%, -0.5%, -1% sous le prix d'achat).
+# Le montant est réparti à parts égales; les tranches forment un groupe annulable avec -c=group:ID.
+# Peut être surchargé par exchange (BINANCE_LADDER_COUNT=3) ou en ligne de commande (--ladder=3 --ladder-step=0.5)
+DEFAULT_LADDER_COUNT=1
+DEFAULT_LADDER_STEP_PERCENT=0.5
+# Contrôle du carnet avant chaque vente: l'écart achat/vente est journalisé et un prix de vente à plus de
+# DEFAULT_SELL_MAX_ABOVE_ASK_PERCENT % au-dessus de la meilleure vente est ramené juste au-dessus d'elle,
+# sans descendre sous le prix couvrant les frais (0 = désactivé). Surchargeable: BINANCE_SELL_MAX_ABOVE_ASK_PERCENT=1
+DEFAULT_SELL_MAX_ABOVE_ASK_PERCENT=0
+# Revente du BTC accumulé: dès que le prix atteint DEFAULT_ACCU_SELL_TRIGGER_PRICE, chaque accumulation est
+# remise en vente et redevient un cycle au prix d'achat d'origine. Prix absolu (95000) ou pourcentage au-dessus
+# du prix de vente annulé lors de l'accumulation (10%); 0 = désactivé. Surchargeable: KRAKEN_ACCU_SELL_TRIGGER_PRICE=15%
+DEFAULT_ACCU_SELL_TRIGGER_PRICE=0
+# Profit net minimum d'un cycle: si le prix de vente couvrant les frais rapporte moins que DEFAULT_MIN_PROFIT_USDC
+# ou DEFAULT_MIN_PROFIT_PERCENT % du montant d'achat (le plus élevé s'applique), il est relevé pour l'atteindre, sans
+# dépasser le prix d'achat + DEFAULT_MIN_PROFIT_MAX_MARKUP_PERCENT %. 0 = désactivé. Surchargeable: BINANCE_MIN_PROFIT_USDC=1
+DEFAULT_MIN_PROFIT_USDC=0
+DEFAULT_MIN_PROFIT_PERCENT=0
+DEFAULT_MIN_PROFIT_MAX_MARKUP_PERCENT=5
 
 # =========== CLÉS API PAR EXCHANGE ===========
 # Ces clés sont OBLIGATOIRES pour l'exchange que vous utilisez
+# Les clés peuvent aussi être lues hors de ce fichier :
+#   env:NOM       variable d'environnement NOM (ex: BINANCE_API_KEY=env:BINANCE_KEY)
+#   keychain:NOM  entrée NOM du magasin d'identifiants du système (--set-secret binance l'enregistre)
 BINANCE_API_KEY=
 BINANCE_SECRET_KEY=
 
@@ -479,15 +1664,141 @@ KUCOIN_SECRET_KEY=
 
 KRAKEN_API_KEY=
 KRAKEN_SECRET_KEY=
+# Paire négociée sur Kraken: XBT/USDC ou XBT/USDT selon la région du compte. Son code est lu
+# au démarrage dans la liste des paires de Kraken; avec XBT/USDT, les montants affichés en USDC sont en USDT
+KRAKEN_PAIR=XBT/USDC
 
 # =========== CONFIGURATION SUPPLÉMENTAIRE ===========
+# Langue des messages, du menu et des pages web: fr ou en (la commande --lang=en la remplace)
+LANGUAGE=fr
+
+# Alias de commandes: ALIAS_NOM=commande et options (avec l'exemple ci-dessous, "bot-spot nb"
+# équivaut à "bot-spot new --exchange binance")
+# ALIAS_NB=new --exchange binance
+
 # Environment: production ou development
 ENVIRONMENT=production
 
 # Niveau de log: debug, info, warn, error
-LOG_LEVEL=info`
-
-	err := os.WriteFile(ConfigFilename, []byte(defaultConfig), 0644)
+LOG_LEVEL=info
+
+# Format des logs de trading: text (sortie colorée) ou json (une ligne JSON par événement)
+LOG_FORMAT=text
+
+# Niveau de log appliqué aux commandes lancées par le planificateur (-plan)
+DAEMON_LOG_LEVEL=warn
+# Nombre d'exécutions de tâches conservées dans l'historique (-plan status, onglet Planificateur)
+SCHEDULER_HISTORY_SIZE=200
+# Avertir en fin de mise à jour lorsque la limite de requêtes d'un exchange est consommée à ce pourcentage
+# (poids X-MBX-USED-WEIGHT de Binance, en-têtes gw-ratelimit de KuCoin; MEXC et Kraken ne la communiquent pas)
+RATE_LIMIT_WARN_PERCENT=80
+
+# =========== SERVEURS WEB ===========
+# Adresse d'écoute du tableau de bord (-s) et du serveur de statistiques (-st)
+# Toute adresse autre que localhost exige SERVER_TLS_CERT/SERVER_TLS_KEY ou SERVER_AUTH_TOKEN
+SERVER_ADDR=localhost
+SERVER_PORT=8080
+STATS_PORT=8081
+
+# Certificat et clé TLS (optionnels) : si renseignés, les serveurs passent en HTTPS
+SERVER_TLS_CERT=
+SERVER_TLS_KEY=
+
+# Jeton d'accès partagé pour les serveurs web
+# Envoyé en en-tête "Authorization: Bearer <jeton>" ou saisi sur la page /login
+SERVER_AUTH_TOKEN=
+# true = pages en lecture accessibles sans jeton ; /update exige toujours POST + jeton
+SERVER_AUTH_PUBLIC_READ=false
+# true = tableau de bord en lecture seule (aussi: --server -readonly) : aucune action possible
+SERVER_READ_ONLY=false
+
+# Nombre de cycles affichés par page sur le tableau de bord
+DASHBOARD_PAGE_SIZE=50
+# Rafraîchissement automatique des statistiques et du tableau, en secondes (0 = désactivé)
+DASHBOARD_REFRESH_SECONDS=30
+# Devise d'affichage des profits à côté de l'USDC (vide = USDC seulement), ex: EUR, GBP, CHF
+# Taux de référence de la BCE (1 USDC = 1 USD): celui de la date de complétion pour les profits
+# réalisés, le dernier publié pour les positions ouvertes
+DISPLAY_CURRENCY=
+# Source des taux (fichier XML de la BCE), conservée en cache une journée à côté de la base
+FX_SOURCE_URL=https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml
+
+# =========== INSTANTANÉS DU PORTEFEUILLE ===========
+# Valeur totale du compte (soldes BTC/USDC de chaque exchange) pour la courbe du serveur de statistiques
+# true = un instantané est enregistré à la fin de chaque mise à jour (-u) ; --snapshot en force un
+SNAPSHOT_ON_UPDATE=true
+# Au-delà de ce nombre de jours, un seul instantané par jour est conservé (0 = tout garder)
+SNAPSHOT_FULL_RESOLUTION_DAYS=90
+
+# =========== HISTORIQUE DU PRIX ===========
+# Prix du BTC relevé par exchange pendant les mises à jour, affiché en haut du tableau de bord avec
+# les prix d'achat et de vente des cycles en cours
+# Intervalle minimal entre deux points, en minutes
+PRICE_HISTORY_INTERVAL_MINUTES=5
+# Durée de conservation en heures (0 = historique désactivé)
+PRICE_HISTORY_RETENTION_HOURS=168
+
+# =========== SAUVEGARDES DE LA BASE DE DONNÉES ===========
+# Nombre de sauvegardes automatiques conservées dans data/backups (0 = désactivées). Une sauvegarde
+# est faite à chaque ouverture de la base, avant toute écriture; si la base est corrompue (coupure
+# de courant...), les enregistrements lisibles sont récupérés et complétés par la plus récente.
+# --fsck vérifie l'intégrité de la base à la demande
+DB_BACKUP_COUNT=5
+
+# =========== RÉPONSES BRUTES DES ORDRES ===========
+# La réponse de l'exchange est conservée sur le cycle à l'exécution de ses ordres d'achat et de
+# vente (audit, affichée et téléchargeable sur la page du cycle). --archive la supprime des cycles
+# créés il y a plus de ce nombre de jours (0 = tout garder)
+ORDER_SNAPSHOT_RETENTION_DAYS=0
+
+# =========== NOTIFICATIONS WEBHOOK ===========
+# URLs (séparées par des virgules) recevant chaque événement en POST JSON (n8n, Zapier, serveur maison...)
+WEBHOOK_URLS=
+# Événements transmis, séparés par des virgules (vide = tous, sauf error qui doit être nommé) :
+# new_cycle, buy_filled, place_sell, sell_filled, cancel_buy_age, cancel_buy_deviation, reprice_buy, accumulate, cancel, report, error, test
+WEBHOOK_EVENTS=
+# Clé de signature: l'en-tête X-Bot-Signature contient sha256=<HMAC-SHA256 du corps> (env: et keychain: acceptés)
+WEBHOOK_SECRET=
+# Une URL est désactivée après ce nombre de livraisons échouées (3 essais chacune) ; --webhook-test la réactive
+WEBHOOK_MAX_FAILURES=5
+
+# =========== HOOKS (COMMANDES EXTERNES) ===========
+# Commandes lancées à chaque événement de trading, nommées dans HOOKS (séparées par des virgules) et
+# décrites par HOOK_<NOM>_COMMAND, HOOK_<NOM>_EVENTS et HOOK_<NOM>_TIMEOUT_SECONDS. La commande
+# reçoit l'événement en JSON sur son entrée standard, et son nom dans la variable BOT_EVENT:
+#   {"event": "sell_filled", "exchange": "BINANCE", "message": "Cycle 12 complété: ...",
+#    "timestamp": "2025-02-01T10:00:00Z", "cycle": {...état du cycle...}, "fields": {"cycle_id": 12, ...}}
+# "cycle" est absent des événements qui ne portent pas sur un cycle (report, loss_limit, error).
+# Événements: new_cycle (cycle créé), buy_filled, place_sell (vente placée), sell_filled (cycle
+# complété), cancel_buy_age, cancel_buy_deviation, cancel (annulations), accumulate, error (chaque
+# erreur, transmis seulement s'il est nommé dans HOOK_<NOM>_EVENTS), report, loss_limit...
+# Aucun interpréteur de commandes n'est utilisé: indiquer le programme (guillemets autour d'un
+# chemin avec espaces). Un hook en échec ou trop long est journalisé sans interrompre le trading.
+# HOOKS=sheet
+# HOOK_SHEET_COMMAND=powershell -NoProfile -File "C:\Bot Spot\hooks\sheet.ps1"
+# HOOK_SHEET_EVENTS=sell_filled,cancel
+# HOOK_SHEET_TIMEOUT_SECONDS=10
+# Durée maximale d'exécution d'un hook, en secondes, quand HOOK_<NOM>_TIMEOUT_SECONDS n'est pas défini
+HOOK_TIMEOUT_SECONDS=10
+
+# =========== NOTIFICATIONS DE BUREAU ===========
+# Notifications natives (toast Windows) pour les événements du planificateur ; ignorées ailleurs
+DESKTOP_NOTIFICATIONS=false
+# Événements affichés, séparés par des virgules (vide = tous) : task_failed, cycle_completed, loss_limit
+DESKTOP_NOTIFY_EVENTS=
+# =========== NOTIFICATIONS WEB PUSH (NAVIGATEUR, TÉLÉPHONE) ===========
+# Le tableau de bord propose l'abonnement aux notifications une fois les clés VAPID configurées:
+# --push-keys crée la paire à copier ici. Le navigateur n'autorise le push que sur une page en
+# HTTPS (SERVER_TLS_CERT) ou sur localhost; les abonnements sont conservés dans push_subscriptions.json
+WEB_PUSH_PUBLIC_KEY=
+# Clé privée (env: et keychain: acceptés)
+WEB_PUSH_PRIVATE_KEY=
+# Contact transmis aux serveurs de push (mailto:vous@example.com ou https://...)
+WEB_PUSH_SUBJECT=
+# Événements envoyés, séparés par des virgules (vide = sell_filled, les cycles complétés)
+WEB_PUSH_EVENTS=`
+
+	err := os.WriteFile(Path(ConfigFilename), []byte(defaultConfig), 0644)
 	if err != nil {
 		return false, fmt.Errorf("failed to create config file: %w", err)
 	}
@@ -496,22 +1807,20 @@ LOG_LEVEL=info`
 	return true, nil
 }
 
-// GetScheduledTasks retourne la liste des tâches planifiées
-func (c *Config) GetScheduledTasks() []types.TaskConfig {
+// readTasksConfig lit les valeurs de tasks.conf (nil si le fichier est absent ou illisible)
+func readTasksConfig() map[string]string {
 	// Vérifier si le fichier de configuration des tâches existe
-	tasksConfigFile := "tasks.conf"
+	tasksConfigFile := Path("tasks.conf")
 
 	if _, err := os.Stat(tasksConfigFile); os.IsNotExist(err) {
-		// Le fichier n'existe pas, retourner une liste vide
-		return []types.TaskConfig{}
+		return nil
 	}
 
 	// Charger le fichier de configuration des tâches
 	tasksConfigContent, err := os.ReadFile(tasksConfigFile)
 	if err != nil {
 		log.Printf("Erreur lors de la lecture du fichier de configuration des tâches: %v", err)
-		// En cas d'erreur, retourner une liste vide
-		return []types.TaskConfig{}
+		return nil
 	}
 
 	// Charger les variables d'environnement depuis le contenu du fichier
@@ -519,6 +1828,31 @@ func (c *Config) GetScheduledTasks() []types.TaskConfig {
 	env, err := godotenv.Unmarshal(string(tasksConfigContent))
 	if err != nil {
 		log.Printf("Erreur lors du parsing du fichier de configuration des tâches: %v", err)
+		return nil
+	}
+	return env
+}
+
+// GetQuietHours retourne la plage QUIET_HOURS de tasks.conf pendant laquelle les tâches "new"
+// ne sont pas exécutées (plage vide si elle n'est pas définie ou invalide)
+func (c *Config) GetQuietHours() types.QuietHours {
+	env := readTasksConfig()
+	if env == nil {
+		return types.QuietHours{}
+	}
+	quiet, err := types.ParseQuietHours(env["QUIET_HOURS"])
+	if err != nil {
+		log.Printf("QUIET_HOURS ignoré dans tasks.conf: %v", err)
+		return types.QuietHours{}
+	}
+	return quiet
+}
+
+// GetScheduledTasks retourne la liste des tâches planifiées
+func (c *Config) GetScheduledTasks() []types.TaskConfig {
+	env := readTasksConfig()
+	if env == nil {
+		// Fichier absent ou illisible, retourner une liste vide
 		return []types.TaskConfig{}
 	}
 
@@ -601,6 +1935,18 @@ func (c *Config) GetScheduledTasks() []types.TaskConfig {
 		// Récupérer l'exchange
 		taskConfig.Exchange = env[prefix+"EXCHANGE"]
 
+		// Délai aléatoire ajouté à chaque exécution
+		if value, ok := env[prefix+"JITTER_SECONDS"]; ok {
+			if jitter, err := strconv.Atoi(value); err == nil && jitter > 0 {
+				taskConfig.JitterSeconds = jitter
+			}
+		}
+
+		// Âge maximal des achats annulés par une tâche "cancel_stale"
+		if taskConfig.Type == "cancel_stale" {
+			taskConfig.MaxAgeDays, _ = strconv.Atoi(env[prefix+"MAX_AGE_DAYS"])
+		}
+
 		// Récupérer les paramètres personnalisés pour les tâches de type "new"
 		if taskConfig.Type == "new" {
 			buyOffsetStr, ok := env[prefix+"BUY_OFFSET"]
@@ -613,10 +1959,31 @@ func (c *Config) GetScheduledTasks() []types.TaskConfig {
 				taskConfig.SellOffset, _ = strconv.ParseFloat(sellOffsetStr, 64)
 			}
 
+			if value, ok := env[prefix+"BUY_OFFSET_PERCENT"]; ok {
+				taskConfig.BuyOffsetPercent, _ = strconv.ParseFloat(value, 64)
+			}
+			if value, ok := env[prefix+"SELL_OFFSET_PERCENT"]; ok {
+				taskConfig.SellOffsetPercent, _ = strconv.ParseFloat(value, 64)
+			}
+
 			percentStr, ok := env[prefix+"PERCENT"]
 			if ok {
 				taskConfig.Percent, _ = strconv.ParseFloat(percentStr, 64)
 			}
+
+			// Les tâches enregistrées sans paire négocient la paire de leur exchange
+			taskConfig.Pair = env[prefix+"PAIR"]
+			if taskConfig.Pair == "" && taskConfig.Exchange != "" {
+				taskConfig.Pair = c.DefaultPair(taskConfig.Exchange)
+			}
+			if taskConfig.Pair != "" {
+				if pair, quote, err := ParsePair(taskConfig.Pair); err == nil {
+					taskConfig.Pair, taskConfig.QuoteAsset = pair, quote
+				} else {
+					log.Printf("Tâche %s: %v, paire de l'exchange utilisée", taskConfig.Name, err)
+					taskConfig.Pair, taskConfig.QuoteAsset = "", ""
+				}
+			}
 		}
 
 		tasks = append(tasks, taskConfig)
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseAliases(t *testing.T) {
+	aliases := parseAliases(map[string]string{
+		"ALIAS_NB":        "new --exchange binance",
+		"ALIAS_UP":        "  update  ",
+		"ALIAS_":          "new",
+		"ALIAS_EMPTY":     "",
+		"BINANCE_API_KEY": "key",
+	})
+
+	if len(aliases) != 2 || aliases["nb"] != "new --exchange binance" || aliases["up"] != "update" {
+		t.Fatalf("alias: %v", aliases)
+	}
+}
+
+// withConfigFile charge content comme bot.conf dans un répertoire temporaire, et retire ensuite
+// ses variables de l'environnement du processus
+func withConfigFile(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ConfigFilename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.WriteFile(ConfigFilename, nil, 0644)
+		loadEnvFile()
+		os.Chdir(wd)
+	})
+}
+
+func TestCheckConfigExampleHasNoUnknownKeys(t *testing.T) {
+	example, err := os.ReadFile("bot.conf.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withConfigFile(t, string(example)+"\nBINANCE_API_KEY=key\nBINANCE_SECRET_KEY=secret\n")
+
+	problems, err := CheckConfig()
+	if err != nil {
+		t.Fatalf("configuration d'exemple refusée: %v", err)
+	}
+	for _, problem := range problems {
+		if strings.Contains(problem.Message, "not a known setting") {
+			t.Errorf("clé de l'exemple inconnue: %s", problem)
+		}
+	}
+}
+
+func TestCheckConfigReportsAllProblems(t *testing.T) {
+	withConfigFile(t, strings.Join([]string{
+		"EXCHANGE=BINANCE",
+		"BINANCE_API_KEY=key",
+		"BINANCE_SECRET_KEY=secret",
+		"BINANCE_SELL_OFSET=700",
+		"BINANCE_PERCENT=150",
+		"KUCOIN_API_KEY=key",
+		"SERVER_PORT=9000",
+		"STATS_PORT=9000",
+		"MEXC_SELL_OFFSET=-50",
+	}, "\n")+"\n")
+
+	problems, err := CheckConfig()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("ValidationError attendue, obtenu %v", err)
+	}
+
+	// Toutes les erreurs sont relevées d'un coup, avec leur ligne
+	want := map[string]int{
+		"BINANCE_PERCENT must be between 0 and 100":                 5,
+		"KUCOIN_API_KEY and KUCOIN_SECRET_KEY must be set together": 6,
+		"SERVER_PORT and STATS_PORT must be different (both 9000)":  7,
+	}
+	if len(validationErr.Problems) != len(want) {
+		t.Fatalf("erreurs: %v", validationErr)
+	}
+	for _, problem := range validationErr.Problems {
+		if line, ok := want[problem.Message]; !ok || problem.Line != line {
+			t.Errorf("erreur inattendue: %s (ligne %d)", problem.Message, problem.Line)
+		}
+	}
+
+	// Les fautes de frappe et les valeurs corrigées sont signalées en avertissement
+	warnings := make(map[string]int)
+	for _, problem := range problems {
+		if problem.Warning {
+			warnings[problem.Message] = problem.Line
+		}
+	}
+	if line := warnings["BINANCE_SELL_OFSET is not a known setting and is ignored (did you mean BINANCE_SELL_OFFSET?)"]; line != 4 {
+		t.Errorf("faute de frappe non signalée à la ligne 4: %v", warnings)
+	}
+	if line, ok := warnings["MEXC_SELL_OFFSET must be positive (above the purchase price), using 50"]; !ok || line != 9 {
+		t.Errorf("offset négatif non signalé à la ligne 9: %v", warnings)
+	}
+}
+
+func TestOffsetPercent(t *testing.T) {
+	lines := []string{
+		"EXCHANGE=BINANCE",
+		"BINANCE_API_KEY=key",
+		"BINANCE_SECRET_KEY=secret",
+		"BINANCE_SELL_OFFSET=500",
+		"BINANCE_SELL_OFFSET_PERCENT=1",
+	}
+	withConfigFile(t, strings.Join(lines, "\n")+"\n")
+
+	// Un offset absolu et un offset en % sans priorité explicite sont refusés
+	_, _, err := loadConfig()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 ||
+		!strings.HasPrefix(validationErr.Problems[0].Message, "BINANCE_SELL_OFFSET_PERCENT and BINANCE_SELL_OFFSET are both set") ||
+		validationErr.Problems[0].Line != 5 {
+		t.Fatalf("conflit non signalé: %v", err)
+	}
+
+	// Avec OFFSET_PRECEDENCE=percent, l'offset absolu sert de plancher
+	withConfigFile(t, strings.Join(append(lines, "BINANCE_OFFSET_PRECEDENCE=percent"), "\n")+"\n")
+	cfg, _, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	binance := cfg.Exchanges["BINANCE"]
+	if offset := binance.SellOffsetAt(60000); offset != 600 {
+		t.Errorf("offset de vente à 60000: %g, attendu 600", offset)
+	}
+	if offset := binance.SellOffsetAt(40000); offset != 500 {
+		t.Errorf("offset de vente à 40000: %g, attendu le plancher de 500", offset)
+	}
+
+	// Avec OFFSET_PRECEDENCE=absolute, le pourcentage est ignoré
+	withConfigFile(t, strings.Join(append(lines, "BINANCE_OFFSET_PRECEDENCE=absolute"), "\n")+"\n")
+	if cfg, _, err = loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if offset := cfg.Exchanges["BINANCE"].SellOffsetAt(60000); offset != 500 {
+		t.Errorf("offset de vente absolu: %g, attendu 500", offset)
+	}
+
+	// Sans offset absolu défini, seul le pourcentage s'applique
+	withConfigFile(t, strings.Join([]string{lines[0], lines[1], lines[2], "BINANCE_BUY_OFFSET_PERCENT=-2"}, "\n")+"\n")
+	if cfg, _, err = loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if offset := cfg.Exchanges["BINANCE"].BuyOffsetAt(50000); offset != -1000 {
+		t.Errorf("offset d'achat: %g, attendu -1000", offset)
+	}
+}
+
+func TestRecvWindowValidation(t *testing.T) {
+	withConfigFile(t, strings.Join([]string{
+		"EXCHANGE=BINANCE",
+		"BINANCE_API_KEY=key",
+		"BINANCE_SECRET_KEY=secret",
+		"BINANCE_RECV_WINDOW_MS=90000",
+		"BINANCE_HTTP_TIMEOUT_SECONDS=25",
+		"KRAKEN_API_KEY=key",
+		"KRAKEN_SECRET_KEY=secret",
+		"KRAKEN_RECV_WINDOW_MS=10000",
+	}, "\n")+"\n")
+
+	cfg, problems, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binance := cfg.Exchanges["BINANCE"]; binance.RecvWindowMs != 60000 || binance.HTTPTimeoutSeconds != 25 {
+		t.Errorf("BINANCE: recvWindow %d, délai %d, attendu 60000 et 25", binance.RecvWindowMs, binance.HTTPTimeoutSeconds)
+	}
+	if kraken := cfg.Exchanges["KRAKEN"]; kraken.RecvWindowMs != 0 {
+		t.Errorf("KRAKEN: recvWindow %d, attendu 0 (non applicable)", kraken.RecvWindowMs)
+	}
+
+	warnings := make(map[string]bool)
+	for _, problem := range problems {
+		warnings[problem.Message] = true
+	}
+	for _, want := range []string{
+		"BINANCE_RECV_WINDOW_MS cannot exceed 60000, setting to 60000",
+		"KRAKEN_RECV_WINDOW_MS has no effect: only Binance and MEXC accept a recvWindow",
+	} {
+		if !warnings[want] {
+			t.Errorf("avertissement manquant: %q (%v)", want, problems)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// internal/config/hooks.go
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrorEvent est l'événement publié pour chaque erreur de trading. Trop fréquent pour être
+// transmis par défaut, il n'est reçu que par les canaux qui le nomment dans leur filtre.
+const ErrorEvent = "error"
+
+// defaultHookTimeout est la durée maximale d'exécution d'un hook par défaut (HOOK_TIMEOUT_SECONDS)
+const defaultHookTimeout = 10
+
+// Hook est une commande externe lancée pour chaque événement de trading retenu par son filtre.
+// Les hooks sont nommés dans HOOKS=nom1,nom2 et décrits par les clés HOOK_<NOM>_*.
+type Hook struct {
+	Name    string
+	Command []string // Programme et arguments
+	Events  []string // Événements transmis (vide = tous, sauf error)
+	Timeout time.Duration
+}
+
+// Accepts indique si l'événement passe le filtre HOOK_<NOM>_EVENTS
+func (h Hook) Accepts(event string) bool {
+	if len(h.Events) == 0 {
+		return event != ErrorEvent
+	}
+	for _, accepted := range h.Events {
+		if strings.EqualFold(accepted, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHooks lit les hooks déclarés par HOOKS et leurs clés HOOK_<NOM>_COMMAND, _EVENTS et
+// _TIMEOUT_SECONDS. Une commande illisible est conservée vide pour être signalée par Validate.
+func loadHooks() []Hook {
+	defaultTimeout := getEnvInt("HOOK_TIMEOUT_SECONDS", defaultHookTimeout)
+
+	var hooks []Hook
+	for _, name := range getEnvList("HOOKS") {
+		prefix := "HOOK_" + strings.ToUpper(name)
+		command, err := SplitCommandLine(getEnvString(prefix+"_COMMAND", ""))
+		if err != nil {
+			noteLoadWarning(prefix+"_COMMAND", "%s_COMMAND: %v", prefix, err)
+		}
+		hooks = append(hooks, Hook{
+			Name:    strings.ToLower(name),
+			Command: command,
+			Events:  getEnvList(prefix + "_EVENTS"),
+			Timeout: time.Duration(getEnvInt(prefix+"_TIMEOUT_SECONDS", defaultTimeout)) * time.Second,
+		})
+	}
+	return hooks
+}
+
+// validateHooks écarte les hooks sans commande et corrige les délais invalides
+func (c *Config) validateHooks() {
+	valid := c.Hooks[:0]
+	for _, hook := range c.Hooks {
+		prefix := "HOOK_" + strings.ToUpper(hook.Name)
+		if len(hook.Command) == 0 {
+			c.warnf("%s_COMMAND is required for hook %q, hook disabled", prefix, hook.Name)
+			continue
+		}
+		if hook.Timeout <= 0 {
+			c.warnf("%s_TIMEOUT_SECONDS must be positive, using %d", prefix, defaultHookTimeout)
+			hook.Timeout = defaultHookTimeout * time.Second
+		}
+		valid = append(valid, hook)
+	}
+	c.Hooks = valid
+}
+
+// SplitCommandLine découpe une ligne de commande en programme et arguments. Les guillemets
+// doubles regroupent un argument contenant des espaces ("C:\Mes scripts\sheet.ps1"); aucun
+// interpréteur de commandes n'est utilisé.
+func SplitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inQuotes, inArg := false, false
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("guillemet non fermé dans %q", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
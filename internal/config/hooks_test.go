@@ -0,0 +1,51 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	args, err := SplitCommandLine(`powershell -NoProfile -File "C:\Bot Spot\sheet.ps1"  --sheet=ventes`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"powershell", "-NoProfile", "-File", `C:\Bot Spot\sheet.ps1`, "--sheet=ventes"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+
+	if _, err := SplitCommandLine(`script "non fermé`); err == nil {
+		t.Error("un guillemet non fermé devrait être refusé")
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	t.Setenv("HOOKS", "sheet, audit")
+	t.Setenv("HOOK_SHEET_COMMAND", `python "C:\hooks\sheet.py"`)
+	t.Setenv("HOOK_SHEET_EVENTS", "sell_filled,cancel")
+	t.Setenv("HOOK_SHEET_TIMEOUT_SECONDS", "5")
+	t.Setenv("HOOK_TIMEOUT_SECONDS", "20")
+
+	c := &Config{Hooks: loadHooks()}
+	c.validateHooks()
+
+	// audit n'a pas de commande: écarté avec un avertissement
+	if len(c.Hooks) != 1 || len(c.Problems()) != 1 {
+		t.Fatalf("hooks = %+v, problèmes = %v", c.Hooks, c.Problems())
+	}
+	hook := c.Hooks[0]
+	if hook.Name != "sheet" || hook.Timeout != 5*time.Second || len(hook.Command) != 2 || hook.Command[1] != `C:\hooks\sheet.py` {
+		t.Errorf("hook = %+v", hook)
+	}
+	if !hook.Accepts("sell_filled") || hook.Accepts("buy_filled") {
+		t.Errorf("le filtre HOOK_SHEET_EVENTS n'est pas appliqué")
+	}
+
+	// Sans filtre, tous les événements sont transmis sauf les erreurs
+	all := Hook{Name: "all"}
+	if !all.Accepts("buy_filled") || all.Accepts(ErrorEvent) {
+		t.Errorf("sans filtre, error ne devrait être transmis que s'il est nommé")
+	}
+}
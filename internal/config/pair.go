@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTradingPair est la paire négociée sur les exchanges sans paire configurable
+const DefaultTradingPair = "BTC/USDC"
+
+// NormalizePair met une paire au format BASE/QUOTE en majuscules (XBT, le code Kraken du
+// bitcoin, devient BTC; BTC-USDC et BTCUSDC sont acceptés pour les quotes connues)
+func NormalizePair(pair string) string {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	pair = strings.ReplaceAll(pair, "-", "/")
+	if !strings.Contains(pair, "/") {
+		for _, quote := range []string{"USDC", "USDT"} {
+			if base, ok := strings.CutSuffix(pair, quote); ok && base != "" {
+				pair = base + "/" + quote
+				break
+			}
+		}
+	}
+	if base, quote, ok := strings.Cut(pair, "/"); ok && base == "XBT" {
+		pair = "BTC/" + quote
+	}
+	return pair
+}
+
+// ParsePair valide une paire et retourne sa forme normalisée et sa devise de cotation
+func ParsePair(pair string) (string, string, error) {
+	normalized := NormalizePair(pair)
+	base, quote, ok := strings.Cut(normalized, "/")
+	if !ok || base == "" || quote == "" || strings.Contains(quote, "/") {
+		return "", "", fmt.Errorf("paire %q invalide (attendu: BASE/QUOTE, par exemple BTC/USDC)", pair)
+	}
+	return normalized, quote, nil
+}
+
+// DefaultPair retourne la paire négociée sur un exchange: KRAKEN_PAIR pour Kraken, BTC/USDC
+// pour les autres
+func (c *Config) DefaultPair(exchange string) string {
+	if c != nil && strings.EqualFold(exchange, "KRAKEN") && c.KrakenPair != "" {
+		return NormalizePair(c.KrakenPair)
+	}
+	return DefaultTradingPair
+}
@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestParsePair(t *testing.T) {
+	for _, tc := range []struct {
+		input, pair, quote string
+	}{
+		{"BTC/USDC", "BTC/USDC", "USDC"},
+		{" xbt/usdt ", "BTC/USDT", "USDT"},
+		{"BTC-USDC", "BTC/USDC", "USDC"},
+		{"BTCUSDT", "BTC/USDT", "USDT"},
+	} {
+		pair, quote, err := ParsePair(tc.input)
+		if err != nil || pair != tc.pair || quote != tc.quote {
+			t.Errorf("ParsePair(%q) = %q, %q, %v; attendu %q, %q", tc.input, pair, quote, err, tc.pair, tc.quote)
+		}
+	}
+	for _, input := range []string{"", "BTC", "BTC/", "/USDC"} {
+		if _, _, err := ParsePair(input); err == nil {
+			t.Errorf("ParsePair(%q) accepté", input)
+		}
+	}
+
+	c := &Config{KrakenPair: "XBT/USDT"}
+	if pair := c.DefaultPair("KRAKEN"); pair != "BTC/USDT" {
+		t.Errorf("paire Kraken %q, attendu BTC/USDT", pair)
+	}
+	if pair := c.DefaultPair("BINANCE"); pair != DefaultTradingPair {
+		t.Errorf("paire Binance %q, attendu %s", pair, DefaultTradingPair)
+	}
+}
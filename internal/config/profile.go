@@ -0,0 +1,106 @@
+// internal/config/profile.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Répertoire de configuration choisi par --config=DIR ou --profile=NOM: bot.conf, tasks.conf, la
+// base de données et les fichiers du planificateur (planner.pid, journaux) y sont lus et écrits, ce
+// qui permet à plusieurs instances de coexister. Le choix passe par l'environnement pour être
+// hérité par le daemon et les commandes qu'il lance.
+const (
+	DirEnv     = "BOT_SPOT_CONFIG_DIR"
+	ProfileEnv = "BOT_SPOT_PROFILE"
+)
+
+// profilesDir est le répertoire des profils nommés, dans le dossier personnel de l'utilisateur
+var profilesDir = filepath.Join(".bot-spot", "profiles")
+
+// Dir retourne le répertoire de configuration, vide pour le répertoire courant
+func Dir() string {
+	return os.Getenv(DirEnv)
+}
+
+// Path retourne le chemin d'un fichier du répertoire de configuration
+func Path(name string) string {
+	return filepath.Join(Dir(), name)
+}
+
+// ActiveProfile retourne le nom du profil actif, ou le répertoire choisi par --config, vide
+// sans l'un ni l'autre
+func ActiveProfile() string {
+	if profile := os.Getenv(ProfileEnv); profile != "" {
+		return profile
+	}
+	return Dir()
+}
+
+// ProfileDir retourne le répertoire du profil nommé: ~/.bot-spot/profiles/NOM
+func ProfileDir(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return "", fmt.Errorf("nom de profil invalide: %q", name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("dossier personnel introuvable pour le profil %s: %w", name, err)
+	}
+	return filepath.Join(home, profilesDir, name), nil
+}
+
+// UseDir choisit le répertoire de configuration, créé s'il n'existe pas. profile est le nom
+// affiché du profil (vide pour un répertoire donné par --config).
+func UseDir(dir, profile string) error {
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("répertoire de configuration %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(absolute, 0755); err != nil {
+		return fmt.Errorf("création du répertoire de configuration %s: %w", absolute, err)
+	}
+	os.Setenv(DirEnv, absolute)
+	if profile != "" {
+		os.Setenv(ProfileEnv, profile)
+	} else {
+		os.Unsetenv(ProfileEnv)
+	}
+	return nil
+}
+
+// SelectFromArgs applique --config=DIR ou --profile=NOM. Sans l'un ni l'autre, le profil hérité
+// de l'environnement (BOT_SPOT_PROFILE seul) est résolu; sinon le répertoire courant est conservé.
+func SelectFromArgs(args []string) error {
+	var dir, profile string
+	for _, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			dir = value
+		}
+		if value, ok := strings.CutPrefix(arg, "--profile="); ok {
+			profile = value
+		}
+	}
+	if dir != "" && profile != "" {
+		return fmt.Errorf("--config et --profile ne peuvent pas être utilisés ensemble")
+	}
+
+	if dir == "" && profile == "" {
+		if Dir() != "" {
+			return nil // Déjà choisi par le processus parent
+		}
+		profile = os.Getenv(ProfileEnv)
+	}
+	if dir != "" {
+		return UseDir(dir, "")
+	}
+	if profile == "" {
+		return nil
+	}
+	profileDir, err := ProfileDir(profile)
+	if err != nil {
+		return err
+	}
+	return UseDir(profileDir, profile)
+}
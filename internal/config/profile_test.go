@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withoutProfile isole le test du profil éventuellement choisi par l'environnement, avec un
+// dossier personnel temporaire
+func withoutProfile(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(DirEnv, "")
+	t.Setenv(ProfileEnv, "")
+	return home
+}
+
+func TestSelectProfile(t *testing.T) {
+	home := withoutProfile(t)
+
+	if err := SelectFromArgs([]string{"-u"}); err != nil || Dir() != "" || ActiveProfile() != "" {
+		t.Fatalf("sans option, le répertoire courant doit être conservé: %q, %v", Dir(), err)
+	}
+
+	if err := SelectFromArgs([]string{"--profile=agressif", "-u"}); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(home, ".bot-spot", "profiles", "agressif")
+	if Dir() != want || ActiveProfile() != "agressif" {
+		t.Errorf("profil %q dans %q, want agressif dans %q", ActiveProfile(), Dir(), want)
+	}
+	if info, err := os.Stat(want); err != nil || !info.IsDir() {
+		t.Errorf("le répertoire du profil devrait être créé: %v", err)
+	}
+	if Path("planner.pid") != filepath.Join(want, "planner.pid") {
+		t.Errorf("Path = %q, want un fichier du profil", Path("planner.pid"))
+	}
+
+	// bot.conf est lu dans le répertoire du profil
+	if err := os.WriteFile(filepath.Join(want, ConfigFilename), []byte("BINANCE_PERCENT=7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := ReadConfigValues()
+	if err != nil || values["BINANCE_PERCENT"] != "7" {
+		t.Errorf("bot.conf du profil: %v, %v", values, err)
+	}
+
+	// Les commandes lancées par le daemon héritent du répertoire choisi
+	if err := SelectFromArgs(nil); err != nil || Dir() != want {
+		t.Errorf("le répertoire hérité devrait être conservé: %q, %v", Dir(), err)
+	}
+}
+
+func TestSelectConfigDir(t *testing.T) {
+	withoutProfile(t)
+	dir := filepath.Join(t.TempDir(), "prudent")
+
+	if err := SelectFromArgs([]string{"--config=" + dir}); err != nil {
+		t.Fatal(err)
+	}
+	if Dir() != dir || ActiveProfile() != dir {
+		t.Errorf("répertoire %q (profil %q), want %q", Dir(), ActiveProfile(), dir)
+	}
+
+	if err := SelectFromArgs([]string{"--config=" + dir, "--profile=agressif"}); err == nil {
+		t.Error("--config et --profile ensemble devraient être refusés")
+	}
+	for _, name := range []string{"..", "a/b", `a\b`} {
+		if err := SelectFromArgs([]string{"--profile=" + name}); err == nil {
+			t.Errorf("le nom de profil %q devrait être refusé", name)
+		}
+	}
+}
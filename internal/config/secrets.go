@@ -0,0 +1,176 @@
+// internal/config/secrets.go
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Préfixes des valeurs de clés résolues hors de bot.conf
+const (
+	envSecretPrefix      = "env:"      // env:NOM lit la variable d'environnement NOM
+	keychainSecretPrefix = "keychain:" // keychain:NOM lit l'entrée NOM du magasin d'identifiants du système
+)
+
+// KeychainService est le service sous lequel les clés sont rangées dans le magasin d'identifiants
+const KeychainService = "neodream-bot-spot"
+
+// ErrSecretNotFound est renvoyée lorsqu'une entrée est absente du magasin d'identifiants
+var ErrSecretNotFound = errors.New("entrée introuvable dans le magasin d'identifiants")
+
+// resolveSecret lit la variable key et résout les références env: et keychain:.
+// Les messages d'erreur citent la variable et la référence, jamais la valeur du secret.
+func resolveSecret(key string) (string, error) {
+	return ResolveSecretValue(key, getEnvString(key, ""))
+}
+
+// ResolveSecretValue résout la valeur value de la variable key lorsqu'elle référence une
+// variable d'environnement (env:) ou le magasin d'identifiants (keychain:)
+func ResolveSecretValue(key, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(value, envSecretPrefix):
+		name := strings.TrimPrefix(value, envSecretPrefix)
+		secret := os.Getenv(name)
+		if secret == "" {
+			return "", fmt.Errorf("%s: la variable d'environnement %s n'est pas définie", key, name)
+		}
+		return secret, nil
+
+	case strings.HasPrefix(value, keychainSecretPrefix):
+		name := strings.TrimPrefix(value, keychainSecretPrefix)
+		secret, err := ReadKeychain(name)
+		if err != nil {
+			return "", fmt.Errorf("%s: lecture de %q dans le magasin d'identifiants impossible: %w", key, name, err)
+		}
+		if secret == "" {
+			return "", fmt.Errorf("%s: l'entrée %q du magasin d'identifiants est vide", key, name)
+		}
+		return secret, nil
+	}
+
+	return value, nil
+}
+
+// KeychainReference retourne la valeur à écrire dans bot.conf pour lire name depuis le magasin d'identifiants
+func KeychainReference(name string) string {
+	return keychainSecretPrefix + name
+}
+
+// ReadSecretInput affiche prompt et lit une ligne sans écho à l'écran
+func ReadSecretInput(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	restore := disableEcho()
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	restore()
+	fmt.Println()
+
+	if err != nil && line == "" {
+		return "", fmt.Errorf("lecture de la saisie impossible: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// SetConfigValue remplace (ou ajoute) la ligne KEY=valeur de bot.conf en conservant le reste du fichier
+func SetConfigValue(key, value string) error {
+	return SetConfigValues(map[string]string{key: value})
+}
+
+// SetConfigValues remplace (ou ajoute, par ordre alphabétique) les lignes KEY=valeur de bot.conf
+// en conservant le reste du fichier. Le fichier est réécrit en une fois: un arrêt en cours
+// d'écriture laisse l'ancienne version intacte.
+func SetConfigValues(values map[string]string) error {
+	content, err := os.ReadFile(Path(ConfigFilename))
+	if err != nil {
+		return fmt.Errorf("lecture de %s impossible: %w", ConfigFilename, err)
+	}
+
+	newline := []byte("\n")
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = []byte("\r\n")
+	}
+
+	lines := bytes.Split(content, newline)
+	replaced := make(map[string]bool, len(values))
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		for key, value := range values {
+			if bytes.HasPrefix(trimmed, []byte(key+"=")) {
+				lines[i] = []byte(key + "=" + value)
+				replaced[key] = true
+			}
+		}
+	}
+
+	missing := make([]string, 0, len(values))
+	for key := range values {
+		if !replaced[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		if len(lines) > 0 && len(bytes.TrimSpace(lines[len(lines)-1])) == 0 {
+			lines = lines[:len(lines)-1]
+		}
+		for _, key := range missing {
+			lines = append(lines, []byte(key+"="+values[key]))
+		}
+		lines = append(lines, nil)
+	}
+
+	if err := writeFileAtomic(Path(ConfigFilename), bytes.Join(lines, newline)); err != nil {
+		return fmt.Errorf("écriture de %s impossible: %w", ConfigFilename, err)
+	}
+	return nil
+}
+
+// ReadConfigValues retourne les variables définies dans bot.conf, sans les appliquer ni résoudre
+// les références env: et keychain:
+func ReadConfigValues() (map[string]string, error) {
+	values, err := godotenv.Read(Path(ConfigFilename))
+	if err != nil {
+		return nil, fmt.Errorf("lecture de %s impossible: %w", ConfigFilename, err)
+	}
+	return values, nil
+}
+
+// writeFileAtomic écrit content dans un fichier temporaire du même dossier puis le renomme en path
+func writeFileAtomic(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
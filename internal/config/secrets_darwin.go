@@ -0,0 +1,39 @@
+// internal/config/secrets_darwin.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ReadKeychain lit une entrée du trousseau macOS
+func ReadKeychain(name string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", KeychainService, "-a", name, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// security retourne 44 lorsque l'entrée n'existe pas
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("security find-generic-password: %v", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// WriteKeychain crée ou remplace une entrée du trousseau macOS. La commande est transmise
+// sur l'entrée standard de "security -i" pour que le secret n'apparaisse pas dans la liste des processus.
+func WriteKeychain(name, secret string) error {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quote(KeychainService), quote(name), quote(secret)))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("security add-generic-password: %v", err)
+	}
+	return nil
+}
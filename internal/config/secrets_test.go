@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("BINANCE_API_KEY", "env:TEST_BINANCE_KEY")
+	t.Setenv("TEST_BINANCE_KEY", "s3cr3t-value")
+	t.Setenv("MEXC_API_KEY", "plain-key")
+	t.Setenv("KRAKEN_API_KEY", "env:TEST_UNDEFINED_KEY")
+
+	if got, err := resolveSecret("BINANCE_API_KEY"); err != nil || got != "s3cr3t-value" {
+		t.Errorf("env: résolu en %q (erreur: %v)", got, err)
+	}
+	if got, err := resolveSecret("MEXC_API_KEY"); err != nil || got != "plain-key" {
+		t.Errorf("valeur en clair résolue en %q (erreur: %v)", got, err)
+	}
+
+	// L'erreur nomme la variable manquante
+	_, err := resolveSecret("KRAKEN_API_KEY")
+	if err == nil || !strings.Contains(err.Error(), "TEST_UNDEFINED_KEY") {
+		t.Errorf("erreur attendue pour une variable non définie, obtenu %v", err)
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	original := "# Clés\r\nBINANCE_API_KEY=old-secret\r\nBINANCE_SECRET_KEY=\r\n"
+	if err := os.WriteFile(ConfigFilename, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetConfigValue("BINANCE_API_KEY", KeychainReference("BINANCE_API_KEY")); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigValue("KRAKEN_API_KEY", KeychainReference("KRAKEN_API_KEY")); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(ConfigFilename)
+	want := "# Clés\r\nBINANCE_API_KEY=keychain:BINANCE_API_KEY\r\nBINANCE_SECRET_KEY=\r\nKRAKEN_API_KEY=keychain:KRAKEN_API_KEY\r\n"
+	if string(content) != want {
+		t.Errorf("bot.conf = %q, attendu %q", content, want)
+	}
+}
+
+func TestSetConfigValues(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	original := "EXCHANGE=BINANCE\nMEXC_PERCENT=4\n"
+	if err := os.WriteFile(ConfigFilename, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SetConfigValues(map[string]string{
+		"EXCHANGE":         "MEXC",
+		"MEXC_SELL_OFFSET": "250",
+		"MEXC_BUY_OFFSET":  "-250",
+		"MEXC_PERCENT":     "5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(ConfigFilename)
+	want := "EXCHANGE=MEXC\nMEXC_PERCENT=5\nMEXC_BUY_OFFSET=-250\nMEXC_SELL_OFFSET=250\n"
+	if string(content) != want {
+		t.Errorf("bot.conf = %q, attendu %q", content, want)
+	}
+
+	// Ni fichier temporaire restant, ni permissions élargies
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("%d fichiers dans le dossier, attendu seulement %s", len(entries), ConfigFilename)
+	}
+	if info, err := os.Stat(ConfigFilename); err == nil && info.Mode().Perm() != 0600 && runtime.GOOS != "windows" {
+		t.Errorf("permissions %v, attendu 0600", info.Mode().Perm())
+	}
+
+	values, err := ReadConfigValues()
+	if err != nil || values["EXCHANGE"] != "MEXC" || values["MEXC_BUY_OFFSET"] != "-250" {
+		t.Errorf("valeurs relues %v (erreur: %v)", values, err)
+	}
+}
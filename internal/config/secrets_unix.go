@@ -0,0 +1,35 @@
+//go:build !windows && !darwin
+
+// internal/config/secrets_unix.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ReadKeychain lit une entrée du service secret freedesktop (GNOME Keyring, KWallet) via secret-tool
+func ReadKeychain(name string) (string, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", KeychainService, "account", name).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// secret-tool retourne 1 sans rien afficher lorsque l'entrée n'existe pas
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(exitErr.Stderr) == 0 {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("secret-tool lookup: %v", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// WriteKeychain crée ou remplace une entrée du service secret; secret-tool lit le secret sur l'entrée standard
+func WriteKeychain(name, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", KeychainService+" "+name, "service", KeychainService, "account", name)
+	cmd.Stdin = strings.NewReader(secret)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("secret-tool store: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// internal/config/secrets_windows.go
+package config
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Gestionnaire d'identifiants Windows (advapi32) et mode de la console (kernel32)
+var (
+	advapi32      = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead  = advapi32.NewProc("CredReadW")
+	procCredWrite = advapi32.NewProc("CredWriteW")
+	procCredFree  = advapi32.NewProc("CredFree")
+
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = 1168
+	enableEchoInput         = 0x0004
+)
+
+// credential correspond à la structure CREDENTIALW
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialTarget retourne le nom de l'entrée dans le gestionnaire d'identifiants
+func credentialTarget(name string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(KeychainService + ":" + name)
+}
+
+// ReadKeychain lit une entrée du gestionnaire d'identifiants Windows
+func ReadKeychain(name string) (string, error) {
+	target, err := credentialTarget(name)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	ret, _, callErr := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if errno, ok := callErr.(syscall.Errno); ok && errno == errorNotFound {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("CredReadW: %v", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+// WriteKeychain crée ou remplace une entrée du gestionnaire d'identifiants Windows
+func WriteKeychain(name, secret string) error {
+	target, err := credentialTarget(name)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+
+	ret, _, callErr := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ret == 0 {
+		return fmt.Errorf("CredWriteW: %v", callErr)
+	}
+	return nil
+}
+
+// disableEcho masque la saisie dans la console et retourne la fonction qui rétablit l'écho
+func disableEcho() func() {
+	handle := uintptr(syscall.Stdin)
+
+	var mode uint32
+	if ret, _, _ := procGetConsoleMode.Call(handle, uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return func() {}
+	}
+
+	procSetConsoleMode.Call(handle, uintptr(mode&^enableEchoInput))
+	return func() {
+		procSetConsoleMode.Call(handle, uintptr(mode))
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+// internal/config/terminal_unix.go
+package config
+
+import (
+	"os"
+	"os/exec"
+)
+
+// disableEcho masque la saisie dans le terminal et retourne la fonction qui rétablit l'écho
+func disableEcho() func() {
+	off := exec.Command("stty", "-echo")
+	off.Stdin = os.Stdin
+	if off.Run() != nil {
+		return func() {}
+	}
+
+	return func() {
+		on := exec.Command("stty", "echo")
+		on.Stdin = os.Stdin
+		on.Run()
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Problem est une anomalie de la configuration. Une erreur empêche le démarrage; un
+// avertissement signale une valeur corrigée, ignorée ou sans effet.
+type Problem struct {
+	Key     string // clé concernée, vide si le problème ne porte pas sur une clé
+	Line    int    // ligne de la clé dans bot.conf, 0 si inconnue
+	Message string
+	Warning bool
+}
+
+// String retourne le problème précédé de sa position dans bot.conf quand elle est connue
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", ConfigFilename, p.Line, p.Message)
+	}
+	return p.Message
+}
+
+// ValidationError regroupe toutes les erreurs de configuration, pour les corriger en une fois
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("invalid configuration (%d error(s)):", len(e.Problems))}
+	for _, problem := range e.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	// Clés lues par le chargement de la configuration, pour repérer les clés inconnues de bot.conf
+	knownKeys   = make(map[string]bool)
+	knownKeysMu sync.Mutex
+
+	// Avertissements relevés pendant la lecture des valeurs (nombres illisibles...)
+	loadProblems []Problem
+)
+
+// noteKey enregistre une clé lue par la configuration
+func noteKey(key string) {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+	knownKeys[key] = true
+}
+
+// noteLoadWarning enregistre un avertissement relevé pendant la lecture d'une valeur
+func noteLoadWarning(key, format string, args ...interface{}) {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+	loadProblems = append(loadProblems, Problem{Key: key, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// takeLoadWarnings retourne et oublie les avertissements de lecture
+func takeLoadWarnings() []Problem {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+	problems := loadProblems
+	loadProblems = nil
+	return problems
+}
+
+// problemKey est la clé qui ouvre un message ("SELL_OFFSET cannot be negative...")
+var problemKey = regexp.MustCompile(`^[A-Z][A-Z0-9_]+\b`)
+
+// warnf enregistre un avertissement portant sur la clé qui ouvre le message
+func (c *Config) warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	c.problems = append(c.problems, Problem{Key: problemKey.FindString(message), Message: message, Warning: true})
+}
+
+// errorf enregistre une erreur portant sur la clé qui ouvre le message
+func (c *Config) errorf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	c.problems = append(c.problems, Problem{Key: problemKey.FindString(message), Message: message})
+}
+
+// Problems retourne les erreurs et avertissements relevés par la dernière validation
+func (c *Config) Problems() []Problem {
+	return c.problems
+}
+
+// validationError retourne les erreurs relevées, nil s'il n'y en a pas
+func (c *Config) validationError() error {
+	var errs []Problem
+	for _, problem := range c.problems {
+		if !problem.Warning {
+			errs = append(errs, problem)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: errs}
+}
+
+// checkUnknownKeys signale les clés de bot.conf qu'aucun paramètre ne lit, probablement mal
+// orthographiées, en proposant la clé connue la plus proche
+func (c *Config) checkUnknownKeys(values map[string]string) {
+	knownKeysMu.Lock()
+	known := make([]string, 0, len(knownKeys))
+	for key := range knownKeys {
+		known = append(known, key)
+	}
+	knownKeysMu.Unlock()
+	sort.Strings(known)
+
+	isKnown := make(map[string]bool, len(known))
+	for _, key := range known {
+		isKnown[key] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if isKnown[key] || strings.HasPrefix(key, "ALIAS_") {
+			continue
+		}
+		if suggestion := closestKey(key, known); suggestion != "" {
+			c.warnf("%s is not a known setting and is ignored (did you mean %s?)", key, suggestion)
+		} else {
+			c.warnf("%s is not a known setting and is ignored", key)
+		}
+	}
+}
+
+// closestKey retourne la clé connue la plus proche de key, si elle en est assez proche pour
+// être une faute de frappe
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", len(key)/3+1
+	for _, candidate := range known {
+		if distance := levenshtein(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// levenshtein retourne la distance d'édition entre deux chaînes
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// locateProblems complète les problèmes avec la ligne de leur clé dans bot.conf
+func locateProblems(problems []Problem) {
+	file, err := os.Open(Path(ConfigFilename))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimPrefix(line, "export ")
+		if key, _, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(line, "#") {
+			lines[strings.TrimSpace(key)] = number
+		}
+	}
+
+	for i := range problems {
+		key := problems[i].Key
+		line, found := lines[key]
+		if !found {
+			// BINANCE_PERCENT hérite de DEFAULT_PERCENT quand il n'est pas défini
+			if _, setting, ok := strings.Cut(key, "_"); ok {
+				line = lines["DEFAULT_"+setting]
+			}
+		}
+		problems[i].Line = line
+	}
+}
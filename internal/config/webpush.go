@@ -0,0 +1,58 @@
+// internal/config/webpush.go
+package config
+
+import (
+	"strings"
+
+	"main/internal/webpush"
+)
+
+// WebPushDefaultEvent est l'événement envoyé aux navigateurs abonnés quand WEB_PUSH_EVENTS est vide
+const WebPushDefaultEvent = "sell_filled"
+
+// WebPush configure les notifications envoyées aux navigateurs abonnés depuis le tableau de
+// bord. Les clés VAPID sont créées par --push-keys; sans elles, le tableau de bord ne propose
+// pas l'abonnement.
+type WebPush struct {
+	PublicKey  string   // Clé publique VAPID (base64url), transmise au navigateur
+	PrivateKey string   // Clé privée VAPID (env: et keychain: acceptés)
+	Subject    string   // Contact de l'expéditeur exigé par les serveurs de push (mailto: ou https:)
+	Events     []string // Événements envoyés (vide = sell_filled)
+}
+
+// Enabled indique si les clés VAPID sont configurées
+func (w WebPush) Enabled() bool {
+	return w.PublicKey != "" && w.PrivateKey != ""
+}
+
+// Accepts indique si l'événement passe le filtre WEB_PUSH_EVENTS
+func (w WebPush) Accepts(event string) bool {
+	if len(w.Events) == 0 {
+		return event == WebPushDefaultEvent
+	}
+	for _, accepted := range w.Events {
+		if strings.EqualFold(accepted, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebPush vérifie la paire de clés VAPID et le contact, et désactive les notifications
+// Web Push si l'un d'eux est invalide
+func (c *Config) validateWebPush() {
+	push := &c.WebPush
+	if push.PublicKey == "" && push.PrivateKey == "" {
+		return
+	}
+	if push.PublicKey == "" || push.PrivateKey == "" {
+		c.errorf("WEB_PUSH_PUBLIC_KEY and WEB_PUSH_PRIVATE_KEY must both be set (--push-keys creates them), web push disabled")
+	} else if err := webpush.ValidateKeys(push.PublicKey, push.PrivateKey); err != nil {
+		c.errorf("WEB_PUSH_PRIVATE_KEY: %v, web push disabled", err)
+	} else if !strings.HasPrefix(push.Subject, "mailto:") && !strings.HasPrefix(push.Subject, "https://") {
+		c.errorf("WEB_PUSH_SUBJECT must be a mailto: or https:// contact, web push disabled")
+	} else {
+		return
+	}
+	push.PublicKey, push.PrivateKey = "", ""
+}
@@ -23,6 +23,41 @@ type Accumulation struct {
 	CancelPrice      float64   `json:"cancelPrice"`      // Prix du BTC au moment de l'annulation
 	Deviation        float64   `json:"deviation"`        // Déviation en pourcentage qui a déclenché l'accumulation
 	CreatedAt        time.Time `json:"createdAt"`        // Date de création de l'accumulation
+	// Frais de l'achat d'origine, repris par le cycle de la revente
+	BuyFees       float64 `json:"buyFees"`
+	BuyFeeBTC     float64 `json:"buyFeeBTC"`
+	FeesEstimated bool    `json:"feesEstimated"`
+	// Revente du BTC accumulé (ACCU_SELL_TRIGGER_PRICE): cycle créé et date, vides tant que le BTC est conservé
+	ConvertedCycleId int32     `json:"convertedCycleId"`
+	ConvertedAt      time.Time `json:"convertedAt"`
+}
+
+// Converted indique si le BTC accumulé a été remis en vente dans un cycle
+func (a *Accumulation) Converted() bool {
+	return a.ConvertedCycleId != 0
+}
+
+// UnrealizedGain retourne l'évolution de la valeur du BTC accumulé depuis l'annulation:
+// sa valeur au prix actuel moins sa valeur au prix d'annulation
+func (a *Accumulation) UnrealizedGain(currentPrice float64) float64 {
+	return a.Quantity*currentPrice - a.Quantity*a.CancelPrice
+}
+
+// readBuyFees complète une accumulation avec les frais de l'achat d'origine
+func readBuyFees(accumulation *Accumulation, doc *clover.Document) {
+	accumulation.BuyFees = docFloat(doc, "buyFees")
+	accumulation.BuyFeeBTC = docFloat(doc, "buyFeeBTC")
+	accumulation.FeesEstimated, _ = doc.Get("feesEstimated").(bool)
+}
+
+// readConversion complète une accumulation avec sa revente éventuelle
+func readConversion(accumulation *Accumulation, doc *clover.Document) {
+	accumulation.ConvertedCycleId = int32(docFloat(doc, "convertedCycleId"))
+	if timeStr, ok := doc.Get("convertedAt").(string); ok && timeStr != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, timeStr); err == nil {
+			accumulation.ConvertedAt = parsedTime
+		}
+	}
 }
 
 // AccumulationRepository gère les opérations de base de données pour les accumulations
@@ -69,6 +104,8 @@ func (r *AccumulationRepository) FindAll() ([]*Accumulation, error) {
 			Deviation:        doc.Get("deviation").(float64),
 			CreatedAt:        createdAt,
 		}
+		readBuyFees(accumulation, doc)
+		readConversion(accumulation, doc)
 		accumulations = append(accumulations, accumulation)
 	}
 
@@ -113,6 +150,8 @@ func (r *AccumulationRepository) FindByExchange(exchange string) ([]*Accumulatio
 			Deviation:        doc.Get("deviation").(float64),
 			CreatedAt:        createdAt,
 		}
+		readBuyFees(accumulation, doc)
+		readConversion(accumulation, doc)
 		accumulations = append(accumulations, accumulation)
 	}
 
@@ -156,12 +195,18 @@ func (r *AccumulationRepository) FindByIdInt(id int32) (*Accumulation, error) {
 		Deviation:        doc.Get("deviation").(float64),
 		CreatedAt:        createdAt,
 	}
+	readBuyFees(accumulation, doc)
+	readConversion(accumulation, doc)
 
 	return accumulation, nil
 }
 
 // Save enregistre une accumulation dans la base de données
 func (r *AccumulationRepository) Save(accumulation *Accumulation) (string, error) {
+	if interceptWrite(WriteIntent{Collection: AccumulationCollectionName, Op: "save", IdInt: accumulation.IdInt}) {
+		return "", nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -185,6 +230,15 @@ func (r *AccumulationRepository) Save(accumulation *Accumulation) (string, error
 	doc.Set("cancelPrice", accumulation.CancelPrice)
 	doc.Set("deviation", accumulation.Deviation)
 	doc.Set("createdAt", accumulation.CreatedAt.Format(time.RFC3339))
+	doc.Set("buyFees", accumulation.BuyFees)
+	doc.Set("buyFeeBTC", accumulation.BuyFeeBTC)
+	doc.Set("feesEstimated", accumulation.FeesEstimated)
+	doc.Set("convertedCycleId", accumulation.ConvertedCycleId)
+	if !accumulation.ConvertedAt.IsZero() {
+		doc.Set("convertedAt", accumulation.ConvertedAt.Format(time.RFC3339))
+	} else {
+		doc.Set("convertedAt", "")
+	}
 
 	docId, err := r.db.InsertOne(AccumulationCollectionName, doc)
 	if err != nil {
@@ -196,6 +250,10 @@ func (r *AccumulationRepository) Save(accumulation *Accumulation) (string, error
 
 // DeleteByIdInt supprime une accumulation par son ID entier
 func (r *AccumulationRepository) DeleteByIdInt(idInt int32) error {
+	if interceptWrite(WriteIntent{Collection: AccumulationCollectionName, Op: "delete", IdInt: idInt}) {
+		return nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -204,6 +262,24 @@ func (r *AccumulationRepository) DeleteByIdInt(idInt int32) error {
 		Delete()
 }
 
+// MarkConverted enregistre la revente d'une accumulation dans le cycle indiqué
+func (r *AccumulationRepository) MarkConverted(idInt, cycleIdInt int32) error {
+	updates := map[string]interface{}{
+		"convertedCycleId": cycleIdInt,
+		"convertedAt":      time.Now().Format(time.RFC3339),
+	}
+	if interceptWrite(WriteIntent{Collection: AccumulationCollectionName, Op: "update", IdInt: idInt, Fields: updates}) {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.db.Query(AccumulationCollectionName).
+		Where(clover.Field("idInt").Eq(idInt)).
+		Update(updates)
+}
+
 // CountByExchange compte les accumulations par exchange
 func (r *AccumulationRepository) CountByExchange(exchange string) (int, error) {
 	r.mu.Lock()
@@ -216,7 +292,7 @@ func (r *AccumulationRepository) CountByExchange(exchange string) (int, error) {
 	return count, err
 }
 
-// GetTotalAccumulatedBTC retourne le total de BTC accumulé pour un exchange
+// GetTotalAccumulatedBTC retourne le total de BTC accumulé et conservé pour un exchange
 func (r *AccumulationRepository) GetTotalAccumulatedBTC(exchange string) (float64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -231,6 +307,10 @@ func (r *AccumulationRepository) GetTotalAccumulatedBTC(exchange string) (float6
 
 	var totalBTC float64
 	for _, doc := range docs {
+		// Le BTC remis en vente est suivi par son cycle
+		if docFloat(doc, "convertedCycleId") != 0 {
+			continue
+		}
 		quantity := doc.Get("quantity").(float64)
 		totalBTC += quantity
 	}
@@ -238,7 +318,7 @@ func (r *AccumulationRepository) GetTotalAccumulatedBTC(exchange string) (float6
 	return totalBTC, nil
 }
 
-// GetTotalAccumulatedValue retourne la valeur totale accumulée pour un exchange
+// GetTotalAccumulatedValue retourne la valeur totale accumulée et conservée pour un exchange
 func (r *AccumulationRepository) GetTotalAccumulatedValue(exchange string) (float64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -253,6 +333,10 @@ func (r *AccumulationRepository) GetTotalAccumulatedValue(exchange string) (floa
 
 	var totalValue float64
 	for _, doc := range docs {
+		// Le profit de la revente sera compté à l'exécution de son cycle
+		if docFloat(doc, "convertedCycleId") != 0 {
+			continue
+		}
 		quantity := doc.Get("quantity").(float64)
 		targetSellPrice := doc.Get("targetSellPrice").(float64)
 		totalValue += quantity * targetSellPrice
@@ -289,8 +373,9 @@ func (r *AccumulationRepository) getNextId() int32 {
 	return int32(nextId)
 }
 
-// Fonction pour obtenir les statistiques des accumulations par exchange
-func (r *AccumulationRepository) GetExchangeAccumulationStats(exchange string) (map[string]interface{}, error) {
+// Fonction pour obtenir les statistiques des accumulations par exchange. La valeur actuelle et
+// le gain latent du BTC conservé sont calculés au prix currentPrice (nuls si le prix est inconnu).
+func (r *AccumulationRepository) GetExchangeAccumulationStats(exchange string, currentPrice float64) (map[string]interface{}, error) {
 	accumulations, err := r.FindByExchange(exchange)
 	if err != nil {
 		return nil, err
@@ -300,24 +385,43 @@ func (r *AccumulationRepository) GetExchangeAccumulationStats(exchange string) (
 	totalOriginalValue := 0.0
 	totalCancelValue := 0.0
 	averageDeviation := 0.0
-
-	if len(accumulations) > 0 {
-		for _, acc := range accumulations {
-			totalQuantity += acc.Quantity
-			totalOriginalValue += acc.Quantity * acc.TargetSellPrice
-			totalCancelValue += acc.Quantity * acc.CancelPrice
-			averageDeviation += acc.Deviation
+	held := 0
+	convertedCount := 0
+	convertedQuantity := 0.0
+
+	// Les accumulations remises en vente sont comptées à part
+	for _, acc := range accumulations {
+		if acc.Converted() {
+			convertedCount++
+			convertedQuantity += acc.Quantity
+			continue
 		}
-		averageDeviation /= float64(len(accumulations))
+		held++
+		totalQuantity += acc.Quantity
+		totalOriginalValue += acc.Quantity * acc.TargetSellPrice
+		totalCancelValue += acc.Quantity * acc.CancelPrice
+		averageDeviation += acc.Deviation
+	}
+	if held > 0 {
+		averageDeviation /= float64(held)
 	}
 
 	stats := map[string]interface{}{
-		"count":              len(accumulations),
+		"count":              held,
 		"totalQuantity":      totalQuantity,
 		"totalOriginalValue": totalOriginalValue,
 		"totalCancelValue":   totalCancelValue,
 		"savedValue":         totalOriginalValue - totalCancelValue,
 		"averageDeviation":   averageDeviation,
+		"hasCurrentPrice":    currentPrice > 0,
+		"currentValue":       0.0,
+		"unrealizedGain":     0.0,
+		"convertedCount":     convertedCount,
+		"convertedQuantity":  convertedQuantity,
+	}
+	if currentPrice > 0 {
+		stats["currentValue"] = totalQuantity * currentPrice
+		stats["unrealizedGain"] = totalQuantity*currentPrice - totalCancelValue
 	}
 
 	return stats, nil
@@ -0,0 +1,58 @@
+// internal/database/archive.go
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// ArchiveCollectionName est la collection des cycles complétés archivés (commande --archive)
+const ArchiveCollectionName = "cycles_archive"
+
+// ArchiveCompletedBefore déplace vers l'archive les cycles complétés avant la date donnée
+// et retourne le nombre de cycles archivés. Les cycles sans date de complétion sont conservés.
+func (r *CycleRepository) ArchiveCompletedBefore(before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return 0, fmt.Errorf("la base de données n'est pas initialisée")
+	}
+	if r.collection != CollectionName {
+		return 0, fmt.Errorf("seuls les cycles actifs peuvent être archivés")
+	}
+
+	docs, err := r.db.Query(r.collection).
+		Where(clover.Field("status").Eq("completed")).
+		MatchPredicate(func(doc *clover.Document) bool {
+			return completedBetween(doc, time.Time{}, before)
+		}).
+		FindAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	// Copier les documents (même identifiant) avant de les supprimer des cycles actifs
+	ids := make([]interface{}, 0, len(docs))
+	copies := make([]*clover.Document, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ObjectId())
+		copies = append(copies, doc.Copy())
+	}
+
+	if err := r.db.Insert(ArchiveCollectionName, copies...); err != nil {
+		return 0, fmt.Errorf("erreur lors de l'écriture dans l'archive: %w", err)
+	}
+
+	err = r.db.Query(r.collection).Where(clover.Field("_id").In(ids...)).Delete()
+	if err != nil {
+		return 0, fmt.Errorf("cycles copiés dans l'archive mais non supprimés des cycles actifs: %w", err)
+	}
+
+	return len(docs), nil
+}
@@ -6,19 +6,25 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"main/internal/config"
 )
 
 const CollectionName = "cycles"
 
 func GetDatabasePath() string {
-	// Obtenir le répertoire de travail courant
-	workDir, err := os.Getwd()
-	if err != nil {
-		log.Fatal(err)
+	// Répertoire de configuration (--config, --profile), sinon répertoire de travail courant
+	baseDir := config.Dir()
+	if baseDir == "" {
+		workDir, err := os.Getwd()
+		if err != nil {
+			log.Fatal(err)
+		}
+		baseDir = workDir
 	}
 
 	// Créer un chemin pour la base de données dans le projet
-	databasePath := filepath.Join(workDir, "data", "db")
+	databasePath := filepath.Join(baseDir, "data", "db")
 
 	// Créer le dossier s'il n'existe pas
 	if _, err := os.Stat(databasePath); errors.Is(err, os.ErrNotExist) {
@@ -45,14 +51,121 @@ type Cycle struct {
 	SellId      string    `json:"sellId"`
 	CreatedAt   time.Time `json:"createdAt"`   // Date d'achat (création)
 	CompletedAt time.Time `json:"completedAt"` // Date de vente (complétion)
+	// Date à laquelle la mise à jour a constaté l'exécution de la vente (repli de CompletedAt)
+	ObservedCompletedAt time.Time `json:"observedCompletedAt"`
 
 	// Nouveaux champs ajoutés pour le calcul précis des gains
 	PurchaseAmountUSDC float64 `json:"purchaseAmountUSDC"`
 	SaleAmountUSDC     float64 `json:"saleAmountUSDC"`
 	ExactExchangeGain  float64 `json:"exactExchangeGain"`
 	TotalFees          float64 `json:"totalFees"` // Total des frais (achat + vente)
+	BuyFees            float64 `json:"buyFees"`   // Frais de l'achat (inclus dans TotalFees)
+	SellFees           float64 `json:"sellFees"`  // Frais de la vente (inclus dans TotalFees)
+	// Frais de l'achat prélevés en BTC par l'exchange (Binance sans paiement en BNB): déduits de
+	// Quantity et comptés en USDC dans TotalFees
+	BuyFeeBTC float64 `json:"buyFeeBTC"`
+	// Frais payés dans un autre actif (BNB sur Binance), tels que prélevés: ils sont comptés dans
+	// BuyFees et SellFees au prix de l'actif lors de l'exécution
+	BuyFeeAsset        string  `json:"buyFeeAsset,omitempty"`
+	BuyFeeAssetAmount  float64 `json:"buyFeeAssetAmount,omitempty"`
+	SellFeeAsset       string  `json:"sellFeeAsset,omitempty"`
+	SellFeeAssetAmount float64 `json:"sellFeeAssetAmount,omitempty"`
+	// Frais estimés selon le taux standard de l'exchange faute de réponse de l'API
+	FeesEstimated bool `json:"feesEstimated"`
+
+	// Prix moyens réellement exécutés (0 si inconnus) ; BuyPrice/SellPrice restent les prix limites
+	BuyFillPrice  float64 `json:"buyFillPrice"`
+	SellFillPrice float64 `json:"sellFillPrice"`
+
+	// Cycle reconstitué depuis l'historique des trades (commande --import)
+	Imported bool `json:"imported"`
+
+	// Cycle ignoré par la mise à jour (--pause / --resume), toujours compté dans l'exposition
+	Paused bool `json:"paused"`
+
+	// Étiquettes libres posées depuis le tableau de bord (actions groupées), sans effet sur le trading
+	Tags []string `json:"tags"`
+
+	// Nombre de replacements de l'ordre d'achat après dépassement de la déviation de prix
+	RepriceCount int `json:"repriceCount"`
+
+	// Cause (CancelReason*) et date de l'annulation, vides si le cycle n'a pas été annulé
+	CancelReason string    `json:"cancelReason"`
+	CancelledAt  time.Time `json:"cancelledAt"`
+	// Annulation de l'achat en échec (statut cancel_pending): nombre de tentatives et dernière erreur
+	CancelRetryCount int    `json:"cancelRetryCount"`
+	CancelRetryError string `json:"cancelRetryError"`
+
+	// Jambe stop-limit d'une vente OCO (SellId est la jambe limite), vide sans OCO
+	StopId    string  `json:"stopId"`
+	StopPrice float64 `json:"stopPrice"` // Prix limite de la jambe stop
+	// La vente a été exécutée par la jambe stop de l'OCO
+	StoppedOut bool `json:"stoppedOut"`
+
+	// Identifiants client des ordres d'achat et de vente (common.ClientOrderID), qui permettent
+	// de retrouver un ordre créé avant un arrêt brutal au lieu de le placer une seconde fois
+	BuyClientOrderId  string `json:"buyClientOrderId"`
+	SellClientOrderId string `json:"sellClientOrderId"`
+
+	// Groupe des tranches d'un achat échelonné: ID du cycle de la première tranche (0 sans échelonnement)
+	GroupId int32 `json:"groupId"`
+
+	// Moyenne à la baisse en cours (--average-down): étape (AverageDown*), achat supplémentaire et
+	// exécution relevée, conservés jusqu'à la fusion pour reprendre après un arrêt brutal
+	AverageDownState         string  `json:"averageDownState"`
+	AverageDownBuyId         string  `json:"averageDownBuyId"`
+	AverageDownClientOrderId string  `json:"averageDownClientOrderId"`
+	AverageDownQuantity      float64 `json:"averageDownQuantity"` // Quantité exécutée de l'achat supplémentaire
+	AverageDownPrice         float64 `json:"averageDownPrice"`    // Prix moyen exécuté de l'achat supplémentaire
+	AverageDownFees          float64 `json:"averageDownFees"`
+	// Nombre d'achats supplémentaires déjà fusionnés dans le cycle
+	AverageDownCount int `json:"averageDownCount"`
+
+	// Vente non placée après l'exécution de l'achat (statut sell sans SellId): nombre d'échecs,
+	// dernière erreur et date de la prochaine tentative
+	SellRetryCount int       `json:"sellRetryCount"`
+	SellRetryError string    `json:"sellRetryError"`
+	SellRetryAt    time.Time `json:"sellRetryAt"`
+
+	// Réponses brutes de l'exchange à l'exécution de l'achat et de la vente, pour audit; hors
+	// du JSON des cycles (API, webhooks), consultables sur la page du cycle
+	OrderSnapshots OrderSnapshots `json:"-"`
 }
 
+// Étapes d'une moyenne à la baisse (Cycle.AverageDownState), vide lorsqu'aucune n'est en cours
+const (
+	AverageDownBuying    = "buying"    // achat supplémentaire placé, en attente d'exécution
+	AverageDownFilled    = "filled"    // achat exécuté, ancienne vente à annuler
+	AverageDownReselling = "reselling" // ancienne vente annulée, fusion et nouvelle vente à placer
+)
+
+// Statuts d'un cycle (Cycle.Status). Les passages autorisés de l'un à l'autre sont décrits par
+// cycleTransitions (transitions.go).
+const (
+	StatusBuy       = "buy"       // ordre d'achat ouvert
+	StatusSell      = "sell"      // achat exécuté, vente placée ou en attente (SellId vide)
+	StatusCompleted = "completed" // vente exécutée
+	StatusCancelled = "cancelled" // achat annulé, cause dans CancelReason
+
+	// StatusCancelPending est le statut d'un cycle dont l'annulation de l'ordre d'achat a échoué:
+	// l'ordre peut encore être ouvert sur l'exchange, l'annulation est retentée à chaque mise à jour
+	// et le cycle ne passe en cancelled qu'une fois l'annulation confirmée
+	StatusCancelPending = "cancel_pending"
+)
+
+// Causes d'annulation d'un cycle (Cycle.CancelReason)
+const (
+	CancelReasonMaxAge         = "max_age"         // achat non exécuté après BUY_MAX_DAYS
+	CancelReasonExpired        = "expired"         // achat expiré par l'exchange (USE_EXCHANGE_EXPIRY)
+	CancelReasonPriceDeviation = "price_deviation" // prix au-delà de BUY_MAX_PRICE_DEVIATION
+	CancelReasonManual         = "manual"          // annulation par -c
+	CancelReasonOrderNotFound  = "order_not_found" // ordre d'achat introuvable sur l'exchange
+	CancelReasonDuplicate      = "duplicate"       // doublon d'un autre cycle sur le même ordre (--dedupe)
+)
+
+// CancelReasons liste les causes d'annulation connues, dans l'ordre d'affichage
+var CancelReasons = []string{CancelReasonMaxAge, CancelReasonExpired, CancelReasonPriceDeviation, CancelReasonManual, CancelReasonOrderNotFound, CancelReasonDuplicate}
+
 // Nouvelle fonction pour calculer le gain exact
 func (c *Cycle) CalculateExactGain() {
 	// Calcul précis du gain exact basé sur les montants USDC
@@ -78,14 +191,45 @@ func (c *Cycle) GetAge() float64 {
 	return duration.Hours() / 24
 }
 
+// PlausibleCompletedAt indique si une date d'exécution fournie par l'exchange est cohérente:
+// postérieure à la création du cycle et pas dans le futur
+func (c *Cycle) PlausibleCompletedAt(t time.Time) bool {
+	return !t.IsZero() && !t.Before(c.CreatedAt) && !t.After(time.Now())
+}
+
+// EffectiveCompletedAt retourne la date de complétion du cycle: celle de l'exchange si elle est
+// cohérente, sinon la date à laquelle l'exécution a été constatée (zéro si le cycle n'est pas complété)
+func (c *Cycle) EffectiveCompletedAt() time.Time {
+	if c.PlausibleCompletedAt(c.CompletedAt) {
+		return c.CompletedAt
+	}
+	return c.ObservedCompletedAt
+}
+
+// EffectiveBuyPrice retourne le prix d'achat exécuté, ou le prix limite s'il est inconnu
+func (c *Cycle) EffectiveBuyPrice() float64 {
+	if c.BuyFillPrice > 0 {
+		return c.BuyFillPrice
+	}
+	return c.BuyPrice
+}
+
+// EffectiveSellPrice retourne le prix de vente exécuté, ou le prix limite s'il est inconnu
+func (c *Cycle) EffectiveSellPrice() float64 {
+	if c.SellFillPrice > 0 {
+		return c.SellFillPrice
+	}
+	return c.SellPrice
+}
+
 // CalculateProfit calcule le profit en USD du cycle
 func (c *Cycle) CalculateProfit() float64 {
 	if c.Status != "completed" {
 		return 0
 	}
 
-	buyTotal := c.BuyPrice * c.Quantity
-	sellTotal := c.SellPrice * c.Quantity
+	buyTotal := c.EffectiveBuyPrice() * c.Quantity
+	sellTotal := c.EffectiveSellPrice() * c.Quantity
 
 	return sellTotal - buyTotal
 }
@@ -97,7 +241,7 @@ func (c *Cycle) CalculateProfitPercentage() float64 {
 	}
 
 	profit := c.CalculateProfit()
-	buyTotal := c.BuyPrice * c.Quantity
+	buyTotal := c.EffectiveBuyPrice() * c.Quantity
 
 	return (profit / buyTotal) * 100
 }
@@ -111,6 +255,8 @@ func (c *Cycle) FormatStatus() string {
 		return "Vente en cours"
 	case "completed":
 		return "Complété"
+	case StatusCancelPending:
+		return "Annulation en cours"
 	case "cancelled":
 		return "Annulé"
 	default:
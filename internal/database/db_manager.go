@@ -13,16 +13,28 @@ import (
 
 var (
 	repositoryInstance       *CycleRepository
+	archiveRepoInstance      *CycleRepository
 	accumulationRepoInstance *AccumulationRepository
+	snapshotRepoInstance     *SnapshotRepository
+	priceHistoryRepoInstance *PriceHistoryRepository
+	ignoredOrderRepoInstance *IgnoredOrderRepository
 	initOnce                 sync.Once
 	db                       *clover.DB
 )
 
 // InitDatabase initialise la base de données
 func InitDatabase() {
+	InitDatabaseAt("")
+}
+
+// InitDatabaseAt initialise la base de données dans le dossier indiqué (base temporaire
+// des tests), ou dans celui de GetDatabasePath si le chemin est vide. Seul le premier
+// appel à InitDatabase ou InitDatabaseAt ouvre la base.
+func InitDatabaseAt(dbPath string) {
 	initOnce.Do(func() {
-		// Obtenir le chemin de la base de données
-		dbPath := GetDatabasePath()
+		if dbPath == "" {
+			dbPath = GetDatabasePath()
+		}
 
 		// Vérifier et supprimer le fichier LOCK s'il existe
 		lockFile := filepath.Join(dbPath, "LOCK")
@@ -31,6 +43,10 @@ func InitDatabase() {
 			os.Remove(lockFile)
 		}
 
+		// Vérifier la base document par document avant de l'ouvrir: une base saine est
+		// sauvegardée, une base corrompue est mise de côté et reconstruite
+		openChecked(dbPath)
+
 		// Ouvrir la base de données
 		var err error
 		db, err = clover.Open(dbPath)
@@ -41,6 +57,10 @@ func InitDatabase() {
 		// Créer les collections si elles n'existent pas
 		ensureCollectionsExist()
 
+		// Compléter les cycles enregistrés par les versions précédentes
+		backfillObservedCompletedAt(GetRepository())
+		backfillObservedCompletedAt(GetArchiveRepository())
+
 		// Nettoyer la base de données au démarrage
 		CleanupDatabase()
 	})
@@ -75,18 +95,86 @@ func ensureCollectionsExist() {
 		}
 		log.Printf("Collection %s créée avec succès", AccumulationCollectionName)
 	}
+
+	// Vérifier la collection des cycles archivés
+	archiveCollectionExists, err := db.HasCollection(ArchiveCollectionName)
+	if err != nil {
+		log.Fatalf("Erreur lors de la vérification de la collection d'archive: %v", err)
+	}
+
+	if !archiveCollectionExists {
+		err = db.CreateCollection(ArchiveCollectionName)
+		if err != nil {
+			log.Fatalf("Erreur lors de la création de la collection d'archive: %v", err)
+		}
+		log.Printf("Collection %s créée avec succès", ArchiveCollectionName)
+	}
+
+	// Vérifier la collection des instantanés du portefeuille
+	snapshotCollectionExists, err := db.HasCollection(SnapshotCollectionName)
+	if err != nil {
+		log.Fatalf("Erreur lors de la vérification de la collection des instantanés: %v", err)
+	}
+
+	if !snapshotCollectionExists {
+		err = db.CreateCollection(SnapshotCollectionName)
+		if err != nil {
+			log.Fatalf("Erreur lors de la création de la collection des instantanés: %v", err)
+		}
+		log.Printf("Collection %s créée avec succès", SnapshotCollectionName)
+	}
+
+	// Vérifier la collection des ordres orphelins ignorés
+	ignoredCollectionExists, err := db.HasCollection(IgnoredOrderCollectionName)
+	if err != nil {
+		log.Fatalf("Erreur lors de la vérification de la collection des ordres ignorés: %v", err)
+	}
+
+	if !ignoredCollectionExists {
+		err = db.CreateCollection(IgnoredOrderCollectionName)
+		if err != nil {
+			log.Fatalf("Erreur lors de la création de la collection des ordres ignorés: %v", err)
+		}
+		log.Printf("Collection %s créée avec succès", IgnoredOrderCollectionName)
+	}
+
+	// Vérifier la collection de l'historique des prix
+	priceHistoryCollectionExists, err := db.HasCollection(PriceHistoryCollectionName)
+	if err != nil {
+		log.Fatalf("Erreur lors de la vérification de la collection de l'historique des prix: %v", err)
+	}
+
+	if !priceHistoryCollectionExists {
+		err = db.CreateCollection(PriceHistoryCollectionName)
+		if err != nil {
+			log.Fatalf("Erreur lors de la création de la collection de l'historique des prix: %v", err)
+		}
+		log.Printf("Collection %s créée avec succès", PriceHistoryCollectionName)
+	}
 }
 
 // GetRepository retourne l'instance du repository de cycles
 func GetRepository() *CycleRepository {
 	if repositoryInstance == nil {
 		repositoryInstance = &CycleRepository{
-			db: db,
+			db:         db,
+			collection: CollectionName,
 		}
 	}
 	return repositoryInstance
 }
 
+// GetArchiveRepository retourne l'instance du repository des cycles archivés
+func GetArchiveRepository() *CycleRepository {
+	if archiveRepoInstance == nil {
+		archiveRepoInstance = &CycleRepository{
+			db:         db,
+			collection: ArchiveCollectionName,
+		}
+	}
+	return archiveRepoInstance
+}
+
 // GetAccumulationRepository retourne l'instance du repository d'accumulation
 func GetAccumulationRepository() *AccumulationRepository {
 	if accumulationRepoInstance == nil {
@@ -97,6 +185,36 @@ func GetAccumulationRepository() *AccumulationRepository {
 	return accumulationRepoInstance
 }
 
+// GetSnapshotRepository retourne l'instance du repository des instantanés du portefeuille
+func GetSnapshotRepository() *SnapshotRepository {
+	if snapshotRepoInstance == nil {
+		snapshotRepoInstance = &SnapshotRepository{
+			db: db,
+		}
+	}
+	return snapshotRepoInstance
+}
+
+// GetPriceHistoryRepository retourne l'instance du repository de l'historique des prix
+func GetPriceHistoryRepository() *PriceHistoryRepository {
+	if priceHistoryRepoInstance == nil {
+		priceHistoryRepoInstance = &PriceHistoryRepository{
+			db: db,
+		}
+	}
+	return priceHistoryRepoInstance
+}
+
+// GetIgnoredOrderRepository retourne l'instance du repository des ordres orphelins ignorés
+func GetIgnoredOrderRepository() *IgnoredOrderRepository {
+	if ignoredOrderRepoInstance == nil {
+		ignoredOrderRepoInstance = &IgnoredOrderRepository{
+			db: db,
+		}
+	}
+	return ignoredOrderRepoInstance
+}
+
 // CloseDatabase ferme proprement la connexion à la base de données
 func CloseDatabase() {
 	if db != nil {
@@ -105,7 +223,11 @@ func CloseDatabase() {
 		}
 		db = nil
 		repositoryInstance = nil
+		archiveRepoInstance = nil
 		accumulationRepoInstance = nil
+		snapshotRepoInstance = nil
+		priceHistoryRepoInstance = nil
+		ignoredOrderRepoInstance = nil
 	}
 }
 
@@ -141,14 +263,10 @@ func CleanupDatabase() {
 			continue
 		}
 
+		// Un cycle 'sell' sans ID d'ordre a un achat exécuté dont la vente n'a pas pu être placée:
+		// il est conservé, la mise à jour retente la vente
 		if cycle.Status == "sell" && (cycle.SellId == "" || strings.TrimSpace(cycle.SellId) == "") {
-			log.Printf("Cycle %d: Statut 'sell' sans ID d'ordre valide, suppression...", cycle.IdInt)
-			err := repo.DeleteByIdInt(cycle.IdInt)
-			if err != nil {
-				log.Printf("Erreur lors de la suppression du cycle %d: %v", cycle.IdInt, err)
-			} else {
-				cleanupCount++
-			}
+			log.Printf("Cycle %d: Statut 'sell' sans ID d'ordre, vente à replacer par la mise à jour", cycle.IdInt)
 			continue
 		}
 
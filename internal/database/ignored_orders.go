@@ -0,0 +1,67 @@
+// internal/database/ignored_orders.go
+package database
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// IgnoredOrderCollectionName est la collection des ordres orphelins à ne plus signaler (--orphans)
+const IgnoredOrderCollectionName = "ignored_orders"
+
+// IgnoredOrderRepository gère la liste des ordres d'exchange volontairement laissés hors du bot
+type IgnoredOrderRepository struct {
+	db *clover.DB
+	mu sync.Mutex
+}
+
+// Ignore ajoute un ordre à la liste des ordres ignorés de l'exchange
+func (r *IgnoredOrderRepository) Ignore(exchange, orderId string) error {
+	if interceptWrite(WriteIntent{Collection: IgnoredOrderCollectionName, Op: "save"}) {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exists, err := r.db.Query(IgnoredOrderCollectionName).
+		Where(clover.Field("exchange").Eq(exchange).And(clover.Field("orderId").Eq(orderId))).
+		Exists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	doc := clover.NewDocument()
+	doc.Set("exchange", exchange)
+	doc.Set("orderId", orderId)
+	doc.Set("ignoredAt", time.Now().Unix())
+	if _, err := r.db.InsertOne(IgnoredOrderCollectionName, doc); err != nil {
+		return fmt.Errorf("erreur lors de l'enregistrement de l'ordre ignoré: %v", err)
+	}
+	return nil
+}
+
+// FindByExchange retourne les IDs des ordres ignorés de l'exchange
+func (r *IgnoredOrderRepository) FindByExchange(exchange string) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs, err := r.db.Query(IgnoredOrderCollectionName).Where(clover.Field("exchange").Eq(exchange)).FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		if orderId, ok := doc.Get("orderId").(string); ok {
+			ignored[orderId] = true
+		}
+	}
+	return ignored, nil
+}
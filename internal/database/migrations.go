@@ -0,0 +1,47 @@
+package database
+
+import (
+	"log"
+	"time"
+)
+
+// backfillObservedCompletedAt renseigne ObservedCompletedAt des cycles complétés avant son
+// introduction. Faute de mieux, la date de complétion enregistrée est reprise, ramenée entre la
+// création du cycle et la date de la migration lorsqu'elle est incohérente (date antérieure à
+// l'achat ou horloge en avance). Les cycles déjà renseignés ne sont pas modifiés.
+func backfillObservedCompletedAt(repo *CycleRepository) {
+	cycles, err := repo.FindByStatus("completed")
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des cycles à migrer: %v", err)
+		return
+	}
+
+	now := time.Now()
+	count := 0
+	for _, cycle := range cycles {
+		if !cycle.ObservedCompletedAt.IsZero() || cycle.CompletedAt.IsZero() {
+			continue
+		}
+
+		observed := cycle.CompletedAt
+		if observed.Before(cycle.CreatedAt) {
+			observed = cycle.CreatedAt
+		}
+		if observed.After(now) {
+			observed = now
+		}
+
+		err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+			"observedCompletedAt": observed.Format(time.RFC3339),
+		})
+		if err != nil {
+			log.Printf("Erreur lors de la migration du cycle %d: %v", cycle.IdInt, err)
+			continue
+		}
+		count++
+	}
+
+	if count > 0 {
+		log.Printf("Migration: date d'exécution constatée renseignée pour %d cycle(s)", count)
+	}
+}
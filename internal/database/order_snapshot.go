@@ -0,0 +1,176 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// MaxOrderSnapshotSize est la taille maximale, en octets, de la réponse brute conservée par
+// ordre; au-delà, la réponse est tronquée
+const MaxOrderSnapshotSize = 64 << 10
+
+// Ordres dont la réponse brute est conservée (clés de Cycle.OrderSnapshots)
+const (
+	OrderSnapshotBuy  = "buy"
+	OrderSnapshotSell = "sell"
+)
+
+// OrderSnapshot est la réponse brute de l'exchange (GetOrderById) relevée à l'exécution d'un
+// ordre, conservée pour audit: l'ordre peut ensuite disparaître de certains endpoints
+type OrderSnapshot struct {
+	OrderId    string    `json:"orderId"`
+	CapturedAt time.Time `json:"capturedAt"`
+	Payload    string    `json:"payload"`   // réponse compressée (gzip) encodée en base64
+	Size       int       `json:"size"`      // taille de la réponse d'origine, en octets
+	Truncated  bool      `json:"truncated"` // réponse limitée à MaxOrderSnapshotSize octets
+}
+
+// OrderSnapshots regroupe les réponses brutes des ordres d'achat et de vente d'un cycle
+type OrderSnapshots struct {
+	Buy  *OrderSnapshot `json:"buy,omitempty"`
+	Sell *OrderSnapshot `json:"sell,omitempty"`
+}
+
+// Get retourne la réponse conservée pour l'ordre indiqué (OrderSnapshotBuy, OrderSnapshotSell)
+func (s OrderSnapshots) Get(side string) *OrderSnapshot {
+	switch side {
+	case OrderSnapshotBuy:
+		return s.Buy
+	case OrderSnapshotSell:
+		return s.Sell
+	}
+	return nil
+}
+
+// Empty indique si aucune réponse n'est conservée
+func (s OrderSnapshots) Empty() bool {
+	return s.Buy == nil && s.Sell == nil
+}
+
+// NewOrderSnapshot compresse la réponse brute d'un ordre, limitée à MaxOrderSnapshotSize octets
+func NewOrderSnapshot(orderId string, raw []byte, capturedAt time.Time) (*OrderSnapshot, error) {
+	snapshot := &OrderSnapshot{OrderId: orderId, CapturedAt: capturedAt, Size: len(raw)}
+	if len(raw) > MaxOrderSnapshotSize {
+		raw = raw[:MaxOrderSnapshotSize]
+		snapshot.Truncated = true
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(raw); err != nil {
+		return nil, fmt.Errorf("compression de la réponse de l'ordre %s: %w", orderId, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("compression de la réponse de l'ordre %s: %w", orderId, err)
+	}
+	snapshot.Payload = base64.StdEncoding.EncodeToString(compressed.Bytes())
+	return snapshot, nil
+}
+
+// Raw retourne la réponse brute conservée, décompressée
+func (s *OrderSnapshot) Raw() ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(s.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("réponse de l'ordre %s illisible: %w", s.OrderId, err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("réponse de l'ordre %s illisible: %w", s.OrderId, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, MaxOrderSnapshotSize))
+}
+
+// document retourne la réponse conservée sous la forme enregistrée dans un cycle
+func (s *OrderSnapshot) document() map[string]interface{} {
+	return map[string]interface{}{
+		"orderId":    s.OrderId,
+		"capturedAt": s.CapturedAt.Format(time.RFC3339),
+		"payload":    s.Payload,
+		"size":       s.Size,
+		"truncated":  s.Truncated,
+	}
+}
+
+// document retourne les réponses conservées d'un cycle, nil s'il n'y en a aucune
+func (s OrderSnapshots) document() map[string]interface{} {
+	if s.Empty() {
+		return nil
+	}
+	doc := make(map[string]interface{})
+	if s.Buy != nil {
+		doc[OrderSnapshotBuy] = s.Buy.document()
+	}
+	if s.Sell != nil {
+		doc[OrderSnapshotSell] = s.Sell.document()
+	}
+	return doc
+}
+
+// readOrderSnapshots complète un cycle avec les réponses brutes conservées de ses ordres
+func readOrderSnapshots(cycle *Cycle, doc *clover.Document) {
+	read := func(side string) *OrderSnapshot {
+		field := "orderSnapshots." + side
+		payload, ok := doc.Get(field + ".payload").(string)
+		if !ok || payload == "" {
+			return nil
+		}
+		snapshot := &OrderSnapshot{Payload: payload, Size: int(docFloat(doc, field+".size"))}
+		snapshot.OrderId, _ = doc.Get(field + ".orderId").(string)
+		snapshot.Truncated, _ = doc.Get(field + ".truncated").(bool)
+		if timeStr, ok := doc.Get(field + ".capturedAt").(string); ok {
+			snapshot.CapturedAt, _ = time.Parse(time.RFC3339, timeStr)
+		}
+		return snapshot
+	}
+	cycle.OrderSnapshots = OrderSnapshots{Buy: read(OrderSnapshotBuy), Sell: read(OrderSnapshotSell)}
+}
+
+// SaveOrderSnapshot enregistre la réponse brute d'un ordre du cycle (OrderSnapshotBuy, OrderSnapshotSell)
+func (r *CycleRepository) SaveOrderSnapshot(idInt int32, side string, snapshot *OrderSnapshot) error {
+	return r.UpdateByIdInt(idInt, map[string]interface{}{
+		"orderSnapshots." + side: snapshot.document(),
+	})
+}
+
+// StripOrderSnapshotsBefore supprime les réponses brutes conservées des cycles créés avant la
+// date donnée et retourne le nombre de cycles allégés
+func (r *CycleRepository) StripOrderSnapshotsBefore(before time.Time) (int, error) {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "update", Fields: map[string]interface{}{"orderSnapshots": nil}}) {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return 0, fmt.Errorf("la base de données n'est pas initialisée")
+	}
+
+	query := r.db.Query(r.collection).MatchPredicate(func(doc *clover.Document) bool {
+		snapshots, ok := doc.Get("orderSnapshots").(map[string]interface{})
+		if !ok || len(snapshots) == 0 {
+			return false
+		}
+		createdAt, ok := doc.Get("createdAt").(string)
+		if !ok {
+			return false
+		}
+		parsed, err := time.Parse(time.RFC3339, createdAt)
+		return err == nil && parsed.Before(before)
+	})
+	count, err := query.Count()
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	if err := query.Update(map[string]interface{}{"orderSnapshots": nil}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
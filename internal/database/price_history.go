@@ -0,0 +1,107 @@
+// internal/database/price_history.go
+package database
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// PriceHistoryCollectionName est la collection des prix du BTC relevés par exchange
+const PriceHistoryCollectionName = "price_history"
+
+// PricePoint est le prix du BTC sur un exchange à un instant donné
+type PricePoint struct {
+	Exchange  string    `json:"exchange"`
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+}
+
+// PriceHistoryRepository gère l'historique des prix affiché par le tableau de bord
+type PriceHistoryRepository struct {
+	db *clover.DB
+	mu sync.Mutex
+}
+
+// Record enregistre un prix si le dernier point de l'exchange date d'au moins interval, et
+// indique s'il a été enregistré
+func (r *PriceHistoryRepository) Record(point PricePoint, interval time.Duration) (bool, error) {
+	if point.Price <= 0 {
+		return false, nil
+	}
+	if point.Timestamp.IsZero() {
+		point.Timestamp = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last, err := r.db.Query(PriceHistoryCollectionName).
+		Where(clover.Field("exchange").Eq(point.Exchange)).
+		Sort(clover.SortOption{Field: "timestamp", Direction: -1}).
+		FindFirst()
+	if err != nil {
+		return false, err
+	}
+	if last != nil && point.Timestamp.Sub(time.Unix(int64(docFloat(last, "timestamp")), 0)) < interval {
+		return false, nil
+	}
+
+	if interceptWrite(WriteIntent{Collection: PriceHistoryCollectionName, Op: "save"}) {
+		return false, nil
+	}
+	doc := clover.NewDocument()
+	doc.Set("exchange", point.Exchange)
+	doc.Set("timestamp", point.Timestamp.Unix())
+	doc.Set("price", point.Price)
+	if _, err := r.db.InsertOne(PriceHistoryCollectionName, doc); err != nil {
+		return false, fmt.Errorf("erreur lors de l'insertion du prix: %v", err)
+	}
+	return true, nil
+}
+
+// FindSince retourne les prix d'un exchange relevés depuis la date donnée, triés chronologiquement
+func (r *PriceHistoryRepository) FindSince(exchange string, since time.Time) ([]PricePoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs, err := r.db.Query(PriceHistoryCollectionName).
+		Where(clover.Field("exchange").Eq(exchange).And(clover.Field("timestamp").GtEq(since.Unix()))).
+		Sort(clover.SortOption{Field: "timestamp", Direction: 1}).
+		FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]PricePoint, 0, len(docs))
+	for _, doc := range docs {
+		points = append(points, PricePoint{
+			Exchange:  exchange,
+			Timestamp: time.Unix(int64(docFloat(doc, "timestamp")), 0),
+			Price:     docFloat(doc, "price"),
+		})
+	}
+	return points, nil
+}
+
+// DeleteBefore supprime les prix relevés avant la date donnée et retourne leur nombre
+func (r *PriceHistoryRepository) DeleteBefore(before time.Time) (int, error) {
+	if interceptWrite(WriteIntent{Collection: PriceHistoryCollectionName, Op: "delete"}) {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := r.db.Query(PriceHistoryCollectionName).Where(clover.Field("timestamp").Lt(before.Unix()))
+	count, err := query.Count()
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	if err := query.Delete(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,369 @@
+package database
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v3"
+	"github.com/ostafen/clover/encoding"
+)
+
+// Sauvegardes automatiques: data/backups/db-<date>.bak, faites à l'ouverture de la base
+const (
+	backupPrefix = "db-"
+	backupSuffix = ".bak"
+)
+
+// backupCount est le nombre de sauvegardes automatiques conservées (DB_BACKUP_COUNT)
+var backupCount = 5
+
+// SetBackupCount définit le nombre de sauvegardes automatiques conservées (0 = désactivées)
+func SetBackupCount(count int) {
+	backupCount = count
+}
+
+// storeRecord est une entrée brute du stockage de clover: marqueur de collection ("coll:cycles")
+// ou document encodé ("cycles:<id>")
+type storeRecord struct {
+	Key   string
+	Value []byte
+}
+
+// recordCollection retourne la collection d'un document, "" pour un marqueur de collection
+func recordCollection(key string) string {
+	collection, _, _ := strings.Cut(key, ":")
+	if collection == "coll" {
+		return ""
+	}
+	return collection
+}
+
+// CollectionIntegrity compte les documents lisibles et endommagés d'une collection
+type CollectionIntegrity struct {
+	Readable int
+	Damaged  int
+}
+
+// IntegrityReport est le résultat de la vérification d'une base (--fsck)
+type IntegrityReport struct {
+	Path        string
+	Err         error // base impossible à ouvrir ou à parcourir
+	Locked      bool  // base ouverte par un autre processus
+	Collections map[string]*CollectionIntegrity
+	Backups     []string // sauvegardes disponibles, de la plus récente à la plus ancienne
+}
+
+// Healthy indique si la base s'ouvre et que tous ses documents sont lisibles
+func (r *IntegrityReport) Healthy() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, collection := range r.Collections {
+		if collection.Damaged > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CollectionRecovery compte, pour une collection, les documents récupérés dans la base corrompue,
+// ceux restaurés depuis la sauvegarde et ceux perdus
+type CollectionRecovery struct {
+	Salvaged   int
+	FromBackup int
+	Lost       int
+}
+
+// RecoveryReport décrit la récupération d'une base corrompue
+type RecoveryReport struct {
+	Cause       error
+	CorruptPath string // emplacement de la base corrompue, mise de côté
+	BackupPath  string // sauvegarde utilisée pour compléter la récupération, vide si aucune
+	Unreadable  bool   // base impossible à parcourir: les documents perdus ne sont pas dénombrables
+	Collections map[string]*CollectionRecovery
+}
+
+func (r *RecoveryReport) collection(name string) *CollectionRecovery {
+	if r.Collections[name] == nil {
+		r.Collections[name] = &CollectionRecovery{}
+	}
+	return r.Collections[name]
+}
+
+// lastRecovery est la récupération faite à l'ouverture de la base, nil si elle était saine
+var lastRecovery *RecoveryReport
+
+// LastRecovery retourne la récupération faite à l'ouverture de la base, nil si elle était saine
+func LastRecovery() *RecoveryReport {
+	return lastRecovery
+}
+
+// scanStore parcourt le stockage de la base document par document, sans passer par clover qui
+// s'arrête au premier document illisible. Il retourne les entrées lisibles et les clés des
+// documents endommagés; l'erreur indique une base impossible à ouvrir ou à parcourir.
+func scanStore(dbPath string) (records []storeRecord, damaged []string, err error) {
+	// Le stockage peut paniquer sur un fichier tronqué par une coupure de courant
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("lecture de la base interrompue: %v", r)
+		}
+	}()
+
+	store, err := badger.Open(badger.DefaultOptions(dbPath).WithLoggingLevel(badger.ERROR))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer store.Close()
+
+	err = store.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := string(it.Item().KeyCopy(nil))
+			value, err := it.Item().ValueCopy(nil)
+			if err == nil && recordCollection(key) != "" {
+				var fields map[string]interface{}
+				err = encoding.Decode(value, &fields)
+			}
+			if err != nil {
+				damaged = append(damaged, key)
+				continue
+			}
+			records = append(records, storeRecord{Key: key, Value: value})
+		}
+		return nil
+	})
+	return records, damaged, err
+}
+
+// storeLocked indique si l'ouverture a échoué parce qu'un autre processus utilise la base: elle
+// n'est alors pas corrompue
+func storeLocked(err error) bool {
+	return strings.Contains(err.Error(), "directory lock")
+}
+
+// openChecked vérifie la base avant son ouverture par clover: une base saine est sauvegardée,
+// une base corrompue est mise de côté et reconstruite
+func openChecked(dbPath string) {
+	records, damaged, err := scanStore(dbPath)
+	if err != nil && (storeLocked(err) || errors.Is(err, fs.ErrPermission)) {
+		log.Fatalf("Erreur lors de l'ouverture de la base de données: %v", err)
+	}
+
+	if err == nil && len(damaged) == 0 {
+		if err := writeBackup(dbPath, records); err != nil {
+			log.Printf("Erreur lors de la sauvegarde de la base de données: %v", err)
+		}
+		return
+	}
+
+	cause := err
+	if cause == nil {
+		cause = fmt.Errorf("%d document(s) illisible(s)", len(damaged))
+	}
+	log.Printf("Base de données corrompue (%v): récupération en cours...", cause)
+	report, err := recoverStore(dbPath, records, damaged, cause)
+	if err != nil {
+		log.Fatalf("Récupération de la base de données impossible: %v", err)
+	}
+	report.log()
+	lastRecovery = report
+}
+
+// recoverStore met la base corrompue de côté et en reconstruit une nouvelle avec ses documents
+// lisibles, complétés par ceux de la sauvegarde la plus récente
+func recoverStore(dbPath string, records []storeRecord, damaged []string, cause error) (*RecoveryReport, error) {
+	report := &RecoveryReport{
+		Cause:       cause,
+		CorruptPath: fmt.Sprintf("%s.corrupt-%s", dbPath, time.Now().Format("20060102-150405")),
+		Unreadable:  len(records) == 0 && len(damaged) == 0,
+		Collections: make(map[string]*CollectionRecovery),
+	}
+	if err := os.Rename(dbPath, report.CorruptPath); err != nil {
+		return nil, fmt.Errorf("impossible de mettre la base corrompue de côté: %w", err)
+	}
+
+	recovered := make(map[string][]byte, len(records))
+	for _, record := range records {
+		recovered[record.Key] = record.Value
+		if collection := recordCollection(record.Key); collection != "" {
+			report.collection(collection).Salvaged++
+		}
+	}
+
+	// Les documents absents ou endommagés sont repris de la sauvegarde la plus récente
+	backupPath, backup := latestBackup(backupDir(dbPath))
+	report.BackupPath = backupPath
+	for _, record := range backup {
+		if _, found := recovered[record.Key]; found {
+			continue
+		}
+		recovered[record.Key] = record.Value
+		if collection := recordCollection(record.Key); collection != "" {
+			report.collection(collection).FromBackup++
+		}
+	}
+	for _, key := range damaged {
+		if _, found := recovered[key]; !found {
+			if collection := recordCollection(key); collection != "" {
+				report.collection(collection).Lost++
+			}
+		}
+	}
+
+	store, err := badger.Open(badger.DefaultOptions(dbPath).WithLoggingLevel(badger.ERROR))
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	batch := store.NewWriteBatch()
+	defer batch.Cancel()
+	for key, value := range recovered {
+		if err := batch.Set([]byte(key), value); err != nil {
+			return nil, err
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// log affiche le bilan de la récupération
+func (r *RecoveryReport) log() {
+	log.Printf("Base corrompue conservée dans %s", r.CorruptPath)
+	if r.BackupPath != "" {
+		log.Printf("Sauvegarde utilisée pour compléter la récupération: %s", r.BackupPath)
+	} else {
+		log.Printf("Aucune sauvegarde disponible pour compléter la récupération")
+	}
+	if r.Unreadable {
+		log.Printf("Base illisible: seuls les documents de la sauvegarde ont pu être restaurés")
+	}
+
+	names := make([]string, 0, len(r.Collections))
+	for name := range r.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		counts := r.Collections[name]
+		log.Printf("Collection %s: %d récupéré(s), %d restauré(s) depuis la sauvegarde, %d perdu(s)",
+			name, counts.Salvaged, counts.FromBackup, counts.Lost)
+	}
+}
+
+// CheckIntegrity vérifie la base document par document sans la modifier (--fsck). La base ne
+// doit pas être ouverte par le processus.
+func CheckIntegrity(dbPath string) *IntegrityReport {
+	if dbPath == "" {
+		dbPath = GetDatabasePath()
+	}
+	report := &IntegrityReport{
+		Path:        dbPath,
+		Collections: make(map[string]*CollectionIntegrity),
+		Backups:     listBackups(backupDir(dbPath)),
+	}
+
+	records, damaged, err := scanStore(dbPath)
+	if err != nil {
+		report.Err = err
+		report.Locked = storeLocked(err)
+	}
+	for _, record := range records {
+		if collection := recordCollection(record.Key); collection != "" {
+			report.collection(collection).Readable++
+		}
+	}
+	for _, key := range damaged {
+		if collection := recordCollection(key); collection != "" {
+			report.collection(collection).Damaged++
+		}
+	}
+	return report
+}
+
+func (r *IntegrityReport) collection(name string) *CollectionIntegrity {
+	if r.Collections[name] == nil {
+		r.Collections[name] = &CollectionIntegrity{}
+	}
+	return r.Collections[name]
+}
+
+// backupDir retourne le dossier des sauvegardes, à côté de celui de la base
+func backupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// listBackups retourne les sauvegardes du dossier, de la plus récente à la plus ancienne
+func listBackups(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, backupPrefix+"*"+backupSuffix))
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	return matches
+}
+
+// writeBackup enregistre les entrées de la base dans une nouvelle sauvegarde, sauf si elles sont
+// identiques à la plus récente, puis supprime les sauvegardes au-delà de backupCount
+func writeBackup(dbPath string, records []storeRecord) error {
+	if backupCount <= 0 || len(records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(records); err != nil {
+		return err
+	}
+
+	dir := backupDir(dbPath)
+	backups := listBackups(dir)
+	if len(backups) > 0 {
+		if latest, err := os.ReadFile(backups[0]); err == nil && bytes.Equal(latest, buf.Bytes()) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, backupPrefix+time.Now().Format("20060102-150405.000")+backupSuffix)
+	if err := os.WriteFile(path+".tmp", buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	if backups = listBackups(dir); len(backups) > backupCount {
+		for _, old := range backups[backupCount:] {
+			os.Remove(old)
+		}
+	}
+	return nil
+}
+
+// latestBackup retourne la sauvegarde lisible la plus récente et ses entrées
+func latestBackup(dir string) (string, []storeRecord) {
+	for _, path := range listBackups(dir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var records []storeRecord
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&records); err != nil {
+			log.Printf("Sauvegarde %s illisible: %v", path, err)
+			continue
+		}
+		return path, records
+	}
+	return "", nil
+}
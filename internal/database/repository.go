@@ -2,6 +2,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -10,86 +11,20 @@ import (
 	"github.com/ostafen/clover"
 )
 
-// CycleRepository gère les opérations de base de données pour les cycles
-type CycleRepository struct {
-	db *clover.DB
-	mu sync.Mutex
-}
-
-// FindAll retourne tous les cycles
-func (r *CycleRepository) FindAll() ([]*Cycle, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.db == nil {
-		return nil, fmt.Errorf("la base de données n'est pas initialisée")
+// docFloat lit un champ numérique optionnel d'un document (0 s'il est absent)
+func docFloat(doc *clover.Document, field string) float64 {
+	switch value := doc.Get(field).(type) {
+	case float64:
+		return value
+	case int64:
+		return float64(value)
+	default:
+		return 0
 	}
-
-	docs, err := r.db.Query(CollectionName).Sort(clover.SortOption{
-		Field:     "idInt",
-		Direction: -1,
-	}).FindAll()
-
-	if err != nil {
-		return nil, err
-	}
-
-	cycles := make([]*Cycle, 0, len(docs))
-	for _, doc := range docs {
-		// Récupérer la date de création si elle existe
-		var createdAt time.Time
-		if createdAtValue := doc.Get("createdAt"); createdAtValue != nil {
-			if timeStr, ok := createdAtValue.(string); ok {
-				parsedTime, err := time.Parse(time.RFC3339, timeStr)
-				if err == nil {
-					createdAt = parsedTime
-				}
-			}
-		}
-
-		// Récupérer la date de complétion si elle existe
-		var completedAt time.Time
-		if completedAtValue := doc.Get("completedAt"); completedAtValue != nil {
-			if timeStr, ok := completedAtValue.(string); ok && timeStr != "" {
-				parsedTime, err := time.Parse(time.RFC3339, timeStr)
-				if err == nil {
-					completedAt = parsedTime
-				}
-			}
-		}
-
-		cycle := &Cycle{
-			IdInt:       int32(doc.Get("idInt").(int64)),
-			Exchange:    doc.Get("exchange").(string),
-			Status:      doc.Get("status").(string),
-			Quantity:    doc.Get("quantity").(float64),
-			BuyPrice:    doc.Get("buyPrice").(float64),
-			BuyId:       doc.Get("buyId").(string),
-			SellPrice:   doc.Get("sellPrice").(float64),
-			SellId:      doc.Get("sellId").(string),
-			CreatedAt:   createdAt,
-			CompletedAt: completedAt,
-		}
-		cycles = append(cycles, cycle)
-	}
-
-	return cycles, nil
 }
 
-// FindById récupère un cycle par son ID
-func (r *CycleRepository) FindById(id string) (*Cycle, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.db == nil {
-		return nil, fmt.Errorf("la base de données n'est pas initialisée")
-	}
-
-	doc, err := r.db.Query(CollectionName).FindById(id)
-	if err != nil {
-		return nil, err
-	}
-
+// documentToCycle convertit un document de la collection en cycle
+func documentToCycle(doc *clover.Document) *Cycle {
 	// Récupérer la date de création si elle existe
 	var createdAt time.Time
 	if createdAtValue := doc.Get("createdAt"); createdAtValue != nil {
@@ -122,10 +57,155 @@ func (r *CycleRepository) FindById(id string) (*Cycle, error) {
 		SellPrice:   doc.Get("sellPrice").(float64),
 		SellId:      doc.Get("sellId").(string),
 		CreatedAt:   createdAt,
-		CompletedAt: completedAt, // Ajout du nouveau champ
+		CompletedAt: completedAt,
+	}
+	if imported, ok := doc.Get("imported").(bool); ok {
+		cycle.Imported = imported
+	}
+	if feesEstimated, ok := doc.Get("feesEstimated").(bool); ok {
+		cycle.FeesEstimated = feesEstimated
+	}
+	if paused, ok := doc.Get("paused").(bool); ok {
+		cycle.Paused = paused
+	}
+	if tags, ok := doc.Get("tags").([]interface{}); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				cycle.Tags = append(cycle.Tags, tag)
+			}
+		}
+	}
+	cycle.RepriceCount = int(docFloat(doc, "repriceCount"))
+	cycle.BuyFillPrice = docFloat(doc, "buyFillPrice")
+	cycle.SellFillPrice = docFloat(doc, "sellFillPrice")
+	cycle.PurchaseAmountUSDC = docFloat(doc, "purchaseAmountUSDC")
+	cycle.SaleAmountUSDC = docFloat(doc, "saleAmountUSDC")
+	cycle.TotalFees = docFloat(doc, "totalFees")
+	cycle.BuyFeeBTC = docFloat(doc, "buyFeeBTC")
+	if buyFeeAsset, ok := doc.Get("buyFeeAsset").(string); ok {
+		cycle.BuyFeeAsset = buyFeeAsset
+		cycle.BuyFeeAssetAmount = docFloat(doc, "buyFeeAssetAmount")
+	}
+	if sellFeeAsset, ok := doc.Get("sellFeeAsset").(string); ok {
+		cycle.SellFeeAsset = sellFeeAsset
+		cycle.SellFeeAssetAmount = docFloat(doc, "sellFeeAssetAmount")
+	}
+	cycle.SellFees = docFloat(doc, "sellFees")
+	// Les cycles antérieurs au champ buyFees ne connaissent leurs frais d'achat que par différence
+	if doc.Get("buyFees") != nil {
+		cycle.BuyFees = docFloat(doc, "buyFees")
+	} else {
+		cycle.BuyFees = cycle.TotalFees - cycle.SellFees
+	}
+	if cancelReason, ok := doc.Get("cancelReason").(string); ok {
+		cycle.CancelReason = cancelReason
+	}
+	if stopId, ok := doc.Get("stopId").(string); ok {
+		cycle.StopId = stopId
+	}
+	cycle.StopPrice = docFloat(doc, "stopPrice")
+	cycle.GroupId = int32(docFloat(doc, "groupId"))
+	if stoppedOut, ok := doc.Get("stoppedOut").(bool); ok {
+		cycle.StoppedOut = stoppedOut
+	}
+	if buyClientOrderId, ok := doc.Get("buyClientOrderId").(string); ok {
+		cycle.BuyClientOrderId = buyClientOrderId
+	}
+	if sellClientOrderId, ok := doc.Get("sellClientOrderId").(string); ok {
+		cycle.SellClientOrderId = sellClientOrderId
+	}
+	if averageDownState, ok := doc.Get("averageDownState").(string); ok {
+		cycle.AverageDownState = averageDownState
+	}
+	if averageDownBuyId, ok := doc.Get("averageDownBuyId").(string); ok {
+		cycle.AverageDownBuyId = averageDownBuyId
+	}
+	if averageDownClientOrderId, ok := doc.Get("averageDownClientOrderId").(string); ok {
+		cycle.AverageDownClientOrderId = averageDownClientOrderId
+	}
+	cycle.AverageDownQuantity = docFloat(doc, "averageDownQuantity")
+	cycle.AverageDownPrice = docFloat(doc, "averageDownPrice")
+	cycle.AverageDownFees = docFloat(doc, "averageDownFees")
+	cycle.AverageDownCount = int(docFloat(doc, "averageDownCount"))
+	cycle.SellRetryCount = int(docFloat(doc, "sellRetryCount"))
+	if sellRetryError, ok := doc.Get("sellRetryError").(string); ok {
+		cycle.SellRetryError = sellRetryError
+	}
+	if timeStr, ok := doc.Get("sellRetryAt").(string); ok && timeStr != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, timeStr); err == nil {
+			cycle.SellRetryAt = parsedTime
+		}
+	}
+	if timeStr, ok := doc.Get("cancelledAt").(string); ok && timeStr != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, timeStr); err == nil {
+			cycle.CancelledAt = parsedTime
+		}
+	}
+	cycle.CancelRetryCount = int(docFloat(doc, "cancelRetryCount"))
+	if cancelRetryError, ok := doc.Get("cancelRetryError").(string); ok {
+		cycle.CancelRetryError = cancelRetryError
+	}
+	if timeStr, ok := doc.Get("observedCompletedAt").(string); ok && timeStr != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, timeStr); err == nil {
+			cycle.ObservedCompletedAt = parsedTime
+		}
+	}
+	readOrderSnapshots(cycle, doc)
+	return cycle
+}
+
+// documentsToCycles convertit une liste de documents en cycles
+func documentsToCycles(docs []*clover.Document) []*Cycle {
+	cycles := make([]*Cycle, 0, len(docs))
+	for _, doc := range docs {
+		cycles = append(cycles, documentToCycle(doc))
+	}
+	return cycles
+}
+
+// CycleRepository gère les opérations de base de données pour les cycles
+type CycleRepository struct {
+	db         *clover.DB
+	mu         sync.Mutex
+	collection string // cycles actifs (CollectionName) ou archivés (ArchiveCollectionName)
+}
+
+// FindAll retourne tous les cycles
+func (r *CycleRepository) FindAll() ([]*Cycle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return nil, fmt.Errorf("la base de données n'est pas initialisée")
+	}
+
+	docs, err := r.db.Query(r.collection).Sort(clover.SortOption{
+		Field:     "idInt",
+		Direction: -1,
+	}).FindAll()
+
+	if err != nil {
+		return nil, err
 	}
 
-	return cycle, nil
+	return documentsToCycles(docs), nil
+}
+
+// FindById récupère un cycle par son ID
+func (r *CycleRepository) FindById(id string) (*Cycle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return nil, fmt.Errorf("la base de données n'est pas initialisée")
+	}
+
+	doc, err := r.db.Query(r.collection).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentToCycle(doc), nil
 }
 
 // FindByIdInt récupère un cycle par son ID entier
@@ -137,7 +217,7 @@ func (r *CycleRepository) FindByIdInt(id int32) (*Cycle, error) {
 		return nil, fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	doc, err := r.db.Query(CollectionName).Where(clover.Field("idInt").Eq(id)).FindFirst()
+	doc, err := r.db.Query(r.collection).Where(clover.Field("idInt").Eq(id)).FindFirst()
 	if err != nil {
 		return nil, err
 	}
@@ -147,45 +227,162 @@ func (r *CycleRepository) FindByIdInt(id int32) (*Cycle, error) {
 		return nil, nil
 	}
 
-	// Récupérer la date de création si elle existe
-	var createdAt time.Time
-	if createdAtValue := doc.Get("createdAt"); createdAtValue != nil {
-		if timeStr, ok := createdAtValue.(string); ok {
-			parsedTime, err := time.Parse(time.RFC3339, timeStr)
-			if err == nil {
-				createdAt = parsedTime
-			}
-		}
+	return documentToCycle(doc), nil
+}
+
+// findWhere retourne les cycles satisfaisant le critère, du plus récent au plus ancien.
+// Clover v1 n'a pas d'index secondaire: le filtre est appliqué pendant le parcours de la
+// collection, ce qui évite de convertir les documents écartés; l'archivage des cycles
+// complétés anciens (--archive) limite la taille de ce parcours.
+func (r *CycleRepository) findWhere(criteria *clover.Criteria, match func(doc *clover.Document) bool) ([]*Cycle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return nil, fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	// Récupérer la date de complétion si elle existe
-	var completedAt time.Time
-	if completedAtValue := doc.Get("completedAt"); completedAtValue != nil {
-		if timeStr, ok := completedAtValue.(string); ok && timeStr != "" {
-			parsedTime, err := time.Parse(time.RFC3339, timeStr)
-			if err == nil {
-				completedAt = parsedTime
+	query := r.db.Query(r.collection).Where(criteria)
+	if match != nil {
+		query = query.MatchPredicate(match)
+	}
+
+	docs, err := query.Sort(clover.SortOption{Field: "idInt", Direction: -1}).FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return documentsToCycles(docs), nil
+}
+
+// FindByStatus retourne les cycles ayant l'un des statuts donnés
+func (r *CycleRepository) FindByStatus(statuses ...string) ([]*Cycle, error) {
+	values := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		values[i] = status
+	}
+	return r.findWhere(clover.Field("status").In(values...), nil)
+}
+
+// FindByExchange retourne les cycles d'un exchange
+func (r *CycleRepository) FindByExchange(exchange string) ([]*Cycle, error) {
+	return r.findWhere(clover.Field("exchange").Eq(exchange), nil)
+}
+
+// FindByGroup retourne les tranches d'un achat échelonné
+func (r *CycleRepository) FindByGroup(groupId int32) ([]*Cycle, error) {
+	return r.findWhere(clover.Field("groupId").Eq(groupId), nil)
+}
+
+// FindCompletedBetween retourne les cycles complétés entre start (inclus) et end (exclu).
+// Une date zéro laisse la borne correspondante ouverte.
+func (r *CycleRepository) FindCompletedBetween(start, end time.Time) ([]*Cycle, error) {
+	return r.findWhere(clover.Field("status").Eq("completed"), func(doc *clover.Document) bool {
+		return completedBetween(doc, start, end)
+	})
+}
+
+// FindActive retourne les cycles que la mise à jour doit suivre: achats, ventes et annulations
+// en attente. Les cycles complétés ou annulés ne sont pas convertis.
+func (r *CycleRepository) FindActive() ([]*Cycle, error) {
+	return r.FindByStatus("buy", "sell", StatusCancelPending)
+}
+
+// ForEach appelle fn pour chaque cycle ayant le statut donné (tous les cycles si status est
+// vide), dans l'ordre de stockage, sans charger la collection en mémoire. Le parcours s'arrête
+// à la première erreur retournée par fn. fn ne doit pas appeler le repository: son verrou est
+// tenu pendant le parcours.
+func (r *CycleRepository) ForEach(status string, fn func(cycle *Cycle) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return fmt.Errorf("la base de données n'est pas initialisée")
+	}
+
+	query := r.db.Query(r.collection)
+	if status != "" {
+		query = query.Where(clover.Field("status").Eq(status))
+	}
+
+	var fnErr error
+	err := query.ForEach(func(doc *clover.Document) bool {
+		fnErr = fn(documentToCycle(doc))
+		return fnErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// CycleSummary agrège les cycles d'un exchange: nombre de cycles par statut et profit réalisé
+type CycleSummary struct {
+	Total     int
+	Buy       int // achats et annulations en attente (l'ordre peut encore être exécuté)
+	Sell      int
+	Completed int
+	Profit    float64 // profit des cycles complétés
+	// PeriodProfits[i] est le profit des cycles complétés depuis la i-ème date passée à Summarize
+	PeriodProfits []float64
+}
+
+// Summarize agrège les cycles par exchange en un seul parcours de la collection. profit calcule
+// le profit d'un cycle complété; chaque date de since ouvre une période, jusqu'à maintenant,
+// dont le profit est cumulé dans PeriodProfits.
+func (r *CycleRepository) Summarize(profit func(cycle *Cycle) float64, since ...time.Time) (map[string]*CycleSummary, error) {
+	summaries := make(map[string]*CycleSummary)
+	err := r.ForEach("", func(cycle *Cycle) error {
+		summary, ok := summaries[cycle.Exchange]
+		if !ok {
+			summary = &CycleSummary{PeriodProfits: make([]float64, len(since))}
+			summaries[cycle.Exchange] = summary
+		}
+
+		summary.Total++
+		switch cycle.Status {
+		case "buy", StatusCancelPending:
+			summary.Buy++
+		case "sell":
+			summary.Sell++
+		case "completed":
+			summary.Completed++
+			cycleProfit := profit(cycle)
+			summary.Profit += cycleProfit
+
+			// Date de complétion, ou de création si elle est inconnue (comme les statistiques)
+			completedAt := cycle.EffectiveCompletedAt()
+			if completedAt.IsZero() {
+				completedAt = cycle.CreatedAt
+			}
+			for i, start := range since {
+				if !completedAt.Before(start) {
+					summary.PeriodProfits[i] += cycleProfit
+				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return summaries, nil
+}
 
-	cycle := &Cycle{
-		IdInt:       int32(doc.Get("idInt").(int64)),
-		Exchange:    doc.Get("exchange").(string),
-		Status:      doc.Get("status").(string),
-		Quantity:    doc.Get("quantity").(float64),
-		BuyPrice:    doc.Get("buyPrice").(float64),
-		BuyId:       doc.Get("buyId").(string),
-		SellPrice:   doc.Get("sellPrice").(float64),
-		SellId:      doc.Get("sellId").(string),
-		CreatedAt:   createdAt,
-		CompletedAt: completedAt, // Ajout du nouveau champ
+// completedBetween indique si la date de complétion du document est dans l'intervalle
+func completedBetween(doc *clover.Document, start, end time.Time) bool {
+	completedAt := documentToCycle(doc).EffectiveCompletedAt()
+	if completedAt.IsZero() {
+		return false
 	}
-
-	return cycle, nil
+	return (start.IsZero() || !completedAt.Before(start)) && (end.IsZero() || completedAt.Before(end))
 }
 
 func (r *CycleRepository) Save(cycle *Cycle) (string, error) {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "save", IdInt: cycle.IdInt}) {
+		return "", nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -193,6 +390,26 @@ func (r *CycleRepository) Save(cycle *Cycle) (string, error) {
 		return "", fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
+	// Deux cycles ne doivent pas suivre le même ordre d'achat: ils vendraient le même BTC. Les
+	// cycles importés (--import) en sont exemptés: un achat revendu en plusieurs fois y forme
+	// plusieurs cycles complétés, et --import ignore les ordres déjà suivis par le bot.
+	if cycle.BuyId != "" && !cycle.Imported {
+		existing, err := r.db.Query(r.collection).Where(clover.Field("exchange").Eq(cycle.Exchange).
+			And(clover.Field("buyId").Eq(cycle.BuyId)).
+			And(clover.Field("idInt").Neq(cycle.IdInt)).
+			And(clover.Field("cancelReason").Neq(CancelReasonDuplicate))).FindAll()
+		if err != nil {
+			return "", err
+		}
+		for _, doc := range existing {
+			if imported, _ := doc.Get("imported").(bool); imported {
+				continue
+			}
+			return "", fmt.Errorf("%w: ordre %s (%s) du cycle %d", ErrDuplicateBuyId, cycle.BuyId, cycle.Exchange,
+				int32(docFloat(doc, "idInt")))
+		}
+	}
+
 	// Vérifier si c'est un nouveau cycle (il faut générer un ID)
 	if cycle.IdInt == 0 {
 		cycle.IdInt = r.getNextId()
@@ -215,9 +432,49 @@ func (r *CycleRepository) Save(cycle *Cycle) (string, error) {
 	doc.Set("createdAt", cycle.CreatedAt.Format(time.RFC3339))
 
 	// Champs de frais
-	//doc.Set("buyFees", cycle.BuyFees)
-	//doc.Set("sellFees", cycle.SellFees)
+	doc.Set("buyFees", cycle.BuyFees)
+	doc.Set("sellFees", cycle.SellFees)
 	doc.Set("totalFees", cycle.TotalFees)
+	doc.Set("buyFeeBTC", cycle.BuyFeeBTC)
+	doc.Set("buyFeeAsset", cycle.BuyFeeAsset)
+	doc.Set("buyFeeAssetAmount", cycle.BuyFeeAssetAmount)
+	doc.Set("sellFeeAsset", cycle.SellFeeAsset)
+	doc.Set("sellFeeAssetAmount", cycle.SellFeeAssetAmount)
+	doc.Set("feesEstimated", cycle.FeesEstimated)
+	doc.Set("paused", cycle.Paused)
+	doc.Set("tags", cycle.Tags)
+	doc.Set("repriceCount", cycle.RepriceCount)
+	doc.Set("imported", cycle.Imported)
+	doc.Set("buyFillPrice", cycle.BuyFillPrice)
+	doc.Set("sellFillPrice", cycle.SellFillPrice)
+	doc.Set("cancelReason", cycle.CancelReason)
+	doc.Set("cancelRetryCount", cycle.CancelRetryCount)
+	doc.Set("cancelRetryError", cycle.CancelRetryError)
+	doc.Set("stopId", cycle.StopId)
+	doc.Set("stopPrice", cycle.StopPrice)
+	doc.Set("stoppedOut", cycle.StoppedOut)
+	doc.Set("buyClientOrderId", cycle.BuyClientOrderId)
+	doc.Set("sellClientOrderId", cycle.SellClientOrderId)
+	doc.Set("groupId", cycle.GroupId)
+	doc.Set("averageDownState", cycle.AverageDownState)
+	doc.Set("averageDownBuyId", cycle.AverageDownBuyId)
+	doc.Set("averageDownClientOrderId", cycle.AverageDownClientOrderId)
+	doc.Set("averageDownQuantity", cycle.AverageDownQuantity)
+	doc.Set("averageDownPrice", cycle.AverageDownPrice)
+	doc.Set("averageDownFees", cycle.AverageDownFees)
+	doc.Set("averageDownCount", cycle.AverageDownCount)
+	doc.Set("sellRetryCount", cycle.SellRetryCount)
+	doc.Set("sellRetryError", cycle.SellRetryError)
+	if !cycle.SellRetryAt.IsZero() {
+		doc.Set("sellRetryAt", cycle.SellRetryAt.Format(time.RFC3339))
+	} else {
+		doc.Set("sellRetryAt", "")
+	}
+	if !cycle.CancelledAt.IsZero() {
+		doc.Set("cancelledAt", cycle.CancelledAt.Format(time.RFC3339))
+	} else {
+		doc.Set("cancelledAt", "")
+	}
 
 	// Ajouter la date de complétion si elle existe
 	if !cycle.CompletedAt.IsZero() {
@@ -225,8 +482,14 @@ func (r *CycleRepository) Save(cycle *Cycle) (string, error) {
 	} else {
 		doc.Set("completedAt", "")
 	}
+	if !cycle.ObservedCompletedAt.IsZero() {
+		doc.Set("observedCompletedAt", cycle.ObservedCompletedAt.Format(time.RFC3339))
+	} else {
+		doc.Set("observedCompletedAt", "")
+	}
+	doc.Set("orderSnapshots", cycle.OrderSnapshots.document())
 
-	docId, err := r.db.InsertOne(CollectionName, doc)
+	docId, err := r.db.InsertOne(r.collection, doc)
 	if err != nil {
 		return "", fmt.Errorf("erreur lors de l'insertion du document: %v", err)
 	}
@@ -234,8 +497,16 @@ func (r *CycleRepository) Save(cycle *Cycle) (string, error) {
 	return docId, nil
 }
 
+// ErrDuplicateBuyId est retournée par Save pour un cycle dont l'ordre d'achat est déjà suivi
+// par un autre cycle du même exchange
+var ErrDuplicateBuyId = errors.New("ordre d'achat déjà suivi par un autre cycle")
+
 // Update met à jour un champ spécifique d'un cycle
 func (r *CycleRepository) Update(id string, field string, value interface{}) error {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "update", Fields: map[string]interface{}{field: value}}) {
+		return nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -243,11 +514,15 @@ func (r *CycleRepository) Update(id string, field string, value interface{}) err
 		return fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	return r.db.Query(CollectionName).UpdateById(id, map[string]interface{}{field: value})
+	return r.db.Query(r.collection).UpdateById(id, map[string]interface{}{field: value})
 }
 
 // UpdateByIdInt met à jour un cycle par son ID entier
 func (r *CycleRepository) UpdateByIdInt(idInt int32, updates map[string]interface{}) error {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "update", IdInt: idInt, Fields: updates}) {
+		return nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -255,13 +530,17 @@ func (r *CycleRepository) UpdateByIdInt(idInt int32, updates map[string]interfac
 		return fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	return r.db.Query(CollectionName).
+	return r.db.Query(r.collection).
 		Where(clover.Field("idInt").Eq(idInt)).
 		Update(updates)
 }
 
 // Delete supprime un cycle par son ID
 func (r *CycleRepository) Delete(id string) error {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "delete"}) {
+		return nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -269,11 +548,15 @@ func (r *CycleRepository) Delete(id string) error {
 		return fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	return r.db.Query(CollectionName).DeleteById(id)
+	return r.db.Query(r.collection).DeleteById(id)
 }
 
 // DeleteByIdInt supprime un cycle par son ID entier
 func (r *CycleRepository) DeleteByIdInt(idInt int32) error {
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "delete", IdInt: idInt}) {
+		return nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -283,7 +566,7 @@ func (r *CycleRepository) DeleteByIdInt(idInt int32) error {
 		return fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	err := r.db.Query(CollectionName).
+	err := r.db.Query(r.collection).
 		Where(clover.Field("idInt").Eq(idInt)).
 		Delete()
 
@@ -306,7 +589,7 @@ func (r *CycleRepository) ListPaginated(page, perPage int) ([]*Cycle, error) {
 	}
 
 	skip := (page - 1) * perPage
-	docs, err := r.db.Query(CollectionName).
+	docs, err := r.db.Query(r.collection).
 		Sort(clover.SortOption{Field: "idInt", Direction: -1}).
 		Skip(skip).
 		Limit(perPage).
@@ -346,37 +629,37 @@ func (r *CycleRepository) ListPaginated(page, perPage int) ([]*Cycle, error) {
 	return cycles, nil
 }
 
-// getNextId génère un nouvel ID pour un cycle
+// NextId retourne l'ID qui sera attribué au prochain cycle enregistré. Il sert à nommer
+// l'ordre d'achat avant la création du cycle.
+func (r *CycleRepository) NextId() int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getNextId()
+}
+
+// getNextId génère un nouvel ID pour un cycle. Les cycles archivés sont pris en
+// compte pour qu'un ID ne soit jamais réutilisé.
 func (r *CycleRepository) getNextId() int32 {
 	if r.db == nil {
 		log.Printf("Base de données non initialisée lors de la génération d'ID")
 		return 1
 	}
 
-	count, err := r.db.Query(CollectionName).Count()
-	if err != nil {
-		log.Printf("Erreur lors du comptage des documents: %v", err)
-		return 1
-	}
-
-	if count == 0 {
-		return 1
-	}
-
-	lastDoc, err := r.db.Query(CollectionName).
-		Sort(clover.SortOption{Field: "idInt", Direction: -1}).
-		Limit(1).
-		FindFirst()
-
-	if err != nil || lastDoc == nil {
-		log.Printf("Erreur lors de la récupération du dernier document: %v", err)
-		return 1
+	var lastId int64
+	for _, collection := range []string{CollectionName, ArchiveCollectionName} {
+		lastDoc, err := r.db.Query(collection).
+			Sort(clover.SortOption{Field: "idInt", Direction: -1}).
+			FindFirst()
+		if err != nil {
+			log.Printf("Erreur lors de la récupération du dernier document de %s: %v", collection, err)
+			continue
+		}
+		if lastDoc != nil && lastDoc.Get("idInt").(int64) > lastId {
+			lastId = lastDoc.Get("idInt").(int64)
+		}
 	}
 
-	lastId := lastDoc.Get("idInt").(int64)
-	nextId := lastId + 1
-
-	return int32(nextId)
+	return int32(lastId + 1)
 }
 
 // CountByStatus compte les cycles par statut
@@ -388,7 +671,7 @@ func (r *CycleRepository) CountByStatus(status string) (int, error) {
 		return 0, fmt.Errorf("la base de données n'est pas initialisée")
 	}
 
-	count, err := r.db.Query(CollectionName).
+	count, err := r.db.Query(r.collection).
 		Where(clover.Field("status").Eq(status)).
 		Count()
 
@@ -417,11 +700,12 @@ func (r *CycleRepository) GetStatistics() (map[string]interface{}, error) {
 		switch cycle.Status {
 		case "completed":
 			stats["completedCycles"] = stats["completedCycles"].(int) + 1
-			buyValue := cycle.BuyPrice * cycle.Quantity
-			sellValue := cycle.SellPrice * cycle.Quantity
+			buyValue := cycle.EffectiveBuyPrice() * cycle.Quantity
+			sellValue := cycle.EffectiveSellPrice() * cycle.Quantity
 			stats["totalBuy"] = stats["totalBuy"].(float64) + buyValue
 			stats["totalSell"] = stats["totalSell"].(float64) + sellValue
-		case "buy":
+		case "buy", StatusCancelPending:
+			// Un ordre en attente d'annulation peut encore être exécuté: compté avec les achats
 			stats["buyCycles"] = stats["buyCycles"].(int) + 1
 		case "sell":
 			stats["sellCycles"] = stats["sellCycles"].(int) + 1
@@ -0,0 +1,46 @@
+package database
+
+import "sync"
+
+// WriteIntent décrit une écriture interceptée pendant une simulation (--simulate-update)
+type WriteIntent struct {
+	Collection string                 // Collection visée
+	Op         string                 // save, update ou delete
+	IdInt      int32                  // ID entier du document (0 s'il est inconnu)
+	Fields     map[string]interface{} // Champs modifiés (update uniquement)
+}
+
+// Hook de simulation: lorsqu'il est défini, les écritures des repositories lui sont
+// transmises au lieu d'être appliquées à la base
+var (
+	simulationMu   sync.Mutex
+	simulationHook func(WriteIntent)
+)
+
+// SimulateWrites remplace les écritures des repositories par des appels à hook jusqu'à
+// l'appel de la fonction retournée. Les lectures restent effectuées sur la base.
+func SimulateWrites(hook func(WriteIntent)) (restore func()) {
+	simulationMu.Lock()
+	previous := simulationHook
+	simulationHook = hook
+	simulationMu.Unlock()
+
+	return func() {
+		simulationMu.Lock()
+		simulationHook = previous
+		simulationMu.Unlock()
+	}
+}
+
+// interceptWrite transmet l'écriture au hook de simulation et indique si elle doit être ignorée
+func interceptWrite(intent WriteIntent) bool {
+	simulationMu.Lock()
+	hook := simulationHook
+	simulationMu.Unlock()
+
+	if hook == nil {
+		return false
+	}
+	hook(intent)
+	return true
+}
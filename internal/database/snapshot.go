@@ -0,0 +1,173 @@
+// internal/database/snapshot.go
+package database
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// SnapshotCollectionName est la collection des instantanés de la valeur du portefeuille
+const SnapshotCollectionName = "snapshots"
+
+// SnapshotBalance représente les soldes d'un exchange au moment de l'instantané
+type SnapshotBalance struct {
+	BTC      float64 `json:"btc"`      // Solde BTC total (libre + verrouillé)
+	USDC     float64 `json:"usdc"`     // Solde USDC total (libre + verrouillé)
+	BTCPrice float64 `json:"btcPrice"` // Prix du BTC sur cet exchange
+	Value    float64 `json:"value"`    // Valeur en USDC: BTC * prix + USDC
+}
+
+// Snapshot représente la valeur totale du portefeuille à un instant donné
+type Snapshot struct {
+	Timestamp  time.Time                  `json:"timestamp"`
+	BTCPrice   float64                    `json:"btcPrice"`   // Prix moyen du BTC sur les exchanges
+	TotalValue float64                    `json:"totalValue"` // Somme des valeurs de chaque exchange
+	Exchanges  map[string]SnapshotBalance `json:"exchanges"`
+}
+
+// SnapshotRepository gère les instantanés du portefeuille
+type SnapshotRepository struct {
+	db *clover.DB
+	mu sync.Mutex
+}
+
+// Save enregistre un instantané
+func (r *SnapshotRepository) Save(snapshot *Snapshot) error {
+	if interceptWrite(WriteIntent{Collection: SnapshotCollectionName, Op: "save"}) {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
+
+	exchanges := make(map[string]interface{}, len(snapshot.Exchanges))
+	for name, balance := range snapshot.Exchanges {
+		exchanges[name] = map[string]interface{}{
+			"btc":      balance.BTC,
+			"usdc":     balance.USDC,
+			"btcPrice": balance.BTCPrice,
+			"value":    balance.Value,
+		}
+	}
+
+	doc := clover.NewDocument()
+	doc.Set("timestamp", snapshot.Timestamp.Unix())
+	doc.Set("btcPrice", snapshot.BTCPrice)
+	doc.Set("totalValue", snapshot.TotalValue)
+	doc.Set("exchanges", exchanges)
+
+	if _, err := r.db.InsertOne(SnapshotCollectionName, doc); err != nil {
+		return fmt.Errorf("erreur lors de l'insertion de l'instantané: %v", err)
+	}
+	return nil
+}
+
+// FindBetween retourne les instantanés de la période, triés chronologiquement.
+// Une borne nulle n'est pas appliquée.
+func (r *SnapshotRepository) FindBetween(start, end *time.Time) ([]*Snapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := r.db.Query(SnapshotCollectionName)
+	if start != nil {
+		query = query.Where(clover.Field("timestamp").GtEq(start.Unix()))
+	}
+	if end != nil {
+		query = query.Where(clover.Field("timestamp").LtEq(end.Unix()))
+	}
+
+	docs, err := query.Sort(clover.SortOption{Field: "timestamp", Direction: 1}).FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*Snapshot, 0, len(docs))
+	for _, doc := range docs {
+		snapshots = append(snapshots, documentToSnapshot(doc))
+	}
+	return snapshots, nil
+}
+
+// ThinBefore ne conserve que le dernier instantané de chaque jour avant la date donnée
+// et retourne le nombre d'instantanés supprimés
+func (r *SnapshotRepository) ThinBefore(before time.Time) (int, error) {
+	if interceptWrite(WriteIntent{Collection: SnapshotCollectionName, Op: "delete"}) {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs, err := r.db.Query(SnapshotCollectionName).
+		Where(clover.Field("timestamp").Lt(before.Unix())).
+		Sort(clover.SortOption{Field: "timestamp", Direction: -1}).
+		FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	// Les documents sont parcourus du plus récent au plus ancien: le premier de chaque jour est conservé
+	kept := make(map[string]bool)
+	var ids []interface{}
+	for _, doc := range docs {
+		day := time.Unix(int64(docFloat(doc, "timestamp")), 0).Format("2006-01-02")
+		if kept[day] {
+			ids = append(ids, doc.ObjectId())
+			continue
+		}
+		kept[day] = true
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := r.db.Query(SnapshotCollectionName).Where(clover.Field("_id").In(ids...)).Delete(); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// documentToSnapshot convertit un document en instantané
+func documentToSnapshot(doc *clover.Document) *Snapshot {
+	snapshot := &Snapshot{
+		Timestamp:  time.Unix(int64(docFloat(doc, "timestamp")), 0),
+		BTCPrice:   docFloat(doc, "btcPrice"),
+		TotalValue: docFloat(doc, "totalValue"),
+		Exchanges:  make(map[string]SnapshotBalance),
+	}
+
+	if exchanges, ok := doc.Get("exchanges").(map[string]interface{}); ok {
+		for name, value := range exchanges {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			snapshot.Exchanges[name] = SnapshotBalance{
+				BTC:      mapFloat(fields, "btc"),
+				USDC:     mapFloat(fields, "usdc"),
+				BTCPrice: mapFloat(fields, "btcPrice"),
+				Value:    mapFloat(fields, "value"),
+			}
+		}
+	}
+	return snapshot
+}
+
+// mapFloat lit un champ numérique d'un sous-document (0 s'il est absent)
+func mapFloat(fields map[string]interface{}, field string) float64 {
+	switch value := fields[field].(type) {
+	case float64:
+		return value
+	case int64:
+		return float64(value)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,344 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ostafen/clover"
+)
+
+// cycleTransitions liste, pour chaque statut, les statuts vers lesquels un cycle peut passer.
+// completed et cancelled sont définitifs; l'accumulation d'une vente supprime le cycle et
+// n'apparaît donc pas ici.
+var cycleTransitions = map[string][]string{
+	StatusBuy:           {StatusSell, StatusCancelPending, StatusCancelled},
+	StatusCancelPending: {StatusCancelPending, StatusBuy, StatusSell, StatusCancelled},
+	StatusSell:          {StatusSell, StatusCompleted, StatusCancelled},
+	StatusCompleted:     nil,
+	StatusCancelled:     nil,
+}
+
+// ErrInvalidTransition est retournée par les méthodes Mark* pour un changement de statut que
+// cycleTransitions n'autorise pas
+var ErrInvalidTransition = errors.New("transition de statut invalide")
+
+// CheckTransition vérifie qu'un cycle peut passer du statut from au statut to
+func CheckTransition(from, to string) error {
+	next, known := cycleTransitions[from]
+	if !known {
+		return fmt.Errorf("%w: statut inconnu %q", ErrInvalidTransition, from)
+	}
+	for _, status := range next {
+		if status == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+}
+
+// SellRetry décrit l'échec du placement de la vente d'un cycle dont l'achat est exécuté
+type SellRetry struct {
+	ClientOrderId string    // Identifiant client de la vente, pour reprendre un ordre créé malgré l'erreur
+	Count         int       // Nombre d'échecs
+	Error         string    // Dernière erreur
+	At            time.Time // Prochaine tentative
+}
+
+// SellOrder décrit la vente placée pour un cycle
+type SellOrder struct {
+	OrderId        string
+	ClientOrderId  string
+	Price          float64
+	SaleAmountUSDC float64
+	StopId         string  // Stop de protection d'une vente OCO
+	StopPrice      float64 // Limite du stop
+}
+
+// Completion décrit l'exécution de la vente d'un cycle
+type Completion struct {
+	CompletedAt        time.Time // Date d'exécution donnée par l'exchange
+	ObservedAt         time.Time // Date de constatation par le bot (CompletedAt si vide)
+	SellFees           float64
+	TotalFees          float64
+	FeesEstimated      bool
+	StoppedOut         bool // Vente exécutée par le stop d'un OCO
+	SellFillPrice      float64
+	PurchaseAmountUSDC float64
+	SaleAmountUSDC     float64
+	SellFeeAsset       string  // Actif des frais de vente payés hors BTC et USDC (BNB), vide sinon
+	SellFeeAssetAmount float64 // Frais de vente payés dans cet actif
+}
+
+// MarkBuyFilled passe en vente en attente un cycle dont l'achat est exécuté mais dont la vente
+// n'a pas pu être placée: la tentative suivante est décrite par retry. Une vente déjà placée
+// n'est jamais effacée.
+func (r *CycleRepository) MarkBuyFilled(idInt int32, retry SellRetry) error {
+	if retry.Count <= 0 || retry.Error == "" {
+		return fmt.Errorf("cycle %d: échec de la vente sans tentative ni erreur", idInt)
+	}
+	return r.transition(idInt, StatusSell, map[string]interface{}{
+		"sellId":            "",
+		"sellClientOrderId": retry.ClientOrderId,
+		"sellRetryCount":    retry.Count,
+		"sellRetryError":    retry.Error,
+		"sellRetryAt":       retry.At.Format(time.RFC3339),
+	}, requireNoSellId)
+}
+
+// MarkSellPlaced enregistre la vente placée pour un cycle dont l'achat est exécuté, directement
+// ou après une vente en attente, et efface les tentatives en échec
+func (r *CycleRepository) MarkSellPlaced(idInt int32, order SellOrder) error {
+	if strings.TrimSpace(order.OrderId) == "" || order.Price <= 0 {
+		return fmt.Errorf("cycle %d: vente placée sans identifiant ou sans prix (%q, %.2f)", idInt, order.OrderId, order.Price)
+	}
+	updates := map[string]interface{}{
+		"sellId":            order.OrderId,
+		"sellClientOrderId": order.ClientOrderId,
+		"sellPrice":         order.Price,
+		"saleAmountUSDC":    order.SaleAmountUSDC,
+		"sellRetryCount":    0,
+		"sellRetryError":    "",
+		"sellRetryAt":       "",
+	}
+	if order.StopId != "" {
+		updates["stopId"] = order.StopId
+		updates["stopPrice"] = order.StopPrice
+	}
+	return r.transition(idInt, StatusSell, updates, requireNoSellId)
+}
+
+// AverageDownMerge décrit le cycle après la fusion d'un achat supplémentaire (--average-down)
+type AverageDownMerge struct {
+	Quantity           float64
+	BuyFillPrice       float64
+	PurchaseAmountUSDC float64
+	BuyFees            float64
+	Count              int // Nombre de fusions, celle-ci comprise
+}
+
+// ReplaceSell remplace la vente oldSellId d'un cycle en vente, annulée au préalable, par l'ordre
+// order. Le remplacement est refusé si la vente enregistrée n'est plus oldSellId: une autre
+// mise à jour l'a déjà remplacée ou constatée exécutée.
+func (r *CycleRepository) ReplaceSell(idInt int32, oldSellId string, order SellOrder) error {
+	return r.replaceSell(idInt, oldSellId, order, nil)
+}
+
+// MergeAverageDown remplace la vente oldSellId comme ReplaceSell en enregistrant dans la même
+// écriture la fusion de l'achat supplémentaire, dont l'étape est effacée
+func (r *CycleRepository) MergeAverageDown(idInt int32, oldSellId string, order SellOrder, merge AverageDownMerge) error {
+	if merge.Quantity <= 0 || merge.BuyFillPrice <= 0 || merge.Count <= 0 {
+		return fmt.Errorf("cycle %d: fusion sans quantité, prix ou compteur (%.8f, %.2f, %d)",
+			idInt, merge.Quantity, merge.BuyFillPrice, merge.Count)
+	}
+	return r.replaceSell(idInt, oldSellId, order, map[string]interface{}{
+		"quantity":                 merge.Quantity,
+		"buyFillPrice":             merge.BuyFillPrice,
+		"purchaseAmountUSDC":       merge.PurchaseAmountUSDC,
+		"buyFees":                  merge.BuyFees,
+		"totalFees":                merge.BuyFees,
+		"averageDownCount":         merge.Count,
+		"averageDownState":         "",
+		"averageDownBuyId":         "",
+		"averageDownClientOrderId": "",
+		"averageDownQuantity":      0.0,
+		"averageDownPrice":         0.0,
+		"averageDownFees":          0.0,
+	})
+}
+
+// replaceSell applique le remplacement de vente commun à ReplaceSell et MergeAverageDown
+func (r *CycleRepository) replaceSell(idInt int32, oldSellId string, order SellOrder, extra map[string]interface{}) error {
+	if strings.TrimSpace(oldSellId) == "" {
+		return fmt.Errorf("cycle %d: remplacement d'une vente sans identifiant", idInt)
+	}
+	if strings.TrimSpace(order.OrderId) == "" || order.Price <= 0 {
+		return fmt.Errorf("cycle %d: vente placée sans identifiant ou sans prix (%q, %.2f)", idInt, order.OrderId, order.Price)
+	}
+	updates := map[string]interface{}{
+		"sellId":            order.OrderId,
+		"sellClientOrderId": order.ClientOrderId,
+		"sellPrice":         order.Price,
+		"saleAmountUSDC":    order.SaleAmountUSDC,
+		"stopId":            order.StopId,
+		"stopPrice":         order.StopPrice,
+		"sellRetryCount":    0,
+		"sellRetryError":    "",
+		"sellRetryAt":       "",
+	}
+	for field, value := range extra {
+		updates[field] = value
+	}
+	return r.transition(idInt, StatusSell, updates, func(doc *clover.Document) error {
+		if doc.Get("status") != StatusSell {
+			return fmt.Errorf("%w: %v -> %s hors vente", ErrInvalidTransition, doc.Get("status"), StatusSell)
+		}
+		if sellId, _ := doc.Get("sellId").(string); sellId != oldSellId {
+			return fmt.Errorf("%w: vente %q enregistrée, %q attendue", ErrInvalidTransition, sellId, oldSellId)
+		}
+		return nil
+	})
+}
+
+// MarkCompleted passe en completed un cycle dont la vente est exécutée
+func (r *CycleRepository) MarkCompleted(idInt int32, completion Completion) error {
+	if completion.CompletedAt.IsZero() {
+		return fmt.Errorf("cycle %d: date d'exécution de la vente manquante", idInt)
+	}
+	observedAt := completion.ObservedAt
+	if observedAt.IsZero() {
+		observedAt = completion.CompletedAt
+	}
+	return r.transition(idInt, StatusCompleted, map[string]interface{}{
+		"completedAt":         completion.CompletedAt.Format(time.RFC3339),
+		"observedCompletedAt": observedAt.Format(time.RFC3339),
+		"sellFees":            completion.SellFees,
+		"totalFees":           completion.TotalFees,
+		"feesEstimated":       completion.FeesEstimated,
+		"stoppedOut":          completion.StoppedOut,
+		"sellFillPrice":       completion.SellFillPrice,
+		"purchaseAmountUSDC":  completion.PurchaseAmountUSDC,
+		"saleAmountUSDC":      completion.SaleAmountUSDC,
+		"sellFeeAsset":        completion.SellFeeAsset,
+		"sellFeeAssetAmount":  completion.SellFeeAssetAmount,
+	}, nil)
+}
+
+// MarkCancelled passe un cycle au statut cancelled en enregistrant la cause et la date de l'annulation
+func (r *CycleRepository) MarkCancelled(idInt int32, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("cycle %d: annulation sans cause", idInt)
+	}
+	return r.transition(idInt, StatusCancelled, map[string]interface{}{
+		"cancelReason":     reason,
+		"cancelledAt":      time.Now().Format(time.RFC3339),
+		"cancelRetryCount": 0,
+		"cancelRetryError": "",
+	}, nil)
+}
+
+// MarkCancelPending passe un cycle au statut cancel_pending après l'échec de l'annulation de son
+// ordre d'achat, avec la cause de l'annulation, le nombre de tentatives et la dernière erreur
+func (r *CycleRepository) MarkCancelPending(idInt int32, reason string, attempts int, cause string) error {
+	if reason == "" || attempts <= 0 {
+		return fmt.Errorf("cycle %d: annulation en attente sans cause ni tentative", idInt)
+	}
+	return r.transition(idInt, StatusCancelPending, map[string]interface{}{
+		"cancelReason":     reason,
+		"cancelRetryCount": attempts,
+		"cancelRetryError": cause,
+	}, nil)
+}
+
+// MarkCancelAborted rend son statut buy à un cycle en cancel_pending dont l'achat a été exécuté
+// avant que l'annulation n'aboutisse. buyId remplace l'identifiant de l'ordre s'il n'est pas vide.
+func (r *CycleRepository) MarkCancelAborted(idInt int32, buyId string) error {
+	updates := map[string]interface{}{
+		"cancelReason":     "",
+		"cancelRetryCount": 0,
+		"cancelRetryError": "",
+	}
+	if buyId != "" {
+		updates["buyId"] = buyId
+	}
+	return r.transition(idInt, StatusBuy, updates, func(doc *clover.Document) error {
+		if doc.Get("status") != StatusCancelPending {
+			return fmt.Errorf("%w: %v -> %s hors annulation en attente", ErrInvalidTransition, doc.Get("status"), StatusBuy)
+		}
+		return nil
+	})
+}
+
+// PartialFill décrit la part exécutée d'un achat dont l'annulation a été confirmée
+type PartialFill struct {
+	BuyId              string // Identifiant de l'ordre retrouvé par son identifiant client, vide sinon
+	Quantity           float64
+	BuyFillPrice       float64
+	PurchaseAmountUSDC float64
+	BuyFees            float64
+	FeesEstimated      bool
+}
+
+// MarkCancelPartiallyFilled passe en vente en attente un cycle en cancel_pending dont l'achat,
+// annulé, a été partiellement exécuté: le cycle ne porte plus que la quantité exécutée, dont la
+// vente est placée comme celle d'un achat exécuté
+func (r *CycleRepository) MarkCancelPartiallyFilled(idInt int32, fill PartialFill) error {
+	if fill.Quantity <= 0 || fill.BuyFillPrice <= 0 {
+		return fmt.Errorf("cycle %d: exécution partielle sans quantité ou sans prix (%.8f, %.2f)", idInt, fill.Quantity, fill.BuyFillPrice)
+	}
+	updates := map[string]interface{}{
+		"quantity":           fill.Quantity,
+		"buyFillPrice":       fill.BuyFillPrice,
+		"purchaseAmountUSDC": fill.PurchaseAmountUSDC,
+		"buyFees":            fill.BuyFees,
+		"totalFees":          fill.BuyFees,
+		"feesEstimated":      fill.FeesEstimated,
+		"cancelReason":       "",
+		"cancelRetryCount":   0,
+		"cancelRetryError":   "",
+		"sellId":             "",
+		"sellClientOrderId":  "",
+		"sellRetryCount":     0,
+		"sellRetryError":     "",
+		"sellRetryAt":        "",
+	}
+	if fill.BuyId != "" {
+		updates["buyId"] = fill.BuyId
+	}
+	return r.transition(idInt, StatusSell, updates, func(doc *clover.Document) error {
+		if doc.Get("status") != StatusCancelPending {
+			return fmt.Errorf("%w: %v -> %s hors annulation en attente", ErrInvalidTransition, doc.Get("status"), StatusSell)
+		}
+		return nil
+	})
+}
+
+// requireNoSellId refuse de remplacer la vente déjà placée d'un cycle en sell
+func requireNoSellId(doc *clover.Document) error {
+	if doc.Get("status") == StatusSell {
+		if sellId, _ := doc.Get("sellId").(string); strings.TrimSpace(sellId) != "" {
+			return fmt.Errorf("%w: vente %s déjà placée", ErrInvalidTransition, sellId)
+		}
+	}
+	return nil
+}
+
+// transition vérifie, sous le verrou, que le statut enregistré du cycle autorise le passage à
+// to et que check accepte le document, puis applique les mises à jour avec le nouveau statut.
+// En simulation, l'écriture est transmise au hook après les mêmes vérifications.
+func (r *CycleRepository) transition(idInt int32, to string, updates map[string]interface{},
+	check func(doc *clover.Document) error) error {
+	updates["status"] = to
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.db == nil {
+		return fmt.Errorf("la base de données n'est pas initialisée")
+	}
+
+	doc, err := r.db.Query(r.collection).Where(clover.Field("idInt").Eq(idInt)).FindFirst()
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return fmt.Errorf("cycle %d introuvable", idInt)
+	}
+	from, _ := doc.Get("status").(string)
+	if err := CheckTransition(from, to); err != nil {
+		return fmt.Errorf("cycle %d: %w", idInt, err)
+	}
+	if check != nil {
+		if err := check(doc); err != nil {
+			return fmt.Errorf("cycle %d: %w", idInt, err)
+		}
+	}
+
+	if interceptWrite(WriteIntent{Collection: r.collection, Op: "update", IdInt: idInt, Fields: updates}) {
+		return nil
+	}
+	return r.db.Query(r.collection).
+		Where(clover.Field("idInt").Eq(idInt)).
+		Update(updates)
+}
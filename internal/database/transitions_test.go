@@ -0,0 +1,181 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestMain ouvre une base temporaire: les tests ne touchent pas à la base du bot
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bot-spot-db-test")
+	if err != nil {
+		panic(err)
+	}
+	InitDatabaseAt(filepath.Join(dir, "db"))
+
+	code := m.Run()
+	CloseDatabase()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// saveCycleWithStatus enregistre un cycle au statut indiqué, avec une vente placée si sellId
+// n'est pas vide
+func saveCycleWithStatus(t *testing.T, status, sellId string) *Cycle {
+	t.Helper()
+	repo := GetRepository()
+	cycle := &Cycle{
+		Exchange:  "BINANCE",
+		Status:    status,
+		Quantity:  0.001,
+		BuyPrice:  60000,
+		SellPrice: 61200,
+		SellId:    sellId,
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+	return cycle
+}
+
+func TestCycleTransitions(t *testing.T) {
+	repo := GetRepository()
+	retry := SellRetry{ClientOrderId: "x-sell-1", Count: 1, Error: "insufficient balance", At: time.Now().Add(time.Minute)}
+	order := SellOrder{OrderId: "7781", ClientOrderId: "x-sell-1", Price: 61200, SaleAmountUSDC: 61.2}
+	completion := Completion{CompletedAt: time.Now(), SellFees: 0.06, TotalFees: 0.12, SellFillPrice: 61200}
+
+	markBuyFilled := func(id int32) error { return repo.MarkBuyFilled(id, retry) }
+	markSellPlaced := func(id int32) error { return repo.MarkSellPlaced(id, order) }
+	markCompleted := func(id int32) error { return repo.MarkCompleted(id, completion) }
+	markCancelled := func(id int32) error { return repo.MarkCancelled(id, CancelReasonManual) }
+	markCancelPending := func(id int32) error { return repo.MarkCancelPending(id, CancelReasonMaxAge, 1, "timeout") }
+	markCancelAborted := func(id int32) error { return repo.MarkCancelAborted(id, "") }
+	markPartiallyFilled := func(id int32) error {
+		return repo.MarkCancelPartiallyFilled(id, PartialFill{Quantity: 0.0004, BuyFillPrice: 60000, PurchaseAmountUSDC: 24})
+	}
+	replaceSell := func(id int32) error {
+		return repo.ReplaceSell(id, "7781", SellOrder{OrderId: "7790", Price: 61500, SaleAmountUSDC: 61.5})
+	}
+
+	tests := []struct {
+		name   string
+		from   string
+		sellId string
+		mark   func(id int32) error
+		want   string // Statut attendu, vide si la transition doit être refusée
+	}{
+		{"achat exécuté, vente en attente", StatusBuy, "", markBuyFilled, StatusSell},
+		{"achat exécuté, vente placée", StatusBuy, "", markSellPlaced, StatusSell},
+		{"nouvel échec de la vente en attente", StatusSell, "", markBuyFilled, StatusSell},
+		{"vente en attente placée", StatusSell, "", markSellPlaced, StatusSell},
+		{"vente exécutée", StatusSell, "7781", markCompleted, StatusCompleted},
+		{"vente annulée", StatusSell, "7781", markCancelled, StatusCancelled},
+		{"achat annulé", StatusBuy, "", markCancelled, StatusCancelled},
+		{"annulation en échec", StatusBuy, "", markCancelPending, StatusCancelPending},
+		{"annulation toujours en échec", StatusCancelPending, "", markCancelPending, StatusCancelPending},
+		{"annulation confirmée", StatusCancelPending, "", markCancelled, StatusCancelled},
+		{"achat exécuté pendant l'annulation", StatusCancelPending, "", markCancelAborted, StatusBuy},
+		{"achat partiellement exécuté pendant l'annulation", StatusCancelPending, "", markPartiallyFilled, StatusSell},
+		{"vente remplacée", StatusSell, "7781", replaceSell, StatusSell},
+
+		{"cycle complété annulé", StatusCompleted, "7781", markCancelled, ""},
+		{"cycle annulé revendu", StatusCancelled, "", markSellPlaced, ""},
+		{"achat complété sans vente", StatusBuy, "", markCompleted, ""},
+		{"vente placée effacée", StatusSell, "7781", markBuyFilled, ""},
+		{"vente placée remplacée", StatusSell, "7781", markSellPlaced, ""},
+		{"annulation d'une vente en attente", StatusSell, "", markCancelPending, ""},
+		{"reprise d'un achat hors annulation", StatusBuy, "", markCancelAborted, ""},
+		{"exécution partielle hors annulation", StatusBuy, "", markPartiallyFilled, ""},
+		{"vente déjà remplacée", StatusSell, "7785", replaceSell, ""},
+		{"vente remplacée après son exécution", StatusCompleted, "7781", replaceSell, ""},
+		{"vente remplacée sur un achat", StatusBuy, "", replaceSell, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle := saveCycleWithStatus(t, tt.from, tt.sellId)
+
+			err := tt.mark(cycle.IdInt)
+			stored, findErr := repo.FindByIdInt(cycle.IdInt)
+			if findErr != nil || stored == nil {
+				t.Fatalf("lecture du cycle: %v", findErr)
+			}
+
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("%s: erreur %v, want ErrInvalidTransition", tt.from, err)
+				}
+				if stored.Status != tt.from || stored.SellId != tt.sellId {
+					t.Errorf("cycle modifié malgré le refus: %s, vente %q", stored.Status, stored.SellId)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s -> %s: %v", tt.from, tt.want, err)
+			}
+			if stored.Status != tt.want {
+				t.Errorf("statut %s, want %s", stored.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkRequiresFields(t *testing.T) {
+	repo := GetRepository()
+	cycle := saveCycleWithStatus(t, StatusBuy, "")
+
+	if err := repo.MarkSellPlaced(cycle.IdInt, SellOrder{Price: 61200}); err == nil {
+		t.Error("une vente sans identifiant devrait être refusée")
+	}
+	if err := repo.MarkSellPlaced(cycle.IdInt, SellOrder{OrderId: "7781"}); err == nil {
+		t.Error("une vente sans prix devrait être refusée")
+	}
+	if err := repo.MarkBuyFilled(cycle.IdInt, SellRetry{}); err == nil {
+		t.Error("une vente en attente sans échec devrait être refusée")
+	}
+	if err := repo.MarkCancelled(cycle.IdInt, ""); err == nil {
+		t.Error("une annulation sans cause devrait être refusée")
+	}
+
+	if err := repo.MarkSellPlaced(cycle.IdInt, SellOrder{OrderId: "7781", Price: 61200}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkCompleted(cycle.IdInt, Completion{}); err == nil {
+		t.Error("une vente exécutée sans date devrait être refusée")
+	}
+
+	completedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := repo.MarkCompleted(cycle.IdInt, Completion{CompletedAt: completedAt, SellFillPrice: 61250}); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.CompletedAt.Equal(completedAt) || !stored.ObservedCompletedAt.Equal(completedAt) {
+		t.Errorf("dates %v / %v, want %v pour les deux", stored.CompletedAt, stored.ObservedCompletedAt, completedAt)
+	}
+	if stored.SellId != "7781" || stored.SellFillPrice != 61250 {
+		t.Errorf("vente %q à %.2f, want 7781 à 61250", stored.SellId, stored.SellFillPrice)
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	if err := CheckTransition(StatusBuy, StatusSell); err != nil {
+		t.Errorf("buy -> sell: %v", err)
+	}
+	for _, tt := range [][2]string{
+		{StatusCompleted, StatusSell},
+		{StatusCancelled, StatusBuy},
+		{StatusSell, StatusBuy},
+		{"accumulated", StatusSell},
+	} {
+		if err := CheckTransition(tt[0], tt[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: %v, want ErrInvalidTransition", tt[0], tt[1], err)
+		}
+	}
+}
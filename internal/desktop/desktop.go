@@ -0,0 +1,17 @@
+// internal/desktop/desktop.go
+
+// Package desktop affiche des notifications natives du système (toast Windows).
+// Sur les autres systèmes, Notify retourne ErrUnavailable: l'appelant journalise et continue.
+package desktop
+
+import "errors"
+
+// ErrUnavailable indique que les notifications de bureau ne peuvent pas être affichées
+// sur ce système (plateforme non prise en charge, PowerShell absent, session sans bureau...)
+var ErrUnavailable = errors.New("notifications de bureau indisponibles")
+
+// Notify affiche une notification de bureau. Les erreurs enveloppent ErrUnavailable
+// lorsque le système ne permet pas l'affichage.
+func Notify(title, message string) error {
+	return notify(title, message)
+}
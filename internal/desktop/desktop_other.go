@@ -0,0 +1,9 @@
+//go:build !windows
+
+// internal/desktop/desktop_other.go
+package desktop
+
+// notify n'est pas pris en charge hors de Windows
+func notify(title, message string) error {
+	return ErrUnavailable
+}
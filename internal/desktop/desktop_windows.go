@@ -0,0 +1,72 @@
+// internal/desktop/desktop_windows.go
+package desktop
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// appID est l'identifiant d'application de PowerShell: Windows n'affiche un toast que pour
+// une application enregistrée, ce qui évite d'avoir à installer un raccourci pour le bot
+const appID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// notifyTimeout borne la durée du processus PowerShell
+const notifyTimeout = 15 * time.Second
+
+// createNoWindow empêche l'ouverture d'une console pour le processus PowerShell
+const createNoWindow = 0x08000000
+
+// toastScript charge le XML du toast depuis la variable BOT_TOAST_XML: le texte n'est
+// ainsi jamais interprété par PowerShell
+const toastScript = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml($env:BOT_TOAST_XML)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:BOT_TOAST_APPID).Show($toast)`
+
+// toastXML construit le contenu du toast en échappant le titre et le message
+func toastXML(title, message string) string {
+	var b strings.Builder
+	b.WriteString(`<toast><visual><binding template="ToastGeneric"><text>`)
+	xml.EscapeText(&b, []byte(title))
+	b.WriteString(`</text><text>`)
+	xml.EscapeText(&b, []byte(message))
+	b.WriteString(`</text></binding></visual></toast>`)
+	return b.String()
+}
+
+// notify affiche un toast via PowerShell et les API WinRT de notification
+func notify(title, message string) error {
+	powershell, err := exec.LookPath("powershell.exe")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, powershell, "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"BOT_TOAST_XML="+toastXML(title, message),
+		"BOT_TOAST_APPID="+appID)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return fmt.Errorf("%w: %v (%s)", ErrUnavailable, err, detail)
+		}
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
@@ -4,12 +4,14 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"main/internal/exchanges/common"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -18,12 +20,27 @@ import (
 	"github.com/fatih/color"
 )
 
+// DefaultHTTPTimeout est le délai des requêtes HTTP vers Binance sans BINANCE_HTTP_TIMEOUT_SECONDS
+const DefaultHTTPTimeout = 10 * time.Second
+
 type Client struct {
 	APIKey    string
 	APISecret string
 	BaseURL   string
+	// Écart en pourcentage pour les ordres maker (0 = 0.2%)
+	MakerBufferPercent float64
+	// Taux de frais utilisés pour les estimations quand les frais réels sont inconnus
+	FeeRates common.FeeRates
+	// Délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+	HTTPTimeout time.Duration
+	// Fenêtre de validité des requêtes signées en millisecondes (0 = celle de l'exchange)
+	RecvWindowMs int
 	// Cache pour les règles de symbole
 	symbolRules map[string]SymbolRules
+	// Décalage avec l'horloge de Binance, mesuré au premier rejet d'horodatage
+	clock common.ClockSkew
+	// Pas de prix et de quantité de BTCUSDC, lus au premier ordre
+	precision common.PrecisionCache
 }
 
 // DetailedBalance représente les informations détaillées d'un solde d'actif
@@ -38,6 +55,7 @@ type SymbolRules struct {
 	MaxQty      float64
 	StepSize    float64
 	MinNotional float64
+	TickSize    float64
 }
 
 // internal/exchanges/binance/client.go
@@ -48,6 +66,7 @@ func NewClient(apiKey, apiSecret string) *Client {
 		APIKey:      apiKey,
 		APISecret:   apiSecret,
 		BaseURL:     "https://api.binance.com",
+		FeeRates:    common.FeeRates{Maker: 0.001, Taker: 0.001},
 		symbolRules: make(map[string]SymbolRules),
 	}
 }
@@ -56,6 +75,35 @@ func (c *Client) SetBaseURL(url string) {
 	c.BaseURL = url
 }
 
+// SetMakerBufferPercent définit l'écart en pourcentage utilisé par CreateMakerOrder (0 = 0.2%)
+func (c *Client) SetMakerBufferPercent(percent float64) {
+	c.MakerBufferPercent = percent
+}
+
+// SetFeeRates définit les taux maker et taker utilisés pour estimer les frais
+func (c *Client) SetFeeRates(rates common.FeeRates) {
+	c.FeeRates = rates
+}
+
+// SetHTTPTimeout définit le délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+func (c *Client) SetHTTPTimeout(timeout time.Duration) {
+	c.HTTPTimeout = timeout
+}
+
+// httpTimeout retourne le délai effectif des requêtes HTTP
+func (c *Client) httpTimeout() time.Duration {
+	if c.HTTPTimeout > 0 {
+		return c.HTTPTimeout
+	}
+	return DefaultHTTPTimeout
+}
+
+// SetRecvWindow définit la fenêtre de validité des requêtes signées en millisecondes
+// (0 = celle de l'exchange, common.DefaultRecvWindowMs)
+func (c *Client) SetRecvWindow(recvWindowMs int) {
+	c.RecvWindowMs = recvWindowMs
+}
+
 // Generates HMAC SHA256 signature for a signed request
 func (c *Client) signRequest(queryString string) string {
 	h := hmac.New(sha256.New, []byte(c.APISecret))
@@ -63,8 +111,77 @@ func (c *Client) signRequest(queryString string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// Sends an HTTP request and returns the response body
+// sendRequest envoie la requête. Si Binance rejette l'horodatage d'une requête signée
+// (code -1021, horloge locale décalée), le décalage est mesuré sur l'heure du serveur, appliqué
+// aux requêtes suivantes de la session, et la requête est renvoyée une fois corrigée.
 func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, error) {
+	queryString = common.WithRecvWindow(queryString, c.RecvWindowMs, c.signRequest)
+	body, err := c.doRequest(method, endpoint, queryString)
+	if err == nil || !isTimestampError(err) || !strings.Contains(queryString, "&signature=") {
+		return body, err
+	}
+
+	offset, syncErr := c.clock.Sync(c.ServerTime)
+	if syncErr != nil {
+		return nil, fmt.Errorf("%w (heure du serveur indisponible: %v)", err, syncErr)
+	}
+	color.Yellow("Horloge locale décalée de %s par rapport à Binance: correction appliquée pour la session", offset.Round(time.Millisecond))
+
+	retried, _ := common.Retimestamp(queryString, c.clock.Timestamp(), c.signRequest)
+	return c.doRequest(method, endpoint, retried)
+}
+
+// isTimestampError indique si Binance a rejeté l'horodatage de la requête (hors de recvWindow)
+func isTimestampError(err error) bool {
+	return strings.Contains(err.Error(), "-1021")
+}
+
+// ServerTime retourne l'heure du serveur Binance
+func (c *Client) ServerTime() (time.Time, error) {
+	body, err := c.doRequest("GET", "/api/v3/time", "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	serverTime, err := jsonparser.GetInt(body, "serverTime")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("réponse /api/v3/time invalide: %w", err)
+	}
+	return time.UnixMilli(serverTime), nil
+}
+
+// ClockOffset retourne la correction appliquée aux horodatages des requêtes signées
+func (c *Client) ClockOffset() time.Duration {
+	return c.clock.Offset()
+}
+
+// GetOrderBook retourne le meilleur achat et la meilleure vente BTC/USDC (/api/v3/ticker/bookTicker)
+func (c *Client) GetOrderBook() (common.BookTop, error) {
+	body, err := c.doRequest("GET", "/api/v3/ticker/bookTicker", "symbol=BTCUSDC")
+	if err != nil {
+		return common.BookTop{}, err
+	}
+	return parseBookTicker(body)
+}
+
+// parseBookTicker lit la réponse de /api/v3/ticker/bookTicker
+func parseBookTicker(body []byte) (common.BookTop, error) {
+	var ticker struct {
+		BidPrice string `json:"bidPrice"`
+		AskPrice string `json:"askPrice"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return common.BookTop{}, fmt.Errorf("réponse bookTicker invalide: %w", err)
+	}
+	bid, bidErr := strconv.ParseFloat(ticker.BidPrice, 64)
+	ask, askErr := strconv.ParseFloat(ticker.AskPrice, 64)
+	if bidErr != nil || askErr != nil {
+		return common.BookTop{}, fmt.Errorf("prix du bookTicker invalides: %s", string(body))
+	}
+	return common.BookTop{Bid: bid, Ask: ask}, nil
+}
+
+// doRequest envoie une requête HTTP et retourne le corps de la réponse
+func (c *Client) doRequest(method, endpoint, queryString string) ([]byte, error) {
 	fullURL := fmt.Sprintf("%s%s?%s", c.BaseURL, endpoint, queryString)
 
 	req, err := http.NewRequest(method, fullURL, nil)
@@ -75,12 +192,14 @@ func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, erro
 	req.Header.Set("X-MBX-APIKEY", c.APIKey)
 
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   c.httpTimeout(),
+		Transport: common.MeteredTransport("BINANCE"),
 	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	recordRateLimit(resp.Header)
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
@@ -93,13 +212,31 @@ func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, erro
 		return nil, err
 	}
 
+	if common.IsMaintenanceStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("%w: %w", common.ErrMaintenance, common.NewHTTPStatusError("HTTP status %d - %s", resp.StatusCode, string(body)))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: HTTP status %d - %s", resp.StatusCode, string(body))
+		return nil, common.NewHTTPStatusError("error: HTTP status %d - %s", resp.StatusCode, string(body))
 	}
 
 	return body, nil
 }
 
+// binanceWeightLimit est le poids de requêtes autorisé par minute et par IP (REQUEST_WEIGHT)
+const binanceWeightLimit = 6000
+
+// recordRateLimit relève le poids consommé dans la minute courante (X-MBX-USED-WEIGHT-1M)
+func recordRateLimit(header http.Header) {
+	if used, err := strconv.Atoi(header.Get("X-MBX-USED-WEIGHT-1M")); err == nil {
+		common.RateLimits.Record("BINANCE", used, binanceWeightLimit, time.Minute)
+	}
+}
+
+// GetRateLimitStatus retourne le poids de requêtes consommé relevé sur les dernières réponses
+func (c *Client) GetRateLimitStatus() common.RateLimitStatus {
+	return common.RateLimits.Status("BINANCE")
+}
+
 func (c *Client) CheckConnection() error {
 	_, err := c.sendRequest("GET", "/api/v3/ping", "")
 	if err != nil {
@@ -115,7 +252,7 @@ func (c *Client) CheckConnection() error {
 func (c *Client) GetBalanceUSD() float64 {
 	color.Blue("Checking USDC balance...")
 
-	timestamp := time.Now().UnixMilli()
+	timestamp := c.clock.Now().UnixMilli()
 	queryString := fmt.Sprintf("timestamp=%d", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -198,6 +335,9 @@ func (c *Client) GetSymbolRules(symbol string) (SymbolRules, error) {
 				} else if filterType == "MIN_NOTIONAL" {
 					minNotionalStr, _ := jsonparser.GetString(filter, "minNotional")
 					rules.MinNotional, _ = strconv.ParseFloat(minNotionalStr, 64)
+				} else if filterType == "PRICE_FILTER" {
+					tickSizeStr, _ := jsonparser.GetString(filter, "tickSize")
+					rules.TickSize, _ = strconv.ParseFloat(tickSizeStr, 64)
 				}
 			}, "filters")
 		}
@@ -212,6 +352,28 @@ func (c *Client) GetSymbolRules(symbol string) (SymbolRules, error) {
 	return rules, nil
 }
 
+// Precision retourne le pas de prix (PRICE_FILTER) et de quantité (LOT_SIZE) de BTCUSDC,
+// DefaultPrecision si les règles ne peuvent pas être lues
+func (c *Client) Precision() common.Precision {
+	return c.precision.Get(func() (common.Precision, error) {
+		rules, err := c.GetSymbolRules("BTCUSDC")
+		if err != nil {
+			return common.Precision{}, err
+		}
+		return common.Precision{PriceTick: rules.TickSize, QuantityStep: rules.StepSize}, nil
+	})
+}
+
+// FormatPrice formate un prix au pas de prix de BTCUSDC
+func (c *Client) FormatPrice(price float64) string {
+	return c.Precision().FormatPrice(price)
+}
+
+// FormatQuantity formate une quantité au pas de quantité de BTCUSDC
+func (c *Client) FormatQuantity(quantity float64) string {
+	return c.Precision().FormatQuantity(quantity)
+}
+
 // Ajuste la quantité pour respecter les règles de LOT_SIZE
 func (c *Client) AdjustQuantity(symbol string, quantity float64) (float64, error) {
 	rules, err := c.GetSymbolRules(symbol)
@@ -253,50 +415,64 @@ func (c *Client) CalculateQuantity(usdcAmount, price float64) (float64, error) {
 	return c.AdjustQuantity("BTCUSDC", rawQuantity)
 }
 
-func (c *Client) CreateOrder(side string, price, quantity string) ([]byte, error) {
+func (c *Client) CreateOrder(side string, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
 	// Convertir price et quantity en float pour pouvoir calculer et ajuster
 	priceFloat, err := strconv.ParseFloat(price, 64)
 	if err != nil {
-		return nil, fmt.Errorf("invalid price format: %v", err)
+		return common.OrderCreateResult{}, fmt.Errorf("invalid price format: %v", err)
 	}
 
 	quantityFloat, err := strconv.ParseFloat(quantity, 64)
 	if err != nil {
-		return nil, fmt.Errorf("invalid quantity format: %v", err)
+		return common.OrderCreateResult{}, fmt.Errorf("invalid quantity format: %v", err)
 	}
 
 	// Récupérer les règles de symbole
 	rules, err := c.GetSymbolRules("BTCUSDC")
 	if err != nil {
-		return nil, fmt.Errorf("error getting symbol rules: %v", err)
+		return common.OrderCreateResult{}, fmt.Errorf("error getting symbol rules: %v", err)
 	}
 
 	// Ajuster la quantité selon les règles
 	adjustedQuantity, err := c.AdjustQuantity("BTCUSDC", quantityFloat)
 	if err != nil {
-		return nil, fmt.Errorf("quantity adjustment failed: %v", err)
+		return common.OrderCreateResult{}, fmt.Errorf("quantity adjustment failed: %v", err)
 	}
 
 	// Vérifier la valeur notionnelle minimale (prix * quantité >= minNotional)
 	notional := priceFloat * adjustedQuantity
 	if notional < rules.MinNotional {
-		return nil, fmt.Errorf("order value %.2f USDC is below minimum allowed %.2f USDC", notional, rules.MinNotional)
+		return common.OrderCreateResult{}, fmt.Errorf("order value %.2f USDC is below minimum allowed %.2f USDC", notional, rules.MinNotional)
 	}
 
 	// Formatter la quantité avec la précision correcte
-	stepSizeStr := strconv.FormatFloat(rules.StepSize, 'f', -1, 64)
-	decimals := 0
-	if strings.Contains(stepSizeStr, ".") {
-		decimals = len(stepSizeStr) - strings.IndexByte(stepSizeStr, '.') - 1
+	adjustedQuantityStr := formatQuantity(adjustedQuantity, rules.StepSize)
+
+	// Un ordre LIMIT_MAKER est rejeté par Binance s'il devait s'exécuter immédiatement
+	orderType := "type=LIMIT&timeInForce=GTC"
+	if common.PostOnlyRequested(opts) {
+		orderType = "type=LIMIT_MAKER"
+	}
+	// Ordre au marché: sans prix ni durée de validité, réponse complète avec les exécutions
+	market := common.MarketRequested(opts)
+	if market {
+		orderType = "type=MARKET&newOrderRespType=FULL"
+	}
+	// Identifiant client: Binance refuse un second ordre ouvert avec le même newClientOrderId
+	if clientOrderID := common.ClientOrderIDRequested(opts); clientOrderID != "" {
+		orderType += "&newClientOrderId=" + url.QueryEscape(clientOrderID)
 	}
-	adjustedQuantityStr := strconv.FormatFloat(adjustedQuantity, 'f', decimals, 64)
 
 	// Créer la requête d'ordre
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 	queryString := fmt.Sprintf(
-		"symbol=BTCUSDC&side=%s&type=LIMIT&timeInForce=GTC&quantity=%s&price=%s&timestamp=%s",
-		side, adjustedQuantityStr, price, timestamp,
+		"symbol=BTCUSDC&side=%s&%s&quantity=%s&price=%s&timestamp=%s",
+		side, orderType, adjustedQuantityStr, price, timestamp,
 	)
+	if market {
+		queryString = fmt.Sprintf("symbol=BTCUSDC&side=%s&%s&quantity=%s&timestamp=%s",
+			side, orderType, adjustedQuantityStr, timestamp)
+	}
 
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -304,16 +480,122 @@ func (c *Client) CreateOrder(side string, price, quantity string) ([]byte, error
 	// Envoyer la requête
 	body, err := c.sendRequest("POST", "/api/v3/order", signedQuery)
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
+		return common.OrderCreateResult{}, fmt.Errorf("error sending request: %v", err)
 	}
 
-	return body, nil
+	return decodeOrderCreated(body)
+}
+
+// decodeOrderCreated extrait l'ID de la réponse de POST /api/v3/order ({"orderId":28,...}).
+// Une réponse d'erreur ({"code":-2010,"msg":"..."}) est retournée comme erreur.
+func decodeOrderCreated(body []byte) (common.OrderCreateResult, error) {
+	if orderId := common.JSONID(body, "orderId"); orderId != "" {
+		return common.NewOrderCreateResult(orderId, body)
+	}
+	if msg, err := jsonparser.GetString(body, "msg"); err == nil && msg != "" {
+		code, _ := jsonparser.GetInt(body, "code")
+		return common.OrderCreateResult{}, fmt.Errorf("ordre refusé par Binance: %d - %s", code, msg)
+	}
+	return common.NewOrderCreateResult("", body)
+}
+
+// formatQuantity formate une quantité avec le nombre de décimales du stepSize
+func formatQuantity(quantity, stepSize float64) string {
+	stepSizeStr := strconv.FormatFloat(stepSize, 'f', -1, 64)
+	decimals := 0
+	if strings.Contains(stepSizeStr, ".") {
+		decimals = len(stepSizeStr) - strings.IndexByte(stepSizeStr, '.') - 1
+	}
+	return strconv.FormatFloat(quantity, 'f', decimals, 64)
+}
+
+// CreateOCOOrder place une vente OCO: une jambe LIMIT_MAKER au prix cible au-dessus du
+// marché et une jambe STOP_LOSS_LIMIT en dessous. L'exécution de l'une annule l'autre.
+func (c *Client) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	quantityFloat, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("invalid quantity format: %v", err)
+	}
+
+	rules, err := c.GetSymbolRules("BTCUSDC")
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("error getting symbol rules: %v", err)
+	}
+	adjustedQuantity, err := c.AdjustQuantity("BTCUSDC", quantityFloat)
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("quantity adjustment failed: %v", err)
+	}
+
+	// Binance impose abovePrice > dernier prix > belowStopPrice pour une vente
+	if stopLimitPrice > stopPrice || stopPrice >= sellPrice {
+		return common.OCOOrder{}, fmt.Errorf("prix OCO incohérents: vente %.2f, stop %.2f, limite du stop %.2f",
+			sellPrice, stopPrice, stopLimitPrice)
+	}
+
+	tickSize := rules.TickSize
+	if tickSize <= 0 {
+		tickSize = 0.01
+	}
+
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf(
+		"symbol=BTCUSDC&side=SELL&quantity=%s&aboveType=LIMIT_MAKER&abovePrice=%s"+
+			"&belowType=STOP_LOSS_LIMIT&belowStopPrice=%s&belowPrice=%s&belowTimeInForce=GTC&timestamp=%s",
+		formatQuantity(adjustedQuantity, rules.StepSize),
+		common.FormatPrice(sellPrice, tickSize),
+		common.FormatPrice(stopPrice, tickSize),
+		common.FormatPrice(stopLimitPrice, tickSize),
+		timestamp,
+	)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, c.signRequest(queryString))
+
+	body, err := c.sendRequest("POST", "/api/v3/orderList/oco", signedQuery)
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("error sending request: %v", err)
+	}
+	return parseOCOResponse(body)
+}
+
+// parseOCOResponse identifie les deux jambes d'un OCO d'après le type de chaque ordre
+func parseOCOResponse(body []byte) (common.OCOOrder, error) {
+	listId, err := jsonparser.GetInt(body, "orderListId")
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("orderListId absent de la réponse OCO: %s", body)
+	}
+	order := common.OCOOrder{ListID: strconv.FormatInt(listId, 10)}
+
+	_, err = jsonparser.ArrayEach(body, func(report []byte, _ jsonparser.ValueType, _ int, _ error) {
+		orderId, idErr := jsonparser.GetInt(report, "orderId")
+		if idErr != nil {
+			return
+		}
+		orderType, _ := jsonparser.GetString(report, "type")
+		switch orderType {
+		case "LIMIT_MAKER", "LIMIT":
+			order.LimitID = strconv.FormatInt(orderId, 10)
+		case "STOP_LOSS_LIMIT", "STOP_LOSS":
+			order.StopID = strconv.FormatInt(orderId, 10)
+		}
+	}, "orderReports")
+	if err != nil || order.LimitID == "" || order.StopID == "" {
+		return common.OCOOrder{}, fmt.Errorf("jambes de l'OCO introuvables dans la réponse: %s", body)
+	}
+	return order, nil
+}
+
+// orderQuery retourne le paramètre identifiant un ordre: orderId pour l'ID numérique attribué
+// par Binance, origClientOrderId pour un ID client (x-..., web_..., and_...)
+func orderQuery(id string) string {
+	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return "orderId=" + id
+	}
+	return "origClientOrderId=" + url.QueryEscape(id)
 }
 
 func (c *Client) GetOrderById(id string) ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 
-	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", id, timestamp)
+	queryString := fmt.Sprintf("symbol=BTCUSDC&%s&timestamp=%s", orderQuery(id), timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
@@ -326,19 +608,179 @@ func (c *Client) GetOrderById(id string) ([]byte, error) {
 	return body, nil
 }
 
-func (c *Client) IsFilled(order string) bool {
-	status, err := jsonparser.GetString([]byte(order), "status")
+// GetOrderStatus récupère un ordre et en interprète l'état. /api/v3/order ne détaille pas les
+// exécutions: celles d'un ordre exécuté sont lues dans myTrades pour connaître ses frais, en
+// particulier ceux prélevés en BTC sur la quantité achetée.
+func (c *Client) GetOrderStatus(id string) (common.OrderStatus, error) {
+	body, err := c.GetOrderById(id)
 	if err != nil {
-		log.Fatal(err)
+		return common.OrderStatus{}, err
+	}
+	status, err := parseOrderStatus(body)
+	if err != nil || !status.Filled() {
+		return status, err
+	}
+	if _, _, _, fillsErr := jsonparser.Get(body, "fills"); fillsErr == nil {
+		c.convertFeeAsset(&status, status.UpdatedAt)
+		return status, nil
+	}
+
+	// Sans les exécutions, les frais restent inconnus (Fee et BaseFee à 0): GetOrderFees les estime
+	if orderId, idErr := jsonparser.GetInt(body, "orderId"); idErr == nil {
+		if trades, tradesErr := c.orderTrades(orderId); tradesErr == nil {
+			fees := parseFills(trades)
+			status.Fee, status.BaseFee = fees.quote, fees.base
+			status.FeeAsset, status.FeeAssetAmount = fees.asset, fees.assetAmount
+			c.convertFeeAsset(&status, fees.at)
+		}
 	}
+	return status, nil
+}
 
-	return status == "FILLED"
+// convertFeeAsset ajoute aux frais en USDC ceux payés en BNB, convertis au prix de BNBUSDC à la
+// date de l'exécution. Si le prix est introuvable, Fee ne compte que les frais en USDC et BTC.
+func (c *Client) convertFeeAsset(status *common.OrderStatus, at time.Time) {
+	if status.FeeAssetAmount == 0 {
+		return
+	}
+	if price, err := c.assetPriceAt(status.FeeAsset, at); err == nil {
+		status.Fee += status.FeeAssetAmount * price
+	}
+}
+
+// assetPriceAt retourne le prix en USDC d'un actif à une date: clôture de la bougie d'une minute
+// de la paire ACTIFUSDC qui la contient, ou dernier prix pour une date inconnue
+func (c *Client) assetPriceAt(asset string, at time.Time) (float64, error) {
+	symbol := asset + "USDC"
+	if at.IsZero() {
+		body, err := c.sendRequest("GET", "/api/v3/ticker/price", "symbol="+symbol)
+		if err != nil {
+			return 0, fmt.Errorf("prix de %s: %w", symbol, err)
+		}
+		if price := common.OrderFloat(body, "price"); price > 0 {
+			return price, nil
+		}
+		return 0, fmt.Errorf("prix de %s absent: %s", symbol, body)
+	}
+
+	minute := at.Truncate(time.Minute)
+	query := fmt.Sprintf("symbol=%s&interval=1m&startTime=%d&limit=1", symbol, minute.UnixMilli())
+	body, err := c.sendRequest("GET", "/api/v3/klines", query)
+	if err != nil {
+		return 0, fmt.Errorf("prix de %s le %s: %w", symbol, minute.Format(time.RFC3339), err)
+	}
+	closePrice, err := jsonparser.GetString(body, "[0]", "[4]")
+	if err != nil {
+		return 0, fmt.Errorf("bougie %s absente le %s", symbol, minute.Format(time.RFC3339))
+	}
+	price, err := strconv.ParseFloat(closePrice, 64)
+	if err != nil || price <= 0 {
+		return 0, fmt.Errorf("prix de %s invalide: %s", symbol, closePrice)
+	}
+	return price, nil
+}
+
+// orderTrades retourne les exécutions d'un ordre (myTrades n'accepte que l'ID numérique)
+func (c *Client) orderTrades(orderId int64) ([]byte, error) {
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%d&timestamp=%s", orderId, timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+	return c.sendRequest("GET", "/api/v3/myTrades", signedQuery)
+}
+
+// fillFees sont les frais des exécutions d'un ordre
+type fillFees struct {
+	quote       float64   // Frais en USDC, ceux prélevés en BTC comptés au prix d'exécution
+	base        float64   // Frais prélevés en BTC
+	asset       string    // Actif des frais payés hors BTC et USDC (BNB), vide sinon
+	assetAmount float64   // Frais payés dans cet actif, non comptés dans quote
+	at          time.Time // Date de la dernière exécution (myTrades), zéro si inconnue
+	found       bool      // Au moins une exécution précise l'actif de ses frais
+}
+
+// parseFills additionne les frais des exécutions d'un ordre (fills de la réponse de création ou
+// myTrades). Les frais payés en BNB (option « payer les frais en BNB ») ne réduisent ni la
+// quantité ni le montant USDC: ils sont rendus à part pour être convertis.
+func parseFills(fills []byte) fillFees {
+	var fees fillFees
+	_, _ = jsonparser.ArrayEach(fills, func(fill []byte, dataType jsonparser.ValueType, offset int, _ error) {
+		asset, err := jsonparser.GetString(fill, "commissionAsset")
+		if err != nil {
+			return
+		}
+		fees.found = true
+		commission := common.OrderFloat(fill, "commission")
+		switch asset {
+		case "USDC":
+			fees.quote += commission
+		case "BTC":
+			fees.base += commission
+			fees.quote += commission * common.OrderFloat(fill, "price")
+		default:
+			fees.asset = asset
+			fees.assetAmount += commission
+		}
+		if fillTime := common.OrderFloat(fill, "time"); fillTime > 0 {
+			fees.at = time.UnixMilli(int64(fillTime))
+		}
+	})
+	return fees
+}
+
+// parseOrderStatus interprète une réponse de /api/v3/order
+func parseOrderStatus(order []byte) (common.OrderStatus, error) {
+	status, err := jsonparser.GetString(order, "status")
+	if err != nil {
+		return common.OrderStatus{}, fmt.Errorf("statut absent de l'ordre: %s", order)
+	}
+
+	// Quantité exécutée telle quelle: la quantité revendue est arrondie au stepSize à la vente,
+	// après déduction des frais prélevés en BTC
+	executedQty := common.OrderFloat(order, "executedQty")
+	quoteAmount := common.OrderFloat(order, "cummulativeQuoteQty")
+	result := common.OrderStatus{
+		ExecutedQty:  executedQty,
+		AvgFillPrice: common.AveragePrice(quoteAmount, executedQty),
+	}
+	// Montant réellement dépensé ou reçu, exécutions à meilleur prix comprises
+	if executedQty > 0 && quoteAmount > 0 {
+		result.QuoteAmount = quoteAmount
+	}
+	if orderId, err := jsonparser.GetInt(order, "orderId"); err == nil {
+		result.ID = strconv.FormatInt(orderId, 10)
+	}
+	// Réponse de création (newOrderRespType FULL): les exécutions détaillent les frais
+	if fills, _, _, err := jsonparser.Get(order, "fills"); err == nil {
+		fees := parseFills(fills)
+		result.Fee, result.BaseFee = fees.quote, fees.base
+		result.FeeAsset, result.FeeAssetAmount = fees.asset, fees.assetAmount
+	}
+	if updateTime := common.OrderFloat(order, "updateTime"); updateTime > 0 {
+		result.UpdatedAt = time.UnixMilli(int64(updateTime))
+	} else if transactTime := common.OrderFloat(order, "transactTime"); transactTime > 0 {
+		result.UpdatedAt = time.UnixMilli(int64(transactTime))
+	}
+
+	switch status {
+	case "FILLED":
+		result.State = common.OrderFilled
+	case "PARTIALLY_FILLED":
+		result.State = common.OrderPartiallyFilled
+	case "CANCELED", "PENDING_CANCEL", "EXPIRED", "EXPIRED_IN_MATCH":
+		result.State = common.OrderCancelled
+	case "REJECTED":
+		result.State = common.OrderRejected
+	default:
+		result.State = common.OrderOpen
+	}
+	return result, nil
 }
 
 func (c *Client) CancelOrder(orderID string) ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 
-	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", orderID, timestamp)
+	queryString := fmt.Sprintf("symbol=BTCUSDC&%s&timestamp=%s", orderQuery(orderID), timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
@@ -351,6 +793,21 @@ func (c *Client) CancelOrder(orderID string) ([]byte, error) {
 	return body, nil
 }
 
+// CancelOrderIdempotent annule un ordre. Les erreurs -2011 (Unknown order sent) et
+// -2013 (Order does not exist) signalent un ordre déjà exécuté ou annulé.
+func (c *Client) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	_, err := c.CancelOrder(orderID)
+	if err == nil {
+		return common.Cancelled, nil
+	}
+
+	code, msg := common.APIError(err)
+	if code == "-2013" || (code == "-2011" && strings.Contains(msg, "Unknown order")) {
+		return common.AlreadyGone, nil
+	}
+	return common.CancelFailed, err
+}
+
 func (c *Client) GetExchangeInfo() ([]byte, error) {
 	body, err := c.sendRequest("GET", "/api/v3/exchangeInfo", "")
 	if err != nil {
@@ -359,8 +816,25 @@ func (c *Client) GetExchangeInfo() ([]byte, error) {
 	return body, nil
 }
 
+// PairStatus lit l'état de négociation de BTCUSDC. Un symbole inconnu (-1121) est une paire
+// retirée de la cote.
+func (c *Client) PairStatus() (common.PairStatus, error) {
+	body, err := c.sendRequest("GET", "/api/v3/exchangeInfo", "symbol=BTCUSDC")
+	if err != nil {
+		if strings.Contains(err.Error(), "-1121") {
+			return common.PairStatus{Status: "DELISTED"}, nil
+		}
+		return common.PairStatus{}, fmt.Errorf("erreur lors de la lecture de l'état de BTCUSDC: %w", err)
+	}
+	status, err := jsonparser.GetString(body, "symbols", "[0]", "status")
+	if err != nil {
+		return common.PairStatus{}, fmt.Errorf("état de BTCUSDC absent de la réponse: %s", body)
+	}
+	return common.PairStatus{Status: status, Trading: status == "TRADING"}, nil
+}
+
 func (c *Client) GetAccountInfo() ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 	queryString := fmt.Sprintf("timestamp=%s", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -372,6 +846,62 @@ func (c *Client) GetAccountInfo() ([]byte, error) {
 	return body, nil
 }
 
+// GetAccountFeeRates lit les taux de frais du compte sur BTCUSDC (niveau VIP, remises BNB non comprises)
+func (c *Client) GetAccountFeeRates() (common.FeeRates, error) {
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+
+	body, err := c.sendRequest("GET", "/sapi/v1/asset/tradeFee", signedQuery)
+	if err != nil {
+		return common.FeeRates{}, fmt.Errorf("erreur lors de la récupération des frais du compte: %w", err)
+	}
+
+	var fees []struct {
+		Symbol          string `json:"symbol"`
+		MakerCommission string `json:"makerCommission"`
+		TakerCommission string `json:"takerCommission"`
+	}
+	if err := json.Unmarshal(body, &fees); err != nil {
+		return common.FeeRates{}, fmt.Errorf("réponse de frais invalide: %w", err)
+	}
+	for _, fee := range fees {
+		if fee.Symbol != "BTCUSDC" {
+			continue
+		}
+		maker, err1 := strconv.ParseFloat(fee.MakerCommission, 64)
+		taker, err2 := strconv.ParseFloat(fee.TakerCommission, 64)
+		if err1 != nil || err2 != nil {
+			return common.FeeRates{}, fmt.Errorf("taux de frais invalides: %s / %s", fee.MakerCommission, fee.TakerCommission)
+		}
+		return common.FeeRates{Maker: maker, Taker: taker}, nil
+	}
+	return common.FeeRates{}, fmt.Errorf("frais BTCUSDC absents de la réponse")
+}
+
+// FeeDiscountAsset indique si le paiement des frais en BNB (remise de 25%) est activé sur le
+// compte: les frais ne sont alors prélevés ni sur le BTC acheté ni sur les USDC reçus
+func (c *Client) FeeDiscountAsset() (string, error) {
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("timestamp=%s", timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+
+	body, err := c.sendRequest("GET", "/sapi/v1/bnbBurn", signedQuery)
+	if err != nil {
+		return "", fmt.Errorf("erreur lors de la lecture du paiement des frais en BNB: %w", err)
+	}
+	enabled, err := jsonparser.GetBoolean(body, "spotBNBBurn")
+	if err != nil {
+		return "", fmt.Errorf("réponse bnbBurn invalide: %s", body)
+	}
+	if enabled {
+		return "BNB", nil
+	}
+	return "", nil
+}
+
 func (c *Client) ShowSymbolRules(symbol string) {
 	rules, err := c.GetSymbolRules(symbol)
 	if err != nil {
@@ -440,7 +970,7 @@ func (c *Client) GetDetailedBalances() (map[string]common.DetailedBalance, error
 
 // Méthode d'origine pour récupérer les soldes (renommée)
 func (c *Client) getOriginalDetailedBalances() (map[string]DetailedBalance, error) {
-	timestamp := time.Now().UnixMilli()
+	timestamp := c.clock.Now().UnixMilli()
 	queryString := fmt.Sprintf("timestamp=%d", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -481,20 +1011,27 @@ func (c *Client) getOriginalDetailedBalances() (map[string]DetailedBalance, erro
 	return balances, nil
 }
 
-func (c *Client) CreateMakerOrder(side string, price float64, quantity string) ([]byte, error) {
+func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
+	// L'écart ne doit jamais être inférieur au pas de prix, sinon il disparaît à l'arrondi
+	tickSize := 0.01
+	if rules, err := c.GetSymbolRules("BTCUSDC"); err == nil && rules.TickSize > 0 {
+		tickSize = rules.TickSize
+	}
+	offset := common.MakerPriceOffset(price, c.MakerBufferPercent, common.DefaultMakerOrderBufferPercent, tickSize)
+
 	// Ajuster le prix pour s'assurer d'être maker
 	adjustedPrice := price
 	if side == "BUY" {
 		// Pour un achat, placer l'ordre légèrement en dessous du marché
-		adjustedPrice = price * 0.998 // 0.2% en dessous
+		adjustedPrice = price - offset
 	} else {
 		// Pour une vente, placer l'ordre légèrement au-dessus du marché
-		adjustedPrice = price * 1.002 // 0.2% au-dessus
+		adjustedPrice = price + offset
 	}
 
 	adjustedPriceStr := strconv.FormatFloat(adjustedPrice, 'f', 2, 64)
 
-	return c.CreateOrder(side, adjustedPriceStr, quantity)
+	return c.CreateOrder(side, adjustedPriceStr, quantity, common.OrderOptions{PostOnly: true})
 }
 
 // GetOrderFees récupère les frais appliqués à un ordre spécifique
@@ -503,8 +1040,8 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 	cleanOrderId := orderId
 
 	// Récupérer les détails de l'ordre
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", cleanOrderId, timestamp)
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&%s&timestamp=%s", orderQuery(cleanOrderId), timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
@@ -514,50 +1051,35 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 	}
 
 	// Vérifier si l'ordre a des informations de frais
+	// Frais signés: une remise maker est négative
 	commission, err := jsonparser.GetFloat(orderDetails, "commission")
-	if err == nil && commission > 0 {
+	if err == nil && commission != 0 {
 		return commission, nil
 	}
 
 	// Si les frais directs ne sont pas disponibles, utilisons l'historique des trades
-	// pour cet ordre pour obtenir les frais cumulés
-	queryString = fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", cleanOrderId, timestamp)
-	signature = c.signRequest(queryString)
-	signedQuery = fmt.Sprintf("%s&signature=%s", queryString, signature)
-
-	tradesData, err := c.sendRequest("GET", "/api/v3/myTrades", signedQuery)
+	// pour cet ordre pour obtenir les frais cumulés, convertis en USDC
+	numericId, err := jsonparser.GetInt(orderDetails, "orderId")
+	if err != nil {
+		return c.estimateOrderFees(orderDetails)
+	}
+	tradesData, err := c.orderTrades(numericId)
 	if err != nil {
 		// Si nous ne pouvons pas obtenir les trades, estimer les frais
 		return c.estimateOrderFees(orderDetails)
 	}
 
-	// Calculer les frais totaux depuis tous les trades liés à cet ordre
-	var totalFees float64
-	_, _ = jsonparser.ArrayEach(tradesData, func(trade []byte, dataType jsonparser.ValueType, offset int, _ error) {
-		// Vérifier si ce trade appartient à notre ordre
-		tradeOrderId, err := jsonparser.GetString(trade, "orderId")
-		if err != nil || tradeOrderId != cleanOrderId {
-			return
-		}
-
-		// Extraire les frais
-		fees, err := jsonparser.GetFloat(trade, "commission")
-		if err == nil {
-			totalFees += fees
-			return
-		}
-
-		// Si on n'a pas pu extraire directement, essayer la version chaîne
-		feesStr, err := jsonparser.GetString(trade, "commission")
-		if err == nil {
-			if feeValue, err := strconv.ParseFloat(feesStr, 64); err == nil {
-				totalFees += feeValue
-			}
+	fees := parseFills(tradesData)
+	if fees.assetAmount != 0 {
+		// Frais payés en BNB: sans leur prix, les frais réels sont inconnus et doivent être estimés
+		price, err := c.assetPriceAt(fees.asset, fees.at)
+		if err != nil {
+			return 0, fmt.Errorf("frais de %.8f %s non convertis en USDC: %w", fees.assetAmount, fees.asset, err)
 		}
-	})
-
-	if totalFees > 0 {
-		return totalFees, nil
+		return fees.quote + fees.assetAmount*price, nil
+	}
+	if fees.quote != 0 {
+		return fees.quote, nil
 	}
 
 	// Si nous n'avons pas pu obtenir les frais réels, faire une estimation
@@ -566,8 +1088,8 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 
 // estimateOrderFees estime les frais d'un ordre à partir des données de l'ordre
 func (c *Client) estimateOrderFees(orderDetails []byte) (float64, error) {
-	// Taux de frais standard de Binance pour les makers (0.1%)
-	const feeRate = 0.001
+	// Taux maker configuré (0.1% au niveau de base)
+	feeRate := c.FeeRates.Maker
 
 	// Récupérer le prix et la quantité exécutée
 	var price, quantity float64
@@ -595,25 +1117,128 @@ func (c *Client) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyO
 	buyFees, err := c.GetOrderFees(buyOrderId)
 
 	// Si nous n'avons pas pu récupérer les frais, estimer avec le taux standard
-	if err != nil || buyFees <= 0 {
-		const feeRate = 0.001 // 0.1% pour Binance
-		buyFees = buyPrice * quantity * feeRate
+	if err != nil || buyFees == 0 {
+		buyFees = buyPrice * quantity * c.FeeRates.Maker
 	}
 
 	// Calculer les frais de vente estimés (même taux)
-	sellFees := buyPrice * quantity * 0.001
+	sellFees := buyPrice * quantity * c.FeeRates.Maker
+
+	// Prix minimal pour couvrir les frais, avec une marge de sécurité de 5% hors remise maker
+	return common.FeeAdjustedSellPrice(buyPrice, quantity, buyFees, sellFees, 0.05), nil
+}
+
+// GetOpenOrders récupère les ordres BTCUSDC encore ouverts
+func (c *Client) GetOpenOrders() ([]common.OpenOrder, error) {
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+
+	body, err := c.sendRequest("GET", "/api/v3/openOrders", signedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des ordres ouverts: %w", err)
+	}
+
+	var orders []common.OpenOrder
+	_, _ = jsonparser.ArrayEach(body, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+		orderId, _ := jsonparser.GetInt(value, "orderId")
+		side, _ := jsonparser.GetString(value, "side")
+		priceStr, _ := jsonparser.GetString(value, "price")
+		origQtyStr, _ := jsonparser.GetString(value, "origQty")
+		executedQtyStr, _ := jsonparser.GetString(value, "executedQty")
+		clientOrderId, _ := jsonparser.GetString(value, "clientOrderId")
+		createdMs, _ := jsonparser.GetInt(value, "time")
+
+		price, _ := strconv.ParseFloat(priceStr, 64)
+		origQty, _ := strconv.ParseFloat(origQtyStr, 64)
+		executedQty, _ := strconv.ParseFloat(executedQtyStr, 64)
+
+		var createdAt time.Time
+		if createdMs > 0 {
+			createdAt = time.UnixMilli(createdMs)
+		}
+
+		orders = append(orders, common.OpenOrder{
+			ID:            strconv.FormatInt(orderId, 10),
+			Side:          side,
+			Price:         price,
+			Quantity:      origQty - executedQty,
+			ClientOrderID: clientOrderId,
+			CreatedAt:     createdAt,
+		})
+	})
+
+	return orders, nil
+}
 
-	// Total des frais à couvrir
-	totalFeesToCover := buyFees + sellFees
+// GetTradeHistory récupère les exécutions BTCUSDC depuis la date indiquée.
+// Binance limite la fenêtre startTime/endTime à 24h : l'historique est donc
+// parcouru par identifiant de trade (fromId), puis filtré sur la date.
+func (c *Client) GetTradeHistory(since time.Time) ([]common.Trade, error) {
+	const pageSize = 1000
 
-	// Ajouter une marge de sécurité de 5%
-	totalFeesToCover *= 1.05
+	var trades []common.Trade
+	fromId := int64(0)
+	sinceMs := since.UnixMilli()
 
-	// Calculer l'ajustement de prix par unité
-	feeAdjustmentPerUnit := totalFeesToCover / quantity
+	for {
+		timestamp := c.clock.Timestamp()
+		queryString := fmt.Sprintf("symbol=BTCUSDC&fromId=%d&limit=%d&timestamp=%s", fromId, pageSize, timestamp)
+		signature := c.signRequest(queryString)
+		signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
-	// Prix minimal pour couvrir les frais
-	minProfitablePrice := buyPrice + feeAdjustmentPerUnit
+		body, err := c.sendRequest("GET", "/api/v3/myTrades", signedQuery)
+		if err != nil {
+			return nil, fmt.Errorf("erreur lors de la récupération de l'historique des trades: %w", err)
+		}
+
+		count := 0
+		_, _ = jsonparser.ArrayEach(body, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+			count++
+
+			id, _ := jsonparser.GetInt(value, "id")
+			if id >= fromId {
+				fromId = id + 1
+			}
+
+			tradeTime, _ := jsonparser.GetInt(value, "time")
+			if tradeTime < sinceMs {
+				return
+			}
+
+			orderId, _ := jsonparser.GetInt(value, "orderId")
+			priceStr, _ := jsonparser.GetString(value, "price")
+			qtyStr, _ := jsonparser.GetString(value, "qty")
+			commissionStr, _ := jsonparser.GetString(value, "commission")
+			commissionAsset, _ := jsonparser.GetString(value, "commissionAsset")
+			isBuyer, _ := jsonparser.GetBoolean(value, "isBuyer")
+
+			price, _ := strconv.ParseFloat(priceStr, 64)
+			qty, _ := strconv.ParseFloat(qtyStr, 64)
+			commission, _ := strconv.ParseFloat(commissionStr, 64)
+
+			side := "SELL"
+			if isBuyer {
+				side = "BUY"
+			}
+
+			trades = append(trades, common.Trade{
+				ID:       strconv.FormatInt(id, 10),
+				OrderID:  strconv.FormatInt(orderId, 10),
+				Side:     side,
+				Price:    price,
+				Quantity: qty,
+				Fee:      commission,
+				FeeAsset: commissionAsset,
+				Time:     time.UnixMilli(tradeTime),
+			})
+		})
+
+		if count < pageSize {
+			break
+		}
+	}
 
-	return minProfitablePrice, nil
+	return trades, nil
 }
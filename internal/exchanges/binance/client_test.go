@@ -0,0 +1,402 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/exchanges/common"
+)
+
+func TestCancelOrderIdempotent(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    common.CancelResult
+		wantErr bool
+	}{
+		{
+			name:   "ordre ouvert annulé",
+			status: http.StatusOK,
+			body:   `{"symbol":"BTCUSDC","orderId":28457112,"status":"CANCELED","executedQty":"0.00000000"}`,
+			want:   common.Cancelled,
+		},
+		{
+			name:   "ordre déjà exécuté ou annulé",
+			status: http.StatusBadRequest,
+			body:   `{"code":-2011,"msg":"Unknown order sent."}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			name:   "ordre inexistant",
+			status: http.StatusBadRequest,
+			body:   `{"code":-2013,"msg":"Order does not exist."}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			// -2011 couvre aussi les refus d'annulation: l'ordre reste ouvert
+			name:    "annulation refusée",
+			status:  http.StatusBadRequest,
+			body:    `{"code":-2011,"msg":"Order was not canceled due to cancel restrictions."}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+		{
+			name:    "horodatage hors fenêtre",
+			status:  http.StatusBadRequest,
+			body:    `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/api/v3/order" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			got, err := client.CancelOrderIdempotent("28457112")
+			if got != tt.want {
+				t.Errorf("CancelOrderIdempotent() = %v, attendu %v (erreur: %v)", got, tt.want, err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/openOrders" || r.URL.Query().Get("symbol") != "BTCUSDC" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"symbol":"BTCUSDC","orderId":28457112,"price":"60000.00","origQty":"0.00150000","executedQty":"0.00050000","status":"PARTIALLY_FILLED","side":"BUY"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	orders, err := client.GetOpenOrders()
+	if err != nil {
+		t.Fatalf("GetOpenOrders: %v", err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("%d ordres ouverts, attendu 1", len(orders))
+	}
+	got := orders[0]
+	if got.ID != "28457112" || got.Side != "BUY" || got.Price != 60000 || got.Quantity < 0.00099999 || got.Quantity > 0.00100001 {
+		t.Errorf("ordre ouvert inattendu: %+v", got)
+	}
+}
+
+func TestGetOrderByIdClientOrderId(t *testing.T) {
+	tests := []struct {
+		id    string
+		param string
+	}{
+		{"28457112", "orderId"},
+		{"x-ABCDEF1234567890", "origClientOrderId"},
+		{"web_4b8d0c6e2f1a4d3c9e7b5a1f0c2d4e6f", "origClientOrderId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// L'ID client doit être transmis intact dans le bon paramètre
+				if r.URL.Path != "/api/v3/order" || r.URL.Query().Get(tt.param) != tt.id {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+					return
+				}
+				w.Write([]byte(`{"symbol":"BTCUSDC","orderId":28457112,"status":"FILLED"}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			if _, err := client.GetOrderById(tt.id); err != nil {
+				t.Errorf("GetOrderById(%q): %v", tt.id, err)
+			}
+		})
+	}
+}
+
+func TestClockSkewCorrection(t *testing.T) {
+	// Horloge du serveur en avance de 10 minutes sur l'horloge locale
+	skew := 10 * time.Minute
+	timeRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverNow := time.Now().Add(skew)
+		switch r.URL.Path {
+		case "/api/v3/time":
+			timeRequests++
+			fmt.Fprintf(w, `{"serverTime":%d}`, serverNow.UnixMilli())
+		case "/api/v3/account":
+			timestamp, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+			if d := serverNow.Sub(time.UnixMilli(timestamp)); d > 5*time.Second || d < -5*time.Second {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`))
+				return
+			}
+			// La requête renvoyée doit être signée à nouveau avec le nouvel horodatage
+			query := r.URL.RawQuery
+			index := strings.LastIndex(query, "&signature=")
+			h := hmac.New(sha256.New, []byte("secret"))
+			h.Write([]byte(query[:index]))
+			if query[index+len("&signature="):] != hex.EncodeToString(h.Sum(nil)) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+				return
+			}
+			w.Write([]byte(`{"balances":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	if _, err := client.GetAccountInfo(); err != nil {
+		t.Fatalf("GetAccountInfo avec horloge décalée: %v", err)
+	}
+	if offset := client.ClockOffset(); offset < skew-time.Second || offset > skew+time.Second {
+		t.Errorf("ClockOffset() = %v, attendu environ %v", offset, skew)
+	}
+
+	// Le décalage mesuré est conservé pour la session
+	if _, err := client.GetAccountInfo(); err != nil {
+		t.Fatalf("deuxième GetAccountInfo: %v", err)
+	}
+	if timeRequests != 1 {
+		t.Errorf("heure du serveur lue %d fois, attendu 1", timeRequests)
+	}
+}
+
+func TestRecvWindowSigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// recvWindow fait partie de la requête signée
+		query := r.URL.RawQuery
+		index := strings.LastIndex(query, "&signature=")
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte(query[:index]))
+		if r.URL.Query().Get("recvWindow") != "15000" || query[index+len("&signature="):] != hex.EncodeToString(h.Sum(nil)) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"code":-1022,"msg":"unexpected query %s"}`, query)
+			return
+		}
+		w.Write([]byte(`{"balances":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+	client.SetRecvWindow(15000)
+	client.SetHTTPTimeout(20 * time.Second)
+
+	if _, err := client.GetAccountInfo(); err != nil {
+		t.Fatalf("GetAccountInfo avec recvWindow: %v", err)
+	}
+	if timeout := client.httpTimeout(); timeout != 20*time.Second {
+		t.Errorf("délai des requêtes %v, attendu 20s", timeout)
+	}
+}
+
+func TestGetOrderBook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/ticker/bookTicker" || r.URL.Query().Get("symbol") != "BTCUSDC" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDC","bidPrice":"60000.00","bidQty":"0.5","askPrice":"60012.50","askQty":"0.2"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	book, err := client.GetOrderBook()
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if book.Bid != 60000 || book.Ask != 60012.5 || book.Spread() != 12.5 {
+		t.Errorf("haut du carnet inattendu: %+v", book)
+	}
+}
+
+func TestParseOrderStatusQuoteAmount(t *testing.T) {
+	// Achat limite à 60000 exécuté en trois fois à de meilleurs prix
+	order := []byte(`{"symbol":"BTCUSDC","orderId":28457112,"price":"60000.00","origQty":"0.00300000",
+		"executedQty":"0.00300000","cummulativeQuoteQty":"179.86500000","status":"FILLED","updateTime":1700000000000,
+		"fills":[{"price":"59950.00","qty":"0.00100000"},{"price":"59955.00","qty":"0.00100000"},{"price":"59960.00","qty":"0.00100000"}]}`)
+
+	status, err := parseOrderStatus(order)
+	if err != nil {
+		t.Fatalf("parseOrderStatus: %v", err)
+	}
+	if status.QuoteAmount != 179.865 {
+		t.Errorf("montant exécuté %.5f, attendu 179.865 et non %.2f (prix limite * quantité)", status.QuoteAmount, 60000*0.003)
+	}
+	if status.AvgFillPrice != 59955 {
+		t.Errorf("prix moyen %.2f, attendu 59955", status.AvgFillPrice)
+	}
+
+	// Ordre non exécuté: pas de montant
+	status, err = parseOrderStatus([]byte(`{"orderId":28457113,"executedQty":"0","cummulativeQuoteQty":"0","status":"NEW"}`))
+	if err != nil || status.QuoteAmount != 0 {
+		t.Errorf("ordre ouvert: montant %.2f (erreur %v), attendu 0", status.QuoteAmount, err)
+	}
+}
+
+func TestParseOrderStatusBaseFee(t *testing.T) {
+	// Réponse de création d'un achat exécuté immédiatement, frais prélevés en BTC (BNB désactivé)
+	order := []byte(`{"symbol":"BTCUSDC","orderId":28457114,"price":"60000.00","origQty":"0.00200000",
+		"executedQty":"0.00200000","cummulativeQuoteQty":"119.98000000","status":"FILLED","transactTime":1700000000000,
+		"fills":[{"price":"59980.00","qty":"0.00150000","commission":"0.00000150","commissionAsset":"BTC"},
+		{"price":"60000.00","qty":"0.00050000","commission":"0.00000050","commissionAsset":"BTC"}]}`)
+
+	status, err := parseOrderStatus(order)
+	if err != nil {
+		t.Fatalf("parseOrderStatus: %v", err)
+	}
+	if status.ExecutedQty != 0.002 {
+		t.Errorf("quantité exécutée %.8f, attendu 0.002", status.ExecutedQty)
+	}
+	if math.Abs(status.BaseFee-0.000002) > 1e-12 {
+		t.Errorf("frais en BTC %.8f, attendu 0.000002", status.BaseFee)
+	}
+	if want := 0.0000015*59980 + 0.0000005*60000; math.Abs(status.Fee-want) > 1e-9 {
+		t.Errorf("frais en USDC %.6f, attendu %.6f (frais en BTC au prix d'exécution)", status.Fee, want)
+	}
+
+	// Frais payés en BNB: rien n'est prélevé sur la quantité, le montant en BNB est rendu à part
+	order = []byte(`{"orderId":28457115,"executedQty":"0.00100000","cummulativeQuoteQty":"60.00000000","status":"FILLED",
+		"fills":[{"price":"60000.00","qty":"0.00100000","commission":"0.00007500","commissionAsset":"BNB"}]}`)
+	status, err = parseOrderStatus(order)
+	if err != nil || status.BaseFee != 0 || status.Fee != 0 {
+		t.Errorf("frais en BNB: %.8f BTC, %.6f USDC (erreur %v), attendu 0", status.BaseFee, status.Fee, err)
+	}
+	if status.FeeAsset != "BNB" || math.Abs(status.FeeAssetAmount-0.000075) > 1e-12 {
+		t.Errorf("frais en BNB: %.8f %s, attendu 0.000075 BNB", status.FeeAssetAmount, status.FeeAsset)
+	}
+}
+
+func TestGetOrderFeesBNB(t *testing.T) {
+	// Exécution à 10:15:42, frais de 0.0001 BNB convertis à la clôture de la bougie de 10:15
+	fillTime := time.Date(2025, 3, 1, 10, 15, 42, 0, time.UTC)
+	minute := fillTime.Truncate(time.Minute).UnixMilli()
+	klinesAvailable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/order":
+			w.Write([]byte(`{"symbol":"BTCUSDC","orderId":42,"executedQty":"0.00100000","cummulativeQuoteQty":"60.00000000","status":"FILLED"}`))
+		case "/api/v3/myTrades":
+			fmt.Fprintf(w, `[{"symbol":"BTCUSDC","orderId":42,"price":"60000.00","qty":"0.001","commission":"0.00010000","commissionAsset":"BNB","time":%d}]`, fillTime.UnixMilli())
+		case "/api/v3/klines":
+			if !klinesAvailable || r.URL.Query().Get("symbol") != "BNBUSDC" || r.URL.Query().Get("startTime") != strconv.FormatInt(minute, 10) {
+				w.Write([]byte(`[]`))
+				return
+			}
+			fmt.Fprintf(w, `[[%d,"598.00","601.00","597.50","600.00","12.5",%d]]`, minute, minute+59999)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	fees, err := client.GetOrderFees("42")
+	if err != nil {
+		t.Fatalf("GetOrderFees: %v", err)
+	}
+	if math.Abs(fees-0.06) > 1e-9 {
+		t.Errorf("frais %.6f USDC, attendu 0.06 (0.0001 BNB à 600 USDC)", fees)
+	}
+
+	// Sans prix de BNB, les frais sont inconnus: l'appelant les estime
+	klinesAvailable = false
+	if _, err := client.GetOrderFees("42"); err == nil || !strings.Contains(err.Error(), "BNB") {
+		t.Errorf("prix de BNB absent: erreur %v, attendu une erreur", err)
+	}
+}
+
+func TestDecodeOrderCreated(t *testing.T) {
+	cases := []struct {
+		name, body, orderId, err string
+	}{
+		{"ordre limite", `{"symbol":"BTCUSDC","orderId":28457113,"orderListId":-1,"clientOrderId":"cyc-7-buy","transactTime":1700000000000,"price":"64000.00","origQty":"0.00156700","executedQty":"0.00000000","status":"NEW","type":"LIMIT","side":"BUY"}`, "28457113", ""},
+		{"fonds insuffisants", `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, "", "-2010 - Account has insufficient balance"},
+		{"réponse sans ID", `{"symbol":"BTCUSDC"}`, "", "ID d'ordre absent"},
+	}
+	for _, c := range cases {
+		result, err := decodeOrderCreated([]byte(c.body))
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: erreur %v, attendu %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil || result.OrderID != c.orderId || string(result.Raw) != c.body {
+			t.Errorf("%s: ID %q (erreur %v), attendu %q", c.name, result.OrderID, err, c.orderId)
+		}
+	}
+}
+
+func TestPairStatus(t *testing.T) {
+	cases := []struct {
+		name, body string
+		code       int
+		want       common.PairStatus
+	}{
+		{"paire ouverte", `{"symbols":[{"symbol":"BTCUSDC","status":"TRADING"}]}`, http.StatusOK, common.PairStatus{Status: "TRADING", Trading: true}},
+		{"paire suspendue", `{"symbols":[{"symbol":"BTCUSDC","status":"HALT"}]}`, http.StatusOK, common.PairStatus{Status: "HALT"}},
+		{"paire retirée", `{"code":-1121,"msg":"Invalid symbol."}`, http.StatusBadRequest, common.PairStatus{Status: "DELISTED"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v3/exchangeInfo" || r.URL.Query().Get("symbol") != "BTCUSDC" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(c.code)
+				w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+			status, err := client.PairStatus()
+			if err != nil || status != c.want {
+				t.Errorf("PairStatus = %+v (erreur %v), attendu %+v", status, err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package binance
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/testutil"
+)
+
+// verifySignature contrôle la clé API et la signature HMAC-SHA256 des requêtes privées
+func verifySignature(r *http.Request, body []byte) error {
+	if r.URL.Path == "/api/v3/ticker/price" || r.URL.Path == "/api/v3/exchangeInfo" {
+		return nil
+	}
+	if r.Header.Get("X-MBX-APIKEY") != "key" {
+		return fmt.Errorf("en-tête X-MBX-APIKEY = %q", r.Header.Get("X-MBX-APIKEY"))
+	}
+
+	// La signature porte sur la query string telle qu'envoyée, sans le paramètre signature
+	query, signature, ok := strings.Cut(r.URL.RawQuery, "&signature=")
+	if !ok {
+		return fmt.Errorf("signature absente de %q", r.URL.RawQuery)
+	}
+	if !strings.Contains(query, "timestamp=") {
+		return fmt.Errorf("timestamp absent de %q", query)
+	}
+	if want := NewClient("key", "secret").signRequest(query); signature != want {
+		return fmt.Errorf("signature %s, attendu %s", signature, want)
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	server := testutil.NewFakeServer(t, verifySignature,
+		testutil.Route{Method: "GET", Path: "/api/v3/ticker/price", File: "ticker_price.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/account", File: "account.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/order", File: "order_filled.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/myTrades", File: "my_trades.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/exchangeInfo", File: "exchange_info.json"},
+		testutil.Route{Method: "POST", Path: "/api/v3/order", File: "order_new.json"},
+		testutil.Route{Method: "POST", Path: "/api/v3/orderList/oco", File: "oco_new.json"},
+		testutil.Route{Method: "GET", Path: "/sapi/v1/asset/tradeFee", File: "trade_fee.json"},
+	)
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	if price := client.GetLastPriceBTC(); price != 64250.12 {
+		t.Errorf("GetLastPriceBTC() = %v, attendu 64250.12", price)
+	}
+
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		t.Fatalf("GetDetailedBalances: %v", err)
+	}
+	if got := balances["USDC"]; got.Free != 1520.55 || got.Locked != 96.37 {
+		t.Errorf("solde USDC inattendu: %+v", got)
+	}
+	if got := balances["BTC"]; got.Free != 0.0125 || got.Locked != 0.0015 {
+		t.Errorf("solde BTC inattendu: %+v", got)
+	}
+
+	status, err := client.GetOrderStatus("28457112")
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if !status.Filled() || status.ID != "28457112" || status.ExecutedQty != 0.0015 || status.AvgFillPrice != 64000 ||
+		!status.UpdatedAt.Equal(time.UnixMilli(1718035260000)) {
+		t.Errorf("état de l'ordre enregistré inattendu: %+v", status)
+	}
+	// Frais des exécutions (myTrades) prélevés en BTC, comptés aussi en USDC au prix d'exécution
+	if math.Abs(status.BaseFee-0.0000015) > 1e-12 || math.Abs(status.Fee-0.096) > 1e-9 {
+		t.Errorf("frais de l'ordre enregistré: %.8f BTC, %.4f USDC, attendu 0.0000015 BTC et 0.096 USDC", status.BaseFee, status.Fee)
+	}
+
+	result, err := client.CreateOrder("BUY", "64000.00", "0.001567", common.OrderOptions{ClientOrderID: "cyc-7-buy"})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if result.OrderID != "28457113" {
+		t.Errorf("ID d'ordre créé %q inattendu (réponse: %s)", result.OrderID, result.Raw)
+	}
+
+	// La quantité est arrondie au stepSize de LOT_SIZE avant l'envoi
+	created := server.RequestsTo("POST", "/api/v3/order")
+	if len(created) != 1 {
+		t.Fatalf("%d créations d'ordre, attendu 1", len(created))
+	}
+	for _, param := range []string{"symbol=BTCUSDC", "side=BUY", "type=LIMIT", "timeInForce=GTC", "quantity=0.00156", "price=64000.00", "newClientOrderId=cyc-7-buy"} {
+		if !strings.Contains(created[0].Query, param) {
+			t.Errorf("paramètre %s absent de %q", param, created[0].Query)
+		}
+	}
+
+	// OCO de vente: les deux jambes sont identifiées par leur type, pas par leur position
+	oco, err := client.CreateOCOOrder(65200, 60900, 60800, "0.001567")
+	if err != nil {
+		t.Fatalf("CreateOCOOrder: %v", err)
+	}
+	if oco.ListID != "1023" || oco.LimitID != "28457121" || oco.StopID != "28457120" {
+		t.Errorf("jambes de l'OCO inattendues: %+v", oco)
+	}
+
+	placed := server.RequestsTo("POST", "/api/v3/orderList/oco")
+	if len(placed) != 1 {
+		t.Fatalf("%d créations d'OCO, attendu 1", len(placed))
+	}
+	for _, param := range []string{"side=SELL", "quantity=0.00156", "aboveType=LIMIT_MAKER", "abovePrice=65200.00",
+		"belowType=STOP_LOSS_LIMIT", "belowStopPrice=60900.00", "belowPrice=60800.00", "belowTimeInForce=GTC"} {
+		if !strings.Contains(placed[0].Query, param) {
+			t.Errorf("paramètre %s absent de %q", param, placed[0].Query)
+		}
+	}
+	rates, err := client.GetAccountFeeRates()
+	if err != nil {
+		t.Fatalf("GetAccountFeeRates: %v", err)
+	}
+	if rates.Maker != 0.00075 || rates.Taker != 0.00095 {
+		t.Errorf("taux de frais inattendus: %+v", rates)
+	}
+}
@@ -0,0 +1,332 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen est renvoyée lorsque le disjoncteur d'un exchange est ouvert
+var ErrCircuitOpen = errors.New("disjoncteur ouvert: appels à l'exchange suspendus pour cette exécution")
+
+// BreakerState est l'état observable d'un disjoncteur
+type BreakerState struct {
+	Open                bool       `json:"open"`
+	Threshold           int        `json:"threshold"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	TotalFailures       int        `json:"totalFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	SkippedCycles       []int32    `json:"skippedCycles,omitempty"`
+	// Ouvert dès la première réponse de maintenance de l'exchange, quel que soit le seuil
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// CircuitBreaker coupe les appels vers un exchange après N pannes consécutives (voir IsOutage).
+// Un seuil de 0 désactive le disjoncteur.
+type CircuitBreaker struct {
+	mu    sync.Mutex
+	state BreakerState
+}
+
+// NewCircuitBreaker crée un disjoncteur fermé avec le seuil indiqué
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return &CircuitBreaker{state: BreakerState{Threshold: threshold}}
+}
+
+// IsOpen indique si les appels vers l'exchange sont suspendus
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.Open
+}
+
+// RecordSuccess remet à zéro le compteur d'échecs consécutifs
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.ConsecutiveFailures = 0
+}
+
+// RecordFailure comptabilise un échec et ouvre le disjoncteur si le seuil est atteint.
+// Une maintenance de l'exchange l'ouvre immédiatement: les appels suivants échoueraient aussi.
+func (b *CircuitBreaker) RecordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state.ConsecutiveFailures++
+	b.state.TotalFailures++
+	if err != nil {
+		b.state.LastError = err.Error()
+	}
+	maintenance := IsMaintenance(err)
+	if maintenance {
+		b.state.Maintenance = true
+	}
+
+	if !b.state.Open && (maintenance || b.state.Threshold > 0 && b.state.ConsecutiveFailures >= b.state.Threshold) {
+		now := time.Now()
+		b.state.Open = true
+		b.state.OpenedAt = &now
+	}
+}
+
+// MarkSkipped mémorise un cycle ignoré parce que le disjoncteur était ouvert
+func (b *CircuitBreaker) MarkSkipped(cycleId int32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.SkippedCycles = append(b.state.SkippedCycles, cycleId)
+}
+
+// State retourne une copie de l'état courant du disjoncteur
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	state.SkippedCycles = append([]int32(nil), b.state.SkippedCycles...)
+	return state
+}
+
+// serverErrorPattern reconnaît une réponse HTTP 5xx dans le message d'une erreur qui ne porte
+// pas de HTTPStatusError ("HTTP status 502 - ..." ou "erreur HTTP 500: ...")
+var serverErrorPattern = regexp.MustCompile(`HTTP (?:status )?5\d\d\b`)
+
+// IsOutage indique si l'erreur signale une panne de l'exchange: erreur de transport, réponse 5xx
+// ou maintenance. Les refus propres à un ordre (ordre inconnu, solde insuffisant, quantité
+// invalide...) prouvent au contraire que l'exchange répond et ne sont pas des pannes.
+func IsOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsMaintenance(err) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.ServerError()
+	}
+	return serverErrorPattern.MatchString(err.Error())
+}
+
+// record enregistre le résultat d'un appel: seule une panne compte comme un échec
+func (b *CircuitBreaker) record(err error) {
+	if IsOutage(err) {
+		b.RecordFailure(err)
+	} else {
+		b.RecordSuccess()
+	}
+}
+
+// GuardedExchange enveloppe un Exchange et court-circuite les appels
+// lorsque son disjoncteur est ouvert
+type GuardedExchange struct {
+	Exchange
+	Breaker *CircuitBreaker
+}
+
+// NewGuardedExchange protège un client d'exchange avec le disjoncteur fourni
+func NewGuardedExchange(exchange Exchange, breaker *CircuitBreaker) *GuardedExchange {
+	return &GuardedExchange{Exchange: exchange, Breaker: breaker}
+}
+
+// guard exécute un appel si le disjoncteur le permet et en comptabilise le résultat
+func (g *GuardedExchange) guard(call func() error) error {
+	if g.Breaker.IsOpen() {
+		return ErrCircuitOpen
+	}
+	err := call()
+	g.Breaker.record(err)
+	return err
+}
+
+// CheckConnection vérifie la connexion si le disjoncteur est fermé
+func (g *GuardedExchange) CheckConnection() error {
+	return g.guard(g.Exchange.CheckConnection)
+}
+
+// GetBalanceUSD retourne 0 lorsque le disjoncteur est ouvert
+func (g *GuardedExchange) GetBalanceUSD() float64 {
+	if g.Breaker.IsOpen() {
+		return 0
+	}
+	return g.Exchange.GetBalanceUSD()
+}
+
+// GetLastPriceBTC retourne 0 lorsque le disjoncteur est ouvert
+func (g *GuardedExchange) GetLastPriceBTC() float64 {
+	if g.Breaker.IsOpen() {
+		return 0
+	}
+
+	price := g.Exchange.GetLastPriceBTC()
+	if price <= 0 {
+		g.Breaker.RecordFailure(fmt.Errorf("prix BTC invalide: %f", price))
+	} else {
+		g.Breaker.RecordSuccess()
+	}
+	return price
+}
+
+// GetDetailedBalances récupère les soldes si le disjoncteur est fermé
+func (g *GuardedExchange) GetDetailedBalances() (map[string]DetailedBalance, error) {
+	var balances map[string]DetailedBalance
+	err := g.guard(func() error {
+		var err error
+		balances, err = g.Exchange.GetDetailedBalances()
+		return err
+	})
+	return balances, err
+}
+
+// CreateOrder crée un ordre si le disjoncteur est fermé. Un ordre post-only refusé parce qu'il
+// serait exécuté immédiatement n'est pas une panne de l'exchange: CreatePostOnlyOrder le replace
+// à un autre prix, le refus n'est pas compté comme un échec.
+func (g *GuardedExchange) CreateOrder(side, price, quantity string, opts ...OrderOptions) (OrderCreateResult, error) {
+	var result OrderCreateResult
+	err := g.guard(func() error {
+		var err error
+		result, err = g.Exchange.CreateOrder(side, price, quantity, opts...)
+		return err
+	})
+	return result, err
+}
+
+// CreateMakerOrder crée un ordre maker si le disjoncteur est fermé
+func (g *GuardedExchange) CreateMakerOrder(side string, price float64, quantity string) (OrderCreateResult, error) {
+	var result OrderCreateResult
+	err := g.guard(func() error {
+		var err error
+		result, err = g.Exchange.CreateMakerOrder(side, price, quantity)
+		return err
+	})
+	return result, err
+}
+
+// GetOrderById récupère un ordre si le disjoncteur est fermé
+func (g *GuardedExchange) GetOrderById(id string) ([]byte, error) {
+	return g.guardBytes(func() ([]byte, error) {
+		return g.Exchange.GetOrderById(id)
+	})
+}
+
+// GetOrderStatus récupère l'état d'un ordre si le disjoncteur est fermé
+func (g *GuardedExchange) GetOrderStatus(id string) (OrderStatus, error) {
+	var status OrderStatus
+	err := g.guard(func() error {
+		var err error
+		status, err = g.Exchange.GetOrderStatus(id)
+		return err
+	})
+	return status, err
+}
+
+// CancelOrder annule un ordre si le disjoncteur est fermé
+func (g *GuardedExchange) CancelOrder(orderID string) ([]byte, error) {
+	return g.guardBytes(func() ([]byte, error) {
+		return g.Exchange.CancelOrder(orderID)
+	})
+}
+
+// CancelOrderIdempotent annule un ordre si le disjoncteur est fermé
+func (g *GuardedExchange) CancelOrderIdempotent(orderID string) (CancelResult, error) {
+	result := CancelFailed
+	err := g.guard(func() error {
+		var err error
+		result, err = g.Exchange.CancelOrderIdempotent(orderID)
+		return err
+	})
+	return result, err
+}
+
+// GetExchangeInfo récupère les informations de l'exchange si le disjoncteur est fermé
+func (g *GuardedExchange) GetExchangeInfo() ([]byte, error) {
+	return g.guardBytes(g.Exchange.GetExchangeInfo)
+}
+
+// GetAccountInfo récupère les informations du compte si le disjoncteur est fermé
+func (g *GuardedExchange) GetAccountInfo() ([]byte, error) {
+	return g.guardBytes(g.Exchange.GetAccountInfo)
+}
+
+// GetOrderFees récupère les frais d'un ordre si le disjoncteur est fermé
+func (g *GuardedExchange) GetOrderFees(orderId string) (float64, error) {
+	var fees float64
+	err := g.guard(func() error {
+		var err error
+		fees, err = g.Exchange.GetOrderFees(orderId)
+		return err
+	})
+	return fees, err
+}
+
+// AdjustSellPriceForFees calcule le prix de vente si le disjoncteur est fermé
+func (g *GuardedExchange) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyOrderId string) (float64, error) {
+	var price float64
+	err := g.guard(func() error {
+		var err error
+		price, err = g.Exchange.AdjustSellPriceForFees(buyPrice, quantity, buyOrderId)
+		return err
+	})
+	return price, err
+}
+
+// GetTradeHistory récupère l'historique des exécutions si le disjoncteur est fermé
+func (g *GuardedExchange) GetTradeHistory(since time.Time) ([]Trade, error) {
+	var trades []Trade
+	err := g.guard(func() error {
+		var err error
+		trades, err = g.Exchange.GetTradeHistory(since)
+		return err
+	})
+	return trades, err
+}
+
+// GetOpenOrders récupère les ordres ouverts si le disjoncteur est fermé
+func (g *GuardedExchange) GetOpenOrders() ([]OpenOrder, error) {
+	var orders []OpenOrder
+	err := g.guard(func() error {
+		var err error
+		orders, err = g.Exchange.GetOpenOrders()
+		return err
+	})
+	return orders, err
+}
+
+// CreateOCOOrder crée un ordre OCO si le disjoncteur est fermé. L'absence de support
+// de l'OCO n'est pas une panne de l'exchange et n'est pas comptée comme un échec.
+func (g *GuardedExchange) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (OCOOrder, error) {
+	var order OCOOrder
+	err := g.guard(func() error {
+		var err error
+		order, err = g.Exchange.CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice, quantity)
+		return err
+	})
+	return order, err
+}
+
+// SupportsOrderExpiry indique si l'exchange enveloppé accepte une date d'expiration d'ordre
+func (g *GuardedExchange) SupportsOrderExpiry() bool {
+	return SupportsOrderExpiry(g.Exchange)
+}
+
+// guardBytes est la variante de guard pour les appels renvoyant une réponse brute
+func (g *GuardedExchange) guardBytes(call func() ([]byte, error)) ([]byte, error) {
+	var body []byte
+	err := g.guard(func() error {
+		var err error
+		body, err = call()
+		return err
+	})
+	return body, err
+}
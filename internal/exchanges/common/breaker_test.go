@@ -0,0 +1,112 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// scriptedExchange renvoie à chaque CreateOrder l'erreur suivante de errs, puis accepte l'ordre
+type scriptedExchange struct {
+	Exchange
+	errs   []error
+	prices []string
+}
+
+func (s *scriptedExchange) CreateOrder(side, price, quantity string, opts ...OrderOptions) (OrderCreateResult, error) {
+	s.prices = append(s.prices, price)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return OrderCreateResult{}, err
+	}
+	return OrderCreateResult{OrderID: "42"}, nil
+}
+
+// Les refus post-only d'un achat replacé plusieurs fois n'ouvrent pas le disjoncteur
+func TestGuardedPostOnlyRetries(t *testing.T) {
+	rejection := errors.New(`HTTP status 400 - {"code":-2010,"msg":"Order would immediately match and take."}`)
+	exchange := &scriptedExchange{errs: []error{rejection, rejection, rejection}}
+	breaker := NewCircuitBreaker(3)
+	guarded := NewGuardedExchange(exchange, breaker)
+
+	result, price, err := CreatePostOnlyOrder(guarded, "BUY", 60000, "0.001", 0.01, 3, OrderOptions{})
+	if err != nil {
+		t.Fatalf("CreatePostOnlyOrder: %v (prix tentés %v)", err, exchange.prices)
+	}
+	if result.OrderID != "42" || len(exchange.prices) != 4 || exchange.prices[3] != "59999.97" {
+		t.Errorf("ordre %q à %.2f, prix tentés %v, attendu 42 à 59999.97 au quatrième essai", result.OrderID, price, exchange.prices)
+	}
+	if state := breaker.State(); state.Open || state.TotalFailures != 0 {
+		t.Errorf("disjoncteur après les refus post-only: %+v", state)
+	}
+
+	// Un autre refus reste un échec de l'exchange
+	exchange.errs = []error{errors.New("HTTP status 503 - service unavailable")}
+	if _, err := guarded.CreateOrder("BUY", "60000.00", "0.001"); err == nil {
+		t.Fatal("l'erreur de l'exchange devrait être retournée")
+	}
+	if state := breaker.State(); state.ConsecutiveFailures != 1 {
+		t.Errorf("échecs consécutifs = %d, attendu 1", state.ConsecutiveFailures)
+	}
+}
+
+// Les refus propres à un ordre n'ouvrent pas le disjoncteur, seules les pannes de l'exchange comptent
+func TestBreakerCountsOnlyOutages(t *testing.T) {
+	exchange := &scriptedExchange{errs: []error{
+		errors.New(`HTTP status 400 - {"code":30005,"msg":"Oversold"}`),
+		errors.New("HTTP status 404 Not Found - ordre 1001 inconnu"),
+		errors.New(`HTTP status 400 - {"code":-2010,"msg":"Account has insufficient balance for requested action."}`),
+		errors.New(`erreur HTTP 400: {"code":"200004","msg":"Balance insufficient!"}`),
+	}}
+	breaker := NewCircuitBreaker(2)
+	guarded := NewGuardedExchange(exchange, breaker)
+
+	for i := 0; i < 4; i++ {
+		if _, err := guarded.CreateOrder("SELL", "61000.00", "0.001"); err == nil {
+			t.Fatalf("essai %d: l'erreur de l'ordre devrait être retournée", i+1)
+		}
+	}
+	if state := breaker.State(); state.Open || state.TotalFailures != 0 {
+		t.Fatalf("disjoncteur après des refus d'ordre: %+v", state)
+	}
+
+	exchange.errs = []error{
+		&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		errors.New("erreur HTTP 502: bad gateway"),
+	}
+	guarded.CreateOrder("SELL", "61000.00", "0.001")
+	guarded.CreateOrder("SELL", "61000.00", "0.001")
+	if state := breaker.State(); !state.Open || state.TotalFailures != 2 {
+		t.Errorf("disjoncteur après deux pannes: %+v", state)
+	}
+}
+
+// Le code d'une HTTPStatusError décide seul de la panne, quel que soit le corps de la réponse;
+// le message reste celui du client
+func TestIsOutageHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"erreur serveur", NewHTTPStatusError("erreur HTTP %d: %s", 502, "bad gateway"), true},
+		{"erreur serveur enveloppée", fmt.Errorf("création de l'ordre: %w", NewHTTPStatusError("error: HTTP status %d - %s", 500, "{}")), true},
+		{"maintenance", fmt.Errorf("%w: %w", ErrMaintenance, NewHTTPStatusError("HTTP %d: %s", 503, "maintenance")), true},
+		{"refus citant une erreur serveur", NewHTTPStatusError("error: HTTP status %d - %s", 400, `{"msg":"upstream HTTP 503"}`), false},
+		{"ordre inconnu", NewHTTPStatusError("erreur HTTP %d: %s", 404, "order not found"), false},
+		{"message sans code typé", errors.New("HTTP status 504 - gateway timeout"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOutage(tt.err); got != tt.want {
+				t.Errorf("IsOutage(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if msg := NewHTTPStatusError("error: HTTP status %d - %s", 400, "{}").Error(); msg != "error: HTTP status 400 - {}" {
+		t.Errorf("message %q, attendu celui du client", msg)
+	}
+}
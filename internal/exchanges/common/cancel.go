@@ -0,0 +1,67 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CancelResult est l'issue d'une annulation d'ordre idempotente (CancelOrderIdempotent)
+type CancelResult int
+
+const (
+	// CancelFailed: l'annulation a échoué, l'ordre est peut-être encore ouvert
+	CancelFailed CancelResult = iota
+	// Cancelled: l'ordre était ouvert et vient d'être annulé
+	Cancelled
+	// AlreadyGone: l'ordre n'est plus ouvert sur l'exchange (déjà exécuté, annulé ou inconnu)
+	AlreadyGone
+)
+
+// String retourne le libellé de l'issue
+func (r CancelResult) String() string {
+	switch r {
+	case Cancelled:
+		return "annulé"
+	case AlreadyGone:
+		return "déjà fermé"
+	default:
+		return "échec"
+	}
+}
+
+// Closed indique si l'ordre n'est plus ouvert sur l'exchange
+func (r CancelResult) Closed() bool {
+	return r == Cancelled || r == AlreadyGone
+}
+
+// APIError extrait le code et le message de la réponse JSON incluse dans une erreur d'API
+// (par exemple "HTTP status 400 - {"code":-2011,"msg":"Unknown order sent."}").
+// Retourne des chaînes vides si l'erreur ne contient pas de réponse JSON.
+func APIError(err error) (code, msg string) {
+	if err == nil {
+		return "", ""
+	}
+
+	text := err.Error()
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return "", ""
+	}
+
+	var body map[string]interface{}
+	if json.NewDecoder(strings.NewReader(text[start:])).Decode(&body) != nil {
+		return "", ""
+	}
+
+	if value, ok := body["code"]; ok && value != nil {
+		code = fmt.Sprint(value)
+	}
+	for _, field := range []string{"msg", "message"} {
+		if value, ok := body[field].(string); ok {
+			msg = value
+			break
+		}
+	}
+	return code, msg
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// ClientOrderID retourne l'identifiant client déterministe d'un ordre du bot, par cycle et
+// par étape ("buy", "sell", "buy-r1"...). Un ordre créé avant un arrêt brutal peut ainsi
+// être retrouvé parmi les ordres ouverts au lieu d'être placé une seconde fois.
+func ClientOrderID(cycleId int32, phase string) string {
+	return fmt.Sprintf("cyc-%d-%s", cycleId, phase)
+}
+
+// ClientOrderIDRequested retourne l'identifiant client demandé dans les options de CreateOrder ("" sinon)
+func ClientOrderIDRequested(opts []OrderOptions) string {
+	if len(opts) == 0 {
+		return ""
+	}
+	return opts[0].ClientOrderID
+}
+
+// ClientOrderRef convertit un identifiant client en référence numérique positive sur 32 bits,
+// pour les exchanges qui n'acceptent qu'un entier (userref de Kraken)
+func ClientOrderRef(clientOrderID string) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(clientOrderID))
+	return int32(h.Sum32() & 0x7fffffff)
+}
+
+// FindOpenOrderByClientID recherche parmi les ordres ouverts celui créé avec l'identifiant
+// client indiqué. Les exchanges à référence numérique renseignent OpenOrder.ClientOrderID
+// avec leur référence: elle est aussi comparée à ClientOrderRef.
+func FindOpenOrderByClientID(client Exchange, clientOrderID string) (OpenOrder, bool, error) {
+	if clientOrderID == "" {
+		return OpenOrder{}, false, nil
+	}
+
+	orders, err := client.GetOpenOrders()
+	if err != nil {
+		return OpenOrder{}, false, err
+	}
+
+	ref := strconv.Itoa(int(ClientOrderRef(clientOrderID)))
+	for _, order := range orders {
+		if order.ClientOrderID != "" && (order.ClientOrderID == clientOrderID || order.ClientOrderID == ref) {
+			return order, true, nil
+		}
+	}
+	return OpenOrder{}, false, nil
+}
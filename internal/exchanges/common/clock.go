@@ -0,0 +1,96 @@
+package common
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerClock est implémenté par les clients capables de lire l'heure de l'exchange (--time-check)
+type ServerClock interface {
+	// Heure du serveur de l'exchange
+	ServerTime() (time.Time, error)
+	// Correction appliquée aux horodatages des requêtes signées (0 tant qu'aucun décalage n'a été mesuré)
+	ClockOffset() time.Duration
+}
+
+// ClockSkew conserve pour la session le décalage entre l'horloge locale et celle d'un exchange.
+// La valeur zéro n'applique aucune correction.
+type ClockSkew struct {
+	mu     sync.Mutex
+	offset time.Duration
+}
+
+// Now retourne l'heure locale corrigée du décalage mesuré
+func (s *ClockSkew) Now() time.Time {
+	return time.Now().Add(s.Offset())
+}
+
+// Timestamp retourne l'horodatage corrigé en millisecondes, tel qu'attendu par les requêtes signées
+func (s *ClockSkew) Timestamp() string {
+	return strconv.FormatInt(s.Now().UnixMilli(), 10)
+}
+
+// Offset retourne le décalage mesuré (heure du serveur - heure locale)
+func (s *ClockSkew) Offset() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offset
+}
+
+// Sync mesure le décalage avec l'heure retournée par serverTime, en supposant que le serveur
+// a répondu au milieu de l'aller-retour, puis l'applique aux requêtes suivantes
+func (s *ClockSkew) Sync(serverTime func() (time.Time, error)) (time.Duration, error) {
+	offset, err := MeasureSkew(serverTime)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	s.offset = offset
+	s.mu.Unlock()
+	return offset, nil
+}
+
+// MeasureSkew retourne le décalage entre l'heure du serveur et l'horloge locale
+func MeasureSkew(serverTime func() (time.Time, error)) (time.Duration, error) {
+	before := time.Now()
+	server, err := serverTime()
+	if err != nil {
+		return 0, err
+	}
+	after := time.Now()
+	local := before.Add(after.Sub(before) / 2)
+	return server.Sub(local), nil
+}
+
+// timestampParam est le paramètre d'horodatage d'une requête signée
+var timestampParam = regexp.MustCompile(`(^|&)timestamp=\d+`)
+
+// Retimestamp remplace l'horodatage d'une requête signée (paramètres puis &signature=...)
+// et la signe à nouveau. Retourne false si la requête n'est pas signée.
+func Retimestamp(signedQuery, timestamp string, sign func(string) string) (string, bool) {
+	index := strings.LastIndex(signedQuery, "&signature=")
+	if index < 0 {
+		return signedQuery, false
+	}
+	query := timestampParam.ReplaceAllString(signedQuery[:index], "${1}timestamp="+timestamp)
+	return query + "&signature=" + sign(query), true
+}
+
+// DefaultRecvWindowMs est la fenêtre de validité des requêtes signées appliquée par Binance et
+// MEXC quand la requête n'en précise pas
+const DefaultRecvWindowMs = 5000
+
+// WithRecvWindow ajoute la fenêtre de validité recvWindow (en millisecondes) à une requête signée
+// (paramètres puis &signature=...) et la signe à nouveau. Une fenêtre nulle laisse la requête
+// inchangée: l'exchange applique la sienne.
+func WithRecvWindow(signedQuery string, recvWindowMs int, sign func(string) string) string {
+	index := strings.LastIndex(signedQuery, "&signature=")
+	if recvWindowMs <= 0 || index < 0 {
+		return signedQuery
+	}
+	query := signedQuery[:index] + "&recvWindow=" + strconv.Itoa(recvWindowMs)
+	return query + "&signature=" + sign(query)
+}
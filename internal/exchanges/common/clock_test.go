@@ -0,0 +1,27 @@
+package common
+
+import "testing"
+
+func TestWithRecvWindow(t *testing.T) {
+	sign := func(query string) string { return "sig(" + query + ")" }
+
+	signed := "symbol=BTCUSDC&timestamp=1700000000000&signature=abc"
+	want := "symbol=BTCUSDC&timestamp=1700000000000&recvWindow=10000&signature=sig(symbol=BTCUSDC&timestamp=1700000000000&recvWindow=10000)"
+	if got := WithRecvWindow(signed, 10000, sign); got != want {
+		t.Errorf("WithRecvWindow = %q, want %q", got, want)
+	}
+
+	// Fenêtre de l'exchange ou requête publique: inchangées
+	if got := WithRecvWindow(signed, 0, sign); got != signed {
+		t.Errorf("WithRecvWindow(0) = %q, want unchanged", got)
+	}
+	if got := WithRecvWindow("symbol=BTCUSDC", 10000, sign); got != "symbol=BTCUSDC" {
+		t.Errorf("WithRecvWindow(unsigned) = %q, want unchanged", got)
+	}
+
+	// Un nouvel horodatage conserve la fenêtre dans la requête signée
+	retried, _ := Retimestamp(WithRecvWindow(signed, 10000, sign), "1700000001000", sign)
+	if want := "symbol=BTCUSDC&timestamp=1700000001000&recvWindow=10000&signature=sig(symbol=BTCUSDC&timestamp=1700000001000&recvWindow=10000)"; retried != want {
+		t.Errorf("Retimestamp = %q, want %q", retried, want)
+	}
+}
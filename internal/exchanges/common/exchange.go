@@ -1,10 +1,37 @@
 package common
 
+import "time"
+
 // DetailedBalance représente les informations détaillées d'un solde d'actif
 type DetailedBalance struct {
 	Free   float64
 	Locked float64
 	Total  float64
+	// Solde immobilisé hors du compte spot (staking, earn), non négociable et exclu de Free et Total
+	Unavailable float64
+}
+
+// Trade représente une exécution (fill) sur la paire BTC/USDC
+type Trade struct {
+	ID       string
+	OrderID  string
+	Side     string // "BUY" ou "SELL"
+	Price    float64
+	Quantity float64
+	Fee      float64
+	FeeAsset string
+	Time     time.Time
+}
+
+// OpenOrder représente un ordre BTC/USDC encore ouvert sur l'exchange
+type OpenOrder struct {
+	ID       string
+	Side     string // "BUY" ou "SELL"
+	Price    float64
+	Quantity float64 // Quantité restant à exécuter
+	// Identifiant client fourni à la création (référence numérique sur Kraken), vide si absent
+	ClientOrderID string
+	CreatedAt     time.Time // Date de création, zéro si l'exchange ne la fournit pas
 }
 
 type Exchange interface {
@@ -14,17 +41,44 @@ type Exchange interface {
 	GetLastPriceBTC() float64
 	GetDetailedBalances() (map[string]DetailedBalance, error)
 	SetBaseURL(url string)
-	CreateOrder(side, price, quantity string) ([]byte, error)
-	CreateMakerOrder(side string, price float64, quantity string) ([]byte, error)
+	// Création d'un ordre: l'identifiant est extrait de la réponse par le client de l'exchange
+	CreateOrder(side, price, quantity string, opts ...OrderOptions) (OrderCreateResult, error)
+	CreateMakerOrder(side string, price float64, quantity string) (OrderCreateResult, error)
+	// Réponse brute de l'exchange pour un ordre (affichage du détail)
 	GetOrderById(id string) ([]byte, error)
-	IsFilled(id string) bool
+	// État interprété d'un ordre, sur lequel reposent les décisions du bot
+	GetOrderStatus(id string) (OrderStatus, error)
 	CancelOrder(orderID string) ([]byte, error)
+	// Annule un ordre en interprétant les erreurs propres à l'exchange: un ordre déjà
+	// exécuté ou annulé donne AlreadyGone sans erreur
+	CancelOrderIdempotent(orderID string) (CancelResult, error)
 	GetExchangeInfo() ([]byte, error)
 	GetAccountInfo() ([]byte, error)
 
+	// Pas de prix et de quantité de la paire BTC/USDC, lus dans GetExchangeInfo et mis en cache
+	Precision() Precision
+	// Prix (arrondi au pas le plus proche) et quantité (arrondie au pas inférieur) tels
+	// qu'envoyés dans les paramètres d'un ordre
+	FormatPrice(price float64) string
+	FormatQuantity(quantity float64) string
+
 	// Nouvelle méthode pour récupérer les frais d'un ordre
 	GetOrderFees(orderId string) (float64, error)
 
 	// Méthode pour ajuster le prix de vente en fonction des frais
 	AdjustSellPriceForFees(buyPrice float64, quantity float64, buyOrderId string) (float64, error)
+
+	// Historique des exécutions BTC/USDC depuis une date, triées chronologiquement
+	GetTradeHistory(since time.Time) ([]Trade, error)
+
+	// Ordres BTC/USDC encore ouverts (fonds bloqués)
+	GetOpenOrders() ([]OpenOrder, error)
+
+	// Vente OCO: limite à sellPrice et stop-limit (déclenché à stopPrice, limite stopLimitPrice).
+	// Retourne ErrOCONotSupported si l'exchange ne propose pas d'OCO natif
+	CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (OCOOrder, error)
+
+	// Consommation de la limite de requêtes relevée dans les en-têtes des réponses
+	// (Known = false si l'exchange ne la communique pas)
+	GetRateLimitStatus() RateLimitStatus
 }
@@ -0,0 +1,34 @@
+package common
+
+// FeeRates contient les taux de frais maker et taker d'un compte (0.001 = 0.1%)
+type FeeRates struct {
+	Maker float64
+	Taker float64
+}
+
+// FeeRateProvider est implémentée par les exchanges capables de lire le niveau de frais
+// réel du compte pour la paire BTC/USDC
+type FeeRateProvider interface {
+	GetAccountFeeRates() (FeeRates, error)
+}
+
+// FeeAssetProvider est implémentée par les exchanges où les frais peuvent être payés dans un
+// autre actif que BTC et USDC (BNB sur Binance). FeeDiscountAsset retourne cet actif si
+// l'option est activée sur le compte, vide sinon.
+type FeeAssetProvider interface {
+	FeeDiscountAsset() (string, error)
+}
+
+// FeeAdjustedSellPrice retourne le prix de vente minimal couvrant les frais d'achat et de vente.
+// La marge de sécurité (0.05 = 5%) ne majore que des frais nets positifs: une remise maker
+// (frais négatifs) n'est pas augmentée, elle abaisse simplement le prix minimal.
+func FeeAdjustedSellPrice(buyPrice, quantity, buyFees, sellFees, safetyMargin float64) float64 {
+	if quantity <= 0 {
+		return buyPrice
+	}
+	totalFees := buyFees + sellFees
+	if totalFees > 0 {
+		totalFees *= 1 + safetyMargin
+	}
+	return buyPrice + totalFees/quantity
+}
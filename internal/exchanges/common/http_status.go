@@ -0,0 +1,29 @@
+package common
+
+import "fmt"
+
+// HTTPStatusError est l'erreur d'une réponse HTTP en échec d'un exchange. Son code distingue
+// une panne de l'exchange (5xx) d'un refus de la requête (4xx) sans analyser le message.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+	format     string // Message propre au client, qui reçoit le code puis le corps
+}
+
+// NewHTTPStatusError crée l'erreur d'une réponse HTTP en échec. format reçoit le code puis le
+// corps de la réponse: chaque client garde ainsi le message qu'il a toujours produit.
+func NewHTTPStatusError(format string, statusCode int, body string) *HTTPStatusError {
+	return &HTTPStatusError{StatusCode: statusCode, Body: body, format: format}
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.format == "" {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf(e.format, e.StatusCode, e.Body)
+}
+
+// ServerError indique si la réponse est une erreur du serveur de l'exchange (5xx)
+func (e *HTTPStatusError) ServerError() bool {
+	return e.StatusCode >= 500 && e.StatusCode <= 599
+}
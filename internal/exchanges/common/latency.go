@@ -0,0 +1,192 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencySamples est le nombre de durées conservées par endpoint pour le calcul des percentiles
+const latencySamples = 1000
+
+// Latency agrège les requêtes HTTP de tous les clients d'exchange du processus (--latency-report)
+var Latency = NewLatencyRecorder()
+
+// EndpointLatency est l'agrégat des requêtes d'un endpoint d'un exchange
+type EndpointLatency struct {
+	Exchange  string        `json:"exchange"`
+	Endpoint  string        `json:"endpoint"` // méthode et chemin: "GET /api/v3/order"
+	Calls     int           `json:"calls"`
+	Errors    int           `json:"errors"`
+	ErrorRate float64       `json:"errorRate"` // entre 0 et 1
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+}
+
+// endpointKey identifie un endpoint d'un exchange
+type endpointKey struct {
+	exchange, endpoint string
+}
+
+// endpointSamples conserve les derniers temps de réponse d'un endpoint
+type endpointSamples struct {
+	calls, errors int
+	durations     []time.Duration
+	next          int
+}
+
+// LatencyRecorder compte les appels, les échecs et les temps de réponse par exchange et par endpoint
+type LatencyRecorder struct {
+	mu        sync.Mutex
+	endpoints map[endpointKey]*endpointSamples
+}
+
+// NewLatencyRecorder crée un enregistreur vide
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{endpoints: make(map[endpointKey]*endpointSamples)}
+}
+
+// Record enregistre une requête terminée; err non nil la compte comme un échec
+func (r *LatencyRecorder) Record(exchange, endpoint string, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := endpointKey{exchange, endpoint}
+	samples, ok := r.endpoints[key]
+	if !ok {
+		samples = &endpointSamples{}
+		r.endpoints[key] = samples
+	}
+	samples.calls++
+	if err != nil {
+		samples.errors++
+	}
+	if len(samples.durations) < latencySamples {
+		samples.durations = append(samples.durations, duration)
+	} else {
+		samples.durations[samples.next] = duration
+		samples.next = (samples.next + 1) % latencySamples
+	}
+}
+
+// Snapshot retourne les agrégats par exchange puis par endpoint, dans l'ordre alphabétique
+func (r *LatencyRecorder) Snapshot() []EndpointLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]EndpointLatency, 0, len(r.endpoints))
+	for key, samples := range r.endpoints {
+		stats = append(stats, aggregateLatency(key.exchange, key.endpoint, samples))
+	}
+	sortLatencies(stats)
+	return stats
+}
+
+// Totals retourne les agrégats de chaque exchange, tous endpoints confondus (Endpoint vide)
+func (r *LatencyRecorder) Totals() []EndpointLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merged := make(map[string]*endpointSamples)
+	for key, samples := range r.endpoints {
+		total, ok := merged[key.exchange]
+		if !ok {
+			total = &endpointSamples{}
+			merged[key.exchange] = total
+		}
+		total.calls += samples.calls
+		total.errors += samples.errors
+		total.durations = append(total.durations, samples.durations...)
+	}
+
+	stats := make([]EndpointLatency, 0, len(merged))
+	for exchange, samples := range merged {
+		stats = append(stats, aggregateLatency(exchange, "", samples))
+	}
+	sortLatencies(stats)
+	return stats
+}
+
+// aggregateLatency calcule l'agrégat des requêtes enregistrées pour un endpoint
+func aggregateLatency(exchange, endpoint string, samples *endpointSamples) EndpointLatency {
+	sorted := append([]time.Duration(nil), samples.durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return EndpointLatency{
+		Exchange:  exchange,
+		Endpoint:  endpoint,
+		Calls:     samples.calls,
+		Errors:    samples.errors,
+		ErrorRate: float64(samples.errors) / float64(samples.calls),
+		P50:       percentile(sorted, 50),
+		P95:       percentile(sorted, 95),
+	}
+}
+
+// sortLatencies trie des agrégats par exchange puis par endpoint
+func sortLatencies(stats []EndpointLatency) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Exchange != stats[j].Exchange {
+			return stats[i].Exchange < stats[j].Exchange
+		}
+		return stats[i].Endpoint < stats[j].Endpoint
+	})
+}
+
+// Reset efface les agrégats
+func (r *LatencyRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints = make(map[endpointKey]*endpointSamples)
+}
+
+// percentile retourne le p-ième percentile (rang le plus proche) de durées triées
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// EndpointName retourne le nom agrégé d'une requête: sa méthode et son chemin, sans paramètres
+// et avec les identifiants d'ordre (segments d'au moins 8 caractères dont un chiffre, comme
+// /api/v1/orders/5f3113a1c9b6d539dc614dc6) remplacés par {id}
+func EndpointName(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) >= 8 && strings.ContainsAny(segment, "0123456789") {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// meteredTransport mesure chaque requête d'un client d'exchange pour Latency
+type meteredTransport struct {
+	exchange string
+	base     http.RoundTripper
+}
+
+// MeteredTransport retourne le transport HTTP des clients d'exchange: chaque requête est mesurée
+// dans Latency, jusqu'à la réception des en-têtes de la réponse. Un échec réseau ou un statut
+// HTTP d'erreur compte comme un échec; les erreurs retournées dans un corps 200 (Kraken) n'y
+// figurent pas.
+func MeteredTransport(exchange string) http.RoundTripper {
+	return &meteredTransport{exchange: exchange, base: http.DefaultTransport}
+}
+
+// RoundTrip exécute la requête et en enregistre la durée et le résultat
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	outcome := err
+	if err == nil && resp.StatusCode >= http.StatusBadRequest {
+		outcome = fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	Latency.Record(t.exchange, EndpointName(req.Method, req.URL.Path), time.Since(start), outcome)
+	return resp, err
+}
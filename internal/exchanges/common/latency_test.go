@@ -0,0 +1,84 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+	recorder := NewLatencyRecorder()
+	for i := 1; i <= 20; i++ {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("timeout")
+		}
+		recorder.Record("KUCOIN", "GET /api/v1/orders/{id}", time.Duration(i)*time.Millisecond, err)
+	}
+	recorder.Record("KUCOIN", "GET /api/v1/timestamp", 5*time.Millisecond, nil)
+	recorder.Record("BINANCE", "GET /api/v3/order", 40*time.Millisecond, nil)
+
+	stats := recorder.Snapshot()
+	if len(stats) != 3 || stats[0].Exchange != "BINANCE" || stats[1].Endpoint != "GET /api/v1/orders/{id}" {
+		t.Fatalf("agrégats = %+v", stats)
+	}
+	orders := stats[1]
+	if orders.Calls != 20 || orders.Errors != 2 || orders.ErrorRate != 0.1 {
+		t.Errorf("appels %d, erreurs %d, taux %.2f", orders.Calls, orders.Errors, orders.ErrorRate)
+	}
+	if orders.P50 != 10*time.Millisecond || orders.P95 != 19*time.Millisecond {
+		t.Errorf("p50 %v, p95 %v, attendu 10ms et 19ms", orders.P50, orders.P95)
+	}
+
+	totals := recorder.Totals()
+	if len(totals) != 2 || totals[1].Exchange != "KUCOIN" || totals[1].Calls != 21 || totals[1].Errors != 2 {
+		t.Errorf("totaux = %+v", totals)
+	}
+
+	recorder.Reset()
+	if len(recorder.Snapshot()) != 0 {
+		t.Error("agrégats conservés après Reset")
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	tests := map[string]string{
+		"/api/v3/order": "GET /api/v3/order",
+		"/api/v1/orders/5f3113a1c9b6d539dc614dc6": "GET /api/v1/orders/{id}",
+		"/api/v1/market/orderbook/level1":         "GET /api/v1/market/orderbook/level1",
+		"/0/private/AddOrder":                     "GET /0/private/AddOrder",
+	}
+	for path, want := range tests {
+		if got := EndpointName("GET", path); got != want {
+			t.Errorf("EndpointName(%q) = %q, attendu %q", path, got, want)
+		}
+	}
+}
+
+func TestMeteredTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	Latency.Reset()
+	defer Latency.Reset()
+	client := &http.Client{Transport: MeteredTransport("MEXC")}
+	for _, path := range []string{"/ok", "/ok", "/fail"} {
+		resp, err := client.Get(server.URL + path + "?symbol=BTCUSDC")
+		if err != nil {
+			t.Fatalf("requête %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	stats := Latency.Snapshot()
+	if len(stats) != 2 || stats[0].Endpoint != "GET /fail" || stats[0].Errors != 1 ||
+		stats[1].Endpoint != "GET /ok" || stats[1].Calls != 2 || stats[1].Errors != 0 {
+		t.Errorf("agrégats = %+v", stats)
+	}
+}
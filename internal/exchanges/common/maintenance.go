@@ -0,0 +1,20 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrMaintenance est renvoyée lorsque l'exchange répond qu'il est en maintenance: ses appels
+// échouent jusqu'à la fin de l'intervention, sans que le bot soit en cause
+var ErrMaintenance = errors.New("exchange en maintenance")
+
+// IsMaintenanceStatus indique si le code HTTP correspond à un service indisponible pour maintenance
+func IsMaintenanceStatus(statusCode int) bool {
+	return statusCode == http.StatusServiceUnavailable
+}
+
+// IsMaintenance indique si l'erreur signale une maintenance de l'exchange
+func IsMaintenance(err error) bool {
+	return errors.Is(err, ErrMaintenance)
+}
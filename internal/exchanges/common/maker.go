@@ -0,0 +1,23 @@
+package common
+
+// Écarts historiques appliqués lorsque aucun écart maker n'est configuré
+const (
+	DefaultMakerOrderBufferPercent = 0.2 // CreateMakerOrder
+	DefaultMakerSellBufferPercent  = 0.1 // prix de vente minimum lors de la mise à jour
+)
+
+// MakerPriceOffset retourne l'écart à appliquer au prix pour rester maker.
+// L'écart vaut bufferPercent% du prix (defaultPercent% si bufferPercent vaut 0)
+// et n'est jamais inférieur au pas de prix du symbole, afin qu'il ne puisse
+// pas disparaître à l'arrondi.
+func MakerPriceOffset(price, bufferPercent, defaultPercent, tickSize float64) float64 {
+	if bufferPercent <= 0 {
+		bufferPercent = defaultPercent
+	}
+
+	offset := price * bufferPercent / 100
+	if offset < tickSize {
+		offset = tickSize
+	}
+	return offset
+}
@@ -0,0 +1,14 @@
+package common
+
+import "errors"
+
+// ErrOCONotSupported est renvoyée par CreateOCOOrder sur les exchanges sans ordre OCO natif
+var ErrOCONotSupported = errors.New("ordres OCO non supportés par cet exchange")
+
+// OCOOrder identifie les deux jambes d'un ordre OCO (one-cancels-other) de vente:
+// l'exécution de l'une annule l'autre
+type OCOOrder struct {
+	ListID  string // Identifiant de la liste d'ordres
+	LimitID string // Vente limite au prix cible
+	StopID  string // Stop-limit de protection
+}
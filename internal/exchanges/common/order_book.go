@@ -0,0 +1,27 @@
+package common
+
+// BookTop est le haut du carnet d'ordres BTC/USDC: meilleur prix acheteur et meilleur prix vendeur
+type BookTop struct {
+	Bid float64 // Meilleure offre d'achat
+	Ask float64 // Meilleure offre de vente
+}
+
+// Spread retourne l'écart entre la meilleure vente et le meilleur achat
+func (b BookTop) Spread() float64 {
+	return b.Ask - b.Bid
+}
+
+// SpreadPercent retourne l'écart en pourcentage du milieu du carnet (0 si le carnet est vide)
+func (b BookTop) SpreadPercent() float64 {
+	mid := (b.Ask + b.Bid) / 2
+	if mid <= 0 {
+		return 0
+	}
+	return b.Spread() / mid * 100
+}
+
+// OrderBookReader est implémenté par les clients capables de lire le haut du carnet d'ordres
+// (bookTicker ou équivalent), utilisé pour vérifier le prix de vente avant de le placer
+type OrderBookReader interface {
+	GetOrderBook() (BookTop, error)
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// OrderCreateResult est la réponse à la création d'un ordre: son identifiant, extrait par chaque
+// client de l'enveloppe propre à son exchange, et la réponse brute pour l'affichage et le journal
+type OrderCreateResult struct {
+	OrderID string
+	Raw     []byte
+}
+
+// NewOrderCreateResult retourne le résultat d'une création d'ordre, ou une erreur citant la
+// réponse si l'exchange n'a pas fourni d'identifiant
+func NewOrderCreateResult(orderID string, raw []byte) (OrderCreateResult, error) {
+	orderID = strings.TrimSpace(orderID)
+	if orderID == "" {
+		return OrderCreateResult{}, fmt.Errorf("ID d'ordre absent de la réponse: %s", string(raw))
+	}
+	return OrderCreateResult{OrderID: orderID, Raw: raw}, nil
+}
+
+// JSONID lit un identifiant au chemin indiqué, qu'il soit transmis comme chaîne ou comme nombre.
+// Retourne une chaîne vide si le champ est absent ou d'un autre type.
+func JSONID(body []byte, keys ...string) string {
+	value, dataType, _, err := jsonparser.Get(body, keys...)
+	if err != nil || (dataType != jsonparser.String && dataType != jsonparser.Number) {
+		return ""
+	}
+	return strings.TrimSpace(string(value))
+}
@@ -0,0 +1,24 @@
+package common
+
+import "time"
+
+// OrderExpirySupporter est implémentée par les clients dont l'exchange peut annuler lui-même un
+// ordre à une date donnée (good-till-date), transmise par OrderOptions.ExpireAt
+type OrderExpirySupporter interface {
+	SupportsOrderExpiry() bool
+}
+
+// SupportsOrderExpiry indique si l'exchange du client accepte une date d'expiration d'ordre
+func SupportsOrderExpiry(client Exchange) bool {
+	supporter, ok := client.(OrderExpirySupporter)
+	return ok && supporter.SupportsOrderExpiry()
+}
+
+// ExpiryRequested retourne la date d'expiration demandée dans les options de CreateOrder
+// (zéro si aucune)
+func ExpiryRequested(opts []OrderOptions) time.Time {
+	if len(opts) == 0 {
+		return time.Time{}
+	}
+	return opts[0].ExpireAt
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+// OrderState est l'état d'un ordre, indépendant du format de l'exchange
+type OrderState int
+
+const (
+	// OrderOpen: l'ordre est ouvert et n'a encore rien exécuté
+	OrderOpen OrderState = iota
+	// OrderPartiallyFilled: l'ordre est ouvert et partiellement exécuté
+	OrderPartiallyFilled
+	// OrderFilled: l'ordre est entièrement exécuté
+	OrderFilled
+	// OrderCancelled: l'ordre a été annulé ou a expiré (ExecutedQty indique une éventuelle exécution partielle)
+	OrderCancelled
+	// OrderRejected: l'ordre a été refusé par l'exchange
+	OrderRejected
+)
+
+// String retourne le libellé de l'état
+func (s OrderState) String() string {
+	switch s {
+	case OrderPartiallyFilled:
+		return "partiellement exécuté"
+	case OrderFilled:
+		return "exécuté"
+	case OrderCancelled:
+		return "annulé"
+	case OrderRejected:
+		return "rejeté"
+	default:
+		return "ouvert"
+	}
+}
+
+// OrderStatus est l'état d'un ordre interprété par le client de l'exchange (GetOrderStatus).
+// Les décisions du bot reposent sur cette structure plutôt que sur la réponse brute.
+type OrderStatus struct {
+	ID           string
+	State        OrderState
+	ExecutedQty  float64 // Quantité de BTC exécutée
+	AvgFillPrice float64 // Prix moyen d'exécution, 0 si rien n'est exécuté ou si l'information manque
+	QuoteAmount  float64 // Montant USDC exécuté fourni par l'exchange (cummulativeQuoteQty), 0 s'il manque
+	Fee          float64 // Frais en USDC lorsque l'exchange les fournit avec l'ordre, 0 sinon
+	BaseFee      float64 // Frais prélevés en BTC sur la quantité exécutée (déjà comptés dans Fee), 0 sinon
+	// Frais payés dans un autre actif que BTC et USDC (BNB sur Binance), comptés dans Fee à leur
+	// prix à la date de l'exécution quand il est connu
+	FeeAsset       string
+	FeeAssetAmount float64
+	UpdatedAt      time.Time // Date de la dernière exécution ou de la clôture, zéro si inconnue
+	Expired        bool      // Ordre annulé par l'exchange à sa date d'expiration (OrderOptions.ExpireAt)
+}
+
+// Filled indique si l'ordre est entièrement exécuté
+func (s OrderStatus) Filled() bool {
+	return s.State == OrderFilled
+}
+
+// OrderFloat lit un champ numérique d'une réponse d'ordre, qu'il soit encodé en chaîne ou
+// en nombre. Retourne 0 si le champ est absent ou invalide.
+func OrderFloat(order []byte, key string) float64 {
+	if str, err := jsonparser.GetString(order, key); err == nil {
+		value, _ := strconv.ParseFloat(str, 64)
+		return value
+	}
+	value, _ := jsonparser.GetFloat(order, key)
+	return value
+}
+
+// AveragePrice calcule un prix moyen d'exécution à partir du montant et de la quantité exécutés
+func AveragePrice(quote, base float64) float64 {
+	if quote <= 0 || base <= 0 {
+		return 0
+	}
+	return quote / base
+}
@@ -0,0 +1,15 @@
+package common
+
+// PairStatus est l'état de négociation de la paire BTC/USDC publié par l'exchange dans
+// GetExchangeInfo. Une paire suspendue (migration, retrait de la cote) refuse la création
+// comme l'annulation des ordres.
+type PairStatus struct {
+	Status  string // État tel que donné par l'exchange (TRADING, HALT, BREAK, online, cancel_only...)
+	Trading bool   // Ordres acceptés
+}
+
+// PairStatusReader est implémenté par les clients capables de lire l'état de négociation de la
+// paire, vérifié au début de chaque mise à jour
+type PairStatusReader interface {
+	PairStatus() (PairStatus, error)
+}
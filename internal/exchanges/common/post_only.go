@@ -0,0 +1,79 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// OrderOptions regroupe les options facultatives de CreateOrder
+type OrderOptions struct {
+	// PostOnly refuse l'ordre s'il devait s'exécuter immédiatement (frais taker)
+	PostOnly bool
+	// ClientOrderID identifie l'ordre côté bot (newClientOrderId, clientOid, userref)
+	ClientOrderID string
+	// Market place un ordre au marché: le prix transmis ne sert qu'à l'estimation (montant minimal)
+	Market bool
+	// ExpireAt fait annuler l'ordre par l'exchange à cette date (zéro = sans expiration), sur les
+	// exchanges qui implémentent OrderExpirySupporter
+	ExpireAt time.Time
+}
+
+// PostOnlyRequested indique si les options passées à CreateOrder demandent un ordre post-only
+func PostOnlyRequested(opts []OrderOptions) bool {
+	return len(opts) > 0 && opts[0].PostOnly
+}
+
+// MarketRequested indique si les options passées à CreateOrder demandent un ordre au marché
+func MarketRequested(opts []OrderOptions) bool {
+	return len(opts) > 0 && opts[0].Market
+}
+
+// ErrPostOnlyWouldMatch est utilisée par les clients dont l'exchange accepte puis annule
+// l'ordre post-only au lieu de le rejeter (KuCoin)
+var ErrPostOnlyWouldMatch = errors.New("post-only order would immediately match")
+
+// IsPostOnlyRejection indique si l'erreur signale un ordre post-only refusé parce
+// qu'il aurait été exécuté immédiatement
+func IsPostOnlyRejection(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, phrase := range []string{
+		"would immediately match", // Binance (-2010), MEXC et ErrPostOnlyWouldMatch
+		"post only order",         // Kraken (EOrder:Post only order)
+		"post-only",
+		"postonly",
+	} {
+		if strings.Contains(msg, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePostOnlyOrder place un ordre post-only. Lorsqu'il est refusé parce qu'il serait
+// exécuté immédiatement, le prix est éloigné du marché d'un tick (plus bas à l'achat,
+// plus haut à la vente) et l'ordre est renvoyé, au plus maxRetries fois.
+// Le prix finalement utilisé est retourné avec l'ordre créé. Les autres options
+// (identifiant client) sont transmises à chaque essai.
+func CreatePostOnlyOrder(client Exchange, side string, price float64, quantity string, tickSize float64, maxRetries int, opts OrderOptions) (OrderCreateResult, float64, error) {
+	step := tickSize
+	if strings.EqualFold(side, "BUY") {
+		step = -tickSize
+	}
+
+	opts.PostOnly = true
+	for attempt := 0; ; attempt++ {
+		result, err := client.CreateOrder(side, FormatPrice(price, tickSize), quantity, opts)
+		if err == nil {
+			return result, price, nil
+		}
+		if !IsPostOnlyRejection(err) || attempt >= maxRetries {
+			return OrderCreateResult{}, price, err
+		}
+		price += step
+	}
+}
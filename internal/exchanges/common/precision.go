@@ -0,0 +1,99 @@
+package common
+
+import (
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Precision est le pas de prix et de quantité de la paire BTC/USDC d'un exchange, lu dans
+// GetExchangeInfo
+type Precision struct {
+	PriceTick    float64 // Pas de prix (0.01 = au centime, 0.1 sur KuCoin)
+	QuantityStep float64 // Pas de quantité en BTC
+}
+
+// DefaultPrecision s'applique lorsque l'exchange ne fournit pas ses pas: prix au centime,
+// quantité à 8 décimales
+var DefaultPrecision = Precision{PriceTick: 0.01, QuantityStep: 0.00000001}
+
+// orDefault complète les pas inconnus (nuls) avec ceux de DefaultPrecision
+func (p Precision) orDefault() Precision {
+	if p.PriceTick <= 0 {
+		p.PriceTick = DefaultPrecision.PriceTick
+	}
+	if p.QuantityStep <= 0 {
+		p.QuantityStep = DefaultPrecision.QuantityStep
+	}
+	return p
+}
+
+// FormatPrice arrondit un prix au pas le plus proche et le formate avec les décimales du pas
+func (p Precision) FormatPrice(price float64) string {
+	return FormatPrice(price, p.orDefault().PriceTick)
+}
+
+// FormatQuantity arrondit une quantité au pas inférieur, pour ne jamais engager plus que le
+// solde, et la formate avec les décimales du pas
+func (p Precision) FormatQuantity(quantity float64) string {
+	step := p.orDefault().QuantityStep
+	return strconv.FormatFloat(math.Floor(quantity/step+1e-9)*step, 'f', stepDecimals(step), 64)
+}
+
+// FormatPrice arrondit un prix au multiple de tickSize le plus proche et le formate avec le
+// nombre de décimales du pas de prix
+func FormatPrice(price, tickSize float64) string {
+	return strconv.FormatFloat(RoundToTick(price, tickSize), 'f', stepDecimals(tickSize), 64)
+}
+
+// RoundToTick arrondit un prix au multiple de tickSize le plus proche
+func RoundToTick(price, tickSize float64) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	rounded := math.Round(price/tickSize) * tickSize
+	// Supprimer les résidus binaires (0.1 * 3 = 0.30000000000000004)
+	value, _ := strconv.ParseFloat(strconv.FormatFloat(rounded, 'f', stepDecimals(tickSize), 64), 64)
+	return value
+}
+
+// RoundSellPrice arrondit un prix de vente au pas le plus proche sans descendre sous minPrice
+// (prix couvrant les frais): l'arrondi se fait alors au pas supérieur à minPrice
+func RoundSellPrice(price, minPrice, tickSize float64) float64 {
+	rounded := RoundToTick(price, tickSize)
+	if tickSize > 0 && rounded < minPrice {
+		rounded = RoundToTick(math.Ceil(minPrice/tickSize-1e-9)*tickSize, tickSize)
+	}
+	return rounded
+}
+
+// stepDecimals retourne le nombre de décimales d'un pas (2 pour 0.01, 0 pour 5)
+func stepDecimals(step float64) int {
+	if step <= 0 {
+		return 8
+	}
+	return int(math.Max(0, math.Ceil(-math.Log10(step)-1e-9)))
+}
+
+// PrecisionCache conserve pour la session la précision lue auprès d'un exchange. Un échec de
+// lecture n'est pas mémorisé: la lecture est retentée au prochain appel.
+type PrecisionCache struct {
+	mu        sync.Mutex
+	precision *Precision
+}
+
+// Get retourne la précision en cache, ou celle lue par fetch, DefaultPrecision en cas d'échec
+func (c *PrecisionCache) Get(fetch func() (Precision, error)) Precision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.precision != nil {
+		return *c.precision
+	}
+	precision, err := fetch()
+	if err != nil {
+		return DefaultPrecision
+	}
+	precision = precision.orDefault()
+	c.precision = &precision
+	return precision
+}
@@ -0,0 +1,53 @@
+package common
+
+import "testing"
+
+func TestRoundSellPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		minPrice float64
+		tick     float64
+		want     float64
+	}{
+		{name: "arrondi au pas inférieur le plus proche", price: 60600.04, minPrice: 60100, tick: 0.1, want: 60600},
+		{name: "arrondi au pas supérieur le plus proche", price: 60600.06, minPrice: 60100, tick: 0.1, want: 60600.1},
+		{name: "plancher des frais au-dessus du pas le plus proche", price: 60100.04, minPrice: 60100.03, tick: 0.1, want: 60100.1},
+		{name: "plancher des frais exactement sur un pas", price: 60100.04, minPrice: 60100, tick: 0.1, want: 60100},
+		{name: "pas entier", price: 60604, minPrice: 60601, tick: 5, want: 60605},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundSellPrice(tt.price, tt.minPrice, tt.tick); got != tt.want {
+				t.Errorf("RoundSellPrice(%v, %v, %v) = %v, attendu %v", tt.price, tt.minPrice, tt.tick, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrecisionFormat(t *testing.T) {
+	tests := []struct {
+		precision Precision
+		price     float64
+		quantity  float64
+		wantPrice string
+		wantQty   string
+	}{
+		{Precision{PriceTick: 0.01, QuantityStep: 0.00001}, 60600.126, 0.0015099, "60600.13", "0.00150"},
+		{Precision{PriceTick: 0.1, QuantityStep: 0.00000001}, 60600.05, 0.0015, "60600.1", "0.00150000"},
+		{Precision{PriceTick: 1, QuantityStep: 0.001}, 60600.4, 0.0029999, "60600", "0.002"},
+		// Pas inconnus: DefaultPrecision
+		{Precision{}, 60600.004, 0.001500009, "60600.00", "0.00150000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.precision.FormatPrice(tt.price); got != tt.wantPrice {
+			t.Errorf("%+v: FormatPrice(%v) = %s, attendu %s", tt.precision, tt.price, got, tt.wantPrice)
+		}
+		// La quantité est arrondie au pas inférieur: jamais plus que le solde
+		if got := tt.precision.FormatQuantity(tt.quantity); got != tt.wantQty {
+			t.Errorf("%+v: FormatQuantity(%v) = %s, attendu %s", tt.precision, tt.quantity, got, tt.wantQty)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimits conserve la consommation des limites de requêtes de tous les clients d'exchange
+// du processus, relevée dans les en-têtes des réponses
+var RateLimits = NewRateLimitTracker()
+
+// RateLimitStatus est la consommation de la limite de requêtes d'un exchange
+type RateLimitStatus struct {
+	Known     bool          `json:"known"`     // false si l'exchange n'a communiqué aucun compteur
+	Used      int           `json:"used"`      // poids ou requêtes consommés dans la fenêtre courante
+	Limit     int           `json:"limit"`     // maximum autorisé par fenêtre
+	Window    time.Duration `json:"window"`    // durée de la fenêtre de comptage
+	Peak      int           `json:"peak"`      // consommation la plus haute relevée depuis la remise à zéro
+	UpdatedAt time.Time     `json:"updatedAt"` // date du dernier relevé
+}
+
+// Percent retourne la consommation courante en pourcentage de la limite
+func (s RateLimitStatus) Percent() float64 {
+	if s.Limit <= 0 {
+		return 0
+	}
+	return float64(s.Used) / float64(s.Limit) * 100
+}
+
+// PeakPercent retourne la consommation la plus haute en pourcentage de la limite
+func (s RateLimitStatus) PeakPercent() float64 {
+	if s.Limit <= 0 {
+		return 0
+	}
+	return float64(s.Peak) / float64(s.Limit) * 100
+}
+
+// RateLimitTracker mémorise le dernier relevé et le pic de consommation par exchange
+type RateLimitTracker struct {
+	mu       sync.Mutex
+	statuses map[string]RateLimitStatus
+}
+
+// NewRateLimitTracker crée un suivi vide
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{statuses: make(map[string]RateLimitStatus)}
+}
+
+// Record enregistre un relevé du compteur d'un exchange. Un relevé sans limite connue est ignoré.
+func (t *RateLimitTracker) Record(exchange string, used, limit int, window time.Duration) {
+	if limit <= 0 || used < 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.statuses[exchange]
+	status.Known = true
+	status.Used, status.Limit, status.Window = used, limit, window
+	if used > status.Peak {
+		status.Peak = used
+	}
+	status.UpdatedAt = time.Now()
+	t.statuses[exchange] = status
+}
+
+// Status retourne le dernier relevé d'un exchange (Known = false sans relevé)
+func (t *RateLimitTracker) Status(exchange string) RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.statuses[exchange]
+}
+
+// Reset efface les relevés, au début d'une mise à jour
+func (t *RateLimitTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statuses = make(map[string]RateLimitStatus)
+}
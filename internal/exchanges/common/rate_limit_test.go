@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitTracker(t *testing.T) {
+	tracker := NewRateLimitTracker()
+	if status := tracker.Status("BINANCE"); status.Known {
+		t.Fatalf("état connu sans relevé: %+v", status)
+	}
+
+	tracker.Record("BINANCE", 4200, 6000, time.Minute)
+	tracker.Record("BINANCE", 1200, 6000, time.Minute)
+	// Relevé sans limite: ignoré
+	tracker.Record("BINANCE", 5000, 0, time.Minute)
+
+	status := tracker.Status("BINANCE")
+	if !status.Known || status.Used != 1200 || status.Peak != 4200 || status.Window != time.Minute {
+		t.Fatalf("relevé inattendu: %+v", status)
+	}
+	if status.Percent() != 20 || status.PeakPercent() != 70 {
+		t.Errorf("consommation %.1f%% (pic %.1f%%), attendu 20%% (pic 70%%)", status.Percent(), status.PeakPercent())
+	}
+
+	tracker.Reset()
+	if status := tracker.Status("BINANCE"); status.Known || status.Peak != 0 {
+		t.Errorf("relevé conservé après Reset: %+v", status)
+	}
+}
@@ -0,0 +1,25 @@
+package common
+
+// Reserve est la part des soldes d'un compte que le bot n'engage jamais: fonds détenus hors du
+// bot sur le même compte (<EXCHANGE>_RESERVE_BTC et <EXCHANGE>_RESERVE_USDC)
+type Reserve struct {
+	BTC  float64
+	USDC float64
+}
+
+// Of retourne la réserve d'un actif ("BTC" ou "USDC", 0 pour les autres)
+func (r Reserve) Of(asset string) float64 {
+	switch asset {
+	case "BTC":
+		return r.BTC
+	case "USDC":
+		return r.USDC
+	default:
+		return 0
+	}
+}
+
+// Usable retourne le solde libre d'un actif diminué de sa réserve, jamais négatif
+func (r Reserve) Usable(balances map[string]DetailedBalance, asset string) float64 {
+	return max(0, balances[asset].Free-r.Of(asset))
+}
@@ -0,0 +1,21 @@
+package common
+
+import "testing"
+
+func TestReserveUsable(t *testing.T) {
+	balances := map[string]DetailedBalance{
+		"BTC":  {Free: 0.05, Locked: 0.01},
+		"USDC": {Free: 80},
+	}
+	reserve := Reserve{BTC: 0.02, USDC: 100}
+
+	if got := reserve.Usable(balances, "BTC"); got < 0.02999999 || got > 0.03000001 {
+		t.Errorf("BTC utilisable = %.8f, attendu 0.03", got)
+	}
+	if got := reserve.Usable(balances, "USDC"); got != 0 {
+		t.Errorf("USDC utilisable = %.2f, attendu 0 (réserve supérieure au solde)", got)
+	}
+	if got := (Reserve{}).Usable(balances, "USDC"); got != 80 {
+		t.Errorf("USDC utilisable sans réserve = %.2f, attendu 80", got)
+	}
+}
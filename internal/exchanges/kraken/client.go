@@ -13,6 +13,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,12 +27,28 @@ const (
 	apiVersion = "0"
 )
 
+// DefaultHTTPTimeout est le délai des requêtes HTTP vers Kraken sans KRAKEN_HTTP_TIMEOUT_SECONDS
+const DefaultHTTPTimeout = 30 * time.Second
+
 // Client représente un client API pour l'exchange Kraken
 type Client struct {
 	APIKey    string
 	APISecret string
 	BaseURL   string
 	Debug     bool
+	// Écart en pourcentage pour les ordres maker (0 = 0.2%)
+	MakerBufferPercent float64
+	// Taux de frais utilisés pour les estimations quand les frais réels sont inconnus
+	FeeRates common.FeeRates
+	// Délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+	HTTPTimeout time.Duration
+	// Paire négociée (XBT/USDC par défaut, XBT/USDT), résolue auprès d'AssetPairs au premier appel
+	Pair  string
+	pairs pairCache
+	// Pas de prix et de quantité de la paire, lus au premier ordre
+	precision common.PrecisionCache
+	// Soldes que CreateOrder ne doit jamais engager
+	Reserve common.Reserve
 }
 
 // Structure de réponse standardisée de Kraken
@@ -47,6 +64,8 @@ func NewClient(apiKey, apiSecret string) *Client {
 		APISecret: apiSecret,
 		BaseURL:   apiURL,
 		Debug:     false,
+		// Niveau de base de Kraken: 0.26% maker, 0.40% taker
+		FeeRates: common.FeeRates{Maker: 0.0026, Taker: 0.004},
 	}
 }
 
@@ -55,6 +74,87 @@ func (c *Client) SetBaseURL(url string) {
 	c.BaseURL = url
 }
 
+// SetMakerBufferPercent définit l'écart en pourcentage utilisé par CreateMakerOrder (0 = 0.2%)
+func (c *Client) SetMakerBufferPercent(percent float64) {
+	c.MakerBufferPercent = percent
+}
+
+// SetReserve définit les soldes que CreateOrder ne doit jamais engager
+func (c *Client) SetReserve(reserve common.Reserve) {
+	c.Reserve = reserve
+}
+
+// SetFeeRates définit les taux maker et taker utilisés pour estimer les frais
+func (c *Client) SetFeeRates(rates common.FeeRates) {
+	c.FeeRates = rates
+}
+
+// SetHTTPTimeout définit le délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+func (c *Client) SetHTTPTimeout(timeout time.Duration) {
+	c.HTTPTimeout = timeout
+}
+
+// httpTimeout retourne le délai effectif des requêtes HTTP
+func (c *Client) httpTimeout() time.Duration {
+	if c.HTTPTimeout > 0 {
+		return c.HTTPTimeout
+	}
+	return DefaultHTTPTimeout
+}
+
+// GetAccountFeeRates lit le niveau de frais du compte sur la paire négociée (TradeVolume, en pourcentage)
+func (c *Client) GetAccountFeeRates() (common.FeeRates, error) {
+	pair, err := c.pair()
+	if err != nil {
+		return common.FeeRates{}, err
+	}
+	params := url.Values{}
+	params.Set("pair", pair.Altname)
+
+	result, err := c.sendPrivateRequest("TradeVolume", params)
+	if err != nil {
+		return common.FeeRates{}, fmt.Errorf("erreur lors de la récupération des frais du compte: %w", err)
+	}
+
+	type feeTier struct {
+		Fee string `json:"fee"`
+	}
+	var volume struct {
+		Fees      map[string]feeTier `json:"fees"`
+		FeesMaker map[string]feeTier `json:"fees_maker"`
+	}
+	if err := json.Unmarshal(result, &volume); err != nil {
+		return common.FeeRates{}, fmt.Errorf("réponse TradeVolume invalide: %w", err)
+	}
+
+	// Les grilles sont indexées par la clé de la paire
+	feeTierOf := func(tiers map[string]feeTier) (feeTier, bool) {
+		for name, tier := range tiers {
+			if pair.matches(name) {
+				return tier, true
+			}
+		}
+		return feeTier{}, false
+	}
+	taker, ok := feeTierOf(volume.Fees)
+	if !ok {
+		return common.FeeRates{}, fmt.Errorf("frais %s absents de la réponse", pair.WSName)
+	}
+	takerPercent, err := strconv.ParseFloat(taker.Fee, 64)
+	if err != nil {
+		return common.FeeRates{}, fmt.Errorf("taux taker invalide: %s", taker.Fee)
+	}
+
+	// Sans grille maker distincte, Kraken applique le même taux aux deux côtés
+	makerPercent := takerPercent
+	if maker, ok := feeTierOf(volume.FeesMaker); ok {
+		if makerPercent, err = strconv.ParseFloat(maker.Fee, 64); err != nil {
+			return common.FeeRates{}, fmt.Errorf("taux maker invalide: %s", maker.Fee)
+		}
+	}
+	return common.FeeRates{Maker: makerPercent / 100, Taker: takerPercent / 100}, nil
+}
+
 // SetDebug active ou désactive le mode debug
 func (c *Client) SetDebug(debug bool) {
 	c.Debug = debug
@@ -119,7 +219,7 @@ func (c *Client) sendPublicRequest(method, endpoint string, params url.Values) (
 	c.logDebug("%s %s", method, fullURL)
 
 	// Exécuter la requête
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: c.httpTimeout(), Transport: common.MeteredTransport("KRAKEN")}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de l'envoi de la requête: %w", err)
@@ -135,8 +235,11 @@ func (c *Client) sendPublicRequest(method, endpoint string, params url.Values) (
 	c.logDebug("Réponse: %s", string(body))
 
 	// Vérifier le code de statut HTTP
+	if common.IsMaintenanceStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("%w: %w", common.ErrMaintenance, common.NewHTTPStatusError("HTTP %d: %s", resp.StatusCode, string(body)))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erreur HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, common.NewHTTPStatusError("erreur HTTP %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parser la réponse Kraken standard
@@ -147,6 +250,9 @@ func (c *Client) sendPublicRequest(method, endpoint string, params url.Values) (
 
 	// Vérifier si Kraken a retourné des erreurs
 	if len(response.Error) > 0 {
+		if isMaintenanceError(response.Error) {
+			return nil, fmt.Errorf("%w: %s", common.ErrMaintenance, strings.Join(response.Error, ", "))
+		}
 		return nil, fmt.Errorf("erreur API Kraken: %s", strings.Join(response.Error, ", "))
 	}
 
@@ -178,7 +284,7 @@ func (c *Client) sendPrivateRequest(endpoint string, params url.Values) ([]byte,
 	c.logDebug("Payload: %s", params.Encode())
 
 	// Exécuter la requête
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: c.httpTimeout(), Transport: common.MeteredTransport("KRAKEN")}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de l'envoi de la requête: %w", err)
@@ -194,8 +300,11 @@ func (c *Client) sendPrivateRequest(endpoint string, params url.Values) ([]byte,
 	c.logDebug("Réponse: %s", string(body))
 
 	// Vérifier le code de statut HTTP
+	if common.IsMaintenanceStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("%w: %w", common.ErrMaintenance, common.NewHTTPStatusError("HTTP %d: %s", resp.StatusCode, string(body)))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erreur HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, common.NewHTTPStatusError("erreur HTTP %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parser la réponse Kraken standard
@@ -206,12 +315,90 @@ func (c *Client) sendPrivateRequest(endpoint string, params url.Values) ([]byte,
 
 	// Vérifier si Kraken a retourné des erreurs
 	if len(response.Error) > 0 {
+		if isMaintenanceError(response.Error) {
+			return nil, fmt.Errorf("%w: %s", common.ErrMaintenance, strings.Join(response.Error, ", "))
+		}
 		return nil, fmt.Errorf("erreur API Kraken: %s", strings.Join(response.Error, ", "))
 	}
 
 	return response.Result, nil
 }
 
+// isMaintenanceError indique si Kraken signale une indisponibilité du service (maintenance)
+func isMaintenanceError(errors []string) bool {
+	for _, e := range errors {
+		if strings.HasPrefix(e, "EService:Unavailable") {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerTime retourne l'heure du serveur Kraken, à la seconde près
+func (c *Client) ServerTime() (time.Time, error) {
+	result, err := c.sendPublicRequest("GET", "Time", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var serverTime struct {
+		UnixTime int64 `json:"unixtime"`
+	}
+	if err := json.Unmarshal(result, &serverTime); err != nil {
+		return time.Time{}, fmt.Errorf("réponse Time invalide: %w", err)
+	}
+	return time.Unix(serverTime.UnixTime, 0), nil
+}
+
+// ClockOffset retourne 0: les requêtes privées de Kraken sont authentifiées par un nonce
+// croissant, sans fenêtre de validité liée à l'horloge
+func (c *Client) ClockOffset() time.Duration {
+	return 0
+}
+
+// ticker retourne l'entrée de la paire négociée dans la réponse Ticker, indexée par la clé
+// de la paire
+func (c *Client) ticker() (map[string]json.RawMessage, error) {
+	pair, err := c.pair()
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("pair", pair.Altname)
+	data, err := c.sendPublicRequest("GET", "Ticker", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticker map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &ticker); err != nil {
+		return nil, fmt.Errorf("erreur lors du parsing du ticker: %w", err)
+	}
+	for name, entry := range ticker {
+		if pair.matches(name) {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("paire %s absente du ticker: %s", pair.WSName, string(data))
+}
+
+// GetOrderBook retourne le meilleur achat et la meilleure vente de la paire (champs b et a du Ticker)
+func (c *Client) GetOrderBook() (common.BookTop, error) {
+	entry, err := c.ticker()
+	if err != nil {
+		return common.BookTop{}, err
+	}
+
+	var ask, bid []string
+	if json.Unmarshal(entry["a"], &ask) == nil && json.Unmarshal(entry["b"], &bid) == nil && len(ask) > 0 && len(bid) > 0 {
+		bidPrice, bidErr := strconv.ParseFloat(bid[0], 64)
+		askPrice, askErr := strconv.ParseFloat(ask[0], 64)
+		if bidErr == nil && askErr == nil {
+			return common.BookTop{Bid: bidPrice, Ask: askPrice}, nil
+		}
+	}
+	return common.BookTop{}, fmt.Errorf("meilleurs prix absents du ticker")
+}
+
 // CheckConnection vérifie la connexion à l'API Kraken
 func (c *Client) CheckConnection() error {
 	// Utiliser une requête publique simple pour vérifier la connexion
@@ -221,6 +408,12 @@ func (c *Client) CheckConnection() error {
 		return err
 	}
 
+	// La paire configurée doit exister pour la région du compte
+	if _, err := c.pair(); err != nil {
+		color.Red("Kraken: %v", err)
+		return err
+	}
+
 	// Vérifier également que les clés API fonctionnent en faisant une requête privée simple
 	if c.APIKey != "" && c.APISecret != "" {
 		_, err = c.sendPrivateRequest("Balance", nil)
@@ -236,40 +429,26 @@ func (c *Client) CheckConnection() error {
 
 // GetLastPriceBTC récupère le prix actuel du BTC
 func (c *Client) GetLastPriceBTC() float64 {
-	// Créer les paramètres pour la requête
-	params := url.Values{}
-	params.Set("pair", "XBTUSDC") // XBT est le code de Kraken pour BTC
-
-	// Envoyer la requête
-	data, err := c.sendPublicRequest("GET", "Ticker", params)
+	// Entrée de la paire négociée (XBT est le code de Kraken pour BTC)
+	entry, err := c.ticker()
 	if err != nil {
 		color.Red("Erreur lors de la récupération du prix BTC: %v", err)
 		return 0
 	}
 
-	// Analyser la réponse
-	var ticker map[string]map[string]json.RawMessage
-	if err := json.Unmarshal(data, &ticker); err != nil {
-		color.Red("Erreur lors du parsing du ticker: %v", err)
+	// Extraction du prix
+	var price []string
+	if err := json.Unmarshal(entry["c"], &price); err != nil {
+		color.Red("Erreur lors de l'extraction du prix: %v", err)
 		return 0
 	}
-
-	// Extraction du prix
-	for _, v := range ticker {
-		var price []string
-		if err := json.Unmarshal(v["c"], &price); err != nil {
-			color.Red("Erreur lors de l'extraction du prix: %v", err)
+	if len(price) > 0 {
+		p, err := strconv.ParseFloat(price[0], 64)
+		if err != nil {
+			color.Red("Erreur lors de la conversion du prix: %v", err)
 			return 0
 		}
-
-		if len(price) > 0 {
-			p, err := strconv.ParseFloat(price[0], 64)
-			if err != nil {
-				color.Red("Erreur lors de la conversion du prix: %v", err)
-				return 0
-			}
-			return p
-		}
+		return p
 	}
 
 	color.Red("Prix BTC non trouvé dans la réponse")
@@ -278,144 +457,73 @@ func (c *Client) GetLastPriceBTC() float64 {
 
 // GetDetailedBalances récupère les soldes détaillés du compte
 func (c *Client) GetDetailedBalances() (map[string]common.DetailedBalance, error) {
-	// Initialiser la map des soldes
-	balances := make(map[string]common.DetailedBalance)
-
-	// Récupérer les soldes
-	data, err := c.sendPrivateRequest("Balance", nil)
+	// BalanceEx fournit, pour chaque actif, le solde et le montant bloqué par les ordres ouverts
+	// (hold_trade), quelle que soit la paire de ces ordres
+	data, err := c.sendPrivateRequest("BalanceEx", nil)
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de la récupération des soldes: %w", err)
 	}
+	return parseBalanceEx(data, c.quoteAsset())
+}
 
-	// Analyser la réponse
-	var balanceData map[string]string
+// parseBalanceEx convertit la réponse BalanceEx au format commun; le solde de la devise de
+// cotation (quote) est reporté sous "USDC". Les soldes en staking ou en earn (suffixes .S, .M,
+// .F, .B, .P) ne sont pas négociables: ils sont reportés dans Unavailable, hors de Free et de Total.
+func parseBalanceEx(data []byte, quote string) (map[string]common.DetailedBalance, error) {
+	var balanceData map[string]struct {
+		Balance   string `json:"balance"`
+		HoldTrade string `json:"hold_trade"`
+	}
 	if err := json.Unmarshal(data, &balanceData); err != nil {
 		return nil, fmt.Errorf("erreur lors du parsing des soldes: %w", err)
 	}
 
-	// Récupérer les informations sur les ordres ouverts pour calculer les montants bloqués
-	openOrdersData, err := c.sendPrivateRequest("OpenOrders", nil)
-	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération des ordres ouverts: %w", err)
+	balances := map[string]common.DetailedBalance{
+		"BTC":  {},
+		"USDC": {},
 	}
-
-	var openOrders struct {
-		Open map[string]struct {
-			Status  string            `json:"status"`
-			Vol     string            `json:"vol"`
-			VolExec string            `json:"vol_exec"`
-			Descr   map[string]string `json:"descr"`
-		} `json:"open"`
-	}
-
-	if err := json.Unmarshal(openOrdersData, &openOrders); err != nil {
-		return nil, fmt.Errorf("erreur lors du parsing des ordres ouverts: %w", err)
-	}
-
-	// Calculer les montants bloqués par devise
-	lockedAmounts := make(map[string]float64)
-
-	// Logique corrigée pour déterminer les montants bloqués
-	for _, order := range openOrders.Open {
-		if order.Status == "open" {
-			pair := order.Descr["pair"]
-			orderType := order.Descr["type"] // "buy" ou "sell"
-			vol, _ := strconv.ParseFloat(order.Vol, 64)
-			volExec, _ := strconv.ParseFloat(order.VolExec, 64)
-			remainingVol := vol - volExec
-
-			// Vérifier spécifiquement pour la paire BTC/USDC (XBTUSDC chez Kraken)
-			if pair == "XBTUSDC" {
-				price, _ := strconv.ParseFloat(order.Descr["price"], 64)
-
-				if orderType == "buy" {
-					// Pour un ordre d'achat de BTC, les USDC sont bloqués
-					// Le montant bloqué est: prix * volume restant
-					lockedAmount := price * remainingVol
-					lockedAmounts["USDC"] += lockedAmount
-				} else if orderType == "sell" {
-					// Pour un ordre de vente de BTC, les BTC sont bloqués
-					lockedAmounts["XBT"] += remainingVol
-				}
-			} else {
-				// Pour les autres paires, essayer de déterminer logiquement
-				if strings.HasPrefix(pair, "XBT") {
-					// Paires commençant par XBT (BTC)
-					if orderType == "sell" {
-						lockedAmounts["XBT"] += remainingVol
-					}
-				} else if strings.HasSuffix(pair, "XBT") {
-					// Paires se terminant par XBT
-					if orderType == "buy" {
-						lockedAmounts["XBT"] += remainingVol
-					}
-				} else if strings.HasPrefix(pair, "USDC") || strings.HasSuffix(pair, "USDC") {
-					// Paires impliquant USDC
-					if (strings.HasPrefix(pair, "USDC") && orderType == "sell") ||
-						(strings.HasSuffix(pair, "USDC") && orderType == "buy") {
-						price, _ := strconv.ParseFloat(order.Descr["price"], 64)
-						lockedAmounts["USDC"] += price * remainingVol
-					}
-				}
-			}
-		}
-	}
-
-	// Traiter chaque solde pour le format commun
-	for asset, balanceStr := range balanceData {
-		// Convertir le code d'actif Kraken vers le format standard
-		standardAsset := asset
-		if asset == "XXBT" {
-			standardAsset = "BTC"
-		} else if asset == "USDC" {
-			standardAsset = "USDC"
-		} else {
+	for code, entry := range balanceData {
+		asset, unavailable := krakenAsset(code, quote)
+		if asset == "" {
 			continue // On ignore les autres actifs
 		}
-
-		// Convertir le solde en float
-		total, err := strconv.ParseFloat(balanceStr, 64)
+		amount, err := strconv.ParseFloat(entry.Balance, 64)
 		if err != nil {
 			continue
 		}
 
-		// Déterminer les montants libres et bloqués
-		// Pour XBT/BTC
-		var locked float64
-		if asset == "XXBT" {
-			locked = lockedAmounts["XBT"]
-		} else if asset == "USDC" {
-			locked = lockedAmounts["USDC"]
-		} else {
-			locked = lockedAmounts[asset]
+		balance := balances[asset]
+		if unavailable {
+			balance.Unavailable += amount
+			balances[asset] = balance
+			continue
 		}
 
-		free := total - locked
+		hold, _ := strconv.ParseFloat(entry.HoldTrade, 64)
+		hold = math.Min(math.Max(hold, 0), amount)
+		balance.Total += amount
+		balance.Locked += hold
+		balance.Free += amount - hold
+		balances[asset] = balance
+	}
 
-		// S'assurer que les valeurs ne sont pas négatives
-		if free < 0 {
-			free = 0
-		}
-		if locked > total {
-			locked = total
-		}
+	return balances, nil
+}
 
-		balances[standardAsset] = common.DetailedBalance{
-			Free:   free,
-			Locked: locked,
-			Total:  total,
-		}
-	}
+// krakenAsset retourne l'actif du bot correspondant à un code d'actif Kraken ("" s'il n'est pas
+// suivi; la devise de cotation quote devient "USDC") et indique si le solde est immobilisé
+// (staking, earn, parachain)
+func krakenAsset(code, quote string) (string, bool) {
+	base, suffix, _ := strings.Cut(code, ".")
+	unavailable := suffix != ""
 
-	// S'assurer que BTC et USDC existent dans la réponse
-	if _, exists := balances["BTC"]; !exists {
-		balances["BTC"] = common.DetailedBalance{Free: 0, Locked: 0, Total: 0}
+	switch {
+	case krakenBitcoin(base):
+		return "BTC", unavailable
+	case base == quote:
+		return "USDC", unavailable
 	}
-	if _, exists := balances["USDC"]; !exists {
-		balances["USDC"] = common.DetailedBalance{Free: 0, Locked: 0, Total: 0}
-	}
-
-	return balances, nil
+	return "", false
 }
 
 // GetBalanceUSD récupère le solde en USDC
@@ -435,27 +543,32 @@ func (c *Client) GetBalanceUSD() float64 {
 }
 
 // CreateOrder crée un nouvel ordre sur Kraken
-func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
+func (c *Client) CreateOrder(side, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
 	// Convertir la quantité en float pour manipulation précise
 	quantityFloat, err := strconv.ParseFloat(quantity, 64)
 	if err != nil {
-		return nil, fmt.Errorf("quantité invalide: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("quantité invalide: %w", err)
 	}
 
 	// Récupérer les soldes pour vérification précise
 	balances, err := c.GetDetailedBalances()
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération des soldes: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de la récupération des soldes: %w", err)
 	}
 
-	// Vérifier le solde disponible
+	// Vérifier le solde disponible hors réserve, exprimé en BTC
 	var availableBalance float64
 	if side == "SELL" {
-		availableBalance = balances["BTC"].Free
+		availableBalance = c.Reserve.Usable(balances, "BTC")
 	} else if side == "BUY" {
-		availableBalance = balances["USDC"].Free
+		// Le solde USDC doit couvrir la quantité au prix de l'ordre
+		priceFloat, err := strconv.ParseFloat(price, 64)
+		if err != nil || priceFloat <= 0 {
+			return common.OrderCreateResult{}, fmt.Errorf("prix invalide: %s", price)
+		}
+		availableBalance = c.Reserve.Usable(balances, "USDC") / priceFloat
 	} else {
-		return nil, fmt.Errorf("côté de l'ordre non supporté: %s (doit être BUY ou SELL)", side)
+		return common.OrderCreateResult{}, fmt.Errorf("côté de l'ordre non supporté: %s (doit être BUY ou SELL)", side)
 	}
 
 	// Ajuster la quantité si nécessaire
@@ -463,7 +576,7 @@ func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
 	if quantityFloat > availableBalance*tolerancePercent {
 		// Ajuster la quantité
 		adjustedQuantity := availableBalance * tolerancePercent
-		quantity = strconv.FormatFloat(adjustedQuantity, 'f', 8, 64)
+		quantity = c.FormatQuantity(adjustedQuantity)
 
 		color.Yellow("Ajustement de la quantité: %.8f → %.8f (solde disponible)", quantityFloat, adjustedQuantity)
 	}
@@ -471,57 +584,83 @@ func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
 	// Adapter le side pour Kraken (buy/sell)
 	krakenSide := strings.ToLower(side)
 
+	pair, err := c.pair()
+	if err != nil {
+		return common.OrderCreateResult{}, err
+	}
+
 	// Créer les paramètres pour la requête
 	params := url.Values{}
-	params.Set("pair", "XBTUSDC")
+	params.Set("pair", pair.Altname)
 	params.Set("type", krakenSide)
 	params.Set("ordertype", "limit")
 	params.Set("price", price)
 	params.Set("volume", quantity)
 
+	// Ordre au marché: le prix n'est pas transmis
+	if common.MarketRequested(opts) {
+		params.Set("ordertype", "market")
+		params.Del("price")
+	}
+
 	// Pour s'assurer d'être maker, on ajoute le paramètre post-only
-	params.Set("oflags", "post")
+	if common.PostOnlyRequested(opts) {
+		params.Set("oflags", "post")
+	}
+
+	// Expiration côté exchange: timestamp Unix absolu, ignoré pour un ordre au marché
+	if expireAt := common.ExpiryRequested(opts); !expireAt.IsZero() && !common.MarketRequested(opts) {
+		params.Set("expiretm", strconv.FormatInt(expireAt.Unix(), 10))
+	}
+
+	// Kraken n'accepte qu'une référence numérique: l'ID client est converti en userref
+	if clientOrderID := common.ClientOrderIDRequested(opts); clientOrderID != "" {
+		params.Set("userref", strconv.Itoa(int(common.ClientOrderRef(clientOrderID))))
+	}
 
 	// Envoyer la requête
 	data, err := c.sendPrivateRequest("AddOrder", params)
 	if err != nil {
 		// Gérer spécifiquement les erreurs de fonds insuffisants
 		if strings.Contains(err.Error(), "Insufficient funds") {
-			return nil, fmt.Errorf("fonds insuffisants: vérifiez votre solde disponible (err: %v)", err)
+			return common.OrderCreateResult{}, fmt.Errorf("fonds insuffisants: vérifiez votre solde disponible (err: %v)", err)
 		}
-		return nil, fmt.Errorf("erreur lors de la création de l'ordre: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de la création de l'ordre: %w", err)
 	}
 
-	// Convertir la réponse au format attendu par le système
-	var addOrderResponse struct {
-		TxID  []string          `json:"txid"`
-		Descr map[string]string `json:"descr"`
-	}
+	return decodeOrderCreated(data)
+}
 
-	if err := json.Unmarshal(data, &addOrderResponse); err != nil {
-		return nil, fmt.Errorf("erreur lors du parsing de la réponse: %w", err)
+// decodeOrderCreated extrait l'ID de la réponse d'AddOrder ({"txid":["OUF4EM-..."],"descr":{...}}),
+// avec ou sans l'enveloppe {"error":[],"result":{...}}. Une enveloppe d'erreur est retournée
+// comme erreur.
+func decodeOrderCreated(body []byte) (common.OrderCreateResult, error) {
+	var response struct {
+		Error  []string `json:"error"`
+		TxID   []string `json:"txid"`
+		Result *struct {
+			TxID []string `json:"txid"`
+		} `json:"result"`
 	}
-
-	// Créer une réponse standardisée avec l'ID de l'ordre
-	if len(addOrderResponse.TxID) > 0 {
-		standardResponse := map[string]interface{}{
-			"orderId": addOrderResponse.TxID[0],
-			"status":  "created",
-		}
-
-		jsonResponse, err := json.Marshal(standardResponse)
-		if err != nil {
-			return nil, fmt.Errorf("erreur lors de la création de la réponse: %w", err)
-		}
-
-		return jsonResponse, nil
+	if err := json.Unmarshal(body, &response); err != nil {
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors du parsing de la réponse: %w", err)
+	}
+	if len(response.Error) > 0 {
+		return common.OrderCreateResult{}, fmt.Errorf("erreur API Kraken: %s", strings.Join(response.Error, ", "))
 	}
 
-	return nil, fmt.Errorf("aucun ID d'ordre retourné par Kraken")
+	txids := response.TxID
+	if response.Result != nil {
+		txids = response.Result.TxID
+	}
+	if len(txids) == 0 {
+		return common.OrderCreateResult{}, fmt.Errorf("aucun ID d'ordre retourné par Kraken")
+	}
+	return common.NewOrderCreateResult(txids[0], body)
 }
 
-// GetOrderById récupère les informations d'un ordre spécifique
-func (c *Client) GetOrderById(id string) ([]byte, error) {
+// queryOrder récupère le détail brut d'un ordre (QueryOrders), ouvert ou fermé
+func (c *Client) queryOrder(id string) (string, map[string]interface{}, error) {
 	// Créer les paramètres pour la requête
 	params := url.Values{}
 	params.Set("txid", id)
@@ -536,73 +675,105 @@ func (c *Client) GetOrderById(id string) ([]byte, error) {
 
 		closedData, closedErr := c.sendPrivateRequest("QueryOrders", closedParams)
 		if closedErr != nil {
-			return nil, fmt.Errorf("erreur lors de la récupération de l'ordre %s: %w", id, err)
+			return "", nil, fmt.Errorf("erreur lors de la récupération de l'ordre %s: %w", id, err)
 		}
 
 		data = closedData
 	}
 
-	// Convertir la réponse pour qu'elle soit conforme au format attendu par le système
 	var orderData map[string]map[string]interface{}
 	if err := json.Unmarshal(data, &orderData); err != nil {
-		return nil, fmt.Errorf("erreur lors du parsing de l'ordre: %w", err)
+		return "", nil, fmt.Errorf("erreur lors du parsing de l'ordre: %w", err)
 	}
-
-	// Créer une réponse standardisée qui fonctionne avec le reste du système
 	for txid, orderDetails := range orderData {
-		status := orderDetails["status"].(string)
+		return txid, orderDetails, nil
+	}
+	return "", nil, fmt.Errorf("ordre %s non trouvé", id)
+}
 
-		// Convertir l'ordre Kraken en format standardisé
-		standardOrder := map[string]interface{}{
-			"orderId":  txid,
-			"status":   status,
-			"price":    orderDetails["price"],
-			"quantity": orderDetails["vol"],
-			"executed": orderDetails["vol_exec"],
-		}
+// GetOrderById récupère les informations d'un ordre spécifique
+func (c *Client) GetOrderById(id string) ([]byte, error) {
+	txid, orderDetails, err := c.queryOrder(id)
+	if err != nil {
+		return nil, err
+	}
 
-		jsonResponse, err := json.Marshal(standardOrder)
-		if err != nil {
-			return nil, fmt.Errorf("erreur lors de la création de la réponse: %w", err)
-		}
+	// Convertir l'ordre Kraken en format standardisé
+	standardOrder := map[string]interface{}{
+		"orderId":  txid,
+		"status":   orderDetails["status"],
+		"price":    orderDetails["price"], // prix moyen d'exécution
+		"quantity": orderDetails["vol"],
+		"executed": orderDetails["vol_exec"],
+		"cost":     orderDetails["cost"],    // montant total exécuté en USDC
+		"closetm":  orderDetails["closetm"], // date de clôture (secondes), absente si l'ordre est ouvert
+	}
+	if descr, ok := orderDetails["descr"].(map[string]interface{}); ok {
+		standardOrder["limitPrice"] = descr["price"]
+	}
 
-		return jsonResponse, nil
+	jsonResponse, err := json.Marshal(standardOrder)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la création de la réponse: %w", err)
 	}
 
-	return nil, fmt.Errorf("ordre %s non trouvé", id)
+	return jsonResponse, nil
 }
 
-// IsFilled vérifie si un ordre est complètement exécuté
-func (c *Client) IsFilled(order string) bool {
-	var orderData map[string]interface{}
-	if err := json.Unmarshal([]byte(order), &orderData); err != nil {
-		c.logDebug("Erreur lors du parsing de l'ordre: %v", err)
-		return false
+// GetOrderStatus récupère un ordre et en interprète l'état
+func (c *Client) GetOrderStatus(id string) (common.OrderStatus, error) {
+	txid, orderDetails, err := c.queryOrder(id)
+	if err != nil {
+		return common.OrderStatus{}, err
 	}
 
-	// Vérifier si l'ordre est rempli selon le format standardisé
-	status, hasStatus := orderData["status"].(string)
-	if hasStatus && (status == "closed" || status == "filled") {
-		return true
+	order, err := json.Marshal(orderDetails)
+	if err != nil {
+		return common.OrderStatus{}, fmt.Errorf("erreur lors de la lecture de l'ordre %s: %w", id, err)
 	}
+	status := parseOrderStatus(order)
+	status.ID = txid
+	return status, nil
+}
 
-	// Vérifier si l'ordre est complètement exécuté en comparant les quantités
-	executed, hasExecuted := orderData["executed"].(string)
-	quantity, hasQuantity := orderData["quantity"].(string)
-
-	if hasExecuted && hasQuantity {
-		executedFloat, err1 := strconv.ParseFloat(executed, 64)
-		quantityFloat, err2 := strconv.ParseFloat(quantity, 64)
-
-		if err1 == nil && err2 == nil && quantityFloat > 0 {
-			// Si la quantité exécutée est pratiquement égale à la quantité totale (marge d'erreur de 1%)
-			if executedFloat >= quantityFloat*0.99 {
-				return true
-			}
+// parseOrderStatus interprète le détail d'un ordre Kraken (entrée de QueryOrders)
+func parseOrderStatus(order []byte) common.OrderStatus {
+	executed := common.OrderFloat(order, "vol_exec")
+	result := common.OrderStatus{
+		ExecutedQty:  executed,
+		AvgFillPrice: common.AveragePrice(common.OrderFloat(order, "cost"), executed),
+		Fee:          common.OrderFloat(order, "fee"),
+	}
+	if result.AvgFillPrice == 0 && executed > 0 {
+		// "price" contient déjà le prix moyen d'exécution chez Kraken
+		result.AvgFillPrice = common.OrderFloat(order, "price")
+	}
+	// closetm est un timestamp Unix en secondes avec décimales, arrondi à la milliseconde
+	if closeTime := common.OrderFloat(order, "closetm"); closeTime > 0 {
+		result.UpdatedAt = time.UnixMilli(int64(math.Round(closeTime * 1000)))
+	}
+
+	var fields struct {
+		Status string `json:"status"`
+	}
+	_ = json.Unmarshal(order, &fields)
+	switch fields.Status {
+	case "closed":
+		result.State = common.OrderFilled
+	case "canceled", "expired":
+		result.State = common.OrderCancelled
+		result.Expired = fields.Status == "expired"
+	default:
+		if volume := common.OrderFloat(order, "vol"); volume > 0 && executed >= volume*0.99 {
+			// Quantité pratiquement entièrement exécutée (marge d'erreur de 1%)
+			result.State = common.OrderFilled
+		} else if executed > 0 {
+			result.State = common.OrderPartiallyFilled
+		} else {
+			result.State = common.OrderOpen
 		}
 	}
-
-	return false
+	return result
 }
 
 // CancelOrder annule un ordre existant sur Kraken
@@ -633,11 +804,35 @@ func (c *Client) CancelOrder(orderID string) ([]byte, error) {
 	return jsonResponse, nil
 }
 
-// GetExchangeInfo récupère les informations de l'exchange
+// CancelOrderIdempotent annule un ordre. Kraken répond "EOrder:Unknown order" pour un
+// ordre déjà exécuté ou annulé.
+func (c *Client) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	_, err := c.CancelOrder(orderID)
+	if err == nil {
+		return common.Cancelled, nil
+	}
+
+	if strings.Contains(err.Error(), "EOrder:Unknown order") {
+		return common.AlreadyGone, nil
+	}
+	return common.CancelFailed, err
+}
+
+// CreateOCOOrder n'est pas disponible: Kraken ne propose pas d'ordre OCO natif sur le spot
+func (c *Client) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	return common.OCOOrder{}, common.ErrOCONotSupported
+}
+
+// GetExchangeInfo récupère la description AssetPairs de la paire négociée
 func (c *Client) GetExchangeInfo() ([]byte, error) {
+	pair, err := c.pair()
+	if err != nil {
+		return nil, err
+	}
+
 	// Créer les paramètres pour la requête
 	params := url.Values{}
-	params.Set("pair", "XBTUSDC")
+	params.Set("pair", pair.Altname)
 
 	// Envoyer la requête
 	data, err := c.sendPublicRequest("GET", "AssetPairs", params)
@@ -648,6 +843,30 @@ func (c *Client) GetExchangeInfo() ([]byte, error) {
 	return data, nil
 }
 
+// PairStatus lit l'état de négociation de la paire (status de AssetPairs): seul "online"
+// accepte tous les ordres, cancel_only, post_only, limit_only, reduce_only et delisted
+// restreignent ceux du bot
+func (c *Client) PairStatus() (common.PairStatus, error) {
+	info, err := c.GetExchangeInfo()
+	if err != nil {
+		return common.PairStatus{}, err
+	}
+	var pairs map[string]struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(info, &pairs); err != nil {
+		return common.PairStatus{}, fmt.Errorf("réponse AssetPairs invalide: %w", err)
+	}
+	var status string
+	for _, pair := range pairs {
+		status = pair.Status
+	}
+	if status == "" {
+		return common.PairStatus{}, fmt.Errorf("état de la paire absent de la réponse: %s", info)
+	}
+	return common.PairStatus{Status: status, Trading: status == "online"}, nil
+}
+
 // GetAccountInfo récupère les informations du compte
 func (c *Client) GetAccountInfo() ([]byte, error) {
 	// Cette fonction peut être utilisée pour récupérer diverses informations sur le compte
@@ -660,22 +879,25 @@ func (c *Client) GetAccountInfo() ([]byte, error) {
 }
 
 // CreateMakerOrder crée un ordre en mode maker
-func (c *Client) CreateMakerOrder(side string, price float64, quantity string) ([]byte, error) {
+func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
 	// Convertir la quantité en float pour les calculs
 	quantityFloat, err := strconv.ParseFloat(quantity, 64)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la conversion de la quantité: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de la conversion de la quantité: %w", err)
 	}
 
+	// Les prix sont arrondis à 2 décimales: l'écart vaut au moins 0.01
+	offset := common.MakerPriceOffset(price, c.MakerBufferPercent, common.DefaultMakerOrderBufferPercent, 0.01)
+
 	var adjustedPrice float64
 	if strings.ToUpper(side) == "BUY" {
 		// Pour un achat, placer l'ordre légèrement en dessous du marché
-		adjustedPrice = price * 0.998 // 0.2% en dessous
+		adjustedPrice = price - offset
 	} else {
 		// Pour une vente, nous devons prendre en compte les frais
 
-		// Taux de frais maker de Kraken (0.26% pour les niveaux de base)
-		const makerFeeRate = 0.0026
+		// Taux de frais maker configuré (0.26% au niveau de base)
+		makerFeeRate := c.FeeRates.Maker
 
 		// Estimer les frais d'achat déjà payés
 		buyFees := price * quantityFloat * makerFeeRate
@@ -695,8 +917,8 @@ func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (
 		// Prix minimum pour couvrir les frais
 		minProfitablePrice := price + feeAdjustmentPerUnit
 
-		// Prix maker standard (0.2% au-dessus)
-		standardPrice := price * 1.002
+		// Prix maker standard (écart maker au-dessus)
+		standardPrice := price + offset
 
 		// Obtenir le prix actuel du marché
 		currentPrice := c.GetLastPriceBTC()
@@ -728,14 +950,39 @@ func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (
 	adjustedPriceStr := c.formatPrice(adjustedPrice)
 
 	// Créer l'ordre avec le prix ajusté
-	return c.CreateOrder(side, adjustedPriceStr, quantity)
+	return c.CreateOrder(side, adjustedPriceStr, quantity, common.OrderOptions{PostOnly: true})
 }
 
-// formatPrice formate un prix avec la précision appropriée pour Kraken
+// Precision retourne le pas de prix (tick_size, à défaut pair_decimals) et de quantité
+// (lot_decimals) de la paire négociée
+func (c *Client) Precision() common.Precision {
+	return c.precision.Get(func() (common.Precision, error) {
+		pair, err := c.pair()
+		if err != nil {
+			return common.Precision{}, err
+		}
+		tick, err := strconv.ParseFloat(pair.TickSize, 64)
+		if err != nil || tick <= 0 {
+			tick = math.Pow10(-pair.PairDecimals)
+		}
+		return common.Precision{PriceTick: tick, QuantityStep: math.Pow10(-pair.LotDecimals)}, nil
+	})
+}
+
+// FormatPrice formate un prix au pas de prix de la paire
+func (c *Client) FormatPrice(price float64) string {
+	return c.Precision().FormatPrice(price)
+}
+
+// FormatQuantity formate une quantité au pas de quantité de la paire
+func (c *Client) FormatQuantity(quantity float64) string {
+	return c.Precision().FormatQuantity(quantity)
+}
+
+// formatPrice formate un prix au pas de prix de la paire, arrondi au pas inférieur
 func (c *Client) formatPrice(price float64) string {
-	// Kraken utilise généralement une précision de 1 décimale pour les prix BTC/USDC
-	// mais cela peut varier, donc nous utilisons 2 décimales pour être sûrs
-	return strconv.FormatFloat(math.Floor(price*100)/100, 'f', 2, 64)
+	tick := c.Precision().PriceTick
+	return c.Precision().FormatPrice(math.Floor(price/tick+1e-9) * tick)
 }
 
 // GetOrderFees récupère les frais appliqués à un ordre spécifique
@@ -769,7 +1016,7 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 
 		if err := json.Unmarshal(orderDetails, &order); err == nil && order.Fee != "" {
 			orderFees, _ = strconv.ParseFloat(order.Fee, 64)
-			if orderFees > 0 {
+			if orderFees != 0 {
 				return orderFees, nil
 			}
 		}
@@ -805,7 +1052,7 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 			}
 		}
 
-		if totalFees > 0 {
+		if totalFees != 0 {
 			return totalFees, nil
 		}
 	}
@@ -816,8 +1063,8 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 
 // estimateOrderFees estime les frais d'un ordre à partir de son ID
 func (c *Client) estimateOrderFees(orderId string) (float64, error) {
-	// Pour Kraken, le taux de frais maker standard est 0.26%
-	const makerFeeRate = 0.0026
+	// Taux de frais maker configuré (0.26% au niveau de base)
+	makerFeeRate := c.FeeRates.Maker
 
 	// Récupérer les détails de l'ordre
 	params := url.Values{}
@@ -859,43 +1106,160 @@ func (c *Client) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyO
 	buyFees, err := c.GetOrderFees(buyOrderId)
 
 	// Si on ne peut pas récupérer les frais, estimer avec le taux standard
-	if err != nil || buyFees <= 0 {
-		// Taux de frais maker de Kraken (0.26%)
-		const makerFeeRate = 0.0026
-		buyFees = buyPrice * quantity * makerFeeRate
+	if err != nil || buyFees == 0 {
+		buyFees = buyPrice * quantity * c.FeeRates.Maker
 	}
 
-	// Multiplier par 2 pour couvrir les frais de vente également
-	totalFeesToCover := buyFees * 2
-
-	// Ajouter une marge de sécurité de 10% pour Kraken qui a des frais plus élevés
-	totalFeesToCover *= 1.1
-
-	// Calculer l'ajustement de prix par unité
-	feeAdjustmentPerUnit := totalFeesToCover / quantity
-
-	// Calculer le prix minimum pour être rentable
-	minProfitablePrice := buyPrice + feeAdjustmentPerUnit
+	// Frais de vente égaux aux frais d'achat, marge de sécurité de 10% pour Kraken qui a des
+	// frais plus élevés (sans majorer une remise)
+	minProfitablePrice := common.FeeAdjustedSellPrice(buyPrice, quantity, buyFees, buyFees, 0.1)
 
 	c.logDebug("Calcul du prix de vente pour couvrir les frais Kraken:")
 	c.logDebug("Prix d'achat: %.2f USDC", buyPrice)
 	c.logDebug("Frais d'achat: %.8f USDC", buyFees)
-	c.logDebug("Frais totaux à couvrir: %.8f USDC", totalFeesToCover)
-	c.logDebug("Ajustement par unité: %.8f USDC", feeAdjustmentPerUnit)
 	c.logDebug("Prix minimal rentable: %.2f USDC", minProfitablePrice)
 
 	return minProfitablePrice, nil
 }
 
-func (c *Client) GetOpenOrders() ([]byte, error) {
-	// Créer la requête
-	params := url.Values{}
+// GetOpenOrders récupère les ordres encore ouverts sur la paire négociée
+func (c *Client) GetOpenOrders() ([]common.OpenOrder, error) {
+	pair, err := c.pair()
+	if err != nil {
+		return nil, err
+	}
 
-	// Envoyer la requête
-	data, err := c.sendPrivateRequest("OpenOrders", params)
+	data, err := c.sendPrivateRequest("OpenOrders", url.Values{})
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de la récupération des ordres ouverts: %w", err)
 	}
 
-	return data, nil
+	var openOrders struct {
+		Open map[string]struct {
+			Vol     string            `json:"vol"`
+			VolExec string            `json:"vol_exec"`
+			UserRef int64             `json:"userref"`
+			OpenTm  float64           `json:"opentm"`
+			Descr   map[string]string `json:"descr"`
+		} `json:"open"`
+	}
+	if err := json.Unmarshal(data, &openOrders); err != nil {
+		return nil, fmt.Errorf("erreur lors du parsing des ordres ouverts: %w", err)
+	}
+
+	var orders []common.OpenOrder
+	for txid, order := range openOrders.Open {
+		// Kraken renvoie toutes les paires du compte
+		if !pair.matches(order.Descr["pair"]) {
+			continue
+		}
+
+		price, _ := strconv.ParseFloat(order.Descr["price"], 64)
+		vol, _ := strconv.ParseFloat(order.Vol, 64)
+		volExec, _ := strconv.ParseFloat(order.VolExec, 64)
+
+		// La référence numérique tient lieu d'ID client (voir common.FindOpenOrderByClientID)
+		var clientOrderID string
+		if order.UserRef != 0 {
+			clientOrderID = strconv.FormatInt(order.UserRef, 10)
+		}
+
+		var createdAt time.Time
+		if order.OpenTm > 0 {
+			createdAt = time.UnixMilli(int64(order.OpenTm * 1000))
+		}
+
+		orders = append(orders, common.OpenOrder{
+			ID:            txid,
+			Side:          strings.ToUpper(order.Descr["type"]),
+			Price:         price,
+			Quantity:      vol - volExec,
+			ClientOrderID: clientOrderID,
+			CreatedAt:     createdAt,
+		})
+	}
+
+	return orders, nil
+}
+
+// GetTradeHistory récupère les exécutions de la paire négociée depuis la date indiquée
+func (c *Client) GetTradeHistory(since time.Time) ([]common.Trade, error) {
+	pair, err := c.pair()
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []common.Trade
+
+	for offset := 0; ; {
+		params := url.Values{}
+		params.Set("start", strconv.FormatInt(since.Unix(), 10))
+		params.Set("ofs", strconv.Itoa(offset))
+
+		data, err := c.sendPrivateRequest("TradesHistory", params)
+		if err != nil {
+			return nil, fmt.Errorf("erreur lors de la récupération de l'historique des trades: %w", err)
+		}
+
+		var history struct {
+			Trades map[string]struct {
+				OrderTxid string  `json:"ordertxid"`
+				Pair      string  `json:"pair"`
+				Time      float64 `json:"time"`
+				Type      string  `json:"type"`
+				Price     string  `json:"price"`
+				Fee       string  `json:"fee"`
+				Vol       string  `json:"vol"`
+			} `json:"trades"`
+			Count int `json:"count"`
+		}
+		if err := json.Unmarshal(data, &history); err != nil {
+			return nil, fmt.Errorf("erreur lors du parsing de l'historique des trades: %w", err)
+		}
+
+		for txid, trade := range history.Trades {
+			// Kraken renvoie toutes les paires du compte
+			if !pair.matches(trade.Pair) {
+				continue
+			}
+
+			price, _ := strconv.ParseFloat(trade.Price, 64)
+			vol, _ := strconv.ParseFloat(trade.Vol, 64)
+			fee, _ := strconv.ParseFloat(trade.Fee, 64)
+			seconds, fraction := math.Modf(trade.Time)
+
+			trades = append(trades, common.Trade{
+				ID:       txid,
+				OrderID:  trade.OrderTxid,
+				Side:     strings.ToUpper(trade.Type),
+				Price:    price,
+				Quantity: vol,
+				Fee:      fee,
+				FeeAsset: "USDC", // devise de cotation, comptée comme les USDC
+				Time:     time.Unix(int64(seconds), int64(fraction*1e9)),
+			})
+		}
+
+		offset += len(history.Trades)
+		if len(history.Trades) == 0 || offset >= history.Count {
+			break
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	return trades, nil
+}
+
+// SupportsOrderExpiry indique que Kraken accepte une date d'expiration d'ordre (expiretm)
+func (c *Client) SupportsOrderExpiry() bool {
+	return true
+}
+
+// GetRateLimitStatus retourne un état inconnu: le compteur d'appels de Kraken n'est pas exposé
+// par l'API
+func (c *Client) GetRateLimitStatus() common.RateLimitStatus {
+	return common.RateLimits.Status("KRAKEN")
 }
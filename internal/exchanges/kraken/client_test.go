@@ -0,0 +1,244 @@
+package kraken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"main/internal/exchanges/common"
+)
+
+func TestCancelOrderIdempotent(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    common.CancelResult
+		wantErr bool
+	}{
+		{
+			name:   "ordre ouvert annulé",
+			status: http.StatusOK,
+			body:   `{"error":[],"result":{"count":1}}`,
+			want:   common.Cancelled,
+		},
+		{
+			name:   "ordre déjà exécuté ou annulé",
+			status: http.StatusOK,
+			body:   `{"error":["EOrder:Unknown order"],"result":{}}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			name:    "nonce invalide",
+			status:  http.StatusOK,
+			body:    `{"error":["EAPI:Invalid nonce"],"result":{}}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+		{
+			name:    "service indisponible",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"error":["EService:Unavailable"]}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/0/private/CancelOrder" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			// La clé secrète Kraken est encodée en base64
+			client := NewClient("key", "c2VjcmV0")
+			client.SetBaseURL(server.URL)
+
+			got, err := client.CancelOrderIdempotent("OQCLML-BW3P3-BUCMWZ")
+			if got != tt.want {
+				t.Errorf("CancelOrderIdempotent() = %v, attendu %v (erreur: %v)", got, tt.want, err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/0/public/AssetPairs" {
+			w.Write([]byte(`{"error":[],"result":{"XBTUSDC":{"altname":"XBTUSDC","wsname":"XBT/USDC","base":"XXBT","quote":"USDC"}}}`))
+			return
+		}
+		if r.URL.Path != "/0/private/OpenOrders" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"error":[],"result":{"open":{
+			"OQCLML-BW3P3-BUCMWZ":{"status":"open","vol":"0.00150000","vol_exec":"0.00050000","descr":{"pair":"XBTUSDC","type":"buy","price":"60000.0"}},
+			"OB5VMB-B4U2U-DK2WRW":{"status":"open","vol":"1.00000000","vol_exec":"0.00000000","descr":{"pair":"ETHUSDC","type":"buy","price":"3000.0"}}
+		}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "c2VjcmV0")
+	client.SetBaseURL(server.URL)
+
+	orders, err := client.GetOpenOrders()
+	if err != nil {
+		t.Fatalf("GetOpenOrders: %v", err)
+	}
+
+	// Seuls les ordres XBTUSDC sont retenus, avec la quantité restant à exécuter
+	if len(orders) != 1 {
+		t.Fatalf("%d ordres ouverts, attendu 1: %+v", len(orders), orders)
+	}
+	got := orders[0]
+	if got.ID != "OQCLML-BW3P3-BUCMWZ" || got.Side != "BUY" || got.Price != 60000 || got.Quantity < 0.00099999 || got.Quantity > 0.00100001 {
+		t.Errorf("ordre ouvert inattendu: %+v", got)
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/0/public/AssetPairs" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"error":[],"result":{"XBTUSDC":{"altname":"XBTUSDC","pair_decimals":1,"lot_decimals":8,"tick_size":"0.1","ordermin":"0.00005"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "c2VjcmV0")
+	client.SetBaseURL(server.URL)
+
+	precision := client.Precision()
+	if precision.PriceTick != 0.1 || precision.QuantityStep != 0.00000001 {
+		t.Fatalf("précision = %+v, attendu pas de prix 0.1 et de quantité 0.00000001", precision)
+	}
+	if got := client.FormatPrice(60600.06); got != "60600.1" {
+		t.Errorf("FormatPrice = %s, attendu 60600.1", got)
+	}
+}
+
+func TestCreateOrderKeepsReserve(t *testing.T) {
+	var volume string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/private/BalanceEx":
+			w.Write([]byte(`{"error":[],"result":{"XXBT":{"balance":"0.0500000000","hold_trade":"0"},"USDC":{"balance":"1000.00","hold_trade":"0"}}}`))
+		case "/0/public/AssetPairs":
+			w.Write([]byte(`{"error":[],"result":{"XBTUSDC":{"pair_decimals":2,"lot_decimals":8,"tick_size":"0.01"}}}`))
+		case "/0/private/AddOrder":
+			r.ParseForm()
+			volume = r.PostForm.Get("volume")
+			w.Write([]byte(`{"error":[],"result":{"descr":{"order":"sell"},"txid":["OUF4EM-FRGI2-MQMWZD"]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "c2VjcmV0")
+	client.SetBaseURL(server.URL)
+	client.SetReserve(common.Reserve{BTC: 0.03, USDC: 400})
+
+	// 0.05 BTC libres dont 0.03 réservés: la vente est ramenée à 99 % des 0.02 utilisables
+	if _, err := client.CreateOrder("SELL", "60000", "0.05"); err != nil {
+		t.Fatalf("CreateOrder(SELL): %v", err)
+	}
+	if volume != "0.01980000" {
+		t.Errorf("volume de la vente = %s, attendu 0.01980000", volume)
+	}
+
+	// 600 USDC utilisables à 60000: l'achat de 0.02 BTC (1200 USDC) est ramené à 0.0099 BTC
+	if _, err := client.CreateOrder("BUY", "60000", "0.02"); err != nil {
+		t.Fatalf("CreateOrder(BUY): %v", err)
+	}
+	if volume != "0.00990000" {
+		t.Errorf("volume de l'achat = %s, attendu 0.00990000", volume)
+	}
+}
+
+func TestPairResolution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/public/AssetPairs":
+			w.Write([]byte(`{"error":[],"result":{
+				"XBTUSDC":{"altname":"XBTUSDC","wsname":"XBT/USDC","base":"XXBT","quote":"USDC"},
+				"XXBTZUSDT":{"altname":"XBTUSDT","wsname":"XBT/USDT","base":"XXBT","quote":"USDT","pair_decimals":1,"lot_decimals":8,"tick_size":"0.1"},
+				"ETHUSDT":{"altname":"ETHUSDT","wsname":"ETH/USDT","base":"XETH","quote":"USDT"}
+			}}`))
+		case "/0/public/Ticker":
+			if r.URL.Query().Get("pair") != "XBTUSDT" {
+				t.Errorf("Ticker demandé pour %q, attendu XBTUSDT", r.URL.Query().Get("pair"))
+			}
+			// Le ticker est indexé par la clé de la paire, différente de son altname
+			w.Write([]byte(`{"error":[],"result":{"XXBTZUSDT":{"a":["67310.1","1","1.000"],"b":["67305.1","2","2.000"],"c":["67308.4","0.0012"]}}}`))
+		case "/0/public/Time":
+			w.Write([]byte(`{"error":[],"result":{"unixtime":1729172947}}`))
+		case "/0/private/BalanceEx":
+			w.Write([]byte(`{"error":[],"result":{"XXBT":{"balance":"0.01","hold_trade":"0"},"USDT":{"balance":"500","hold_trade":"0"},"USDC":{"balance":"900","hold_trade":"0"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "c2VjcmV0")
+	client.SetBaseURL(server.URL)
+	client.SetPair("BTC/USDT")
+
+	if price := client.GetLastPriceBTC(); price != 67308.4 {
+		t.Errorf("GetLastPriceBTC() = %v, attendu 67308.4", price)
+	}
+	if got := client.FormatPrice(67308.44); got != "67308.4" {
+		t.Errorf("FormatPrice = %s, attendu 67308.4 (pas de prix de XBT/USDT)", got)
+	}
+	// Le solde de la devise de cotation tient lieu de solde USDC
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		t.Fatalf("GetDetailedBalances: %v", err)
+	}
+	if balances["USDC"].Free != 500 {
+		t.Errorf("solde de cotation = %+v, attendu les 500 USDT", balances["USDC"])
+	}
+
+	// Une paire absente est signalée avec les paires XBT en USDC/USDT disponibles
+	client.SetPair("XBT/EURC")
+	err = client.CheckConnection()
+	if err == nil || !strings.Contains(err.Error(), "XBT/EURC") || !strings.Contains(err.Error(), "XBT/USDC, XBT/USDT") {
+		t.Errorf("erreur de paire inattendue: %v", err)
+	}
+}
+
+func TestDecodeOrderCreated(t *testing.T) {
+	cases := []struct {
+		name, body, orderId, err string
+	}{
+		{"résultat AddOrder", `{"descr":{"order":"buy 0.00150000 XBTUSDC @ limit 66000.0"},"txid":["OUF4EM-FRGI2-MQMWZD"]}`, "OUF4EM-FRGI2-MQMWZD", ""},
+		{"enveloppe complète", `{"error":[],"result":{"descr":{"order":"buy 0.00150000 XBTUSDC @ limit 66000.0"},"txid":["OUF4EM-FRGI2-MQMWZD"]}}`, "OUF4EM-FRGI2-MQMWZD", ""},
+		{"fonds insuffisants", `{"error":["EOrder:Insufficient funds"]}`, "", "EOrder:Insufficient funds"},
+		{"sans txid", `{"descr":{"order":"buy 0.00150000 XBTUSDC @ limit 66000.0"},"txid":[]}`, "", "aucun ID d'ordre"},
+	}
+	for _, c := range cases {
+		result, err := decodeOrderCreated([]byte(c.body))
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: erreur %v, attendu %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil || result.OrderID != c.orderId || string(result.Raw) != c.body {
+			t.Errorf("%s: ID %q (erreur %v), attendu %q", c.name, result.OrderID, err, c.orderId)
+		}
+	}
+}
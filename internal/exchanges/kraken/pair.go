@@ -0,0 +1,127 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultPair est la paire négociée par défaut, au format wsname de Kraken
+const DefaultPair = "XBT/USDC"
+
+// pairInfo décrit une paire de la réponse AssetPairs
+type pairInfo struct {
+	Key          string `json:"-"`       // clé de la paire dans les réponses (XBTUSDC, XXBTZUSD...)
+	Altname      string `json:"altname"` // nom accepté par AddOrder et repris dans descr.pair
+	WSName       string `json:"wsname"`  // nom lisible: XBT/USDC
+	Base         string `json:"base"`
+	Quote        string `json:"quote"`
+	TickSize     string `json:"tick_size"`
+	PairDecimals int    `json:"pair_decimals"`
+	LotDecimals  int    `json:"lot_decimals"`
+}
+
+// pairCache conserve la paire résolue par AssetPairs. Un échec n'est pas conservé: la
+// résolution est retentée à l'appel suivant.
+type pairCache struct {
+	mu   sync.Mutex
+	pair *pairInfo
+}
+
+// SetPair définit la paire négociée (XBT/USDC, XBT/USDT); BTC est accepté pour XBT
+func (c *Client) SetPair(pair string) {
+	c.Pair = pair
+	c.pairs.mu.Lock()
+	c.pairs.pair = nil
+	c.pairs.mu.Unlock()
+}
+
+// configuredPair retourne la paire configurée au format wsname de Kraken
+func (c *Client) configuredPair() string {
+	pair := strings.ToUpper(strings.TrimSpace(c.Pair))
+	if pair == "" {
+		return DefaultPair
+	}
+	base, quote, _ := strings.Cut(pair, "/")
+	if base == "BTC" {
+		base = "XBT"
+	}
+	return base + "/" + quote
+}
+
+// quoteAsset retourne la devise de cotation de la paire configurée (USDC, USDT). Les montants
+// présentés par le bot comme des USDC sont exprimés dans cette devise.
+func (c *Client) quoteAsset() string {
+	_, quote, _ := strings.Cut(c.configuredPair(), "/")
+	return quote
+}
+
+// pair retourne la paire configurée telle que Kraken la nomme, lue une fois dans AssetPairs
+func (c *Client) pair() (pairInfo, error) {
+	c.pairs.mu.Lock()
+	defer c.pairs.mu.Unlock()
+	if c.pairs.pair != nil {
+		return *c.pairs.pair, nil
+	}
+
+	data, err := c.sendPublicRequest("GET", "AssetPairs", nil)
+	if err != nil {
+		return pairInfo{}, fmt.Errorf("erreur lors de la récupération des paires Kraken: %w", err)
+	}
+	pair, err := findPair(data, c.configuredPair())
+	if err != nil {
+		return pairInfo{}, err
+	}
+	c.pairs.pair = &pair
+	c.logDebug("Paire %s résolue: clé %s, altname %s", c.configuredPair(), pair.Key, pair.Altname)
+	return pair, nil
+}
+
+// findPair retrouve une paire (XBT/USDC) dans la réponse AssetPairs par son wsname, son
+// altname ou sa clé. Une paire absente est signalée avec la liste des paires XBT cotées en
+// USDC ou USDT disponibles.
+func findPair(data []byte, wanted string) (pairInfo, error) {
+	var pairs map[string]pairInfo
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return pairInfo{}, fmt.Errorf("erreur lors du décodage des paires: %w", err)
+	}
+
+	base, quote, _ := strings.Cut(wanted, "/")
+	altname := base + quote
+	var available []string
+	for key, pair := range pairs {
+		pair.Key = key
+		if pair.Altname == "" {
+			pair.Altname = key
+		}
+		if pair.WSName == wanted || pair.Altname == altname || key == altname {
+			return pair, nil
+		}
+		if krakenBitcoin(pair.Base) && (pair.Quote == "USDC" || pair.Quote == "USDT") {
+			name := pair.WSName
+			if name == "" {
+				name = pair.Altname
+			}
+			available = append(available, name)
+		}
+	}
+
+	if len(available) == 0 {
+		return pairInfo{}, fmt.Errorf("paire %s introuvable sur Kraken (KRAKEN_PAIR) et aucune paire XBT en USDC ou USDT disponible", wanted)
+	}
+	sort.Strings(available)
+	return pairInfo{}, fmt.Errorf("paire %s introuvable sur Kraken (KRAKEN_PAIR); paires XBT disponibles en USDC/USDT: %s",
+		wanted, strings.Join(available, ", "))
+}
+
+// krakenBitcoin indique si un code d'actif Kraken désigne le BTC
+func krakenBitcoin(code string) bool {
+	return code == "XXBT" || code == "XBT"
+}
+
+// matches indique si un nom de paire d'une réponse (clé, altname ou wsname) désigne la paire
+func (p pairInfo) matches(name string) bool {
+	return name != "" && (name == p.Key || name == p.Altname || name == p.WSName)
+}
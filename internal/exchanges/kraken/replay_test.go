@@ -0,0 +1,124 @@
+package kraken
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/testutil"
+)
+
+// testSecret est une clé secrète factice, encodée en base64 comme celles de Kraken
+var testSecret = base64.StdEncoding.EncodeToString([]byte("kraken-test-secret"))
+
+// verifySignature contrôle API-Key et API-Sign (HMAC-SHA512 de chemin + SHA256(nonce + corps))
+// des requêtes privées
+func verifySignature(r *http.Request, body []byte) error {
+	if strings.Contains(r.URL.Path, "/public/") {
+		return nil
+	}
+	if r.Header.Get("API-Key") != "key" {
+		return fmt.Errorf("en-tête API-Key = %q", r.Header.Get("API-Key"))
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Errorf("corps invalide: %v", err)
+	}
+	if values.Get("nonce") == "" {
+		return fmt.Errorf("nonce absent de %q", body)
+	}
+	if want := NewClient("key", testSecret).signature(r.URL.Path, values); r.Header.Get("API-Sign") != want {
+		return fmt.Errorf("signature %s, attendu %s", r.Header.Get("API-Sign"), want)
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	server := testutil.NewFakeServer(t, verifySignature,
+		testutil.Route{Method: "GET", Path: "/0/public/Ticker", File: "ticker.json"},
+		testutil.Route{Method: "GET", Path: "/0/public/AssetPairs", File: "asset_pairs.json"},
+		testutil.Route{Method: "POST", Path: "/0/private/BalanceEx", File: "balance_ex.json"},
+		testutil.Route{Method: "POST", Path: "/0/private/OpenOrders", File: "open_orders.json"},
+		testutil.Route{Method: "POST", Path: "/0/private/QueryOrders", File: "query_orders.json"},
+		testutil.Route{Method: "POST", Path: "/0/private/AddOrder", File: "add_order.json"},
+		testutil.Route{Method: "POST", Path: "/0/private/TradeVolume", File: "trade_volume.json"},
+	)
+
+	client := NewClient("key", testSecret)
+	client.SetBaseURL(server.URL)
+
+	if price := client.GetLastPriceBTC(); price != 67308.4 {
+		t.Errorf("GetLastPriceBTC() = %v, attendu 67308.4", price)
+	}
+
+	// Les montants bloqués sont ceux de hold_trade; le staking (XXBT.S, XBT.M) et l'earn (USDC.F)
+	// sont hors de Free et de Total
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		t.Fatalf("GetDetailedBalances: %v", err)
+	}
+	if got := balances["USDC"]; math.Abs(got.Free-1101) > 1e-6 || math.Abs(got.Locked-99) > 1e-6 || got.Total != 1200 || got.Unavailable != 250 {
+		t.Errorf("solde USDC inattendu: %+v", got)
+	}
+	if got := balances["BTC"]; math.Abs(got.Free-0.003) > 1e-9 || got.Total != 0.005 || math.Abs(got.Unavailable-0.013) > 1e-9 {
+		t.Errorf("solde BTC inattendu: %+v", got)
+	}
+	if len(server.RequestsTo("POST", "/0/private/OpenOrders")) != 0 {
+		t.Error("les montants bloqués ne devraient plus être reconstitués depuis les ordres ouverts")
+	}
+
+	order, err := client.GetOrderById("OE2QDF-TOFCN-4TP7V2")
+	if err != nil {
+		t.Fatalf("GetOrderById: %v", err)
+	}
+	status, err := client.GetOrderStatus("OE2QDF-TOFCN-4TP7V2")
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if !status.Filled() || status.ID != "OE2QDF-TOFCN-4TP7V2" || status.ExecutedQty != 0.0015 || status.Fee != 0.25348 ||
+		math.Abs(status.AvgFillPrice-64995) > 1e-6 || !status.UpdatedAt.Equal(time.UnixMilli(1729100500568)) {
+		t.Errorf("état de l'ordre enregistré inattendu: %+v", status)
+	}
+	if !strings.Contains(string(order), `"limitPrice":"65000.0"`) {
+		t.Errorf("prix limite absent de l'ordre: %s", order)
+	}
+	if !strings.Contains(string(order), `"closetm":1729100500.5678`) {
+		t.Errorf("date de clôture absente de l'ordre: %s", order)
+	}
+
+	result, err := client.CreateOrder("BUY", "66000.0", "0.0015", common.OrderOptions{ClientOrderID: "cyc-7-buy"})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if result.OrderID != "OUF4EM-FRGI2-MQMWZD" {
+		t.Errorf("ID d'ordre créé %q inattendu (réponse: %s)", result.OrderID, result.Raw)
+	}
+
+	created := server.RequestsTo("POST", "/0/private/AddOrder")
+	if len(created) != 1 {
+		t.Fatalf("%d créations d'ordre, attendu 1", len(created))
+	}
+	// L'identifiant client est transmis sous forme de référence numérique
+	userref := "userref=" + strconv.Itoa(int(common.ClientOrderRef("cyc-7-buy")))
+	for _, param := range []string{"pair=XBTUSDC", "type=buy", "ordertype=limit", "price=66000.0", "volume=0.0015", userref} {
+		if !strings.Contains(string(created[0].Body), param) {
+			t.Errorf("paramètre %s absent de %q", param, created[0].Body)
+		}
+	}
+	// TradeVolume exprime les taux en pourcentage
+	rates, err := client.GetAccountFeeRates()
+	if err != nil {
+		t.Fatalf("GetAccountFeeRates: %v", err)
+	}
+	if math.Abs(rates.Maker-0.0015) > 1e-12 || math.Abs(rates.Taker-0.0035) > 1e-12 {
+		t.Errorf("taux de frais inattendus: %+v", rates)
+	}
+}
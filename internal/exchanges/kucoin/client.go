@@ -13,8 +13,10 @@ import (
 	"math"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/buger/jsonparser"
@@ -38,6 +40,9 @@ type SymbolRules struct {
 
 var symbolRulesCache = make(map[string]SymbolRules)
 
+// DefaultHTTPTimeout est le délai des requêtes HTTP vers KuCoin sans KUCOIN_HTTP_TIMEOUT_SECONDS
+const DefaultHTTPTimeout = 15 * time.Second
+
 // Client représente un client API pour l'échange KuCoin
 type Client struct {
 	APIKey     string
@@ -45,6 +50,30 @@ type Client struct {
 	Passphrase string
 	BaseURL    string
 	Debug      bool
+	// Écart en pourcentage pour les ordres maker (0 = 0.2%)
+	MakerBufferPercent float64
+	// Taux de frais utilisés pour les estimations quand les frais réels sont inconnus
+	FeeRates common.FeeRates
+	// Délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+	HTTPTimeout time.Duration
+	// Décalage avec l'horloge de KuCoin, mesuré au premier rejet d'horodatage
+	clock common.ClockSkew
+	// Pas de prix et de quantité de BTC-USDC, lus au premier ordre
+	precision common.PrecisionCache
+	// Frais des ordres terminés, lus une seule fois dans leurs exécutions (/api/v1/fills)
+	fillsMu    sync.Mutex
+	fillsCache map[string]fillFees
+}
+
+// maintenanceCodes sont les codes d'erreur KuCoin annonçant une maintenance du service
+var maintenanceCodes = map[string]bool{
+	"130101": true,
+}
+
+// isMaintenanceResponse indique si le corps d'une réponse en erreur porte un code de maintenance
+func isMaintenanceResponse(body []byte) bool {
+	var response kuCoinResponse
+	return json.Unmarshal(body, &response) == nil && maintenanceCodes[response.Code]
 }
 
 // Réponse standardisée de KuCoin
@@ -93,6 +122,7 @@ func NewClient(apiKey, apiSecret string) *Client {
 		Passphrase: passphrase,
 		BaseURL:    "https://api.kucoin.com",
 		Debug:      false,
+		FeeRates:   common.FeeRates{Maker: 0.001, Taker: 0.001},
 	}
 }
 
@@ -101,6 +131,29 @@ func (c *Client) SetBaseURL(url string) {
 	c.BaseURL = url
 }
 
+// SetMakerBufferPercent définit l'écart en pourcentage utilisé par CreateMakerOrder (0 = 0.2%)
+func (c *Client) SetMakerBufferPercent(percent float64) {
+	c.MakerBufferPercent = percent
+}
+
+// SetFeeRates définit les taux maker et taker utilisés pour estimer les frais
+func (c *Client) SetFeeRates(rates common.FeeRates) {
+	c.FeeRates = rates
+}
+
+// SetHTTPTimeout définit le délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+func (c *Client) SetHTTPTimeout(timeout time.Duration) {
+	c.HTTPTimeout = timeout
+}
+
+// httpTimeout retourne le délai effectif des requêtes HTTP
+func (c *Client) httpTimeout() time.Duration {
+	if c.HTTPTimeout > 0 {
+		return c.HTTPTimeout
+	}
+	return DefaultHTTPTimeout
+}
+
 // SetDebug active ou désactive le mode debug
 func (c *Client) SetDebug(debug bool) {
 	c.Debug = debug
@@ -128,16 +181,92 @@ func (c *Client) signPassphrase() string {
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
 
-// Envoie une requête HTTP à l'API KuCoin
+// sendRequest envoie une requête à l'API KuCoin. Si KuCoin rejette KC-API-TIMESTAMP (code 400002,
+// horloge locale décalée), le décalage est mesuré sur l'heure du serveur, appliqué aux requêtes
+// suivantes de la session, et la requête est renvoyée une fois corrigée.
 func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	signature := c.signRequest(timestamp, method, endpoint, body)
+	data, err := c.doRequest(method, endpoint, body)
+	if err == nil || !strings.Contains(err.Error(), "400002") {
+		return data, err
+	}
 
-	// Construire l'URL complète
-	fullURL := c.BaseURL + endpoint
+	offset, syncErr := c.clock.Sync(c.ServerTime)
+	if syncErr != nil {
+		return nil, fmt.Errorf("%w (heure du serveur indisponible: %v)", err, syncErr)
+	}
+	color.Yellow("Horloge locale décalée de %s par rapport à KuCoin: correction appliquée pour la session", offset.Round(time.Millisecond))
+
+	return c.doRequest(method, endpoint, body)
+}
+
+// ServerTime retourne l'heure du serveur KuCoin
+func (c *Client) ServerTime() (time.Time, error) {
+	data, err := c.doRequest("GET", "/api/v1/timestamp", "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	serverTime, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("réponse /api/v1/timestamp invalide: %w", err)
+	}
+	return time.UnixMilli(serverTime), nil
+}
+
+// ClockOffset retourne la correction appliquée à KC-API-TIMESTAMP
+func (c *Client) ClockOffset() time.Duration {
+	return c.clock.Offset()
+}
+
+// GetOrderBook retourne le meilleur achat et la meilleure vente BTC-USDC (/api/v1/market/orderbook/level1)
+func (c *Client) GetOrderBook() (common.BookTop, error) {
+	data, err := c.sendRequest("GET", "/api/v1/market/orderbook/level1", "symbol=BTC-USDC")
+	if err != nil {
+		return common.BookTop{}, err
+	}
+
+	var ticker tickerResponse
+	if err := json.Unmarshal(data, &ticker); err != nil {
+		return common.BookTop{}, fmt.Errorf("erreur lors du décodage du ticker: %w", err)
+	}
+	bid, bidErr := strconv.ParseFloat(ticker.BestBid, 64)
+	ask, askErr := strconv.ParseFloat(ticker.BestAsk, 64)
+	if bidErr != nil || askErr != nil {
+		return common.BookTop{}, fmt.Errorf("meilleurs prix du ticker invalides: %s", string(data))
+	}
+	return common.BookTop{Bid: bid, Ask: ask}, nil
+}
+
+// kucoinRateLimitWindow est la fenêtre du quota de requêtes KuCoin (gw-ratelimit-reset la décompte)
+const kucoinRateLimitWindow = 30 * time.Second
+
+// recordRateLimit relève le quota de requêtes consommé dans la fenêtre courante
+// (gw-ratelimit-limit et gw-ratelimit-remaining)
+func recordRateLimit(header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("gw-ratelimit-limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("gw-ratelimit-remaining"))
+	if limitErr == nil && remainingErr == nil {
+		common.RateLimits.Record("KUCOIN", limit-remaining, limit, kucoinRateLimitWindow)
+	}
+}
+
+// GetRateLimitStatus retourne le quota de requêtes consommé relevé sur les dernières réponses
+func (c *Client) GetRateLimitStatus() common.RateLimitStatus {
+	return common.RateLimits.Status("KUCOIN")
+}
+
+// doRequest envoie une requête HTTP signée à l'API KuCoin
+func (c *Client) doRequest(method, endpoint string, body string) ([]byte, error) {
+	timestamp := c.clock.Timestamp()
+
+	// Pour un GET, les paramètres passent dans l'URL et sont signés avec le chemin (endpoint?query), sans corps
+	requestPath, requestBody := endpoint, body
 	if method == "GET" && body != "" {
-		fullURL += "?" + body
+		requestPath, requestBody = endpoint+"?"+body, ""
 	}
+	signature := c.signRequest(timestamp, method, requestPath, requestBody)
+
+	// Construire l'URL complète
+	fullURL := c.BaseURL + requestPath
 
 	if c.Debug {
 		c.logDebug("URL complète: %s", fullURL)
@@ -145,7 +274,7 @@ func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, erro
 	}
 
 	// Créer la requête
-	req, err := http.NewRequest(method, fullURL, strings.NewReader(body))
+	req, err := http.NewRequest(method, fullURL, strings.NewReader(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de la création de la requête: %w", err)
 	}
@@ -164,7 +293,8 @@ func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, erro
 
 	// Envoyer la requête
 	client := &http.Client{
-		Timeout: 15 * time.Second,
+		Timeout:   c.httpTimeout(),
+		Transport: common.MeteredTransport("KUCOIN"),
 	}
 
 	if c.Debug {
@@ -179,6 +309,7 @@ func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, erro
 		return nil, fmt.Errorf("erreur lors de l'envoi de la requête: %w", err)
 	}
 	defer resp.Body.Close()
+	recordRateLimit(resp.Header)
 
 	// Lire la réponse
 	responseBody, err := io.ReadAll(resp.Body)
@@ -192,7 +323,10 @@ func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, erro
 
 	// Vérifier le code de statut HTTP
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erreur HTTP %d: %s", resp.StatusCode, string(responseBody))
+		if common.IsMaintenanceStatus(resp.StatusCode) || isMaintenanceResponse(responseBody) {
+			return nil, fmt.Errorf("%w: %w", common.ErrMaintenance, common.NewHTTPStatusError("HTTP %d: %s", resp.StatusCode, string(responseBody)))
+		}
+		return nil, common.NewHTTPStatusError("erreur HTTP %d: %s", resp.StatusCode, string(responseBody))
 	}
 
 	// Décoder la réponse
@@ -203,6 +337,9 @@ func (c *Client) sendRequest(method, endpoint string, body string) ([]byte, erro
 
 	// Vérifier le code de la réponse
 	if response.Code != "200000" {
+		if maintenanceCodes[response.Code] {
+			return nil, fmt.Errorf("%w: %s - %s", common.ErrMaintenance, response.Code, response.Message)
+		}
 		return nil, fmt.Errorf("erreur API KuCoin: %s - %s", response.Code, response.Message)
 	}
 
@@ -270,8 +407,8 @@ func (c *Client) normalizeOrderId(orderId string) string {
 }
 
 // CreateOrder crée un nouvel ordre sur KuCoin
-// Modification de la méthode CreateOrder pour utiliser FormatPrice
-func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
+// Modification de la méthode CreateOrder pour utiliser formatSymbolPrice
+func (c *Client) CreateOrder(side, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
 	endpoint := "/api/v1/orders"
 
 	// Adapter le side pour KuCoin (buy/sell au lieu de BUY/SELL)
@@ -281,16 +418,22 @@ func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
 	if _, err := strconv.ParseFloat(price, 64); err == nil {
 		// Le prix est fourni en tant que chaîne, vérifier s'il est correctement formaté
 		priceValue, _ := strconv.ParseFloat(price, 64)
-		formattedPrice, err := c.FormatPrice("BTC-USDC", priceValue)
+		formattedPrice, err := c.formatSymbolPrice("BTC-USDC", priceValue)
 		if err == nil && formattedPrice != price {
 			c.logDebug("Reformatage du prix: %s -> %s", price, formattedPrice)
 			price = formattedPrice
 		}
 	}
 
+	// ID client fourni par le bot, sinon ID unique généré ici
+	clientOid := common.ClientOrderIDRequested(opts)
+	if clientOid == "" {
+		clientOid = fmt.Sprintf("bot-%d", time.Now().UnixNano())
+	}
+
 	// Créer le corps de la requête
-	orderData := map[string]string{
-		"clientOid":   fmt.Sprintf("bot-%d", time.Now().UnixNano()), // ID unique généré côté client
+	orderData := map[string]interface{}{
+		"clientOid":   clientOid,
 		"side":        kuSide,
 		"symbol":      "BTC-USDC",
 		"type":        "limit",
@@ -298,19 +441,81 @@ func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
 		"size":        quantity,
 		"timeInForce": "GTC", // Good Till Canceled
 	}
+	postOnly := common.PostOnlyRequested(opts)
+	if postOnly {
+		orderData["postOnly"] = true
+	}
+	// Expiration côté exchange: Good Till Time, annulé après cancelAfter secondes
+	if expireAt := common.ExpiryRequested(opts); !expireAt.IsZero() {
+		if seconds := int64(time.Until(expireAt).Seconds()); seconds > 0 {
+			orderData["timeInForce"] = "GTT"
+			orderData["cancelAfter"] = seconds
+		}
+	}
+	// Ordre au marché: la taille seule suffit, sans prix ni durée de validité
+	if common.MarketRequested(opts) {
+		delete(orderData, "cancelAfter")
+		orderData["type"] = "market"
+		delete(orderData, "price")
+		delete(orderData, "timeInForce")
+	}
 
 	jsonData, err := json.Marshal(orderData)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la création du JSON pour l'ordre: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de la création du JSON pour l'ordre: %w", err)
 	}
 
 	// Envoyer la requête
 	data, err := c.sendRequest("POST", endpoint, string(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de l'envoi de l'ordre: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de l'envoi de l'ordre: %w", err)
 	}
 
-	return data, nil
+	result, err := decodeOrderCreated(data)
+	if err != nil {
+		return common.OrderCreateResult{}, err
+	}
+
+	// KuCoin accepte l'ordre post-only puis l'annule aussitôt s'il devait s'exécuter immédiatement
+	if postOnly && c.postOnlyCanceled(result.OrderID) {
+		return common.OrderCreateResult{}, common.ErrPostOnlyWouldMatch
+	}
+
+	return result, nil
+}
+
+// decodeOrderCreated extrait l'ID de la réponse de POST /api/v1/orders. L'enveloppe
+// {"code":"200000","data":{"orderId":...}} est normalement retirée par doRequest mais reste
+// acceptée; une enveloppe d'erreur ({"code":"400100","msg":"..."}) est retournée comme erreur.
+func decodeOrderCreated(body []byte) (common.OrderCreateResult, error) {
+	if orderId := common.JSONID(body, "orderId"); orderId != "" {
+		return common.NewOrderCreateResult(orderId, body)
+	}
+	if orderId := common.JSONID(body, "data", "orderId"); orderId != "" {
+		return common.NewOrderCreateResult(orderId, body)
+	}
+	if code, err := jsonparser.GetString(body, "code"); err == nil && code != "200000" {
+		msg, _ := jsonparser.GetString(body, "msg")
+		return common.OrderCreateResult{}, fmt.Errorf("erreur API KuCoin: %s - %s", code, msg)
+	}
+	return common.NewOrderCreateResult("", body)
+}
+
+// postOnlyCanceled indique si l'ordre post-only qui vient d'être créé a été annulé
+// par KuCoin sans aucune exécution
+func (c *Client) postOnlyCanceled(orderId string) bool {
+	order, err := c.GetOrderById(orderId)
+	if err != nil {
+		c.logDebug("Impossible de vérifier l'ordre post-only %s: %v", orderId, err)
+		return false
+	}
+
+	isActive, _ := jsonparser.GetBoolean(order, "isActive")
+	cancelExist, _ := jsonparser.GetBoolean(order, "cancelExist")
+	dealSize, _ := jsonparser.GetString(order, "dealSize")
+	dealSizeFloat, _ := strconv.ParseFloat(dealSize, 64)
+
+	return !isActive && cancelExist && dealSizeFloat == 0
 }
 
 // GetOrderById récupère les informations d'un ordre spécifique
@@ -366,25 +571,72 @@ func (c *Client) findOrderInHistory(orderId string) ([]byte, error) {
 	return nil, fmt.Errorf("ordre non trouvé dans l'historique: %s", orderId)
 }
 
-// IsFilled vérifie si un ordre est complètement exécuté
-func (c *Client) IsFilled(order string) bool {
-	var orderData map[string]interface{}
-	if err := json.Unmarshal([]byte(order), &orderData); err != nil {
-		c.logDebug("Erreur lors du décodage de l'ordre: %v", err)
-		return false
+// GetOrderStatus récupère un ordre et en interprète l'état
+func (c *Client) GetOrderStatus(id string) (common.OrderStatus, error) {
+	order, err := c.GetOrderById(id)
+	if err != nil {
+		return common.OrderStatus{}, err
 	}
-
-	// Vérifier si l'ordre est complété
-	if isActive, ok := orderData["isActive"].(bool); ok && !isActive {
-		// Vérifier si la quantité exécutée est égale à la quantité totale
-		if dealSize, ok := orderData["dealSize"].(string); ok {
-			if size, ok := orderData["size"].(string); ok {
-				return dealSize == size
-			}
+	status := parseOrderStatus(order)
+	// Les exécutions détaillent les frais, y compris ceux prélevés en BTC ou en KCS
+	if status.Filled() {
+		if fees, err := c.orderFills(c.normalizeOrderId(id), true); err == nil {
+			status.Fee, status.BaseFee = fees.quote, fees.base
+			status.FeeAsset, status.FeeAssetAmount = fees.asset, fees.assetAmount
 		}
 	}
+	return status, nil
+}
+
+// parseOrderStatus interprète une réponse d'ordre KuCoin. Un ordre inactif est exécuté
+// lorsque dealSize atteint size, annulé sinon.
+func parseOrderStatus(order []byte) common.OrderStatus {
+	dealSize := common.OrderFloat(order, "dealSize")
+	size := common.OrderFloat(order, "size")
+	isActive, _ := jsonparser.GetBoolean(order, "isActive")
+
+	result := common.OrderStatus{
+		ExecutedQty:  dealSize,
+		AvgFillPrice: common.AveragePrice(common.OrderFloat(order, "dealFunds"), dealSize),
+	}
+	result.ID, _ = jsonparser.GetString(order, "id")
+	if feeCurrency, _ := jsonparser.GetString(order, "feeCurrency"); feeCurrency == "USDC" {
+		result.Fee = common.OrderFloat(order, "fee")
+	}
+	// KuCoin ne fournit que la date de création de l'ordre
+	if createdAt := common.OrderFloat(order, "createdAt"); createdAt > 0 {
+		result.UpdatedAt = time.UnixMilli(int64(createdAt))
+	}
 
-	return false
+	switch {
+	case isActive && dealSize > 0:
+		result.State = common.OrderPartiallyFilled
+	case isActive:
+		result.State = common.OrderOpen
+	case size > 0 && dealSize >= size:
+		result.State = common.OrderFilled
+	default:
+		result.State = common.OrderCancelled
+		result.Expired = dealSize == 0 && orderExpired(order)
+	}
+	return result
+}
+
+// orderExpired indique si un ordre GTT inactif a atteint son délai cancelAfter: KuCoin ne
+// distingue pas dans son statut une expiration d'une annulation
+func orderExpired(order []byte) bool {
+	cancelAfter := common.OrderFloat(order, "cancelAfter")
+	createdAt := common.OrderFloat(order, "createdAt")
+	if cancelAfter <= 0 || createdAt <= 0 {
+		return false
+	}
+	expireAt := time.UnixMilli(int64(createdAt)).Add(time.Duration(cancelAfter) * time.Second)
+	return !time.Now().Before(expireAt)
+}
+
+// SupportsOrderExpiry indique que KuCoin accepte les ordres Good Till Time (cancelAfter)
+func (c *Client) SupportsOrderExpiry() bool {
+	return true
 }
 
 // CancelOrder annule un ordre existant sur KuCoin
@@ -407,6 +659,31 @@ func (c *Client) CancelOrder(orderID string) ([]byte, error) {
 	return data, nil
 }
 
+// CancelOrderIdempotent annule un ordre. KuCoin répond avec le code 400100 et un message
+// "order not exist" (ou "cannot be canceled") pour un ordre déjà exécuté ou annulé.
+func (c *Client) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	_, err := c.CancelOrder(orderID)
+	if err == nil {
+		return common.Cancelled, nil
+	}
+
+	// L'erreur est soit le corps JSON d'une réponse HTTP en erreur, soit "erreur API KuCoin: code - message"
+	text := strings.ToLower(err.Error())
+	if strings.Contains(text, "400100") {
+		for _, phrase := range []string{"not exist", "not_exist", "cannot be canceled", "cannot be cancelled"} {
+			if strings.Contains(text, phrase) {
+				return common.AlreadyGone, nil
+			}
+		}
+	}
+	return common.CancelFailed, err
+}
+
+// CreateOCOOrder n'est pas disponible: KuCoin ne propose pas d'ordre OCO natif sur le spot
+func (c *Client) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	return common.OCOOrder{}, common.ErrOCONotSupported
+}
+
 // GetExchangeInfo récupère les informations de l'échange
 func (c *Client) GetExchangeInfo() ([]byte, error) {
 	data, err := c.sendRequest("GET", "/api/v1/symbols", "")
@@ -416,6 +693,32 @@ func (c *Client) GetExchangeInfo() ([]byte, error) {
 	return data, nil
 }
 
+// PairStatus lit l'état de négociation de BTC-USDC (enableTrading) dans la liste des paires
+func (c *Client) PairStatus() (common.PairStatus, error) {
+	data, err := c.GetExchangeInfo()
+	if err != nil {
+		return common.PairStatus{}, err
+	}
+	var symbols []struct {
+		Symbol        string `json:"symbol"`
+		EnableTrading bool   `json:"enableTrading"`
+	}
+	if err := json.Unmarshal(data, &symbols); err != nil {
+		return common.PairStatus{}, fmt.Errorf("erreur lors du décodage des informations de l'échange: %w", err)
+	}
+	for _, symbol := range symbols {
+		if symbol.Symbol != "BTC-USDC" {
+			continue
+		}
+		if symbol.EnableTrading {
+			return common.PairStatus{Status: "enabled", Trading: true}, nil
+		}
+		return common.PairStatus{Status: "disabled"}, nil
+	}
+	// Paire absente de la liste: retirée de la cote
+	return common.PairStatus{Status: "delisted"}, nil
+}
+
 // GetAccountInfo récupère les informations du compte
 func (c *Client) GetAccountInfo() ([]byte, error) {
 	data, err := c.sendRequest("GET", "/api/v1/accounts", "")
@@ -507,27 +810,34 @@ func (c *Client) GetBalanceUSD() float64 {
 }
 
 // CreateMakerOrder crée un ordre en mode maker
-func (c *Client) CreateMakerOrder(side string, price float64, quantity string) ([]byte, error) {
+func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
+	// L'écart ne doit jamais être inférieur à l'incrément de prix, sinon il disparaît à l'arrondi
+	tickSize := 0.01
+	if rules, err := c.GetSymbolRules("BTC-USDC"); err == nil && rules.PriceIncrement > 0 {
+		tickSize = rules.PriceIncrement
+	}
+	offset := common.MakerPriceOffset(price, c.MakerBufferPercent, common.DefaultMakerOrderBufferPercent, tickSize)
+
 	// Ajuster le prix pour s'assurer d'être maker
 	var adjustedPrice float64
 	if strings.ToUpper(side) == "BUY" {
 		// Pour un achat, placer l'ordre légèrement en dessous du marché
-		adjustedPrice = price * 0.998 // 0.2% en dessous
+		adjustedPrice = price - offset
 	} else {
 		// Pour une vente, placer l'ordre légèrement au-dessus du marché
-		adjustedPrice = price * 1.002 // 0.2% au-dessus
+		adjustedPrice = price + offset
 	}
 
 	// Formater le prix selon les règles de précision de KuCoin
-	adjustedPriceStr, err := c.FormatPrice("BTC-USDC", adjustedPrice)
+	adjustedPriceStr, err := c.formatSymbolPrice("BTC-USDC", adjustedPrice)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors du formatage du prix: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors du formatage du prix: %w", err)
 	}
 
 	// Pour debug, afficher le prix formaté
 	c.logDebug("Prix ajusté pour maker: %f -> %s", adjustedPrice, adjustedPriceStr)
 
-	return c.CreateOrder(side, adjustedPriceStr, quantity)
+	return c.CreateOrder(side, adjustedPriceStr, quantity, common.OrderOptions{PostOnly: true})
 }
 
 func (c *Client) GetSymbolRules(symbol string) (SymbolRules, error) {
@@ -582,8 +892,30 @@ func parseFloat(s string) float64 {
 	return f
 }
 
-// FormatPrice formate un prix selon les règles de précision d'une paire de trading
-func (c *Client) FormatPrice(symbol string, price float64) (string, error) {
+// Precision retourne le pas de prix (priceIncrement) et de quantité (baseIncrement) de BTC-USDC,
+// DefaultPrecision si les règles ne peuvent pas être lues
+func (c *Client) Precision() common.Precision {
+	return c.precision.Get(func() (common.Precision, error) {
+		rules, err := c.GetSymbolRules("BTC-USDC")
+		if err != nil {
+			return common.Precision{}, err
+		}
+		return common.Precision{PriceTick: rules.PriceIncrement, QuantityStep: rules.BaseIncrement}, nil
+	})
+}
+
+// FormatPrice formate un prix au pas de prix de BTC-USDC
+func (c *Client) FormatPrice(price float64) string {
+	return c.Precision().FormatPrice(price)
+}
+
+// FormatQuantity formate une quantité au pas de quantité de BTC-USDC
+func (c *Client) FormatQuantity(quantity float64) string {
+	return c.Precision().FormatQuantity(quantity)
+}
+
+// formatSymbolPrice formate un prix selon les règles de précision d'une paire de trading
+func (c *Client) formatSymbolPrice(symbol string, price float64) (string, error) {
 	rules, err := c.GetSymbolRules(symbol)
 	if err != nil {
 		return "", err
@@ -612,7 +944,73 @@ func (c *Client) FormatPrice(symbol string, price float64) (string, error) {
 	return strconv.FormatFloat(roundedPrice, 'f', precision, 64), nil
 }
 
-// GetOrderFees récupère les frais appliqués à un ordre spécifique
+// fillFees sont les frais des exécutions d'un ordre
+type fillFees struct {
+	quote       float64 // Frais en USDC, ceux prélevés en BTC comptés au prix d'exécution
+	base        float64 // Frais prélevés en BTC
+	asset       string  // Actif des frais payés hors BTC et USDC (KCS), vide sinon
+	assetAmount float64 // Frais payés dans cet actif, non comptés dans quote
+}
+
+// parseFills additionne les frais des exécutions d'une réponse de /api/v1/fills. Un ordre
+// exécuté en plusieurs fois a une exécution par contrepartie, chacune avec ses frais.
+func parseFills(data []byte) (fillFees, bool) {
+	var fees fillFees
+	found := false
+	_, _ = jsonparser.ArrayEach(data, func(fill []byte, dataType jsonparser.ValueType, offset int, _ error) {
+		currency, err := jsonparser.GetString(fill, "feeCurrency")
+		if err != nil {
+			return
+		}
+		found = true
+		fee := common.OrderFloat(fill, "fee")
+		switch currency {
+		case "USDC":
+			fees.quote += fee
+		case "BTC":
+			fees.base += fee
+			fees.quote += fee * common.OrderFloat(fill, "price")
+		default:
+			fees.asset = currency
+			fees.assetAmount += fee
+		}
+	}, "items")
+	return fees, found
+}
+
+// orderFills retourne les frais des exécutions d'un ordre. Ceux d'un ordre terminé (done) ne
+// changent plus: ils sont mémorisés et relus sans appel à KuCoin.
+func (c *Client) orderFills(orderId string, done bool) (fillFees, error) {
+	c.fillsMu.Lock()
+	fees, cached := c.fillsCache[orderId]
+	c.fillsMu.Unlock()
+	if cached {
+		return fees, nil
+	}
+
+	// La requête est incluse dans l'endpoint pour qu'elle soit prise en compte dans la signature
+	endpoint := fmt.Sprintf("/api/v1/fills?orderId=%s&tradeType=TRADE", orderId)
+	data, err := c.sendRequest("GET", endpoint, "")
+	if err != nil {
+		return fillFees{}, fmt.Errorf("erreur lors de la récupération des exécutions de l'ordre: %w", err)
+	}
+	fees, found := parseFills(data)
+	if !found {
+		return fillFees{}, fmt.Errorf("aucune exécution pour l'ordre %s", orderId)
+	}
+
+	if done {
+		c.fillsMu.Lock()
+		if c.fillsCache == nil {
+			c.fillsCache = make(map[string]fillFees)
+		}
+		c.fillsCache[orderId] = fees
+		c.fillsMu.Unlock()
+	}
+	return fees, nil
+}
+
+// GetOrderFees récupère les frais appliqués à un ordre spécifique, en USDC
 func (c *Client) GetOrderFees(orderId string) (float64, error) {
 	// Normaliser l'ID de l'ordre
 	normalizedId := c.normalizeOrderId(orderId)
@@ -620,6 +1018,14 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 		return 0, fmt.Errorf("ID d'ordre invalide: %s", orderId)
 	}
 
+	// Frais d'un ordre terminé déjà lus dans ses exécutions
+	c.fillsMu.Lock()
+	fees, cached := c.fillsCache[normalizedId]
+	c.fillsMu.Unlock()
+	if cached {
+		return fillsQuoteFees(normalizedId, fees)
+	}
+
 	// Pour KuCoin, nous devons récupérer les détails de l'ordre
 	// puis extraire les informations sur les frais
 	endpoint := fmt.Sprintf("/api/v1/orders/%s", normalizedId)
@@ -628,6 +1034,14 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 		return 0, fmt.Errorf("erreur lors de la récupération des détails de l'ordre: %w", err)
 	}
 
+	// Les exécutions donnent les frais réels, même quand la page de l'ordre ne les porte pas
+	if common.OrderFloat(data, "dealSize") > 0 {
+		isActive, _ := jsonparser.GetBoolean(data, "isActive")
+		if fees, err := c.orderFills(normalizedId, !isActive); err == nil {
+			return fillsQuoteFees(normalizedId, fees)
+		}
+	}
+
 	// Tenter d'extraire les frais directement
 	feesStr, err := jsonparser.GetString(data, "fee")
 	if err == nil {
@@ -646,9 +1060,7 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 		price, _ := strconv.ParseFloat(dealPrice, 64)
 
 		if size > 0 && price > 0 {
-			// Taux de frais standard de KuCoin (0.1%)
-			const feeRate = 0.001
-			return size * price * feeRate, nil
+			return size * price * c.FeeRates.Maker, nil
 		}
 	}
 
@@ -656,10 +1068,19 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 	return c.estimateOrderFees(data)
 }
 
+// fillsQuoteFees retourne les frais en USDC des exécutions d'un ordre. Les frais payés en KCS
+// ne sont pas convertis: l'erreur laisse l'appelant les estimer au taux configuré.
+func fillsQuoteFees(orderId string, fees fillFees) (float64, error) {
+	if fees.assetAmount > 0 {
+		return 0, fmt.Errorf("frais de l'ordre %s payés en %s, non convertis en USDC", orderId, fees.asset)
+	}
+	return fees.quote, nil
+}
+
 // estimateOrderFees estime les frais d'un ordre à partir des données brutes de l'ordre
 func (c *Client) estimateOrderFees(orderData []byte) (float64, error) {
-	// Taux de frais standard de KuCoin (0.1%)
-	const feeRate = 0.001
+	// Taux maker configuré (0.1% au niveau de base)
+	feeRate := c.FeeRates.Maker
 
 	// Extraire les valeurs nécessaires
 	var dealAmount float64
@@ -695,25 +1116,119 @@ func (c *Client) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyO
 	buyFees, err := c.GetOrderFees(buyOrderId)
 
 	// Si nous n'avons pas pu récupérer les frais, estimer avec le taux standard
-	if err != nil || buyFees <= 0 {
-		const feeRate = 0.001 // 0.1% pour KuCoin
-		buyFees = buyPrice * quantity * feeRate
+	if err != nil || buyFees == 0 {
+		buyFees = buyPrice * quantity * c.FeeRates.Maker
 	}
 
 	// Calculer les frais de vente estimés (même taux)
-	sellFees := buyPrice * quantity * 0.001
+	sellFees := buyPrice * quantity * c.FeeRates.Maker
 
-	// Total des frais à couvrir
-	totalFeesToCover := buyFees + sellFees
+	// Prix minimal pour couvrir les frais, avec une marge de sécurité de 5% hors remise maker
+	return common.FeeAdjustedSellPrice(buyPrice, quantity, buyFees, sellFees, 0.05), nil
+}
+
+// GetOpenOrders récupère les ordres BTC-USDC encore ouverts
+func (c *Client) GetOpenOrders() ([]common.OpenOrder, error) {
+	const pageSize = 500
+
+	var orders []common.OpenOrder
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("/api/v1/orders?status=active&symbol=BTC-USDC&currentPage=%d&pageSize=%d", page, pageSize)
+		data, err := c.sendRequest("GET", endpoint, "")
+		if err != nil {
+			return nil, fmt.Errorf("erreur lors de la récupération des ordres ouverts: %w", err)
+		}
+
+		_, _ = jsonparser.ArrayEach(data, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+			id, _ := jsonparser.GetString(value, "id")
+			side, _ := jsonparser.GetString(value, "side")
+			priceStr, _ := jsonparser.GetString(value, "price")
+			sizeStr, _ := jsonparser.GetString(value, "size")
+			dealSizeStr, _ := jsonparser.GetString(value, "dealSize")
+			clientOid, _ := jsonparser.GetString(value, "clientOid")
+			createdMs, _ := jsonparser.GetInt(value, "createdAt")
+
+			var createdAt time.Time
+			if createdMs > 0 {
+				createdAt = time.UnixMilli(createdMs)
+			}
+
+			orders = append(orders, common.OpenOrder{
+				ID:            id,
+				Side:          strings.ToUpper(side),
+				Price:         parseFloat(priceStr),
+				Quantity:      parseFloat(sizeStr) - parseFloat(dealSizeStr),
+				ClientOrderID: clientOid,
+				CreatedAt:     createdAt,
+			})
+		}, "items")
+
+		totalPage, err := jsonparser.GetInt(data, "totalPage")
+		if err != nil || int64(page) >= totalPage {
+			break
+		}
+	}
+
+	return orders, nil
+}
+
+// GetTradeHistory récupère les exécutions BTC-USDC depuis la date indiquée.
+// KuCoin limite chaque requête /api/v1/fills à une fenêtre de 7 jours.
+func (c *Client) GetTradeHistory(since time.Time) ([]common.Trade, error) {
+	const window = 7 * 24 * time.Hour
+	const pageSize = 500
+
+	var trades []common.Trade
+	now := time.Now()
+
+	for start := since; start.Before(now); start = start.Add(window) {
+		end := start.Add(window)
+		if end.After(now) {
+			end = now
+		}
 
-	// Ajouter une marge de sécurité de 5%
-	totalFeesToCover *= 1.05
+		for page := 1; ; page++ {
+			// La requête est incluse dans l'endpoint pour qu'elle soit prise en compte dans la signature
+			endpoint := fmt.Sprintf("/api/v1/fills?symbol=BTC-USDC&startAt=%d&endAt=%d&currentPage=%d&pageSize=%d",
+				start.UnixMilli(), end.UnixMilli(), page, pageSize)
+			data, err := c.sendRequest("GET", endpoint, "")
+			if err != nil {
+				return nil, fmt.Errorf("erreur lors de la récupération de l'historique des trades: %w", err)
+			}
 
-	// Calculer l'ajustement de prix par unité
-	feeAdjustmentPerUnit := totalFeesToCover / quantity
+			_, _ = jsonparser.ArrayEach(data, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+				tradeId, _ := jsonparser.GetString(value, "tradeId")
+				orderId, _ := jsonparser.GetString(value, "orderId")
+				side, _ := jsonparser.GetString(value, "side")
+				priceStr, _ := jsonparser.GetString(value, "price")
+				sizeStr, _ := jsonparser.GetString(value, "size")
+				feeStr, _ := jsonparser.GetString(value, "fee")
+				feeCurrency, _ := jsonparser.GetString(value, "feeCurrency")
+				createdAt, _ := jsonparser.GetInt(value, "createdAt")
+
+				trades = append(trades, common.Trade{
+					ID:       tradeId,
+					OrderID:  orderId,
+					Side:     strings.ToUpper(side),
+					Price:    parseFloat(priceStr),
+					Quantity: parseFloat(sizeStr),
+					Fee:      parseFloat(feeStr),
+					FeeAsset: feeCurrency,
+					Time:     time.UnixMilli(createdAt),
+				})
+			}, "items")
+
+			totalPage, err := jsonparser.GetInt(data, "totalPage")
+			if err != nil || int64(page) >= totalPage {
+				break
+			}
+		}
+	}
 
-	// Prix minimal pour couvrir les frais
-	minProfitablePrice := buyPrice + feeAdjustmentPerUnit
+	// KuCoin renvoie les exécutions les plus récentes en premier
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
 
-	return minProfitablePrice, nil
+	return trades, nil
 }
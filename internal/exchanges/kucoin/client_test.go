@@ -0,0 +1,167 @@
+package kucoin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"main/internal/exchanges/common"
+)
+
+func TestCancelOrderIdempotent(t *testing.T) {
+	const orderId = "5bd6e9286d99522a52e458de"
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    common.CancelResult
+		wantErr bool
+	}{
+		{
+			name:   "ordre ouvert annulé",
+			status: http.StatusOK,
+			body:   `{"code":"200000","data":{"cancelledOrderIds":["` + orderId + `"]}}`,
+			want:   common.Cancelled,
+		},
+		{
+			name:   "ordre inexistant (réponse HTTP en erreur)",
+			status: http.StatusBadRequest,
+			body:   `{"code":"400100","msg":"order_not_exist_or_not_allow_to_cancel"}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			name:   "ordre inexistant (code KuCoin)",
+			status: http.StatusOK,
+			body:   `{"code":"400100","msg":"order not exist."}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			name:   "ordre déjà clôturé",
+			status: http.StatusOK,
+			body:   `{"code":"400100","msg":"The order cannot be canceled."}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			// 400100 est aussi le code générique des paramètres invalides
+			name:    "paramètre invalide",
+			status:  http.StatusOK,
+			body:    `{"code":"400100","msg":"Parameter error"}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+		{
+			name:    "trop de requêtes",
+			status:  http.StatusTooManyRequests,
+			body:    `{"code":"429000","msg":"Too Many Requests"}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/api/v1/orders/"+orderId {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			got, err := client.CancelOrderIdempotent(orderId)
+			if got != tt.want {
+				t.Errorf("CancelOrderIdempotent() = %v, attendu %v (erreur: %v)", got, tt.want, err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaintenanceResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantMaintenance bool
+		wantErr         bool
+	}{
+		{
+			name:   "service disponible",
+			status: http.StatusOK,
+			body:   `{"code":"200000","data":1700000000000}`,
+		},
+		{
+			name:            "service indisponible (HTTP 503)",
+			status:          http.StatusServiceUnavailable,
+			body:            `<html>Service Unavailable</html>`,
+			wantMaintenance: true,
+			wantErr:         true,
+		},
+		{
+			name:            "code de maintenance KuCoin",
+			status:          http.StatusOK,
+			body:            `{"code":"130101","msg":"System maintenance"}`,
+			wantMaintenance: true,
+			wantErr:         true,
+		},
+		{
+			name:    "trop de requêtes",
+			status:  http.StatusTooManyRequests,
+			body:    `{"code":"429000","msg":"Too Many Requests"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			_, err := client.ServerTime()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			if common.IsMaintenance(err) != tt.wantMaintenance {
+				t.Errorf("IsMaintenance(%v) = %v, attendu %v", err, !tt.wantMaintenance, tt.wantMaintenance)
+			}
+		})
+	}
+}
+
+func TestDecodeOrderCreated(t *testing.T) {
+	cases := []struct {
+		name, body, orderId, err string
+	}{
+		{"données sans enveloppe", `{"orderId":"6710d8336afb9d0007c74b10","clientOid":"bot-1729172948000000000"}`, "6710d8336afb9d0007c74b10", ""},
+		{"enveloppe data.orderId", `{"code":"200000","data":{"orderId":"6710d8336afb9d0007c74b10","clientOid":"bot-1729172948000000000"}}`, "6710d8336afb9d0007c74b10", ""},
+		{"solde insuffisant", `{"code":"200004","msg":"Balance insufficient!"}`, "", "200004 - Balance insufficient!"},
+		{"enveloppe sans ID", `{"code":"200000","data":{}}`, "", "ID d'ordre absent"},
+	}
+	for _, c := range cases {
+		result, err := decodeOrderCreated([]byte(c.body))
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: erreur %v, attendu %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil || result.OrderID != c.orderId || string(result.Raw) != c.body {
+			t.Errorf("%s: ID %q (erreur %v), attendu %q", c.name, result.OrderID, err, c.orderId)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package kucoin
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"main/internal/exchanges/testutil"
+)
+
+// verifySignature contrôle les en-têtes KC-API-* de l'API v2: la signature porte sur
+// timestamp + méthode + chemin avec sa query string + corps
+func verifySignature(r *http.Request, body []byte) error {
+	client := NewClient("key", "secret")
+	client.Passphrase = "passphrase"
+
+	if r.Header.Get("KC-API-KEY") != "key" {
+		return fmt.Errorf("en-tête KC-API-KEY = %q", r.Header.Get("KC-API-KEY"))
+	}
+	if r.Header.Get("KC-API-KEY-VERSION") != "2" {
+		return fmt.Errorf("en-tête KC-API-KEY-VERSION = %q", r.Header.Get("KC-API-KEY-VERSION"))
+	}
+	if got, want := r.Header.Get("KC-API-PASSPHRASE"), client.signPassphrase(); got != want {
+		return fmt.Errorf("passphrase %s, attendu %s", got, want)
+	}
+	if r.Method == "GET" && len(body) > 0 {
+		return fmt.Errorf("corps inattendu pour un GET: %s", body)
+	}
+
+	timestamp := r.Header.Get("KC-API-TIMESTAMP")
+	want := client.signRequest(timestamp, r.Method, r.URL.RequestURI(), string(body))
+	if got := r.Header.Get("KC-API-SIGN"); got != want {
+		return fmt.Errorf("signature %s, attendu %s", got, want)
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	const orderId = "6710d8336afb9d0007c74b0f"
+
+	server := testutil.NewFakeServer(t, verifySignature,
+		testutil.Route{Method: "GET", Path: "/api/v1/market/orderbook/level1", File: "level1.json"},
+		testutil.Route{Method: "GET", Path: "/api/v1/accounts", File: "accounts.json"},
+		testutil.Route{Method: "GET", Path: "/api/v1/orders/" + orderId, File: "order_done.json"},
+		testutil.Route{Method: "GET", Path: "/api/v1/fills", File: "fills.json"},
+		testutil.Route{Method: "GET", Path: "/api/v1/symbols", File: "symbols.json"},
+		testutil.Route{Method: "POST", Path: "/api/v1/orders", File: "order_created.json"},
+	)
+
+	client := NewClient("key", "secret")
+	client.Passphrase = "passphrase"
+	client.SetBaseURL(server.URL)
+
+	if price := client.GetLastPriceBTC(); price != 67269.5 {
+		t.Errorf("GetLastPriceBTC() = %v, attendu 67269.5", price)
+	}
+	if requests := server.RequestsTo("GET", "/api/v1/market/orderbook/level1"); len(requests) != 1 || requests[0].Query != "symbol=BTC-USDC" {
+		t.Errorf("requête de prix inattendue: %+v", requests)
+	}
+
+	// Seuls les comptes de trading sont comptés
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		t.Fatalf("GetDetailedBalances: %v", err)
+	}
+	if got := balances["USDC"]; got.Free != 900.25 || got.Total != 1000.5 {
+		t.Errorf("solde USDC inattendu: %+v", got)
+	}
+	if got := balances["BTC"]; got.Free != 0.003 || got.Total != 0.0042 {
+		t.Errorf("solde BTC inattendu: %+v", got)
+	}
+
+	status, err := client.GetOrderStatus(orderId)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if !status.Filled() || status.ExecutedQty != 0.0012 || math.Abs(status.AvgFillPrice-67000) > 1e-6 || math.Abs(status.Fee-0.0804) > 1e-9 {
+		t.Errorf("état de l'ordre enregistré inattendu: %+v", status)
+	}
+	// Les frais de l'ordre exécuté sont relus dans ses exécutions
+	if requests := server.RequestsTo("GET", "/api/v1/fills"); len(requests) != 1 || requests[0].Query != "orderId="+orderId+"&tradeType=TRADE" {
+		t.Errorf("requête des exécutions inattendue: %+v", requests)
+	}
+
+	result, err := client.CreateOrder("BUY", "67000.04", "0.0012")
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if result.OrderID != "6710d8336afb9d0007c74b10" {
+		t.Errorf("ID d'ordre créé %q inattendu (réponse: %s)", result.OrderID, result.Raw)
+	}
+
+	// Le prix est arrondi au priceIncrement de la paire
+	created := server.RequestsTo("POST", "/api/v1/orders")
+	if len(created) != 1 {
+		t.Fatalf("%d créations d'ordre, attendu 1", len(created))
+	}
+	for _, field := range []string{`"side":"buy"`, `"symbol":"BTC-USDC"`, `"price":"67000.0"`, `"size":"0.0012"`} {
+		if !strings.Contains(string(created[0].Body), field) {
+			t.Errorf("champ %s absent de %s", field, created[0].Body)
+		}
+	}
+}
+
+// La page d'un ordre ne porte pas toujours ses frais: ils sont additionnés sur ses exécutions,
+// ceux prélevés en BTC convertis au prix de chaque exécution, puis mémorisés
+func TestOrderFeesFromFills(t *testing.T) {
+	const orderId = "6710d8336afb9d0007c74b11"
+
+	server := testutil.NewFakeServer(t, verifySignature,
+		testutil.Route{Method: "GET", Path: "/api/v1/orders/" + orderId, File: "order_done_no_fee.json"},
+		testutil.Route{Method: "GET", Path: "/api/v1/fills", File: "fills_base_fee.json"},
+	)
+
+	client := NewClient("key", "secret")
+	client.Passphrase = "passphrase"
+	client.SetBaseURL(server.URL)
+
+	for i := 0; i < 2; i++ {
+		fees, err := client.GetOrderFees(orderId)
+		if err != nil {
+			t.Fatalf("GetOrderFees: %v", err)
+		}
+		if math.Abs(fees-0.132) > 1e-9 {
+			t.Errorf("GetOrderFees() = %v, attendu 0.132", fees)
+		}
+	}
+
+	status, err := client.GetOrderStatus(orderId)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if math.Abs(status.Fee-0.132) > 1e-9 || math.Abs(status.BaseFee-0.000002) > 1e-12 || status.FeeAsset != "" {
+		t.Errorf("frais de l'ordre inattendus: %+v", status)
+	}
+
+	// L'ordre terminé n'est lu qu'une fois, ses exécutions aussi
+	if requests := server.RequestsTo("GET", "/api/v1/orders/"+orderId); len(requests) != 2 {
+		t.Errorf("%d lectures de l'ordre, attendu 2 (GetOrderFees puis GetOrderStatus)", len(requests))
+	}
+	if requests := server.RequestsTo("GET", "/api/v1/fills"); len(requests) != 1 {
+		t.Errorf("%d lectures des exécutions, attendu 1", len(requests))
+	}
+}
+
+func TestParseFills(t *testing.T) {
+	tests := []struct {
+		name  string
+		items string
+		want  fillFees
+		found bool
+	}{
+		{
+			name:  "frais en USDC",
+			items: `[{"price":"67000","fee":"0.0335","feeCurrency":"USDC"},{"price":"67000","fee":"0.0469","feeCurrency":"USDC"}]`,
+			want:  fillFees{quote: 0.0804},
+			found: true,
+		},
+		{
+			name:  "frais en BTC convertis au prix d'exécution",
+			items: `[{"price":"60000","fee":"0.000001","feeCurrency":"BTC"},{"price":"70000","fee":"0.000002","feeCurrency":"BTC"}]`,
+			want:  fillFees{quote: 0.2, base: 0.000003},
+			found: true,
+		},
+		{
+			name:  "frais payés en KCS",
+			items: `[{"price":"67000","fee":"0.0052","feeCurrency":"KCS"},{"price":"67000","fee":"0.0071","feeCurrency":"KCS"}]`,
+			want:  fillFees{asset: "KCS", assetAmount: 0.0123},
+			found: true,
+		},
+		{
+			name:  "aucune exécution",
+			items: `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := parseFills([]byte(`{"items":` + tt.items + `}`))
+			if found != tt.found || got.asset != tt.want.asset ||
+				math.Abs(got.quote-tt.want.quote) > 1e-9 || math.Abs(got.base-tt.want.base) > 1e-12 ||
+				math.Abs(got.assetAmount-tt.want.assetAmount) > 1e-9 {
+				t.Errorf("parseFills() = %+v, %v, attendu %+v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
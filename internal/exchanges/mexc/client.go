@@ -10,8 +10,11 @@ import (
 	"log"
 	"main/internal/database"
 	"main/internal/exchanges/common"
+	"math"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,12 +23,27 @@ import (
 	"github.com/fatih/color"
 )
 
+// DefaultHTTPTimeout est le délai des requêtes HTTP vers MEXC sans MEXC_HTTP_TIMEOUT_SECONDS
+const DefaultHTTPTimeout = 15 * time.Second
+
 // Client représente un client API pour l'exchange MEXC
 type Client struct {
 	APIKey    string
 	APISecret string
 	BaseURL   string
 	Debug     bool // Mode debug pour afficher plus d'informations
+	// Écart en pourcentage pour les ordres maker (0 = 0.2%)
+	MakerBufferPercent float64
+	// Taux de frais utilisés pour les estimations quand les frais réels sont inconnus
+	FeeRates common.FeeRates
+	// Délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+	HTTPTimeout time.Duration
+	// Fenêtre de validité des requêtes signées en millisecondes (0 = celle de l'exchange)
+	RecvWindowMs int
+	// Décalage avec l'horloge de MEXC, mesuré au premier rejet d'horodatage
+	clock common.ClockSkew
+	// Pas de prix et de quantité de BTCUSDC, lus au premier ordre
+	precision common.PrecisionCache
 }
 
 // NewClient crée une nouvelle instance de client MEXC
@@ -35,6 +53,8 @@ func NewClient(apiKey, apiSecret string) *Client {
 		APISecret: apiSecret,
 		BaseURL:   "https://api.mexc.com",
 		Debug:     false, // Activer le mode debug par défaut
+		// Niveau de base de MEXC: 0% maker, 0.05% taker
+		FeeRates: common.FeeRates{Maker: 0, Taker: 0.0005},
 	}
 }
 
@@ -43,6 +63,35 @@ func (c *Client) SetBaseURL(url string) {
 	c.BaseURL = url
 }
 
+// SetMakerBufferPercent définit l'écart en pourcentage utilisé par CreateMakerOrder (0 = 0.2%)
+func (c *Client) SetMakerBufferPercent(percent float64) {
+	c.MakerBufferPercent = percent
+}
+
+// SetFeeRates définit les taux maker et taker utilisés pour estimer les frais
+func (c *Client) SetFeeRates(rates common.FeeRates) {
+	c.FeeRates = rates
+}
+
+// SetHTTPTimeout définit le délai des requêtes HTTP (0 = DefaultHTTPTimeout)
+func (c *Client) SetHTTPTimeout(timeout time.Duration) {
+	c.HTTPTimeout = timeout
+}
+
+// httpTimeout retourne le délai effectif des requêtes HTTP
+func (c *Client) httpTimeout() time.Duration {
+	if c.HTTPTimeout > 0 {
+		return c.HTTPTimeout
+	}
+	return DefaultHTTPTimeout
+}
+
+// SetRecvWindow définit la fenêtre de validité des requêtes signées en millisecondes
+// (0 = celle de l'exchange, common.DefaultRecvWindowMs)
+func (c *Client) SetRecvWindow(recvWindowMs int) {
+	c.RecvWindowMs = recvWindowMs
+}
+
 // SetDebug active ou désactive le mode debug
 func (c *Client) SetDebug(debug bool) {
 	c.Debug = debug
@@ -62,8 +111,78 @@ func (c *Client) signRequest(queryString string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// sendRequest envoie une requête HTTP à l'API MEXC
+// sendRequest envoie une requête à l'API MEXC. Si MEXC rejette l'horodatage d'une requête
+// signée (code 700003, horloge locale décalée), le décalage est mesuré sur l'heure du serveur,
+// appliqué aux requêtes suivantes de la session, et la requête est renvoyée une fois corrigée.
 func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, error) {
+	queryString = common.WithRecvWindow(queryString, c.RecvWindowMs, c.signRequest)
+	body, err := c.doRequest(method, endpoint, queryString)
+	if err == nil || !isTimestampError(err) || !strings.Contains(queryString, "&signature=") {
+		return body, err
+	}
+
+	offset, syncErr := c.clock.Sync(c.ServerTime)
+	if syncErr != nil {
+		return nil, fmt.Errorf("%w (heure du serveur indisponible: %v)", err, syncErr)
+	}
+	color.Yellow("Horloge locale décalée de %s par rapport à MEXC: correction appliquée pour la session", offset.Round(time.Millisecond))
+
+	retried, _ := common.Retimestamp(queryString, c.clock.Timestamp(), c.signRequest)
+	return c.doRequest(method, endpoint, retried)
+}
+
+// isTimestampError indique si MEXC a rejeté l'horodatage de la requête (hors de recvWindow)
+func isTimestampError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "700003") || strings.Contains(message, "outside of the recvWindow")
+}
+
+// ServerTime retourne l'heure du serveur MEXC
+func (c *Client) ServerTime() (time.Time, error) {
+	body, err := c.doRequest("GET", "/api/v3/time", "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	serverTime, err := jsonparser.GetInt(body, "serverTime")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("réponse /api/v3/time invalide: %w", err)
+	}
+	return time.UnixMilli(serverTime), nil
+}
+
+// ClockOffset retourne la correction appliquée aux horodatages des requêtes signées
+func (c *Client) ClockOffset() time.Duration {
+	return c.clock.Offset()
+}
+
+// GetOrderBook retourne le meilleur achat et la meilleure vente BTC/USDC (/api/v3/ticker/bookTicker)
+func (c *Client) GetOrderBook() (common.BookTop, error) {
+	body, err := c.doRequest("GET", "/api/v3/ticker/bookTicker", "symbol=BTCUSDC")
+	if err != nil {
+		return common.BookTop{}, err
+	}
+	return parseBookTicker(body)
+}
+
+// parseBookTicker lit la réponse de /api/v3/ticker/bookTicker
+func parseBookTicker(body []byte) (common.BookTop, error) {
+	var ticker struct {
+		BidPrice string `json:"bidPrice"`
+		AskPrice string `json:"askPrice"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return common.BookTop{}, fmt.Errorf("réponse bookTicker invalide: %w", err)
+	}
+	bid, bidErr := strconv.ParseFloat(ticker.BidPrice, 64)
+	ask, askErr := strconv.ParseFloat(ticker.AskPrice, 64)
+	if bidErr != nil || askErr != nil {
+		return common.BookTop{}, fmt.Errorf("prix du bookTicker invalides: %s", string(body))
+	}
+	return common.BookTop{Bid: bid, Ask: ask}, nil
+}
+
+// doRequest envoie une requête HTTP à l'API MEXC
+func (c *Client) doRequest(method, endpoint, queryString string) ([]byte, error) {
 	fullURL := fmt.Sprintf("%s%s?%s", c.BaseURL, endpoint, queryString)
 
 	req, err := http.NewRequest(method, fullURL, nil)
@@ -76,7 +195,8 @@ func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, erro
 	req.Header.Set("X-MEXC-APIKEY", c.APIKey)
 
 	client := &http.Client{
-		Timeout: 15 * time.Second, // Augmenter le timeout à 15 secondes
+		Timeout:   c.httpTimeout(),
+		Transport: common.MeteredTransport("MEXC"),
 	}
 
 	resp, err := client.Do(req)
@@ -91,8 +211,11 @@ func (c *Client) sendRequest(method, endpoint, queryString string) ([]byte, erro
 	}
 
 	// En cas d'erreur HTTP, inclure le corps de la réponse pour le diagnostic
+	if common.IsMaintenanceStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("%w: %w", common.ErrMaintenance, common.NewHTTPStatusError("HTTP %d: %s", resp.StatusCode, string(body)))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erreur HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, common.NewHTTPStatusError("erreur HTTP %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Vérifier si la réponse est une erreur de l'API
@@ -177,14 +300,31 @@ func (c *Client) normalizeOrderId(orderId string) string {
 }
 
 // CreateOrder crée un nouvel ordre sur MEXC
-func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+func (c *Client) CreateOrder(side, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
+	timestamp := c.clock.Timestamp()
 
-	// Construire le query string avec tous les paramètres requis
+	// Un ordre LIMIT_MAKER est rejeté par MEXC s'il devait s'exécuter immédiatement
+	orderType := "type=LIMIT&timeInForce=GTC"
+	if common.PostOnlyRequested(opts) {
+		orderType = "type=LIMIT_MAKER"
+	}
+	market := common.MarketRequested(opts)
+	if market {
+		orderType = "type=MARKET"
+	}
+	if clientOrderID := common.ClientOrderIDRequested(opts); clientOrderID != "" {
+		orderType += "&newClientOrderId=" + url.QueryEscape(clientOrderID)
+	}
+
+	// Construire le query string avec tous les paramètres requis (sans prix pour un ordre au marché)
 	queryString := fmt.Sprintf(
-		"symbol=BTCUSDC&side=%s&type=LIMIT&timeInForce=GTC&quantity=%s&price=%s&timestamp=%s",
-		side, quantity, price, timestamp,
+		"symbol=BTCUSDC&side=%s&%s&quantity=%s&price=%s&timestamp=%s",
+		side, orderType, quantity, price, timestamp,
 	)
+	if market {
+		queryString = fmt.Sprintf("symbol=BTCUSDC&side=%s&%s&quantity=%s&timestamp=%s",
+			side, orderType, quantity, timestamp)
+	}
 
 	// Signer la requête
 	signature := c.signRequest(queryString)
@@ -193,10 +333,24 @@ func (c *Client) CreateOrder(side, price, quantity string) ([]byte, error) {
 	// Envoyer la requête
 	body, err := c.sendRequest("POST", "/api/v3/order", signedQuery)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de l'envoi de l'ordre: %w", err)
+		return common.OrderCreateResult{}, fmt.Errorf("erreur lors de l'envoi de l'ordre: %w", err)
 	}
 
-	return body, nil
+	return decodeOrderCreated(body)
+}
+
+// decodeOrderCreated extrait l'ID de la réponse de POST /api/v3/order
+// ({"orderId":"C02__443...",...}). Une réponse d'erreur ({"code":30004,"msg":"..."}) est
+// retournée comme erreur.
+func decodeOrderCreated(body []byte) (common.OrderCreateResult, error) {
+	if orderId := common.JSONID(body, "orderId"); orderId != "" {
+		return common.NewOrderCreateResult(orderId, body)
+	}
+	if msg, err := jsonparser.GetString(body, "msg"); err == nil && msg != "" {
+		code, _ := jsonparser.GetInt(body, "code")
+		return common.OrderCreateResult{}, fmt.Errorf("ordre refusé par MEXC: %d - %s", code, msg)
+	}
+	return common.NewOrderCreateResult("", body)
 }
 
 // GetOrderById récupère les informations d'un ordre spécifique
@@ -207,74 +361,49 @@ func (c *Client) GetOrderById(id string) ([]byte, error) {
 		return nil, fmt.Errorf("ID d'ordre invalide: %s", id)
 	}
 
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 
 	// CHANGEMENT IMPORTANT: Pour les ordres de vente, vérifier d'abord l'historique des ordres
 	// car les ordres complétés disparaissent des ordres actifs
 
 	// 1. Vérifier d'abord l'historique des ordres (ordres complétés)
-	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
-	signature := c.signRequest(queryString)
-	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
-
-	history, histErr := c.sendRequest("GET", "/api/v3/allOrders", signedQuery)
+	foundOrder, histErr := c.findOrderInHistory(id)
 	if histErr == nil {
-		var foundOrder []byte
-		jsonparser.ArrayEach(history, func(order []byte, dataType jsonparser.ValueType, offset int, err error) {
-			if err != nil {
-				return
-			}
+		// Modifier l'état si c'est un ordre complété dans l'historique
+		status, err := jsonparser.GetString(foundOrder, "status")
+		if err == nil && status != "FILLED" && status != "CANCELED" {
+			c.logDebug("Ordre trouvé dans l'historique mais avec statut: %s, vérification supplémentaire", status)
 
-			orderIdVal, _ := jsonparser.GetString(order, "orderId")
-			if strings.Contains(orderIdVal, normalizedId) || strings.Contains(normalizedId, orderIdVal) ||
-				strings.Contains(id, orderIdVal) || strings.Contains(orderIdVal, id) {
-				foundOrder = order
+			// Vérifier si l'ordre est potentiellement complété
+			executedQty, err1 := jsonparser.GetString(foundOrder, "executedQty")
+			origQty, err2 := jsonparser.GetString(foundOrder, "origQty")
 
-				// Ajouter du debug pour voir les ordres trouvés
-				status, _ := jsonparser.GetString(order, "status")
-				c.logDebug("Ordre trouvé dans l'historique - ID: %s, Status: %s", orderIdVal, status)
-
-				return
-			}
-		})
-
-		if foundOrder != nil {
-			// Modifier l'état si c'est un ordre complété dans l'historique
-			status, err := jsonparser.GetString(foundOrder, "status")
-			if err == nil && status != "FILLED" && status != "CANCELED" {
-				c.logDebug("Ordre trouvé dans l'historique mais avec statut: %s, vérification supplémentaire", status)
-
-				// Vérifier si l'ordre est potentiellement complété
-				executedQty, err1 := jsonparser.GetString(foundOrder, "executedQty")
-				origQty, err2 := jsonparser.GetString(foundOrder, "origQty")
-
-				if err1 == nil && err2 == nil {
-					executedQtyFloat, _ := strconv.ParseFloat(executedQty, 64)
-					origQtyFloat, _ := strconv.ParseFloat(origQty, 64)
+			if err1 == nil && err2 == nil {
+				executedQtyFloat, _ := strconv.ParseFloat(executedQty, 64)
+				origQtyFloat, _ := strconv.ParseFloat(origQty, 64)
 
-					if executedQtyFloat > 0 && executedQtyFloat >= origQtyFloat*0.99 {
-						// L'ordre est effectivement exécuté, mais pas marqué comme FILLED
-						// Créer une copie de l'ordre avec un statut FILLED
-						var orderMap map[string]interface{}
-						json.Unmarshal(foundOrder, &orderMap)
-						orderMap["status"] = "FILLED" // Forcer le statut à FILLED
+				if executedQtyFloat > 0 && executedQtyFloat >= origQtyFloat*0.99 {
+					// L'ordre est effectivement exécuté, mais pas marqué comme FILLED
+					// Créer une copie de l'ordre avec un statut FILLED
+					var orderMap map[string]interface{}
+					json.Unmarshal(foundOrder, &orderMap)
+					orderMap["status"] = "FILLED" // Forcer le statut à FILLED
 
-						modifiedOrder, _ := json.Marshal(orderMap)
-						c.logDebug("Ordre modifié avec statut FILLED: %s", string(modifiedOrder))
+					modifiedOrder, _ := json.Marshal(orderMap)
+					c.logDebug("Ordre modifié avec statut FILLED: %s", string(modifiedOrder))
 
-						return modifiedOrder, nil
-					}
+					return modifiedOrder, nil
 				}
 			}
-
-			return foundOrder, nil
 		}
+
+		return foundOrder, nil
 	}
 
 	// 2. Ensuite, vérifier les ordres actifs (comme avant)
-	queryString = fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", normalizedId, timestamp)
-	signature = c.signRequest(queryString)
-	signedQuery = fmt.Sprintf("%s&signature=%s", queryString, signature)
+	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", normalizedId, timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
 	body, err := c.sendRequest("GET", "/api/v3/order", signedQuery)
 	if err == nil {
@@ -312,82 +441,124 @@ func (c *Client) GetOrderById(id string) ([]byte, error) {
 	return nil, fmt.Errorf("impossible de trouver l'ordre avec ID %s: %w", id, err)
 }
 
-func (c *Client) IsFilled(order string) bool {
-	// Activer temporairement le débogage
-	debugState := c.Debug
-	c.Debug = false
-	defer func() { c.Debug = debugState }()
+// findOrderInHistory recherche un ordre dans l'historique (/api/v3/allOrders)
+func (c *Client) findOrderInHistory(id string) ([]byte, error) {
+	normalizedId := c.normalizeOrderId(id)
+	if normalizedId == "" {
+		return nil, fmt.Errorf("ID d'ordre invalide: %s", id)
+	}
 
-	c.logDebug("Vérification si l'ordre est rempli: %s", order)
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
 
-	// 1. Vérifier le statut standard
-	status, err := jsonparser.GetString([]byte(order), "status")
-	if err == nil {
-		c.logDebug("Statut trouvé: %s", status)
-		if status == "FILLED" {
-			// Pour MEXC, même si le statut est FILLED, nous devons vérifier que le solde est réellement disponible
-			// Vérifier les quantités exécutées
-			executedQty, err1 := jsonparser.GetString([]byte(order), "executedQty")
-			origQty, err2 := jsonparser.GetString([]byte(order), "origQty")
+	history, err := c.sendRequest("GET", "/api/v3/allOrders", signedQuery)
+	if err != nil {
+		return nil, err
+	}
 
-			if err1 == nil && err2 == nil {
-				c.logDebug("Quantités trouvées - exécutée: %s, originale: %s", executedQty, origQty)
-				executedQtyFloat, err1 := strconv.ParseFloat(executedQty, 64)
-				origQtyFloat, err2 := strconv.ParseFloat(origQty, 64)
-
-				// Si l'exécution est complète selon les données de l'API
-				if err1 == nil && err2 == nil && executedQtyFloat > 0 && executedQtyFloat >= origQtyFloat*0.99 {
-					// Vérifier également le solde disponible
-					balances, err := c.GetDetailedBalances()
-					if err == nil {
-						side, sideErr := jsonparser.GetString([]byte(order), "side")
-						if sideErr == nil && side == "BUY" {
-							// Pour un ordre d'achat, vérifier si le BTC est disponible
-							availableBTC := balances["BTC"].Free
-							c.logDebug("BTC disponible: %.8f - Ordre d'achat reporté comme complété", availableBTC)
-
-							// Si le solde disponible est d'au moins 95% de la quantité d'origine
-							if availableBTC >= origQtyFloat*0.95 {
-								c.logDebug("Solde BTC suffisant, confirmation d'ordre FILLED")
-								return true
-							} else {
-								c.logDebug("Solde BTC insuffisant (%.8f) pour %.8f BTC. Attente de mise à jour des soldes.",
-									availableBTC, origQtyFloat)
-								return false
-							}
-						} else if sideErr == nil && side == "SELL" {
-							// Pour un ordre de vente, vérifier les USDC
-							availableUSDC := balances["USDC"].Free
-							expectedUSDC := origQtyFloat * 0.95 * c.GetLastPriceBTC()
-							c.logDebug("USDC disponible: %.2f - Attendu environ: %.2f", availableUSDC, expectedUSDC)
-
-							// Si le solde USDC a augmenté, l'ordre est probablement complété
-							if availableUSDC > expectedUSDC {
-								c.logDebug("Solde USDC suffisant, confirmation d'ordre FILLED")
-								return true
-							}
-
-							// Si le solde n'a pas augmenté, l'ordre n'est probablement pas réellement exécuté
-							c.logDebug("Solde USDC insuffisant, l'ordre n'est probablement pas réellement exécuté")
-							return false
-						}
-					}
-
-					// Si on ne peut pas vérifier les soldes, être conservateur
-					c.logDebug("Impossible de vérifier les soldes, considérons l'ordre comme non rempli")
-					return false
-				}
-			}
+	var foundOrder []byte
+	jsonparser.ArrayEach(history, func(order []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if err != nil || foundOrder != nil {
+			return
+		}
 
-			// Par défaut pour MEXC, ne pas faire confiance au statut FILLED
-			// à moins de confirmer avec les soldes
-			c.logDebug("ATTENTION: Ordre marqué FILLED mais vérification impossible, considéré comme non rempli")
-			return false
+		orderIdVal, _ := jsonparser.GetString(order, "orderId")
+		if strings.Contains(orderIdVal, normalizedId) || strings.Contains(normalizedId, orderIdVal) ||
+			strings.Contains(id, orderIdVal) || strings.Contains(orderIdVal, id) {
+			foundOrder = order
+
+			// Ajouter du debug pour voir les ordres trouvés
+			status, _ := jsonparser.GetString(order, "status")
+			c.logDebug("Ordre trouvé dans l'historique - ID: %s, Status: %s", orderIdVal, status)
 		}
+	})
+
+	if foundOrder == nil {
+		return nil, fmt.Errorf("ordre %s absent de l'historique", id)
 	}
+	return foundOrder, nil
+}
 
-	// Autres vérifications inchangées...
-	return false
+// GetOrderStatus récupère un ordre et en interprète l'état. L'exécution complète repose sur
+// executedQty et origQty, relus dans /api/v3/allOrders lorsqu'ils manquent ou contredisent
+// le statut. La disponibilité du BTC acheté reste à vérifier par l'appelant.
+func (c *Client) GetOrderStatus(id string) (common.OrderStatus, error) {
+	order, err := c.GetOrderById(id)
+	if err != nil {
+		return common.OrderStatus{}, err
+	}
+	return c.parseOrderStatus(order), nil
+}
+
+// parseOrderStatus interprète une réponse d'ordre MEXC
+func (c *Client) parseOrderStatus(order []byte) common.OrderStatus {
+	c.logDebug("Interprétation de l'ordre: %s", order)
+
+	status, _ := jsonparser.GetString(order, "status")
+	executedQty, origQty, ok := orderQuantities(order)
+
+	// Quantités absentes ou incohérentes avec le statut: consulter l'historique des ordres
+	if !ok || (status == "FILLED" && !isFullyExecuted(executedQty, origQty)) {
+		orderId, _ := jsonparser.GetString(order, "orderId")
+		if historyOrder, err := c.findOrderInHistory(orderId); err == nil {
+			order = historyOrder
+			status, _ = jsonparser.GetString(order, "status")
+			executedQty, origQty, ok = orderQuantities(order)
+		} else {
+			c.logDebug("Ordre %s introuvable dans l'historique: %v", orderId, err)
+		}
+	}
+
+	result := common.OrderStatus{
+		ExecutedQty:  executedQty,
+		AvgFillPrice: common.AveragePrice(common.OrderFloat(order, "cummulativeQuoteQty"), executedQty),
+	}
+	result.ID, _ = jsonparser.GetString(order, "orderId")
+	if updateTime := common.OrderFloat(order, "updateTime"); updateTime > 0 {
+		result.UpdatedAt = time.UnixMilli(int64(updateTime))
+	}
+
+	switch {
+	case status == "CANCELED" || status == "PARTIALLY_CANCELED" || status == "EXPIRED":
+		result.State = common.OrderCancelled
+	case status == "REJECTED":
+		result.State = common.OrderRejected
+	case ok && isFullyExecuted(executedQty, origQty):
+		result.State = common.OrderFilled
+	case executedQty > 0:
+		result.State = common.OrderPartiallyFilled
+	default:
+		result.State = common.OrderOpen
+	}
+
+	if result.State != common.OrderFilled {
+		c.logDebug("Ordre non entièrement exécuté (statut: %s, exécutée: %.8f, originale: %.8f)",
+			status, executedQty, origQty)
+	}
+	return result
+}
+
+// orderQuantities extrait les quantités exécutée et originale d'un ordre MEXC
+func orderQuantities(order []byte) (float64, float64, bool) {
+	executedStr, err1 := jsonparser.GetString(order, "executedQty")
+	origStr, err2 := jsonparser.GetString(order, "origQty")
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+
+	executedQty, err1 := strconv.ParseFloat(executedStr, 64)
+	origQty, err2 := strconv.ParseFloat(origStr, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return executedQty, origQty, true
+}
+
+// isFullyExecuted tolère 1% d'écart dû aux arrondis de quantité
+func isFullyExecuted(executedQty, origQty float64) bool {
+	return executedQty > 0 && executedQty >= origQty*0.99
 }
 
 // Ajout d'une nouvelle méthode pour attendre la mise à jour des soldes
@@ -437,7 +608,7 @@ func (c *Client) CancelOrder(orderID string) ([]byte, error) {
 		}
 	}
 
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 
 	// Construction de la requête pour l'annulation
 	queryString := fmt.Sprintf("symbol=BTCUSDC&orderId=%s&timestamp=%s", orderIDToUse, timestamp)
@@ -492,6 +663,26 @@ func (c *Client) CancelOrder(orderID string) ([]byte, error) {
 	return body, nil
 }
 
+// CancelOrderIdempotent annule un ordre. CancelOrder essaie déjà l'ID avec et sans le
+// préfixe C02__; -2011 (Unknown order id) et -2013 signalent alors un ordre déjà fermé.
+func (c *Client) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	_, err := c.CancelOrder(orderID)
+	if err == nil {
+		return common.Cancelled, nil
+	}
+
+	code, msg := common.APIError(err)
+	if code == "-2011" || code == "-2013" || strings.Contains(msg, "Unknown order") {
+		return common.AlreadyGone, nil
+	}
+	return common.CancelFailed, err
+}
+
+// CreateOCOOrder n'est pas disponible: MEXC ne propose pas d'ordre OCO natif sur le spot
+func (c *Client) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	return common.OCOOrder{}, common.ErrOCONotSupported
+}
+
 // GetExchangeInfo récupère les informations de l'exchange
 func (c *Client) GetExchangeInfo() ([]byte, error) {
 	body, err := c.sendRequest("GET", "/api/v3/exchangeInfo", "")
@@ -501,9 +692,72 @@ func (c *Client) GetExchangeInfo() ([]byte, error) {
 	return body, nil
 }
 
+// PairStatus lit l'état de négociation de BTCUSDC: status vaut "1" (ou "ENABLED") pour une
+// paire ouverte, "2" pour une pause et "3" pour une paire fermée
+func (c *Client) PairStatus() (common.PairStatus, error) {
+	body, err := c.sendRequest("GET", "/api/v3/exchangeInfo", "symbol=BTCUSDC")
+	if err != nil {
+		return common.PairStatus{}, fmt.Errorf("erreur lors de la lecture de l'état de BTCUSDC: %w", err)
+	}
+	var (
+		result common.PairStatus
+		found  bool
+	)
+	_, _ = jsonparser.ArrayEach(body, func(symbol []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if name, _ := jsonparser.GetString(symbol, "symbol"); name != "BTCUSDC" {
+			return
+		}
+		found = true
+		result.Status, _ = jsonparser.GetString(symbol, "status")
+		result.Trading = result.Status == "1" || result.Status == "ENABLED"
+		if allowed, err := jsonparser.GetBoolean(symbol, "isSpotTradingAllowed"); err == nil && !allowed {
+			result.Trading = false
+		}
+	}, "symbols")
+	if !found {
+		// Paire absente de la liste: retirée de la cote
+		return common.PairStatus{Status: "DELISTED"}, nil
+	}
+	return result, nil
+}
+
+// Precision retourne le pas de prix (quotePrecision) et de quantité (baseAssetPrecision) de
+// BTCUSDC, exprimés par MEXC en nombre de décimales
+func (c *Client) Precision() common.Precision {
+	return c.precision.Get(func() (common.Precision, error) {
+		body, err := c.sendRequest("GET", "/api/v3/exchangeInfo", "symbol=BTCUSDC")
+		if err != nil {
+			return common.Precision{}, err
+		}
+		var precision common.Precision
+		_, err = jsonparser.ArrayEach(body, func(symbol []byte, _ jsonparser.ValueType, _ int, _ error) {
+			if name, _ := jsonparser.GetString(symbol, "symbol"); name != "BTCUSDC" {
+				return
+			}
+			if decimals, err := jsonparser.GetInt(symbol, "quotePrecision"); err == nil {
+				precision.PriceTick = math.Pow10(-int(decimals))
+			}
+			if decimals, err := jsonparser.GetInt(symbol, "baseAssetPrecision"); err == nil {
+				precision.QuantityStep = math.Pow10(-int(decimals))
+			}
+		}, "symbols")
+		return precision, err
+	})
+}
+
+// FormatPrice formate un prix au pas de prix de BTCUSDC
+func (c *Client) FormatPrice(price float64) string {
+	return c.Precision().FormatPrice(price)
+}
+
+// FormatQuantity formate une quantité au pas de quantité de BTCUSDC
+func (c *Client) FormatQuantity(quantity float64) string {
+	return c.Precision().FormatQuantity(quantity)
+}
+
 // GetAccountInfo récupère les informations du compte
 func (c *Client) GetAccountInfo() ([]byte, error) {
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 	queryString := fmt.Sprintf("timestamp=%s", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -519,7 +773,7 @@ func (c *Client) GetAccountInfo() ([]byte, error) {
 func (c *Client) GetDetailedBalances() (map[string]common.DetailedBalance, error) {
 	balances := make(map[string]common.DetailedBalance)
 
-	timestamp := time.Now().UnixMilli()
+	timestamp := c.clock.Now().UnixMilli()
 	queryString := fmt.Sprintf("timestamp=%d", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -586,7 +840,7 @@ func (c *Client) GetDetailedBalances() (map[string]common.DetailedBalance, error
 func (c *Client) GetBalanceUSD() float64 {
 	color.Blue("Vérification du solde USDC sur MEXC...")
 
-	timestamp := time.Now().UnixMilli()
+	timestamp := c.clock.Now().UnixMilli()
 	queryString := fmt.Sprintf("timestamp=%d", timestamp)
 	signature := c.signRequest(queryString)
 	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
@@ -627,20 +881,23 @@ func (c *Client) GetBalanceUSD() float64 {
 }
 
 // CreateMakerOrder crée un ordre en mode maker (prix ajusté pour s'assurer d'être dans le carnet d'ordres)
-func (c *Client) CreateMakerOrder(side string, price float64, quantity string) ([]byte, error) {
+func (c *Client) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
+	// Les prix sont envoyés avec 2 décimales: l'écart vaut au moins 0.01
+	offset := common.MakerPriceOffset(price, c.MakerBufferPercent, common.DefaultMakerOrderBufferPercent, 0.01)
+
 	// Ajuster le prix pour s'assurer d'être maker
 	var adjustedPrice float64
 	if side == "BUY" {
 		// Pour un achat, placer l'ordre légèrement en dessous du marché
-		adjustedPrice = price * 0.998 // 0.2% en dessous
+		adjustedPrice = price - offset
 	} else {
 		// Pour une vente, placer l'ordre légèrement au-dessus du marché
-		adjustedPrice = price * 1.002 // 0.2% au-dessus
+		adjustedPrice = price + offset
 	}
 
 	adjustedPriceStr := strconv.FormatFloat(adjustedPrice, 'f', 2, 64)
 
-	return c.CreateOrder(side, adjustedPriceStr, quantity)
+	return c.CreateOrder(side, adjustedPriceStr, quantity, common.OrderOptions{PostOnly: true})
 }
 
 // DumpOrderInfo affiche les informations détaillées d'un ordre pour le débogage
@@ -678,7 +935,7 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 		return 0, fmt.Errorf("ID d'ordre invalide: %s", orderId)
 	}
 
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	timestamp := c.clock.Timestamp()
 
 	// Récupérer l'historique des trades
 	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
@@ -713,7 +970,8 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 		}
 	})
 
-	if foundTrades && totalFees > 0 {
+	// Frais signés: une remise maker (commission négative) est retournée telle quelle
+	if foundTrades && totalFees != 0 {
 		return totalFees, nil
 	}
 
@@ -723,8 +981,8 @@ func (c *Client) GetOrderFees(orderId string) (float64, error) {
 
 // estimateOrderFees estime les frais d'un ordre à partir des données de l'ordre
 func (c *Client) estimateOrderFees(orderId string) (float64, error) {
-	// Taux de frais standard de MEXC (0.2%)
-	const feeRate = 0.0
+	// Taux maker configuré (0% au niveau de base)
+	feeRate := c.FeeRates.Maker
 
 	// Récupérer les détails de l'ordre
 	orderDetails, err := c.GetOrderById(orderId)
@@ -758,25 +1016,140 @@ func (c *Client) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyO
 	buyFees, err := c.GetOrderFees(buyOrderId)
 
 	// Si nous n'avons pas pu récupérer les frais, estimer avec le taux standard
-	if err != nil || buyFees <= 0 {
-		const feeRate = 0.002 // 0.2% pour MEXC
-		buyFees = buyPrice * quantity * feeRate
+	if err != nil || buyFees == 0 {
+		buyFees = buyPrice * quantity * c.FeeRates.Maker
 	}
 
 	// Calculer les frais de vente estimés (même taux)
-	sellFees := buyPrice * quantity * 0.000
+	sellFees := buyPrice * quantity * c.FeeRates.Maker
+
+	// Prix minimal pour couvrir les frais, avec une marge de sécurité de 5% hors remise maker
+	return common.FeeAdjustedSellPrice(buyPrice, quantity, buyFees, sellFees, 0.05), nil
+}
+
+// GetOpenOrders récupère les ordres BTCUSDC encore ouverts
+func (c *Client) GetOpenOrders() ([]common.OpenOrder, error) {
+	timestamp := c.clock.Timestamp()
+	queryString := fmt.Sprintf("symbol=BTCUSDC&timestamp=%s", timestamp)
+	signature := c.signRequest(queryString)
+	signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+
+	body, err := c.sendRequest("GET", "/api/v3/openOrders", signedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des ordres ouverts: %w", err)
+	}
+
+	var orders []common.OpenOrder
+	_, _ = jsonparser.ArrayEach(body, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+		orderId, _ := jsonparser.GetString(value, "orderId")
+		side, _ := jsonparser.GetString(value, "side")
+		priceStr, _ := jsonparser.GetString(value, "price")
+		origQtyStr, _ := jsonparser.GetString(value, "origQty")
+		executedQtyStr, _ := jsonparser.GetString(value, "executedQty")
+		clientOrderId, _ := jsonparser.GetString(value, "clientOrderId")
+		createdMs, _ := jsonparser.GetInt(value, "time")
+
+		price, _ := strconv.ParseFloat(priceStr, 64)
+		origQty, _ := strconv.ParseFloat(origQtyStr, 64)
+		executedQty, _ := strconv.ParseFloat(executedQtyStr, 64)
+
+		var createdAt time.Time
+		if createdMs > 0 {
+			createdAt = time.UnixMilli(createdMs)
+		}
+
+		orders = append(orders, common.OpenOrder{
+			ID:            c.normalizeOrderId(orderId),
+			Side:          side,
+			Price:         price,
+			Quantity:      origQty - executedQty,
+			ClientOrderID: clientOrderId,
+			CreatedAt:     createdAt,
+		})
+	})
+
+	return orders, nil
+}
+
+// GetTradeHistory récupère les exécutions BTCUSDC depuis la date indiquée.
+// MEXC ne conserve qu'environ un mois d'historique via /api/v3/myTrades.
+func (c *Client) GetTradeHistory(since time.Time) ([]common.Trade, error) {
+	const pageSize = 100
+
+	var trades []common.Trade
+	seen := make(map[string]bool)
+	startTime := since.UnixMilli()
+
+	for {
+		timestamp := c.clock.Timestamp()
+		queryString := fmt.Sprintf("symbol=BTCUSDC&startTime=%d&limit=%d&timestamp=%s", startTime, pageSize, timestamp)
+		signature := c.signRequest(queryString)
+		signedQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+
+		body, err := c.sendRequest("GET", "/api/v3/myTrades", signedQuery)
+		if err != nil {
+			return nil, fmt.Errorf("erreur lors de la récupération de l'historique des trades: %w", err)
+		}
+
+		count := 0
+		lastTime := startTime
+		_, _ = jsonparser.ArrayEach(body, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+			count++
 
-	// Total des frais à couvrir
-	totalFeesToCover := buyFees + sellFees
+			id, _ := jsonparser.GetString(value, "id")
+			tradeTime, _ := jsonparser.GetInt(value, "time")
+			if tradeTime > lastTime {
+				lastTime = tradeTime
+			}
+			// Les pages se chevauchent sur la milliseconde de reprise
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+
+			orderId, _ := jsonparser.GetString(value, "orderId")
+			priceStr, _ := jsonparser.GetString(value, "price")
+			qtyStr, _ := jsonparser.GetString(value, "qty")
+			commissionStr, _ := jsonparser.GetString(value, "commission")
+			commissionAsset, _ := jsonparser.GetString(value, "commissionAsset")
+			isBuyer, _ := jsonparser.GetBoolean(value, "isBuyer")
+
+			price, _ := strconv.ParseFloat(priceStr, 64)
+			qty, _ := strconv.ParseFloat(qtyStr, 64)
+			commission, _ := strconv.ParseFloat(commissionStr, 64)
+
+			side := "SELL"
+			if isBuyer {
+				side = "BUY"
+			}
 
-	// Ajouter une marge de sécurité de 5%
-	totalFeesToCover *= 1.05
+			trades = append(trades, common.Trade{
+				ID:       id,
+				OrderID:  strings.TrimPrefix(strings.TrimSpace(orderId), "C02__"),
+				Side:     side,
+				Price:    price,
+				Quantity: qty,
+				Fee:      commission,
+				FeeAsset: commissionAsset,
+				Time:     time.UnixMilli(tradeTime),
+			})
+		})
 
-	// Calculer l'ajustement de prix par unité
-	feeAdjustmentPerUnit := totalFeesToCover / quantity
+		if count < pageSize || lastTime == startTime {
+			break
+		}
+		startTime = lastTime
+	}
 
-	// Prix minimal pour couvrir les frais
-	minProfitablePrice := buyPrice + feeAdjustmentPerUnit
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	return trades, nil
+}
 
-	return minProfitablePrice, nil
+// GetRateLimitStatus retourne un état inconnu: MEXC ne communique pas sa consommation de
+// requêtes dans les en-têtes de ses réponses
+func (c *Client) GetRateLimitStatus() common.RateLimitStatus {
+	return common.RateLimits.Status("MEXC")
 }
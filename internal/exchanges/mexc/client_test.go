@@ -0,0 +1,248 @@
+package mexc
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"main/internal/exchanges/common"
+)
+
+// Réponses MEXC enregistrées (champs inutiles au test retirés)
+const (
+	filledSellOrder = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567890","orderListId":-1,"clientOrderId":"","price":"98500.12","origQty":"0.000512","executedQty":"0.000512","cummulativeQuoteQty":"50.43206144","status":"FILLED","timeInForce":"","type":"LIMIT","side":"SELL","stopPrice":"","icebergQty":"","time":1736421234000,"updateTime":1736425870000,"isWorking":true,"origQuoteOrderQty":"50.43206144"}`
+
+	filledBuyOrder = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567891","orderListId":-1,"clientOrderId":"","price":"95010.00","origQty":"0.000526","executedQty":"0.000526","cummulativeQuoteQty":"49.97526","status":"FILLED","timeInForce":"","type":"LIMIT","side":"BUY","stopPrice":"","icebergQty":"","time":1736401234000,"updateTime":1736402870000,"isWorking":true,"origQuoteOrderQty":"49.97526"}`
+
+	partiallyFilledSellOrder = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567892","orderListId":-1,"clientOrderId":"","price":"98500.12","origQty":"0.000512","executedQty":"0.000200","cummulativeQuoteQty":"19.700024","status":"PARTIALLY_FILLED","timeInForce":"","type":"LIMIT","side":"SELL","stopPrice":"","icebergQty":"","time":1736421234000,"updateTime":1736425870000,"isWorking":true,"origQuoteOrderQty":"50.43206144"}`
+
+	canceledBuyOrder = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567893","orderListId":-1,"clientOrderId":"","price":"95010.00","origQty":"0.000526","executedQty":"0","cummulativeQuoteQty":"0","status":"CANCELED","timeInForce":"","type":"LIMIT","side":"BUY","stopPrice":"","icebergQty":"","time":1736401234000,"updateTime":1736402870000,"isWorking":false,"origQuoteOrderQty":"49.97526"}`
+
+	// Réponse incomplète de /api/v3/order: le statut est FILLED mais les quantités sont absentes
+	filledSellOrderWithoutQty = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567894","status":"FILLED","type":"LIMIT","side":"SELL"}`
+
+	historyFilledSellOrder = `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567894","orderListId":-1,"clientOrderId":"","price":"99000.00","origQty":"0.000300","executedQty":"0.000300","cummulativeQuoteQty":"29.7","status":"FILLED","timeInForce":"","type":"LIMIT","side":"SELL","stopPrice":"","icebergQty":"","time":1736421234000,"updateTime":1736425870000,"isWorking":true,"origQuoteOrderQty":"29.7"}`
+)
+
+func TestParseOrderStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   string
+		history string
+		want    common.OrderState
+		wantQty float64
+	}{
+		{
+			name:    "vente remplie",
+			order:   filledSellOrder,
+			history: "[]",
+			want:    common.OrderFilled,
+			wantQty: 0.000512,
+		},
+		{
+			// La disponibilité du BTC acheté est vérifiée par la mise à jour, pas ici
+			name:    "achat rempli",
+			order:   filledBuyOrder,
+			history: "[]",
+			want:    common.OrderFilled,
+			wantQty: 0.000526,
+		},
+		{
+			name:    "vente partiellement remplie",
+			order:   partiallyFilledSellOrder,
+			history: "[" + partiallyFilledSellOrder + "]",
+			want:    common.OrderPartiallyFilled,
+			wantQty: 0.0002,
+		},
+		{
+			name:    "achat annulé",
+			order:   canceledBuyOrder,
+			history: "[" + canceledBuyOrder + "]",
+			want:    common.OrderCancelled,
+		},
+		{
+			name:    "vente remplie sans quantités, retrouvée dans l'historique",
+			order:   filledSellOrderWithoutQty,
+			history: "[" + filledBuyOrder + "," + historyFilledSellOrder + "]",
+			want:    common.OrderFilled,
+			wantQty: 0.0003,
+		},
+		{
+			name:    "vente sans quantités absente de l'historique",
+			order:   filledSellOrderWithoutQty,
+			history: "[" + filledBuyOrder + "]",
+			want:    common.OrderOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v3/allOrders" {
+					w.Write([]byte(tt.history))
+					return
+				}
+				http.NotFound(w, r)
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			got := client.parseOrderStatus([]byte(tt.order))
+			if got.State != tt.want || got.ExecutedQty != tt.wantQty {
+				t.Errorf("parseOrderStatus() = %v (%.8f), attendu %v (%.8f)", got.State, got.ExecutedQty, tt.want, tt.wantQty)
+			}
+		})
+	}
+}
+
+func TestCancelOrderIdempotent(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    common.CancelResult
+		wantErr bool
+	}{
+		{
+			name:   "ordre ouvert annulé",
+			status: http.StatusOK,
+			body:   `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567890","status":"CANCELED","executedQty":"0"}`,
+			want:   common.Cancelled,
+		},
+		{
+			// Inconnu avec et sans le préfixe C02__: l'ordre n'est plus ouvert
+			name:   "ordre déjà exécuté ou annulé",
+			status: http.StatusBadRequest,
+			body:   `{"code":-2011,"msg":"Unknown order id."}`,
+			want:   common.AlreadyGone,
+		},
+		{
+			name:    "signature invalide",
+			status:  http.StatusBadRequest,
+			body:    `{"code":700002,"msg":"Signature for this request is not valid."}`,
+			want:    common.CancelFailed,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var orderIds []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/api/v3/order" {
+					http.NotFound(w, r)
+					return
+				}
+				orderIds = append(orderIds, r.URL.Query().Get("orderId"))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("key", "secret")
+			client.SetBaseURL(server.URL)
+
+			got, err := client.CancelOrderIdempotent("512345678901234567890")
+			if got != tt.want {
+				t.Errorf("CancelOrderIdempotent() = %v, attendu %v (erreur: %v)", got, tt.want, err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erreur = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			if len(orderIds) == 0 || orderIds[0] != "C02__512345678901234567890" {
+				t.Errorf("premier ID envoyé = %v, attendu l'ID préfixé C02__", orderIds)
+			}
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/exchangeInfo" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		w.Write([]byte(`{"timezone":"CST","symbols":[{"symbol":"BTCUSDC","status":"1","baseAsset":"BTC","baseAssetPrecision":6,"quoteAsset":"USDC","quotePrecision":2,"quoteAssetPrecision":2}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	if got := client.FormatPrice(60600.126); got != "60600.13" {
+		t.Errorf("FormatPrice = %s, attendu 60600.13", got)
+	}
+	if got := client.FormatQuantity(0.0015009); got != "0.001500" {
+		t.Errorf("FormatQuantity = %s, attendu 0.001500", got)
+	}
+	// Les pas sont lus une seule fois
+	if requests != 1 {
+		t.Errorf("%d requêtes exchangeInfo, attendu 1", requests)
+	}
+}
+
+// Exécution maker rémunérée: MEXC reverse une remise, la commission est négative
+const rebateTrades = `[{"symbol":"BTCUSDC","id":"4c72a9a9f1e24a0fbd4e0e4f5a2d1c3b","orderId":"C02__512345678901234567891","orderListId":-1,"price":"95010.00","qty":"0.000526","quoteQty":"49.97526","commission":"-0.00499753","commissionAsset":"USDC","time":1736402870000,"isBuyer":true,"isMaker":true,"isBestMatch":true}]`
+
+func TestOrderFeesRebate(t *testing.T) {
+	trades := rebateTrades
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/myTrades" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(trades))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+	client.FeeRates = common.FeeRates{}
+
+	// Frais signés: la remise n'est pas remplacée par une estimation
+	fees, err := client.GetOrderFees("512345678901234567891")
+	if err != nil || math.Abs(fees+0.00499753) > 1e-12 {
+		t.Fatalf("GetOrderFees() = %.8f (%v), attendu -0.00499753", fees, err)
+	}
+
+	// La remise abaisse le prix minimal sans marge de sécurité
+	price, err := client.AdjustSellPriceForFees(95010, 0.000526, "512345678901234567891")
+	if want := 95010 - 0.00499753/0.000526; err != nil || math.Abs(price-want) > 1e-9 {
+		t.Errorf("AdjustSellPriceForFees() avec remise = %.6f (%v), attendu %.6f", price, err, want)
+	}
+
+	// Des frais positifs restent majorés de la marge de 5%
+	trades = strings.Replace(rebateTrades, `"-0.00499753"`, `"0.00499753"`, 1)
+	price, err = client.AdjustSellPriceForFees(95010, 0.000526, "512345678901234567891")
+	if want := 95010 + 0.00499753*1.05/0.000526; err != nil || math.Abs(price-want) > 1e-9 {
+		t.Errorf("AdjustSellPriceForFees() avec frais = %.6f (%v), attendu %.6f", price, err, want)
+	}
+}
+
+func TestDecodeOrderCreated(t *testing.T) {
+	cases := []struct {
+		name, body, orderId, err string
+	}{
+		{"ordre limite", `{"symbol":"BTCUSDC","orderId":"C02__512345678901234567894","orderListId":-1,"price":"95010","origQty":"0.000526","type":"LIMIT","side":"BUY","transactTime":1736500000000}`, "C02__512345678901234567894", ""},
+		{"ID numérique", `{"symbol":"BTCUSDC","orderId":512345678901234567,"orderListId":-1}`, "512345678901234567", ""},
+		{"position insuffisante", `{"code":30004,"msg":"Insufficient position"}`, "", "30004 - Insufficient position"},
+	}
+	for _, c := range cases {
+		result, err := decodeOrderCreated([]byte(c.body))
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: erreur %v, attendu %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil || result.OrderID != c.orderId || string(result.Raw) != c.body {
+			t.Errorf("%s: ID %q (erreur %v), attendu %q", c.name, result.OrderID, err, c.orderId)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mexc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/testutil"
+)
+
+// verifySignature contrôle la clé API et la signature HMAC-SHA256 des requêtes privées
+func verifySignature(r *http.Request, body []byte) error {
+	if r.URL.Path == "/api/v3/ticker/price" {
+		return nil
+	}
+	if r.Header.Get("X-MEXC-APIKEY") != "key" {
+		return fmt.Errorf("en-tête X-MEXC-APIKEY = %q", r.Header.Get("X-MEXC-APIKEY"))
+	}
+
+	query, signature, ok := strings.Cut(r.URL.RawQuery, "&signature=")
+	if !ok {
+		return fmt.Errorf("signature absente de %q", r.URL.RawQuery)
+	}
+	if !strings.Contains(query, "timestamp=") {
+		return fmt.Errorf("timestamp absent de %q", query)
+	}
+	if want := NewClient("key", "secret").signRequest(query); signature != want {
+		return fmt.Errorf("signature %s, attendu %s", signature, want)
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	server := testutil.NewFakeServer(t, verifySignature,
+		testutil.Route{Method: "GET", Path: "/api/v3/ticker/price", File: "ticker_price.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/account", File: "account.json"},
+		testutil.Route{Method: "GET", Path: "/api/v3/allOrders", File: "all_orders.json"},
+		testutil.Route{Method: "POST", Path: "/api/v3/order", File: "order_new.json"},
+	)
+
+	client := NewClient("key", "secret")
+	client.SetBaseURL(server.URL)
+
+	if price := client.GetLastPriceBTC(); price != 98512.34 {
+		t.Errorf("GetLastPriceBTC() = %v, attendu 98512.34", price)
+	}
+
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		t.Fatalf("GetDetailedBalances: %v", err)
+	}
+	if got := balances["USDC"]; got.Free != 812.44 || got.Locked != 50.43 {
+		t.Errorf("solde USDC inattendu: %+v", got)
+	}
+	if got := balances["BTC"]; got.Free != 0.000526 || got.Locked != 0.000512 {
+		t.Errorf("solde BTC inattendu: %+v", got)
+	}
+
+	// Un ordre exécuté n'est plus actif: il est retrouvé dans l'historique, avec ou sans préfixe
+	status, err := client.GetOrderStatus("512345678901234567890")
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if !status.Filled() || status.AvgFillPrice != 50.43206144/0.000512 || !status.UpdatedAt.Equal(time.UnixMilli(1736425870000)) {
+		t.Errorf("l'ordre de vente enregistré devrait être exécuté: %+v", status)
+	}
+	status, err = client.GetOrderStatus("C02__512345678901234567893")
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if status.State != common.OrderCancelled {
+		t.Errorf("l'ordre annulé ne devrait pas être exécuté: %+v", status)
+	}
+
+	result, err := client.CreateOrder("BUY", "95010.00", "0.000526")
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if result.OrderID != "C02__512345678901234567894" {
+		t.Errorf("ID d'ordre créé %q inattendu (réponse: %s)", result.OrderID, result.Raw)
+	}
+
+	created := server.RequestsTo("POST", "/api/v3/order")
+	if len(created) != 1 {
+		t.Fatalf("%d créations d'ordre, attendu 1", len(created))
+	}
+	for _, param := range []string{"symbol=BTCUSDC", "side=BUY", "type=LIMIT", "quantity=0.000526", "price=95010.00"} {
+		if !strings.Contains(created[0].Query, param) {
+			t.Errorf("paramètre %s absent de %q", param, created[0].Query)
+		}
+	}
+}
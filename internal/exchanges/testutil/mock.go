@@ -0,0 +1,565 @@
+// Package testutil fournit des doublures d'exchange pour tester le bot sans clés API:
+// un client scripté (MockExchange) et des serveurs HTTP rejouant des réponses enregistrées.
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"main/internal/exchanges/common"
+)
+
+// Call est un appel reçu par MockExchange
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockExchange implémente common.Exchange avec des réponses scriptées et enregistre
+// chaque appel. Les ordres créés sont conservés au format Binance (orderId, status,
+// price, origQty, executedQty) et restent ouverts jusqu'à FillOrder ou une annulation.
+type MockExchange struct {
+	mu sync.Mutex
+
+	// Prix retourné par GetLastPriceBTC
+	Price float64
+	// Soldes retournés par GetDetailedBalances
+	Balances map[string]common.DetailedBalance
+	// Frais retournés par GetOrderFees, par ID d'ordre (erreur si l'ID est absent)
+	Fees map[string]float64
+	// Frais prélevés en BTC retournés par GetOrderStatus pour un ordre exécuté, par ID d'ordre
+	BaseFees map[string]float64
+	// Calcul de AdjustSellPriceForFees (erreur si nil, le bot estime alors les frais)
+	AdjustSellPrice func(buyPrice, quantity float64, buyOrderId string) (float64, error)
+	// Historique et ordres ouverts retournés par GetTradeHistory et GetOpenOrders
+	// (qui y ajoute les ordres encore ouverts créés par le mock)
+	Trades     []common.Trade
+	OpenOrders []common.OpenOrder
+	// Erreurs forcées par nom de méthode ("CreateOrder", "GetOrderStatus"...)
+	Errors map[string]error
+	// Active CreateOCOOrder (ErrOCONotSupported sinon, comme hors Binance)
+	SupportsOCO bool
+	// Active SupportsOrderExpiry (OrderOptions.ExpireAt), comme sur KuCoin et Kraken
+	SupportsExpiry bool
+	// Consommation de la limite de requêtes retournée par GetRateLimitStatus
+	RateLimit common.RateLimitStatus
+	// Pas de prix et de quantité retournés par Precision (DefaultPrecision pour les pas nuls)
+	Steps common.Precision
+	// État de la paire retourné par PairStatus (vide = TRADING, négociable)
+	PairState string
+
+	orders map[string]map[string]interface{}
+	// Jambe opposée de chaque jambe d'OCO, annulée (EXPIRED) à l'exécution de l'autre
+	ocoPeers map[string]string
+	nextId   int64
+	calls    []Call
+}
+
+// NewMockExchange crée un exchange simulé au prix indiqué, sans solde ni ordre
+func NewMockExchange(price float64) *MockExchange {
+	return &MockExchange{
+		Price:    price,
+		Balances: make(map[string]common.DetailedBalance),
+		Fees:     make(map[string]float64),
+		BaseFees: make(map[string]float64),
+		Errors:   make(map[string]error),
+		orders:   make(map[string]map[string]interface{}),
+		ocoPeers: make(map[string]string),
+		nextId:   1000,
+	}
+}
+
+// record enregistre un appel et retourne l'erreur forcée pour la méthode. mu doit être verrouillé.
+func (m *MockExchange) record(method string, args ...interface{}) error {
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	return m.Errors[method]
+}
+
+// Calls retourne une copie des appels reçus, dans l'ordre
+func (m *MockExchange) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo retourne les appels reçus par une méthode
+func (m *MockExchange) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// SetBalance définit le solde libre d'un actif
+func (m *MockExchange) SetBalance(asset string, free float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Balances[asset] = common.DetailedBalance{Free: free, Total: free}
+}
+
+// AddOrder enregistre un ordre ouvert existant (status NEW) et retourne son ID
+func (m *MockExchange) AddOrder(id, side string, price, quantity float64) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addOrder(id, side, price, quantity)
+	return id
+}
+
+// addOrder crée un ordre ouvert. mu doit être verrouillé.
+func (m *MockExchange) addOrder(id, side string, price, quantity float64) {
+	m.orders[id] = map[string]interface{}{
+		"symbol":              "BTCUSDC",
+		"orderId":             id,
+		"side":                side,
+		"status":              "NEW",
+		"price":               strconv.FormatFloat(price, 'f', 2, 64),
+		"origQty":             strconv.FormatFloat(quantity, 'f', 8, 64),
+		"executedQty":         "0.00000000",
+		"cummulativeQuoteQty": "0.00000000",
+		"time":                time.Now().UnixMilli(),
+		"updateTime":          time.Now().UnixMilli(),
+	}
+}
+
+// FillOrder exécute entièrement un ordre au prix limite. La jambe opposée d'un OCO expire.
+func (m *MockExchange) FillOrder(id string) error {
+	return m.FillOrderAmount(id, 0)
+}
+
+// FillOrderAmount exécute entièrement un ordre pour le montant USDC indiqué, comme plusieurs
+// exécutions à des prix différents du prix limite (0 = au prix limite)
+func (m *MockExchange) FillOrderAmount(id string, quoteAmount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.orders[id]
+	if !ok {
+		return fmt.Errorf("ordre %s inconnu", id)
+	}
+	if peer, ok := m.ocoPeers[id]; ok && m.orders[peer]["status"] == "NEW" {
+		m.orders[peer]["status"] = "EXPIRED"
+	}
+	if quoteAmount <= 0 {
+		price, _ := strconv.ParseFloat(order["price"].(string), 64)
+		quantity, _ := strconv.ParseFloat(order["origQty"].(string), 64)
+		quoteAmount = price * quantity
+	}
+	order["status"] = "FILLED"
+	order["executedQty"] = order["origQty"]
+	order["cummulativeQuoteQty"] = strconv.FormatFloat(quoteAmount, 'f', 8, 64)
+	order["updateTime"] = time.Now().UnixMilli()
+	return nil
+}
+
+// ExpireOrder fait expirer un ordre ouvert comme à sa date d'expiration (OrderOptions.ExpireAt)
+func (m *MockExchange) ExpireOrder(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.orders[id]
+	if !ok {
+		return fmt.Errorf("ordre %s inconnu", id)
+	}
+	order["status"] = "EXPIRED"
+	order["expired"] = true
+	order["updateTime"] = time.Now().UnixMilli()
+	return nil
+}
+
+// PartiallyFillOrder exécute une partie d'un ordre ouvert au prix limite, l'ordre restant ouvert
+func (m *MockExchange) PartiallyFillOrder(id string, quantity float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.orders[id]
+	if !ok {
+		return fmt.Errorf("ordre %s inconnu", id)
+	}
+	price, _ := strconv.ParseFloat(order["price"].(string), 64)
+	order["status"] = "PARTIALLY_FILLED"
+	order["executedQty"] = strconv.FormatFloat(quantity, 'f', 8, 64)
+	order["cummulativeQuoteQty"] = strconv.FormatFloat(price*quantity, 'f', 8, 64)
+	order["updateTime"] = time.Now().UnixMilli()
+	return nil
+}
+
+// RejectOrder fait refuser un ordre par l'exchange, sans exécution
+func (m *MockExchange) RejectOrder(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.orders[id]
+	if !ok {
+		return fmt.Errorf("ordre %s inconnu", id)
+	}
+	order["status"] = "REJECTED"
+	order["updateTime"] = time.Now().UnixMilli()
+	return nil
+}
+
+// orderOpen indique si un ordre est encore ouvert, partiellement exécuté ou non. mu doit être verrouillé.
+func orderOpen(order map[string]interface{}) bool {
+	return order["status"] == "NEW" || order["status"] == "PARTIALLY_FILLED"
+}
+
+// OrderStatus retourne le statut d'un ordre (NEW, PARTIALLY_FILLED, FILLED, CANCELED, EXPIRED, REJECTED), vide s'il est inconnu
+func (m *MockExchange) OrderStatus(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if order, ok := m.orders[id]; ok {
+		return order["status"].(string)
+	}
+	return ""
+}
+
+// CheckConnection simule la vérification de la connexion
+func (m *MockExchange) CheckConnection() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.record("CheckConnection")
+}
+
+// GetBalanceUSD retourne le solde USDC libre
+func (m *MockExchange) GetBalanceUSD() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("GetBalanceUSD")
+	return m.Balances["USDC"].Free
+}
+
+// GetLastPriceBTC retourne le prix scripté
+func (m *MockExchange) GetLastPriceBTC() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("GetLastPriceBTC")
+	return m.Price
+}
+
+// GetDetailedBalances retourne une copie des soldes scriptés
+func (m *MockExchange) GetDetailedBalances() (map[string]common.DetailedBalance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetDetailedBalances"); err != nil {
+		return nil, err
+	}
+	balances := make(map[string]common.DetailedBalance, len(m.Balances))
+	for asset, balance := range m.Balances {
+		balances[asset] = balance
+	}
+	return balances, nil
+}
+
+// SetBaseURL est sans effet pour un exchange simulé
+func (m *MockExchange) SetBaseURL(url string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("SetBaseURL", url)
+}
+
+// CreateOrder crée un ordre ouvert et retourne son ID, avec la réponse brute {"orderId":"<id>"}.
+// Un ordre au marché est exécuté aussitôt au prix indiqué.
+func (m *MockExchange) CreateOrder(side, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clientOrderID := common.ClientOrderIDRequested(opts)
+	market := common.MarketRequested(opts)
+	expireAt := common.ExpiryRequested(opts)
+	if err := m.record("CreateOrder", side, price, quantity, common.PostOnlyRequested(opts), clientOrderID, market, expireAt); err != nil {
+		return common.OrderCreateResult{}, err
+	}
+
+	priceValue, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return common.OrderCreateResult{}, fmt.Errorf("prix invalide: %s", price)
+	}
+	quantityValue, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return common.OrderCreateResult{}, fmt.Errorf("quantité invalide: %s", quantity)
+	}
+
+	m.nextId++
+	id := strconv.FormatInt(m.nextId, 10)
+	m.addOrder(id, side, priceValue, quantityValue)
+	m.orders[id]["clientOrderId"] = clientOrderID
+	if !expireAt.IsZero() {
+		m.orders[id]["goodTillDate"] = expireAt.UnixMilli()
+	}
+	if market {
+		m.orders[id]["type"] = "MARKET"
+		m.orders[id]["status"] = "FILLED"
+		m.orders[id]["executedQty"] = m.orders[id]["origQty"]
+		m.orders[id]["cummulativeQuoteQty"] = strconv.FormatFloat(priceValue*quantityValue, 'f', 8, 64)
+	}
+	body, _ := json.Marshal(map[string]string{"orderId": id})
+	return common.OrderCreateResult{OrderID: id, Raw: body}, nil
+}
+
+// CreateMakerOrder crée un ordre ouvert au prix indiqué
+func (m *MockExchange) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
+	return m.CreateOrder(side, strconv.FormatFloat(price, 'f', 2, 64), quantity)
+}
+
+// GetOrderById retourne l'ordre au format Binance, ou une erreur 404 s'il est inconnu
+func (m *MockExchange) GetOrderById(id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetOrderById", id); err != nil {
+		return nil, err
+	}
+	order, ok := m.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("HTTP status 404 Not Found - ordre %s inconnu", id)
+	}
+	return json.Marshal(order)
+}
+
+// GetOrderStatus retourne l'état d'un ordre, ou une erreur 404 s'il est inconnu
+func (m *MockExchange) GetOrderStatus(id string) (common.OrderStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetOrderStatus", id); err != nil {
+		return common.OrderStatus{}, err
+	}
+	order, ok := m.orders[id]
+	if !ok {
+		return common.OrderStatus{}, fmt.Errorf("HTTP status 404 Not Found - ordre %s inconnu", id)
+	}
+
+	executedQty, _ := strconv.ParseFloat(order["executedQty"].(string), 64)
+	quote, _ := strconv.ParseFloat(order["cummulativeQuoteQty"].(string), 64)
+	status := common.OrderStatus{
+		ID:           id,
+		State:        common.OrderOpen,
+		ExecutedQty:  executedQty,
+		AvgFillPrice: common.AveragePrice(quote, executedQty),
+		UpdatedAt:    time.UnixMilli(order["updateTime"].(int64)),
+	}
+	if executedQty > 0 && quote > 0 {
+		status.QuoteAmount = quote
+	}
+	if executedQty > 0 {
+		status.BaseFee = m.BaseFees[id]
+	}
+	switch order["status"] {
+	case "PARTIALLY_FILLED":
+		status.State = common.OrderPartiallyFilled
+	case "FILLED":
+		status.State = common.OrderFilled
+	case "REJECTED":
+		status.State = common.OrderRejected
+	case "CANCELED", "EXPIRED":
+		status.State = common.OrderCancelled
+		// Les jambes d'OCO expirent aussi (EXPIRED), sans date d'expiration atteinte
+		status.Expired = order["expired"] == true
+	}
+	return status, nil
+}
+
+// SupportsOrderExpiry indique si SupportsExpiry est activé
+func (m *MockExchange) SupportsOrderExpiry() bool {
+	return m.SupportsExpiry
+}
+
+// CancelOrder annule un ordre ouvert
+func (m *MockExchange) CancelOrder(orderID string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("CancelOrder", orderID); err != nil {
+		return nil, err
+	}
+	order, ok := m.orders[orderID]
+	if !ok || !orderOpen(order) {
+		return nil, fmt.Errorf("HTTP status 400 - {\"code\":-2011,\"msg\":\"Unknown order sent.\"}")
+	}
+	order["status"] = "CANCELED"
+	return json.Marshal(order)
+}
+
+// CancelOrderIdempotent annule un ordre ouvert; un ordre exécuté, annulé ou inconnu donne AlreadyGone
+func (m *MockExchange) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("CancelOrderIdempotent", orderID); err != nil {
+		return common.CancelFailed, err
+	}
+	order, ok := m.orders[orderID]
+	if !ok || !orderOpen(order) {
+		return common.AlreadyGone, nil
+	}
+	order["status"] = "CANCELED"
+	return common.Cancelled, nil
+}
+
+// GetExchangeInfo retourne une réponse vide
+func (m *MockExchange) GetExchangeInfo() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return []byte("{}"), m.record("GetExchangeInfo")
+}
+
+// PairStatus retourne PairState, la paire n'étant négociable que dans l'état TRADING
+func (m *MockExchange) PairStatus() (common.PairStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := m.PairState
+	if state == "" {
+		state = "TRADING"
+	}
+	return common.PairStatus{Status: state, Trading: state == "TRADING"}, m.record("PairStatus")
+}
+
+// GetAccountInfo retourne une réponse vide
+func (m *MockExchange) GetAccountInfo() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return []byte("{}"), m.record("GetAccountInfo")
+}
+
+// Precision retourne les pas de Steps, complétés par DefaultPrecision
+func (m *MockExchange) Precision() common.Precision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	precision := m.Steps
+	if precision.PriceTick <= 0 {
+		precision.PriceTick = common.DefaultPrecision.PriceTick
+	}
+	if precision.QuantityStep <= 0 {
+		precision.QuantityStep = common.DefaultPrecision.QuantityStep
+	}
+	return precision
+}
+
+// FormatPrice arrondit un prix au pas de prix de Steps
+func (m *MockExchange) FormatPrice(price float64) string {
+	return m.Precision().FormatPrice(price)
+}
+
+// FormatQuantity arrondit une quantité au pas de quantité de Steps
+func (m *MockExchange) FormatQuantity(quantity float64) string {
+	return m.Precision().FormatQuantity(quantity)
+}
+
+// GetOrderFees retourne les frais scriptés de l'ordre
+func (m *MockExchange) GetOrderFees(orderId string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetOrderFees", orderId); err != nil {
+		return 0, err
+	}
+	fees, ok := m.Fees[orderId]
+	if !ok {
+		return 0, fmt.Errorf("frais de l'ordre %s non scriptés", orderId)
+	}
+	return fees, nil
+}
+
+// AdjustSellPriceForFees délègue au calcul scripté
+func (m *MockExchange) AdjustSellPriceForFees(buyPrice float64, quantity float64, buyOrderId string) (float64, error) {
+	m.mu.Lock()
+	adjust := m.AdjustSellPrice
+	err := m.record("AdjustSellPriceForFees", buyPrice, quantity, buyOrderId)
+	m.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	if adjust == nil {
+		return 0, fmt.Errorf("ajustement du prix de vente non scripté")
+	}
+	return adjust(buyPrice, quantity, buyOrderId)
+}
+
+// GetTradeHistory retourne les exécutions scriptées postérieures à since
+func (m *MockExchange) GetTradeHistory(since time.Time) ([]common.Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetTradeHistory", since); err != nil {
+		return nil, err
+	}
+	var trades []common.Trade
+	for _, trade := range m.Trades {
+		if !trade.Time.Before(since) {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// GetOpenOrders retourne les ordres ouverts scriptés suivis des ordres NEW du mock, par ID
+func (m *MockExchange) GetOpenOrders() ([]common.OpenOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetOpenOrders"); err != nil {
+		return nil, err
+	}
+
+	orders := append([]common.OpenOrder(nil), m.OpenOrders...)
+	ids := make([]string, 0, len(m.orders))
+	for id, order := range m.orders {
+		if orderOpen(order) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		order := m.orders[id]
+		price, _ := strconv.ParseFloat(order["price"].(string), 64)
+		quantity, _ := strconv.ParseFloat(order["origQty"].(string), 64)
+		clientOrderID, _ := order["clientOrderId"].(string)
+		orders = append(orders, common.OpenOrder{
+			ID:            id,
+			Side:          order["side"].(string),
+			Price:         price,
+			Quantity:      quantity,
+			ClientOrderID: clientOrderID,
+			CreatedAt:     time.UnixMilli(order["time"].(int64)),
+		})
+	}
+	return orders, nil
+}
+
+// CreateOCOOrder crée les deux jambes d'une vente OCO si SupportsOCO est activé
+func (m *MockExchange) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("CreateOCOOrder", sellPrice, stopPrice, stopLimitPrice, quantity); err != nil {
+		return common.OCOOrder{}, err
+	}
+	if !m.SupportsOCO {
+		return common.OCOOrder{}, common.ErrOCONotSupported
+	}
+
+	quantityValue, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return common.OCOOrder{}, fmt.Errorf("quantité invalide: %s", quantity)
+	}
+
+	m.nextId += 3
+	order := common.OCOOrder{
+		ListID:  strconv.FormatInt(m.nextId-2, 10),
+		LimitID: strconv.FormatInt(m.nextId-1, 10),
+		StopID:  strconv.FormatInt(m.nextId, 10),
+	}
+	m.addOrder(order.LimitID, "SELL", sellPrice, quantityValue)
+	m.addOrder(order.StopID, "SELL", stopLimitPrice, quantityValue)
+	m.ocoPeers[order.LimitID] = order.StopID
+	m.ocoPeers[order.StopID] = order.LimitID
+	return order, nil
+}
+
+// Vérification à la compilation
+var _ common.Exchange = (*MockExchange)(nil)
+
+// GetRateLimitStatus retourne RateLimit
+func (m *MockExchange) GetRateLimitStatus() common.RateLimitStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.RateLimit
+}
@@ -0,0 +1,134 @@
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Route associe une requête (méthode et chemin) à une réponse enregistrée
+type Route struct {
+	Method string
+	Path   string
+	// Code HTTP de la réponse (200 par défaut)
+	Status int
+	// Fichier de réponse dans le dossier testdata du paquet testé
+	File string
+	// Réponse utilisée si File est vide
+	Body string
+}
+
+// Request est une requête reçue par FakeServer
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Verifier contrôle l'authentification d'une requête (signature, clé API...)
+type Verifier func(r *http.Request, body []byte) error
+
+// FakeServer est un serveur HTTP qui rejoue des réponses enregistrées d'un exchange.
+// Chaque requête est vérifiée par le Verifier fourni: une signature invalide ou une
+// route inconnue fait échouer le test.
+type FakeServer struct {
+	*httptest.Server
+
+	t        testing.TB
+	verify   Verifier
+	routes   map[string]Route
+	bodies   map[string][]byte
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewFakeServer démarre un serveur rejouant les routes indiquées. Il est arrêté à la fin du test.
+func NewFakeServer(t testing.TB, verify Verifier, routes ...Route) *FakeServer {
+	t.Helper()
+
+	s := &FakeServer{
+		t:      t,
+		verify: verify,
+		routes: make(map[string]Route),
+		bodies: make(map[string][]byte),
+	}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		body := []byte(route.Body)
+		if route.File != "" {
+			content, err := os.ReadFile(filepath.Join("testdata", route.File))
+			if err != nil {
+				t.Fatalf("réponse enregistrée %s: %v", route.File, err)
+			}
+			body = content
+		}
+		s.routes[key] = route
+		s.bodies[key] = body
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *FakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	key := r.Method + " " + r.URL.Path
+	route, ok := s.routes[key]
+	if !ok {
+		s.t.Errorf("requête inattendue: %s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery)
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.verify != nil {
+		if err := s.verify(r, body); err != nil {
+			s.t.Errorf("%s: authentification invalide: %v", key, err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(s.bodies[key])
+}
+
+// Requests retourne une copie des requêtes reçues, dans l'ordre
+func (s *FakeServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo retourne les requêtes reçues pour une méthode et un chemin
+func (s *FakeServer) RequestsTo(method, path string) []Request {
+	var requests []Request
+	for _, request := range s.Requests() {
+		if request.Method == method && request.Path == path {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
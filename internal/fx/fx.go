@@ -0,0 +1,198 @@
+// internal/fx/fx.go
+
+// Package fx convertit les montants en USDC dans une devise d'affichage à partir des taux de
+// référence quotidiens de la BCE, conservés dans un cache local. L'USDC est assimilé au dollar.
+package fx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSourceURL est l'historique complet des taux de référence de la BCE (1 EUR = x devise)
+const DefaultSourceURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+
+// dateLayout est le format des dates de publication des taux
+const dateLayout = "2006-01-02"
+
+// Rates est l'historique des taux publiés, par date puis par devise, exprimés pour 1 EUR
+type Rates struct {
+	Source    string                        `json:"source"`
+	UpdatedAt time.Time                     `json:"updatedAt"`
+	Days      map[string]map[string]float64 `json:"days"`
+
+	dates []string // dates publiées triées
+}
+
+// NewRates crée un historique à partir des taux publiés par date
+func NewRates(source string, days map[string]map[string]float64) *Rates {
+	rates := &Rates{Source: source, UpdatedAt: time.Now(), Days: days}
+	rates.sortDates()
+	return rates
+}
+
+// sortDates trie les dates publiées, une fois pour toutes les recherches
+func (r *Rates) sortDates() {
+	r.dates = make([]string, 0, len(r.Days))
+	for date := range r.Days {
+		r.dates = append(r.dates, date)
+	}
+	sort.Strings(r.dates)
+}
+
+// Rate est le taux appliqué à une conversion et la date de sa publication
+type Rate struct {
+	Currency string    `json:"currency"`
+	Value    float64   `json:"rate"` // 1 USDC = Value devise
+	Date     time.Time `json:"date"`
+}
+
+// Convert convertit un montant en USDC dans la devise du taux
+func (r Rate) Convert(amountUSDC float64) float64 {
+	return amountUSDC * r.Value
+}
+
+// ecbEnvelope est le document XML des taux de la BCE
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB lit un fichier de taux de la BCE (quotidien, 90 jours ou historique complet)
+func ParseECB(reader io.Reader) (map[string]map[string]float64, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(reader).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("taux de la BCE illisibles: %w", err)
+	}
+
+	days := make(map[string]map[string]float64, len(envelope.Days))
+	for _, day := range envelope.Days {
+		if _, err := time.Parse(dateLayout, day.Time); err != nil {
+			continue
+		}
+		rates := make(map[string]float64, len(day.Rates))
+		for _, rate := range day.Rates {
+			if rate.Rate > 0 {
+				rates[strings.ToUpper(rate.Currency)] = rate.Rate
+			}
+		}
+		if len(rates) > 0 {
+			days[day.Time] = rates
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("aucun taux dans le fichier de la BCE")
+	}
+	return days, nil
+}
+
+// USDCRate retourne le taux USDC -> devise publié à la date indiquée, ou au dernier jour
+// publié qui la précède (week-ends et jours fériés). Une date antérieure à l'historique
+// prend le premier taux connu.
+func (r *Rates) USDCRate(currency string, at time.Time) (Rate, error) {
+	currency = strings.ToUpper(currency)
+	if r == nil || len(r.dates) == 0 {
+		return Rate{}, fmt.Errorf("aucun taux de change disponible")
+	}
+
+	// Dernière date publiée au plus tard le jour demandé
+	day := at.Format(dateLayout)
+	index := sort.SearchStrings(r.dates, day)
+	if index == len(r.dates) || r.dates[index] != day {
+		index--
+	}
+	if index < 0 {
+		index = 0
+	}
+
+	for i := index; i >= 0; i-- {
+		rates := r.Days[r.dates[i]]
+		usd := rates["USD"]
+		target := 1.0
+		if currency != "EUR" {
+			target = rates[currency]
+		}
+		if usd <= 0 || target <= 0 {
+			continue
+		}
+		date, _ := time.Parse(dateLayout, r.dates[i])
+		return Rate{Currency: currency, Value: target / usd, Date: date}, nil
+	}
+	return Rate{}, fmt.Errorf("aucun taux %s publié avant le %s", currency, day)
+}
+
+// Latest retourne le dernier taux USDC -> devise publié
+func (r *Rates) Latest(currency string) (Rate, error) {
+	return r.USDCRate(currency, time.Now())
+}
+
+// Load retourne les taux du cache local, téléchargés à nouveau depuis sourceURL lorsque le cache
+// a plus de maxAge ou provient d'une autre source. Si le téléchargement échoue, le cache est
+// utilisé tel quel et l'erreur n'est retournée qu'en l'absence de tout taux.
+func Load(cachePath, sourceURL string, maxAge time.Duration) (*Rates, error) {
+	cached, cacheErr := readCache(cachePath)
+	if cached != nil && cached.Source == sourceURL && time.Since(cached.UpdatedAt) < maxAge {
+		return cached, nil
+	}
+
+	days, err := download(sourceURL)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		if cacheErr != nil && !os.IsNotExist(cacheErr) {
+			return nil, fmt.Errorf("%w (cache: %v)", err, cacheErr)
+		}
+		return nil, err
+	}
+
+	rates := NewRates(sourceURL, days)
+	if content, err := json.Marshal(rates); err == nil {
+		// Un cache non écrit sera simplement retéléchargé
+		_ = os.WriteFile(cachePath, content, 0644)
+	}
+	return rates, nil
+}
+
+// readCache lit le cache local des taux
+func readCache(path string) (*Rates, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates Rates
+	if err := json.Unmarshal(content, &rates); err != nil {
+		return nil, fmt.Errorf("cache des taux de change invalide: %w", err)
+	}
+	if len(rates.Days) == 0 {
+		return nil, fmt.Errorf("cache des taux de change vide")
+	}
+	rates.sortDates()
+	return &rates, nil
+}
+
+// download télécharge et lit le fichier de taux de la source
+func download(sourceURL string) (map[string]map[string]float64, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Get(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("téléchargement des taux de change: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("téléchargement des taux de change: HTTP %d", response.StatusCode)
+	}
+	return ParseECB(response.Body)
+}
@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ecbSample reprend le format des fichiers de la BCE (le plus récent en premier)
+const ecbSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2025-01-06">
+			<Cube currency="USD" rate="1.0400"/>
+			<Cube currency="GBP" rate="0.8320"/>
+		</Cube>
+		<Cube time="2025-01-03">
+			<Cube currency="USD" rate="1.0250"/>
+			<Cube currency="GBP" rate="0.8300"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func date(value string) time.Time {
+	parsed, _ := time.Parse("2006-01-02", value)
+	return parsed.Add(15 * time.Hour)
+}
+
+func TestUSDCRate(t *testing.T) {
+	days, err := ParseECB(strings.NewReader(ecbSample))
+	if err != nil {
+		t.Fatalf("ParseECB: %v", err)
+	}
+	rates := NewRates("test", days)
+
+	cases := []struct {
+		currency, at, published string
+		want                    float64
+	}{
+		{"EUR", "2025-01-06", "2025-01-06", 1 / 1.04},
+		// Samedi: taux du vendredi précédent
+		{"eur", "2025-01-04", "2025-01-03", 1 / 1.025},
+		{"GBP", "2025-01-06", "2025-01-06", 0.832 / 1.04},
+		// Avant l'historique: premier taux connu
+		{"EUR", "2024-12-01", "2025-01-03", 1 / 1.025},
+	}
+	for _, c := range cases {
+		rate, err := rates.USDCRate(c.currency, date(c.at))
+		if err != nil {
+			t.Fatalf("%s au %s: %v", c.currency, c.at, err)
+		}
+		if math.Abs(rate.Value-c.want) > 1e-12 || rate.Date.Format("2006-01-02") != c.published {
+			t.Errorf("%s au %s: %.6f publié le %s, attendu %.6f le %s", c.currency, c.at,
+				rate.Value, rate.Date.Format("2006-01-02"), c.want, c.published)
+		}
+	}
+	if math.Abs(Rate{Value: 0.9}.Convert(100)-90) > 1e-12 {
+		t.Error("100 USDC au taux 0.9 devraient valoir 90")
+	}
+	if _, err := rates.USDCRate("CHF", date("2025-01-06")); err == nil {
+		t.Error("une devise absente des taux devrait être refusée")
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	downloads := 0
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		if !available {
+			http.Error(w, "indisponible", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(ecbSample))
+	}))
+	defer server.Close()
+
+	cache := filepath.Join(t.TempDir(), "fx_rates.json")
+	if _, err := Load(cache, server.URL, time.Hour); err != nil {
+		t.Fatalf("premier chargement: %v", err)
+	}
+	// Cache récent: pas de nouveau téléchargement
+	if _, err := Load(cache, server.URL, time.Hour); err != nil || downloads != 1 {
+		t.Fatalf("chargement depuis le cache: %d téléchargement(s), %v", downloads, err)
+	}
+
+	// Cache périmé et source indisponible: les taux en cache restent utilisés
+	available = false
+	rates, err := Load(cache, server.URL, 0)
+	if err != nil || downloads != 2 {
+		t.Fatalf("source indisponible: %d téléchargement(s), %v", downloads, err)
+	}
+	if _, err := rates.Latest("EUR"); err != nil {
+		t.Errorf("taux en cache inutilisables: %v", err)
+	}
+
+	// Sans cache ni source: erreur
+	os.Remove(cache)
+	if _, err := Load(cache, server.URL, time.Hour); err == nil {
+		t.Error("une erreur était attendue sans cache ni source")
+	}
+}
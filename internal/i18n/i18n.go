@@ -0,0 +1,151 @@
+// internal/i18n/i18n.go
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLanguage est la langue utilisée si LANGUAGE n'est pas défini ou pas supporté
+const DefaultLanguage = "fr"
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// Catalogues de messages par langue, chargés une seule fois
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]string
+	loadErr  error
+
+	mu      sync.RWMutex
+	current = DefaultLanguage
+)
+
+// dateLayouts associe à chaque langue le format des dates et des dates avec heure
+var dateLayouts = map[string][2]string{
+	"fr": {"02/01/2006", "02/01/2006 15:04"},
+	"en": {"2006-01-02", "2006-01-02 15:04"},
+}
+
+// load lit les catalogues embarqués (locales/<langue>.json)
+func load() {
+	loadOnce.Do(func() {
+		catalogs = make(map[string]map[string]string)
+		entries, err := localesFS.ReadDir("locales")
+		if err != nil {
+			loadErr = err
+			return
+		}
+		for _, entry := range entries {
+			content, err := localesFS.ReadFile("locales/" + entry.Name())
+			if err != nil {
+				loadErr = err
+				return
+			}
+			messages := make(map[string]string)
+			if err := json.Unmarshal(content, &messages); err != nil {
+				loadErr = fmt.Errorf("catalogue %s invalide: %w", entry.Name(), err)
+				return
+			}
+			catalogs[strings.TrimSuffix(entry.Name(), ".json")] = messages
+		}
+	})
+}
+
+// Normalize réduit un code de langue (en_US.UTF-8, EN, fr-FR...) à sa langue sur deux lettres
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	// LANGUAGE peut contenir une liste de préférences (en_US:en)
+	lang, _, _ = strings.Cut(lang, ":")
+	if len(lang) > 2 {
+		lang = lang[:2]
+	}
+	return lang
+}
+
+// Supported indique si un catalogue existe pour la langue
+func Supported(lang string) bool {
+	load()
+	_, ok := catalogs[Normalize(lang)]
+	return ok
+}
+
+// SetLanguage choisit la langue des messages. Une langue non supportée laisse
+// la langue courante inchangée et retourne une erreur.
+func SetLanguage(lang string) error {
+	load()
+	if loadErr != nil {
+		return loadErr
+	}
+
+	lang = Normalize(lang)
+	if _, ok := catalogs[lang]; !ok {
+		return fmt.Errorf("langue %q non supportée (fr, en)", lang)
+	}
+
+	mu.Lock()
+	current = lang
+	mu.Unlock()
+	return nil
+}
+
+// Language retourne la langue courante
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T retourne le message de la clé dans la langue courante, formaté avec args s'ils sont fournis.
+// Sans argument, le message est retourné tel quel et peut servir de format (color.Red(i18n.T(...), x)).
+// Une clé absente se rabat sur le français, puis sur la clé elle-même.
+func T(key string, args ...interface{}) string {
+	load()
+	lang := Language()
+
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// layouts retourne les formats de date de la langue courante (ceux du français à défaut)
+func layouts() [2]string {
+	if layout, ok := dateLayouts[Language()]; ok {
+		return layout
+	}
+	return dateLayouts[DefaultLanguage]
+}
+
+// DateLayout retourne le format des dates de la langue courante
+func DateLayout() string {
+	return layouts()[0]
+}
+
+// DateTimeLayout retourne le format des dates avec heure de la langue courante
+func DateTimeLayout() string {
+	return layouts()[1]
+}
+
+// FormatDate formate une date selon la langue courante (02/01/2006 ou 2006-01-02)
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout())
+}
+
+// FormatDateTime formate une date et une heure selon la langue courante
+func FormatDateTime(t time.Time) string {
+	return t.Format(DateTimeLayout())
+}
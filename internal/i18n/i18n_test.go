@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// formatVerb reconnaît les verbes de format fmt (%d, %.2f, %s...) hors %%
+var formatVerb = regexp.MustCompile(`%[-+#0-9.]*[a-zA-Z]`)
+
+func verbs(message string) []string {
+	return formatVerb.FindAllString(strings.ReplaceAll(message, "%%", ""), -1)
+}
+
+func TestCatalogsMatch(t *testing.T) {
+	load()
+	if loadErr != nil {
+		t.Fatalf("chargement des catalogues: %v", loadErr)
+	}
+
+	fr, en := catalogs["fr"], catalogs["en"]
+	for key, message := range fr {
+		translation, ok := en[key]
+		if !ok {
+			t.Errorf("clé %q absente du catalogue en", key)
+			continue
+		}
+		if strings.Join(verbs(message), " ") != strings.Join(verbs(translation), " ") {
+			t.Errorf("clé %q: formats différents entre fr %q et en %q", key, message, translation)
+		}
+	}
+	for key := range en {
+		if _, ok := fr[key]; !ok {
+			t.Errorf("clé %q absente du catalogue fr", key)
+		}
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	if err := SetLanguage("en_US.UTF-8"); err != nil {
+		t.Fatalf("SetLanguage(en_US.UTF-8): %v", err)
+	}
+	if got := T("update.buy_filled", 12); got != "Cycle 12: buy order filled" {
+		t.Errorf("T en = %q", got)
+	}
+	if got := FormatDate(time.Date(2025, 3, 7, 9, 5, 0, 0, time.UTC)); got != "2025-03-07" {
+		t.Errorf("FormatDate en = %q", got)
+	}
+
+	if err := SetLanguage("de"); err == nil {
+		t.Error("une langue sans catalogue devrait être refusée")
+	}
+	if Language() != "en" {
+		t.Errorf("une langue refusée ne doit pas changer la langue courante, obtenu %q", Language())
+	}
+
+	if err := SetLanguage("FR"); err != nil {
+		t.Fatalf("SetLanguage(FR): %v", err)
+	}
+	if got := T("update.buy_filled", 12); got != "Cycle 12: Ordre d'achat exécuté" {
+		t.Errorf("T fr = %q", got)
+	}
+	if got := FormatDateTime(time.Date(2025, 3, 7, 9, 5, 0, 0, time.UTC)); got != "07/03/2025 09:05" {
+		t.Errorf("FormatDateTime fr = %q", got)
+	}
+	if got := T("cle.inconnue"); got != "cle.inconnue" {
+		t.Errorf("une clé inconnue devrait être retournée telle quelle, obtenu %q", got)
+	}
+}
+
+// TestKeysExist vérifie que chaque clé utilisée dans le code et les templates existe dans les catalogues
+func TestKeysExist(t *testing.T) {
+	load()
+	used := regexp.MustCompile(`(?:i18n\.T\(|\{\{ t )"([a-z0-9_.]+)"`)
+
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".html")) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range used.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := catalogs[DefaultLanguage][match[1]]; !ok {
+				t.Errorf("%s: clé %q absente des catalogues", path, match[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
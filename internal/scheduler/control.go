@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"main/internal/config"
+)
+
+// Fichiers partagés entre le daemon et le serveur web, dans le répertoire de configuration
+// (config.Path) comme tasks.conf
+const (
+	tasksConfigFile = "tasks.conf"
+	runNowFile      = "scheduler_run_now.txt" // une demande d'exécution immédiate par ligne
+	statusFile      = "scheduler_status.json" // état publié par le daemon
+)
+
+// Journaux du daemon, relus par l'onglet Logs du tableau de bord (dans le répertoire de configuration)
+const (
+	LogFile       = "planner.log"        // sortie du daemon: planificateur et commandes lancées
+	DaemonLogFile = "planner_daemon.log" // démarrage, arrêt et signaux du daemon
+)
+
+// ipcInterval est la fréquence à laquelle le daemon traite les demandes du serveur web
+const ipcInterval = 5 * time.Second
+
+// statusTimeout est le délai au-delà duquel un état non rafraîchi signifie que le daemon est arrêté
+const statusTimeout = 3 * ipcInterval
+
+// TaskStatus est l'état d'exécution d'une tâche connu du daemon
+type TaskStatus struct {
+	Name            string    `json:"name"`
+	Enabled         bool      `json:"enabled"`
+	LastRunTime     time.Time `json:"lastRunTime,omitempty"`
+	NextScheduledAt time.Time `json:"nextScheduledAt"`
+	LastError       string    `json:"lastError,omitempty"`
+}
+
+// DaemonStatus est l'état publié par le planificateur en mode daemon
+type DaemonStatus struct {
+	Running   bool         `json:"running"`
+	PID       int          `json:"pid"`
+	StartedAt time.Time    `json:"startedAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Tasks     []TaskStatus `json:"tasks"`
+}
+
+// findTask retourne la tâche portant ce nom (le verrou doit être détenu)
+func (s *Scheduler) findTask(name string) *Task {
+	for _, task := range s.tasks {
+		if task.Config.Name == name {
+			return task
+		}
+	}
+	return nil
+}
+
+// SetTaskEnabled active ou désactive une tâche et enregistre tasks.conf
+func (s *Scheduler) SetTaskEnabled(name string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := s.findTask(name)
+	if task == nil {
+		return fmt.Errorf("tâche non trouvée: %s", name)
+	}
+
+	task.Config.Enabled = enabled
+	if enabled {
+		task.Config.NextScheduledAt = s.calculateNextRun(task.Config)
+	}
+
+	if err := s.SaveTasksToConfig(); err != nil {
+		return err
+	}
+	s.tasksModTime = tasksFileModTime()
+	return nil
+}
+
+// RunNow exécute immédiatement une tâche sans modifier sa planification
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	task := s.findTask(name)
+	s.mu.Unlock()
+
+	if task == nil {
+		return fmt.Errorf("tâche non trouvée: %s", name)
+	}
+
+	s.logger.Info("Exécution immédiate demandée pour la tâche: %s", name)
+	go s.executeTask(task)
+	return nil
+}
+
+// RequestRunNow demande au daemon d'exécuter une tâche lors de sa prochaine vérification
+func RequestRunNow(name string) error {
+	file, err := os.OpenFile(config.Path(runNowFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erreur lors de l'écriture de la demande d'exécution: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintln(file, name)
+	return err
+}
+
+// ReadDaemonStatus lit le dernier état publié par le daemon. Running vaut false
+// si le daemon ne l'a pas rafraîchi récemment.
+func ReadDaemonStatus() (DaemonStatus, error) {
+	var status DaemonStatus
+
+	content, err := os.ReadFile(config.Path(statusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status, nil
+		}
+		return status, err
+	}
+
+	if err := json.Unmarshal(content, &status); err != nil {
+		return status, fmt.Errorf("fichier %s invalide: %w", statusFile, err)
+	}
+
+	if time.Since(status.UpdatedAt) > statusTimeout {
+		status.Running = false
+	}
+	return status, nil
+}
+
+// processIPC traite les échanges avec le serveur web: rechargement de tasks.conf,
+// demandes d'exécution immédiate et publication de l'état
+func (s *Scheduler) processIPC() {
+	s.reloadTasksIfChanged()
+	s.processRunNowRequests()
+	s.publishStatus(true)
+}
+
+// taskHistory conserve l'historique d'une tâche lors d'un rechargement
+type taskHistory struct {
+	lastRun   time.Time
+	nextRun   time.Time
+	lastError string
+}
+
+// reloadTasksIfChanged recharge les tâches si tasks.conf a été modifié par un autre processus
+func (s *Scheduler) reloadTasksIfChanged() {
+	modTime := tasksFileModTime()
+
+	s.mu.Lock()
+	unchanged := modTime.Equal(s.tasksModTime)
+	previous := make(map[string]taskHistory, len(s.tasks))
+	for _, task := range s.tasks {
+		previous[task.Config.Name] = taskHistory{
+			lastRun:   task.Config.LastRunTime,
+			nextRun:   task.Config.NextScheduledAt,
+			lastError: task.lastError,
+		}
+	}
+	s.mu.Unlock()
+
+	if unchanged {
+		return
+	}
+
+	if err := s.LoadTasksFromConfig(); err != nil {
+		s.logger.Error("Erreur lors du rechargement des tâches: %v", err)
+		return
+	}
+
+	// Conserver l'historique d'exécution des tâches déjà connues
+	s.mu.Lock()
+	for _, task := range s.tasks {
+		if history, ok := previous[task.Config.Name]; ok {
+			task.Config.LastRunTime = history.lastRun
+			task.lastError = history.lastError
+			if task.Config.Enabled && !history.nextRun.IsZero() && history.nextRun.After(time.Now()) {
+				task.Config.NextScheduledAt = history.nextRun
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	s.logger.Info("Fichier %s modifié: tâches rechargées", tasksConfigFile)
+}
+
+// processRunNowRequests exécute les tâches demandées par le serveur web
+func (s *Scheduler) processRunNowRequests() {
+	// Renommer le fichier avant lecture pour ne perdre aucune demande écrite entre-temps
+	processing := config.Path(runNowFile + ".processing")
+	if err := os.Rename(config.Path(runNowFile), processing); err != nil {
+		return // Aucune demande en attente
+	}
+	content, err := os.ReadFile(processing)
+	os.Remove(processing)
+	if err != nil {
+		s.logger.Error("Erreur lors de la lecture des demandes d'exécution: %v", err)
+		return
+	}
+
+	for _, name := range strings.Split(string(content), "\n") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := s.RunNow(name); err != nil {
+			s.logger.Error("Demande d'exécution ignorée: %v", err)
+		}
+	}
+}
+
+// publishStatus écrit l'état du daemon pour le serveur web
+func (s *Scheduler) publishStatus(running bool) {
+	s.mu.Lock()
+	status := DaemonStatus{
+		Running:   running,
+		PID:       os.Getpid(),
+		StartedAt: s.startedAt,
+		UpdatedAt: time.Now(),
+		Tasks:     make([]TaskStatus, 0, len(s.tasks)),
+	}
+	for _, task := range s.tasks {
+		status.Tasks = append(status.Tasks, TaskStatus{
+			Name:            task.Config.Name,
+			Enabled:         task.Config.Enabled,
+			LastRunTime:     task.Config.LastRunTime,
+			NextScheduledAt: task.Config.NextScheduledAt,
+			LastError:       task.lastError,
+		})
+	}
+	s.mu.Unlock()
+
+	content, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		s.logger.Error("Erreur lors de la sérialisation de l'état du planificateur: %v", err)
+		return
+	}
+	if err := os.WriteFile(config.Path(statusFile), content, 0644); err != nil {
+		s.logger.Error("Erreur lors de l'écriture de %s: %v", statusFile, err)
+	}
+}
+
+// tasksFileModTime retourne la date de modification de tasks.conf (zéro s'il n'existe pas)
+func tasksFileModTime() time.Time {
+	info, err := os.Stat(config.Path(tasksConfigFile))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"main/internal/config"
+	"main/internal/types"
+)
+
+// historyFile conserve l'historique des exécutions, partagé comme statusFile avec le serveur web.
+// Le daemon n'ouvre pas la base de données, réservée aux commandes qu'il lance.
+const historyFile = "scheduler_history.json"
+
+// DefaultHistorySize est le nombre d'exécutions conservées si SCHEDULER_HISTORY_SIZE n'est pas défini
+const DefaultHistorySize = 200
+
+// TaskRun est le résultat d'une exécution de tâche
+type TaskRun struct {
+	Task      string        `json:"task"`
+	Type      string        `json:"type"`
+	Exchange  string        `json:"exchange,omitempty"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"` // En nanosecondes
+	Success   bool          `json:"success"`
+	Skipped   bool          `json:"skipped,omitempty"` // Commande volontairement sans effet (limite d'exposition...)
+	Error     string        `json:"error,omitempty"`
+	CycleIDs  []int32       `json:"cycleIds,omitempty"` // Cycles créés par une tâche "new"
+	// Cycles annulés par une tâche "cancel_stale"
+	CancelledIDs []int32 `json:"cancelledCycleIds,omitempty"`
+}
+
+// Outcome retourne le résultat de l'exécution pour l'affichage
+func (r TaskRun) Outcome() string {
+	switch {
+	case !r.Success:
+		return "échec"
+	case r.Skipped:
+		return "ignorée"
+	default:
+		return "succès"
+	}
+}
+
+// TaskRunRepository enregistre les exécutions des tâches dans historyFile, en ne conservant
+// que les maxRuns plus récentes
+type TaskRunRepository struct {
+	path    string
+	maxRuns int
+	mu      sync.Mutex
+}
+
+// NewTaskRunRepository crée le repository de l'historique (maxRuns <= 0: DefaultHistorySize)
+func NewTaskRunRepository(maxRuns int) *TaskRunRepository {
+	if maxRuns <= 0 {
+		maxRuns = DefaultHistorySize
+	}
+	return &TaskRunRepository{path: config.Path(historyFile), maxRuns: maxRuns}
+}
+
+// Save ajoute une exécution et supprime les plus anciennes au-delà de la limite
+func (r *TaskRunRepository) Save(run TaskRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, err := r.load()
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > r.maxRuns {
+		runs = runs[len(runs)-r.maxRuns:]
+	}
+
+	content, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erreur lors de la sérialisation de l'historique: %w", err)
+	}
+	// Écriture dans un fichier temporaire puis renommage: le serveur web ne lit jamais un fichier partiel
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("erreur lors de l'écriture de %s: %w", r.path, err)
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// FindRecent retourne les limit dernières exécutions, de la plus récente à la plus ancienne.
+// Si task n'est pas vide, seules les exécutions de cette tâche sont retournées (limit <= 0: toutes).
+func (r *TaskRunRepository) FindRecent(task string, limit int) ([]TaskRun, error) {
+	r.mu.Lock()
+	runs, err := r.load()
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]TaskRun, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		if task != "" && runs[i].Task != task {
+			continue
+		}
+		recent = append(recent, runs[i])
+		if limit > 0 && len(recent) == limit {
+			break
+		}
+	}
+	return recent, nil
+}
+
+// load lit l'historique enregistré (vide si le fichier n'existe pas)
+func (r *TaskRunRepository) load() ([]TaskRun, error) {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []TaskRun
+	if err := json.Unmarshal(content, &runs); err != nil {
+		return nil, fmt.Errorf("fichier %s invalide: %w", r.path, err)
+	}
+	return runs, nil
+}
+
+// ReadTaskHistory lit les dernières exécutions enregistrées par le daemon
+func ReadTaskHistory(task string, limit int) ([]TaskRun, error) {
+	return NewTaskRunRepository(0).FindRecent(task, limit)
+}
+
+// taskRunKey est la clé du contexte transportant l'exécution en cours
+type taskRunKey struct{}
+
+// withTaskRun rattache l'exécution en cours au contexte transmis à la fonction de la tâche
+func withTaskRun(ctx context.Context, run *TaskRun) context.Context {
+	return context.WithValue(ctx, taskRunKey{}, run)
+}
+
+// recordCommandOutput relève dans la sortie d'une commande les cycles qu'elle a créés ou annulés
+func recordCommandOutput(ctx context.Context, output []byte) {
+	run, ok := ctx.Value(taskRunKey{}).(*TaskRun)
+	if !ok {
+		return
+	}
+	run.CycleIDs = append(run.CycleIDs, parseCycleMarkers(output, types.CreatedCycleMarker)...)
+	run.CancelledIDs = append(run.CancelledIDs, parseCycleMarkers(output, types.CancelledCycleMarker)...)
+}
+
+// markSkipped signale que la commande n'a volontairement rien fait
+func markSkipped(ctx context.Context) {
+	if run, ok := ctx.Value(taskRunKey{}).(*TaskRun); ok {
+		run.Skipped = true
+	}
+}
+
+// parseCycleMarkers extrait les identifiants des lignes préfixées par marker
+// (types.CreatedCycleMarker, types.CancelledCycleMarker)
+func parseCycleMarkers(output []byte, marker string) []int32 {
+	var ids []int32
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), marker)
+		if !ok {
+			continue
+		}
+		if id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32); err == nil {
+			ids = append(ids, int32(id))
+		}
+	}
+	return ids
+}
@@ -3,10 +3,13 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"main/internal/config"
+	"main/internal/desktop"
 	"main/internal/types"
 	"main/pkg/logger"
+	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,6 +34,8 @@ var dbSemaphore = make(chan struct{}, 1)
 type Task struct {
 	Config types.TaskConfig
 	Fn     func(ctx context.Context, config types.TaskConfig) error
+
+	lastError string // erreur de la dernière exécution, vide si elle a réussi
 }
 
 // Scheduler gère l'exécution des tâches planifiées
@@ -42,11 +47,24 @@ type Scheduler struct {
 	mu        sync.Mutex
 	ctx       context.Context
 	cancel    context.CancelFunc
+
+	startedAt    time.Time // démarrage du daemon
+	tasksModTime time.Time // date de modification de tasks.conf lors du dernier chargement
+
+	quietHours types.QuietHours // plage sans exécution des tâches "new" (QUIET_HOURS de tasks.conf)
+
+	desktopUnavailable bool // notifications de bureau impossibles sur ce système
+
+	history *TaskRunRepository // historique des exécutions
 }
 
 // NewScheduler crée un nouveau planificateur
 func NewScheduler(config *config.Config, logger *logger.Logger) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
+	historySize := 0
+	if config != nil {
+		historySize = config.SchedulerHistorySize
+	}
 	return &Scheduler{
 		tasks:     make([]*Task, 0),
 		logger:    logger,
@@ -54,9 +72,17 @@ func NewScheduler(config *config.Config, logger *logger.Logger) *Scheduler {
 		isRunning: false,
 		ctx:       ctx,
 		cancel:    cancel,
+		history:   NewTaskRunRepository(historySize),
 	}
 }
 
+// SetConfig remplace la configuration utilisée par le planificateur (rechargement à chaud)
+func (s *Scheduler) SetConfig(config *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
 // AddTask ajoute une nouvelle tâche au planificateur
 func (s *Scheduler) AddTask(config types.TaskConfig, fn func(ctx context.Context, config types.TaskConfig) error) {
 	s.mu.Lock()
@@ -163,6 +189,7 @@ func (s *Scheduler) Start() {
 		return
 	}
 	s.isRunning = true
+	s.startedAt = time.Now()
 	s.mu.Unlock()
 
 	s.logger.Info("Démarrage du planificateur de tâches")
@@ -175,11 +202,19 @@ func (s *Scheduler) runScheduler() {
 	ticker := time.NewTicker(1 * time.Minute) // Vérifier toutes les minutes
 	defer ticker.Stop()
 
+	// Demandes du serveur web (tasks.conf modifié, exécutions immédiates)
+	ipcTicker := time.NewTicker(ipcInterval)
+	defer ipcTicker.Stop()
+	s.publishStatus(true)
+
 	for {
 		select {
 		case <-ticker.C:
 			s.checkAndRunTasks()
+		case <-ipcTicker.C:
+			s.processIPC()
 		case <-s.ctx.Done():
+			s.publishStatus(false)
 			s.logger.Info("Arrêt du planificateur de tâches")
 			return
 		}
@@ -223,10 +258,66 @@ func (s *Scheduler) checkAndRunTasks() {
 		if i > 0 {
 			time.Sleep(2 * time.Second)
 		}
-		go s.executeTask(task)
+		go s.runScheduledTask(task)
 	}
 }
 
+// runScheduledTask exécute une tâche arrivée à échéance après son délai aléatoire. Pendant les
+// heures calmes, les tâches "new" sont ignorées; les exécutions immédiates (RunNow) ne sont
+// concernées ni par le délai ni par les heures calmes.
+func (s *Scheduler) runScheduledTask(task *Task) {
+	if jitter := task.Config.JitterSeconds; jitter > 0 {
+		delay := time.Duration(rand.Intn(jitter+1)) * time.Second
+		s.logger.Debug("Tâche %s retardée de %s", task.Config.Name, delay)
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	quiet := s.quietHours
+	s.mu.Unlock()
+
+	now := time.Now()
+	if task.Config.Type == "new" && quiet.Contains(now) {
+		s.logger.Info("Tâche %s ignorée: heures calmes %s", task.Config.Name, quiet)
+		s.recordRun(&TaskRun{
+			Task:      task.Config.Name,
+			Type:      task.Config.Type,
+			Exchange:  task.Config.Exchange,
+			StartedAt: now,
+			Success:   true,
+			Skipped:   true,
+		})
+		return
+	}
+
+	s.executeTask(task)
+}
+
+// QuietHours retourne la plage pendant laquelle les tâches "new" ne sont pas exécutées
+func (s *Scheduler) QuietHours() types.QuietHours {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quietHours
+}
+
+// SetQuietHours définit la plage sans exécution des tâches "new" (plage vide: désactivée) et
+// enregistre tasks.conf
+func (s *Scheduler) SetQuietHours(quiet types.QuietHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quietHours = quiet
+	if err := s.SaveTasksToConfig(); err != nil {
+		return err
+	}
+	s.tasksModTime = tasksFileModTime()
+	return nil
+}
+
 // Stop arrête le planificateur
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
@@ -249,9 +340,15 @@ func (s *Scheduler) executeTask(task *Task) {
 	s.logger.Debug("Exécution de la tâche: %s", task.Config.Name)
 
 	startTime := time.Now()
+	run := &TaskRun{
+		Task:      task.Config.Name,
+		Type:      task.Config.Type,
+		Exchange:  task.Config.Exchange,
+		StartedAt: startTime,
+	}
 
 	// Acquérir le sémaphore pour les opérations de base de données
-	if task.Config.Type == "update" || task.Config.Type == "new" {
+	if task.Config.Type == "update" || task.Config.Type == "new" || task.Config.Type == "cancel_stale" {
 		s.logger.Debug("Acquisition du verrou de base de données pour la tâche: %s", task.Config.Name)
 		select {
 		case dbSemaphore <- struct{}{}:
@@ -260,22 +357,69 @@ func (s *Scheduler) executeTask(task *Task) {
 		case <-taskCtx.Done():
 			// Timeout pendant l'attente du sémaphore
 			s.logger.Error("Timeout pendant l'attente du verrou de base de données pour la tâche: %s", task.Config.Name)
+			run.Duration = time.Since(startTime)
+			run.Error = "timeout pendant l'attente du verrou de base de données"
+			s.recordRun(run)
 			return
 		}
 	}
 
-	err := task.Fn(taskCtx, task.Config)
+	err := task.Fn(withTaskRun(taskCtx, run), task.Config)
 	duration := time.Since(startTime)
 
+	run.Duration = duration
+	run.Success = err == nil
+	if err != nil {
+		run.Error = err.Error()
+	}
+	s.recordRun(run)
+
+	s.mu.Lock()
+	task.lastError = ""
+	if err != nil {
+		task.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
 	if err != nil {
 		s.logger.Error("Erreur lors de l'exécution de la tâche %s: %v (durée: %s)",
 			task.Config.Name, err, duration)
+		s.notifyDesktop(task.Config.Name, err)
 	} else {
 		s.logger.Info("Tâche %s exécutée avec succès (durée: %s)",
 			task.Config.Name, duration)
 	}
 }
 
+// recordRun ajoute une exécution à l'historique
+func (s *Scheduler) recordRun(run *TaskRun) {
+	if err := s.history.Save(*run); err != nil {
+		s.logger.Error("Erreur lors de l'enregistrement de l'historique de la tâche %s: %v", run.Task, err)
+	}
+}
+
+// notifyDesktop signale l'échec d'une tâche en notification de bureau si DESKTOP_NOTIFY_EVENTS
+// le demande. Une indisponibilité n'est journalisée qu'une fois, puis le canal est ignoré.
+func (s *Scheduler) notifyDesktop(taskName string, taskErr error) {
+	s.mu.Lock()
+	cfg := s.config
+	unavailable := s.desktopUnavailable
+	s.mu.Unlock()
+	if unavailable || !cfg.DesktopNotifies(config.DesktopEventTaskFailed) {
+		return
+	}
+
+	err := desktop.Notify("Bot Spot", fmt.Sprintf("Tâche %s en échec: %v", taskName, taskErr))
+	if errors.Is(err, desktop.ErrUnavailable) {
+		s.mu.Lock()
+		s.desktopUnavailable = true
+		s.mu.Unlock()
+		s.logger.Warn("Notifications de bureau désactivées: %v", err)
+	} else if err != nil {
+		s.logger.Warn("Notification de bureau non affichée: %v", err)
+	}
+}
+
 // GetAllTasks retourne toutes les tâches configurées
 func (s *Scheduler) GetAllTasks() []types.TaskConfig {
 	s.mu.Lock()
@@ -352,18 +496,13 @@ func (s *Scheduler) LoadTasksFromConfig() error {
 	// Réinitialiser les tâches existantes
 	s.tasks = make([]*Task, 0)
 
-	// Charger les tâches depuis la configuration
+	// Charger les tâches et les heures calmes depuis la configuration
 	scheduledTasks := s.config.GetScheduledTasks()
+	s.quietHours = s.config.GetQuietHours()
 	for _, taskConfig := range scheduledTasks {
 		// Créer la fonction appropriée en fonction du type de tâche
-		var taskFn func(ctx context.Context, config types.TaskConfig) error
-
-		switch taskConfig.Type {
-		case "update":
-			taskFn = s.createUpdateTask()
-		case "new":
-			taskFn = s.createNewCycleTask()
-		default:
+		taskFn := s.taskFunc(taskConfig.Type)
+		if taskFn == nil {
 			continue // Ignorer les types de tâches inconnus
 		}
 
@@ -381,6 +520,7 @@ func (s *Scheduler) LoadTasksFromConfig() error {
 
 	}
 
+	s.tasksModTime = tasksFileModTime()
 	return nil
 }
 
@@ -415,6 +555,7 @@ func (s *Scheduler) createUpdateTask() func(ctx context.Context, config types.Ta
 		defer cmdCancel()
 		cmd = exec.CommandContext(cmdCtx, cmd.Path, cmd.Args[1:]...)
 		cmd.Dir = projectDir
+		cmd.Env = s.commandEnv()
 
 		output, err := cmd.CombinedOutput()
 
@@ -446,7 +587,8 @@ func (s *Scheduler) createNewCycleTask() func(ctx context.Context, config types.
 			args = append(args, fmt.Sprintf("-exchange%s", strings.ToLower(config.Exchange)))
 
 			// Si des paramètres personnalisés sont définis, les configurer temporairement via des variables d'environnement
-			if config.BuyOffset != 0 || config.SellOffset != 0 || config.Percent != 0 {
+			if config.BuyOffset != 0 || config.SellOffset != 0 || config.Percent != 0 ||
+				config.BuyOffsetPercent != 0 || config.SellOffsetPercent != 0 {
 				exchangeUpper := strings.ToUpper(config.Exchange)
 
 				if config.BuyOffset != 0 {
@@ -459,6 +601,17 @@ func (s *Scheduler) createNewCycleTask() func(ctx context.Context, config types.
 					tempEnvVars = append(tempEnvVars, sellOffsetEnv)
 				}
 
+				// Un offset en % de la tâche l'emporte, l'offset absolu servant de plancher
+				if config.BuyOffsetPercent != 0 || config.SellOffsetPercent != 0 {
+					tempEnvVars = append(tempEnvVars, fmt.Sprintf("%s_OFFSET_PRECEDENCE=percent", exchangeUpper))
+				}
+				if config.BuyOffsetPercent != 0 {
+					tempEnvVars = append(tempEnvVars, fmt.Sprintf("%s_BUY_OFFSET_PERCENT=%g", exchangeUpper, config.BuyOffsetPercent))
+				}
+				if config.SellOffsetPercent != 0 {
+					tempEnvVars = append(tempEnvVars, fmt.Sprintf("%s_SELL_OFFSET_PERCENT=%g", exchangeUpper, config.SellOffsetPercent))
+				}
+
 				if config.Percent != 0 {
 					percentEnv := fmt.Sprintf("%s_PERCENT=%g", exchangeUpper, config.Percent)
 					tempEnvVars = append(tempEnvVars, percentEnv)
@@ -466,8 +619,11 @@ func (s *Scheduler) createNewCycleTask() func(ctx context.Context, config types.
 			}
 		}
 
-		// Ajouter la commande de création de cycle
+		// Ajouter la commande de création de cycle, sur la paire de la tâche
 		args = append(args, "-n")
+		if config.Pair != "" {
+			args = append(args, "--pair="+config.Pair)
+		}
 
 		// Préparer la commande
 		cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
@@ -486,23 +642,111 @@ func (s *Scheduler) createNewCycleTask() func(ctx context.Context, config types.
 			s.logger.Info("Paramètres personnalisés pour la tâche: %s", strings.Join(tempEnvVars, ", "))
 
 			// Récupérer l'environnement actuel et ajouter les variables temporaires
-			currentEnv := os.Environ()
-			cmd.Env = append(currentEnv, tempEnvVars...)
+			cmd.Env = s.commandEnv(tempEnvVars...)
+		} else {
+			cmd.Env = s.commandEnv()
 		}
 
 		// Exécuter la commande
 		output, err := cmd.CombinedOutput()
 
 		if err != nil {
+			// Une limite d'exposition atteinte n'est pas une erreur: la tâche est simplement ignorée
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == types.ExitCodeSkipped {
+				s.logger.Info("Commande new-cycle ignorée (limite d'exposition atteinte): %s", string(output))
+				markSkipped(ctx)
+				return nil
+			}
+
 			s.logger.Error("Erreur lors de l'exécution de la commande new-cycle: %v, output: %s", err, string(output))
 			return err
 		}
 
+		recordCommandOutput(ctx, output)
 		s.logger.Info("Commande new-cycle exécutée avec succès: %s", string(output))
 		return nil
 	}
 }
 
+// createSnapshotTask crée une fonction pour la tâche d'instantané du portefeuille
+func (s *Scheduler) createSnapshotTask() func(ctx context.Context, config types.TaskConfig) error {
+	return func(ctx context.Context, config types.TaskConfig) error {
+		projectDir, err := findProjectRoot()
+		if err != nil {
+			s.logger.Error("Impossible de trouver le répertoire du projet: %v", err)
+			return err
+		}
+
+		cmdCtx, cmdCancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cmdCancel()
+		cmd := exec.CommandContext(cmdCtx, "go", "run", ".", "--snapshot")
+		cmd.Dir = projectDir
+		cmd.Env = s.commandEnv()
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			s.logger.Error("Erreur lors de l'exécution de la commande snapshot: %v, output: %s", err, string(output))
+			return err
+		}
+
+		s.logger.Info("Commande snapshot exécutée avec succès: %s", string(output))
+		return nil
+	}
+}
+
+// createCancelStaleTask crée une fonction pour la tâche d'annulation des achats trop anciens
+func (s *Scheduler) createCancelStaleTask() func(ctx context.Context, config types.TaskConfig) error {
+	return func(ctx context.Context, config types.TaskConfig) error {
+		if config.MaxAgeDays <= 0 {
+			return fmt.Errorf("âge maximal des achats non défini pour la tâche %s", config.Name)
+		}
+
+		projectDir, err := findProjectRoot()
+		if err != nil {
+			s.logger.Error("Impossible de trouver le répertoire du projet: %v", err)
+			return err
+		}
+
+		args := []string{"run", ".", "--cancel-stale", fmt.Sprintf("--max-age-days=%d", config.MaxAgeDays)}
+		if config.Exchange != "" {
+			args = append(args, fmt.Sprintf("-exchange%s", strings.ToLower(config.Exchange)))
+		}
+
+		cmdCtx, cmdCancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cmdCancel()
+		cmd := exec.CommandContext(cmdCtx, "go", args...)
+		cmd.Dir = projectDir
+		cmd.Env = s.commandEnv()
+
+		// Les cycles annulés sont relevés même si d'autres annulations ont échoué
+		output, err := cmd.CombinedOutput()
+		recordCommandOutput(ctx, output)
+		if err != nil {
+			s.logger.Error("Erreur lors de l'exécution de la commande cancel-stale: %v, output: %s", err, string(output))
+			return err
+		}
+
+		s.logger.Info("Commande cancel-stale exécutée avec succès: %s", string(output))
+		return nil
+	}
+}
+
+// commandEnv retourne l'environnement des commandes lancées par le planificateur,
+// avec le niveau de log réduit à DAEMON_LOG_LEVEL pour limiter le bruit par cycle
+// et types.TaskRunEnv pour que les cycles créés soient signalés dans la sortie
+func (s *Scheduler) commandEnv(extra ...string) []string {
+	s.mu.Lock()
+	cfg := s.config
+	s.mu.Unlock()
+
+	env := append(os.Environ(), types.TaskRunEnv+"=1")
+	if cfg != nil && cfg.DaemonLogLevel != "" {
+		env = append(env, "LOG_LEVEL="+cfg.DaemonLogLevel)
+	}
+	return append(env, extra...)
+}
+
 // CreateUpdateTask crée une fonction pour la tâche de mise à jour des cycles
 func (s *Scheduler) CreateUpdateTask() func(ctx context.Context, config types.TaskConfig) error {
 	return s.createUpdateTask()
@@ -554,6 +798,16 @@ func (s *Scheduler) CreateNewCycleTask() func(ctx context.Context, config types.
 	return s.createNewCycleTask()
 }
 
+// CreateSnapshotTask crée une fonction pour la tâche d'instantané du portefeuille
+func (s *Scheduler) CreateSnapshotTask() func(ctx context.Context, config types.TaskConfig) error {
+	return s.createSnapshotTask()
+}
+
+// CreateCancelStaleTask crée une fonction pour la tâche d'annulation des achats trop anciens
+func (s *Scheduler) CreateCancelStaleTask() func(ctx context.Context, config types.TaskConfig) error {
+	return s.createCancelStaleTask()
+}
+
 // CreateDefaultTasks crée les tâches par défaut pour le bot
 func (s *Scheduler) CreateDefaultTasks() {
 	// Mise à jour des cycles toutes les 5 minutes
@@ -646,15 +900,17 @@ func FormatIntervalToString(value int, unit types.TimeUnit) string {
 
 // SaveTasksToConfig sauvegarde les tâches dans la configuration
 func (s *Scheduler) SaveTasksToConfig() error {
-	// Chemin du fichier de configuration des tâches
-	tasksConfigFile := "tasks.conf"
-
 	// Préparer le contenu du fichier
 	var lines []string
 	lines = append(lines, "# Configuration des tâches planifiées")
 	lines = append(lines, "# Format: TASK_[index]_[property]=[value]")
 	lines = append(lines, fmt.Sprintf("TASKS_COUNT=%d", len(s.tasks)))
 
+	// Plage sans exécution des tâches "new"
+	if s.quietHours.Enabled() {
+		lines = append(lines, "QUIET_HOURS="+s.quietHours.String())
+	}
+
 	// Écrire chaque tâche
 	for i, task := range s.tasks {
 		prefix := fmt.Sprintf("TASK_%d_", i+1)
@@ -676,6 +932,16 @@ func (s *Scheduler) SaveTasksToConfig() error {
 			lines = append(lines, prefix+"EXCHANGE="+task.Config.Exchange)
 		}
 
+		// Délai aléatoire ajouté à chaque exécution
+		if task.Config.JitterSeconds > 0 {
+			lines = append(lines, prefix+"JITTER_SECONDS="+strconv.Itoa(task.Config.JitterSeconds))
+		}
+
+		// Âge maximal des achats annulés par une tâche "cancel_stale"
+		if task.Config.Type == "cancel_stale" {
+			lines = append(lines, prefix+"MAX_AGE_DAYS="+strconv.Itoa(task.Config.MaxAgeDays))
+		}
+
 		// Paramètres spécifiques aux tâches de type "new"
 		if task.Config.Type == "new" {
 			if task.Config.BuyOffset != 0 {
@@ -684,9 +950,19 @@ func (s *Scheduler) SaveTasksToConfig() error {
 			if task.Config.SellOffset != 0 {
 				lines = append(lines, prefix+"SELL_OFFSET="+strconv.FormatFloat(task.Config.SellOffset, 'f', -1, 64))
 			}
+			if task.Config.BuyOffsetPercent != 0 {
+				lines = append(lines, prefix+"BUY_OFFSET_PERCENT="+strconv.FormatFloat(task.Config.BuyOffsetPercent, 'f', -1, 64))
+			}
+			if task.Config.SellOffsetPercent != 0 {
+				lines = append(lines, prefix+"SELL_OFFSET_PERCENT="+strconv.FormatFloat(task.Config.SellOffsetPercent, 'f', -1, 64))
+			}
 			if task.Config.Percent != 0 {
 				lines = append(lines, prefix+"PERCENT="+strconv.FormatFloat(task.Config.Percent, 'f', -1, 64))
 			}
+			// La paire par défaut de l'exchange n'est pas figée: elle suit KRAKEN_PAIR
+			if task.Config.Pair != "" && (task.Config.Exchange == "" || task.Config.Pair != s.config.DefaultPair(task.Config.Exchange)) {
+				lines = append(lines, prefix+"PAIR="+task.Config.Pair)
+			}
 		}
 
 		if !task.Config.NextScheduledAt.IsZero() {
@@ -696,7 +972,7 @@ func (s *Scheduler) SaveTasksToConfig() error {
 
 	// Écrire le contenu dans le fichier
 	content := strings.Join(lines, "\n") + "\n"
-	err := os.WriteFile(tasksConfigFile, []byte(content), 0644)
+	err := os.WriteFile(config.Path(tasksConfigFile), []byte(content), 0644)
 	if err != nil {
 		return fmt.Errorf("erreur lors de la sauvegarde des tâches: %w", err)
 	}
@@ -0,0 +1,341 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"main/internal/config"
+	"main/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tasksYAMLHeader documente le format des fichiers produits par -plan export
+const tasksYAMLHeader = `# Tâches planifiées du bot (-plan export / -plan import)
+#
+# tasks:
+#   - name: create-cycle     # nom unique de la tâche (obligatoire)
+#     type: new              # update, new, snapshot ou cancel_stale (obligatoire)
+#     interval: 24h          # intervalle: nombre suivi de m, h ou d (obligatoire)
+#     at: "09:00"            # heure fixe d'exécution HH:MM (facultatif)
+#     exchange: BINANCE      # BINANCE, MEXC, KUCOIN ou KRAKEN (facultatif)
+#     buy_offset: -700       # tâches new: écart du prix d'achat (facultatif)
+#     sell_offset: 700       # tâches new: écart du prix de vente (facultatif)
+#     buy_offset_percent: -1 # tâches new: écart d'achat en % du prix, buy_offset en plancher (facultatif)
+#     sell_offset_percent: 1 # tâches new: écart de vente en % du prix d'achat, sell_offset en plancher (facultatif)
+#     percent: 5             # tâches new: pourcentage du solde à engager (facultatif)
+#     pair: BTC/USDC         # tâches new: paire négociée, celle de l'exchange par défaut (facultatif)
+#     max_age_days: 7        # tâches cancel_stale: âge en jours des achats à annuler (obligatoire)
+#     jitter_seconds: 30     # délai aléatoire maximum ajouté à chaque exécution, en secondes (facultatif)
+#     enabled: true          # false pour désactiver la tâche (true par défaut)
+#
+# Les heures calmes (QUIET_HOURS de tasks.conf, réglées avec -plan) ne sont pas exportées.
+# tasks.conf reste le fichier lu par le planificateur: -plan import le réécrit.
+`
+
+// taskTypes liste les types de tâches connus du planificateur
+var taskTypes = []string{"update", "new", "snapshot", "cancel_stale"}
+
+// taskExchanges liste les exchanges qu'une tâche peut cibler
+var taskExchanges = []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"}
+
+// taskFields liste les champs d'une tâche dans le format d'export
+var taskFields = []string{"name", "type", "interval", "at", "exchange", "buy_offset", "sell_offset", "buy_offset_percent", "sell_offset_percent",
+	"percent", "pair", "max_age_days", "jitter_seconds", "enabled"}
+
+// specificTimePattern valide l'heure fixe d'une tâche (HH:MM)
+var specificTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// tasksFile est le document YAML d'export des tâches
+type tasksFile struct {
+	Tasks []yaml.Node `yaml:"tasks"`
+}
+
+// taskYAML est une tâche dans le format d'export
+type taskYAML struct {
+	Name              string   `yaml:"name"`
+	Type              string   `yaml:"type"`
+	Interval          string   `yaml:"interval"`
+	At                string   `yaml:"at,omitempty"`
+	Exchange          string   `yaml:"exchange,omitempty"`
+	BuyOffset         *float64 `yaml:"buy_offset,omitempty"`
+	SellOffset        *float64 `yaml:"sell_offset,omitempty"`
+	BuyOffsetPercent  *float64 `yaml:"buy_offset_percent,omitempty"`
+	SellOffsetPercent *float64 `yaml:"sell_offset_percent,omitempty"`
+	Percent           *float64 `yaml:"percent,omitempty"`
+	Pair              string   `yaml:"pair,omitempty"`
+	MaxAgeDays        int      `yaml:"max_age_days,omitempty"`
+	JitterSeconds     int      `yaml:"jitter_seconds,omitempty"`
+	Enabled           *bool    `yaml:"enabled,omitempty"`
+}
+
+// ExportTasksYAML convertit des tâches dans le format YAML documenté
+func ExportTasksYAML(tasks []types.TaskConfig) ([]byte, error) {
+	exported := make([]taskYAML, 0, len(tasks))
+	for _, task := range tasks {
+		entry := taskYAML{
+			Name:     task.Name,
+			Type:     task.Type,
+			Interval: formatIntervalShort(task.IntervalValue, task.IntervalUnit),
+			At:       task.SpecificTime,
+			Exchange: task.Exchange,
+
+			MaxAgeDays:    task.MaxAgeDays,
+			JitterSeconds: task.JitterSeconds,
+		}
+		if !task.Enabled {
+			disabled := false
+			entry.Enabled = &disabled
+		}
+		if task.Type == "new" {
+			if task.BuyOffset != 0 {
+				entry.BuyOffset = floatPtr(task.BuyOffset)
+			}
+			if task.SellOffset != 0 {
+				entry.SellOffset = floatPtr(task.SellOffset)
+			}
+			if task.BuyOffsetPercent != 0 {
+				entry.BuyOffsetPercent = floatPtr(task.BuyOffsetPercent)
+			}
+			if task.SellOffsetPercent != 0 {
+				entry.SellOffsetPercent = floatPtr(task.SellOffsetPercent)
+			}
+			if task.Percent != 0 {
+				entry.Percent = floatPtr(task.Percent)
+			}
+			entry.Pair = task.Pair
+		}
+		exported = append(exported, entry)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(tasksYAMLHeader)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string][]taskYAML{"tasks": exported}); err != nil {
+		return nil, fmt.Errorf("erreur lors de l'encodage des tâches: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseTasksYAML lit et valide des tâches au format YAML. Les erreurs désignent la tâche fautive
+// par son rang, sa ligne et son nom.
+func ParseTasksYAML(data []byte) ([]types.TaskConfig, error) {
+	var file tasksFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("fichier YAML invalide: %w", err)
+	}
+	if len(file.Tasks) == 0 {
+		return nil, fmt.Errorf("aucune tâche trouvée sous la clé tasks")
+	}
+
+	tasks := make([]types.TaskConfig, 0, len(file.Tasks))
+	names := make(map[string]int)
+	for i, node := range file.Tasks {
+		// Node.Decode ignore KnownFields: les champs sont vérifiés ici
+		if err := checkTaskFields(&node); err != nil {
+			return nil, fmt.Errorf("tâche %d (ligne %d): %w", i+1, node.Line, err)
+		}
+		var entry taskYAML
+		if err := node.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("tâche %d (ligne %d): %w", i+1, node.Line, err)
+		}
+
+		task, err := entry.toTaskConfig()
+		if err != nil {
+			return nil, fmt.Errorf("tâche %d (ligne %d, %q): %w", i+1, node.Line, entry.Name, err)
+		}
+		if previous, exists := names[task.Name]; exists {
+			return nil, fmt.Errorf("tâche %d (ligne %d, %q): nom déjà utilisé par la tâche %d", i+1, node.Line, entry.Name, previous)
+		}
+		names[task.Name] = i + 1
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// checkTaskFields refuse une tâche qui n'est pas un dictionnaire ou qui contient un champ inconnu
+func checkTaskFields(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("une tâche doit être un dictionnaire name/type/interval")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !containsString(taskFields, key.Value) {
+			return fmt.Errorf("champ %q inconnu ligne %d (attendu: %s)", key.Value, key.Line, strings.Join(taskFields, ", "))
+		}
+	}
+	return nil
+}
+
+// toTaskConfig valide une tâche exportée et la convertit en configuration du planificateur
+func (t taskYAML) toTaskConfig() (types.TaskConfig, error) {
+	task := types.TaskConfig{
+		Name:         strings.TrimSpace(t.Name),
+		Type:         strings.ToLower(strings.TrimSpace(t.Type)),
+		SpecificTime: strings.TrimSpace(t.At),
+		Exchange:     strings.ToUpper(strings.TrimSpace(t.Exchange)),
+		Enabled:      t.Enabled == nil || *t.Enabled,
+	}
+
+	if task.Name == "" {
+		return task, fmt.Errorf("name est obligatoire")
+	}
+	if !containsString(taskTypes, task.Type) {
+		return task, fmt.Errorf("type %q inconnu (attendu: %s)", t.Type, strings.Join(taskTypes, ", "))
+	}
+
+	value, unit, err := ParseInterval(strings.TrimSpace(t.Interval))
+	if err != nil {
+		return task, fmt.Errorf("interval %q: %v", t.Interval, err)
+	}
+	if value <= 0 {
+		return task, fmt.Errorf("interval %q doit être positif", t.Interval)
+	}
+	task.IntervalValue, task.IntervalUnit = value, unit
+	task.Interval = intervalDuration(value, unit)
+
+	if task.SpecificTime != "" && !specificTimePattern.MatchString(task.SpecificTime) {
+		return task, fmt.Errorf("at %q invalide (attendu: HH:MM)", t.At)
+	}
+	if task.Exchange != "" && !containsString(taskExchanges, task.Exchange) {
+		return task, fmt.Errorf("exchange %q inconnu (attendu: %s)", t.Exchange, strings.Join(taskExchanges, ", "))
+	}
+	if t.JitterSeconds < 0 || time.Duration(t.JitterSeconds)*time.Second >= task.Interval {
+		return task, fmt.Errorf("jitter_seconds %d doit être positif et inférieur à l'intervalle %q", t.JitterSeconds, t.Interval)
+	}
+	task.JitterSeconds = t.JitterSeconds
+
+	if task.Type == "cancel_stale" {
+		if t.MaxAgeDays <= 0 {
+			return task, fmt.Errorf("max_age_days est obligatoire et doit être positif pour une tâche cancel_stale")
+		}
+		task.MaxAgeDays = t.MaxAgeDays
+	} else if t.MaxAgeDays != 0 {
+		return task, fmt.Errorf("max_age_days ne s'applique qu'aux tâches cancel_stale")
+	}
+
+	if task.Type != "new" {
+		if t.BuyOffset != nil || t.SellOffset != nil || t.BuyOffsetPercent != nil || t.SellOffsetPercent != nil || t.Percent != nil || t.Pair != "" {
+			return task, fmt.Errorf("buy_offset, sell_offset, buy_offset_percent, sell_offset_percent, percent et pair ne s'appliquent qu'aux tâches new")
+		}
+		return task, nil
+	}
+	if t.BuyOffset != nil {
+		task.BuyOffset = *t.BuyOffset
+	}
+	if t.SellOffset != nil {
+		task.SellOffset = *t.SellOffset
+	}
+	for _, offset := range []struct {
+		name   string
+		value  *float64
+		target *float64
+	}{
+		{"buy_offset_percent", t.BuyOffsetPercent, &task.BuyOffsetPercent},
+		{"sell_offset_percent", t.SellOffsetPercent, &task.SellOffsetPercent},
+	} {
+		if offset.value == nil {
+			continue
+		}
+		if *offset.value == 0 || *offset.value <= -50 || *offset.value >= 50 {
+			return task, fmt.Errorf("%s %g doit être compris entre -50 et 50 (hors 0)", offset.name, *offset.value)
+		}
+		*offset.target = *offset.value
+	}
+	if t.Percent != nil {
+		if *t.Percent <= 0 || *t.Percent > 100 {
+			return task, fmt.Errorf("percent %g doit être compris entre 0 et 100", *t.Percent)
+		}
+		task.Percent = *t.Percent
+	}
+	if t.Pair != "" {
+		pair, quote, err := config.ParsePair(t.Pair)
+		if err != nil {
+			return task, err
+		}
+		task.Pair, task.QuoteAsset = pair, quote
+	}
+	return task, nil
+}
+
+// ReplaceTasks remplace toutes les tâches du planificateur et enregistre tasks.conf
+func (s *Scheduler) ReplaceTasks(configs []types.TaskConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]*Task, 0, len(configs))
+	for _, config := range configs {
+		fn := s.taskFunc(config.Type)
+		if fn == nil {
+			return fmt.Errorf("type de tâche inconnu pour %s: %s", config.Name, config.Type)
+		}
+		task := &Task{Config: config, Fn: fn}
+		task.Config.NextScheduledAt = s.calculateNextRun(config)
+		tasks = append(tasks, task)
+	}
+
+	s.tasks = tasks
+	return s.SaveTasksToConfig()
+}
+
+// taskFunc retourne la fonction d'exécution d'un type de tâche (nil si le type est inconnu)
+func (s *Scheduler) taskFunc(taskType string) func(ctx context.Context, config types.TaskConfig) error {
+	switch taskType {
+	case "update":
+		return s.createUpdateTask()
+	case "new":
+		return s.createNewCycleTask()
+	case "snapshot":
+		return s.createSnapshotTask()
+	case "cancel_stale":
+		return s.createCancelStaleTask()
+	}
+	return nil
+}
+
+// formatIntervalShort écrit un intervalle dans le format accepté par ParseInterval (5m, 2h, 1d)
+func formatIntervalShort(value int, unit types.TimeUnit) string {
+	switch unit {
+	case types.Hours:
+		return fmt.Sprintf("%dh", value)
+	case types.Days:
+		return fmt.Sprintf("%dd", value)
+	default:
+		return fmt.Sprintf("%dm", value)
+	}
+}
+
+// intervalDuration convertit une valeur et une unité d'intervalle en durée
+func intervalDuration(value int, unit types.TimeUnit) time.Duration {
+	switch unit {
+	case types.Hours:
+		return time.Duration(value) * time.Hour
+	case types.Days:
+		return time.Duration(value) * 24 * time.Hour
+	default:
+		return time.Duration(value) * time.Minute
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+)
+
+// sellAccumulations remet en vente le BTC accumulé d'un exchange dont le prix a atteint
+// <EXCHANGE>_ACCU_SELL_TRIGGER_PRICE. Chaque accumulation devient un cycle en vente au prix
+// d'achat d'origine, pour que le profit de la revente couvre toute la durée de l'accumulation.
+func sellAccumulations(client common.Exchange, repo *database.CycleRepository, exchange string, exchangeConfig config.ExchangeConfig, lastPrice float64) {
+	if exchangeConfig.AccuSellTriggerPrice.Value <= 0 || lastPrice <= 0 {
+		return
+	}
+
+	accuRepo := database.GetAccumulationRepository()
+	accumulations, err := accuRepo.FindByExchange(exchange)
+	if err != nil {
+		exchangeEvent(exchange, "accumulation_sell").with("error", err).
+			fail(i18n.T("accumulation_sell.load_error"), exchange, err)
+		return
+	}
+
+	for _, accumulation := range accumulations {
+		if accumulation.Converted() {
+			continue
+		}
+		triggerPrice := exchangeConfig.AccuSellTriggerPrice.Price(accumulation.TargetSellPrice)
+		if lastPrice < triggerPrice {
+			continue
+		}
+		convertAccumulation(client, repo, accuRepo, accumulation, exchangeConfig, triggerPrice, lastPrice)
+	}
+}
+
+// convertAccumulation place la vente limite d'une accumulation, puis enregistre le cycle qui la
+// suit et marque l'accumulation comme revendue. L'identifiant client de la vente, propre à
+// l'accumulation, permet de reprendre l'ordre déjà placé après un arrêt brutal.
+func convertAccumulation(client common.Exchange, repo *database.CycleRepository, accuRepo *database.AccumulationRepository,
+	accumulation *database.Accumulation, exchangeConfig config.ExchangeConfig, triggerPrice, lastPrice float64) {
+	ev := exchangeEvent(accumulation.Exchange, "accumulation_sell").
+		with("accumulation", accumulation.IdInt).with("price", lastPrice)
+
+	clientOrderID := common.ClientOrderID(accumulation.CycleIdInt, fmt.Sprintf("accu-%d", accumulation.IdInt))
+
+	// Cycle déjà créé avant un arrêt brutal: il ne reste qu'à marquer l'accumulation
+	if existing := findCycleBySellClientOrder(repo, accumulation.Exchange, clientOrderID); existing != nil {
+		if err := accuRepo.MarkConverted(accumulation.IdInt, existing.IdInt); err != nil {
+			ev.with("error", err).fail(i18n.T("accumulation_sell.convert_save_error"),
+				accumulation.IdInt, existing.IdInt, err)
+		}
+		return
+	}
+
+	if _, found := findClientOrder(client, clientOrderID); !found {
+		balances, err := client.GetDetailedBalances()
+		if err != nil {
+			ev.with("error", err).warn(i18n.T("accumulation_sell.balances_unavailable"), accumulation.IdInt, err)
+			return
+		}
+		if free := usableBalance(accumulation.Exchange, balances, "BTC"); free < accumulation.Quantity {
+			ev.warn(i18n.T("accumulation_sell.insufficient_btc"),
+				accumulation.IdInt, free, accumulation.Quantity)
+			return
+		}
+	}
+
+	// Vendre au seuil, ou juste au-dessus du marché s'il l'a dépassé, pour rester maker
+	makerMinPrice := lastPrice + common.MakerPriceOffset(lastPrice,
+		exchangeConfig.MakerBufferPercent, common.DefaultMakerSellBufferPercent, 0.01)
+	sellPrice := math.Ceil(math.Max(triggerPrice, makerMinPrice)*100) / 100
+
+	cycle := &database.Cycle{
+		Exchange:           accumulation.Exchange,
+		Status:             "sell",
+		Quantity:           accumulation.Quantity,
+		BuyPrice:           accumulation.OriginalBuyPrice,
+		BuyFillPrice:       accumulation.OriginalBuyPrice,
+		PurchaseAmountUSDC: accumulation.OriginalBuyPrice * accumulation.Quantity,
+		// Les frais de l'achat d'origine restent à la charge de la revente
+		BuyFees:       accumulation.BuyFees,
+		TotalFees:     accumulation.BuyFees,
+		BuyFeeBTC:     accumulation.BuyFeeBTC,
+		FeesEstimated: accumulation.FeesEstimated,
+		// Le cycle d'origine a été supprimé: l'achat est daté de l'accumulation
+		CreatedAt:         accumulation.CreatedAt,
+		SellClientOrderId: clientOrderID,
+	}
+
+	quantityStr := client.FormatQuantity(accumulation.Quantity)
+	orderIdStr, placedPrice, err := placeLimitSell(client, repo, cycle, ev, sellPrice, quantityStr, clientOrderID)
+	if err != nil {
+		return
+	}
+	cycle.SellId = orderIdStr
+	cycle.SellPrice = placedPrice
+	cycle.SaleAmountUSDC = placedPrice * accumulation.Quantity
+
+	if _, err := repo.Save(cycle); err != nil {
+		ev.with("error", err).fail(i18n.T("accumulation_sell.cycle_save_error"),
+			accumulation.IdInt, orderIdStr, err)
+		return
+	}
+	if err := accuRepo.MarkConverted(accumulation.IdInt, cycle.IdInt); err != nil {
+		ev.with("error", err).fail(i18n.T("accumulation_sell.convert_save_error"),
+			accumulation.IdInt, cycle.IdInt, err)
+		return
+	}
+	accumulation.ConvertedCycleId = cycle.IdInt
+	accumulation.ConvertedAt = time.Now()
+
+	ev.with("order_id", orderIdStr).with("price", placedPrice).
+		success(i18n.T("accumulation_sell.converted"),
+			accumulation.IdInt, accumulation.Quantity, placedPrice, triggerPrice, cycle.IdInt, accumulation.OriginalBuyPrice)
+	ev.notify(cycle, "Accumulation %d revendue: %.8f BTC en vente à %.2f USDC dans le cycle %d",
+		accumulation.IdInt, accumulation.Quantity, placedPrice, cycle.IdInt)
+}
+
+// findCycleBySellClientOrder retourne le cycle de l'exchange dont la vente porte l'identifiant client indiqué
+func findCycleBySellClientOrder(repo *database.CycleRepository, exchange, clientOrderID string) *database.Cycle {
+	cycles, err := repo.FindByExchange(exchange)
+	if err != nil {
+		return nil
+	}
+	for _, cycle := range cycles {
+		if cycle.SellClientOrderId == clientOrderID {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestSellAccumulations(t *testing.T) {
+	exchangeConfig := config.ExchangeConfig{
+		SellOffset:           1200,
+		APIKey:               "key",
+		SecretKey:            "secret",
+		AccuSellTriggerPrice: config.PriceTrigger{Value: 10, Percent: true},
+	}
+	mock := useMockExchange(t, exchangeConfig, 65000)
+	mock.SetBalance("BTC", 0.002)
+	repo := database.GetRepository()
+	accuRepo := database.GetAccumulationRepository()
+
+	// Vente à 60000 annulée pour accumulation: revente à partir de 66000
+	accumulation := &database.Accumulation{
+		Exchange:         "BINANCE",
+		CycleIdInt:       41,
+		Quantity:         0.001,
+		OriginalBuyPrice: 58800,
+		TargetSellPrice:  60000,
+		CancelPrice:      54000,
+		Deviation:        10,
+		BuyFees:          0.0588,
+		BuyFeeBTC:        0.000001,
+		FeesEstimated:    true,
+	}
+	if _, err := accuRepo.Save(accumulation); err != nil {
+		t.Fatalf("enregistrement de l'accumulation: %v", err)
+	}
+	t.Cleanup(func() { accuRepo.DeleteByIdInt(accumulation.IdInt) })
+
+	// Seuil non atteint: aucun ordre
+	sellAccumulations(mock, repo, "BINANCE", cfg.Exchanges["BINANCE"], 65000)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 0 {
+		t.Fatalf("%d ordre(s) placé(s) sous le seuil de revente", len(calls))
+	}
+
+	// Seuil atteint: vente placée et cycle créé au prix d'achat d'origine
+	sellAccumulations(mock, repo, "BINANCE", cfg.Exchanges["BINANCE"], 67000)
+	stored, err := accuRepo.FindByIdInt(accumulation.IdInt)
+	if err != nil || stored == nil {
+		t.Fatalf("lecture de l'accumulation: %v", err)
+	}
+	if !stored.Converted() || stored.ConvertedAt.IsZero() {
+		t.Fatal("l'accumulation devrait être marquée comme revendue")
+	}
+	cycle, err := repo.FindByIdInt(stored.ConvertedCycleId)
+	if err != nil || cycle == nil {
+		t.Fatalf("cycle de revente %d introuvable: %v", stored.ConvertedCycleId, err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+
+	if cycle.Status != "sell" || cycle.Quantity != 0.001 || cycle.EffectiveBuyPrice() != 58800 {
+		t.Errorf("cycle %s de %.8f BTC acheté à %.2f, attendu sell de 0.001 BTC à 58800", cycle.Status, cycle.Quantity, cycle.EffectiveBuyPrice())
+	}
+	if cycle.BuyFees != 0.0588 || cycle.TotalFees != 0.0588 || cycle.BuyFeeBTC != 0.000001 || !cycle.FeesEstimated {
+		t.Errorf("frais d'achat d'origine perdus: %.4f USDC (total %.4f), %.8f BTC, estimés %v",
+			cycle.BuyFees, cycle.TotalFees, cycle.BuyFeeBTC, cycle.FeesEstimated)
+	}
+	if cycle.SellPrice < 67000 || mock.OrderStatus(cycle.SellId) != "NEW" {
+		t.Errorf("vente %q à %.2f, attendue ouverte au-dessus de 67000", cycle.SellId, cycle.SellPrice)
+	}
+
+	// Une accumulation revendue ne l'est qu'une fois et sort des totaux conservés
+	sellAccumulations(mock, repo, "BINANCE", cfg.Exchanges["BINANCE"], 68000)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Errorf("%d ordres placés, attendu 1", len(calls))
+	}
+	stats, err := accuRepo.GetExchangeAccumulationStats("BINANCE", 68000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["count"] != 0 || stats["convertedCount"] != 1 {
+		t.Errorf("statistiques: %v conservée(s), %v revendue(s), attendu 0 et 1", stats["count"], stats["convertedCount"])
+	}
+}
+
+// Une accumulation annule la vente sur l'exchange avant de supprimer le cycle: le BTC conservé
+// doit être libre pour être revendu. Une annulation en échec reporte l'accumulation.
+func TestAccumulationCancelsSell(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{
+		SellOffset:             1200,
+		APIKey:                 "key",
+		SecretKey:              "secret",
+		Accumulation:           true,
+		SellAccuPriceDeviation: 10,
+	}, 55000)
+	repo := database.GetRepository()
+	accuRepo := database.GetAccumulationRepository()
+
+	// Profit réalisé qui autorise l'accumulation
+	completed := &database.Cycle{Exchange: "BINANCE", Status: "completed", Quantity: 0.01, BuyPrice: 50000, SellPrice: 60000, TotalFees: 1}
+	if _, err := repo.Save(completed); err != nil {
+		t.Fatalf("enregistrement du cycle complété: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(completed.IdInt) })
+
+	cycle := &database.Cycle{
+		Exchange:  "BINANCE",
+		Status:    "sell",
+		Quantity:  0.001,
+		BuyPrice:  62000,
+		SellPrice: 63200,
+		BuyFees:   0.062,
+		SellId:    mock.AddOrder("6001", "SELL", 63200, 0.001),
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() {
+		repo.DeleteByIdInt(cycle.IdInt)
+		accumulations, _ := accuRepo.FindByExchange("BINANCE")
+		for _, accumulation := range accumulations {
+			if accumulation.CycleIdInt == cycle.IdInt {
+				accuRepo.DeleteByIdInt(accumulation.IdInt)
+			}
+		}
+	})
+
+	// Annulation en échec: le cycle et sa vente restent en place
+	mock.Errors["CancelOrderIdempotent"] = errors.New("HTTP status 503 - service unavailable")
+	stored, _ := repo.FindByIdInt(cycle.IdInt)
+	processSellCycle(mock, repo, stored, 55000)
+	if stored, _ = repo.FindByIdInt(cycle.IdInt); stored == nil || mock.OrderStatus("6001") != "NEW" {
+		t.Fatalf("accumulation malgré l'échec de l'annulation: cycle %v, vente %s", stored, mock.OrderStatus("6001"))
+	}
+
+	delete(mock.Errors, "CancelOrderIdempotent")
+	processSellCycle(mock, repo, stored, 55000)
+	if mock.OrderStatus("6001") != "CANCELED" {
+		t.Errorf("vente %s, attendue annulée sur l'exchange", mock.OrderStatus("6001"))
+	}
+	if stored, _ = repo.FindByIdInt(cycle.IdInt); stored != nil {
+		t.Error("le cycle accumulé devrait être supprimé")
+	}
+}
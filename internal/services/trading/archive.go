@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// Archive déplace les cycles complétés anciens vers l'archive pour alléger les requêtes:
+// --archive --before=2023-01-01 [--dry-run]
+func Archive() {
+	var before time.Time
+	dryRun := false
+
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--before="):
+			value := strings.TrimPrefix(arg, "--before=")
+			parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				color.Red("Date invalide: %s. Utilisez --before=AAAA-MM-JJ", value)
+				os.Exit(1)
+			}
+			before = parsed
+		case arg == "--dry-run":
+			dryRun = true
+		}
+	}
+
+	if before.IsZero() {
+		color.Red("Date manquante. Utilisez --archive --before=2023-01-01")
+		os.Exit(1)
+	}
+
+	repo := database.GetRepository()
+	cycles, err := repo.FindCompletedBetween(time.Time{}, before)
+	if err != nil {
+		color.Red("Erreur lors de la récupération des cycles: %v", err)
+		os.Exit(1)
+	}
+
+	totalProfit := 0.0
+	for _, cycle := range cycles {
+		totalProfit += cycle.CalculateProfit()
+	}
+	color.White("%d cycle(s) complété(s) avant le %s (profit: %.2f USDC)",
+		len(cycles), before.Format("02/01/2006"), totalProfit)
+
+	if dryRun {
+		color.Yellow("Simulation: aucun cycle n'a été archivé")
+		return
+	}
+
+	if len(cycles) > 0 {
+		archived, err := repo.ArchiveCompletedBefore(before)
+		if err != nil {
+			color.Red("Erreur lors de l'archivage: %v", err)
+			os.Exit(1)
+		}
+		color.Green("%d cycle(s) archivé(s). Ils restent consultables dans les statistiques (Inclure les cycles archivés).", archived)
+	}
+
+	stripOrderSnapshots()
+}
+
+// stripOrderSnapshots supprime les réponses brutes des ordres conservées sur les cycles, actifs
+// et archivés, créés il y a plus de ORDER_SNAPSHOT_RETENTION_DAYS jours
+func stripOrderSnapshots() {
+	if cfg == nil || cfg.OrderSnapshotRetentionDays <= 0 {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -cfg.OrderSnapshotRetentionDays)
+	stripped := 0
+	for _, repo := range []*database.CycleRepository{database.GetRepository(), database.GetArchiveRepository()} {
+		count, err := repo.StripOrderSnapshotsBefore(cutoff)
+		if err != nil {
+			color.Red("Erreur lors de la suppression des réponses brutes des ordres: %v", err)
+			os.Exit(1)
+		}
+		stripped += count
+	}
+	if stripped > 0 {
+		color.Green("Réponses brutes des ordres supprimées de %d cycle(s) de plus de %d jours", stripped, cfg.OrderSnapshotRetentionDays)
+	}
+}
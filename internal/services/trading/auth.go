@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"main/internal/web"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authCookieName est le nom du cookie de session posé après connexion
+const authCookieName = "botspot_session"
+
+// Délai maximal imposé à une IP après des échecs d'authentification répétés
+const maxAuthBackoff = 5 * time.Minute
+
+// authFailure mémorise les échecs d'authentification d'une adresse IP
+type authFailure struct {
+	count        int
+	blockedUntil time.Time
+}
+
+var (
+	authFailuresMu sync.Mutex
+	authFailures   = make(map[string]*authFailure)
+)
+
+// authEnabled indique si un jeton d'accès est configuré
+func authEnabled() bool {
+	return cfg != nil && cfg.AuthToken != ""
+}
+
+// sessionValue dérive la valeur du cookie de session à partir du jeton,
+// afin de ne jamais renvoyer le jeton lui-même au navigateur
+func sessionValue() string {
+	sum := sha256.Sum256([]byte("botspot-session:" + cfg.AuthToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// constantTimeEqual compare deux chaînes sans fuite d'information temporelle
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// isAuthenticated vérifie le jeton Bearer ou le cookie de session de la requête
+func isAuthenticated(r *http.Request) bool {
+	if !authEnabled() {
+		return true
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return constantTimeEqual(strings.TrimPrefix(header, "Bearer "), cfg.AuthToken)
+	}
+
+	if cookie, err := r.Cookie(authCookieName); err == nil {
+		return constantTimeEqual(cookie.Value, sessionValue())
+	}
+
+	return false
+}
+
+// clientIP extrait l'adresse IP du client sans tenir compte du port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// authBlockedFor retourne la durée restante de blocage pour une IP
+func authBlockedFor(ip string) time.Duration {
+	authFailuresMu.Lock()
+	defer authFailuresMu.Unlock()
+
+	failure, exists := authFailures[ip]
+	if !exists {
+		return 0
+	}
+	return time.Until(failure.blockedUntil)
+}
+
+// recordAuthFailure enregistre un échec et double le délai de blocage de l'IP
+func recordAuthFailure(ip string) {
+	authFailuresMu.Lock()
+	defer authFailuresMu.Unlock()
+
+	failure, exists := authFailures[ip]
+	if !exists {
+		failure = &authFailure{}
+		authFailures[ip] = failure
+	}
+	failure.count++
+
+	backoff := time.Second << uint(min(failure.count-1, 9))
+	if backoff > maxAuthBackoff {
+		backoff = maxAuthBackoff
+	}
+	failure.blockedUntil = time.Now().Add(backoff)
+}
+
+// resetAuthFailures efface l'historique d'échecs d'une IP après un succès
+func resetAuthFailures(ip string) {
+	authFailuresMu.Lock()
+	defer authFailuresMu.Unlock()
+	delete(authFailures, ip)
+}
+
+// checkAuth valide la requête et applique le délai anti-force brute par IP.
+// Retourne false si une réponse d'erreur a déjà été envoyée.
+func checkAuth(w http.ResponseWriter, r *http.Request) bool {
+	if !authEnabled() {
+		return true
+	}
+
+	ip := clientIP(r)
+	if remaining := authBlockedFor(ip); remaining > 0 {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(remaining.Seconds())+1))
+		http.Error(w, "Trop de tentatives échouées, réessayez plus tard", http.StatusTooManyRequests)
+		return false
+	}
+
+	if isAuthenticated(r) {
+		resetAuthFailures(ip)
+		return true
+	}
+
+	// Seul un jeton invalide compte comme un échec: un cookie de session périmé (jeton changé
+	// depuis la connexion) est effacé et le navigateur renvoyé vers le formulaire de connexion,
+	// dont les soumissions erronées sont comptées par handleLogin
+	staleSession := false
+	if r.Header.Get("Authorization") != "" {
+		recordAuthFailure(ip)
+	} else if _, err := r.Cookie(authCookieName); err == nil {
+		staleSession = true
+		http.SetCookie(w, &http.Cookie{
+			Name:     authCookieName,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   cfg.TLSEnabled(),
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+
+	// Rediriger les navigateurs vers le formulaire de connexion
+	if r.Method == http.MethodGet && (staleSession || strings.Contains(r.Header.Get("Accept"), "text/html")) {
+		http.Redirect(w, r, "/login?next="+r.URL.RequestURI(), http.StatusSeeOther)
+		return false
+	}
+	if staleSession {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return false
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="bot-spot"`)
+	http.Error(w, "Authentification requise", http.StatusUnauthorized)
+	return false
+}
+
+// requireAuth protège une route en lecture. Si SERVER_AUTH_PUBLIC_READ est activé,
+// la route reste accessible sans jeton.
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cfg != nil && cfg.AuthPublicRead {
+			next(w, r)
+			return
+		}
+		if !checkAuth(w, r) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireAuthPost protège une route qui modifie l'état du bot : elle exige
+// une requête POST et le jeton, même lorsque les pages en lecture sont ouvertes.
+func requireAuthPost(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if !checkAuth(w, r) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleLogin affiche le formulaire de connexion et pose le cookie de session
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !authEnabled() {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/"
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := map[string]interface{}{
+		"next":  next,
+		"error": "",
+	}
+
+	if r.Method != http.MethodPost {
+		renderTemplate(w, web.LoginTemplate, data)
+		return
+	}
+
+	ip := clientIP(r)
+	if remaining := authBlockedFor(ip); remaining > 0 {
+		data["error"] = fmt.Sprintf("Trop de tentatives, réessayez dans %d s.", int(remaining.Seconds())+1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		renderTemplate(w, web.LoginTemplate, data)
+		return
+	}
+
+	if !constantTimeEqual(r.FormValue("token"), cfg.AuthToken) {
+		recordAuthFailure(ip)
+		data["error"] = "Jeton invalide."
+		w.WriteHeader(http.StatusUnauthorized)
+		renderTemplate(w, web.LoginTemplate, data)
+		return
+	}
+
+	resetAuthFailures(ip)
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Value:    sessionValue(),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   cfg.TLSEnabled(),
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// readOnly indique si le tableau de bord est en lecture seule (SERVER_READ_ONLY ou --server -readonly)
+func readOnly() bool {
+	if cfg != nil && cfg.ServerReadOnly {
+		return true
+	}
+	for _, arg := range GetAllArgs() {
+		if arg == "-readonly" || arg == "--readonly" {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyMessage est la réponse aux requêtes de modification en lecture seule
+const readOnlyMessage = "Tableau de bord en lecture seule: aucune action n'est possible depuis cette page"
+
+// requireWritable refuse, en lecture seule, toute requête susceptible de modifier l'état du bot.
+// Appliqué à l'ensemble du serveur, il couvre aussi les routes ajoutées par la suite: seules
+// les méthodes de lecture, la connexion et l'abonnement aux notifications restent permis.
+func requireWritable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case !readOnly(), r.Method == http.MethodGet, r.Method == http.MethodHead, r.URL.Path == "/login",
+			strings.HasPrefix(r.URL.Path, "/api/push/"):
+			next.ServeHTTP(w, r)
+		case strings.HasPrefix(r.URL.Path, "/api/"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": readOnlyMessage})
+		default:
+			http.Error(w, readOnlyMessage, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"main/internal/config"
+)
+
+func TestRequireWritable(t *testing.T) {
+	previous := cfg
+	cfg = &config.Config{ServerReadOnly: true}
+	t.Cleanup(func() { cfg = previous })
+
+	handler := requireWritable(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/", http.StatusOK},
+		{http.MethodGet, "/api/cycles", http.StatusOK},
+		{http.MethodPost, "/login", http.StatusOK},
+		{http.MethodPost, "/update", http.StatusForbidden},
+		{http.MethodPost, "/cycles/42/pause", http.StatusForbidden},
+		{http.MethodPost, "/api/cycles/bulk", http.StatusForbidden},
+		// Une route ajoutée sans précaution particulière est aussi protégée
+		{http.MethodDelete, "/api/some-future-endpoint", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+		if rec.Code != c.code {
+			t.Errorf("%s %s: code %d, attendu %d", c.method, c.path, rec.Code, c.code)
+		}
+	}
+}
+
+// Un cookie de session périmé est effacé sans pénaliser l'IP, un mauvais jeton est compté
+func TestCheckAuthStaleSession(t *testing.T) {
+	previous := cfg
+	cfg = &config.Config{AuthToken: "nouveau-jeton"}
+	t.Cleanup(func() {
+		cfg = previous
+		resetAuthFailures("192.0.2.1")
+	})
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/cycles", nil)
+		req.AddCookie(&http.Cookie{Name: authCookieName, Value: "session-de-l-ancien-jeton"})
+		rec := httptest.NewRecorder()
+		if checkAuth(rec, req) {
+			t.Fatal("un cookie périmé ne devrait pas authentifier la requête")
+		}
+		if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?next=/api/cycles" {
+			t.Fatalf("code %d vers %q, attendu une redirection vers /login", rec.Code, rec.Header().Get("Location"))
+		}
+		cleared := rec.Result().Cookies()
+		if len(cleared) != 1 || cleared[0].Name != authCookieName || cleared[0].MaxAge >= 0 {
+			t.Fatalf("cookie de session non effacé: %v", cleared)
+		}
+	}
+	if remaining := authBlockedFor("192.0.2.1"); remaining > 0 {
+		t.Fatalf("IP bloquée %s après des cookies périmés", remaining)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/cycles", nil)
+	req.Header.Set("Authorization", "Bearer ancien-jeton")
+	rec := httptest.NewRecorder()
+	if checkAuth(rec, req) || rec.Code != http.StatusUnauthorized {
+		t.Fatalf("mauvais jeton: code %d, attendu 401", rec.Code)
+	}
+	if authBlockedFor("192.0.2.1") <= 0 {
+		t.Error("un mauvais jeton devrait être compté comme un échec")
+	}
+}
@@ -0,0 +1,438 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// AverageDown renforce un cycle en perte par un achat au prix actuel, fusionné dans le cycle
+// après son exécution: --average-down --id=123 --usdc=200
+func AverageDown() {
+	var idStr, usdcStr string
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--id="):
+			idStr = strings.TrimPrefix(arg, "--id=")
+		case strings.HasPrefix(arg, "--usdc="):
+			usdcStr = strings.TrimPrefix(arg, "--usdc=")
+		}
+	}
+
+	if idStr == "" || usdcStr == "" {
+		color.Red(i18n.T("average_down.usage"))
+		os.Exit(1)
+	}
+
+	idInt, err := strconv.Atoi(idStr)
+	if err != nil {
+		color.Red(i18n.T("average_down.invalid_id"), idStr)
+		os.Exit(1)
+	}
+	usdc, err := strconv.ParseFloat(usdcStr, 64)
+	if err != nil {
+		color.Red(i18n.T("average_down.invalid_amount"), usdcStr)
+		os.Exit(1)
+	}
+
+	cycle, err := startAverageDown(int32(idInt), usdc)
+	if err != nil {
+		color.Red(i18n.T("average_down.failed"), err)
+		os.Exit(1)
+	}
+
+	color.Green(i18n.T("average_down.buy_started"), cycle.IdInt,
+		cycle.AverageDownBuyId, cycle.AverageDownQuantity, cycle.AverageDownPrice)
+	color.Cyan(i18n.T("average_down.merge_pending"))
+}
+
+// startAverageDown place l'achat supplémentaire d'une moyenne à la baisse au prix actuel.
+// L'étape est enregistrée avant l'envoi de l'ordre: un arrêt brutal est repris par la mise à jour
+// grâce à l'identifiant client. Le cycle mis à jour est retourné.
+func startAverageDown(idInt int32, usdc float64) (*database.Cycle, error) {
+	if usdc <= 0 {
+		return nil, fmt.Errorf("le montant doit être positif")
+	}
+
+	repo := database.GetRepository()
+	cycle, err := repo.FindByIdInt(idInt)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération du cycle: %w", err)
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("cycle avec ID %d introuvable", idInt)
+	}
+	if cycle.Status != "sell" {
+		return nil, fmt.Errorf("le cycle %d a le statut '%s', seul un cycle en vente peut être renforcé", idInt, cycle.Status)
+	}
+	if cycle.AverageDownState != "" {
+		return nil, fmt.Errorf("une moyenne à la baisse est déjà en cours sur le cycle %d (étape %s)", idInt, cycle.AverageDownState)
+	}
+	if cycle.Paused {
+		return nil, fmt.Errorf("le cycle %d est en pause (--resume=%d pour le reprendre)", idInt, idInt)
+	}
+	if cycle.StopId != "" {
+		return nil, fmt.Errorf("le cycle %d est vendu par un OCO, sa vente ne peut pas être replacée", idInt)
+	}
+
+	exchangeConfig := cfg.Exchanges[cycle.Exchange]
+	if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+		return nil, fmt.Errorf("clés API %s non configurées", cycle.Exchange)
+	}
+	client := GetClientByExchange(cycle.Exchange)
+
+	price := client.GetLastPriceBTC()
+	if price <= 0 {
+		return nil, fmt.Errorf("prix BTC indisponible sur %s", cycle.Exchange)
+	}
+	if price >= cycle.EffectiveBuyPrice() {
+		return nil, fmt.Errorf("le prix actuel %.2f n'est pas inférieur au prix d'achat %.2f: le cycle n'est pas en perte",
+			price, cycle.EffectiveBuyPrice())
+	}
+
+	// Vérifier les fonds comme pour un nouveau cycle (frais et minimum de l'exchange compris)
+	quantity := CalcAmountBTC(usdc, price)
+	rates := exchangeFeeRates(cycle.Exchange)
+	funding := newFundingCheck(price, quantity, math.Max(rates.Maker, rates.Taker), minOrderNotional(client), usableUSDC(client, cycle.Exchange))
+	if funding.BelowMinimum() {
+		return nil, fmt.Errorf("ordre de %.2f USDC inférieur au minimum de %.2f USDC sur %s", funding.Notional, funding.MinNotional, cycle.Exchange)
+	}
+	if funding.Missing() > 0 {
+		return nil, fmt.Errorf("fonds insuffisants sur %s: %.2f USDC manquants", cycle.Exchange, funding.Missing())
+	}
+
+	clientOrderID := common.ClientOrderID(cycle.IdInt, fmt.Sprintf("avg-%d", cycle.AverageDownCount+1))
+	ev := cycleEvent(cycle, "average_down").with("price", price)
+
+	// Enregistrer l'étape avant l'ordre: la mise à jour retrouvera l'achat par son identifiant client
+	err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+		"averageDownState":         database.AverageDownBuying,
+		"averageDownBuyId":         "",
+		"averageDownClientOrderId": clientOrderID,
+		"averageDownQuantity":      quantity,
+		"averageDownPrice":         price,
+		"averageDownFees":          0.0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de l'enregistrement de la moyenne à la baisse: %w", err)
+	}
+	cycle.AverageDownState = database.AverageDownBuying
+	cycle.AverageDownClientOrderId = clientOrderID
+	cycle.AverageDownQuantity = quantity
+	cycle.AverageDownPrice = price
+
+	created, placedPrice, err := createLimitOrder(client, cycle.Exchange, "BUY", price, client.FormatQuantity(quantity), clientOrderID)
+	if err != nil {
+		// L'ordre a pu être créé malgré l'erreur: l'étape n'est abandonnée que s'il est introuvable
+		if _, found := findClientOrder(client, clientOrderID); !found {
+			clearAverageDown(repo, cycle)
+		}
+		return nil, fmt.Errorf("erreur lors de la création de l'ordre d'achat: %w", err)
+	}
+
+	orderId := created.OrderID
+
+	err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+		"averageDownBuyId": orderId,
+		"averageDownPrice": placedPrice,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ordre %s placé mais erreur lors de la mise à jour du cycle: %w", orderId, err)
+	}
+	cycle.AverageDownBuyId = orderId
+	cycle.AverageDownPrice = placedPrice
+
+	ev.with("order_id", orderId).with("price", placedPrice).
+		success(i18n.T("average_down.buy_placed"), cycle.IdInt, quantity, placedPrice)
+	return cycle, nil
+}
+
+// advanceAverageDown fait avancer la moyenne à la baisse en cours d'un cycle en vente: exécution
+// de l'achat, annulation de l'ancienne vente, puis fusion et nouvelle vente. Chaque étape est
+// enregistrée avant de passer à la suivante, une étape interrompue est reprise à la mise à jour suivante.
+func advanceAverageDown(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, lastPrice float64) {
+	ev := cycleEvent(cycle, "average_down").with("order_id", cycle.AverageDownBuyId)
+
+	if cycle.AverageDownState == database.AverageDownBuying && !checkAverageDownBuy(client, repo, cycle, ev) {
+		return
+	}
+
+	if cycle.AverageDownState == database.AverageDownFilled {
+		result, err := safeOrderCancel(client, cleanOrderId(cycle.SellId, cycle.Exchange), cycle.IdInt)
+		switch {
+		case result == common.CancelFailed:
+			ev.with("error", err).warn(i18n.T("average_down.sell_cancel_failed"),
+				cycle.IdInt, cycle.SellId, err)
+			return
+		case result == common.AlreadyGone && orderFilled(client, cleanOrderId(cycle.SellId, cycle.Exchange)):
+			// La vente est exécutée: le cycle se termine et l'achat supplémentaire en devient un nouveau
+			ev.info(i18n.T("average_down.sell_filled_before_merge"),
+				cycle.IdInt, cycle.SellId)
+			return
+		}
+
+		if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{"averageDownState": database.AverageDownReselling}); err != nil {
+			ev.with("error", err).fail(i18n.T("average_down.sell_cancel_save_error"), cycle.IdInt, err)
+			return
+		}
+		cycle.AverageDownState = database.AverageDownReselling
+		ev.info(i18n.T("average_down.sell_cancelled"), cycle.IdInt, cycle.SellId)
+	}
+
+	if cycle.AverageDownState == database.AverageDownReselling {
+		mergeAndResell(client, repo, cycle, ev, lastPrice)
+	}
+}
+
+// checkAverageDownBuy suit l'achat supplémentaire et enregistre son exécution. Le booléen est vrai
+// une fois l'achat exécuté (étape AverageDownFilled).
+func checkAverageDownBuy(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent) bool {
+	// Ordre créé avant un arrêt brutal, sans que son ID ait été enregistré
+	if cycle.AverageDownBuyId == "" {
+		order, found := findClientOrder(client, cycle.AverageDownClientOrderId)
+		if !found {
+			ev.warn(i18n.T("average_down.buy_not_found"),
+				cycle.IdInt, cycle.AverageDownClientOrderId)
+			clearAverageDown(repo, cycle)
+			return false
+		}
+		if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{"averageDownBuyId": order.ID}); err != nil {
+			ev.with("error", err).fail(i18n.T("average_down.buy_save_error"), cycle.IdInt, err)
+			return false
+		}
+		cycle.AverageDownBuyId = order.ID
+		ev = ev.with("order_id", order.ID)
+	}
+
+	orderId := cleanOrderId(cycle.AverageDownBuyId, cycle.Exchange)
+	status, err := client.GetOrderStatus(orderId)
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("average_down.buy_status_error"),
+			cycle.IdInt, cycle.AverageDownBuyId, err)
+		return false
+	}
+
+	// Seule une exécution complète, ou partielle d'un ordre annulé, est fusionnée: un ordre encore
+	// ouvert, même partiellement exécuté, peut toujours acheter le reste de sa quantité
+	switch {
+	case status.State == common.OrderRejected:
+		ev.warn(i18n.T("average_down.buy_rejected"), cycle.IdInt, cycle.AverageDownBuyId)
+		clearAverageDown(repo, cycle)
+		return false
+	case status.State == common.OrderCancelled && status.ExecutedQty <= 0:
+		ev.warn(i18n.T("average_down.buy_cancelled"),
+			cycle.IdInt, cycle.AverageDownBuyId)
+		clearAverageDown(repo, cycle)
+		return false
+	case status.State == common.OrderOpen || status.State == common.OrderPartiallyFilled:
+		ev.info(i18n.T("average_down.buy_waiting"), cycle.IdInt, cycle.AverageDownBuyId)
+		return false
+	case status.State != common.OrderFilled && status.State != common.OrderCancelled:
+		return false
+	}
+
+	// Seule la quantité exécutée est fusionnée
+	quantity := cycle.AverageDownQuantity
+	if status.ExecutedQty > 0 {
+		quantity = status.ExecutedQty
+	}
+	price := cycle.AverageDownPrice
+	if status.AvgFillPrice > 0 {
+		price = status.AvgFillPrice
+	}
+
+	fees, err := client.GetOrderFees(orderId)
+	feesEstimated := false
+	if err != nil && status.Fee != 0 {
+		fees = status.Fee
+	} else if err != nil {
+		feeRate := getFeeRateForExchange(cycle.Exchange)
+		fees = price * quantity * feeRate
+		feesEstimated = true
+		ev.warn(i18n.T("average_down.fees_estimated"), cycle.IdInt, fees, feeRate*100)
+	}
+
+	update := map[string]interface{}{
+		"averageDownState":    database.AverageDownFilled,
+		"averageDownQuantity": quantity,
+		"averageDownPrice":    price,
+		"averageDownFees":     fees,
+	}
+	if feesEstimated {
+		update["feesEstimated"] = true
+	}
+	if err := repo.UpdateByIdInt(cycle.IdInt, update); err != nil {
+		ev.with("error", err).fail(i18n.T("average_down.buy_save_error"), cycle.IdInt, err)
+		return false
+	}
+	cycle.AverageDownState = database.AverageDownFilled
+	cycle.AverageDownQuantity = quantity
+	cycle.AverageDownPrice = price
+	cycle.AverageDownFees = fees
+	cycle.FeesEstimated = cycle.FeesEstimated || feesEstimated
+
+	ev.with("price", price).success(i18n.T("average_down.buy_filled"),
+		cycle.IdInt, quantity, price, fees)
+	return true
+}
+
+// mergeAverageDown calcule le cycle après fusion de l'achat supplémentaire: quantité totale,
+// prix d'achat moyen pondéré, montant d'achat et frais cumulés
+func mergeAverageDown(cycle *database.Cycle) (quantity, buyFillPrice, purchaseAmountUSDC, buyFees float64) {
+	purchaseAmountUSDC = cycle.PurchaseAmountUSDC
+	if purchaseAmountUSDC <= 0 {
+		purchaseAmountUSDC = cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+	purchaseAmountUSDC += cycle.AverageDownPrice * cycle.AverageDownQuantity
+
+	quantity = cycle.Quantity + cycle.AverageDownQuantity
+	if quantity > 0 {
+		buyFillPrice = purchaseAmountUSDC / quantity
+	}
+	buyFees = cycle.BuyFees + cycle.AverageDownFees
+	return quantity, buyFillPrice, purchaseAmountUSDC, buyFees
+}
+
+// mergeAndResell fusionne l'achat supplémentaire dans le cycle et place la nouvelle vente au prix
+// calculé par computeSellPrice sur le prix d'achat moyen. La fusion n'est enregistrée qu'avec la nouvelle vente, en une seule
+// mise à jour: une vente placée avant un arrêt brutal est retrouvée par son identifiant client.
+func mergeAndResell(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent, lastPrice float64) {
+	// Le prix de vente dépend du prix actuel: la vente attend une mise à jour au prix fiable
+	if !priceTrusted(cycle.Exchange) {
+		ev.with("action", "price_untrusted").warn(i18n.T("update.sell_price_untrusted"), cycle.IdInt, cycle.Exchange)
+		return
+	}
+	quantity, buyFillPrice, purchaseAmountUSDC, buyFees := mergeAverageDown(cycle)
+
+	// Prix calculé comme toute autre vente (frais, profit minimum, carnet) sur le cycle fusionné.
+	// Sans ID d'achat, les frais d'achat sont estimés sur la quantité totale des deux achats.
+	merged := *cycle
+	merged.BuyPrice = buyFillPrice
+	merged.BuyFillPrice = buyFillPrice
+	merged.PurchaseAmountUSDC = purchaseAmountUSDC
+	merged.Quantity = quantity
+	sellPrice := computeSellPrice(client, &merged, ev, cfg.Exchanges[cycle.Exchange], lastPrice, buyFees, "")
+
+	availableBTC, err := waitForFreeBTC(client, cycle.Exchange, quantity)
+	if err != nil {
+		ev.with("error", err).warn(i18n.T("average_down.sell_deferred"), cycle.IdInt, err)
+		return
+	}
+	quantityToSell := math.Min(quantity, availableBTC)
+	quantityStr := client.FormatQuantity(quantityToSell)
+
+	clientOrderID := common.ClientOrderID(cycle.IdInt, fmt.Sprintf("sell-avg-%d", cycle.AverageDownCount+1))
+	orderIdStr, placedPrice, err := placeLimitSell(client, repo, cycle, ev, sellPrice, quantityStr, clientOrderID)
+	if err != nil {
+		return
+	}
+
+	saleAmountUSDC := placedPrice * quantityToSell
+	err = repo.MergeAverageDown(cycle.IdInt, cycle.SellId, database.SellOrder{
+		OrderId:        orderIdStr,
+		ClientOrderId:  clientOrderID,
+		Price:          placedPrice,
+		SaleAmountUSDC: saleAmountUSDC,
+	}, database.AverageDownMerge{
+		Quantity:           quantity,
+		BuyFillPrice:       buyFillPrice,
+		PurchaseAmountUSDC: purchaseAmountUSDC,
+		BuyFees:            buyFees,
+		Count:              cycle.AverageDownCount + 1,
+	})
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("average_down.merge_save_error"),
+			cycle.IdInt, orderIdStr, err)
+		return
+	}
+
+	addedQuantity := cycle.AverageDownQuantity
+	cycle.Quantity = quantity
+	cycle.BuyFillPrice = buyFillPrice
+	cycle.PurchaseAmountUSDC = purchaseAmountUSDC
+	cycle.BuyFees = buyFees
+	cycle.TotalFees = buyFees
+	cycle.SellPrice = placedPrice
+	cycle.SellId = orderIdStr
+	cycle.SellClientOrderId = clientOrderID
+	cycle.StopId, cycle.StopPrice = "", 0
+	cycle.SaleAmountUSDC = saleAmountUSDC
+	cycle.AverageDownCount++
+	resetAverageDown(cycle)
+
+	ev.with("order_id", orderIdStr).with("price", placedPrice).
+		success(i18n.T("average_down.merged"),
+			cycle.IdInt, addedQuantity, buyFillPrice, quantityToSell, placedPrice)
+	ev.notify(cycle, "Cycle %d renforcé: prix d'achat moyen %.2f USDC, vente replacée à %.2f USDC",
+		cycle.IdInt, buyFillPrice, placedPrice)
+}
+
+// detachAverageDown transforme l'achat supplémentaire d'un cycle terminé avant la fusion en un
+// cycle distinct, suivi ensuite comme un achat ordinaire
+func detachAverageDown(repo *database.CycleRepository, cycle *database.Cycle) {
+	ev := cycleEvent(cycle, "average_down").with("order_id", cycle.AverageDownBuyId)
+	if cycle.AverageDownBuyId == "" {
+		clearAverageDown(repo, cycle)
+		return
+	}
+
+	detached := &database.Cycle{
+		Exchange:         cycle.Exchange,
+		Status:           "buy",
+		Quantity:         cycle.AverageDownQuantity,
+		BuyPrice:         cycle.AverageDownPrice,
+		BuyId:            cycle.AverageDownBuyId,
+		SellPrice:        cycle.AverageDownPrice + cfg.Exchanges[cycle.Exchange].SellOffsetAt(cycle.AverageDownPrice),
+		CreatedAt:        time.Now(),
+		BuyClientOrderId: cycle.AverageDownClientOrderId,
+	}
+	if _, err := repo.Save(detached); err != nil {
+		ev.with("error", err).fail(i18n.T("average_down.detach_error"),
+			cycle.IdInt, cycle.AverageDownBuyId, err)
+		return
+	}
+
+	ev.warn(i18n.T("average_down.detached"),
+		cycle.IdInt, cycle.AverageDownBuyId, detached.IdInt)
+	clearAverageDown(repo, cycle)
+}
+
+// clearAverageDown met fin à la moyenne à la baisse en cours d'un cycle, sans fusion
+func clearAverageDown(repo *database.CycleRepository, cycle *database.Cycle) {
+	if err := repo.UpdateByIdInt(cycle.IdInt, averageDownClearedFields()); err != nil {
+		color.Red(i18n.T("average_down.clear_error"), cycle.IdInt, err)
+		return
+	}
+	resetAverageDown(cycle)
+}
+
+// averageDownClearedFields retourne les champs à enregistrer pour effacer l'étape et l'achat supplémentaire
+func averageDownClearedFields() map[string]interface{} {
+	return map[string]interface{}{
+		"averageDownState":         "",
+		"averageDownBuyId":         "",
+		"averageDownClientOrderId": "",
+		"averageDownQuantity":      0.0,
+		"averageDownPrice":         0.0,
+		"averageDownFees":          0.0,
+	}
+}
+
+// resetAverageDown efface en mémoire l'étape et l'achat supplémentaire d'un cycle
+func resetAverageDown(cycle *database.Cycle) {
+	cycle.AverageDownState = ""
+	cycle.AverageDownBuyId = ""
+	cycle.AverageDownClientOrderId = ""
+	cycle.AverageDownQuantity = 0
+	cycle.AverageDownPrice = 0
+	cycle.AverageDownFees = 0
+}
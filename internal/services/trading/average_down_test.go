@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"math"
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/testutil"
+)
+
+func TestAverageDownMerge(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, APIKey: "key", SecretKey: "secret"}, 60000)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+
+	// Cycle en vente acheté à 62000, le prix est tombé à 60000
+	cycle := &database.Cycle{
+		Exchange:           "BINANCE",
+		Status:             "sell",
+		Quantity:           0.001,
+		BuyPrice:           62000,
+		BuyFillPrice:       62000,
+		PurchaseAmountUSDC: 62,
+		BuyFees:            0.06,
+		TotalFees:          0.06,
+		SellPrice:          63200,
+		SellId:             mock.AddOrder("5001", "SELL", 63200, 0.001),
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+
+	started, err := startAverageDown(cycle.IdInt, 60)
+	if err != nil {
+		t.Fatalf("startAverageDown: %v", err)
+	}
+	if started.AverageDownState != database.AverageDownBuying || started.AverageDownBuyId == "" {
+		t.Fatalf("achat supplémentaire non enregistré: étape %q, ordre %q", started.AverageDownState, started.AverageDownBuyId)
+	}
+
+	// Achat non exécuté: la vente d'origine reste en place
+	stored, _ := repo.FindByIdInt(cycle.IdInt)
+	processSellCycle(mock, repo, stored, mock.Price)
+	if status := mock.OrderStatus("5001"); status != "NEW" {
+		t.Fatalf("vente d'origine %s avant l'exécution de l'achat supplémentaire", status)
+	}
+
+	// Achat exécuté: fusion au prix moyen et vente replacée à moyenne + SELL_OFFSET
+	if err := mock.FillOrder(started.AverageDownBuyId); err != nil {
+		t.Fatal(err)
+	}
+	mock.Fees[started.AverageDownBuyId] = 0.06
+	mock.SetBalance("BTC", 0.002)
+
+	stored, _ = repo.FindByIdInt(cycle.IdInt)
+	processSellCycle(mock, repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.AverageDownState != "" || stored.AverageDownCount != 1 {
+		t.Fatalf("fusion non terminée: étape %q, %d fusion(s)", stored.AverageDownState, stored.AverageDownCount)
+	}
+	if mock.OrderStatus("5001") != "CANCELED" {
+		t.Error("la vente d'origine devrait être annulée")
+	}
+	if math.Abs(stored.Quantity-0.002) > 1e-12 || math.Abs(stored.BuyFillPrice-61000) > 1e-6 {
+		t.Errorf("cycle fusionné: %.8f BTC à %.2f, attendu 0.002 à 61000", stored.Quantity, stored.BuyFillPrice)
+	}
+	if math.Abs(stored.TotalFees-0.12) > 1e-9 {
+		t.Errorf("frais cumulés %.4f, attendu 0.12", stored.TotalFees)
+	}
+	if stored.SellPrice != 62200 || stored.SellId == "5001" || mock.OrderStatus(stored.SellId) != "NEW" {
+		t.Errorf("nouvelle vente %q à %.2f, attendue à 62200", stored.SellId, stored.SellPrice)
+	}
+}
+
+// startAverageDownCycle enregistre un cycle en vente acheté à 62000 et lance son achat supplémentaire
+func startAverageDownCycle(t *testing.T, mock *testutil.MockExchange, sellId string) *database.Cycle {
+	t.Helper()
+	repo := database.GetRepository()
+	cycle := &database.Cycle{
+		Exchange:           "BINANCE",
+		Status:             "sell",
+		Quantity:           0.001,
+		BuyPrice:           62000,
+		BuyFillPrice:       62000,
+		PurchaseAmountUSDC: 62,
+		BuyFees:            0.06,
+		TotalFees:          0.06,
+		SellPrice:          63200,
+		SellId:             mock.AddOrder(sellId, "SELL", 63200, 0.001),
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+
+	started, err := startAverageDown(cycle.IdInt, 60)
+	if err != nil {
+		t.Fatalf("startAverageDown: %v", err)
+	}
+	return started
+}
+
+// Seul un achat supplémentaire terminé est fusionné: ouvert partiellement exécuté il reste attendu,
+// refusé il est abandonné, annulé après une exécution partielle seule la partie exécutée est fusionnée
+func TestAverageDownBuyStates(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, APIKey: "key", SecretKey: "secret"}, 60000)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+
+	t.Run("partiellement exécuté", func(t *testing.T) {
+		started := startAverageDownCycle(t, mock, "5101")
+		if err := mock.PartiallyFillOrder(started.AverageDownBuyId, 0.0004); err != nil {
+			t.Fatal(err)
+		}
+		mock.SetBalance("BTC", 0.0014)
+
+		stored, _ := repo.FindByIdInt(started.IdInt)
+		processSellCycle(mock, repo, stored, mock.Price)
+
+		stored, _ = repo.FindByIdInt(started.IdInt)
+		if stored.AverageDownState != database.AverageDownBuying || stored.Quantity != 0.001 {
+			t.Errorf("achat partiel fusionné: étape %q, %.8f BTC", stored.AverageDownState, stored.Quantity)
+		}
+		if mock.OrderStatus("5101") != "NEW" || mock.OrderStatus(started.AverageDownBuyId) != "PARTIALLY_FILLED" {
+			t.Errorf("ordres modifiés: vente %s, achat %s", mock.OrderStatus("5101"), mock.OrderStatus(started.AverageDownBuyId))
+		}
+	})
+
+	t.Run("refusé", func(t *testing.T) {
+		started := startAverageDownCycle(t, mock, "5102")
+		if err := mock.RejectOrder(started.AverageDownBuyId); err != nil {
+			t.Fatal(err)
+		}
+
+		stored, _ := repo.FindByIdInt(started.IdInt)
+		processSellCycle(mock, repo, stored, mock.Price)
+
+		stored, _ = repo.FindByIdInt(started.IdInt)
+		if stored.AverageDownState != "" || stored.AverageDownCount != 0 || stored.Quantity != 0.001 || stored.SellId != "5102" {
+			t.Errorf("achat refusé fusionné: étape %q, %d fusion(s), %.8f BTC, vente %q",
+				stored.AverageDownState, stored.AverageDownCount, stored.Quantity, stored.SellId)
+		}
+		if mock.OrderStatus("5102") != "NEW" {
+			t.Error("la vente d'origine ne devrait pas être annulée")
+		}
+	})
+
+	t.Run("annulé après une exécution partielle", func(t *testing.T) {
+		started := startAverageDownCycle(t, mock, "5103")
+		if err := mock.PartiallyFillOrder(started.AverageDownBuyId, 0.0004); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mock.CancelOrder(started.AverageDownBuyId); err != nil {
+			t.Fatal(err)
+		}
+		mock.SetBalance("BTC", 0.0014)
+
+		stored, _ := repo.FindByIdInt(started.IdInt)
+		processSellCycle(mock, repo, stored, mock.Price)
+
+		stored, _ = repo.FindByIdInt(started.IdInt)
+		if stored.AverageDownCount != 1 || math.Abs(stored.Quantity-0.0014) > 1e-12 {
+			t.Errorf("fusion de la partie exécutée: %d fusion(s), %.8f BTC, attendu 0.0014", stored.AverageDownCount, stored.Quantity)
+		}
+		if mock.OrderStatus("5103") != "CANCELED" || stored.SellId == "5103" {
+			t.Errorf("vente non replacée: %q", stored.SellId)
+		}
+	})
+}
+
+// La vente replacée après une fusion est relevée au profit net minimum comme toute autre vente
+func TestAverageDownMergeMinProfit(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 100, MinProfitUSDC: 5, MinProfitMaxMarkupPercent: 5,
+		APIKey: "key", SecretKey: "secret"}, 60000)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+
+	started := startAverageDownCycle(t, mock, "5201")
+	if err := mock.FillOrder(started.AverageDownBuyId); err != nil {
+		t.Fatal(err)
+	}
+	mock.Fees[started.AverageDownBuyId] = 0.06
+	mock.SetBalance("BTC", 0.002)
+
+	stored, _ := repo.FindByIdInt(started.IdInt)
+	processSellCycle(mock, repo, stored, mock.Price)
+
+	stored, _ = repo.FindByIdInt(started.IdInt)
+	if stored.AverageDownCount != 1 {
+		t.Fatalf("fusion non terminée: étape %q", stored.AverageDownState)
+	}
+	// 0.002 BTC pour 122 USDC et 0.12 USDC de frais: 5 USDC de profit net exigent plus de 63500
+	if stored.SellPrice < 63500 {
+		t.Errorf("vente replacée à %.2f, sous le prix du profit net minimum", stored.SellPrice)
+	}
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// exchangeBalance est le solde consolidé d'un exchange
+type exchangeBalance struct {
+	Exchange   string  `json:"exchange"`
+	BTCPrice   float64 `json:"btcPrice"`
+	BTCFree    float64 `json:"btcFree"`
+	BTCLocked  float64 `json:"btcLocked"`
+	BTCTotal   float64 `json:"btcTotal"`
+	USDCFree   float64 `json:"usdcFree"`
+	USDCLocked float64 `json:"usdcLocked"`
+	USDCTotal  float64 `json:"usdcTotal"`
+	TotalUSDC  float64 `json:"totalUSDC"` // BTC valorisé au prix courant + USDC
+	// Soldes immobilisés (staking, earn), exclus des totaux
+	BTCUnavailable  float64 `json:"btcUnavailable,omitempty"`
+	USDCUnavailable float64 `json:"usdcUnavailable,omitempty"`
+	// Réserve jamais engagée par le bot (<EXCHANGE>_RESERVE_BTC et _USDC), comprise dans les soldes libres
+	BTCReserved  float64 `json:"btcReserved,omitempty"`
+	USDCReserved float64 `json:"usdcReserved,omitempty"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// balanceReport regroupe les soldes de tous les exchanges
+type balanceReport struct {
+	Exchanges  []exchangeBalance `json:"exchanges"`
+	TotalBTC   float64           `json:"totalBTC"`
+	TotalUSDC  float64           `json:"totalUSDC"`
+	GrandTotal float64           `json:"grandTotalUSDC"`
+}
+
+// Balance affiche les soldes de tous les exchanges activés, sans jamais
+// lire ni modifier les cycles. Avec --json, le rapport est imprimé en JSON.
+func Balance() {
+	jsonOutput := false
+	for _, arg := range GetAllArgs() {
+		if arg == "--json" {
+			jsonOutput = true
+		}
+	}
+
+	// Exchanges activés disposant de clés API
+	var exchanges []string
+	for _, name := range []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"} {
+		exchangeConfig, exists := cfg.Exchanges[name]
+		if !exists || !exchangeConfig.Enabled {
+			continue
+		}
+		if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+			continue
+		}
+		exchanges = append(exchanges, name)
+	}
+
+	// Interroger les exchanges en parallèle
+	results := make([]exchangeBalance, len(exchanges))
+	var wg sync.WaitGroup
+	for i, name := range exchanges {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = fetchExchangeBalance(name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	report := balanceReport{Exchanges: results}
+	for _, balance := range results {
+		if balance.Error != "" {
+			continue
+		}
+		report.TotalBTC += balance.BTCTotal
+		report.TotalUSDC += balance.USDCTotal
+		report.GrandTotal += balance.TotalUSDC
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			color.Red("Erreur lors de l'encodage JSON: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printBalanceReport(report)
+}
+
+// fetchExchangeBalance récupère les soldes et le prix du BTC d'un exchange
+func fetchExchangeBalance(name string) (balance exchangeBalance) {
+	balance.Exchange = name
+
+	// Un client défaillant ne doit pas interrompre les autres exchanges
+	defer func() {
+		if r := recover(); r != nil {
+			balance.Error = fmt.Sprint(r)
+		}
+	}()
+
+	client := GetClientByExchange(name)
+
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		balance.Error = err.Error()
+		return balance
+	}
+
+	balance.BTCPrice = client.GetLastPriceBTC()
+	balance.BTCFree = balances["BTC"].Free
+	balance.BTCLocked = balances["BTC"].Locked
+	balance.BTCTotal = balances["BTC"].Total
+	balance.USDCFree = balances["USDC"].Free
+	balance.USDCLocked = balances["USDC"].Locked
+	balance.USDCTotal = balances["USDC"].Total
+	balance.TotalUSDC = balance.BTCTotal*balance.BTCPrice + balance.USDCTotal
+	balance.BTCUnavailable = balances["BTC"].Unavailable
+	balance.USDCUnavailable = balances["USDC"].Unavailable
+	reserve := exchangeReserve(name)
+	balance.BTCReserved = reserve.BTC
+	balance.USDCReserved = reserve.USDC
+
+	return balance
+}
+
+// printBalanceReport affiche le rapport sous forme de tableau
+func printBalanceReport(report balanceReport) {
+	fmt.Println("")
+	color.Cyan("=== Soldes consolidés ===")
+	fmt.Println("")
+
+	if len(report.Exchanges) == 0 {
+		color.Yellow("Aucun exchange activé avec des clés API configurées.")
+		return
+	}
+
+	color.Cyan("%-10s %14s %14s %14s %12s %12s %12s %14s",
+		"Exchange", "BTC libre", "BTC bloqué", "BTC total", "USDC libre", "USDC bloqué", "USDC total", "Total USDC")
+
+	for _, balance := range report.Exchanges {
+		if balance.Error != "" {
+			color.Red("%-10s Erreur: %s", balance.Exchange, balance.Error)
+			continue
+		}
+		color.White("%-10s %14.8f %14.8f %14.8f %12.2f %12.2f %12.2f %14.2f",
+			balance.Exchange,
+			balance.BTCFree, balance.BTCLocked, balance.BTCTotal,
+			balance.USDCFree, balance.USDCLocked, balance.USDCTotal,
+			balance.TotalUSDC)
+		if balance.BTCUnavailable > 0 || balance.USDCUnavailable > 0 {
+			color.Yellow("%-10s immobilisés (staking, earn), non compris: %.8f BTC, %.2f USDC",
+				"", balance.BTCUnavailable, balance.USDCUnavailable)
+		}
+		if balance.BTCReserved > 0 || balance.USDCReserved > 0 {
+			color.Yellow("%-10s réserve jamais engagée, comprise dans les soldes libres: %.8f BTC, %.2f USDC",
+				"", balance.BTCReserved, balance.USDCReserved)
+		}
+	}
+
+	fmt.Println("")
+	color.Green("%-10s %44.8f %38.2f %14.2f", "TOTAL", report.TotalBTC, report.TotalUSDC, report.GrandTotal)
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// breakerStateFile est le fichier où est publié l'état des disjoncteurs après chaque mise à jour
+const breakerStateFile = "circuit_breakers.json"
+
+// Disjoncteurs de l'exécution en cours, remis à zéro à chaque Update()
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*common.CircuitBreaker)
+)
+
+// breakerSnapshot est le contenu publié dans circuit_breakers.json et sur /health
+type breakerSnapshot struct {
+	UpdatedAt time.Time                      `json:"updatedAt"`
+	Exchanges map[string]common.BreakerState `json:"exchanges"`
+}
+
+// resetCircuitBreakers referme tous les disjoncteurs au début d'une exécution
+func resetCircuitBreakers() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers = make(map[string]*common.CircuitBreaker)
+}
+
+// breakerFor retourne le disjoncteur de l'exchange pour l'exécution en cours
+func breakerFor(exchange string) *common.CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	breaker, exists := breakers[exchange]
+	if !exists {
+		threshold := 0
+		if cfg != nil {
+			threshold = cfg.Exchanges[exchange].CircuitBreakerThreshold
+		}
+		breaker = common.NewCircuitBreaker(threshold)
+		breakers[exchange] = breaker
+	}
+	return breaker
+}
+
+// guardedClient retourne le client de l'exchange protégé par son disjoncteur. Pendant une
+// simulation, la création et l'annulation d'ordres sont seulement enregistrées.
+func guardedClient(exchange string) common.Exchange {
+	client := GetClientByExchange(exchange)
+	if client == nil {
+		return nil
+	}
+	guarded := common.NewGuardedExchange(client, breakerFor(exchange))
+	if rec := currentSimulation(); rec != nil {
+		return &simulatedExchange{Exchange: guarded, exchange: exchange, rec: rec}
+	}
+	return guarded
+}
+
+// breakerStatePath retourne le chemin du fichier d'état, à côté de la base de données
+func breakerStatePath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), breakerStateFile)
+}
+
+// saveCircuitBreakers publie l'état des disjoncteurs de l'exécution terminée
+func saveCircuitBreakers() {
+	if simulating() {
+		return
+	}
+
+	breakersMu.Lock()
+	snapshot := breakerSnapshot{
+		UpdatedAt: time.Now(),
+		Exchanges: make(map[string]common.BreakerState, len(breakers)),
+	}
+	for exchange, breaker := range breakers {
+		snapshot.Exchanges[exchange] = breaker.State()
+	}
+	breakersMu.Unlock()
+
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des disjoncteurs: %v", err)
+		return
+	}
+	if err := os.WriteFile(breakerStatePath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", breakerStateFile, err)
+	}
+}
+
+// loadCircuitBreakers lit le dernier état publié des disjoncteurs
+func loadCircuitBreakers() (*breakerSnapshot, error) {
+	content, err := os.ReadFile(breakerStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &breakerSnapshot{Exchanges: map[string]common.BreakerState{}}, nil
+		}
+		return nil, err
+	}
+
+	var snapshot breakerSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("fichier %s invalide: %w", breakerStateFile, err)
+	}
+	return &snapshot, nil
+}
+
+// handleHealth expose l'état des disjoncteurs de la dernière mise à jour, celui des
+// limites de pertes quotidiennes, les exchanges en maintenance et les paires suspendues
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := loadCircuitBreakers()
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'état des disjoncteurs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	lossLimit, err := loadLossLimits()
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'état des limites de pertes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, maintenance, err := activeMaintenance()
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'état des maintenances: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tradingHalts, err := loadTradingHalts()
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'état des suspensions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := "ok"
+	if len(maintenance) > 0 || len(tradingHalts) > 0 {
+		status = "degraded"
+	}
+	for _, state := range snapshot.Exchanges {
+		if state.Open {
+			status = "degraded"
+			break
+		}
+	}
+	// Une limite atteinte ne concerne que la journée UTC où elle a été calculée
+	if lossLimit.Day == utcDay(time.Now()) && !lossLimit.Overridden && len(lossLimit.breachedScopes()) > 0 {
+		status = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          status,
+		"circuitBreakers": snapshot,
+		"lossLimit":       lossLimit,
+		"maintenance":     maintenance,
+		"tradingHalts":    tradingHalts,
+	})
+}
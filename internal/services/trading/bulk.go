@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/i18n"
+	"main/internal/web"
+)
+
+// Actions groupées du tableau de bord (POST /api/cycles/bulk)
+const (
+	bulkActionCancel = "cancel"
+	bulkActionPause  = "pause"
+	bulkActionResume = "resume"
+	bulkActionTag    = "tag"
+)
+
+// Issue d'une action groupée sur un cycle
+const (
+	bulkOutcomeDone    = "done"
+	bulkOutcomeSkipped = "skipped"
+	bulkOutcomeFailed  = "failed"
+)
+
+// bulkResult est le résultat d'une action groupée pour un cycle
+type bulkResult struct {
+	IdInt    int32  `json:"idInt"`
+	Exchange string `json:"exchange"`
+	Status   string `json:"status"`  // statut du cycle après l'action
+	Outcome  string `json:"outcome"` // bulkOutcome*
+	Message  string `json:"message"`
+}
+
+// bulkSummary récapitule une action groupée
+type bulkSummary struct {
+	Action  string       `json:"action"`
+	Tag     string       `json:"tag,omitempty"`
+	Done    int          `json:"done"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Results []bulkResult `json:"results"`
+}
+
+// handleBulkCycles applique une action (cancel, pause, resume ou tag) à une sélection de cycles:
+// les IDs cochés (ids=1&ids=2 ou ids=1,2) ou tous les cycles des filtres du tableau de bord
+// (scope=filter&filter=exchange%3DMEXC%26status%3Dbuy). Chaque cycle passe par la même logique
+// que l'action unitaire; le récapitulatif est rendu en HTML, ou en JSON si le client l'accepte.
+func handleBulkCycles(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeBulkError(w, r, http.StatusBadRequest, "Formulaire invalide: "+err.Error())
+		return
+	}
+
+	action := r.PostFormValue("action")
+	tag := strings.TrimSpace(r.PostFormValue("tag"))
+	switch action {
+	case bulkActionCancel, bulkActionPause, bulkActionResume:
+	case bulkActionTag:
+		if tag == "" {
+			writeBulkError(w, r, http.StatusBadRequest, "Étiquette vide: renseignez le champ tag")
+			return
+		}
+	default:
+		writeBulkError(w, r, http.StatusBadRequest, fmt.Sprintf("action invalide: %q (attendu: %s, %s, %s ou %s)",
+			action, bulkActionCancel, bulkActionPause, bulkActionResume, bulkActionTag))
+		return
+	}
+
+	cycles, code, err := bulkSelection(r.PostForm)
+	if err != nil {
+		writeBulkError(w, r, code, err.Error())
+		return
+	}
+
+	summary := runBulkAction(cycles, action, tag)
+	if wantsJSON(r) {
+		writeSchedulerJSON(w, http.StatusOK, summary)
+		return
+	}
+	renderTemplate(w, web.BulkTemplate, map[string]interface{}{
+		"summary":     summary,
+		"currentTime": time.Now().Format("02/01/2006 15:04:05"),
+	})
+}
+
+// bulkSelection retourne les cycles visés par le formulaire, par ID croissant
+func bulkSelection(form url.Values) ([]*database.Cycle, int, error) {
+	if form.Get("scope") == "filter" {
+		filter, err := url.ParseQuery(form.Get("filter"))
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("filtre invalide: %w", err)
+		}
+		cycles, err := filterDashboardCycles(filter)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		sort.Slice(cycles, func(i, j int) bool { return cycles[i].IdInt < cycles[j].IdInt })
+		return cycles, http.StatusOK, nil
+	}
+
+	var ids []int32
+	for _, value := range form["ids"] {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			id, err := strconv.Atoi(field)
+			if err != nil || id <= 0 {
+				return nil, http.StatusBadRequest, fmt.Errorf("ID de cycle invalide: %s", field)
+			}
+			if !slices.Contains(ids, int32(id)) {
+				ids = append(ids, int32(id))
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("aucun cycle sélectionné: cochez des cycles ou choisissez tous les cycles filtrés")
+	}
+	slices.Sort(ids)
+
+	repo := database.GetRepository()
+	var cycles []*database.Cycle
+	for _, id := range ids {
+		cycle, err := repo.FindByIdInt(id)
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("erreur lors de la récupération du cycle %d: %w", id, err)
+		}
+		if cycle == nil {
+			return nil, http.StatusNotFound, fmt.Errorf("cycle avec ID %d introuvable", id)
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles, http.StatusOK, nil
+}
+
+// runBulkAction applique l'action à chaque cycle; l'échec d'un cycle n'interrompt pas les suivants
+func runBulkAction(cycles []*database.Cycle, action, tag string) bulkSummary {
+	summary := bulkSummary{Action: action, Tag: tag}
+	for _, cycle := range cycles {
+		result := bulkResult{IdInt: cycle.IdInt, Exchange: cycle.Exchange, Outcome: bulkOutcomeDone}
+		var skip string
+		var err error
+		switch action {
+		case bulkActionCancel:
+			skip, err = bulkCancel(cycle)
+		case bulkActionPause, bulkActionResume:
+			skip, err = bulkSetPaused(cycle, action == bulkActionPause)
+		case bulkActionTag:
+			skip, err = bulkTag(cycle, tag)
+		}
+
+		switch {
+		case err != nil:
+			result.Outcome, result.Message = bulkOutcomeFailed, err.Error()
+			summary.Failed++
+		case skip != "":
+			result.Outcome, result.Message = bulkOutcomeSkipped, skip
+			summary.Skipped++
+		default:
+			summary.Done++
+		}
+		result.Status = cycle.Status
+		summary.Results = append(summary.Results, result)
+	}
+	return summary
+}
+
+// bulkCancel annule l'ordre ouvert du cycle puis le marque annulé (cause manual), comme -c=ID.
+// Contrairement à la ligne de commande, un cycle sans ordre ouvert n'est jamais supprimé et un
+// échec de l'annulation de l'ordre laisse le cycle en l'état.
+func bulkCancel(cycle *database.Cycle) (string, error) {
+	orderId := cycle.BuyId
+	switch cycle.Status {
+	case "buy", database.StatusCancelPending:
+	case "sell":
+		orderId = cycle.SellId
+	default:
+		return fmt.Sprintf(i18n.T("dash.bulk_skip_no_order"), cycle.Status), nil
+	}
+
+	// Ne pas laisser GetClientByExchange arrêter le processus (serveur web)
+	exchangeConfig := cfg.Exchanges[cycle.Exchange]
+	if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+		return "", fmt.Errorf("clés API %s non configurées", cycle.Exchange)
+	}
+	cleanId := cleanOrderId(orderId, cycle.Exchange)
+	if cleanId == "" {
+		return "", fmt.Errorf("ID d'ordre invalide: %s", orderId)
+	}
+
+	result, err := safeOrderCancel(GetClientByExchange(cycle.Exchange), cleanId, cycle.IdInt)
+	if !result.Closed() {
+		return "", fmt.Errorf("échec de l'annulation de l'ordre %s: %v", cleanId, err)
+	}
+	if err := markCycleCancelled(database.GetRepository(), cycle, database.CancelReasonManual); err != nil {
+		return "", fmt.Errorf("erreur lors de la mise à jour du cycle: %w", err)
+	}
+	cycleEvent(cycle, "cancel").with("bulk", true).notify(cycle, "Cycle %d annulé manuellement (action groupée)", cycle.IdInt)
+	return "", nil
+}
+
+// bulkSetPaused met en pause ou reprend le cycle; les cycles sans ordre ouvert ou déjà dans
+// l'état demandé sont ignorés plutôt qu'en échec
+func bulkSetPaused(cycle *database.Cycle, paused bool) (string, error) {
+	if paused && cycle.Status != "buy" && cycle.Status != "sell" {
+		return fmt.Sprintf(i18n.T("dash.bulk_skip_pause_status"), cycle.Status), nil
+	}
+	if cycle.Paused == paused {
+		if paused {
+			return i18n.T("dash.bulk_skip_already_paused"), nil
+		}
+		return i18n.T("dash.bulk_skip_not_paused"), nil
+	}
+
+	updated, err := setCyclePaused(cycle.IdInt, paused)
+	if err != nil {
+		return "", err
+	}
+	cycle.Paused = updated.Paused
+	return "", nil
+}
+
+// bulkTag ajoute une étiquette au cycle
+func bulkTag(cycle *database.Cycle, tag string) (string, error) {
+	if slices.Contains(cycle.Tags, tag) {
+		return i18n.T("dash.bulk_skip_tag_present"), nil
+	}
+	tags := append(slices.Clone(cycle.Tags), tag)
+	if err := database.GetRepository().UpdateByIdInt(cycle.IdInt, map[string]interface{}{"tags": tags}); err != nil {
+		return "", fmt.Errorf("erreur lors de la mise à jour du cycle: %w", err)
+	}
+	cycle.Tags = tags
+	return "", nil
+}
+
+// wantsJSON indique si le client attend une réponse JSON plutôt qu'une page HTML
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// writeBulkError renvoie l'erreur d'une action groupée au format attendu par le client
+func writeBulkError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	if wantsJSON(r) {
+		writeSchedulerError(w, code, message)
+		return
+	}
+	http.Error(w, message, code)
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+// postBulk envoie le formulaire à /api/cycles/bulk et décode le récapitulatif JSON
+func postBulk(t *testing.T, form url.Values) bulkSummary {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/cycles/bulk", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	handleBulkCycles(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("action %s: code %d (%s)", form.Get("action"), rec.Code, rec.Body.String())
+	}
+	var summary bulkSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("récapitulatif illisible: %v", err)
+	}
+	return summary
+}
+
+func TestBulkCycles(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, APIKey: "key", SecretKey: "secret"}, 60100)
+	repo := database.GetRepository()
+
+	old := saveBuyCycle(t, mock, 60000, 0.001)
+	recent := saveBuyCycle(t, mock, 59000, 0.001)
+	completed := &database.Cycle{Exchange: "BINANCE", Status: "completed", Quantity: 0.001, BuyId: "8801", SellId: "8802"}
+	if _, err := repo.Save(completed); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(completed.IdInt) })
+	createdAt := time.Now().AddDate(0, 0, -30).Format(time.RFC3339)
+	if err := repo.UpdateByIdInt(old.IdInt, map[string]interface{}{"createdAt": createdAt}); err != nil {
+		t.Fatalf("vieillissement du cycle: %v", err)
+	}
+
+	// Pause des cycles cochés: le cycle complété est ignoré sans faire échouer les autres
+	ids := []string{itoa(old.IdInt) + "," + itoa(completed.IdInt), itoa(recent.IdInt)}
+	summary := postBulk(t, url.Values{"action": {"pause"}, "ids": ids})
+	if summary.Done != 2 || summary.Skipped != 1 || summary.Failed != 0 || len(summary.Results) != 3 {
+		t.Fatalf("pause groupée: %+v", summary)
+	}
+	if stored, _ := repo.FindByIdInt(recent.IdInt); !stored.Paused {
+		t.Errorf("cycle %d non mis en pause", recent.IdInt)
+	}
+
+	// Étiquette: ajoutée une seule fois
+	postBulk(t, url.Values{"action": {"tag"}, "tag": {"vieux"}, "ids": {itoa(old.IdInt)}})
+	summary = postBulk(t, url.Values{"action": {"tag"}, "tag": {"vieux"}, "ids": {itoa(old.IdInt)}})
+	if summary.Skipped != 1 {
+		t.Errorf("étiquette posée deux fois: %+v", summary)
+	}
+	if stored, _ := repo.FindByIdInt(old.IdInt); len(stored.Tags) != 1 || stored.Tags[0] != "vieux" {
+		t.Errorf("étiquettes %v, attendu [vieux]", stored.Tags)
+	}
+
+	// Annulation de tous les cycles filtrés: seul l'achat de plus de 20 jours est visé
+	filter := url.Values{"exchange": {"BINANCE"}, "status": {"buy"}, "min_age": {"20"}}.Encode()
+	summary = postBulk(t, url.Values{"action": {"cancel"}, "scope": {"filter"}, "filter": {filter}})
+	if summary.Done != 1 || len(summary.Results) != 1 || summary.Results[0].IdInt != old.IdInt {
+		t.Fatalf("annulation des cycles filtrés: %+v", summary)
+	}
+	if status := mock.OrderStatus(old.BuyId); status != "CANCELED" {
+		t.Errorf("ordre d'achat %s, attendu CANCELED", status)
+	}
+	stored, _ := repo.FindByIdInt(old.IdInt)
+	if stored.Status != "cancelled" || stored.CancelReason != database.CancelReasonManual {
+		t.Errorf("cycle %q (%q), attendu cancelled (manual)", stored.Status, stored.CancelReason)
+	}
+	if stored, _ := repo.FindByIdInt(recent.IdInt); stored.Status != "buy" {
+		t.Errorf("cycle récent %q, il ne correspondait pas au filtre", stored.Status)
+	}
+
+	// Action inconnue ou sélection vide: refusées
+	for _, form := range []url.Values{
+		{"action": {"delete"}, "ids": {itoa(old.IdInt)}},
+		{"action": {"pause"}},
+		{"action": {"tag"}, "ids": {itoa(old.IdInt)}},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/cycles/bulk", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleBulkCycles(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: code %d, attendu 400", form, rec.Code)
+		}
+	}
+}
+
+// itoa formate un ID de cycle pour un champ de formulaire
+func itoa(id int32) string {
+	return strconv.Itoa(int(id))
+}
@@ -3,9 +3,11 @@ package commands
 import (
 	"fmt"
 	"main/internal/database"
+	"main/internal/exchanges/common"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -28,6 +30,12 @@ func Cancel(cancelArg string) {
 		os.Exit(1)
 	}
 
+	// -c=group:ID annule toutes les tranches restantes d'un achat échelonné
+	if strings.HasPrefix(idStr, "group:") {
+		cancelGroup("", idStr)
+		return
+	}
+
 	// Convertir l'ID en nombre entier
 	idInt, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -56,10 +64,10 @@ func Cancel(cancelArg string) {
 	// Obtenir le client de l'échange approprié pour ce cycle
 	client := GetClientByExchange(cycle.Exchange)
 
-	// Annuler l'ordre uniquement si le statut est "buy" ou "sell"
-	if status == "buy" || status == "sell" {
+	// Annuler l'ordre uniquement si le statut est "buy", "cancel_pending" ou "sell"
+	if status == "buy" || status == database.StatusCancelPending || status == "sell" {
 		var orderIdToCancel string
-		if status == "buy" {
+		if status != "sell" {
 			orderIdToCancel = cycle.BuyId
 			color.Yellow("Annulation de l'ordre d'achat %s", orderIdToCancel)
 		} else {
@@ -73,28 +81,39 @@ func Cancel(cancelArg string) {
 			color.Red("ID d'ordre invalide: %s", orderIdToCancel)
 		} else {
 			// Annuler l'ordre avec la fonction sécurisée
-			success, err := safeOrderCancel(client, cleanOrderId, cycle.IdInt)
+			result, err := safeOrderCancel(client, cleanOrderId, cycle.IdInt)
 
-			if !success && err != nil {
+			if !result.Closed() {
 				color.Red("Échec de l'annulation de l'ordre: %v", err)
 				// Demander confirmation pour continuer malgré l'erreur
-				color.Yellow("Voulez-vous quand même supprimer le cycle de la base de données? (o/n): ")
+				color.Yellow("Voulez-vous quand même marquer le cycle comme annulé? (o/n): ")
 				var response string
 				fmt.Scanln(&response)
 				if strings.ToLower(response) != "o" && strings.ToLower(response) != "oui" {
 					color.Red("Annulation abandonnée.")
 					os.Exit(1)
 				}
+			} else if result == common.AlreadyGone {
+				color.Yellow("L'ordre n'était plus ouvert sur l'exchange (déjà exécuté ou annulé)")
 			} else {
 				color.Green("Ordre annulé avec succès!")
 			}
 		}
-	} else {
-		color.Yellow("Le cycle a le statut '%s', aucun ordre à annuler, suppression de la base de données uniquement", status)
+
+		// Conserver le cycle avec la cause de l'annulation, même si l'annulation de l'ordre
+		// a échoué mais que l'utilisateur a confirmé
+		if err := markCycleCancelled(repo, cycle, database.CancelReasonManual); err != nil {
+			color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Cycle %d marqué comme annulé", idInt)
+		cycleEvent(cycle, "cancel").notify(cycle, "Cycle %d annulé manuellement", idInt)
+		return
 	}
 
-	// Supprimer le cycle de la base de données, même si l'annulation de l'ordre a échoué
-	// mais que l'utilisateur a confirmé la suppression
+	color.Yellow("Le cycle a le statut '%s', aucun ordre à annuler, suppression de la base de données uniquement", status)
+
+	// Supprimer le cycle de la base de données
 	err = repo.DeleteByIdInt(int32(idInt))
 	if err != nil {
 		color.Red("Erreur lors de la suppression du cycle: %v", err)
@@ -102,3 +121,81 @@ func Cancel(cancelArg string) {
 	}
 	color.Green("Cycle %d supprimé avec succès", idInt)
 }
+
+// cancelGroup annule les tranches encore en attente d'achat d'un achat échelonné (-c=group:ID).
+// Les tranches déjà achetées poursuivent leur cycle jusqu'à la vente.
+func cancelGroup(exchange string, groupArg string) {
+	groupStr := strings.TrimPrefix(groupArg, "group:")
+	groupId, err := strconv.Atoi(groupStr)
+	if err != nil || groupId <= 0 {
+		color.Red("ID de groupe invalide: %s", groupStr)
+		os.Exit(1)
+	}
+
+	repo := database.GetRepository()
+	cycles, err := repo.FindByGroup(int32(groupId))
+	if err != nil {
+		color.Red("Erreur lors de la récupération du groupe: %v", err)
+		os.Exit(1)
+	}
+	if len(cycles) == 0 {
+		color.Red("Groupe %d introuvable", groupId)
+		os.Exit(1)
+	}
+	if exchange != "" && cycles[0].Exchange != exchange {
+		color.Red("Le groupe %d appartient à l'exchange %s, pas à %s", groupId, cycles[0].Exchange, exchange)
+		os.Exit(1)
+	}
+
+	color.White("Annulation des tranches du groupe %d sur %s...", groupId, cycles[0].Exchange)
+	client := GetClientByExchange(cycles[0].Exchange)
+
+	countCancelled := 0
+	countFailed := 0
+	for _, cycle := range cycles {
+		if cycle.Status != "buy" {
+			color.White("Tranche %d au statut '%s', conservée", cycle.IdInt, cycle.Status)
+			continue
+		}
+
+		cleanOrderId := cleanOrderId(cycle.BuyId, cycle.Exchange)
+		if cleanOrderId == "" {
+			color.Red("ID d'ordre invalide pour la tranche %d: %s", cycle.IdInt, cycle.BuyId)
+			countFailed++
+			continue
+		}
+		result, err := safeOrderCancel(client, cleanOrderId, cycle.IdInt)
+		if !result.Closed() {
+			color.Red("Échec de l'annulation de l'ordre d'achat de la tranche %d: %v", cycle.IdInt, err)
+			countFailed++
+			continue
+		}
+
+		if err := markCycleCancelled(repo, cycle, database.CancelReasonManual); err != nil {
+			color.Red("Erreur lors de la mise à jour de la tranche %d: %v", cycle.IdInt, err)
+			countFailed++
+			continue
+		}
+		color.Green("Tranche %d annulée", cycle.IdInt)
+		cycleEvent(cycle, "cancel").with("group_id", groupId).notify(cycle, "Cycle %d annulé manuellement (groupe %d)", cycle.IdInt, groupId)
+		countCancelled++
+	}
+
+	fmt.Println("")
+	color.Cyan("Groupe %d: %d tranche(s) annulée(s)", groupId, countCancelled)
+	if countFailed > 0 {
+		color.Red("  %d tranche(s) n'ont pas pu être annulée(s)", countFailed)
+	}
+}
+
+// markCycleCancelled passe le cycle au statut cancelled avec la cause indiquée, en base et en
+// mémoire. Un cycle déjà complété ou annulé est refusé (database.ErrInvalidTransition).
+func markCycleCancelled(repo *database.CycleRepository, cycle *database.Cycle, reason string) error {
+	if err := repo.MarkCancelled(cycle.IdInt, reason); err != nil {
+		return err
+	}
+	cycle.Status = database.StatusCancelled
+	cycle.CancelReason = reason
+	cycle.CancelledAt = time.Now()
+	return nil
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+	"main/internal/types"
+
+	"github.com/fatih/color"
+)
+
+// staleBuyResult est l'issue de l'annulation d'un achat trop ancien
+type staleBuyResult int
+
+const (
+	staleBuyCancelled staleBuyResult = iota // Achat annulé, cycle marqué annulé
+	staleBuyFailed                          // Annulation à retenter (ordre encore ouvert, base non mise à jour)
+	staleBuyFilled                          // Achat exécuté avant l'annulation: le cycle suit son cours
+)
+
+// cancelStaleBuy annule l'achat d'un cycle qui a dépassé maxDays jours, à la mise à jour
+// (<EXCHANGE>_BUY_MAX_DAYS) comme par la tâche planifiée "cancel_stale"
+func cancelStaleBuy(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent, cleanBuyId string, maxDays int) staleBuyResult {
+	ev = ev.with("action", "cancel_buy_age")
+	ev.warn(i18n.T("update.buy_too_old"), cycle.IdInt, maxDays, cycle.GetAge())
+
+	// Annuler l'ordre avec la fonction sécurisée
+	result, err := safeOrderCancel(client, cleanBuyId, cycle.IdInt)
+
+	if result == common.AlreadyGone && orderFilled(client, cleanBuyId) {
+		// L'achat a été exécuté avant l'annulation: poursuivre le traitement normal du cycle
+		ev.warn(i18n.T("update.buy_filled_before_cancel"), cycle.IdInt)
+		return staleBuyFilled
+	}
+
+	// L'ordre peut encore s'exécuter: l'annulation sera retentée aux prochaines mises à jour
+	if !result.Closed() {
+		ev.with("error", err).fail(i18n.T("update.cancel_age_error"), err)
+		recordCancelFailure(repo, cycle, ev, database.CancelReasonMaxAge, err)
+		return staleBuyFailed
+	}
+
+	// Un achat expiré par l'exchange (USE_EXCHANGE_EXPIRY) garde sa propre cause
+	reason := database.CancelReasonMaxAge
+	if result == common.AlreadyGone && orderExpired(client, cleanBuyId) {
+		reason = database.CancelReasonExpired
+	}
+	if err := markCycleCancelled(repo, cycle, reason); err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return staleBuyFailed
+	}
+	ev.success(i18n.T("update.buy_cancelled_age"), cycle.IdInt)
+	ev.notify(cycle, "Cycle %d: ordre d'achat annulé (âge maximal de %d jours dépassé)", cycle.IdInt, maxDays)
+	return staleBuyCancelled
+}
+
+// CancelStale annule les achats non exécutés depuis au moins --max-age-days jours, sur tous les
+// exchanges activés ou sur celui indiqué: --cancel-stale --max-age-days=7 [-exchangeXXX]. Le
+// seuil est indépendant de <EXCHANGE>_BUY_MAX_DAYS appliqué par la mise à jour. Les cycles en
+// pause et les exchanges dont la paire est suspendue ne sont pas touchés.
+func CancelStale(exchange string) {
+	maxDays := 0
+	for _, arg := range GetAllArgs() {
+		if value, ok := strings.CutPrefix(arg, "--max-age-days="); ok {
+			days, err := strconv.Atoi(value)
+			if err != nil || days <= 0 {
+				color.Red("Âge invalide: %s. Utilisez --max-age-days=NOMBRE_DE_JOURS", value)
+				os.Exit(1)
+			}
+			maxDays = days
+		}
+	}
+	if maxDays == 0 {
+		color.Red("Âge manquant. Utilisez --cancel-stale --max-age-days=7")
+		os.Exit(1)
+	}
+
+	repo := database.GetRepository()
+	cycles, err := repo.FindByStatus("buy")
+	if err != nil {
+		color.Red("Erreur lors de la récupération des cycles: %v", err)
+		os.Exit(1)
+	}
+
+	// Exchanges écartés (désactivés, paire suspendue), évalués une seule fois chacun
+	excluded := make(map[string]bool)
+	cancelled, failed := 0, 0
+	for _, cycle := range cycles {
+		if exchange != "" && !strings.EqualFold(cycle.Exchange, exchange) {
+			continue
+		}
+		if cycle.Paused || cycle.GetAge() < float64(maxDays) {
+			continue
+		}
+		skip, known := excluded[cycle.Exchange]
+		if !known {
+			exchangeConfig, exists := cfg.Exchanges[cycle.Exchange]
+			_, halted := checkTradingHalt(cycle.Exchange)
+			skip = !exists || !exchangeConfig.Enabled || halted
+			if halted {
+				color.Yellow("%s: paire suspendue, achats laissés en l'état", cycle.Exchange)
+			}
+			excluded[cycle.Exchange] = skip
+		}
+		if skip {
+			continue
+		}
+
+		cleanBuyId := cleanOrderId(cycle.BuyId, cycle.Exchange)
+		if cleanBuyId == "" {
+			color.Red("Cycle %d: ID d'ordre d'achat invalide (%s)", cycle.IdInt, cycle.BuyId)
+			failed++
+			continue
+		}
+
+		ev := cycleEvent(cycle, "cancel_stale").with("order_id", cycle.BuyId)
+		switch cancelStaleBuy(GetClientByExchange(cycle.Exchange), repo, cycle, ev, cleanBuyId, maxDays) {
+		case staleBuyCancelled:
+			cancelled++
+			// Signaler le cycle annulé à l'historique du planificateur
+			if os.Getenv(types.TaskRunEnv) != "" {
+				fmt.Printf("%s%d\n", types.CancelledCycleMarker, cycle.IdInt)
+			}
+		case staleBuyFailed:
+			failed++
+		}
+	}
+
+	if cancelled == 0 && failed == 0 {
+		color.Green("Aucun achat de plus de %d jours à annuler", maxDays)
+		return
+	}
+	color.Green("%d achat(s) de plus de %d jours annulé(s)", cancelled, maxDays)
+	if failed > 0 {
+		color.Red("%d annulation(s) en échec, à retenter", failed)
+		database.CloseDatabase()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestCancelStaleUsesOwnThreshold(t *testing.T) {
+	t.Setenv(config.DirEnv, t.TempDir())
+	// BUY_MAX_DAYS=10: la mise à jour garderait ces achats, la tâche applique son propre seuil
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, BuyMaxDays: 10}, 60100)
+	repo := database.GetRepository()
+
+	saveAgedBuy := func(days int, paused bool) *database.Cycle {
+		buyOrderSeq++
+		cycle := &database.Cycle{
+			Exchange:  "BINANCE",
+			Status:    "buy",
+			Quantity:  0.0015,
+			BuyPrice:  60000,
+			BuyId:     mock.AddOrder(strconv.Itoa(buyOrderSeq), "BUY", 60000, 0.0015),
+			SellPrice: 61200,
+			Paused:    paused,
+			CreatedAt: time.Now().Add(-time.Duration(days) * 24 * time.Hour),
+		}
+		if _, err := repo.Save(cycle); err != nil {
+			t.Fatalf("enregistrement du cycle: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+		return cycle
+	}
+	stale := saveAgedBuy(6, false)
+	recent := saveAgedBuy(2, false)
+	paused := saveAgedBuy(8, true)
+
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = []string{"bot", "--cancel-stale", "--max-age-days=5"}
+	CancelStale("BINANCE")
+
+	for _, want := range []struct {
+		cycle  *database.Cycle
+		status string
+	}{{stale, "cancelled"}, {recent, "buy"}, {paused, "buy"}} {
+		stored, err := repo.FindByIdInt(want.cycle.IdInt)
+		if err != nil {
+			t.Fatalf("lecture du cycle: %v", err)
+		}
+		if stored.Status != want.status {
+			t.Errorf("cycle de %.0f jours (pause %v): statut %q, attendu %q", want.cycle.GetAge(), want.cycle.Paused, stored.Status, want.status)
+		}
+	}
+	if stored, _ := repo.FindByIdInt(stale.IdInt); stored.CancelReason != database.CancelReasonMaxAge {
+		t.Errorf("cause d'annulation %q, attendu %q", stored.CancelReason, database.CancelReasonMaxAge)
+	}
+	if calls := mock.CallsTo("CancelOrderIdempotent"); len(calls) != 1 {
+		t.Errorf("annulations envoyées: %+v, attendu une seule", calls)
+	}
+}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// closeNowFillTimeout est le délai d'attente de l'exécution de la vente au marché. Au-delà,
+// l'ordre reste suivi par le cycle et la mise à jour suivante termine le cycle.
+var closeNowFillTimeout = 30 * time.Second
+
+// closeEstimate est le résultat estimé d'une clôture au marché, présenté avant confirmation
+type closeEstimate struct {
+	Cycle     *database.Cycle
+	Price     float64 // dernier prix BTC de l'exchange
+	Quantity  float64 // quantité suivie par le cycle
+	BuyAmount float64
+	SellFees  float64 // frais de vente estimés au taux taker
+	Profit    float64
+	Percent   float64
+}
+
+// CloseNow vend immédiatement un cycle au marché: --close-now --id=123 [--yes]. L'ordre de
+// vente ouvert est annulé puis la quantité du cycle vendue au marché; sans --yes, le profit
+// estimé est affiché et une confirmation demandée.
+func CloseNow() {
+	var idStr string
+	skipConfirm := false
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--id="):
+			idStr = strings.TrimPrefix(arg, "--id=")
+		case arg == "--yes":
+			skipConfirm = true
+		}
+	}
+
+	if idStr == "" {
+		color.Red(i18n.T("close_now.usage"))
+		os.Exit(1)
+	}
+	idInt, err := strconv.Atoi(idStr)
+	if err != nil {
+		color.Red(i18n.T("close_now.invalid_id"), idStr)
+		os.Exit(1)
+	}
+
+	confirm := confirmCloseNow
+	if skipConfirm {
+		confirm = nil
+	}
+	cycle, err := closeCycleNow(int32(idInt), confirm)
+	if err == errCloseAborted {
+		color.Yellow(i18n.T("close_now.aborted"))
+		return
+	}
+	if err != nil {
+		color.Red(i18n.T("close_now.failed"), err)
+		os.Exit(1)
+	}
+
+	color.Green(i18n.T("close_now.completed"),
+		cycle.IdInt, cycle.Quantity, cycle.SellFillPrice, cycle.SellFees)
+}
+
+// errCloseAborted signale une clôture refusée à la confirmation
+var errCloseAborted = fmt.Errorf("clôture annulée par l'utilisateur")
+
+// confirmCloseNow affiche le résultat estimé de la clôture et demande confirmation
+func confirmCloseNow(estimate closeEstimate) bool {
+	color.Cyan(i18n.T("close_now.estimate_header"), estimate.Cycle.IdInt, estimate.Cycle.Exchange, estimate.Quantity)
+	fmt.Printf(i18n.T("close_now.estimate_price"), estimate.Price, estimate.Cycle.EffectiveBuyPrice())
+	fmt.Printf(i18n.T("close_now.estimate_buy_amount"), estimate.BuyAmount)
+	fmt.Printf(i18n.T("close_now.estimate_fees"), estimate.Cycle.BuyFees+estimate.SellFees, estimate.SellFees)
+	profitColor := color.GreenString
+	if estimate.Profit < 0 {
+		profitColor = color.RedString
+	}
+	fmt.Printf(i18n.T("close_now.estimate_profit"), profitColor("%.2f USDC (%.2f%%)", estimate.Profit, estimate.Percent))
+	fmt.Print(i18n.T("close_now.confirm"))
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "o" || answer == "oui" || answer == "y" || answer == "yes"
+}
+
+// estimateClose calcule le profit net d'une vente au marché de la quantité du cycle au prix
+// donné, frais d'achat enregistrés et frais de vente au taux taker compris
+func estimateClose(cycle *database.Cycle, price, takerRate float64) closeEstimate {
+	buyAmount := cycle.PurchaseAmountUSDC
+	if buyAmount <= 0 {
+		buyAmount = cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+	sellAmount := price * cycle.Quantity
+	sellFees := sellAmount * takerRate
+
+	estimate := closeEstimate{
+		Cycle:     cycle,
+		Price:     price,
+		Quantity:  cycle.Quantity,
+		BuyAmount: buyAmount,
+		SellFees:  sellFees,
+		Profit:    sellAmount - buyAmount - cycle.BuyFees - sellFees,
+	}
+	if buyAmount > 0 {
+		estimate.Percent = estimate.Profit / buyAmount * 100
+	}
+	return estimate
+}
+
+// closeCycleNow annule la vente ouverte d'un cycle et vend sa quantité au marché, puis complète
+// le cycle avec le prix et les frais réels de l'exécution. confirm reçoit le résultat estimé
+// avant toute action; nil vaut confirmation (--yes). Le cycle complété est retourné.
+func closeCycleNow(idInt int32, confirm func(closeEstimate) bool) (*database.Cycle, error) {
+	repo := database.GetRepository()
+	cycle, err := repo.FindByIdInt(idInt)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération du cycle: %w", err)
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("cycle avec ID %d introuvable", idInt)
+	}
+	if cycle.Status != "sell" {
+		return nil, fmt.Errorf("le cycle %d a le statut '%s', seul un cycle en vente peut être clôturé", idInt, cycle.Status)
+	}
+	if cycle.AverageDownState != "" {
+		return nil, fmt.Errorf("une moyenne à la baisse est en cours sur le cycle %d (étape %s)", idInt, cycle.AverageDownState)
+	}
+
+	exchangeConfig := cfg.Exchanges[cycle.Exchange]
+	if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+		return nil, fmt.Errorf("clés API %s non configurées", cycle.Exchange)
+	}
+	client := GetClientByExchange(cycle.Exchange)
+
+	price := client.GetLastPriceBTC()
+	if price <= 0 {
+		return nil, fmt.Errorf("prix BTC indisponible sur %s", cycle.Exchange)
+	}
+	takerRate := exchangeFeeRates(cycle.Exchange).Taker
+	if confirm != nil && !confirm(estimateClose(cycle, price, takerRate)) {
+		return nil, errCloseAborted
+	}
+
+	ev := cycleEvent(cycle, "close_now").with("price", price)
+
+	// Annuler la vente limite et, pour un OCO, le stop de protection
+	for _, orderId := range []string{cycle.SellId, cycle.StopId} {
+		if orderId == "" {
+			continue
+		}
+		cleanId := cleanOrderId(orderId, cycle.Exchange)
+		result, err := safeOrderCancel(client, cleanId, cycle.IdInt)
+		switch {
+		case result == common.CancelFailed:
+			return nil, fmt.Errorf("échec de l'annulation de l'ordre %s: %v", orderId, err)
+		case result == common.AlreadyGone && orderFilled(client, cleanId):
+			return nil, fmt.Errorf("l'ordre %s est déjà exécuté: lancez une mise à jour pour compléter le cycle", orderId)
+		}
+		ev.with("order_id", orderId).info(i18n.T("close_now.order_cancelled"), cycle.IdInt, orderId)
+	}
+
+	availableBTC, err := waitForFreeBTC(client, cycle.Exchange, cycle.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("%v; la vente annulée n'a pas été remplacée, lancez une mise à jour pour la replacer", err)
+	}
+	quantityStr := client.FormatQuantity(math.Min(cycle.Quantity, availableBTC))
+
+	clientOrderID := common.ClientOrderID(cycle.IdInt, "close")
+	created, err := client.CreateOrder("SELL", client.FormatPrice(price), quantityStr,
+		common.OrderOptions{Market: true, ClientOrderID: clientOrderID})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la création de la vente au marché (la vente annulée sera replacée à la mise à jour): %w", err)
+	}
+	orderId := created.OrderID
+	ev = ev.with("order_id", orderId)
+
+	// La vente au marché devient la vente suivie du cycle: si son exécution n'est pas constatée
+	// ici, la mise à jour complète le cycle comme pour une vente limite
+	err = saveReplacedSell(repo, cycle, database.SellOrder{OrderId: orderId, ClientOrderId: clientOrderID, Price: price})
+	if err != nil {
+		return nil, fmt.Errorf("vente au marché %s placée mais erreur lors de la mise à jour du cycle: %w", orderId, err)
+	}
+	cycle.SellId, cycle.SellClientOrderId, cycle.StopId, cycle.StopPrice, cycle.SellPrice = orderId, clientOrderID, "", 0, price
+	ev.info(i18n.T("close_now.sell_placed"), cycle.IdInt, orderId, quantityStr)
+
+	status, err := waitForMarketFill(client, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("%v; le cycle sera complété par la mise à jour suivante", err)
+	}
+	captureOrderSnapshot(client, repo, cycle, ev, database.OrderSnapshotSell, orderId)
+
+	// Quantité et prix réellement exécutés
+	quantity, _ := strconv.ParseFloat(quantityStr, 64)
+	if status.ExecutedQty > 0 {
+		quantity = status.ExecutedQty
+	}
+	fillPrice := price
+	if status.AvgFillPrice > 0 {
+		fillPrice = status.AvgFillPrice
+	}
+	sellAmount := filledAmount(status, fillPrice, quantity)
+
+	sellFees, err := client.GetOrderFees(orderId)
+	if err != nil && status.Fee != 0 {
+		sellFees = status.Fee
+	} else if err != nil {
+		sellFees = sellAmount * takerRate
+		cycle.FeesEstimated = true
+		ev.warn(i18n.T("close_now.fees_estimated"), cycle.IdInt, sellFees, takerRate*100)
+	}
+
+	buyAmount := cycle.PurchaseAmountUSDC
+	if buyAmount <= 0 {
+		buyAmount = cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+	totalFees := cycle.BuyFees + sellFees
+	profit := sellAmount - buyAmount - totalFees
+	profitPercent := 0.0
+	if buyAmount > 0 {
+		profitPercent = profit / buyAmount * 100
+	}
+
+	completionTime := time.Now()
+	err = repo.MarkCompleted(cycle.IdInt, database.Completion{
+		CompletedAt:        completionTime,
+		SellFees:           sellFees,
+		TotalFees:          totalFees,
+		FeesEstimated:      cycle.FeesEstimated,
+		SellFillPrice:      fillPrice,
+		PurchaseAmountUSDC: buyAmount,
+		SaleAmountUSDC:     sellAmount,
+		SellFeeAsset:       status.FeeAsset,
+		SellFeeAssetAmount: status.FeeAssetAmount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vente au marché %s exécutée mais erreur lors de la mise à jour du cycle: %w", orderId, err)
+	}
+
+	cycle.Status = "completed"
+	cycle.CompletedAt = completionTime
+	cycle.ObservedCompletedAt = completionTime
+	cycle.SellFees = sellFees
+	cycle.TotalFees = totalFees
+	cycle.SellFillPrice = fillPrice
+	cycle.PurchaseAmountUSDC = buyAmount
+	cycle.SaleAmountUSDC = sellAmount
+	cycle.SellFeeAsset, cycle.SellFeeAssetAmount = status.FeeAsset, status.FeeAssetAmount
+
+	ev.with("price", fillPrice).with("profit", profit).
+		success(i18n.T("close_now.sold"), cycle.IdInt, fillPrice, profit, profitPercent)
+	ev.with("profit", profit).notify(cycle, "Cycle %d clôturé au marché: profit net %.2f USDC (%.2f%%)", cycle.IdInt, profit, profitPercent)
+	return cycle, nil
+}
+
+// waitForMarketFill attend l'exécution complète d'une vente au marché, au plus closeNowFillTimeout
+func waitForMarketFill(client common.Exchange, orderId string) (common.OrderStatus, error) {
+	deadline := time.Now().Add(closeNowFillTimeout)
+	for {
+		status, err := client.GetOrderStatus(orderId)
+		if err == nil && status.Filled() {
+			return status, nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return status, fmt.Errorf("exécution de la vente au marché %s non confirmée: %w", orderId, err)
+			}
+			return status, fmt.Errorf("vente au marché %s non exécutée après %s", orderId, closeNowFillTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
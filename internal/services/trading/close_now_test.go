@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"math"
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestCloseNow(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, APIKey: "key", SecretKey: "secret"}, 61000)
+	mock.SetBalance("BTC", 0.001)
+	repo := database.GetRepository()
+
+	cycle := &database.Cycle{
+		Exchange:           "BINANCE",
+		Status:             "sell",
+		Quantity:           0.001,
+		BuyPrice:           60000,
+		BuyFillPrice:       60000,
+		PurchaseAmountUSDC: 60,
+		BuyFees:            0.06,
+		TotalFees:          0.06,
+		SellPrice:          61200,
+		SellId:             mock.AddOrder("5001", "SELL", 61200, 0.001),
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+
+	// Refusée à la confirmation: la vente limite reste en place
+	var estimate closeEstimate
+	_, err := closeCycleNow(cycle.IdInt, func(e closeEstimate) bool {
+		estimate = e
+		return false
+	})
+	if err != errCloseAborted {
+		t.Fatalf("clôture refusée: %v, attendu errCloseAborted", err)
+	}
+	takerRate := exchangeFeeRates("BINANCE").Taker
+	if want := 61 - 60 - 0.06 - 61*takerRate; math.Abs(estimate.Profit-want) > 1e-9 {
+		t.Fatalf("profit estimé %.6f, attendu %.6f", estimate.Profit, want)
+	}
+	if status := mock.OrderStatus("5001"); status != "NEW" {
+		t.Fatalf("vente limite %s après un refus, attendu NEW", status)
+	}
+
+	closed, err := closeCycleNow(cycle.IdInt, nil)
+	if err != nil {
+		t.Fatalf("closeCycleNow: %v", err)
+	}
+	if status := mock.OrderStatus("5001"); status != "CANCELED" {
+		t.Errorf("vente limite %s, attendu CANCELED", status)
+	}
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 || calls[0].Args[0] != "SELL" || calls[0].Args[2] != "0.00100000" || calls[0].Args[5] != true {
+		t.Fatalf("vente au marché attendue pour 0.001 BTC: %+v", calls)
+	}
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "completed" || stored.SellId != closed.SellId || stored.CompletedAt.IsZero() {
+		t.Fatalf("cycle %q, vente %q, attendu completed sur la vente au marché %q", stored.Status, stored.SellId, closed.SellId)
+	}
+	if math.Abs(stored.SellFillPrice-61000) > 1e-9 || math.Abs(stored.SaleAmountUSDC-61) > 1e-9 {
+		t.Errorf("vente à %.2f pour %.2f USDC, attendu 61000 et 61", stored.SellFillPrice, stored.SaleAmountUSDC)
+	}
+	if wantFees := 61 * takerRate; !stored.FeesEstimated || math.Abs(stored.SellFees-wantFees) > 1e-9 ||
+		math.Abs(stored.TotalFees-0.06-wantFees) > 1e-9 {
+		t.Errorf("frais de vente %.6f (total %.6f), attendu %.6f estimés au taux taker", stored.SellFees, stored.TotalFees, wantFees)
+	}
+
+	// Un cycle complété ne peut plus être clôturé
+	if _, err := closeCycleNow(cycle.IdInt, nil); err == nil {
+		t.Error("un cycle complété ne devrait pas pouvoir être clôturé")
+	}
+}
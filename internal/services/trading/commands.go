@@ -2,12 +2,14 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"main/internal/config"
@@ -17,8 +19,8 @@ import (
 	"main/internal/exchanges/kraken"
 	"main/internal/exchanges/kucoin"
 	"main/internal/exchanges/mexc"
+	"main/internal/types"
 
-	"github.com/buger/jsonparser"
 	"github.com/fatih/color"
 )
 
@@ -33,6 +35,8 @@ func GetAllArgs() []string {
 // SetConfig permet de définir la configuration pour toutes les commandes
 func SetConfig(config *config.Config) {
 	cfg = config
+	initTradeLogger(config)
+	initNotifiers(config)
 }
 
 // GetLastArg retourne le dernier argument de la ligne de commande
@@ -45,6 +49,34 @@ func GetLastArg() string {
 	return args[argsLen-1]
 }
 
+// Clients enregistrés par RegisterClient, prioritaires sur les clients réels
+var (
+	clientRegistryMu sync.RWMutex
+	clientRegistry   = make(map[string]common.Exchange)
+)
+
+// RegisterClient fait retourner client par GetClientByExchange pour l'exchange indiqué,
+// à la place du client réel (mock de test, simulation). Un client nil rétablit le client réel.
+func RegisterClient(exchange string, client common.Exchange) {
+	exchange = strings.ToUpper(exchange)
+
+	clientRegistryMu.Lock()
+	defer clientRegistryMu.Unlock()
+	if client == nil {
+		delete(clientRegistry, exchange)
+		return
+	}
+	clientRegistry[exchange] = client
+}
+
+// registeredClient retourne le client enregistré pour l'exchange, s'il y en a un
+func registeredClient(exchange string) (common.Exchange, bool) {
+	clientRegistryMu.RLock()
+	defer clientRegistryMu.RUnlock()
+	client, ok := clientRegistry[exchange]
+	return client, ok
+}
+
 // GetClientByExchange retourne un client pour l'échange spécifié
 func GetClientByExchange(exchangeArg ...string) common.Exchange {
 	// Récupérer le nom de l'exchange
@@ -56,6 +88,11 @@ func GetClientByExchange(exchangeArg ...string) common.Exchange {
 	}
 	ex = strings.ToUpper(ex)
 
+	// Un client enregistré ne nécessite pas de clés API
+	if client, ok := registeredClient(ex); ok {
+		return client
+	}
+
 	// Vérifier les clés API
 	if cfg.Exchanges[ex].APIKey == "" || cfg.Exchanges[ex].SecretKey == "" {
 		color.Red(fmt.Sprintf("%s_API_KEY and %s_SECRET_KEY must be set in bot.conf", ex, ex))
@@ -65,19 +102,43 @@ func GetClientByExchange(exchangeArg ...string) common.Exchange {
 	var client common.Exchange
 
 	// Sélectionner dynamiquement le client en fonction de l'exchange
+	// Écart maker configuré pour l'exchange
+	makerBuffer := cfg.Exchanges[ex].MakerBufferPercent
+	// Délai des requêtes et fenêtre des requêtes signées (0 = valeurs par défaut)
+	httpTimeout := time.Duration(cfg.Exchanges[ex].HTTPTimeoutSeconds) * time.Second
+	recvWindow := cfg.Exchanges[ex].RecvWindowMs
+
 	switch ex {
 	case "BINANCE":
-		client = binance.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		binanceClient := binance.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		binanceClient.SetMakerBufferPercent(makerBuffer)
+		binanceClient.SetHTTPTimeout(httpTimeout)
+		binanceClient.SetRecvWindow(recvWindow)
+		client = binanceClient
 	case "MEXC":
-		client = mexc.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		mexcClient := mexc.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		mexcClient.SetMakerBufferPercent(makerBuffer)
+		mexcClient.SetHTTPTimeout(httpTimeout)
+		mexcClient.SetRecvWindow(recvWindow)
+		client = mexcClient
 	case "KUCOIN": // Ajout du cas pour KuCoin
-		client = kucoin.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		kucoinClient := kucoin.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		kucoinClient.SetMakerBufferPercent(makerBuffer)
+		kucoinClient.SetHTTPTimeout(httpTimeout)
+		client = kucoinClient
 	case "KRAKEN": // Ajouter ce cas
-		client = kraken.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		krakenClient := kraken.NewClient(cfg.Exchanges[ex].APIKey, cfg.Exchanges[ex].SecretKey)
+		krakenClient.SetMakerBufferPercent(makerBuffer)
+		krakenClient.SetHTTPTimeout(httpTimeout)
+		krakenClient.SetReserve(exchangeReserve(ex))
+		krakenClient.SetPair(cfg.KrakenPair)
+		client = krakenClient
 	default:
 		color.Red("Unsupported exchange: %s. Defaulting to Binance.", ex)
 		client = binance.NewClient(cfg.APIKey(), cfg.SecretKey())
 	}
+	applyFeeRates(ex, client)
+	warnFeeAsset(ex, client)
 	return client
 }
 
@@ -134,6 +195,8 @@ func CancelAll() {
 		}
 
 		color.Green("Cycle %d supprimé avec succès", cycle.IdInt)
+		cycle.Status = "cancelled"
+		cycleEvent(cycle, "cancel").notify(cycle, "Cycle %d annulé manuellement", cycle.IdInt)
 		countCancelled++
 	}
 
@@ -150,41 +213,98 @@ func CancelAll() {
 	}
 }
 
+// checkCyclePair vérifie que la paire demandée par --pair= est celle que négocie l'exchange
+// (BTC/USDC, KRAKEN_PAIR pour Kraken): un exchange ne négocie qu'une seule paire
+func checkCyclePair(exchange string) error {
+	for _, arg := range GetAllArgs() {
+		value, ok := strings.CutPrefix(arg, "--pair=")
+		if !ok {
+			continue
+		}
+		pair, _, err := config.ParsePair(value)
+		if err != nil {
+			return err
+		}
+		if expected := cfg.DefaultPair(exchange); pair != expected {
+			return fmt.Errorf("paire %s non négociée sur %s (paire configurée: %s)", pair, exchange, expected)
+		}
+	}
+	return nil
+}
+
 // Si aucun exchange n'est spécifié, il utilisera la méthode standard
-// Si aucun exchange n'est spécifié, il utilisera la méthode standard
-func NewWithExchange(exchange string) {
+// Retourne ErrCycleSkipped lorsqu'une limite d'exposition ou de pertes empêche la création du cycle
+func NewWithExchange(exchange string) error {
 	// Si aucun exchange n'est spécifié, utiliser la méthode standard
 	if exchange == "" {
 		New()
-		return
+		return nil
+	}
+
+	// Une tâche planifiée précise la paire négociée (--pair=)
+	if err := checkCyclePair(exchange); err != nil {
+		color.Red("Nouveau cycle non créé: %v", err)
+		return err
 	}
 
 	// Récupérer les paramètres de configuration pour l'exchange spécifié en utilisant
 	// les fonctions existantes qui lisent depuis bot.conf
 	percent := getExchangePercent(exchange)
 
-	buyOffsetStr := getExchangeParam(exchange, "BUY_OFFSET", "-700")
-	buyOffset, _ := strconv.ParseFloat(buyOffsetStr, 64)
-	buyOffset = math.Abs(buyOffset) // Convertir en valeur positive pour le calcul
-
-	sellOffsetStr := getExchangeParam(exchange, "SELL_OFFSET", "700")
-	sellOffset, _ := strconv.ParseFloat(sellOffsetStr, 64)
-	sellOffset = math.Abs(sellOffset) // Convertir en valeur positive
+	// Offsets absolus ou en % du prix (l'offset absolu sert alors de plancher)
+	exchangeConfig := cfg.Exchanges[exchange]
 
 	// Ces valeurs peuvent être utilisées plus tard dans le code si nécessaire
 	// buyMaxDays, _ := strconv.Atoi(buyMaxDaysStr)
 	// buyMaxDeviation, _ := strconv.ParseFloat(buyMaxDeviationStr, 64)
 
+	// Aucun achat pendant une maintenance de l'exchange: le planificateur réessaiera plus tard
+	if entry, skip := checkMaintenance(exchange); skip {
+		err := fmt.Errorf("%w: %s en maintenance jusqu'à au moins %s", ErrCycleSkipped, exchange, entry.Until.Format("15:04"))
+		color.Yellow("Nouveau cycle non créé: %v", err)
+		return err
+	}
+
+	// Aucun achat tant que la paire est suspendue par l'exchange
+	if entry, halted := checkTradingHalt(exchange); halted {
+		err := fmt.Errorf("%w: paire BTC/USDC suspendue sur %s (état %s)", ErrCycleSkipped, exchange, entry.Status)
+		color.Yellow("Nouveau cycle non créé: %v", err)
+		return err
+	}
+
 	// Initialiser le client d'échange spécifique
 	client := GetClientByExchange(exchange)
 	client.CheckConnection()
 
-	// Récupérer le solde disponible
-	freeBalance := client.GetBalanceUSD()
+	// L'ordre d'achat porte l'identifiant client du prochain cycle: un achat placé par une
+	// exécution interrompue avant l'enregistrement de son cycle est repris au lieu d'être replacé
+	cycleId := database.GetRepository().NextId()
+	clientOrderID := common.ClientOrderID(cycleId, "buy")
+	if order, found := findClientOrder(client, clientOrderID); found {
+		return saveNewCycle(client, exchange, cycleId, clientOrderID, order.ID, order.Price,
+			order.Price-exchangeConfig.BuyOffsetAt(order.Price)+exchangeConfig.SellOffsetAt(order.Price), order.Quantity, 0)
+	}
+
+	// Aucun nouvel achat tant que la limite de pertes quotidienne est atteinte
+	if lossLimits, err := currentLossLimits(); err != nil {
+		color.Red("Erreur lors du calcul des pertes quotidiennes: %v", err)
+		return err
+	} else if lossLimits.Blocks(exchange) {
+		err := fmt.Errorf("%w: limite de pertes quotidienne atteinte sur %s (--override-loss-limit pour reprendre)",
+			ErrCycleSkipped, exchange)
+		color.Yellow("Nouveau cycle non créé: %v", err)
+		return err
+	}
+
+	// Récupérer le solde disponible, hors réserve
+	freeBalance := usableUSDC(client, exchange)
+	if reserve := exchangeReserve(exchange); reserve.USDC > 0 {
+		color.White("Réserve USDC sur %s: %.2f (jamais engagée)", exchange, reserve.USDC)
+	}
 	color.White("Solde USD disponible sur %s: %.2f", exchange, freeBalance)
 	if freeBalance < 10 {
 		color.Red("Un minimum de 10$ est nécessaire sur %s", exchange)
-		return // Continuer avec les autres exchanges en cas d'échec
+		return fmt.Errorf("solde insuffisant sur %s: %.2f USDC", exchange, freeBalance)
 	}
 
 	// Récupérer le prix actuel du BTC
@@ -196,6 +316,22 @@ func NewWithExchange(exchange string) {
 
 	// Calculer le montant pour le nouveau cycle
 	newCycleUSDC := CalcAmountUSD(freeBalance, percent)
+
+	// Respecter les limites d'exposition des cycles ouverts
+	cappedUSDC, err := applyExposureCaps(exchange, cfg.Exchanges[exchange], newCycleUSDC)
+	if err != nil {
+		if errors.Is(err, ErrCycleSkipped) {
+			color.Yellow("Nouveau cycle non créé: %v", err)
+		} else {
+			color.Red("Erreur lors du calcul de l'exposition sur %s: %v", exchange, err)
+		}
+		return err
+	}
+	if cappedUSDC < newCycleUSDC {
+		color.Yellow("Montant réduit de %.2f à %.2f USDC pour respecter %s_MAX_OPEN_EXPOSURE_USDC",
+			newCycleUSDC, cappedUSDC, exchange)
+		newCycleUSDC = cappedUSDC
+	}
 	fmt.Printf("%s %s\n",
 		color.CyanString("USD pour ce nouveau cycle:"),
 		color.YellowString("%.2f", newCycleUSDC),
@@ -210,81 +346,149 @@ func NewWithExchange(exchange string) {
 	)
 
 	// Calculer les prix d'achat et de vente en utilisant les offsets
-	// Comme BUY_OFFSET est généralement négatif dans le fichier bot.conf,
-	// on le soustrait au prix actuel (on a converti en valeur positive précédemment)
-	buyPrice := btcPrice - buyOffset
+	// BUY_OFFSET est négatif: on l'ajoute au prix actuel pour acheter en dessous
+	buyPrice := btcPrice + exchangeConfig.BuyOffsetAt(btcPrice)
 	fmt.Printf("%s %s\n",
 		color.CyanString("Prix d'achat:"),
 		color.YellowString("%.2f", buyPrice),
 	)
 
-	// SELL_OFFSET est généralement positif, on l'ajoute au prix actuel
-	sellPrice := btcPrice + sellOffset
+	// SELL_OFFSET est positif, on l'ajoute au prix actuel; en % il porte sur le prix d'achat
+	sellPrice := btcPrice + exchangeConfig.SellOffsetAt(buyPrice)
 	fmt.Printf("%s %s\n",
 		color.CyanString("Prix de vente:"),
 		color.YellowString("%.2f", sellPrice),
 	)
 
-	// Préparer l'ordre d'achat
-	buyPriceStr := fmt.Sprintf("%.2f", buyPrice)
+	// Vérifier les fonds avant d'envoyer l'ordre (frais et minimum de l'exchange compris).
+	// Le taux le plus élevé est retenu: un achat exécuté immédiatement paie le taux taker.
+	rates := exchangeFeeRates(exchange)
+	feeRate := math.Max(rates.Maker, rates.Taker)
+	minNotional := minOrderNotional(client)
+	funding := newFundingCheck(buyPrice, newCycleBTC, feeRate, minNotional, freeBalance)
+	if funding.Missing() > 0 || funding.BelowMinimum() {
+		if !hasMaxArg() {
+			if funding.Missing() > 0 {
+				printFundingShortfall(client, exchange, funding)
+				return fmt.Errorf("fonds insuffisants sur %s: %.2f USDC manquants", exchange, funding.Missing())
+			}
+			color.Red("Ordre de %.2f USDC inférieur au minimum de %.2f USDC sur %s (augmentez %s_PERCENT ou relancez avec --max)",
+				funding.Notional, funding.MinNotional, exchange, exchange)
+			return fmt.Errorf("ordre inférieur au minimum sur %s: %.2f < %.2f USDC", exchange, funding.Notional, funding.MinNotional)
+		}
 
-	// Créer l'ordre d'achat
-	body, err := client.CreateOrder("BUY", buyPriceStr, newCycleBTCFormated)
-	if err != nil {
-		color.Red("Échec de l'ordre sur %s: %v", exchange, err)
-		return // Continuer avec les autres exchanges en cas d'échec
+		// --max: acheter la plus grande quantité finançable
+		newCycleBTC = maxAffordableQuantity(freeBalance, buyPrice, feeRate)
+		funding = newFundingCheck(buyPrice, newCycleBTC, feeRate, minNotional, freeBalance)
+		if newCycleBTC <= 0 || funding.BelowMinimum() {
+			color.Red("Solde libre de %.2f USDC insuffisant pour le minimum de %.2f USDC sur %s", freeBalance, minNotional, exchange)
+			return fmt.Errorf("solde insuffisant sur %s: %.2f USDC", exchange, freeBalance)
+		}
+		newCycleBTCFormated = FormatSmallFloat(newCycleBTC)
+		color.Yellow("--max: quantité ajustée à %s BTC (%.2f USDC, frais compris)", newCycleBTCFormated, funding.Required)
+	}
+
+	// Écart d'achat/vente trop faible pour le profit net minimum avec les frais actuels
+	warnMinProfitUnreachable(exchange, cfg.Exchanges[exchange], buyPrice, sellPrice, newCycleBTC)
+
+	// Achat échelonné: la quantité est répartie à parts égales entre les tranches, qui forment
+	// chacune un cycle du groupe. Le prix de vente de chaque tranche garde l'écart d'achat/vente.
+	ladderCount, ladderStep := ladderParams(exchange)
+	if ladderCount > 1 {
+		deepest := ladderPrices(buyPrice, ladderCount, ladderStep)[ladderCount-1]
+		if count := ladderTrancheCount(ladderCount, deepest*newCycleBTC, minNotional); count < ladderCount {
+			color.Yellow("Achat réparti en %d tranche(s) au lieu de %d pour respecter le minimum de %.2f USDC par ordre",
+				count, ladderCount, minNotional)
+			ladderCount = count
+		}
 	}
-
-	// Extraire l'ID de l'ordre
-	orderIdValue, dataType, _, err := jsonparser.Get(body, "orderId")
-	if err != nil {
-		color.Red("Erreur lors de l'extraction de l'ID d'ordre: %v", err)
-		return
+	if ladderCount == 1 {
+		return placeBuyOrder(client, exchange, cycleId, clientOrderID, buyPrice, sellPrice, newCycleBTC, 0)
 	}
 
-	// Extraction et nettoyage cohérent de l'ID
-	var orderIdStr string
-	switch dataType {
-	case jsonparser.String:
-		orderIdStr = strings.TrimSpace(string(orderIdValue))
-	case jsonparser.Number:
-		orderIdStr = strings.TrimSpace(string(orderIdValue))
-	default:
-		color.Yellow("Type d'ID d'ordre inattendu: %v", dataType)
-		orderIdStr = strings.TrimSpace(string(orderIdValue))
+	// Le groupe porte l'ID du cycle de la première tranche
+	groupId := cycleId
+	spread := sellPrice - buyPrice
+	trancheBTC := newCycleBTC / float64(ladderCount)
+	for i, price := range ladderPrices(buyPrice, ladderCount, ladderStep) {
+		if i > 0 {
+			cycleId = database.GetRepository().NextId()
+			clientOrderID = common.ClientOrderID(cycleId, "buy")
+		}
+		fmt.Printf("%s %s\n",
+			color.CyanString("Tranche %d/%d du groupe %d:", i+1, ladderCount, groupId),
+			color.YellowString("%s BTC à %.2f", FormatSmallFloat(trancheBTC), price),
+		)
+		if err := placeBuyOrder(client, exchange, cycleId, clientOrderID, price, price+spread, trancheBTC, groupId); err != nil {
+			if i > 0 {
+				color.Yellow("%d tranche(s) sur %d placée(s) dans le groupe %d", i, ladderCount, groupId)
+			}
+			return err
+		}
 	}
+	return nil
+}
 
-	if exchange == "MEXC" {
-		// Supprimer les préfixes spécifiques
-		orderIdStr = strings.TrimPrefix(orderIdStr, "C02__")
+// placeBuyOrder place l'ordre d'achat d'un cycle (ou d'une tranche d'un groupe) et enregistre le cycle
+func placeBuyOrder(client common.Exchange, exchange string, cycleId int32, clientOrderID string,
+	buyPrice, sellPrice, quantity float64, groupId int32) error {
+	// Créer l'ordre d'achat (post-only si activé: le prix peut être abaissé d'un ou plusieurs ticks)
+	created, placedPrice, err := createBuyOrder(client, exchange, buyPrice, client.FormatQuantity(quantity), clientOrderID, time.Now())
+	if err != nil {
+		color.Red("Échec de l'ordre sur %s: %v", exchange, err)
+		return err
+	}
+	if placedPrice != buyPrice {
+		color.Yellow("Ordre post-only replacé à %.2f au lieu de %.2f pour rester maker", placedPrice, buyPrice)
+		buyPrice = placedPrice
 	}
 
-	// Créer un objet Cycle
+	return saveNewCycle(client, exchange, cycleId, clientOrderID, buyOrderId(created, exchange), buyPrice, sellPrice, quantity, groupId)
+}
+
+// saveNewCycle enregistre le cycle d'un ordre d'achat placé (ou repris) sous l'ID réservé
+// pour son identifiant client. L'ordre est annulé si l'enregistrement échoue.
+func saveNewCycle(client common.Exchange, exchange string, cycleId int32, clientOrderID, orderIdStr string,
+	buyPrice, sellPrice, quantity float64, groupId int32) error {
 	cycle := &database.Cycle{
-		Exchange:  exchange,
-		Status:    string(database.Status("buy")),
-		Quantity:  newCycleBTC,
-		BuyPrice:  buyPrice,
-		BuyId:     orderIdStr,
-		SellPrice: sellPrice,
-		SellId:    "",
-		CreatedAt: time.Now(),
+		IdInt:            cycleId,
+		Exchange:         exchange,
+		Status:           string(database.Status("buy")),
+		Quantity:         quantity,
+		BuyPrice:         buyPrice,
+		BuyId:            orderIdStr,
+		SellPrice:        sellPrice,
+		SellId:           "",
+		CreatedAt:        time.Now(),
+		BuyClientOrderId: clientOrderID,
+		GroupId:          groupId,
 	}
 
 	// Enregistrer le cycle dans la base de données
 	repo := database.GetRepository()
-	_, err = repo.Save(cycle)
+	_, err := repo.Save(cycle)
 	if err != nil {
 		color.Red("Erreur lors de l'enregistrement du cycle sur %s: %v", exchange, err)
+		// L'ordre est déjà suivi par un autre cycle: il ne doit pas être annulé
+		if errors.Is(err, database.ErrDuplicateBuyId) {
+			return err
+		}
 		// Tenter d'annuler l'ordre si l'enregistrement échoue
 		_, cancelErr := client.CancelOrder(orderIdStr)
 		if cancelErr != nil {
 			color.Red("Erreur lors de l'annulation de l'ordre après échec de sauvegarde: %v", cancelErr)
 		}
-		return
+		return err
 	}
 
 	color.Green("Nouveau cycle créé avec succès sur %s", exchange)
+	// Lancé par le planificateur: signaler le cycle pour l'historique des exécutions
+	if os.Getenv(types.TaskRunEnv) != "" {
+		fmt.Printf("%s%d\n", types.CreatedCycleMarker, cycleId)
+	}
+	cycleEvent(cycle, "new_cycle").with("order_id", orderIdStr).with("price", buyPrice).
+		notify(cycle, "Cycle %d créé: achat de %.8f BTC à %.2f USDC", cycle.IdInt, quantity, buyPrice)
+	return nil
 }
 
 // UpdateWithExchange exécute la commande Update avec un exchange spécifique
@@ -318,6 +522,10 @@ func UpdateWithExchange(exchange string) {
 	color.White("  Libre:      %.8f BTC (%.2f USDC)", btcBalance.Free, btcBalance.Free*lastPrice)
 	color.White("  Verrouillé: %.8f BTC (%.2f USDC)", btcBalance.Locked, btcBalance.Locked*lastPrice)
 	color.White("  Total:      %.8f BTC (%.2f USDC)", btcBalance.Total, btcBalance.Total*lastPrice)
+	reserve := exchangeReserve(exchange)
+	if reserve.BTC > 0 {
+		color.White("  Réservé:    %.8f BTC (%.2f USDC)", reserve.BTC, reserve.BTC*lastPrice)
+	}
 
 	// Afficher les soldes USDC
 	usdcBalance := balances["USDC"]
@@ -325,46 +533,40 @@ func UpdateWithExchange(exchange string) {
 	color.White("  Libre:      %.2f USDC", usdcBalance.Free)
 	color.White("  Verrouillé: %.2f USDC", usdcBalance.Locked)
 	color.White("  Total:      %.2f USDC", usdcBalance.Total)
+	if reserve.USDC > 0 {
+		color.White("  Réservé:    %.2f USDC", reserve.USDC)
+	}
 
 	fmt.Println("") // Ligne vide pour séparer les sections
 
-	// Récupérer tous les cycles depuis le repository
+	// Récupérer les cycles en cours depuis le repository
 	repo := database.GetRepository()
-	allCycles, err := repo.FindAll()
+	activeCycles, err := repo.FindActive()
 	if err != nil {
 		color.Red("Erreur lors de la récupération des cycles: %v", err)
 		return
 	}
 
-	// Filtrer les cycles pour l'exchange spécifié
-	var cycles []*database.Cycle
-	for _, cycle := range allCycles {
-		if cycle.Exchange == exchange {
-			cycles = append(cycles, cycle)
+	// Traiter chaque cycle de l'exchange spécifié en fonction de son statut
+	for _, cycle := range activeCycles {
+		if cycle.Exchange != exchange {
+			continue
 		}
-	}
-
-	if len(cycles) == 0 {
-		color.Yellow("Aucun cycle trouvé pour l'exchange %s", exchange)
-		return
-	}
-
-	// Traiter chaque cycle
-	for _, cycle := range cycles {
-		// Traiter le cycle en fonction de son statut
 		switch cycle.Status {
 		case "buy":
 			processBuyCycle(client, repo, cycle, lastPrice)
 		case "sell":
-			processSellCycle(client, repo, cycle)
-		case "completed":
-			// Pas d'action nécessaire pour les cycles complétés
-			continue
+			processSellCycle(client, repo, cycle, lastPrice)
 		}
 	}
 
-	// Afficher l'historique des cycles filtrés
-	displayCyclesHistory(cycles, 0)
+	// Afficher les cycles en cours et les statistiques de l'exchange
+	prices := map[string]float64{exchange: lastPrice}
+	saveLastPrices(prices)
+	recordPriceHistory(prices)
+	if err := displayUpdateSummary(repo, prices, exchange); err != nil {
+		color.Red("Erreur lors de la récupération des cycles: %v", err)
+	}
 }
 
 func CancelWithExchange(exchange string, cancelArg string) {
@@ -392,6 +594,12 @@ func CancelWithExchange(exchange string, cancelArg string) {
 		os.Exit(1)
 	}
 
+	// -c=group:ID annule toutes les tranches restantes d'un achat échelonné
+	if strings.HasPrefix(idStr, "group:") {
+		cancelGroup(exchange, idStr)
+		return
+	}
+
 	// Convertir l'ID en nombre entier
 	idInt, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -427,10 +635,10 @@ func CancelWithExchange(exchange string, cancelArg string) {
 	// Obtenir le client de l'échange approprié pour le cycle
 	client := GetClientByExchange(cycle.Exchange)
 
-	// Annuler l'ordre uniquement si le statut est "buy" ou "sell"
-	if status == "buy" || status == "sell" {
+	// Annuler l'ordre uniquement si le statut est "buy", "cancel_pending" ou "sell"
+	if status == "buy" || status == database.StatusCancelPending || status == "sell" {
 		var orderIdToCancel string
-		if status == "buy" {
+		if status != "sell" {
 			orderIdToCancel = cycle.BuyId
 			color.Yellow("Annulation de l'ordre d'achat %s", orderIdToCancel)
 		} else {
@@ -447,16 +655,25 @@ func CancelWithExchange(exchange string, cancelArg string) {
 			res, err := client.CancelOrder(cleanOrderId)
 			if err != nil {
 				color.Red("Échec de l'annulation de l'ordre: %v", err)
-				// Continuer malgré l'erreur pour supprimer le cycle de la base de données
+				// Continuer malgré l'erreur pour marquer le cycle comme annulé
 			} else {
 				color.Green("Ordre annulé avec succès:")
 				fmt.Println(string(res))
 			}
 		}
-	} else {
-		color.Yellow("Le cycle a le statut '%s', aucun ordre à annuler, suppression de la base de données uniquement", status)
+
+		// Conserver le cycle avec la cause de l'annulation
+		if err := markCycleCancelled(repo, cycle, database.CancelReasonManual); err != nil {
+			color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Cycle %d marqué comme annulé", idInt)
+		cycleEvent(cycle, "cancel").notify(cycle, "Cycle %d annulé manuellement", idInt)
+		return
 	}
 
+	color.Yellow("Le cycle a le statut '%s', aucun ordre à annuler, suppression de la base de données uniquement", status)
+
 	// Supprimer le cycle de la base de données
 	err = repo.DeleteByIdInt(int32(idInt))
 	if err != nil {
@@ -544,6 +761,8 @@ func CancelAllWithExchange(exchange string) {
 			color.Green("Succès avec ID original!")
 			// Si ça a fonctionné, supprimer le cycle et continuer
 			repo.DeleteByIdInt(cycle.IdInt)
+			cycle.Status = "cancelled"
+			cycleEvent(cycle, "cancel").notify(cycle, "Cycle %d annulé manuellement", cycle.IdInt)
 			countCancelled++
 			continue
 		}
@@ -558,6 +777,8 @@ func CancelAllWithExchange(exchange string) {
 				color.Green("Succès avec ID nettoyé!")
 				// Si ça a fonctionné, supprimer le cycle et continuer
 				repo.DeleteByIdInt(cycle.IdInt)
+				cycle.Status = "cancelled"
+				cycleEvent(cycle, "cancel").notify(cycle, "Cycle %d annulé manuellement", cycle.IdInt)
 				countCancelled++
 				continue
 			}
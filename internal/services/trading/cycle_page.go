@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/web"
+)
+
+// cycleFromPath retourne le cycle désigné par le paramètre {id} de la route
+func cycleFromPath(r *http.Request) (*database.Cycle, int, error) {
+	idInt, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("ID de cycle invalide: %s", r.PathValue("id"))
+	}
+
+	cycle, err := database.GetRepository().FindByIdInt(int32(idInt))
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("erreur lors de la récupération du cycle: %w", err)
+	}
+	if cycle == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("cycle avec ID %d introuvable", idInt)
+	}
+	return cycle, http.StatusOK, nil
+}
+
+// handleCyclePage affiche le détail d'un cycle
+func handleCyclePage(w http.ResponseWriter, r *http.Request) {
+	cycle, code, err := cycleFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	dto := convertCycleToDTO(cycle)
+	dto["buyTotal"] = cycle.EffectiveBuyPrice() * cycle.Quantity
+	dto["purchaseAmountUSDC"] = cycle.PurchaseAmountUSDC
+	dto["saleAmountUSDC"] = cycle.SaleAmountUSDC
+	dto["totalFees"] = cycle.TotalFees
+	dto["feeAssetNote"] = feeAssetNote(cycle)
+
+	renderTemplate(w, web.CycleTemplate, map[string]interface{}{
+		"cycle":          dto,
+		"orderSnapshots": orderSnapshotViews(cycle),
+		"message":        r.URL.Query().Get("message"),
+		"error":          r.URL.Query().Get("error"),
+		"readOnly":       readOnly(),
+		"currentTime":    time.Now().Format("02/01/2006 15:04:05"),
+	})
+}
+
+// feeAssetNote décrit les frais payés hors BTC et USDC (BNB), tels que prélevés par l'exchange:
+// « 0.00007500 BNB à l'achat, 0.00007600 BNB à la vente ». Vide si aucun.
+func feeAssetNote(cycle *database.Cycle) string {
+	var parts []string
+	if cycle.BuyFeeAssetAmount > 0 {
+		parts = append(parts, fmt.Sprintf("%.8f %s à l'achat", cycle.BuyFeeAssetAmount, cycle.BuyFeeAsset))
+	}
+	if cycle.SellFeeAssetAmount > 0 {
+		parts = append(parts, fmt.Sprintf("%.8f %s à la vente", cycle.SellFeeAssetAmount, cycle.SellFeeAsset))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleSetSellPrice remplace l'ordre de vente d'un cycle par un ordre au prix saisi
+func handleSetSellPrice(w http.ResponseWriter, r *http.Request) {
+	cycle, code, err := cycleFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	target := fmt.Sprintf("/cycles/%d", cycle.IdInt)
+	price, err := strconv.ParseFloat(r.FormValue("price"), 64)
+	if err != nil {
+		http.Redirect(w, r, target+"?error="+url.QueryEscape("Prix invalide: "+r.FormValue("price")), http.StatusSeeOther)
+		return
+	}
+
+	updated, err := overrideSellPrice(cycle.IdInt, price)
+	if err != nil {
+		http.Redirect(w, r, target+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	message := fmt.Sprintf("Nouvel ordre de vente %s placé à %.2f USDC", updated.SellId, updated.SellPrice)
+	http.Redirect(w, r, target+"?message="+url.QueryEscape(message), http.StatusSeeOther)
+}
@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"main/internal/database"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Colonnes triables du tableau de bord (?sort=...&dir=asc|desc)
+var dashboardSortColumns = map[string]string{
+	"id":        "idInt",
+	"exchange":  "exchange",
+	"status":    "status",
+	"buy_price": "buyPrice",
+	"profit":    "profit",
+	"duration":  "durationDays",
+	"age":       "age",
+}
+
+// dashboardSort décrit le tri demandé pour le tableau des cycles
+type dashboardSort struct {
+	Column string
+	Desc   bool
+}
+
+// parseDashboardSort lit les paramètres sort et dir (tri par ID décroissant par défaut)
+func parseDashboardSort(params url.Values) dashboardSort {
+	column := params.Get("sort")
+	if _, ok := dashboardSortColumns[column]; !ok {
+		column = "id"
+	}
+
+	desc := true
+	switch params.Get("dir") {
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		// Les colonnes texte se lisent naturellement dans l'ordre alphabétique
+		desc = column != "exchange" && column != "status"
+	}
+
+	return dashboardSort{Column: column, Desc: desc}
+}
+
+// Dir retourne le sens du tri tel qu'attendu dans le paramètre dir
+func (s dashboardSort) Dir() string {
+	if s.Desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// sortCycleDTOs trie les DTOs des cycles selon la colonne demandée
+func sortCycleDTOs(dtos []map[string]interface{}, s dashboardSort) {
+	key := dashboardSortColumns[s.Column]
+
+	sort.SliceStable(dtos, func(i, j int) bool {
+		cmp := compareDTOValues(dtos[i][key], dtos[j][key])
+		if cmp == 0 {
+			// Départager par ID pour un ordre stable entre les pages
+			cmp = compareDTOValues(dtos[i]["idInt"], dtos[j]["idInt"])
+		}
+		if s.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// groupCycleDTOs rassemble les tranches d'un achat échelonné à la place de la première
+// d'entre elles dans le tri, et attache le sous-total du groupe à la dernière tranche
+func groupCycleDTOs(dtos []map[string]interface{}) []map[string]interface{} {
+	members := make(map[int32][]map[string]interface{})
+	for _, dto := range dtos {
+		if groupId, _ := dto["groupId"].(int32); groupId != 0 {
+			members[groupId] = append(members[groupId], dto)
+		}
+	}
+
+	grouped := make([]map[string]interface{}, 0, len(dtos))
+	for _, dto := range dtos {
+		groupId, _ := dto["groupId"].(int32)
+		if groupId == 0 {
+			grouped = append(grouped, dto)
+			continue
+		}
+		tranches, pending := members[groupId]
+		if !pending {
+			continue // Tranche déjà placée avec la première du groupe
+		}
+		delete(members, groupId)
+		grouped = append(grouped, tranches...)
+
+		if len(tranches) > 1 {
+			subtotal := map[string]interface{}{"groupId": groupId, "count": len(tranches)}
+			var quantity, buyTotal, sellTotal, profit float64
+			for _, tranche := range tranches {
+				quantity += toFloat(tranche["quantity"])
+				buyTotal += toFloat(tranche["buyTotal"])
+				sellTotal += toFloat(tranche["sellTotal"])
+				profit += toFloat(tranche["profit"])
+			}
+			subtotal["quantity"] = quantity
+			subtotal["buyTotal"] = buyTotal
+			subtotal["sellTotal"] = sellTotal
+			subtotal["profit"] = profit
+			tranches[len(tranches)-1]["groupSubtotal"] = subtotal
+		}
+	}
+	return grouped
+}
+
+// groupByExchange regroupe le tableau de bord et /api/cycles par exchange (?groupBy=exchange)
+const groupByExchange = "exchange"
+
+// exchangeSubtotal est le sous-total d'un exchange, ou le total général, du tableau groupé
+type exchangeSubtotal struct {
+	Exchange       string  `json:"exchange,omitempty"`
+	Count          int     `json:"count"`
+	DeployedUSDC   float64 `json:"deployedUSDC"`     // Montant d'achat des cycles en cours
+	ExpectedProfit float64 `json:"expectedProfit"`   // Profit brut prévu des cycles en vente
+	Unrealized     float64 `json:"unrealizedProfit"` // P&L latent des cycles en vente au dernier prix
+	// BTC accumulé encore conservé et sa valeur au dernier prix, compris dans CapitalUSDC
+	AccumulatedBTC   float64 `json:"accumulatedBTC"`
+	AccumulatedValue float64 `json:"accumulatedValue"`
+	CapitalUSDC      float64 `json:"capitalUSDC"` // Montant engagé plus valeur du BTC accumulé
+}
+
+// add ajoute un cycle au sous-total, au prix BTC de son exchange
+func (s *exchangeSubtotal) add(cycle *database.Cycle, price float64) {
+	s.Count++
+	switch cycle.Status {
+	case "buy", database.StatusCancelPending:
+		s.DeployedUSDC += cycle.EffectiveBuyPrice() * cycle.Quantity
+	case "sell":
+		s.DeployedUSDC += cycle.EffectiveBuyPrice() * cycle.Quantity
+		s.ExpectedProfit += (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
+	}
+	if profit, ok := unrealizedProfit(cycle, price); ok {
+		s.Unrealized += profit
+	}
+	s.CapitalUSDC = s.DeployedUSDC + s.AccumulatedValue
+}
+
+// addAccumulation ajoute au sous-total le BTC accumulé encore conservé, valorisé au prix BTC de
+// son exchange (la quantité seule si le prix est inconnu)
+func (s *exchangeSubtotal) addAccumulation(accumulation *database.Accumulation, price float64) {
+	s.AccumulatedBTC += accumulation.Quantity
+	if price > 0 {
+		s.AccumulatedValue += accumulation.Quantity * price
+	}
+	s.CapitalUSDC = s.DeployedUSDC + s.AccumulatedValue
+}
+
+// subtotalsByExchange calcule le sous-total de chaque exchange et le total général des cycles
+func subtotalsByExchange(cycles []*database.Cycle, prices map[string]float64) (map[string]*exchangeSubtotal, *exchangeSubtotal) {
+	subtotals := make(map[string]*exchangeSubtotal)
+	total := &exchangeSubtotal{}
+	for _, cycle := range cycles {
+		subtotal, exists := subtotals[cycle.Exchange]
+		if !exists {
+			subtotal = &exchangeSubtotal{Exchange: cycle.Exchange}
+			subtotals[cycle.Exchange] = subtotal
+		}
+		subtotal.add(cycle, prices[cycle.Exchange])
+		total.add(cycle, prices[cycle.Exchange])
+	}
+	return subtotals, total
+}
+
+// addAccumulationsToSubtotals ajoute le BTC accumulé conservé aux sous-totaux et au total général:
+// sans cycle pour le porter, ce capital n'apparaîtrait nulle part. exchangeFilter limite
+// les accumulations retenues à un exchange (vide: toutes).
+func addAccumulationsToSubtotals(subtotals map[string]*exchangeSubtotal, total *exchangeSubtotal,
+	accumulations []*database.Accumulation, prices map[string]float64, exchangeFilter string) {
+	for _, accumulation := range accumulations {
+		if accumulation.Converted() || (exchangeFilter != "" && !strings.EqualFold(accumulation.Exchange, exchangeFilter)) {
+			continue
+		}
+		subtotal, exists := subtotals[accumulation.Exchange]
+		if !exists {
+			subtotal = &exchangeSubtotal{Exchange: accumulation.Exchange}
+			subtotals[accumulation.Exchange] = subtotal
+		}
+		subtotal.addAccumulation(accumulation, prices[accumulation.Exchange])
+		total.addAccumulation(accumulation, prices[accumulation.Exchange])
+	}
+}
+
+// groupDTOsByExchange range les lignes par exchange en conservant le tri à l'intérieur de chacun,
+// et attache le sous-total de l'exchange à sa dernière ligne
+func groupDTOsByExchange(dtos []map[string]interface{}, subtotals map[string]*exchangeSubtotal) {
+	sort.SliceStable(dtos, func(i, j int) bool {
+		return compareDTOValues(dtos[i]["exchange"], dtos[j]["exchange"]) < 0
+	})
+	for i, dto := range dtos {
+		exchange, _ := dto["exchange"].(string)
+		if i == len(dtos)-1 || dtos[i+1]["exchange"] != exchange {
+			if subtotal, ok := subtotals[exchange]; ok {
+				dto["exchangeSubtotal"] = subtotal
+			}
+		}
+	}
+}
+
+// compareDTOValues compare deux valeurs de DTO (nombres ou chaînes)
+func compareDTOValues(a, b interface{}) int {
+	if as, ok := a.(string); ok {
+		bs, _ := b.(string)
+		return strings.Compare(strings.ToLower(as), strings.ToLower(bs))
+	}
+
+	af, bf := toFloat(a), toFloat(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// toFloat convertit une valeur numérique de DTO en float64
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// dashboardURL construit l'URL du tableau de bord en conservant les filtres
+// courants et en remplaçant les paramètres fournis
+func dashboardURL(params url.Values, overrides map[string]string) string {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = append([]string(nil), values...)
+	}
+	for key, value := range overrides {
+		if value == "" {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+	}
+
+	if len(query) == 0 {
+		return "/"
+	}
+	return "/?" + query.Encode()
+}
+
+// dashboardSortLinks retourne, pour chaque colonne triable, le lien d'en-tête et
+// l'indicateur de tri. Un clic sur la colonne active inverse le sens du tri.
+func dashboardSortLinks(params url.Values, current dashboardSort) (map[string]string, map[string]string) {
+	links := make(map[string]string, len(dashboardSortColumns))
+	arrows := make(map[string]string, len(dashboardSortColumns))
+
+	for column := range dashboardSortColumns {
+		dir := "desc"
+		if column == "exchange" || column == "status" {
+			dir = "asc"
+		}
+		arrows[column] = ""
+
+		if column == current.Column {
+			if current.Desc {
+				dir = "asc"
+				arrows[column] = "▼"
+			} else {
+				dir = "desc"
+				arrows[column] = "▲"
+			}
+		}
+
+		// Un changement de tri ramène à la première page
+		links[column] = dashboardURL(params, map[string]string{"sort": column, "dir": dir, "page": ""})
+	}
+
+	return links, arrows
+}
+
+// paginateCycleDTOs retourne la page demandée ainsi que la page effective et le nombre de pages
+func paginateCycleDTOs(dtos []map[string]interface{}, pageParam string, pageSize int) ([]map[string]interface{}, int, int) {
+	totalPages := (len(dtos) + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	page, err := strconv.Atoi(pageParam)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > len(dtos) {
+		end = len(dtos)
+	}
+
+	return dtos[start:end], page, totalPages
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"math"
+	"testing"
+
+	"main/internal/database"
+)
+
+func TestGroupDTOsByExchange(t *testing.T) {
+	cycles := []*database.Cycle{
+		{IdInt: 1, Exchange: "MEXC", Status: "buy", BuyPrice: 60000, Quantity: 0.001},
+		{IdInt: 2, Exchange: "BINANCE", Status: "sell", BuyPrice: 60000, SellPrice: 61000, Quantity: 0.002},
+		{IdInt: 3, Exchange: "MEXC", Status: "completed", BuyPrice: 59000, SellPrice: 60000, Quantity: 0.001},
+		{IdInt: 4, Exchange: "BINANCE", Status: "buy", BuyPrice: 58000, Quantity: 0.001},
+	}
+	subtotals, total := subtotalsByExchange(cycles, map[string]float64{"BINANCE": 62000})
+
+	binance := subtotals["BINANCE"]
+	if binance.Count != 2 || math.Abs(binance.DeployedUSDC-178) > 1e-9 || math.Abs(binance.ExpectedProfit-2) > 1e-9 {
+		t.Errorf("sous-total BINANCE = %+v, want 2 cycles, 178 USDC engagés et 2 USDC prévus", binance)
+	}
+	if binance.Unrealized <= 0 {
+		t.Errorf("le P&L latent du cycle en vente au-dessus de son prix d'achat devrait être positif: %v", binance.Unrealized)
+	}
+	// Un cycle complété compte sans être engagé
+	if mexc := subtotals["MEXC"]; mexc.Count != 2 || math.Abs(mexc.DeployedUSDC-60) > 1e-9 {
+		t.Errorf("sous-total MEXC = %+v, want 2 cycles et 60 USDC engagés", mexc)
+	}
+	if total.Count != 4 || math.Abs(total.DeployedUSDC-238) > 1e-9 {
+		t.Errorf("total = %+v, want 4 cycles et 238 USDC engagés", total)
+	}
+
+	dtos := []map[string]interface{}{
+		{"idInt": int32(1), "exchange": "MEXC", "exchangeSubtotal": nil},
+		{"idInt": int32(2), "exchange": "BINANCE", "exchangeSubtotal": nil},
+		{"idInt": int32(3), "exchange": "MEXC", "exchangeSubtotal": nil},
+		{"idInt": int32(4), "exchange": "BINANCE", "exchangeSubtotal": nil},
+	}
+	groupDTOsByExchange(dtos, subtotals)
+
+	var order []int32
+	for _, dto := range dtos {
+		order = append(order, dto["idInt"].(int32))
+	}
+	if order[0] != 2 || order[1] != 4 || order[2] != 1 || order[3] != 3 {
+		t.Errorf("ordre = %v, want [2 4 1 3]: exchanges regroupés, tri conservé", order)
+	}
+	if dtos[0]["exchangeSubtotal"] != nil || dtos[1]["exchangeSubtotal"] != binance || dtos[3]["exchangeSubtotal"] != subtotals["MEXC"] {
+		t.Errorf("le sous-total de chaque exchange devrait être attaché à sa dernière ligne")
+	}
+}
+
+func TestAddAccumulationsToSubtotals(t *testing.T) {
+	cycles := []*database.Cycle{
+		{IdInt: 1, Exchange: "BINANCE", Status: "buy", BuyPrice: 60000, Quantity: 0.001},
+	}
+	accumulations := []*database.Accumulation{
+		{IdInt: 1, Exchange: "BINANCE", Quantity: 0.002, CancelPrice: 50000},
+		{IdInt: 2, Exchange: "KRAKEN", Quantity: 0.001, CancelPrice: 55000},
+		{IdInt: 3, Exchange: "BINANCE", Quantity: 0.005, CancelPrice: 50000, ConvertedCycleId: 9},
+	}
+	prices := map[string]float64{"BINANCE": 62000}
+
+	subtotals, total := subtotalsByExchange(cycles, prices)
+	addAccumulationsToSubtotals(subtotals, total, accumulations, prices, "")
+
+	binance := subtotals["BINANCE"]
+	if math.Abs(binance.AccumulatedBTC-0.002) > 1e-12 || math.Abs(binance.AccumulatedValue-124) > 1e-9 || math.Abs(binance.CapitalUSDC-184) > 1e-9 {
+		t.Errorf("sous-total BINANCE = %+v, want 0.002 BTC accumulés valant 124 USDC, capital 184 USDC", binance)
+	}
+	// Sans prix connu, la quantité est comptée sans valeur
+	if kraken := subtotals["KRAKEN"]; kraken == nil || kraken.Count != 0 || kraken.AccumulatedBTC != 0.001 || kraken.AccumulatedValue != 0 {
+		t.Errorf("sous-total KRAKEN = %+v, want 0.001 BTC accumulé sans valeur", kraken)
+	}
+	if math.Abs(total.AccumulatedBTC-0.003) > 1e-12 || math.Abs(total.CapitalUSDC-184) > 1e-9 {
+		t.Errorf("total = %+v, want 0.003 BTC accumulés et 184 USDC de capital", total)
+	}
+
+	subtotals, total = subtotalsByExchange(cycles, prices)
+	addAccumulationsToSubtotals(subtotals, total, accumulations, prices, "kraken")
+	if subtotals["BINANCE"].AccumulatedBTC != 0 || total.AccumulatedBTC != 0.001 {
+		t.Errorf("le filtre d'exchange devrait écarter les accumulations de BINANCE: %+v", total)
+	}
+
+	if gain := accumulations[0].UnrealizedGain(62000); math.Abs(gain-24) > 1e-9 {
+		t.Errorf("UnrealizedGain = %v, want 24 USDC", gain)
+	}
+}
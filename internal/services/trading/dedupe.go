@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"main/internal/database"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// duplicateGroup regroupe les cycles d'un exchange qui référencent le même ordre
+type duplicateGroup struct {
+	Exchange string
+	Side     string // "buy" (BuyId) ou "sell" (SellId)
+	OrderId  string
+	Cycles   []*database.Cycle // par ID croissant
+}
+
+// orderLabel retourne le libellé traduit de l'ordre partagé par le groupe
+func (g duplicateGroup) orderLabel() string {
+	if g.Side == "sell" {
+		return i18n.T("dedupe.sell_order")
+	}
+	return i18n.T("dedupe.buy_order")
+}
+
+// Dedupe recherche les cycles qui suivent le même ordre d'achat ou de vente sur un exchange
+// (créés en double lors d'un arrêt brutal) et propose pour chaque groupe de les fusionner ou
+// d'en supprimer un: --dedupe. Les ordres de l'exchange ne sont jamais modifiés.
+func Dedupe() {
+	repo := database.GetRepository()
+	cycles, err := repo.FindAll()
+	if err != nil {
+		color.Red(i18n.T("dedupe.cycles_error"), err)
+		os.Exit(1)
+	}
+
+	groups := findDuplicateCycles(cycles)
+	if len(groups) == 0 {
+		color.Green(i18n.T("dedupe.none"))
+		return
+	}
+
+	color.Yellow(i18n.T("dedupe.found"), len(groups))
+	reader := bufio.NewReader(os.Stdin)
+	for _, group := range groups {
+		// Un groupe précédent a pu régler ce doublon (même achat et même vente)
+		group.Cycles = reloadDuplicates(repo, group.Cycles)
+		if len(group.Cycles) < 2 {
+			continue
+		}
+		fmt.Println("")
+		printDuplicateGroup(group)
+		handleDuplicateGroup(reader, repo, group)
+	}
+}
+
+// findDuplicateCycles retourne les groupes de cycles qui référencent le même ordre d'achat ou
+// de vente sur un même exchange. Les cycles déjà écartés comme doublons sont ignorés, comme les
+// cycles importés: un achat revendu en plusieurs fois y forme volontairement plusieurs cycles.
+func findDuplicateCycles(cycles []*database.Cycle) []duplicateGroup {
+	type groupKey struct{ exchange, side, orderId string }
+	byOrder := make(map[groupKey][]*database.Cycle)
+	for _, cycle := range cycles {
+		if cycle.CancelReason == database.CancelReasonDuplicate || cycle.Imported {
+			continue
+		}
+		for side, orderId := range map[string]string{"buy": cycle.BuyId, "sell": cycle.SellId} {
+			if orderKey(orderId) == "" {
+				continue
+			}
+			key := groupKey{strings.ToUpper(cycle.Exchange), side, orderKey(orderId)}
+			byOrder[key] = append(byOrder[key], cycle)
+		}
+	}
+
+	var groups []duplicateGroup
+	for key, members := range byOrder {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].IdInt < members[j].IdInt })
+		groups = append(groups, duplicateGroup{Exchange: key.exchange, Side: key.side, OrderId: key.orderId, Cycles: members})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Cycles[0].IdInt != groups[j].Cycles[0].IdInt {
+			return groups[i].Cycles[0].IdInt < groups[j].Cycles[0].IdInt
+		}
+		return groups[i].Side < groups[j].Side
+	})
+	return groups
+}
+
+// reloadDuplicates relit les cycles d'un groupe et écarte ceux supprimés ou déjà marqués doublons
+func reloadDuplicates(repo *database.CycleRepository, cycles []*database.Cycle) []*database.Cycle {
+	var current []*database.Cycle
+	for _, cycle := range cycles {
+		stored, err := repo.FindByIdInt(cycle.IdInt)
+		if err != nil || stored == nil || stored.CancelReason == database.CancelReasonDuplicate {
+			continue
+		}
+		current = append(current, stored)
+	}
+	return current
+}
+
+// cycleCompleteness mesure les données connues d'un cycle: avancement de son statut, puis
+// informations d'exécution (vente, prix réels, frais, montants, dates, réponses brutes)
+func cycleCompleteness(cycle *database.Cycle) int {
+	score := 0
+	switch cycle.Status {
+	case "completed":
+		score += 3
+	case "sell":
+		score += 2
+	case "buy", database.StatusCancelPending:
+		score++
+	}
+	for _, known := range []bool{
+		cycle.SellId != "",
+		cycle.BuyFillPrice > 0,
+		cycle.SellFillPrice > 0,
+		cycle.TotalFees != 0,
+		cycle.PurchaseAmountUSDC > 0,
+		cycle.SaleAmountUSDC > 0,
+		!cycle.CompletedAt.IsZero(),
+		!cycle.OrderSnapshots.Empty(),
+	} {
+		if known {
+			score++
+		}
+	}
+	return score
+}
+
+// preferredDuplicate retourne le cycle à conserver d'un groupe: le plus complet, le plus
+// ancien à égalité
+func preferredDuplicate(cycles []*database.Cycle) *database.Cycle {
+	best := cycles[0]
+	for _, cycle := range cycles[1:] {
+		if score, bestScore := cycleCompleteness(cycle), cycleCompleteness(best); score > bestScore ||
+			(score == bestScore && cycle.IdInt < best.IdInt) {
+			best = cycle
+		}
+	}
+	return best
+}
+
+// printDuplicateGroup affiche côte à côte les cycles d'un groupe de doublons
+func printDuplicateGroup(group duplicateGroup) {
+	color.Cyan(i18n.T("dedupe.group_header"), group.Exchange, group.orderLabel(), group.OrderId, len(group.Cycles))
+
+	date := func(cycle *database.Cycle, completed bool) string {
+		if completed {
+			if cycle.CompletedAt.IsZero() {
+				return "-"
+			}
+			return i18n.FormatDateTime(cycle.CompletedAt.Local())
+		}
+		return i18n.FormatDateTime(cycle.CreatedAt.Local())
+	}
+	orDash := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+	rows := []struct {
+		label string
+		value func(cycle *database.Cycle) string
+	}{
+		{i18n.T("dedupe.row_cycle"), func(c *database.Cycle) string { return strconv.Itoa(int(c.IdInt)) }},
+		{i18n.T("dedupe.row_status"), func(c *database.Cycle) string { return c.Status }},
+		{i18n.T("dedupe.row_created"), func(c *database.Cycle) string { return date(c, false) }},
+		{i18n.T("dedupe.row_completed"), func(c *database.Cycle) string { return date(c, true) }},
+		{i18n.T("dedupe.row_quantity"), func(c *database.Cycle) string { return fmt.Sprintf("%.8f", c.Quantity) }},
+		{i18n.T("dedupe.row_buy_price"), func(c *database.Cycle) string { return fmt.Sprintf("%.2f", c.EffectiveBuyPrice()) }},
+		{i18n.T("dedupe.row_sell_price"), func(c *database.Cycle) string { return fmt.Sprintf("%.2f", c.EffectiveSellPrice()) }},
+		{i18n.T("dedupe.row_buy_order"), func(c *database.Cycle) string { return orDash(c.BuyId) }},
+		{i18n.T("dedupe.row_sell_order"), func(c *database.Cycle) string { return orDash(c.SellId) }},
+		{i18n.T("dedupe.row_fees"), func(c *database.Cycle) string { return fmt.Sprintf("%.4f", c.TotalFees) }},
+		{i18n.T("dedupe.row_completeness"), func(c *database.Cycle) string { return strconv.Itoa(cycleCompleteness(c)) }},
+	}
+	for _, row := range rows {
+		line := fmt.Sprintf("  %-15s", row.label)
+		for _, cycle := range group.Cycles {
+			line += fmt.Sprintf(" | %-22s", row.value(cycle))
+		}
+		fmt.Println(line)
+	}
+}
+
+// handleDuplicateGroup demande l'action à appliquer à un groupe de doublons et l'exécute
+func handleDuplicateGroup(reader *bufio.Reader, repo *database.CycleRepository, group duplicateGroup) {
+	preferred := preferredDuplicate(group.Cycles)
+	switch strings.ToLower(promptLine(reader, i18n.T("dedupe.prompt_action"))) {
+	case "f":
+		keep := preferred
+		if answer := promptLine(reader, fmt.Sprintf(i18n.T("dedupe.prompt_keep"), preferred.IdInt)); answer != "" {
+			if keep = duplicateMember(group, answer); keep == nil {
+				color.Red(i18n.T("dedupe.not_in_group"), answer)
+				return
+			}
+		}
+		merged, err := mergeDuplicates(repo, keep, group.Cycles)
+		if err != nil {
+			color.Red(i18n.T("dedupe.merge_failed"), err)
+			return
+		}
+		color.Green(i18n.T("dedupe.merged"), keep.IdInt, merged)
+
+	case "s":
+		answer := promptLine(reader, i18n.T("dedupe.prompt_delete"))
+		target := duplicateMember(group, answer)
+		if target == nil {
+			color.Red(i18n.T("dedupe.not_in_group"), answer)
+			return
+		}
+		if err := repo.DeleteByIdInt(target.IdInt); err != nil {
+			color.Red(i18n.T("dedupe.delete_error"), target.IdInt, err)
+			return
+		}
+		cycleEvent(target, "dedupe").info(i18n.T("dedupe.deleted_event"), target.IdInt, group.orderLabel(), group.OrderId)
+		color.Green(i18n.T("dedupe.deleted"), target.IdInt)
+
+	default:
+		color.White(i18n.T("dedupe.left_as_is"))
+	}
+}
+
+// duplicateMember retourne le cycle du groupe dont l'ID est saisi, nil s'il n'en fait pas partie
+func duplicateMember(group duplicateGroup, answer string) *database.Cycle {
+	id, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil {
+		return nil
+	}
+	for _, cycle := range group.Cycles {
+		if int(cycle.IdInt) == id {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// mergeDuplicates conserve keep et annule les autres cycles du groupe avec la cause
+// CancelReasonDuplicate, sans annuler leurs ordres sur l'exchange (ce sont ceux de keep).
+// Le nombre de cycles annulés est retourné.
+func mergeDuplicates(repo *database.CycleRepository, keep *database.Cycle, cycles []*database.Cycle) (int, error) {
+	merged := 0
+	for _, cycle := range cycles {
+		if cycle.IdInt == keep.IdInt {
+			continue
+		}
+		if err := markCycleCancelled(repo, cycle, database.CancelReasonDuplicate); err != nil {
+			return merged, fmt.Errorf("cycle %d: %w", cycle.IdInt, err)
+		}
+		cycleEvent(cycle, "dedupe").info(i18n.T("dedupe.cancelled_event"), cycle.IdInt, keep.IdInt)
+		merged++
+	}
+	return merged, nil
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"main/internal/database"
+)
+
+func TestFindDuplicateCycles(t *testing.T) {
+	now := time.Now()
+	cycles := []*database.Cycle{
+		// Même achat Binance, l'un déjà en vente: le cycle en vente est le plus complet
+		{IdInt: 1, Exchange: "BINANCE", Status: "buy", BuyId: "1001", CreatedAt: now},
+		{IdInt: 2, Exchange: "BINANCE", Status: "sell", BuyId: "C02__1001", SellId: "2001", BuyFillPrice: 60000, CreatedAt: now},
+		// Même ID sur un autre exchange: pas un doublon
+		{IdInt: 3, Exchange: "KRAKEN", Status: "buy", BuyId: "1001", CreatedAt: now},
+		// Déjà écarté par --dedupe
+		{IdInt: 4, Exchange: "BINANCE", Status: "cancelled", BuyId: "1001", CancelReason: database.CancelReasonDuplicate, CreatedAt: now},
+	}
+
+	groups := findDuplicateCycles(cycles)
+	if len(groups) != 1 {
+		t.Fatalf("%d groupe(s) de doublons, attendu 1: %+v", len(groups), groups)
+	}
+	group := groups[0]
+	if group.Exchange != "BINANCE" || group.Side != "buy" || group.OrderId != "1001" ||
+		len(group.Cycles) != 2 || group.Cycles[0].IdInt != 1 || group.Cycles[1].IdInt != 2 {
+		t.Fatalf("groupe inattendu: %+v", group)
+	}
+	if keep := preferredDuplicate(group.Cycles); keep.IdInt != 2 {
+		t.Fatalf("cycle conservé %d, attendu le cycle 2 (le plus complet)", keep.IdInt)
+	}
+}
+
+func TestMergeDuplicatesAndUniqueBuyId(t *testing.T) {
+	repo := database.GetRepository()
+	save := func(cycle *database.Cycle) *database.Cycle {
+		t.Helper()
+		if _, err := repo.Save(cycle); err != nil {
+			t.Fatalf("enregistrement du cycle: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+		return cycle
+	}
+
+	first := save(&database.Cycle{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyId: "7001", SellId: "7101"})
+
+	// Un second cycle sur le même achat est refusé à l'enregistrement
+	_, err := repo.Save(&database.Cycle{Exchange: "BINANCE", Status: "buy", Quantity: 0.001, BuyId: "7001"})
+	if !errors.Is(err, database.ErrDuplicateBuyId) {
+		t.Fatalf("doublon d'achat enregistré: %v", err)
+	}
+	// Le même ID d'ordre reste possible sur un autre exchange
+	save(&database.Cycle{Exchange: "KRAKEN", Status: "buy", Quantity: 0.001, BuyId: "7001"})
+
+	// Doublon sur la vente seule (achat différent): fusionné sans toucher à l'exchange
+	second := save(&database.Cycle{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyId: "7002", SellId: "7101"})
+	groups := findDuplicateCycles([]*database.Cycle{first, second})
+	if len(groups) != 1 || groups[0].Side != "sell" {
+		t.Fatalf("groupes de doublons: %+v", groups)
+	}
+	merged, err := mergeDuplicates(repo, first, groups[0].Cycles)
+	if err != nil || merged != 1 {
+		t.Fatalf("fusion: %d cycle(s) annulé(s), %v", merged, err)
+	}
+
+	stored, err := repo.FindByIdInt(second.IdInt)
+	if err != nil || stored == nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "cancelled" || stored.CancelReason != database.CancelReasonDuplicate {
+		t.Fatalf("doublon %q (%q), attendu cancelled (duplicate)", stored.Status, stored.CancelReason)
+	}
+	if kept, _ := repo.FindByIdInt(first.IdInt); kept.Status != "sell" {
+		t.Fatalf("cycle conservé %q, attendu sell", kept.Status)
+	}
+	if groups := findDuplicateCycles([]*database.Cycle{first, stored}); len(groups) != 0 {
+		t.Fatalf("doublon encore signalé après la fusion: %+v", groups)
+	}
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+
+	"main/internal/config"
+	"main/internal/desktop"
+)
+
+// desktopEvents associe les actions de trading aux événements de DESKTOP_NOTIFY_EVENTS
+var desktopEvents = map[string]string{
+	"sell_filled": config.DesktopEventCycleCompleted,
+	"loss_limit":  config.DesktopEventLossLimit,
+}
+
+// desktopNotifier affiche les cycles complétés et les limites de pertes atteintes en
+// notification de bureau. Si le système ne le permet pas, le canal se désactive après
+// avoir signalé l'échec une seule fois.
+type desktopNotifier struct {
+	cfg *config.Config
+
+	mu          sync.Mutex
+	unavailable bool
+}
+
+func newDesktopNotifier(c *config.Config) *desktopNotifier {
+	return &desktopNotifier{cfg: c}
+}
+
+func (d *desktopNotifier) Name() string {
+	return "desktop"
+}
+
+func (d *desktopNotifier) Notify(n Notification) error {
+	event, ok := desktopEvents[n.Event]
+	if !ok || !d.cfg.DesktopNotifies(event) {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.unavailable {
+		return nil
+	}
+
+	title := "Bot Spot"
+	if n.Exchange != "" {
+		title += " - " + n.Exchange
+	}
+	err := desktop.Notify(title, n.Message)
+	if errors.Is(err, desktop.ErrUnavailable) {
+		d.unavailable = true
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"main/internal/database"
+	"main/internal/fx"
+)
+
+// fxRatesFile est le cache local des taux de change, à côté de la base de données
+const fxRatesFile = "fx_rates.json"
+
+// Âge maximal du cache des taux (la BCE publie une fois par jour ouvré) et délai avant de
+// relire le cache ou de retenter un téléchargement en échec
+const (
+	fxRatesMaxAge = 24 * time.Hour
+	fxReloadDelay = time.Hour
+)
+
+var (
+	fxMu       sync.Mutex
+	fxRates    *fx.Rates
+	fxSource   string
+	fxLoadedAt time.Time
+)
+
+// displayConversion est un montant en USDC converti dans la devise d'affichage (DISPLAY_CURRENCY)
+type displayConversion struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+	Rate     float64 `json:"rate"`     // 1 USDC = Rate devise
+	RateDate string  `json:"rateDate"` // date de publication du taux (AAAA-MM-JJ)
+}
+
+// displayCurrency retourne la devise d'affichage configurée, vide sans conversion
+func displayCurrency() string {
+	if cfg == nil {
+		return ""
+	}
+	return cfg.DisplayCurrency
+}
+
+// displayRates retourne les taux de change de la source configurée, nil si aucune devise
+// d'affichage n'est configurée ou si aucun taux n'est disponible
+func displayRates() *fx.Rates {
+	if displayCurrency() == "" {
+		return nil
+	}
+
+	fxMu.Lock()
+	defer fxMu.Unlock()
+	if fxSource == cfg.FXSourceURL && time.Since(fxLoadedAt) < fxReloadDelay {
+		return fxRates
+	}
+
+	cachePath := filepath.Join(filepath.Dir(database.GetDatabasePath()), fxRatesFile)
+	rates, err := fx.Load(cachePath, cfg.FXSourceURL, fxRatesMaxAge)
+	if err != nil {
+		log.Printf("Taux de change indisponibles, montants affichés en USDC seulement: %v", err)
+	}
+	fxRates, fxSource, fxLoadedAt = rates, cfg.FXSourceURL, time.Now()
+	return fxRates
+}
+
+// convertForDisplay convertit un montant en USDC au taux publié à la date indiquée (date de
+// complétion d'un profit réalisé, maintenant pour une position ouverte). Le booléen est faux
+// sans devise d'affichage ou sans taux.
+func convertForDisplay(amountUSDC float64, at time.Time) (displayConversion, bool) {
+	rates := displayRates()
+	if rates == nil {
+		return displayConversion{}, false
+	}
+	rate, err := rates.USDCRate(displayCurrency(), at)
+	if err != nil {
+		return displayConversion{}, false
+	}
+	return displayConversion{
+		Currency: rate.Currency,
+		Amount:   rate.Convert(amountUSDC),
+		Rate:     rate.Value,
+		RateDate: rate.Date.Format("2006-01-02"),
+	}, true
+}
+
+// displayValue retourne la conversion pour les templates et les réponses JSON, nil sans conversion
+func displayValue(amountUSDC float64, at time.Time) *displayConversion {
+	conversion, ok := convertForDisplay(amountUSDC, at)
+	if !ok {
+		return nil
+	}
+	return &conversion
+}
+
+// realizedAt retourne la date de réalisation du profit d'un cycle complété: sa date de
+// complétion, ou sa date de création si elle est inconnue
+func realizedAt(cycle *database.Cycle) time.Time {
+	if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+		return completedAt
+	}
+	return cycle.CreatedAt
+}
+
+// realizedDisplayTotal additionne, dans la devise d'affichage, les profits des cycles complétés
+// convertis chacun au taux de sa date de complétion. Le taux retourné est le dernier publié,
+// indiqué à titre de référence.
+func realizedDisplayTotal(cycles []*database.Cycle, profit func(cycle *database.Cycle) float64) *displayConversion {
+	latest := displayValue(0, time.Now())
+	if latest == nil {
+		return nil
+	}
+	for _, cycle := range cycles {
+		if cycle.Status != "completed" {
+			continue
+		}
+		if conversion, ok := convertForDisplay(profit(cycle), realizedAt(cycle)); ok {
+			latest.Amount += conversion.Amount
+		}
+	}
+	return latest
+}
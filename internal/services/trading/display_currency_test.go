@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/fx"
+)
+
+// useDisplayRates active la devise d'affichage avec des taux fixés, sans téléchargement
+func useDisplayRates(t *testing.T, currency string, days map[string]map[string]float64) {
+	t.Helper()
+	cfg.DisplayCurrency, cfg.FXSourceURL = currency, "test://fx"
+
+	fxMu.Lock()
+	fxRates, fxSource, fxLoadedAt = fx.NewRates("test://fx", days), "test://fx", time.Now()
+	fxMu.Unlock()
+	t.Cleanup(func() {
+		fxMu.Lock()
+		fxRates, fxSource, fxLoadedAt = nil, "", time.Time{}
+		fxMu.Unlock()
+	})
+}
+
+func TestDisplayCurrencyConversion(t *testing.T) {
+	useMockExchange(t, config.ExchangeConfig{}, 60000)
+	useDisplayRates(t, "EUR", map[string]map[string]float64{
+		"2025-01-03": {"USD": 1.25},
+		"2025-03-03": {"USD": 1.0},
+	})
+
+	day := func(value string) time.Time {
+		parsed, _ := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+		return parsed
+	}
+	cycles := []*database.Cycle{
+		// 10 USDC réalisés un dimanche: taux du vendredi 3 janvier (1 EUR = 1.25 USD)
+		{Status: "completed", Quantity: 0.001, BuyPrice: 60000, SellPrice: 70000, CreatedAt: day("2025-01-02 10:00"), CompletedAt: day("2025-01-05 10:00")},
+		// 5 USDC réalisés en mars, au taux de 1
+		{Status: "completed", Quantity: 0.001, BuyPrice: 60000, SellPrice: 65000, CreatedAt: day("2025-03-01 10:00"), CompletedAt: day("2025-03-04 10:00")},
+		// Cycle en vente: ignoré dans les profits réalisés
+		{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyPrice: 60000, SellPrice: 61000, CreatedAt: day("2025-03-01 10:00")},
+	}
+
+	total := realizedDisplayTotal(cycles, cycleGrossProfit)
+	if total == nil {
+		t.Fatal("profit total non converti")
+	}
+	if math.Abs(total.Amount-13) > 1e-9 || total.Currency != "EUR" || total.RateDate != "2025-03-03" {
+		t.Errorf("profit converti %.4f %s (taux du %s), attendu 13 EUR au dernier taux du 2025-03-03",
+			total.Amount, total.Currency, total.RateDate)
+	}
+	if byYear := calculateDisplayProfitsByTaxYear(cycles); math.Abs(byYear[2025]-13) > 1e-9 {
+		t.Errorf("profits 2025 convertis: %.4f, attendu 13", byYear[2025])
+	}
+
+	// Position ouverte: dernier taux publié
+	dto := convertCycleToDTO(cycles[2])
+	addUnrealizedToDTO(dto, cycles[2], map[string]float64{"BINANCE": 62000})
+	if conversion, ok := dto["unrealizedDisplay"].(*displayConversion); !ok || conversion == nil || conversion.RateDate != "2025-03-03" {
+		t.Errorf("P&L latent converti: %+v", dto["unrealizedDisplay"])
+	}
+
+	// Sans devise d'affichage, rien n'est converti
+	cfg.DisplayCurrency = ""
+	if total := realizedDisplayTotal(cycles, cycleGrossProfit); total != nil {
+		t.Errorf("conversion sans devise d'affichage: %+v", total)
+	}
+}
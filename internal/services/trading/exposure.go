@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+// minCycleUSDC est le montant minimal d'un nouveau cycle
+const minCycleUSDC = 10.0
+
+// ErrCycleSkipped indique qu'aucun cycle n'a été créé parce qu'une limite d'exposition est atteinte
+var ErrCycleSkipped = errors.New("création du cycle ignorée")
+
+// openExposure décrit les cycles encore ouverts (achat ou vente en cours) d'un exchange
+type openExposure struct {
+	Cycles int
+	USDC   float64
+}
+
+// computeOpenExposure calcule l'exposition actuelle des cycles ouverts d'un exchange
+func computeOpenExposure(exchange string) (openExposure, error) {
+	var exposure openExposure
+
+	cycles, err := database.GetRepository().FindByStatus("buy", "sell", database.StatusCancelPending)
+	if err != nil {
+		return exposure, fmt.Errorf("erreur lors de la récupération des cycles: %w", err)
+	}
+
+	for _, cycle := range cycles {
+		if cycle.Exchange != exchange {
+			continue
+		}
+		exposure.Cycles++
+		exposure.USDC += cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+
+	return exposure, nil
+}
+
+// applyExposureCaps retourne le montant autorisé pour un nouveau cycle. Le montant
+// est réduit pour respecter MaxOpenExposureUSDC; ErrCycleSkipped est renvoyée lorsque
+// MaxOpenCycles est atteint ou que la marge restante est inférieure au minimum.
+func applyExposureCaps(exchange string, exchangeConfig config.ExchangeConfig, amountUSDC float64) (float64, error) {
+	if exchangeConfig.MaxOpenExposureUSDC <= 0 && exchangeConfig.MaxOpenCycles <= 0 {
+		return amountUSDC, nil
+	}
+
+	exposure, err := computeOpenExposure(exchange)
+	if err != nil {
+		return 0, err
+	}
+
+	if exchangeConfig.MaxOpenCycles > 0 && exposure.Cycles >= exchangeConfig.MaxOpenCycles {
+		return 0, fmt.Errorf("%w: %d cycle(s) ouvert(s) sur %s, limite %s_MAX_OPEN_CYCLES=%d atteinte",
+			ErrCycleSkipped, exposure.Cycles, exchange, exchange, exchangeConfig.MaxOpenCycles)
+	}
+
+	if exchangeConfig.MaxOpenExposureUSDC > 0 {
+		remaining := exchangeConfig.MaxOpenExposureUSDC - exposure.USDC
+		if remaining < minCycleUSDC {
+			return 0, fmt.Errorf("%w: exposition ouverte de %.2f USDC sur %s, limite %s_MAX_OPEN_EXPOSURE_USDC=%.2f atteinte",
+				ErrCycleSkipped, exposure.USDC, exchange, exchange, exchangeConfig.MaxOpenExposureUSDC)
+		}
+		if amountUSDC > remaining {
+			return remaining, nil
+		}
+	}
+
+	return amountUSDC, nil
+}
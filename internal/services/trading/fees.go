@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// Taux de frais lus sur le compte (FETCH_FEE_RATES), prioritaires sur la configuration.
+// La lecture n'est tentée qu'une fois par exchange et par processus.
+var (
+	accountFeeRatesMu      sync.Mutex
+	accountFeeRates        = make(map[string]common.FeeRates)
+	accountFeeRatesFetched = make(map[string]bool)
+	feeAssetChecked        = make(map[string]bool)
+)
+
+// exchangeFeeRates retourne les taux maker et taker d'un exchange: niveau réel du compte
+// s'il a été lu, sinon taux configurés, sinon niveau de base de l'exchange
+func exchangeFeeRates(exchange string) common.FeeRates {
+	exchange = strings.ToUpper(exchange)
+
+	accountFeeRatesMu.Lock()
+	rates, fetched := accountFeeRates[exchange]
+	accountFeeRatesMu.Unlock()
+	if fetched {
+		return rates
+	}
+
+	if cfg != nil {
+		if exchangeConfig, ok := cfg.Exchanges[exchange]; ok {
+			return common.FeeRates{Maker: exchangeConfig.MakerFeeRate, Taker: exchangeConfig.TakerFeeRate}
+		}
+	}
+	maker, taker := config.DefaultFeeRates(exchange)
+	return common.FeeRates{Maker: maker, Taker: taker}
+}
+
+// Frais des ordres déjà lus sur l'exchange, par exchange et ID, pour la durée du processus
+var (
+	orderFeesMu    sync.Mutex
+	orderFeesCache = make(map[string]float64)
+)
+
+// cachedOrderFees retourne les frais d'un ordre en ne les demandant qu'une fois à l'exchange.
+// Les frais sont signés (négatifs pour une remise maker); des frais nuls ne sont pas mémorisés:
+// un ordre sans frais connus sera relu.
+func cachedOrderFees(client common.Exchange, exchange, orderId string) (float64, error) {
+	key := strings.ToUpper(exchange) + "/" + orderId
+
+	orderFeesMu.Lock()
+	fees, cached := orderFeesCache[key]
+	orderFeesMu.Unlock()
+	if cached {
+		return fees, nil
+	}
+
+	fees, err := client.GetOrderFees(orderId)
+	if err != nil {
+		return 0, err
+	}
+	if fees != 0 {
+		orderFeesMu.Lock()
+		orderFeesCache[key] = fees
+		orderFeesMu.Unlock()
+	}
+	return fees, nil
+}
+
+// cycleBuyFees retourne les frais d'achat d'un cycle actif pour l'affichage. Les frais réels déjà
+// enregistrés sur un cycle en vente sont repris sans appel à l'exchange; à défaut ils sont lus
+// une fois puis enregistrés sur le cycle, car ils ne changent plus après l'exécution de l'achat.
+// Un achat non exécuté n'a pas encore de frais: ils sont estimés au taux maker.
+func cycleBuyFees(cycle *database.Cycle, usdcAmount float64) float64 {
+	estimate := usdcAmount * getFeeRateForExchange(cycle.Exchange)
+	if cycle.Status != "sell" {
+		return estimate
+	}
+	if cycle.TotalFees != 0 && !cycle.FeesEstimated {
+		return cycle.TotalFees
+	}
+
+	cleanBuyId := cleanOrderId(cycle.BuyId, cycle.Exchange)
+	if cleanBuyId == "" {
+		return estimate
+	}
+	var client common.Exchange
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				color.Red(i18n.T("update.client_init_error"), cycle.Exchange, r)
+			}
+		}()
+		client = GetClientByExchange(cycle.Exchange)
+	}()
+	if client == nil {
+		return estimate
+	}
+
+	fees, err := cachedOrderFees(client, cycle.Exchange, cleanBuyId)
+	if err != nil || fees == 0 {
+		return estimate
+	}
+
+	// Les frais d'un achat renforcé (--average-down) incluent ceux des achats fusionnés
+	if cycle.AverageDownCount == 0 {
+		err := database.GetRepository().UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+			"buyFees":       fees,
+			"totalFees":     fees,
+			"feesEstimated": false,
+		})
+		if err == nil {
+			cycle.BuyFees = fees
+			cycle.TotalFees = fees
+			cycle.FeesEstimated = false
+		}
+	}
+	return fees
+}
+
+// logFees journalise les frais récupérés d'un ordre, ou la remise maker reçue s'ils sont négatifs
+func logFees(ev *tradeEvent, feesKey, rebateKey string, fees float64) {
+	if fees < 0 {
+		ev.success(i18n.T(rebateKey), -fees)
+		return
+	}
+	ev.success(i18n.T(feesKey), fees)
+}
+
+// getFeeRateForExchange retourne le taux de frais maker d'un exchange, utilisé pour les
+// estimations (les ordres du bot sont placés en maker)
+func getFeeRateForExchange(exchange string) float64 {
+	return exchangeFeeRates(exchange).Maker
+}
+
+// applyFeeRates transmet les taux de frais de l'exchange au client. Si FETCH_FEE_RATES est
+// activé et que l'exchange expose le niveau du compte, celui-ci est lu et remplace les taux
+// configurés; un échec de lecture n'est pas bloquant.
+func applyFeeRates(exchange string, client common.Exchange) {
+	setter, ok := client.(interface{ SetFeeRates(common.FeeRates) })
+	if !ok {
+		return
+	}
+
+	if cfg.Exchanges[exchange].FetchFeeRates {
+		if provider, ok := client.(common.FeeRateProvider); ok {
+			fetchAccountFeeRates(exchange, provider)
+		}
+	}
+	setter.SetFeeRates(exchangeFeeRates(exchange))
+}
+
+// warnFeeAsset signale, une fois par processus, que l'exchange prélève les frais dans un autre
+// actif (BNB sur Binance): ils sont convertis en USDC au prix de l'exécution, et le solde de
+// cet actif doit rester suffisant. Un échec de la vérification est ignoré.
+func warnFeeAsset(exchange string, client common.Exchange) {
+	provider, ok := client.(common.FeeAssetProvider)
+	if !ok {
+		return
+	}
+	accountFeeRatesMu.Lock()
+	defer accountFeeRatesMu.Unlock()
+	if feeAssetChecked[exchange] {
+		return
+	}
+	feeAssetChecked[exchange] = true
+
+	if asset, err := provider.FeeDiscountAsset(); err == nil && asset != "" {
+		color.Yellow(i18n.T("update.fee_asset_enabled"), exchange, asset, asset)
+	}
+}
+
+// fetchAccountFeeRates lit le niveau de frais du compte, une seule fois par processus
+func fetchAccountFeeRates(exchange string, provider common.FeeRateProvider) {
+	accountFeeRatesMu.Lock()
+	defer accountFeeRatesMu.Unlock()
+	if accountFeeRatesFetched[exchange] {
+		return
+	}
+	accountFeeRatesFetched[exchange] = true
+
+	rates, err := provider.GetAccountFeeRates()
+	if err != nil {
+		color.Yellow(i18n.T("update.fee_rates_fetch_error"), exchange, err)
+		return
+	}
+	accountFeeRates[exchange] = rates
+	color.Cyan(i18n.T("update.fee_rates_fetched"), exchange, rates.Maker*100, rates.Taker*100)
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"main/internal/database"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// Fsck vérifie l'intégrité de la base document par document, sans la modifier (--fsck). Le code
+// de sortie vaut 1 si la base est endommagée: sa prochaine ouverture la récupérera.
+func Fsck() {
+	report := database.CheckIntegrity("")
+	color.Cyan(i18n.T("fsck.checking"), report.Path)
+
+	if report.Locked {
+		color.Red(i18n.T("fsck.locked"))
+		os.Exit(1)
+	}
+	if report.Err != nil {
+		color.Red(i18n.T("fsck.unreadable"), report.Err)
+	}
+
+	names := make([]string, 0, len(report.Collections))
+	for name := range report.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		counts := report.Collections[name]
+		if counts.Damaged > 0 {
+			color.Red(i18n.T("fsck.collection_damaged"), name, counts.Readable, counts.Damaged)
+		} else {
+			color.White(i18n.T("fsck.collection_ok"), name, counts.Readable)
+		}
+	}
+
+	if len(report.Backups) == 0 {
+		color.Yellow(i18n.T("fsck.no_backup"))
+	} else {
+		color.White(i18n.T("fsck.backups"), len(report.Backups), filepath.Base(report.Backups[0]))
+	}
+
+	if !report.Healthy() {
+		color.Red(i18n.T("fsck.damaged"))
+		os.Exit(1)
+	}
+	color.Green(i18n.T("fsck.healthy"))
+}
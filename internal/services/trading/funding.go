@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"main/internal/database"
+	"main/internal/exchanges/binance"
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/kraken"
+	"main/internal/exchanges/kucoin"
+	"main/internal/exchanges/mexc"
+
+	"github.com/buger/jsonparser"
+	"github.com/fatih/color"
+)
+
+// fundingCheck résume la vérification des fonds USDC avant un ordre d'achat
+type fundingCheck struct {
+	Notional    float64 // prix * quantité
+	Fees        float64 // frais estimés sur l'achat
+	MinNotional float64 // montant minimum d'un ordre sur l'exchange (0 si inconnu)
+	Required    float64 // montant USDC nécessaire, frais compris
+	Free        float64 // solde USDC libre
+}
+
+// Missing retourne le montant USDC manquant (0 si les fonds suffisent)
+func (f fundingCheck) Missing() float64 {
+	return math.Max(0, f.Required-f.Free)
+}
+
+// BelowMinimum indique si l'ordre est inférieur au minimum accepté par l'exchange
+func (f fundingCheck) BelowMinimum() bool {
+	return f.MinNotional > 0 && f.Notional < f.MinNotional
+}
+
+// newFundingCheck calcule le montant nécessaire pour acheter quantity BTC au prix indiqué.
+// Un ordre sous le minimum de l'exchange devrait être porté à ce minimum: c'est lui qui est exigé.
+func newFundingCheck(price, quantity, feeRate, minNotional, free float64) fundingCheck {
+	notional := price * quantity
+	base := math.Max(notional, minNotional)
+	fees := base * feeRate
+	return fundingCheck{
+		Notional:    notional,
+		Fees:        fees,
+		MinNotional: minNotional,
+		Required:    base + fees,
+		Free:        free,
+	}
+}
+
+// maxAffordableQuantity retourne la plus grande quantité de BTC achetable avec le solde
+// libre, frais compris, arrondie à l'inférieur au pas de FormatSmallFloat (6 décimales)
+func maxAffordableQuantity(free, price, feeRate float64) float64 {
+	if free <= 0 || price <= 0 {
+		return 0
+	}
+	quantity := free / (price * (1 + feeRate))
+	return math.Floor(quantity*1e6) / 1e6
+}
+
+// hasMaxArg indique si --max a été passé pour dimensionner l'ordre au solde disponible
+func hasMaxArg() bool {
+	for _, arg := range GetAllArgs() {
+		if arg == "--max" {
+			return true
+		}
+	}
+	return false
+}
+
+// minOrderNotional retourne le montant minimum d'un ordre BTC/USDC d'après GetExchangeInfo (0 si inconnu)
+func minOrderNotional(client common.Exchange) float64 {
+	if guarded, ok := client.(*common.GuardedExchange); ok {
+		client = guarded.Exchange
+	}
+
+	switch c := client.(type) {
+	case *binance.Client:
+		if rules, err := c.GetSymbolRules("BTCUSDC"); err == nil {
+			return rules.MinNotional
+		}
+	case *kucoin.Client:
+		if rules, err := c.GetSymbolRules("BTC-USDC"); err == nil {
+			return rules.QuoteMinSize
+		}
+	case *mexc.Client:
+		info, err := c.GetExchangeInfo()
+		if err != nil {
+			return 0
+		}
+		var minNotional float64
+		_, _ = jsonparser.ArrayEach(info, func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+			if symbol, _ := jsonparser.GetString(value, "symbol"); symbol != "BTCUSDC" {
+				return
+			}
+			// MEXC expose le montant minimum d'un ordre dans quoteAmountPrecision
+			minStr, _ := jsonparser.GetString(value, "quoteAmountPrecision")
+			minNotional, _ = strconv.ParseFloat(minStr, 64)
+		}, "symbols")
+		return minNotional
+	case *kraken.Client:
+		info, err := c.GetExchangeInfo()
+		if err != nil {
+			return 0
+		}
+		var minNotional float64
+		_ = jsonparser.ObjectEach(info, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+			costMin, _ := jsonparser.GetString(value, "costmin")
+			minNotional, _ = strconv.ParseFloat(costMin, 64)
+			return nil
+		})
+		return minNotional
+	}
+	return 0
+}
+
+// printFundingShortfall détaille le montant manquant et les ordres d'achat ouverts qui bloquent des fonds
+func printFundingShortfall(client common.Exchange, exchange string, check fundingCheck) {
+	color.Red("Fonds insuffisants sur %s: %.2f USDC nécessaires, %.2f USDC libres, il manque %.2f USDC",
+		exchange, check.Required, check.Free, check.Missing())
+	color.White("  Montant de l'ordre: %.2f USDC", check.Notional)
+	color.White("  Frais estimés:      %.2f USDC", check.Fees)
+	if check.MinNotional > 0 {
+		color.White("  Minimum exchange:   %.2f USDC", check.MinNotional)
+	}
+
+	orders, err := client.GetOpenOrders()
+	if err != nil {
+		color.Yellow("Impossible de lister les ordres ouverts sur %s: %v", exchange, err)
+		return
+	}
+
+	// Rattacher les ordres d'achat ouverts aux cycles connus
+	cycleByOrder := make(map[string]int32)
+	if cycles, err := database.GetRepository().FindByStatus("buy", database.StatusCancelPending); err == nil {
+		for _, cycle := range cycles {
+			if cycle.Exchange == exchange {
+				cycleByOrder[cleanOrderId(cycle.BuyId, exchange)] = cycle.IdInt
+			}
+		}
+	}
+
+	var locked float64
+	var buyOrders []common.OpenOrder
+	for _, order := range orders {
+		if order.Side == "BUY" {
+			buyOrders = append(buyOrders, order)
+			locked += order.Price * order.Quantity
+		}
+	}
+	if len(buyOrders) == 0 {
+		color.White("Aucun ordre d'achat ouvert ne bloque de fonds sur %s", exchange)
+		return
+	}
+
+	color.Yellow("%d ordre(s) d'achat ouvert(s) bloquent %.2f USDC:", len(buyOrders), locked)
+	for _, order := range buyOrders {
+		label := "hors bot"
+		if id, ok := cycleByOrder[cleanOrderId(order.ID, exchange)]; ok {
+			label = fmt.Sprintf("cycle %d", id)
+		}
+		color.White("  %s: %.6f BTC à %.2f = %.2f USDC (%s)",
+			order.ID, order.Quantity, order.Price, order.Price*order.Quantity, label)
+	}
+	color.White("Annulez l'un de ces cycles (-c=ID), déposez des USDC ou relancez avec --max pour acheter la quantité disponible")
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFundingCheck(t *testing.T) {
+	const feeRate = 0.001
+
+	// 0.0015 BTC à 60000 = 90 USDC + 0.09 de frais pour 90 USDC libres
+	check := newFundingCheck(60000, 0.0015, feeRate, 5, 90)
+	if math.Abs(check.Missing()-0.09) > 1e-9 {
+		t.Errorf("Missing() = %f, attendu 0.09", check.Missing())
+	}
+	if check.BelowMinimum() {
+		t.Error("un ordre de 90 USDC ne devrait pas être sous le minimum de 5 USDC")
+	}
+
+	// Un ordre sous le minimum exige le minimum de l'exchange
+	small := newFundingCheck(60000, 0.00005, feeRate, 5, 4)
+	if !small.BelowMinimum() {
+		t.Error("un ordre de 3 USDC devrait être sous le minimum de 5 USDC")
+	}
+	if math.Abs(small.Required-5.005) > 1e-9 {
+		t.Errorf("Required = %f, attendu 5.005", small.Required)
+	}
+
+	// --max: la quantité finançable couvre les frais et reste au pas de 6 décimales
+	quantity := maxAffordableQuantity(90, 60000, feeRate)
+	if quantity != 0.001498 {
+		t.Errorf("maxAffordableQuantity() = %f, attendu 0.001498", quantity)
+	}
+	if newFundingCheck(60000, quantity, feeRate, 5, 90).Missing() > 0 {
+		t.Error("la quantité maximale ne devrait pas dépasser le solde libre")
+	}
+	if maxAffordableQuantity(0, 60000, feeRate) != 0 {
+		t.Error("aucune quantité n'est finançable sans solde")
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"main/internal/config"
+)
+
+// hookWaitDelay borne l'attente des sorties d'un hook arrêté à son délai: un processus enfant
+// qui les garderait ouvertes ne doit pas bloquer la mise à jour
+const hookWaitDelay = 2 * time.Second
+
+// hookNotifier lance la commande d'un hook (HOOKS) pour chaque événement retenu par son filtre,
+// avec la notification en JSON sur l'entrée standard et l'événement dans BOT_EVENT. Un échec
+// ou un dépassement du délai est journalisé par notify sans interrompre le trading.
+type hookNotifier struct {
+	hook config.Hook
+}
+
+func newHookNotifier(hook config.Hook) *hookNotifier {
+	return &hookNotifier{hook: hook}
+}
+
+func (h *hookNotifier) Name() string {
+	return "hook " + h.hook.Name
+}
+
+func (h *hookNotifier) Notify(n Notification) error {
+	if !h.hook.Accepts(n.Event) {
+		return nil
+	}
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("sérialisation de la notification: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.hook.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.hook.Command[0], h.hook.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = io.Discard
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "BOT_EVENT="+n.Event, "BOT_HOOK="+h.hook.Name)
+	cmd.WaitDelay = hookWaitDelay
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("arrêté après %s", h.hook.Timeout)
+	}
+	if err != nil {
+		if output := lastLine(stderr.String()); output != "" {
+			return fmt.Errorf("%v: %s", err, output)
+		}
+		return err
+	}
+	return nil
+}
+
+// lastLine retourne la dernière ligne non vide d'une sortie, l'erreur d'un script y figurant
+// le plus souvent
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
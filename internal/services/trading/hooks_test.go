@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+// TestHookHelperProcess n'est pas un test: c'est la commande lancée par les hooks des tests
+// ci-dessous (le binaire de test relancé avec BOT_HOOK_HELPER)
+func TestHookHelperProcess(t *testing.T) {
+	mode := os.Getenv("BOT_HOOK_HELPER")
+	if mode == "" {
+		return
+	}
+	switch mode {
+	case "record":
+		body, _ := io.ReadAll(os.Stdin)
+		os.WriteFile(os.Getenv("BOT_HOOK_OUTPUT"), []byte(os.Getenv("BOT_EVENT")+"\n"+string(body)), 0644)
+	case "fail":
+		os.Stderr.WriteString("feuille de calcul introuvable\n")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	}
+	os.Exit(0)
+}
+
+// newTestHook crée un hook qui relance le binaire de test dans le mode indiqué
+func newTestHook(t *testing.T, mode string, events []string) *hookNotifier {
+	t.Setenv("BOT_HOOK_HELPER", mode)
+	return newHookNotifier(config.Hook{
+		Name:    mode,
+		Command: []string{os.Args[0], "-test.run=TestHookHelperProcess"},
+		Events:  events,
+		Timeout: 5 * time.Second,
+	})
+}
+
+func TestHookReceivesEventOnStdin(t *testing.T) {
+	output := t.TempDir() + "/event.json"
+	t.Setenv("BOT_HOOK_OUTPUT", output)
+	hook := newTestHook(t, "record", []string{"sell_filled"})
+
+	cycle := &database.Cycle{IdInt: 12, Exchange: "BINANCE", Status: "completed"}
+	if err := hook.Notify(Notification{Event: "sell_filled", Exchange: "BINANCE", Message: "cycle complété", Timestamp: time.Now(), Cycle: cycle}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("le hook n'a pas été lancé: %v", err)
+	}
+	event, body, _ := strings.Cut(string(content), "\n")
+	var received Notification
+	if err := json.Unmarshal([]byte(body), &received); err != nil {
+		t.Fatalf("JSON reçu invalide: %v", err)
+	}
+	if event != "sell_filled" || received.Event != "sell_filled" || received.Cycle == nil || received.Cycle.IdInt != 12 {
+		t.Errorf("événement reçu %q: %+v", event, received)
+	}
+
+	// Un événement hors du filtre ne lance pas la commande
+	os.Remove(output)
+	if err := hook.Notify(Notification{Event: "buy_filled"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if _, err := os.Stat(output); err == nil {
+		t.Error("buy_filled ne devrait pas être transmis au hook")
+	}
+}
+
+func TestHookFailureAndTimeout(t *testing.T) {
+	err := newTestHook(t, "fail", nil).Notify(Notification{Event: "sell_filled"})
+	if err == nil || !strings.Contains(err.Error(), "feuille de calcul introuvable") {
+		t.Errorf("l'erreur du hook devrait citer sa sortie d'erreur: %v", err)
+	}
+
+	hook := newTestHook(t, "sleep", nil)
+	hook.hook.Timeout = 200 * time.Millisecond
+	start := time.Now()
+	err = hook.Notify(Notification{Event: "sell_filled"})
+	if err == nil || time.Since(start) > 5*time.Second {
+		t.Errorf("un hook trop long devrait être arrêté à son délai: %v après %s", err, time.Since(start))
+	}
+}
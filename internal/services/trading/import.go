@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+
+	"github.com/fatih/color"
+)
+
+// quantityEpsilon est la quantité de BTC en dessous de laquelle un lot est considéré comme soldé
+const quantityEpsilon = 1e-8
+
+// importLot regroupe les exécutions d'un même ordre
+type importLot struct {
+	OrderID   string
+	Side      string
+	Price     float64 // prix moyen pondéré
+	Quantity  float64
+	Remaining float64
+	FeesUSDC  float64
+	Time      time.Time // date de la première exécution
+}
+
+// importedPair est un couple achat/vente reconstitué
+type importedPair struct {
+	Buy      *importLot
+	Sell     *importLot
+	Quantity float64
+	Fees     float64
+}
+
+// Import reconstitue des cycles complétés à partir de l'historique des trades
+// d'un exchange: --import --exchange=binance --since=2024-01-01 [--dry-run]
+func Import(exchange string) {
+	since := time.Now().AddDate(0, -1, 0)
+	dryRun := false
+
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--exchange="):
+			exchange = strings.ToUpper(strings.TrimPrefix(arg, "--exchange="))
+		case strings.HasPrefix(arg, "--since="):
+			value := strings.TrimPrefix(arg, "--since=")
+			parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				color.Red("Date invalide: %s. Utilisez --since=AAAA-MM-JJ", value)
+				os.Exit(1)
+			}
+			since = parsed
+		case arg == "--dry-run":
+			dryRun = true
+		}
+	}
+
+	if exchange == "" {
+		color.Red("Exchange manquant. Utilisez --import --exchange=binance --since=2024-01-01")
+		os.Exit(1)
+	}
+
+	client := GetClientByExchange(exchange)
+
+	color.Cyan("Récupération de l'historique des trades %s depuis le %s...", exchange, since.Format("02/01/2006"))
+	trades, err := client.GetTradeHistory(since)
+	if err != nil {
+		color.Red("Erreur lors de la récupération de l'historique des trades: %v", err)
+		os.Exit(1)
+	}
+	color.White("%d exécution(s) récupérée(s)", len(trades))
+
+	// Ignorer les ordres déjà suivis par le bot
+	repo := database.GetRepository()
+	cycles, err := repo.FindAll()
+	if err != nil {
+		color.Red("Erreur lors de la récupération des cycles: %v", err)
+		os.Exit(1)
+	}
+	known := make(map[string]bool)
+	for _, cycle := range cycles {
+		if cycle.Exchange != exchange {
+			continue
+		}
+		known[orderKey(cycle.BuyId)] = true
+		known[orderKey(cycle.SellId)] = true
+	}
+
+	lots := groupTradesByOrder(trades, known)
+	pairs, openBuys, orphanSells := matchLotsFIFO(lots)
+
+	fmt.Println("")
+	if len(pairs) == 0 {
+		color.Yellow("Aucun couple achat/vente à importer pour %s.", exchange)
+	} else {
+		if dryRun {
+			color.Cyan("Cycles qui seraient créés (--dry-run):")
+		} else {
+			color.Cyan("Cycles importés:")
+		}
+		color.Cyan("%-22s %-22s %-12s %-12s %-12s %-10s", "Achat", "Vente", "Quantité", "Prix achat", "Prix vente", "Frais")
+	}
+
+	created := 0
+	for _, pair := range pairs {
+		color.White("%-22s %-22s %-12.8f %-12.2f %-12.2f %-10.4f",
+			pair.Buy.Time.Format("02/01/2006 15:04:05"),
+			pair.Sell.Time.Format("02/01/2006 15:04:05"),
+			pair.Quantity, pair.Buy.Price, pair.Sell.Price, pair.Fees)
+
+		if dryRun {
+			continue
+		}
+
+		cycle := &database.Cycle{
+			Exchange:    exchange,
+			Status:      "completed",
+			Quantity:    pair.Quantity,
+			BuyPrice:    pair.Buy.Price,
+			BuyId:       pair.Buy.OrderID,
+			SellPrice:   pair.Sell.Price,
+			SellId:      pair.Sell.OrderID,
+			CreatedAt:   pair.Buy.Time,
+			CompletedAt: pair.Sell.Time,
+			TotalFees:   pair.Fees,
+			Imported:    true,
+		}
+		if _, err := repo.Save(cycle); err != nil {
+			color.Red("Erreur lors de l'enregistrement du cycle importé: %v", err)
+			continue
+		}
+		created++
+	}
+
+	// Résumé
+	fmt.Println("")
+	for _, lot := range openBuys {
+		color.Yellow("Achat %s du %s non revendu: %.8f BTC restant(s), ignoré",
+			lot.OrderID, lot.Time.Format("02/01/2006"), lot.Remaining)
+	}
+	for _, lot := range orphanSells {
+		color.Yellow("Vente %s du %s sans achat correspondant: %.8f BTC, ignorée",
+			lot.OrderID, lot.Time.Format("02/01/2006"), lot.Remaining)
+	}
+
+	if dryRun {
+		color.Green("Simulation terminée: %d cycle(s) seraient créés. Relancez sans --dry-run pour les importer.", len(pairs))
+	} else {
+		color.Green("%d cycle(s) importé(s) sur %s", created, exchange)
+	}
+}
+
+// orderKey normalise un ID d'ordre pour comparer l'historique aux cycles existants
+func orderKey(orderId string) string {
+	return strings.TrimPrefix(strings.TrimSpace(orderId), "C02__")
+}
+
+// groupTradesByOrder regroupe les exécutions par ordre, en ignorant les ordres connus
+func groupTradesByOrder(trades []common.Trade, known map[string]bool) []*importLot {
+	var lots []*importLot
+	byOrder := make(map[string]*importLot)
+
+	for _, trade := range trades {
+		if known[orderKey(trade.OrderID)] || trade.Quantity <= 0 {
+			continue
+		}
+
+		lot, exists := byOrder[trade.OrderID]
+		if !exists {
+			lot = &importLot{OrderID: trade.OrderID, Side: trade.Side, Time: trade.Time}
+			byOrder[trade.OrderID] = lot
+			lots = append(lots, lot)
+		}
+
+		// Prix moyen pondéré par la quantité
+		total := lot.Price*lot.Quantity + trade.Price*trade.Quantity
+		lot.Quantity += trade.Quantity
+		lot.Remaining = lot.Quantity
+		lot.Price = total / lot.Quantity
+		lot.FeesUSDC += tradeFeeUSDC(trade)
+	}
+
+	return lots
+}
+
+// tradeFeeUSDC convertit les frais d'une exécution en USDC lorsque c'est possible
+func tradeFeeUSDC(trade common.Trade) float64 {
+	switch strings.ToUpper(trade.FeeAsset) {
+	case "USDC", "USD", "ZUSD", "":
+		return trade.Fee
+	case "BTC", "XBT", "XXBT":
+		return trade.Fee * trade.Price
+	default:
+		// Frais payés dans un autre actif (BNB, KCS, MX...): non convertis
+		return 0
+	}
+}
+
+// matchLotsFIFO apparie chaque vente aux achats les plus anciens encore ouverts.
+// Retourne les couples, les achats non soldés et les ventes sans achat correspondant.
+func matchLotsFIFO(lots []*importLot) ([]importedPair, []*importLot, []*importLot) {
+	var pairs []importedPair
+	var openBuys []*importLot
+	var orphanSells []*importLot
+
+	for _, lot := range lots {
+		if lot.Side == "BUY" {
+			openBuys = append(openBuys, lot)
+			continue
+		}
+
+		for lot.Remaining > quantityEpsilon && len(openBuys) > 0 {
+			buy := openBuys[0]
+			quantity := math.Min(buy.Remaining, lot.Remaining)
+
+			// Répartir les frais au prorata de la quantité appariée
+			fees := buy.FeesUSDC*quantity/buy.Quantity + lot.FeesUSDC*quantity/lot.Quantity
+
+			pairs = append(pairs, importedPair{Buy: buy, Sell: lot, Quantity: quantity, Fees: fees})
+			buy.Remaining -= quantity
+			lot.Remaining -= quantity
+
+			if buy.Remaining <= quantityEpsilon {
+				openBuys = openBuys[1:]
+			}
+		}
+
+		if lot.Remaining > quantityEpsilon {
+			orphanSells = append(orphanSells, lot)
+		}
+	}
+
+	return pairs, openBuys, orphanSells
+}
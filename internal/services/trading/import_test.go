@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+)
+
+// Un achat revendu en deux fois forme deux cycles complétés sur le même ordre d'achat: ils sont
+// tous deux enregistrés et ne sont pas signalés comme doublons
+func TestImportSplitBuy(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{}, 62000)
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	mock.Trades = []common.Trade{
+		{ID: "1", OrderID: "9001", Side: "BUY", Price: 60000, Quantity: 0.0012, Fee: 0.072, FeeAsset: "USDC", Time: at},
+		{ID: "2", OrderID: "9001", Side: "BUY", Price: 60000, Quantity: 0.0008, Fee: 0.048, FeeAsset: "USDC", Time: at.Add(time.Minute)},
+		{ID: "3", OrderID: "9101", Side: "SELL", Price: 61000, Quantity: 0.001, Fee: 0.061, FeeAsset: "USDC", Time: at.Add(time.Hour)},
+		{ID: "4", OrderID: "9102", Side: "SELL", Price: 62000, Quantity: 0.001, Fee: 0.062, FeeAsset: "USDC", Time: at.Add(2 * time.Hour)},
+	}
+
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = []string{"bot", "--import", "--exchange=binance", "--since=2024-01-01"}
+
+	Import("")
+
+	repo := database.GetRepository()
+	cycles, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("lecture des cycles: %v", err)
+	}
+	var imported []*database.Cycle
+	for _, cycle := range cycles {
+		if cycle.Imported && cycle.BuyId == "9001" {
+			imported = append(imported, cycle)
+			t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+		}
+	}
+	if len(imported) != 2 {
+		t.Fatalf("%d cycle(s) importé(s) sur l'achat 9001, attendu 2", len(imported))
+	}
+	for _, cycle := range imported {
+		if cycle.Status != "completed" || cycle.Quantity < 0.00099999 || cycle.Quantity > 0.00100001 {
+			t.Errorf("cycle importé inattendu: %+v", cycle)
+		}
+	}
+	if groups := findDuplicateCycles(imported); len(groups) != 0 {
+		t.Errorf("cycles importés signalés comme doublons: %+v", groups)
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ladderParams retourne le nombre de tranches et l'écart entre tranches (en %) d'un nouveau
+// cycle: <EXCHANGE>_LADDER_COUNT et <EXCHANGE>_LADDER_STEP_PERCENT, remplacés par
+// --ladder=N et --ladder-step=P en ligne de commande
+func ladderParams(exchange string) (int, float64) {
+	exchangeConfig := cfg.Exchanges[exchange]
+	count, step := exchangeConfig.LadderCount, exchangeConfig.LadderStepPercent
+
+	for _, arg := range GetAllArgs() {
+		if value, ok := strings.CutPrefix(arg, "--ladder="); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				color.Yellow("--ladder=%s ignoré: nombre de tranches invalide", value)
+				continue
+			}
+			count = n
+		}
+		if value, ok := strings.CutPrefix(arg, "--ladder-step="); ok {
+			p, err := strconv.ParseFloat(value, 64)
+			if err != nil || p <= 0 || p >= 100 {
+				color.Yellow("--ladder-step=%s ignoré: écart invalide", value)
+				continue
+			}
+			step = p
+		}
+	}
+
+	if count < 1 {
+		count = 1
+	}
+	return count, step
+}
+
+// ladderPrices retourne les prix d'achat des tranches: la première au prix d'achat,
+// chacune des suivantes stepPercent % plus bas que le prix d'achat par tranche
+func ladderPrices(buyPrice float64, count int, stepPercent float64) []float64 {
+	prices := make([]float64, count)
+	for i := range prices {
+		prices[i] = buyPrice * (1 - float64(i)*stepPercent/100)
+	}
+	return prices
+}
+
+// ladderTrancheCount réduit le nombre de tranches pour que chacune atteigne le montant
+// minimum d'un ordre sur l'exchange (minNotional = 0: pas de réduction)
+func ladderTrancheCount(count int, notional, minNotional float64) int {
+	if count <= 1 || minNotional <= 0 {
+		return count
+	}
+	affordable := int(math.Floor(notional / minNotional))
+	if affordable < 1 {
+		return 1
+	}
+	if affordable < count {
+		return affordable
+	}
+	return count
+}
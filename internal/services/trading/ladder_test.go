@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLadderPrices(t *testing.T) {
+	prices := ladderPrices(60000, 3, 0.5)
+	expected := []float64{60000, 59700, 59400}
+	for i := range expected {
+		if math.Abs(prices[i]-expected[i]) > 1e-6 {
+			t.Errorf("tranche %d: prix %.2f, attendu %.2f", i, prices[i], expected[i])
+		}
+	}
+
+	// Chaque tranche doit atteindre le minimum de l'exchange
+	if count := ladderTrancheCount(3, 25, 10); count != 2 {
+		t.Errorf("ladderTrancheCount(3, 25, 10) = %d, attendu 2", count)
+	}
+	if count := ladderTrancheCount(3, 5, 10); count != 1 {
+		t.Errorf("ladderTrancheCount(3, 5, 10) = %d, attendu 1", count)
+	}
+	if count := ladderTrancheCount(3, 25, 0); count != 3 {
+		t.Errorf("sans minimum connu, le nombre de tranches ne doit pas changer (obtenu %d)", count)
+	}
+}
+
+func TestGroupCycleDTOs(t *testing.T) {
+	dto := func(id, groupId int32, buyTotal float64) map[string]interface{} {
+		return map[string]interface{}{"idInt": id, "groupId": groupId, "quantity": 0.001,
+			"buyTotal": buyTotal, "sellTotal": 0.0, "profit": 0.0, "groupSubtotal": nil}
+	}
+
+	// Tri par ID décroissant: le cycle 11 s'intercale entre les tranches du groupe 10
+	grouped := groupCycleDTOs([]map[string]interface{}{
+		dto(12, 10, 59.4), dto(11, 0, 90), dto(10, 10, 60),
+	})
+
+	order := []int32{12, 10, 11}
+	for i, id := range order {
+		if grouped[i]["idInt"] != id {
+			t.Fatalf("position %d: cycle %v, attendu %d", i, grouped[i]["idInt"], id)
+		}
+	}
+	if grouped[0]["groupSubtotal"] != nil {
+		t.Error("le sous-total ne doit suivre que la dernière tranche du groupe")
+	}
+	subtotal, ok := grouped[1]["groupSubtotal"].(map[string]interface{})
+	if !ok {
+		t.Fatal("la dernière tranche devrait porter le sous-total du groupe")
+	}
+	if subtotal["count"] != 2 || math.Abs(subtotal["buyTotal"].(float64)-119.4) > 1e-9 {
+		t.Errorf("sous-total inattendu: %v", subtotal)
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"main/internal/exchanges/common"
+
+	"github.com/fatih/color"
+)
+
+// LatencyReportRequested indique si --latency-report figure parmi les arguments
+func LatencyReportRequested() bool {
+	for _, arg := range GetAllArgs() {
+		if arg == "--latency-report" {
+			return true
+		}
+	}
+	return false
+}
+
+// LatencyReport affiche, pour les requêtes envoyées aux exchanges pendant l'exécution, le nombre
+// d'appels, le taux d'erreur et les latences p50/p95 de chaque exchange puis de chaque endpoint:
+// -u --latency-report
+func LatencyReport() {
+	totals := common.Latency.Totals()
+	fmt.Println("")
+	color.Cyan("=== Latence des exchanges (exécution en cours) ===")
+	if len(totals) == 0 {
+		fmt.Println("Aucune requête envoyée aux exchanges")
+		return
+	}
+
+	endpoints := common.Latency.Snapshot()
+	for _, total := range totals {
+		line := color.GreenString
+		if total.Errors > 0 {
+			line = color.YellowString
+		}
+		fmt.Println(line("%-8s %-40s %s", total.Exchange, "total", formatLatency(total)))
+		for _, endpoint := range endpoints {
+			if endpoint.Exchange == total.Exchange {
+				fmt.Printf("%-8s %-40s %s\n", "", endpoint.Endpoint, formatLatency(endpoint))
+			}
+		}
+	}
+}
+
+// formatLatency présente les compteurs et les percentiles d'un agrégat sur une ligne
+func formatLatency(stats common.EndpointLatency) string {
+	return fmt.Sprintf("%5d appel(s)  erreurs %5.1f%%  p50 %7s  p95 %7s",
+		stats.Calls, stats.ErrorRate*100, stats.P50.Round(time.Millisecond), stats.P95.Round(time.Millisecond))
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// serverListenAddress détermine l'adresse d'écoute d'un serveur web à partir
+// de la configuration, éventuellement surchargée par --addr=... et --port=...
+func serverListenAddress(defaultPort int) (string, string, error) {
+	host := "localhost"
+	port := defaultPort
+	if cfg != nil && cfg.ServerAddr != "" {
+		host = cfg.ServerAddr
+	}
+
+	// Les arguments de la ligne de commande priment sur bot.conf
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--addr="):
+			host = strings.TrimPrefix(arg, "--addr=")
+		case strings.HasPrefix(arg, "--port="):
+			value, err := strconv.Atoi(strings.TrimPrefix(arg, "--port="))
+			if err != nil || value <= 0 || value > 65535 {
+				return "", "", fmt.Errorf("port invalide: %s", arg)
+			}
+			port = value
+		}
+	}
+
+	return host, strconv.Itoa(port), nil
+}
+
+// isLoopbackHost indique si l'adresse n'est joignable que depuis la machine locale
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// listenAndServe démarre un serveur web en HTTP ou HTTPS selon la configuration.
+// L'écoute sur une adresse autre que localhost est refusée si ni TLS ni le jeton
+// d'authentification ne sont configurés.
+func listenAndServe(name string, defaultPort int, handler http.Handler) error {
+	host, port, err := serverListenAddress(defaultPort)
+	if err != nil {
+		return err
+	}
+
+	tlsEnabled := cfg != nil && cfg.TLSEnabled()
+	authEnabled := cfg != nil && cfg.AuthToken != ""
+	if !isLoopbackHost(host) && !tlsEnabled && !authEnabled {
+		return fmt.Errorf("refus d'écouter sur %q sans TLS (SERVER_TLS_CERT/SERVER_TLS_KEY) ni SERVER_AUTH_TOKEN", host)
+	}
+
+	addr := net.JoinHostPort(host, port)
+	scheme := "http"
+	if tlsEnabled {
+		scheme = "https"
+	}
+
+	fmt.Printf("Démarrage du %s sur %s://%s\n", name, scheme, addr)
+	if readOnly() {
+		fmt.Println("Lecture seule: les actions sont masquées et les requêtes de modification refusées")
+	}
+	fmt.Println("Appuyez sur Ctrl+C pour arrêter le serveur")
+
+	// La lecture seule s'applique à toutes les routes, y compris celles ajoutées plus tard
+	handler = requireWritable(handler)
+	if tlsEnabled {
+		return http.ListenAndServeTLS(addr, cfg.TLSCertFile, cfg.TLSKeyFile, handler)
+	}
+	return http.ListenAndServe(addr, handler)
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/config"
+	"main/internal/scheduler"
+	"main/internal/web"
+	"main/pkg/logger"
+)
+
+// logFollowInterval est la fréquence de relecture des journaux du daemon
+const logFollowInterval = 2 * time.Second
+
+// logsPageLimit est le nombre maximal d'entrées retournées par /api/logs
+const logsPageLimit = 1000
+
+// followDaemonLogs ajoute au tampon du serveur les journaux écrits par le daemon du planificateur,
+// qui tourne dans un autre processus
+func followDaemonLogs() {
+	for _, name := range []string{scheduler.LogFile, scheduler.DaemonLogFile} {
+		go logger.FollowFile(config.Path(name), logger.Recent, logFollowInterval, nil)
+	}
+}
+
+// handleLogsPage affiche l'onglet Logs du tableau de bord
+func handleLogsPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, web.LogsTemplate, map[string]interface{}{
+		"bufferSize":  logger.DefaultRingSize,
+		"currentTime": time.Now().Format("02/01/2006 15:04:05"),
+	})
+}
+
+// handleLogsAPI retourne les dernières entrées du journal: /api/logs?level=warn&since=1234.
+// since est le numéro (seq) de la dernière entrée déjà reçue, ou une date RFC 3339.
+func handleLogsAPI(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	minLevel := logger.LevelDebug
+	if level := query.Get("level"); level != "" {
+		minLevel = logger.ParseLevel(level)
+	}
+
+	var afterSeq uint64
+	var sinceTime time.Time
+	if since := strings.TrimSpace(query.Get("since")); since != "" {
+		if seq, err := strconv.ParseUint(since, 10, 64); err == nil {
+			afterSeq = seq
+		} else if parsed, err := time.Parse(time.RFC3339, since); err == nil {
+			sinceTime = parsed
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "Paramètre since invalide: " + since})
+			return
+		}
+	}
+
+	entries := logger.Recent.Entries(minLevel, afterSeq)
+	if !sinceTime.IsZero() {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Time.After(sinceTime) {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+	}
+	if len(entries) > logsPageLimit {
+		entries = entries[len(entries)-logsPageLimit:]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"lastSeq": logger.Recent.LastSeq(),
+	})
+}
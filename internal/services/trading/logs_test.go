@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"main/internal/database"
+	"main/pkg/logger"
+)
+
+func TestLogsAPI(t *testing.T) {
+	start := logger.Recent.LastSeq()
+	log := logger.NewLogger(logger.LogConfig{Level: "debug", Format: "text"})
+	log.Info("Mise à jour des cycles")
+	log.Warn("Solde USDC insuffisant")
+	cycleEvent(&database.Cycle{IdInt: 42, Exchange: "BINANCE"}, "place_sell").warn("Cycle %d: vente refusée", 42)
+
+	var body struct {
+		Entries []logger.Entry `json:"entries"`
+		LastSeq uint64         `json:"lastSeq"`
+	}
+	rec := httptest.NewRecorder()
+	handleLogsAPI(rec, httptest.NewRequest(http.MethodGet, "/api/logs?level=warn&since="+strconv.FormatUint(start, 10), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("statut %d", rec.Code)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Entries) != 2 || body.Entries[0].Message != "Solde USDC insuffisant" || body.LastSeq != start+3 {
+		t.Fatalf("entrées: %+v (dernière %d)", body.Entries, body.LastSeq)
+	}
+	if event := body.Entries[1]; event.Message != "Cycle 42: vente refusée" || event.Fields["action"] != "place_sell" {
+		t.Errorf("décision de trading: %+v", event)
+	}
+
+	rec = httptest.NewRecorder()
+	handleLogsAPI(rec, httptest.NewRequest(http.MethodGet, "/api/logs?since=hier", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("since invalide: statut %d, attendu %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/i18n"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// lossLimitStateFile est le fichier où est publié l'état des limites de pertes quotidiennes
+const lossLimitStateFile = "loss_limit.json"
+
+// lossLimitGlobal désigne la limite tous exchanges confondus (DAILY_MAX_LOSS_USDC)
+const lossLimitGlobal = "GLOBAL"
+
+// lossLimitState est le contenu publié dans loss_limit.json, sur /health et par --check
+type lossLimitState struct {
+	Day       string    `json:"day"` // Journée UTC (AAAA-MM-JJ)
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Pertes réalisées nettes de la journée par exchange et au total (0 si la journée est gagnante)
+	Losses    map[string]float64 `json:"losses"`
+	TotalLoss float64            `json:"totalLoss"`
+	// Limites configurées par exchange et globale (absentes ou 0 = désactivées)
+	Limits      map[string]float64 `json:"limits"`
+	GlobalLimit float64            `json:"globalLimit"`
+	// Périmètres (exchange ou GLOBAL) dont la limite est atteinte, et ceux déjà notifiés
+	Breached map[string]bool `json:"breached"`
+	Notified map[string]bool `json:"notified"`
+	// Reprise forcée par --override-loss-limit, valable jusqu'à la fin de la journée UTC
+	Overridden bool `json:"overridden"`
+}
+
+// Limites de l'exécution en cours, calculées au début de chaque Update()
+var (
+	lossLimitsMu sync.Mutex
+	lossLimits   *lossLimitState
+)
+
+// utcDay retourne la journée UTC d'une date au format AAAA-MM-JJ
+func utcDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// computeLossLimits calcule les pertes réalisées de la journée UTC de now à partir des cycles
+// complétés ce jour-là, et les périmètres dont la limite est atteinte. Les gains d'un exchange
+// compensent ses pertes: seule une journée perdante nette compte.
+func computeLossLimits(c *config.Config, cycles []*database.Cycle, now time.Time) *lossLimitState {
+	state := &lossLimitState{
+		Day:       utcDay(now),
+		UpdatedAt: now,
+		Losses:    make(map[string]float64),
+		Limits:    make(map[string]float64),
+		Breached:  make(map[string]bool),
+		Notified:  make(map[string]bool),
+	}
+
+	net := make(map[string]float64)
+	for _, cycle := range cycles {
+		completedAt := cycle.EffectiveCompletedAt()
+		if cycle.Status != "completed" || completedAt.IsZero() || utcDay(completedAt) != state.Day {
+			continue
+		}
+		net[cycle.Exchange] += cycle.CalculateProfit() - cycle.TotalFees
+	}
+
+	totalNet := 0.0
+	for exchange, profit := range net {
+		totalNet += profit
+		if profit < 0 {
+			state.Losses[exchange] = -profit
+		}
+	}
+	if totalNet < 0 {
+		state.TotalLoss = -totalNet
+	}
+
+	if c == nil {
+		return state
+	}
+	for exchange, exchangeConfig := range c.Exchanges {
+		if exchangeConfig.DailyMaxLossUSDC <= 0 {
+			continue
+		}
+		state.Limits[exchange] = exchangeConfig.DailyMaxLossUSDC
+		if state.Losses[exchange] >= exchangeConfig.DailyMaxLossUSDC {
+			state.Breached[exchange] = true
+		}
+	}
+	state.GlobalLimit = c.DailyMaxLossUSDC
+	if c.DailyMaxLossUSDC > 0 && state.TotalLoss >= c.DailyMaxLossUSDC {
+		state.Breached[lossLimitGlobal] = true
+	}
+	return state
+}
+
+// Blocks indique si les nouveaux ordres de l'exchange sont suspendus
+func (s *lossLimitState) Blocks(exchange string) bool {
+	if s == nil || s.Overridden {
+		return false
+	}
+	return s.Breached[exchange] || s.Breached[lossLimitGlobal]
+}
+
+// breachedScopes retourne les périmètres dont la limite est atteinte, triés
+func (s *lossLimitState) breachedScopes() []string {
+	scopes := make([]string, 0, len(s.Breached))
+	for scope, breached := range s.Breached {
+		if breached {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// scopeLoss retourne la perte et la limite d'un périmètre
+func (s *lossLimitState) scopeLoss(scope string) (loss, limit float64) {
+	if scope == lossLimitGlobal {
+		return s.TotalLoss, s.GlobalLimit
+	}
+	return s.Losses[scope], s.Limits[scope]
+}
+
+// currentLossLimits recalcule l'état des limites de la journée en conservant la reprise forcée
+// et les notifications déjà envoyées si l'état publié concerne la même journée
+func currentLossLimits() (*lossLimitState, error) {
+	c, err := config.Get()
+	if err != nil {
+		return nil, err
+	}
+	cycles, err := database.GetRepository().FindByStatus("completed")
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des cycles: %w", err)
+	}
+
+	state := computeLossLimits(c, cycles, time.Now())
+	if previous, err := loadLossLimits(); err == nil && previous.Day == state.Day {
+		state.Overridden = previous.Overridden
+		for scope, notified := range previous.Notified {
+			state.Notified[scope] = notified
+		}
+	}
+	return state, nil
+}
+
+// refreshLossLimits calcule les limites au début d'une mise à jour, notifie une seule fois
+// par journée chaque périmètre nouvellement atteint et publie l'état
+func refreshLossLimits() {
+	state, err := currentLossLimits()
+	if err != nil {
+		exchangeEvent("", "loss_limit").with("error", err).fail(i18n.T("update.loss_limit_error"), err)
+		return
+	}
+
+	for _, scope := range state.breachedScopes() {
+		loss, limit := state.scopeLoss(scope)
+		ev := exchangeEvent(scope, "loss_limit").with("loss", loss).with("limit", limit)
+		if state.Overridden {
+			ev.warn(i18n.T("update.loss_limit_overridden"), scope, loss, limit)
+			continue
+		}
+		ev.warn(i18n.T("update.loss_limit_reached"), scope, loss, limit)
+		if !state.Notified[scope] {
+			state.Notified[scope] = true
+			ev.notify(nil, "Limite de pertes quotidienne atteinte (%s): %.2f USDC perdus pour une limite de %.2f USDC, nouveaux ordres suspendus jusqu'à demain (UTC) ou --override-loss-limit",
+				scope, loss, limit)
+		}
+	}
+
+	lossLimitsMu.Lock()
+	lossLimits = state
+	lossLimitsMu.Unlock()
+	saveLossLimits(state)
+}
+
+// lossLimitBlocks indique si la limite de pertes de l'exécution en cours suspend les nouveaux ordres de l'exchange
+func lossLimitBlocks(exchange string) bool {
+	lossLimitsMu.Lock()
+	defer lossLimitsMu.Unlock()
+	return lossLimits.Blocks(exchange)
+}
+
+// lossLimitPath retourne le chemin du fichier d'état, à côté de la base de données
+func lossLimitPath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), lossLimitStateFile)
+}
+
+// saveLossLimits publie l'état des limites de pertes
+func saveLossLimits(state *lossLimitState) {
+	if simulating() {
+		return
+	}
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des limites de pertes: %v", err)
+		return
+	}
+	if err := os.WriteFile(lossLimitPath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", lossLimitStateFile, err)
+	}
+}
+
+// loadLossLimits lit le dernier état publié des limites de pertes
+func loadLossLimits() (*lossLimitState, error) {
+	content, err := os.ReadFile(lossLimitPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &lossLimitState{}, nil
+		}
+		return nil, err
+	}
+
+	var state lossLimitState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("fichier %s invalide: %w", lossLimitStateFile, err)
+	}
+	return &state, nil
+}
+
+// OverrideLossLimit lève la limite de pertes atteinte jusqu'à la fin de la journée UTC (--override-loss-limit)
+func OverrideLossLimit() {
+	state, err := currentLossLimits()
+	if err != nil {
+		color.Red(i18n.T("loss_limit.compute_error"), err)
+		os.Exit(1)
+	}
+
+	scopes := state.breachedScopes()
+	if len(scopes) == 0 {
+		color.Green(i18n.T("loss_limit.nothing_to_override"), state.Day)
+		return
+	}
+
+	state.Overridden = true
+	state.UpdatedAt = time.Now()
+	saveLossLimits(state)
+	color.Yellow(i18n.T("loss_limit.overridden"), state.Day, scopes)
+}
+
+// Check affiche l'état courant des protections du bot: limites de pertes quotidiennes,
+// disjoncteurs de la dernière mise à jour, exchanges en maintenance et réglages réseau (--check)
+func Check() {
+	state, err := currentLossLimits()
+	if err != nil {
+		color.Red(i18n.T("loss_limit.compute_error"), err)
+		os.Exit(1)
+	}
+
+	color.Cyan(i18n.T("loss_limit.check_header"), state.Day)
+	exchanges := make([]string, 0, len(state.Limits))
+	for exchange := range state.Limits {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+	for _, scope := range append(exchanges, lossLimitGlobal) {
+		loss, limit := state.scopeLoss(scope)
+		switch {
+		case limit <= 0:
+			fmt.Printf(i18n.T("loss_limit.check_disabled"), scope, loss)
+		case state.Breached[scope] && state.Overridden:
+			color.Yellow(i18n.T("loss_limit.check_overridden"), scope, loss, limit)
+		case state.Breached[scope]:
+			color.Red(i18n.T("loss_limit.check_breached"), scope, loss, limit)
+		default:
+			color.Green(i18n.T("loss_limit.check_ok"), scope, loss, limit)
+		}
+	}
+
+	snapshot, err := loadCircuitBreakers()
+	if err != nil {
+		color.Red(i18n.T("loss_limit.breakers_error"), err)
+		return
+	}
+	color.Cyan(i18n.T("loss_limit.breakers_header"), snapshot.UpdatedAt.Format("2006-01-02 15:04:05"))
+	if len(snapshot.Exchanges) == 0 {
+		fmt.Println(i18n.T("loss_limit.breakers_none"))
+	}
+	exchanges = exchanges[:0]
+	for exchange := range snapshot.Exchanges {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+	for _, exchange := range exchanges {
+		if breaker := snapshot.Exchanges[exchange]; breaker.Maintenance {
+			color.Yellow(i18n.T("loss_limit.breaker_maintenance"), exchange, breaker.LastError)
+		} else if breaker.Open {
+			color.Red(i18n.T("loss_limit.breaker_open"), exchange, breaker.LastError)
+		} else {
+			color.Green(i18n.T("loss_limit.breaker_closed"), exchange)
+		}
+	}
+
+	printMaintenance()
+	printTradingHalts()
+	printNetworkSettings()
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// maintenanceStateFile conserve entre les exécutions les exchanges en maintenance
+const maintenanceStateFile = "exchange_maintenance.json"
+
+// maintenanceWindow est la durée minimale pendant laquelle un exchange en maintenance n'est
+// plus sollicité: il est de nouveau interrogé à la première exécution qui suit
+const maintenanceWindow = 15 * time.Minute
+
+// maintenanceEntry décrit la maintenance en cours d'un exchange
+type maintenanceEntry struct {
+	Since  time.Time `json:"since"` // Première réponse de maintenance
+	Until  time.Time `json:"until"` // Aucun appel à l'exchange avant cette date
+	Reason string    `json:"reason"`
+}
+
+// Active indique si l'exchange ne doit pas encore être interrogé
+func (e maintenanceEntry) Active(now time.Time) bool {
+	return now.Before(e.Until)
+}
+
+// maintenanceMu protège les lectures-écritures du fichier d'état
+var maintenanceMu sync.Mutex
+
+// maintenanceStatePath retourne le chemin du fichier d'état, à côté de la base de données
+func maintenanceStatePath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), maintenanceStateFile)
+}
+
+// loadMaintenance lit les maintenances enregistrées (aucune si le fichier n'existe pas)
+func loadMaintenance() (map[string]maintenanceEntry, error) {
+	content, err := os.ReadFile(maintenanceStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]maintenanceEntry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := make(map[string]maintenanceEntry)
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("fichier %s invalide: %w", maintenanceStateFile, err)
+	}
+	return entries, nil
+}
+
+// saveMaintenance publie les maintenances pour les exécutions suivantes, --check et /health
+func saveMaintenance(entries map[string]maintenanceEntry) {
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des maintenances: %v", err)
+		return
+	}
+	if err := os.WriteFile(maintenanceStatePath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", maintenanceStateFile, err)
+	}
+}
+
+// markMaintenance enregistre qu'un exchange est en maintenance pour au moins maintenanceWindow
+func markMaintenance(exchange string, cause error) maintenanceEntry {
+	now := time.Now()
+	entry := maintenanceEntry{Since: now, Until: now.Add(maintenanceWindow)}
+	if cause != nil {
+		entry.Reason = cause.Error()
+	}
+	if simulating() {
+		return entry
+	}
+
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+
+	entries, err := loadMaintenance()
+	if err != nil {
+		log.Printf("Erreur lors de la lecture de %s: %v", maintenanceStateFile, err)
+		entries = make(map[string]maintenanceEntry)
+	}
+	// Une maintenance déjà connue garde sa date de début
+	if previous, exists := entries[exchange]; exists {
+		entry.Since = previous.Since
+	}
+	entries[exchange] = entry
+	saveMaintenance(entries)
+	return entry
+}
+
+// clearMaintenance retire un exchange de l'état de maintenance et indique s'il y figurait
+func clearMaintenance(exchange string) bool {
+	if simulating() {
+		return false
+	}
+
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+
+	entries, err := loadMaintenance()
+	if err != nil {
+		return false
+	}
+	if _, exists := entries[exchange]; !exists {
+		return false
+	}
+	delete(entries, exchange)
+	saveMaintenance(entries)
+	return true
+}
+
+// checkMaintenance indique si l'exchange doit être ignoré pour maintenance. Pendant la fenêtre
+// enregistrée, l'exchange n'est pas interrogé; ensuite, l'heure du serveur sert de sonde:
+// une nouvelle réponse de maintenance prolonge la fenêtre, une réponse normale y met fin.
+func checkMaintenance(exchange string) (maintenanceEntry, bool) {
+	maintenanceMu.Lock()
+	entries, err := loadMaintenance()
+	maintenanceMu.Unlock()
+	if err != nil {
+		log.Printf("Erreur lors de la lecture de %s: %v", maintenanceStateFile, err)
+	}
+	if entry, exists := entries[exchange]; exists && entry.Active(time.Now()) {
+		return entry, true
+	}
+
+	clock, ok := GetClientByExchange(exchange).(common.ServerClock)
+	if !ok {
+		return maintenanceEntry{}, false
+	}
+	_, err = clock.ServerTime()
+	if common.IsMaintenance(err) {
+		return markMaintenance(exchange, err), true
+	}
+	if err == nil && clearMaintenance(exchange) {
+		exchangeEvent(exchange, "maintenance_ended").success(i18n.T("update.maintenance_ended"), exchange)
+	}
+	return maintenanceEntry{}, false
+}
+
+// activeMaintenance retourne les maintenances en cours, triées par exchange
+func activeMaintenance() ([]string, map[string]maintenanceEntry, error) {
+	maintenanceMu.Lock()
+	entries, err := loadMaintenance()
+	maintenanceMu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	active := make(map[string]maintenanceEntry)
+	var exchanges []string
+	now := time.Now()
+	for exchange, entry := range entries {
+		if entry.Active(now) {
+			active[exchange] = entry
+			exchanges = append(exchanges, exchange)
+		}
+	}
+	sort.Strings(exchanges)
+	return exchanges, active, nil
+}
+
+// printMaintenance affiche les exchanges en maintenance (--check)
+func printMaintenance() {
+	exchanges, active, err := activeMaintenance()
+	if err != nil {
+		color.Red("Erreur lors de la lecture de l'état des maintenances: %v", err)
+		return
+	}
+
+	color.Cyan("Maintenances des exchanges")
+	if len(exchanges) == 0 {
+		fmt.Println("  Aucun exchange en maintenance")
+		return
+	}
+	for _, exchange := range exchanges {
+		entry := active[exchange]
+		color.Yellow("  %-8s en maintenance depuis %s, nouvelle vérification après %s: %s", exchange,
+			entry.Since.Format("2006-01-02 15:04:05"), entry.Until.Format("15:04:05"), entry.Reason)
+	}
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"math"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/i18n"
+)
+
+// minProfitTarget retourne le profit net minimum exigé pour un achat de buyAmount USDC: le plus
+// élevé de MIN_PROFIT_USDC et de MIN_PROFIT_PERCENT % du montant d'achat (0 si désactivé)
+func minProfitTarget(exchangeConfig config.ExchangeConfig, buyAmount float64) float64 {
+	return math.Max(exchangeConfig.MinProfitUSDC, buyAmount*exchangeConfig.MinProfitPercent/100)
+}
+
+// netProfitAt retourne le profit net d'une vente de quantity BTC au prix sellPrice, frais
+// d'achat et de vente (au taux sellFeeRate) déduits
+func netProfitAt(sellPrice, quantity, buyAmount, buyFees, sellFeeRate float64) float64 {
+	saleAmount := sellPrice * quantity
+	return saleAmount - buyAmount - buyFees - saleAmount*sellFeeRate
+}
+
+// minProfitSellPrice retourne le prix de vente qui dégage target USDC nets, frais compris,
+// arrondi au centime supérieur
+func minProfitSellPrice(quantity, buyAmount, buyFees, sellFeeRate, target float64) float64 {
+	if quantity <= 0 || sellFeeRate >= 1 {
+		return 0
+	}
+	price := (buyAmount + buyFees + target) / (quantity * (1 - sellFeeRate))
+	return math.Ceil(price*100) / 100
+}
+
+// applyMinProfit relève le prix de vente d'un cycle dont le profit net n'atteindrait pas le
+// minimum configuré, sans dépasser le prix d'achat + MIN_PROFIT_MAX_MARKUP_PERCENT %. Retourne
+// le prix de vente et le prix plancher sous lequel le contrôle du carnet ne doit pas descendre.
+func applyMinProfit(cycle *database.Cycle, ev *tradeEvent, exchangeConfig config.ExchangeConfig,
+	sellPrice, feeAdjustedPrice, buyFees float64) (float64, float64) {
+	buyAmount := cycle.PurchaseAmountUSDC
+	if buyAmount <= 0 {
+		buyAmount = cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+	target := minProfitTarget(exchangeConfig, buyAmount)
+	if target <= 0 {
+		return sellPrice, feeAdjustedPrice
+	}
+
+	sellFeeRate := getFeeRateForExchange(cycle.Exchange)
+	profit := netProfitAt(sellPrice, cycle.Quantity, buyAmount, buyFees, sellFeeRate)
+	if profit >= target {
+		return sellPrice, math.Max(feeAdjustedPrice, minProfitSellPrice(cycle.Quantity, buyAmount, buyFees, sellFeeRate, target))
+	}
+
+	required := minProfitSellPrice(cycle.Quantity, buyAmount, buyFees, sellFeeRate, target)
+	maxPrice := math.Floor(cycle.EffectiveBuyPrice()*(1+exchangeConfig.MinProfitMaxMarkupPercent/100)*100) / 100
+	raised := math.Min(required, maxPrice)
+	if raised <= sellPrice {
+		ev.warn(i18n.T("min_profit.raise_capped_kept"),
+			cycle.IdInt, profit, target, maxPrice, exchangeConfig.MinProfitMaxMarkupPercent, sellPrice)
+		return sellPrice, feeAdjustedPrice
+	}
+
+	raisedProfit := netProfitAt(raised, cycle.Quantity, buyAmount, buyFees, sellFeeRate)
+	if raised < required {
+		ev.warn(i18n.T("min_profit.raised_capped"),
+			cycle.IdInt, sellPrice, raised, exchangeConfig.MinProfitMaxMarkupPercent, raisedProfit, target)
+	} else {
+		ev.info(i18n.T("min_profit.raised"),
+			cycle.IdInt, sellPrice, raised, target, profit)
+	}
+	return raised, math.Max(feeAdjustedPrice, raised)
+}
+
+// warnMinProfitUnreachable signale à la création d'un cycle que l'écart BUY_OFFSET/SELL_OFFSET,
+// frais déduits, n'atteint pas le profit net minimum, et si le relèvement maximal y suffit
+func warnMinProfitUnreachable(exchange string, exchangeConfig config.ExchangeConfig, buyPrice, sellPrice, quantity float64) {
+	buyAmount := buyPrice * quantity
+	target := minProfitTarget(exchangeConfig, buyAmount)
+	if target <= 0 || quantity <= 0 {
+		return
+	}
+
+	feeRate := getFeeRateForExchange(exchange)
+	buyFees := buyAmount * feeRate
+	profit := netProfitAt(sellPrice, quantity, buyAmount, buyFees, feeRate)
+	if profit >= target {
+		return
+	}
+
+	ev := exchangeEvent(exchange, "min_profit").with("profit", profit).with("target", target)
+	required := minProfitSellPrice(quantity, buyAmount, buyFees, feeRate, target)
+	maxPrice := buyPrice * (1 + exchangeConfig.MinProfitMaxMarkupPercent/100)
+	if required > maxPrice {
+		ev.warn(i18n.T("min_profit.unreachable"),
+			profit, target, feeRate*100, required, maxPrice, exchange, exchangeConfig.MinProfitMaxMarkupPercent, exchange)
+		return
+	}
+	ev.warn(i18n.T("min_profit.will_raise"),
+		profit, target, feeRate*100, required)
+}
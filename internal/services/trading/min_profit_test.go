@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestMinProfitRaisesSellPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxMarkup float64
+		capped    bool
+	}{
+		{name: "relevé au minimum", maxMarkup: 5},
+		{name: "plafonné", maxMarkup: 1, capped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchangeConfig := config.ExchangeConfig{SellOffset: 100, MinProfitUSDC: 1, MinProfitMaxMarkupPercent: tt.maxMarkup}
+			mock := useMockExchange(t, exchangeConfig, 60000)
+			repo := database.GetRepository()
+			cycle := saveBuyCycle(t, mock, 60000, 0.001)
+
+			// 100 USDC d'écart sur 0.001 BTC: 0.10 USDC brut, moins que les frais
+			if err := mock.FillOrder(cycle.BuyId); err != nil {
+				t.Fatal(err)
+			}
+			mock.Fees[cycle.BuyId] = 0.06
+			processBuyCycle(mock, repo, cycle, 60000)
+
+			stored, err := repo.FindByIdInt(cycle.IdInt)
+			if err != nil {
+				t.Fatalf("lecture du cycle: %v", err)
+			}
+			feeRate := getFeeRateForExchange("BINANCE")
+			expected := minProfitSellPrice(0.001, 60, 0.06, feeRate, 1)
+			if tt.capped {
+				expected = 60600
+			}
+			if stored.Status != "sell" || stored.SellPrice != expected {
+				t.Fatalf("vente %s à %.2f, attendue à %.2f", stored.Status, stored.SellPrice, expected)
+			}
+			if profit := netProfitAt(stored.SellPrice, 0.001, 60, 0.06, feeRate); !tt.capped && profit < 1 {
+				t.Errorf("profit net %.4f USDC sous le minimum de 1 USDC", profit)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"main/internal/config"
+	"main/internal/exchanges/binance"
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/kraken"
+	"main/internal/exchanges/kucoin"
+	"main/internal/exchanges/mexc"
+
+	"github.com/fatih/color"
+)
+
+// defaultHTTPTimeouts sont les délais des requêtes des clients sans <EXCHANGE>_HTTP_TIMEOUT_SECONDS
+var defaultHTTPTimeouts = map[string]time.Duration{
+	"BINANCE": binance.DefaultHTTPTimeout,
+	"MEXC":    mexc.DefaultHTTPTimeout,
+	"KUCOIN":  kucoin.DefaultHTTPTimeout,
+	"KRAKEN":  kraken.DefaultHTTPTimeout,
+}
+
+// networkSettings est le délai des requêtes et la fenêtre des requêtes signées appliqués à un exchange
+type networkSettings struct {
+	HTTPTimeout        time.Duration
+	HTTPTimeoutDefault bool
+	RecvWindowMs       int // 0 si l'exchange n'accepte pas de recvWindow
+	RecvWindowDefault  bool
+}
+
+// exchangeNetworkSettings retourne les valeurs effectives de <EXCHANGE>_HTTP_TIMEOUT_SECONDS et
+// <EXCHANGE>_RECV_WINDOW_MS, valeurs par défaut comprises
+func exchangeNetworkSettings(name string, exchangeConfig config.ExchangeConfig) networkSettings {
+	settings := networkSettings{HTTPTimeout: time.Duration(exchangeConfig.HTTPTimeoutSeconds) * time.Second}
+	if settings.HTTPTimeout <= 0 {
+		settings.HTTPTimeout, settings.HTTPTimeoutDefault = defaultHTTPTimeouts[name], true
+	}
+	if config.RecvWindowSupported(name) {
+		settings.RecvWindowMs = exchangeConfig.RecvWindowMs
+		if settings.RecvWindowMs <= 0 {
+			settings.RecvWindowMs, settings.RecvWindowDefault = common.DefaultRecvWindowMs, true
+		}
+	}
+	return settings
+}
+
+// String retourne les réglages sous la forme affichée par --check
+func (s networkSettings) String() string {
+	text := fmt.Sprintf("délai des requêtes %s", s.HTTPTimeout)
+	if s.HTTPTimeoutDefault {
+		text += " (défaut)"
+	}
+	if s.RecvWindowMs == 0 {
+		return text + ", recvWindow non applicable"
+	}
+	text += fmt.Sprintf(", recvWindow %d ms", s.RecvWindowMs)
+	if s.RecvWindowDefault {
+		text += " (défaut de l'exchange)"
+	}
+	return text
+}
+
+// printNetworkSettings affiche le délai des requêtes et la fenêtre des requêtes signées de chaque
+// exchange activé
+func printNetworkSettings() {
+	color.Cyan("Réseau des exchanges")
+	exchanges := make([]string, 0, len(cfg.Exchanges))
+	for name, exchangeConfig := range cfg.Exchanges {
+		if exchangeConfig.Enabled {
+			exchanges = append(exchanges, name)
+		}
+	}
+	if len(exchanges) == 0 {
+		fmt.Println("  Aucun exchange activé")
+		return
+	}
+	sort.Strings(exchanges)
+	for _, name := range exchanges {
+		fmt.Printf("  %-8s %s\n", name, exchangeNetworkSettings(name, cfg.Exchanges[name]))
+	}
+}
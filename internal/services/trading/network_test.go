@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"main/internal/config"
+)
+
+func TestExchangeNetworkSettings(t *testing.T) {
+	settings := exchangeNetworkSettings("MEXC", config.ExchangeConfig{HTTPTimeoutSeconds: 30, RecvWindowMs: 10000})
+	if settings.String() != "délai des requêtes 30s, recvWindow 10000 ms" {
+		t.Errorf("MEXC configuré: %q", settings)
+	}
+
+	settings = exchangeNetworkSettings("BINANCE", config.ExchangeConfig{})
+	if settings.HTTPTimeout != 10*time.Second || settings.RecvWindowMs != 5000 {
+		t.Errorf("BINANCE par défaut: %+v, attendu 10s et 5000 ms", settings)
+	}
+	if settings.String() != "délai des requêtes 10s (défaut), recvWindow 5000 ms (défaut de l'exchange)" {
+		t.Errorf("BINANCE par défaut: %q", settings)
+	}
+
+	settings = exchangeNetworkSettings("KRAKEN", config.ExchangeConfig{})
+	if settings.String() != "délai des requêtes 30s (défaut), recvWindow non applicable" {
+		t.Errorf("KRAKEN par défaut: %q", settings)
+	}
+}
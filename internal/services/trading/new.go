@@ -29,7 +29,7 @@ func FormatSmallFloat(quantity float64) string {
 // New crée des nouveaux cycles sur tous les exchanges configurés
 func New() {
 	// Récupérer la configuration globale
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
 		color.Red("Erreur de configuration: %v", err)
 		os.Exit(1)
@@ -43,7 +43,7 @@ func New() {
 			continue
 		}
 
-		// Créer un cycle pour cet exchange
+		// Créer un cycle pour cet exchange (les erreurs sont déjà affichées, on continue avec les autres)
 		NewWithExchange(exchangeName)
 	}
 }
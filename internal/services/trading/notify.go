@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/pkg/logger"
+)
+
+// Notification est le message publié auprès des canaux de notification. Sa forme JSON est le
+// corps des webhooks et l'entrée standard des hooks:
+//
+//	{"event": "sell_filled", "exchange": "BINANCE", "message": "Cycle 12 complété: ...",
+//	 "timestamp": "2025-02-01T10:00:00Z", "cycle": {...}, "fields": {"cycle_id": 12, "price": 61000}}
+//
+// cycle est absent des événements qui ne portent pas sur un cycle (report, loss_limit, error).
+type Notification struct {
+	Event     string          `json:"event"` // Action de l'événement de trading (buy_filled, sell_filled...)
+	Exchange  string          `json:"exchange,omitempty"`
+	Message   string          `json:"message"`
+	Timestamp time.Time       `json:"timestamp"`
+	Cycle     *database.Cycle `json:"cycle,omitempty"` // État du cycle au moment de l'événement
+	Fields    logger.Fields   `json:"fields,omitempty"`
+}
+
+// Notifier est un canal de notification. Ajouter un canal (Telegram, e-mail...)
+// revient à implémenter cette interface et à l'enregistrer dans initNotifiers.
+type Notifier interface {
+	Name() string
+	Notify(n Notification) error
+}
+
+// Canaux de notification configurés, initialisés par SetConfig
+var (
+	notifiersMu sync.Mutex
+	notifiers   []Notifier
+)
+
+// initNotifiers crée les canaux de notification activés dans la configuration
+func initNotifiers(c *config.Config) {
+	var enabled []Notifier
+	if len(c.WebhookURLs) > 0 {
+		enabled = append(enabled, newWebhookNotifier(c))
+	}
+	if c.DesktopNotifications {
+		enabled = append(enabled, newDesktopNotifier(c))
+	}
+	for _, hook := range c.Hooks {
+		enabled = append(enabled, newHookNotifier(hook))
+	}
+	if c.WebPush.Enabled() {
+		enabled = append(enabled, newPushNotifier(c))
+	}
+
+	notifiersMu.Lock()
+	notifiers = enabled
+	notifiersMu.Unlock()
+}
+
+// notify publie l'événement auprès de tous les canaux de notification. Un échec de
+// livraison est journalisé sans interrompre le traitement du cycle. Pendant une simulation,
+// la notification est seulement enregistrée.
+func (e *tradeEvent) notify(cycle *database.Cycle, format string, args ...interface{}) {
+	if rec := currentSimulation(); rec != nil {
+		exchange, _ := e.fields["exchange"].(string)
+		rec.record(0, exchange, "notify", fmt.Sprintf(format, args...))
+		return
+	}
+
+	event, _ := e.fields["action"].(string)
+	var snapshot *database.Cycle
+	if cycle != nil {
+		// Copie: le cycle peut encore être modifié après la notification
+		copied := *cycle
+		snapshot = &copied
+	}
+	e.publish(event, snapshot, fmt.Sprintf(format, args...))
+}
+
+// notifyError publie une erreur comme événement "error", reçu seulement par les canaux qui le
+// demandent. L'action en échec reste dans les champs de l'événement.
+func (e *tradeEvent) notifyError(message string) {
+	if simulating() {
+		return
+	}
+	e.publish(config.ErrorEvent, nil, message)
+}
+
+// publish transmet l'événement à chaque canal de notification
+func (e *tradeEvent) publish(event string, cycle *database.Cycle, message string) {
+	notifiersMu.Lock()
+	current := notifiers
+	notifiersMu.Unlock()
+	if len(current) == 0 {
+		return
+	}
+
+	exchange, _ := e.fields["exchange"].(string)
+	n := Notification{
+		Event:     event,
+		Exchange:  exchange,
+		Message:   message,
+		Timestamp: time.Now(),
+		Cycle:     cycle,
+		Fields:    e.fields,
+	}
+
+	for _, notifier := range current {
+		if err := notifier.Notify(n); err != nil {
+			e.with("notifier", notifier.Name()).with("error", err).warn("Notification %s non transmise: %v", notifier.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// orderIdFormats liste les formats d'ID d'ordre connus de chaque exchange.
+// Un ID conforme à l'un d'eux est transmis tel quel à l'exchange.
+var orderIdFormats = map[string][]*regexp.Regexp{
+	"BINANCE": {
+		regexp.MustCompile(`^[0-9]+$`),                 // orderId: 28457112
+		regexp.MustCompile(`^[.A-Za-z0-9:/_-]{1,36}$`), // clientOrderId: x-ABCDEF123, web_4b8d...
+	},
+	"MEXC": {
+		regexp.MustCompile(`^C02__[0-9]+$`),         // orderId: C02__512345678901234567890
+		regexp.MustCompile(`^[0-9]+$`),              // orderId enregistré sans préfixe
+		regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`), // clientOrderId ou orderId hexadécimal
+	},
+	"KUCOIN": {
+		regexp.MustCompile(`^[0-9a-f]{24}$`),        // orderId: 5bd6e9286d99522a52e458de
+		regexp.MustCompile(`^[A-Za-z0-9_-]{1,40}$`), // clientOid
+	},
+	"KRAKEN": {
+		regexp.MustCompile(`^[A-Z0-9]{6}-[A-Z0-9]{5}-[A-Z0-9]{6}$`),                          // txid: OQCLML-BW3P3-BUCMWZ
+		regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`), // cl_ord_id (UUID)
+	},
+}
+
+// mexcNumericOrderId reconnaît un ID MEXC enregistré sans son préfixe C02__
+var mexcNumericOrderId = regexp.MustCompile(`^[0-9]+$`)
+
+// checkOrderId vérifie qu'un ID d'ordre correspond à un format connu de l'exchange
+func checkOrderId(orderId, exchange string) error {
+	formats, known := orderIdFormats[exchange]
+	if !known {
+		return nil
+	}
+	for _, format := range formats {
+		if format.MatchString(orderId) {
+			return nil
+		}
+	}
+	return fmt.Errorf("format d'ID d'ordre inattendu pour %s: %q", exchange, orderId)
+}
+
+// cleanOrderId prépare un ID d'ordre pour l'API de l'exchange (BINANCE par défaut).
+// Seuls les espaces sont retirés et le préfixe C02__ de MEXC est rétabli: un ID de
+// format inattendu est signalé dans les logs mais transmis tel quel, car le modifier
+// produirait un ID d'un autre ordre ou inexistant.
+func cleanOrderId(orderId string, exchange ...string) string {
+	orderId = strings.TrimSpace(orderId)
+	if orderId == "" {
+		return ""
+	}
+
+	ex := "BINANCE"
+	if len(exchange) > 0 && exchange[0] != "" {
+		ex = strings.ToUpper(exchange[0])
+	}
+
+	if err := checkOrderId(orderId, ex); err != nil {
+		exchangeEvent(ex, "order_id").with("order_id", orderId).warn("%v, ID utilisé tel quel", err)
+		return orderId
+	}
+
+	// Les IDs MEXC sont enregistrés sans le préfixe que l'API attend
+	if ex == "MEXC" && mexcNumericOrderId.MatchString(orderId) {
+		return "C02__" + orderId
+	}
+	return orderId
+}
+
+// CheckOrderIds signale les cycles dont les IDs d'ordre ne correspondent à aucun format
+// connu de leur exchange, sans rien modifier (--check-order-ids)
+func CheckOrderIds() {
+	cycles, err := database.GetRepository().FindAll()
+	if err != nil {
+		color.Red("Erreur lors de la récupération des cycles: %v", err)
+		os.Exit(1)
+	}
+
+	suspicious := 0
+	for _, cycle := range cycles {
+		ids := map[string]string{"achat": cycle.BuyId, "vente": cycle.SellId}
+		for _, side := range []string{"achat", "vente"} {
+			id := strings.TrimSpace(ids[side])
+			if id == "" {
+				continue
+			}
+			if err := checkOrderId(id, cycle.Exchange); err != nil {
+				suspicious++
+				color.Yellow("Cycle %d (%s, %s): ID d'%s %q au format inattendu",
+					cycle.IdInt, cycle.Exchange, cycle.Status, side, id)
+			}
+		}
+	}
+
+	if suspicious == 0 {
+		color.Green("%d cycles vérifiés: tous les IDs d'ordre ont un format connu", len(cycles))
+		return
+	}
+	color.Yellow("%d ID(s) d'ordre au format inattendu sur %d cycles (aucune modification effectuée)", suspicious, len(cycles))
+}
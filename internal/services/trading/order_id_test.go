@@ -0,0 +1,65 @@
+package commands
+
+import "testing"
+
+func TestCleanOrderId(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange string
+		id       string
+		want     string
+	}{
+		{"binance orderId", "BINANCE", "28457112", "28457112"},
+		{"binance clientOrderId API", "BINANCE", "x-ABCDEF1234567890", "x-ABCDEF1234567890"},
+		{"binance clientOrderId web", "BINANCE", "web_4b8d0c6e2f1a4d3c9e7b5a1f0c2d4e6f", "web_4b8d0c6e2f1a4d3c9e7b5a1f0c2d4e6f"},
+		{"binance clientOrderId app", "BINANCE", "and_8f2b1d4c6e0a4f3b9d7c5e1a2b4c6d8e", "and_8f2b1d4c6e0a4f3b9d7c5e1a2b4c6d8e"},
+		{"binance espaces", "BINANCE", "  28457112\n", "28457112"},
+		{"exchange par défaut", "", "x-ABCDEF123", "x-ABCDEF123"},
+		{"mexc avec préfixe", "MEXC", "C02__512345678901234567890", "C02__512345678901234567890"},
+		{"mexc sans préfixe", "MEXC", "512345678901234567890", "C02__512345678901234567890"},
+		{"mexc hexadécimal", "MEXC", "06a480e69e604477bfb48dddd5f0b750", "06a480e69e604477bfb48dddd5f0b750"},
+		{"kucoin orderId", "KUCOIN", "5bd6e9286d99522a52e458de", "5bd6e9286d99522a52e458de"},
+		{"kucoin clientOid", "KUCOIN", "5c52e11203aa677f33e493fb-bot", "5c52e11203aa677f33e493fb-bot"},
+		{"kraken txid", "KRAKEN", "OQCLML-BW3P3-BUCMWZ", "OQCLML-BW3P3-BUCMWZ"},
+		{"kraken cl_ord_id", "KRAKEN", "6d1b345e-2821-40e2-ad83-4ecb18a06876", "6d1b345e-2821-40e2-ad83-4ecb18a06876"},
+		{"vide", "BINANCE", "   ", ""},
+
+		// Un ID de format inattendu est signalé mais jamais modifié
+		{"binance suspect", "BINANCE", "28457112 (annulé)", "28457112 (annulé)"},
+		{"kraken suspect", "KRAKEN", "OQCLML BW3P3 BUCMWZ", "OQCLML BW3P3 BUCMWZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanOrderId(tt.id, tt.exchange); got != tt.want {
+				t.Errorf("cleanOrderId(%q, %q) = %q, attendu %q", tt.id, tt.exchange, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOrderId(t *testing.T) {
+	valid := map[string]string{
+		"BINANCE": "x-ABCDEF123",
+		"MEXC":    "C02__512345678901234567890",
+		"KUCOIN":  "5bd6e9286d99522a52e458de",
+		"KRAKEN":  "OQCLML-BW3P3-BUCMWZ",
+	}
+	for exchange, id := range valid {
+		if err := checkOrderId(id, exchange); err != nil {
+			t.Errorf("%s: %v", exchange, err)
+		}
+	}
+
+	suspicious := map[string]string{
+		"BINANCE": "{\"orderId\":28457112}",
+		"MEXC":    "C02__51234 5678",
+		"KUCOIN":  "5bd6e9286d99522a52e458de5bd6e9286d99522a52e458de",
+		"KRAKEN":  "oqclml-bw3p3-bucmwz",
+	}
+	for exchange, id := range suspicious {
+		if err := checkOrderId(id, exchange); err == nil {
+			t.Errorf("%s: %q devrait être signalé", exchange, id)
+		}
+	}
+}
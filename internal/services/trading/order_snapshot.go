@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+)
+
+// captureOrderSnapshot conserve sur le cycle la réponse brute de l'exchange pour un ordre exécuté
+// (side: database.OrderSnapshotBuy ou OrderSnapshotSell). Un échec est seulement signalé: l'audit
+// ne bloque pas le traitement du cycle.
+func captureOrderSnapshot(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent, side, orderId string) {
+	if existing := cycle.OrderSnapshots.Get(side); existing != nil && existing.OrderId == orderId {
+		return
+	}
+
+	raw, err := client.GetOrderById(orderId)
+	if err == nil {
+		var snapshot *database.OrderSnapshot
+		if snapshot, err = database.NewOrderSnapshot(orderId, raw, time.Now()); err == nil {
+			if err = repo.SaveOrderSnapshot(cycle.IdInt, side, snapshot); err == nil {
+				if side == database.OrderSnapshotBuy {
+					cycle.OrderSnapshots.Buy = snapshot
+				} else {
+					cycle.OrderSnapshots.Sell = snapshot
+				}
+				return
+			}
+		}
+	}
+	ev.with("error", err).warn(i18n.T("update.order_snapshot_error"), orderId, cycle.IdInt, err)
+}
+
+// orderSnapshotView est une réponse brute conservée, présentée sur la page du cycle
+type orderSnapshotView struct {
+	Side       string
+	Label      string
+	OrderId    string
+	CapturedAt string
+	Size       int
+	Truncated  bool
+	Payload    string // JSON indenté, ou tel quel s'il n'est pas valide (réponse tronquée)
+}
+
+// orderSnapshotViews retourne les réponses brutes conservées d'un cycle, achat puis vente
+func orderSnapshotViews(cycle *database.Cycle) []orderSnapshotView {
+	var views []orderSnapshotView
+	for _, side := range []string{database.OrderSnapshotBuy, database.OrderSnapshotSell} {
+		snapshot := cycle.OrderSnapshots.Get(side)
+		if snapshot == nil {
+			continue
+		}
+		view := orderSnapshotView{
+			Side:       side,
+			Label:      "Achat",
+			OrderId:    snapshot.OrderId,
+			CapturedAt: snapshot.CapturedAt.Local().Format("02/01/2006 15:04:05"),
+			Size:       snapshot.Size,
+			Truncated:  snapshot.Truncated,
+		}
+		if side == database.OrderSnapshotSell {
+			view.Label = "Vente"
+		}
+
+		raw, err := snapshot.Raw()
+		if err != nil {
+			view.Payload = err.Error()
+		} else {
+			var indented bytes.Buffer
+			if json.Indent(&indented, raw, "", "  ") == nil {
+				view.Payload = indented.String()
+			} else {
+				view.Payload = string(raw)
+			}
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// handleOrderSnapshotDownload télécharge la réponse brute conservée d'un ordre du cycle:
+// /cycles/{id}/order-snapshots/{side}
+func handleOrderSnapshotDownload(w http.ResponseWriter, r *http.Request) {
+	cycle, code, err := cycleFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	side := r.PathValue("side")
+	snapshot := cycle.OrderSnapshots.Get(side)
+	if snapshot == nil {
+		http.Error(w, fmt.Sprintf("aucune réponse conservée pour l'ordre %q du cycle %d", side, cycle.IdInt), http.StatusNotFound)
+		return
+	}
+	raw, err := snapshot.Raw()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=cycle-%d-%s-%s.json", cycle.IdInt, side, snapshot.OrderId))
+	w.Write(raw)
+}
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// Orphans liste les ordres ouverts des exchanges activés qu'aucun cycle ne suit (créés pendant un
+// arrêt brutal ou placés à la main) et propose pour chacun de l'adopter dans un nouveau cycle, de
+// l'annuler ou de l'ignorer définitivement: --orphans [-exchangebinance]
+func Orphans(exchange string) {
+	repo := database.GetRepository()
+	cycles, err := repo.FindByStatus("buy", "sell", database.StatusCancelPending)
+	if err != nil {
+		color.Red(i18n.T("orphans.cycles_error"), err)
+		os.Exit(1)
+	}
+	ignoredRepo := database.GetIgnoredOrderRepository()
+	reader := bufio.NewReader(os.Stdin)
+
+	total := 0
+	for _, name := range orphanExchanges(exchange) {
+		client := GetClientByExchange(name)
+		if client == nil {
+			color.Red(i18n.T("orphans.client_unavailable"), name)
+			continue
+		}
+		ignored, err := ignoredRepo.FindByExchange(name)
+		if err != nil {
+			color.Red(i18n.T("orphans.ignored_error"), name, err)
+			continue
+		}
+		orphans, err := findOrphanOrders(client, name, cycles, ignored)
+		if err != nil {
+			color.Red(i18n.T("orphans.list_error"), name, err)
+			continue
+		}
+		if len(orphans) == 0 {
+			color.Green(i18n.T("orphans.none_exchange"), name)
+			continue
+		}
+		total += len(orphans)
+
+		color.Yellow(i18n.T("orphans.found"), name, len(orphans))
+		for _, order := range orphans {
+			printOrphanOrder(order)
+			handleOrphanOrder(reader, client, repo, ignoredRepo, name, order)
+		}
+	}
+
+	if total == 0 {
+		color.Green(i18n.T("orphans.none"))
+	}
+}
+
+// orphanExchanges retourne l'exchange demandé, ou tous les exchanges activés disposant de clés API
+func orphanExchanges(exchange string) []string {
+	if exchange != "" {
+		return []string{strings.ToUpper(exchange)}
+	}
+	var exchanges []string
+	for _, name := range []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"} {
+		exchangeConfig, exists := cfg.Exchanges[name]
+		if !exists || !exchangeConfig.Enabled || exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+			continue
+		}
+		exchanges = append(exchanges, name)
+	}
+	return exchanges
+}
+
+// findOrphanOrders retourne les ordres ouverts de l'exchange qu'aucun cycle en cours ne référence,
+// ni par ID (achat, vente, stop OCO, achat de moyenne à la baisse) ni par identifiant client,
+// en excluant les ordres de la liste ignorée
+func findOrphanOrders(client common.Exchange, exchange string, cycles []*database.Cycle, ignored map[string]bool) ([]common.OpenOrder, error) {
+	orders, err := client.GetOpenOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, cycle := range cycles {
+		if cycle.Exchange != exchange {
+			continue
+		}
+		for _, id := range []string{cycle.BuyId, cycle.SellId, cycle.StopId, cycle.AverageDownBuyId,
+			cycle.BuyClientOrderId, cycle.SellClientOrderId, cycle.AverageDownClientOrderId} {
+			if id != "" {
+				known[orderKey(id)] = true
+			}
+		}
+	}
+
+	var orphans []common.OpenOrder
+	for _, order := range orders {
+		if known[orderKey(order.ID)] || ignored[orderKey(order.ID)] {
+			continue
+		}
+		if order.ClientOrderID != "" && known[order.ClientOrderID] {
+			continue
+		}
+		orphans = append(orphans, order)
+	}
+	return orphans, nil
+}
+
+// printOrphanOrder affiche un ordre orphelin: sens, quantité, prix, montant et ancienneté
+func printOrphanOrder(order common.OpenOrder) {
+	age := i18n.T("orphans.age_unknown")
+	if !order.CreatedAt.IsZero() {
+		age = i18n.T("orphans.open_since", calculateDuration(order.CreatedAt))
+	}
+	color.White(i18n.T("orphans.row"),
+		order.ID, order.Side, order.Quantity, order.Price, order.Price*order.Quantity, age)
+}
+
+// handleOrphanOrder demande l'action à appliquer à un ordre orphelin et l'exécute
+func handleOrphanOrder(reader *bufio.Reader, client common.Exchange, repo *database.CycleRepository,
+	ignoredRepo *database.IgnoredOrderRepository, exchange string, order common.OpenOrder) {
+	switch strings.ToLower(promptLine(reader, i18n.T("orphans.prompt_action"))) {
+	case "a":
+		buyPrice := order.Price
+		if order.Side == "SELL" {
+			// Le prix d'achat d'une vente adoptée est inconnu: SELL_OFFSET sous la vente par défaut
+			buyPrice = order.Price - cfg.Exchanges[exchange].SellOffsetAt(order.Price)
+			if answer := promptLine(reader, fmt.Sprintf(i18n.T("orphans.prompt_buy_price"), buyPrice)); answer != "" {
+				price, err := strconv.ParseFloat(strings.ReplaceAll(answer, ",", "."), 64)
+				if err != nil || price <= 0 {
+					color.Red(i18n.T("orphans.invalid_price"), answer)
+					return
+				}
+				buyPrice = price
+			}
+		}
+		cycle, err := adoptOrphanOrder(repo, exchange, order, buyPrice)
+		if err != nil {
+			color.Red(i18n.T("orphans.adopt_failed"), err)
+			return
+		}
+		color.Green(i18n.T("orphans.adopted"), order.ID, cycle.IdInt, cycle.Status)
+
+	case "c":
+		result, err := client.CancelOrderIdempotent(order.ID)
+		if err != nil {
+			color.Red(i18n.T("orphans.cancel_error"), order.ID, err)
+			return
+		}
+		if result == common.AlreadyGone {
+			color.Yellow(i18n.T("orphans.already_gone"), order.ID)
+			return
+		}
+		color.Green(i18n.T("orphans.cancelled"), order.ID)
+
+	case "i":
+		if err := ignoredRepo.Ignore(exchange, orderKey(order.ID)); err != nil {
+			color.Red(i18n.T("orphans.ignore_error"), err)
+			return
+		}
+		color.White(i18n.T("orphans.ignored"), order.ID)
+
+	default:
+		color.White(i18n.T("orphans.left_as_is"), order.ID)
+	}
+}
+
+// promptLine affiche prompt et lit une ligne sur l'entrée standard
+func promptLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// adoptOrphanOrder crée le cycle qui suivra un ordre orphelin. Un achat devient un cycle en
+// achat dont la vente sera placée à SELL_OFFSET au-dessus; une vente devient un cycle en vente
+// acheté à buyPrice.
+func adoptOrphanOrder(repo *database.CycleRepository, exchange string, order common.OpenOrder, buyPrice float64) (*database.Cycle, error) {
+	if order.Quantity <= 0 || order.Price <= 0 {
+		return nil, fmt.Errorf("ordre %s sans prix ni quantité", order.ID)
+	}
+
+	createdAt := order.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	cycle := &database.Cycle{
+		Exchange:  exchange,
+		Quantity:  order.Quantity,
+		CreatedAt: createdAt,
+	}
+	switch order.Side {
+	case "BUY":
+		cycle.Status = "buy"
+		cycle.BuyId = order.ID
+		cycle.BuyPrice = order.Price
+		cycle.SellPrice = order.Price + cfg.Exchanges[exchange].SellOffsetAt(order.Price)
+		cycle.BuyClientOrderId = order.ClientOrderID
+	case "SELL":
+		if buyPrice <= 0 {
+			return nil, fmt.Errorf("prix d'achat invalide: %.2f", buyPrice)
+		}
+		cycle.Status = "sell"
+		cycle.BuyPrice = buyPrice
+		cycle.BuyFillPrice = buyPrice
+		cycle.PurchaseAmountUSDC = buyPrice * order.Quantity
+		cycle.SellId = order.ID
+		cycle.SellPrice = order.Price
+		cycle.SaleAmountUSDC = order.Price * order.Quantity
+		cycle.SellClientOrderId = order.ClientOrderID
+	default:
+		return nil, fmt.Errorf("sens d'ordre inconnu: %s", order.Side)
+	}
+
+	if _, err := repo.Save(cycle); err != nil {
+		return nil, fmt.Errorf("erreur lors de la création du cycle: %w", err)
+	}
+	return cycle, nil
+}
+
+// warnOrphanOrders signale en une ligne par exchange les ordres ouverts qu'aucun cycle ne suit,
+// sans rien modifier. Les exchanges sans prix ou dont le disjoncteur est ouvert sont ignorés.
+func warnOrphanOrders(repo *database.CycleRepository, exchanges []string, prices map[string]float64) {
+	cycles, err := repo.FindByStatus("buy", "sell", database.StatusCancelPending)
+	if err != nil {
+		return
+	}
+	ignoredRepo := database.GetIgnoredOrderRepository()
+
+	for _, exchange := range exchanges {
+		if _, ok := prices[exchange]; !ok || breakerFor(exchange).IsOpen() {
+			continue
+		}
+		client := guardedClient(exchange)
+		if client == nil {
+			continue
+		}
+		ignored, err := ignoredRepo.FindByExchange(exchange)
+		if err != nil {
+			continue
+		}
+		orphans, err := findOrphanOrders(client, exchange, cycles, ignored)
+		if err != nil || len(orphans) == 0 {
+			continue
+		}
+
+		var locked float64
+		for _, order := range orphans {
+			locked += order.Price * order.Quantity
+		}
+		exchangeEvent(exchange, "orphans").with("count", len(orphans)).
+			warn(i18n.T("update.orphans_found"), exchange, len(orphans), locked)
+	}
+}
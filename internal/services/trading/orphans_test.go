@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+)
+
+func TestFindAndAdoptOrphanOrders(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60000)
+	repo := database.GetRepository()
+
+	// Cycle suivi, plus un achat et une vente placés hors du bot
+	cycle := saveBuyCycle(t, mock, 59000, 0.001)
+	mock.AddOrder("7001", "BUY", 58000, 0.002)
+	mock.AddOrder("7002", "SELL", 62000, 0.001)
+	mock.AddOrder("7003", "SELL", 63000, 0.001)
+
+	cycles, err := repo.FindByStatus("buy", "sell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphans, err := findOrphanOrders(mock, "BINANCE", cycles, map[string]bool{"7003": true})
+	if err != nil {
+		t.Fatalf("findOrphanOrders: %v", err)
+	}
+	if len(orphans) != 2 || orphans[0].ID != "7001" || orphans[1].ID != "7002" {
+		t.Fatalf("ordres orphelins %+v, attendu 7001 et 7002 (cycle %s suivi, 7003 ignoré)", orphans, cycle.BuyId)
+	}
+	if orphans[0].CreatedAt.IsZero() {
+		t.Error("la date de création de l'ordre devrait être renseignée")
+	}
+
+	bought, err := adoptOrphanOrder(repo, "BINANCE", orphans[0], 0)
+	if err != nil {
+		t.Fatalf("adoption de l'achat: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(bought.IdInt) })
+	if bought.Status != "buy" || bought.BuyId != "7001" || bought.BuyPrice != 58000 || bought.SellPrice != 59200 {
+		t.Errorf("achat adopté: %s %q à %.2f, vente %.2f, attendu buy 7001 à 58000, vente 59200",
+			bought.Status, bought.BuyId, bought.BuyPrice, bought.SellPrice)
+	}
+
+	sold, err := adoptOrphanOrder(repo, "BINANCE", orphans[1], 60800)
+	if err != nil {
+		t.Fatalf("adoption de la vente: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(sold.IdInt) })
+	if sold.Status != "sell" || sold.SellId != "7002" || sold.EffectiveBuyPrice() != 60800 || sold.Quantity != 0.001 {
+		t.Errorf("vente adoptée: %s %q achetée à %.2f, attendu sell 7002 achetée à 60800", sold.Status, sold.SellId, sold.EffectiveBuyPrice())
+	}
+
+	// Une fois adoptés, les ordres ne sont plus orphelins
+	cycles, _ = repo.FindByStatus("buy", "sell")
+	orphans, err = findOrphanOrders(mock, "BINANCE", cycles, map[string]bool{"7003": true})
+	if err != nil || len(orphans) != 0 {
+		t.Errorf("%d ordre(s) encore orphelin(s) après adoption (erreur %v)", len(orphans), err)
+	}
+
+	if _, err := adoptOrphanOrder(repo, "BINANCE", common.OpenOrder{ID: "7004", Side: "SELL", Price: 62000, Quantity: 0.001}, 0); err == nil {
+		t.Error("une vente sans prix d'achat ne devrait pas être adoptée")
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// setCyclePaused met en pause ou reprend un cycle. Seuls les cycles dont un ordre est
+// ouvert (achat ou vente) sont concernés par la mise à jour.
+func setCyclePaused(idInt int32, paused bool) (*database.Cycle, error) {
+	repo := database.GetRepository()
+	cycle, err := repo.FindByIdInt(idInt)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération du cycle: %w", err)
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("cycle avec ID %d introuvable", idInt)
+	}
+	if paused && cycle.Status != "buy" && cycle.Status != "sell" {
+		return nil, fmt.Errorf("le cycle %d a le statut '%s': seuls les cycles en achat ou en vente peuvent être mis en pause", idInt, cycle.Status)
+	}
+
+	if err := repo.UpdateByIdInt(idInt, map[string]interface{}{"paused": paused}); err != nil {
+		return nil, fmt.Errorf("erreur lors de la mise à jour du cycle: %w", err)
+	}
+	cycle.Paused = paused
+	return cycle, nil
+}
+
+// PauseOrResume traite --pause=ID et --resume=ID
+func PauseOrResume(arg string) {
+	paused := strings.HasPrefix(arg, "--pause")
+	_, value, found := strings.Cut(arg, "=")
+	idInt, err := strconv.Atoi(value)
+	if !found || err != nil {
+		color.Red("ID de cycle invalide. Utilisez --pause=123 ou --resume=123")
+		os.Exit(1)
+	}
+
+	cycle, err := setCyclePaused(int32(idInt), paused)
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+
+	if paused {
+		color.Yellow("Cycle %d (%s, %s) mis en pause: la mise à jour l'ignorera jusqu'à --resume=%d",
+			cycle.IdInt, cycle.Exchange, formatStatus(cycle), cycle.IdInt)
+	} else {
+		color.Green("Cycle %d (%s, %s) repris", cycle.IdInt, cycle.Exchange, formatStatus(cycle))
+	}
+}
+
+// handleSetPaused met en pause (/cycles/{id}/pause) ou reprend (/cycles/{id}/resume) un cycle
+func handleSetPaused(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idInt, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "ID de cycle invalide: "+r.PathValue("id"), http.StatusBadRequest)
+			return
+		}
+
+		target := fmt.Sprintf("/cycles/%d", idInt)
+		if _, err := setCyclePaused(int32(idInt), paused); err != nil {
+			http.Redirect(w, r, target+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+
+		message := fmt.Sprintf("Cycle %d repris", idInt)
+		if paused {
+			message = fmt.Sprintf("Cycle %d mis en pause", idInt)
+		}
+		http.Redirect(w, r, target+"?message="+url.QueryEscape(message), http.StatusSeeOther)
+	}
+}
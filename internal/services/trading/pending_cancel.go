@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+)
+
+// cancelRetryNotifyAfter est le nombre de tentatives d'annulation d'un achat au-delà duquel
+// l'échec est notifié
+const cancelRetryNotifyAfter = 3
+
+// errCancelNotConfirmed est la cause enregistrée lorsque l'exchange ne confirme pas l'annulation
+// sans retourner d'erreur
+var errCancelNotConfirmed = errors.New("annulation non confirmée par l'exchange")
+
+// recordCancelFailure passe en cancel_pending un cycle dont l'ordre d'achat n'a pas pu être
+// annulé: l'ordre peut encore s'exécuter, l'annulation est retentée à la prochaine mise à jour
+func recordCancelFailure(repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent, reason string, cause error) {
+	if cause == nil {
+		cause = errCancelNotConfirmed
+	}
+	cycle.Status = database.StatusCancelPending
+	cycle.CancelReason = reason
+	cycle.CancelRetryCount++
+	cycle.CancelRetryError = cause.Error()
+
+	if err := repo.MarkCancelPending(cycle.IdInt, reason, cycle.CancelRetryCount, cycle.CancelRetryError); err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+
+	ev = ev.with("action", "cancel_retry").with("error", cause)
+	ev.warn(i18n.T("update.cancel_pending"), cycle.IdInt, cycle.CancelRetryCount, cycle.CancelRetryError)
+	if cycle.CancelRetryCount == cancelRetryNotifyAfter {
+		ev.notify(cycle, "Cycle %d: ordre d'achat %s toujours non annulé après %d tentatives (%s)",
+			cycle.IdInt, cycle.BuyId, cycle.CancelRetryCount, cycle.CancelRetryError)
+	}
+}
+
+// retryPendingCancel retente l'annulation de l'ordre d'achat d'un cycle en cancel_pending, avec
+// chacun des identifiants sous lesquels l'exchange peut le connaître. Le cycle passe en cancelled
+// dès que l'exchange confirme; un achat exécuté entre-temps est adopté et le cycle reprend le
+// traitement normal d'un achat exécuté.
+func retryPendingCancel(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, lastPrice float64) {
+	ev := cycleEvent(cycle, "cancel_retry").with("order_id", cycle.BuyId)
+	ev.info(i18n.T("update.cancel_retry"), cycle.IdInt, cycle.CancelRetryCount+1, cycle.CancelRetryError)
+
+	var lastErr error
+	for _, orderId := range cancelOrderIds(client, cycle) {
+		result, err := safeOrderCancel(client, orderId, cycle.IdInt)
+		switch result {
+		case common.Cancelled:
+			// L'ordre a pu être partiellement exécuté avant son annulation: son état le dit, et
+			// une nouvelle tentative le relira si l'exchange ne répond pas
+			status, statusErr := client.GetOrderStatus(orderId)
+			if statusErr != nil {
+				recordCancelFailure(repo, cycle, ev, cycle.CancelReason, statusErr)
+				return
+			}
+			settlePendingCancel(client, repo, cycle, ev, orderId, status, lastPrice)
+			return
+		case common.AlreadyGone:
+			// Un identifiant au mauvais format est aussi "inconnu": seul l'état de l'ordre confirme
+			status, statusErr := client.GetOrderStatus(orderId)
+			switch {
+			case statusErr != nil:
+				lastErr = statusErr
+			case status.Filled():
+				adoptPendingCancelFill(client, repo, cycle, ev, orderId, lastPrice)
+				return
+			case status.State == common.OrderCancelled || status.State == common.OrderRejected:
+				settlePendingCancel(client, repo, cycle, ev, orderId, status, lastPrice)
+				return
+			}
+		default:
+			lastErr = err
+		}
+	}
+	recordCancelFailure(repo, cycle, ev, cycle.CancelReason, lastErr)
+}
+
+// cancelOrderIds retourne les identifiants à essayer pour annuler l'achat d'un cycle: l'ID
+// nettoyé, l'ID tel qu'enregistré et celui de l'ordre ouvert portant l'identifiant client du cycle
+func cancelOrderIds(client common.Exchange, cycle *database.Cycle) []string {
+	var ids []string
+	add := func(id string) {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	add(cleanOrderId(cycle.BuyId, cycle.Exchange))
+	add(cycle.BuyId)
+	if cycle.BuyClientOrderId != "" {
+		if order, found, err := common.FindOpenOrderByClientID(client, cycle.BuyClientOrderId); err == nil && found {
+			add(order.ID)
+		}
+	}
+	return ids
+}
+
+// settlePendingCancel conclut l'annulation confirmée de l'achat d'un cycle: la part exécutée
+// avant l'annulation est conservée et vendue, le cycle est annulé sinon
+func settlePendingCancel(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent,
+	orderId string, status common.OrderStatus, lastPrice float64) {
+	if status.State == common.OrderCancelled && status.ExecutedQty > 0 {
+		adoptPendingCancelPartialFill(client, repo, cycle, ev, orderId, status, lastPrice)
+		return
+	}
+	confirmPendingCancel(repo, cycle, ev)
+}
+
+// confirmPendingCancel passe en cancelled un cycle dont l'annulation est enfin confirmée
+func confirmPendingCancel(repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent) {
+	if err := markCycleCancelled(repo, cycle, cycle.CancelReason); err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+	cycle.CancelRetryCount, cycle.CancelRetryError = 0, ""
+	ev.with("action", "cancel_buy").success(i18n.T("update.cancel_confirmed"), cycle.IdInt)
+	ev.notify(cycle, "Cycle %d: ordre d'achat annulé (%s)", cycle.IdInt, formatCancelReason(cycle.CancelReason))
+}
+
+// adoptPendingCancelFill reprend un cycle dont l'achat a été exécuté avant que son annulation
+// n'aboutisse: le cycle redevient un achat et la vente est placée comme pour tout achat exécuté
+func adoptPendingCancelFill(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent,
+	orderId string, lastPrice float64) {
+	buyId := ""
+	if orderId != cleanOrderId(cycle.BuyId, cycle.Exchange) && orderId != strings.TrimSpace(cycle.BuyId) {
+		// Ordre retrouvé par son identifiant client
+		buyId = orderId
+	}
+	if err := repo.MarkCancelAborted(cycle.IdInt, buyId); err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+	if buyId != "" {
+		cycle.BuyId = buyId
+	}
+	cycle.Status = "buy"
+	cycle.CancelReason, cycle.CancelRetryCount, cycle.CancelRetryError = "", 0, ""
+
+	ev.with("action", "buy_filled").warn(i18n.T("update.cancel_pending_filled"), cycle.IdInt)
+	processBuyCycle(client, repo, cycle, lastPrice)
+}
+
+// adoptPendingCancelPartialFill reprend la part exécutée de l'achat d'un cycle dont l'annulation
+// est confirmée: le cycle ne porte plus que la quantité achetée, passe en vente en attente et
+// sa vente est placée aussitôt, ou retentée comme toute vente en attente
+func adoptPendingCancelPartialFill(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent,
+	orderId string, status common.OrderStatus, lastPrice float64) {
+	fill := database.PartialFill{
+		Quantity:     status.ExecutedQty,
+		BuyFillPrice: cycle.BuyPrice,
+	}
+	if orderId != cleanOrderId(cycle.BuyId, cycle.Exchange) && orderId != strings.TrimSpace(cycle.BuyId) {
+		// Ordre retrouvé par son identifiant client
+		fill.BuyId = orderId
+	}
+	if status.AvgFillPrice > 0 {
+		fill.BuyFillPrice = status.AvgFillPrice
+	}
+	fill.PurchaseAmountUSDC = filledAmount(status, fill.BuyFillPrice, fill.Quantity)
+
+	// Frais de la part exécutée: relevés par l'exchange, ou estimés au taux par défaut
+	fees, err := client.GetOrderFees(orderId)
+	switch {
+	case err == nil:
+		fill.BuyFees = fees
+	case status.Fee != 0:
+		fill.BuyFees = status.Fee
+	default:
+		fill.BuyFees = fill.PurchaseAmountUSDC * getFeeRateForExchange(cycle.Exchange)
+		fill.FeesEstimated = true
+	}
+
+	if err := repo.MarkCancelPartiallyFilled(cycle.IdInt, fill); err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+	previousQty := cycle.Quantity
+	if fill.BuyId != "" {
+		cycle.BuyId = fill.BuyId
+	}
+	cycle.Status = "sell"
+	cycle.Quantity = fill.Quantity
+	cycle.BuyFillPrice = fill.BuyFillPrice
+	cycle.PurchaseAmountUSDC = fill.PurchaseAmountUSDC
+	cycle.BuyFees, cycle.TotalFees, cycle.FeesEstimated = fill.BuyFees, fill.BuyFees, fill.FeesEstimated
+	cycle.CancelReason, cycle.CancelRetryCount, cycle.CancelRetryError = "", 0, ""
+	cycle.SellId, cycle.SellClientOrderId = "", ""
+	cycle.SellRetryCount, cycle.SellRetryError, cycle.SellRetryAt = 0, "", time.Time{}
+
+	ev = ev.with("action", "buy_partially_filled").with("price", fill.BuyFillPrice)
+	ev.warn(i18n.T("update.cancel_pending_partial"), cycle.IdInt, fill.Quantity, previousQty, fill.BuyFillPrice)
+	ev.notify(cycle, "Cycle %d: achat annulé après une exécution partielle, %.8f BTC sur %.8f conservés et mis en vente",
+		cycle.IdInt, fill.Quantity, previousQty)
+	retryPendingSell(client, repo, cycle, lastPrice)
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+)
+
+// Tentatives de placement d'une vente en attente: le délai double à chaque échec, dans la limite
+// de sellRetryMaxDelay, et l'échec est notifié une fois sellRetryNotifyAfter tentatives atteintes
+const (
+	sellRetryBaseDelay   = time.Minute
+	sellRetryMaxDelay    = time.Hour
+	sellRetryNotifyAfter = 3
+)
+
+// sellPending indique si l'achat du cycle est exécuté mais que sa vente n'a pas pu être placée
+func sellPending(cycle *database.Cycle) bool {
+	return cycle.Status == "sell" && strings.TrimSpace(cycle.SellId) == ""
+}
+
+// sellRetryDelay retourne l'attente avant la tentative qui suit le n-ième échec
+func sellRetryDelay(attempts int) time.Duration {
+	delay := sellRetryBaseDelay
+	for i := 1; i < attempts && delay < sellRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, sellRetryMaxDelay)
+}
+
+// recordSellFailure enregistre l'échec du placement de la vente d'un cycle dont l'achat est
+// exécuté: le cycle passe en vente en attente, avec son identifiant client pour reprendre un
+// ordre créé malgré l'erreur, et la prochaine tentative est programmée
+func recordSellFailure(repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent, clientOrderID string, cause error) {
+	cycle.Status = "sell"
+	cycle.SellId = ""
+	cycle.SellClientOrderId = clientOrderID
+	cycle.SellRetryCount++
+	cycle.SellRetryError = cause.Error()
+	cycle.SellRetryAt = time.Now().Add(sellRetryDelay(cycle.SellRetryCount))
+
+	err := repo.MarkBuyFilled(cycle.IdInt, database.SellRetry{
+		ClientOrderId: clientOrderID,
+		Count:         cycle.SellRetryCount,
+		Error:         cycle.SellRetryError,
+		At:            cycle.SellRetryAt,
+	})
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+
+	ev = ev.with("action", "sell_retry").with("error", cause)
+	ev.warn(i18n.T("update.sell_retry_scheduled"), cycle.IdInt, cycle.SellRetryCount, i18n.FormatDateTime(cycle.SellRetryAt))
+	if cycle.SellRetryCount == sellRetryNotifyAfter {
+		ev.notify(cycle, "Cycle %d: vente toujours non placée après %d tentatives (%s)",
+			cycle.IdInt, cycle.SellRetryCount, cycle.SellRetryError)
+	}
+}
+
+// retryPendingSell retente, une fois son délai écoulé, la vente d'un cycle en attente: le solde
+// BTC est contrôlé et le prix de vente recalculé sur le prix de l'exécution (lastPrice) avant le
+// placement, en OCO si USE_OCO est activé comme pour la première tentative
+func retryPendingSell(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, lastPrice float64) {
+	ev := cycleEvent(cycle, "sell_retry")
+	if time.Now().Before(cycle.SellRetryAt) {
+		ev.info(i18n.T("update.sell_retry_waiting"), cycle.IdInt, i18n.FormatDateTime(cycle.SellRetryAt))
+		return
+	}
+	if lossLimitBlocks(cycle.Exchange) {
+		ev.with("action", "loss_limit").warn(i18n.T("update.loss_limit_sell_blocked"), cycle.IdInt, cycle.Exchange)
+		return
+	}
+
+	// Le prix de vente dépend du prix actuel: nouvelle tentative à une mise à jour au prix fiable
+	if !priceTrusted(cycle.Exchange) {
+		ev.with("action", "price_untrusted").warn(i18n.T("update.sell_price_untrusted"), cycle.IdInt, cycle.Exchange)
+		return
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.config_load_error"), err)
+		return
+	}
+	exchangeConfig, err := cfg.GetExchangeConfig(cycle.Exchange)
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.exchange_config_error"), err)
+		return
+	}
+
+	clientOrderID := cycle.SellClientOrderId
+	if clientOrderID == "" {
+		clientOrderID = common.ClientOrderID(cycle.IdInt, "sell")
+	}
+	if cycle.SellRetryCount > 0 {
+		ev.info(i18n.T("update.sell_retry"), cycle.IdInt, cycle.SellRetryCount+1, cycle.SellRetryError)
+	}
+
+	// Une vente créée malgré l'erreur verrouille déjà le BTC: elle est reprise par placeLimitSell
+	quantityToSell := cycle.Quantity
+	_, found := findClientOrder(client, clientOrderID)
+	if !found {
+		balances, err := client.GetDetailedBalances()
+		if err != nil {
+			ev.with("error", err).fail(i18n.T("update.balance_error"), err)
+			recordSellFailure(repo, cycle, ev, clientOrderID, err)
+			return
+		}
+		availableBTC := usableBalance(cycle.Exchange, balances, "BTC")
+		if availableBTC < cycle.Quantity*0.95 {
+			recordSellFailure(repo, cycle, ev, clientOrderID,
+				fmt.Errorf("solde BTC insuffisant: %.8f disponible pour %.8f", availableBTC, cycle.Quantity))
+			return
+		}
+		if availableBTC < cycle.Quantity {
+			ev.info(i18n.T("update.sell_qty_adjusted"), cycle.IdInt, cycle.Quantity, availableBTC)
+			quantityToSell = availableBTC
+		}
+	}
+
+	// Prix recalculé: le marché a pu passer au-dessus du prix prévu pendant l'attente
+	sellPrice := computeSellPrice(client, cycle, ev, exchangeConfig, lastPrice, cycle.BuyFees,
+		cleanOrderId(cycle.BuyId, cycle.Exchange))
+	quantityStr := client.FormatQuantity(quantityToSell)
+
+	// Même placement que la première tentative (OCO si activé), sauf pour reprendre une vente
+	// limite déjà créée sous l'identifiant client
+	var oco common.OCOOrder
+	var stopLimitPrice float64
+	if !found {
+		if oco, stopLimitPrice, err = placeOCOSell(client, cycle, ev, exchangeConfig, sellPrice, lastPrice, quantityStr); err != nil {
+			recordSellFailure(repo, cycle, ev, clientOrderID, err)
+			return
+		}
+	}
+
+	orderIdStr, placedPrice, sellClientOrderId := oco.LimitID, sellPrice, ""
+	if orderIdStr == "" {
+		sellClientOrderId = clientOrderID
+		if orderIdStr, placedPrice, err = placeLimitSell(client, repo, cycle, ev, sellPrice, quantityStr, clientOrderID); err != nil {
+			recordSellFailure(repo, cycle, ev, clientOrderID, err)
+			return
+		}
+	}
+
+	attempts := cycle.SellRetryCount
+	saleAmountUSDC := placedPrice * quantityToSell
+	err = repo.MarkSellPlaced(cycle.IdInt, database.SellOrder{
+		OrderId:        orderIdStr,
+		ClientOrderId:  sellClientOrderId,
+		Price:          placedPrice,
+		SaleAmountUSDC: saleAmountUSDC,
+		StopId:         oco.StopID,
+		StopPrice:      stopLimitPrice,
+	})
+	ev = ev.with("action", "place_sell").with("order_id", orderIdStr).with("price", placedPrice)
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+
+	cycle.SellId = orderIdStr
+	cycle.SellClientOrderId = sellClientOrderId
+	cycle.SellPrice = placedPrice
+	if oco.StopID != "" {
+		cycle.StopId = oco.StopID
+		cycle.StopPrice = stopLimitPrice
+	}
+	cycle.SaleAmountUSDC = saleAmountUSDC
+	cycle.SellRetryCount, cycle.SellRetryError, cycle.SellRetryAt = 0, "", time.Time{}
+
+	profitPercent := (placedPrice - cycle.BuyPrice) / cycle.BuyPrice * 100
+	ev.success(i18n.T("update.sell_placed"), cycle.IdInt, orderIdStr)
+	ev.success(i18n.T("update.sell_placed_prices"), cycle.IdInt, cycle.BuyPrice, placedPrice, profitPercent)
+	ev.notify(cycle, "Cycle %d: ordre de vente placé à %.2f USDC après %d échec(s) (profit potentiel: %.2f%%)",
+		cycle.IdInt, placedPrice, attempts, profitPercent)
+}
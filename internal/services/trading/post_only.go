@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strconv"
+	"time"
+
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// createLimitOrder place un ordre limite portant l'identifiant client indiqué ("" = aucun).
+// Lorsque <EXCHANGE>_POST_ONLY est actif, l'ordre est envoyé en post-only et repoussé d'un tick
+// à chaque refus (au plus <EXCHANGE>_POST_ONLY_RETRIES fois).
+// Le prix finalement utilisé est retourné avec l'ordre créé.
+func createLimitOrder(client common.Exchange, exchange, side string, price float64, quantity, clientOrderID string) (common.OrderCreateResult, float64, error) {
+	return placeLimitOrder(client, exchange, side, price, quantity, common.OrderOptions{ClientOrderID: clientOrderID})
+}
+
+// createBuyOrder place l'ordre d'achat d'un cycle créé à createdAt, comme createLimitOrder.
+// Avec <EXCHANGE>_USE_EXCHANGE_EXPIRY et <EXCHANGE>_BUY_MAX_DAYS, l'exchange annule lui-même
+// l'ordre à l'échéance s'il le permet; sinon l'annulation reste faite par la mise à jour.
+func createBuyOrder(client common.Exchange, exchange string, price float64, quantity, clientOrderID string, createdAt time.Time) (common.OrderCreateResult, float64, error) {
+	opts := common.OrderOptions{ClientOrderID: clientOrderID}
+	if exchangeConfig, ok := cfg.Exchanges[exchange]; ok && exchangeConfig.UseExchangeExpiry &&
+		exchangeConfig.BuyMaxDays > 0 && common.SupportsOrderExpiry(client) {
+		// Une échéance déjà passée est laissée à l'annulation par la mise à jour
+		if expireAt := createdAt.Add(time.Duration(exchangeConfig.BuyMaxDays) * 24 * time.Hour); expireAt.After(time.Now()) {
+			opts.ExpireAt = expireAt
+		}
+	}
+	return placeLimitOrder(client, exchange, "BUY", price, quantity, opts)
+}
+
+// placeLimitOrder place un ordre limite avec les options indiquées, en post-only si activé
+func placeLimitOrder(client common.Exchange, exchange, side string, price float64, quantity string, opts common.OrderOptions) (common.OrderCreateResult, float64, error) {
+	exchangeConfig, ok := cfg.Exchanges[exchange]
+	if !ok || !exchangeConfig.PostOnly {
+		priceStr := client.FormatPrice(price)
+		if rounded, err := strconv.ParseFloat(priceStr, 64); err == nil {
+			price = rounded
+		}
+		created, err := client.CreateOrder(side, priceStr, quantity, opts)
+		return created, price, err
+	}
+
+	return common.CreatePostOnlyOrder(client, side, price, quantity, client.Precision().PriceTick, exchangeConfig.PostOnlyRetries, opts)
+}
+
+// findClientOrder recherche l'ordre ouvert créé avec l'identifiant client indiqué, placé par
+// une exécution interrompue avant d'avoir pu l'enregistrer. Un échec de la recherche est signalé
+// mais n'empêche pas de placer l'ordre.
+func findClientOrder(client common.Exchange, clientOrderID string) (common.OpenOrder, bool) {
+	order, found, err := common.FindOpenOrderByClientID(client, clientOrderID)
+	if err != nil {
+		color.Yellow(i18n.T("update.client_order_lookup_error"), clientOrderID, err)
+		return common.OpenOrder{}, false
+	}
+	if found {
+		color.Yellow(i18n.T("update.client_order_adopted"), order.ID, clientOrderID)
+	}
+	return order, found
+}
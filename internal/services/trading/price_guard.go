@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/i18n"
+)
+
+// priceGuardFile conserve entre les exécutions le dernier prix jugé fiable de chaque exchange
+const priceGuardFile = "price_guard.json"
+
+// goodPriceMaxAge est l'âge au-delà duquel le dernier prix fiable ne sert plus de référence:
+// le marché a pu légitimement s'en éloigner
+const goodPriceMaxAge = 6 * time.Hour
+
+// goodPrice est le dernier prix BTC jugé fiable d'un exchange
+type goodPrice struct {
+	Price float64   `json:"price"`
+	At    time.Time `json:"at"`
+}
+
+// priceSuspicion décrit un prix rejeté et la référence qui l'a fait rejeter
+type priceSuspicion struct {
+	Price     float64
+	Reference float64
+	Deviation float64 // Écart en % par rapport à la référence
+	Median    bool    // Référence: médiane des autres exchanges (sinon dernier prix fiable)
+}
+
+// Prix fiables connus et prix rejetés pendant l'exécution en cours
+var (
+	priceGuardMu    sync.Mutex
+	lastGoodPrices  = make(map[string]goodPrice)
+	untrustedPrices = make(map[string]priceSuspicion)
+	goodPricesRead  bool
+)
+
+// priceGuardPath retourne le chemin du fichier des prix fiables, à côté de la base de données
+func priceGuardPath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), priceGuardFile)
+}
+
+// resetPriceGuard rend sa confiance à tous les exchanges au début d'une exécution
+func resetPriceGuard() {
+	priceGuardMu.Lock()
+	defer priceGuardMu.Unlock()
+	untrustedPrices = make(map[string]priceSuspicion)
+}
+
+// priceTrusted indique si le prix relevé pour l'exchange pendant cette exécution peut fonder
+// une décision (annulation, accumulation, prix de vente)
+func priceTrusted(exchange string) bool {
+	priceGuardMu.Lock()
+	defer priceGuardMu.Unlock()
+	_, untrusted := untrustedPrices[exchange]
+	return !untrusted
+}
+
+// medianPrice retourne la médiane des prix (0 sans prix)
+func medianPrice(prices []float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), prices...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// suspectPrices retourne les prix qui s'écartent de plus de maxDeviation % de leur référence:
+// la médiane des autres exchanges quand au moins deux autres ont répondu (un seul ne permet pas
+// de savoir lequel se trompe), sinon le dernier prix fiable de l'exchange s'il est assez récent
+func suspectPrices(prices map[string]float64, known map[string]goodPrice, maxDeviation float64, now time.Time) map[string]priceSuspicion {
+	suspects := make(map[string]priceSuspicion)
+	if maxDeviation <= 0 {
+		return suspects
+	}
+
+	for exchange, price := range prices {
+		var others []float64
+		for other, otherPrice := range prices {
+			if other != exchange && otherPrice > 0 {
+				others = append(others, otherPrice)
+			}
+		}
+
+		suspicion := priceSuspicion{Price: price}
+		if len(others) >= 2 {
+			suspicion.Reference = medianPrice(others)
+			suspicion.Median = true
+		} else if good, ok := known[exchange]; ok && good.Price > 0 && now.Sub(good.At) <= goodPriceMaxAge {
+			suspicion.Reference = good.Price
+		} else {
+			continue
+		}
+
+		suspicion.Deviation = math.Abs(price-suspicion.Reference) / suspicion.Reference * 100
+		if suspicion.Deviation > maxDeviation {
+			suspects[exchange] = suspicion
+		}
+	}
+	return suspects
+}
+
+// guardPrices vérifie les prix relevés par la mise à jour (PRICE_SANITY_MAX_DEVIATION): un prix
+// aberrant rend son exchange non fiable pour l'exécution, les autres deviennent les derniers prix
+// fiables connus, enregistrés si PRICE_SANITY_PERSIST est activé
+func guardPrices(c *config.Config, prices map[string]float64) {
+	priceGuardMu.Lock()
+	defer priceGuardMu.Unlock()
+
+	if c.PriceSanityPersist && !goodPricesRead {
+		goodPricesRead = true
+		for exchange, good := range loadGoodPrices() {
+			if _, exists := lastGoodPrices[exchange]; !exists {
+				lastGoodPrices[exchange] = good
+			}
+		}
+	}
+
+	now := time.Now()
+	untrustedPrices = suspectPrices(prices, lastGoodPrices, c.PriceSanityMaxDeviation, now)
+	for _, exchange := range sortedExchanges(prices) {
+		if suspicion, suspect := untrustedPrices[exchange]; suspect {
+			ev := exchangeEvent(exchange, "price_untrusted").with("price", suspicion.Price).
+				with("reference", suspicion.Reference).with("deviation", suspicion.Deviation)
+			if suspicion.Median {
+				ev.warn(i18n.T("update.price_untrusted_median"), exchange, suspicion.Price,
+					suspicion.Deviation, suspicion.Reference, c.PriceSanityMaxDeviation)
+			} else {
+				good := lastGoodPrices[exchange]
+				ev.warn(i18n.T("update.price_untrusted_last_good"), exchange, suspicion.Price,
+					suspicion.Deviation, good.Price, i18n.FormatDateTime(good.At), c.PriceSanityMaxDeviation)
+			}
+			ev.warn(i18n.T("update.price_untrusted_skip"), exchange)
+			continue
+		}
+		lastGoodPrices[exchange] = goodPrice{Price: prices[exchange], At: now}
+	}
+
+	if c.PriceSanityPersist && !simulating() {
+		saveGoodPrices(lastGoodPrices)
+	}
+}
+
+// trustedPrices retourne les prix de l'exécution sans ceux jugés non fiables
+func trustedPrices(prices map[string]float64) map[string]float64 {
+	trusted := make(map[string]float64, len(prices))
+	for exchange, price := range prices {
+		if priceTrusted(exchange) {
+			trusted[exchange] = price
+		}
+	}
+	return trusted
+}
+
+// sortedExchanges retourne les exchanges des prix dans un ordre stable
+func sortedExchanges(prices map[string]float64) []string {
+	keys := make([]string, 0, len(prices))
+	for key := range prices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadGoodPrices lit les derniers prix fiables enregistrés (aucun si le fichier est absent ou invalide)
+func loadGoodPrices() map[string]goodPrice {
+	known := make(map[string]goodPrice)
+	content, err := os.ReadFile(priceGuardPath())
+	if err != nil {
+		return known
+	}
+	if err := json.Unmarshal(content, &known); err != nil {
+		log.Printf("Fichier %s invalide, ignoré: %v", priceGuardFile, err)
+		return make(map[string]goodPrice)
+	}
+	return known
+}
+
+// saveGoodPrices enregistre les derniers prix fiables pour les exécutions suivantes
+func saveGoodPrices(known map[string]goodPrice) {
+	content, err := json.MarshalIndent(known, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des prix fiables: %v", err)
+		return
+	}
+	if err := os.WriteFile(priceGuardPath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", priceGuardFile, err)
+	}
+}
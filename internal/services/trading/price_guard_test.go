@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSuspectPricesMedianOfOtherExchanges(t *testing.T) {
+	prices := map[string]float64{"BINANCE": 95000, "MEXC": 95050, "KUCOIN": 0.01, "KRAKEN": 94980}
+
+	suspects := suspectPrices(prices, nil, 10, time.Now())
+	if len(suspects) != 1 {
+		t.Fatalf("suspects = %v, want KUCOIN only", suspects)
+	}
+	suspicion, ok := suspects["KUCOIN"]
+	if !ok || !suspicion.Median || suspicion.Reference != 95000 {
+		t.Errorf("KUCOIN suspicion = %+v, want median reference 95000", suspicion)
+	}
+}
+
+func TestSuspectPricesFallsBackToLastGoodPrice(t *testing.T) {
+	now := time.Now()
+	prices := map[string]float64{"KUCOIN": 0.01, "BINANCE": 95000}
+	known := map[string]goodPrice{"KUCOIN": {Price: 94000, At: now.Add(-time.Hour)}}
+
+	// Avec un seul autre exchange, seul le dernier prix fiable sert de référence
+	suspects := suspectPrices(prices, known, 10, now)
+	if _, ok := suspects["KUCOIN"]; !ok || len(suspects) != 1 {
+		t.Errorf("suspects = %v, want KUCOIN only", suspects)
+	}
+
+	// Un prix fiable trop ancien n'est plus une référence
+	known["KUCOIN"] = goodPrice{Price: 94000, At: now.Add(-goodPriceMaxAge - time.Minute)}
+	if suspects := suspectPrices(prices, known, 10, now); len(suspects) != 0 {
+		t.Errorf("suspects = %v, want none with a stale reference", suspects)
+	}
+}
+
+func TestSuspectPricesDisabled(t *testing.T) {
+	prices := map[string]float64{"BINANCE": 95000, "MEXC": 95050, "KUCOIN": 0.01}
+	if suspects := suspectPrices(prices, nil, 0, time.Now()); len(suspects) != 0 {
+		t.Errorf("suspects = %v, want none when PRICE_SANITY_MAX_DEVIATION=0", suspects)
+	}
+}
+
+func TestMedianPrice(t *testing.T) {
+	cases := []struct {
+		prices []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, c := range cases {
+		if got := medianPrice(c.prices); got != c.want {
+			t.Errorf("medianPrice(%v) = %v, want %v", c.prices, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+)
+
+// priceHistoryDefaultHours est la période affichée par le graphique du tableau de bord
+const priceHistoryDefaultHours = 24
+
+// recordPriceHistory ajoute les prix relevés par la mise à jour à l'historique du tableau de
+// bord (un point par exchange toutes les PRICE_HISTORY_INTERVAL_MINUTES au plus) et supprime
+// les points plus anciens que PRICE_HISTORY_RETENTION_HOURS
+func recordPriceHistory(prices map[string]float64) {
+	if cfg == nil || cfg.PriceHistoryRetentionHours == 0 || len(prices) == 0 || simulating() {
+		return
+	}
+
+	repo := database.GetPriceHistoryRepository()
+	now := time.Now()
+	interval := time.Duration(cfg.PriceHistoryIntervalMinutes) * time.Minute
+	for exchange, price := range prices {
+		point := database.PricePoint{Exchange: exchange, Timestamp: now, Price: price}
+		if _, err := repo.Record(point, interval); err != nil {
+			log.Printf("Erreur lors de l'enregistrement du prix %s: %v", exchange, err)
+		}
+	}
+
+	retention := time.Duration(cfg.PriceHistoryRetentionHours) * time.Hour
+	if _, err := repo.DeleteBefore(now.Add(-retention)); err != nil {
+		log.Printf("Erreur lors de la purge de l'historique des prix: %v", err)
+	}
+}
+
+// priceHistoryExchange retourne l'exchange dont le tableau de bord affiche l'historique du
+// prix: celui du filtre, sinon l'exchange principal. Vide si l'historique est désactivé.
+func priceHistoryExchange(exchangeFilter string) string {
+	if cfg == nil || cfg.PriceHistoryRetentionHours == 0 {
+		return ""
+	}
+	if exchangeFilter != "" {
+		return strings.ToUpper(exchangeFilter)
+	}
+	return cfg.MainExchangeName
+}
+
+// handlePriceHistory retourne l'historique du prix d'un exchange (?exchange=, l'exchange
+// principal par défaut) sur les ?hours= dernières heures (24 par défaut, au plus la durée de
+// conservation), avec les prix d'achat et de vente de ses cycles en cours
+func handlePriceHistory(w http.ResponseWriter, r *http.Request) {
+	if cfg.PriceHistoryRetentionHours == 0 {
+		writeSchedulerError(w, http.StatusNotFound, "Historique des prix désactivé (PRICE_HISTORY_RETENTION_HOURS=0)")
+		return
+	}
+
+	exchange := priceHistoryExchange(r.URL.Query().Get("exchange"))
+	hours := priceHistoryDefaultHours
+	if value := r.URL.Query().Get("hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeSchedulerError(w, http.StatusBadRequest, "hours doit être un nombre d'heures positif")
+			return
+		}
+		hours = parsed
+	}
+	if hours > cfg.PriceHistoryRetentionHours {
+		hours = cfg.PriceHistoryRetentionHours
+	}
+
+	history, err := database.GetPriceHistoryRepository().FindSince(exchange, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors de la lecture de l'historique des prix: "+err.Error())
+		return
+	}
+	points := make([]map[string]interface{}, 0, len(history))
+	for _, point := range history {
+		points = append(points, map[string]interface{}{"t": point.Timestamp.Unix(), "price": point.Price})
+	}
+
+	cycles, err := database.GetRepository().FindActive()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors de la récupération des cycles: "+err.Error())
+		return
+	}
+	markers := make([]map[string]interface{}, 0)
+	for _, cycle := range cycles {
+		if !strings.EqualFold(cycle.Exchange, exchange) {
+			continue
+		}
+		markers = append(markers, map[string]interface{}{
+			"cycleId":   cycle.IdInt,
+			"status":    cycle.Status,
+			"buyPrice":  cycle.BuyPrice,
+			"sellPrice": cycle.SellPrice,
+		})
+	}
+
+	writeSchedulerJSON(w, http.StatusOK, map[string]interface{}{
+		"exchange": exchange,
+		"hours":    hours,
+		"points":   points,
+		"markers":  markers,
+	})
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestPriceHistoryAPI(t *testing.T) {
+	previous := cfg
+	cfg = &config.Config{MainExchangeName: "KRAKEN", PriceHistoryIntervalMinutes: 5, PriceHistoryRetentionHours: 24}
+	t.Cleanup(func() { cfg = previous })
+
+	repo := database.GetPriceHistoryRepository()
+	stale := database.PricePoint{Exchange: "KUCOIN", Timestamp: time.Now().Add(-48 * time.Hour), Price: 50000}
+	if _, err := repo.Record(stale, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le second relevé tombe dans l'intervalle d'échantillonnage et n'est pas conservé
+	recordPriceHistory(map[string]float64{"KRAKEN": 60000})
+	recordPriceHistory(map[string]float64{"KRAKEN": 60100})
+	if old, _ := repo.FindSince("KUCOIN", time.Time{}); len(old) != 0 {
+		t.Errorf("les prix au-delà de la durée de conservation devraient être supprimés: %+v", old)
+	}
+
+	cycle := &database.Cycle{Exchange: "KRAKEN", Status: "sell", Quantity: 0.001, BuyPrice: 59000, SellPrice: 60500}
+	if _, err := database.GetRepository().Save(cycle); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.GetRepository().DeleteByIdInt(cycle.IdInt) })
+
+	recorder := httptest.NewRecorder()
+	handlePriceHistory(recorder, httptest.NewRequest(http.MethodGet, "/api/price-history?hours=1000", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("statut %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response struct {
+		Exchange string `json:"exchange"`
+		Hours    int    `json:"hours"`
+		Points   []struct {
+			Price float64 `json:"price"`
+		} `json:"points"`
+		Markers []struct {
+			CycleId   int32   `json:"cycleId"`
+			BuyPrice  float64 `json:"buyPrice"`
+			SellPrice float64 `json:"sellPrice"`
+		} `json:"markers"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Exchange != "KRAKEN" || response.Hours != 24 {
+		t.Errorf("exchange %q sur %d h, want KRAKEN sur 24 h", response.Exchange, response.Hours)
+	}
+	if len(response.Points) != 1 || response.Points[0].Price != 60000 {
+		t.Errorf("points: %+v, want un seul relevé à 60000", response.Points)
+	}
+	if len(response.Markers) != 1 || response.Markers[0].CycleId != cycle.IdInt || response.Markers[0].SellPrice != 60500 {
+		t.Errorf("repères des cycles en cours: %+v", response.Markers)
+	}
+
+	recorder = httptest.NewRecorder()
+	handlePriceHistory(recorder, httptest.NewRequest(http.MethodGet, "/api/price-history?hours=abc", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("hours invalide: statut %d, want 400", recorder.Code)
+	}
+}
@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/web"
+	"main/internal/webpush"
+
+	"github.com/fatih/color"
+)
+
+// pushSubscriptionsFile conserve, à côté de la base de données, les navigateurs abonnés aux
+// notifications: le serveur web y ajoute les abonnements, la mise à jour des cycles les lit
+const pushSubscriptionsFile = "push_subscriptions.json"
+
+// pushTTL est la durée pendant laquelle le serveur de push conserve une notification destinée
+// à un navigateur injoignable (téléphone éteint...)
+const pushTTL = 24 * time.Hour
+
+// pushSubscription est un navigateur abonné depuis le tableau de bord
+type pushSubscription struct {
+	webpush.Subscription
+	UserAgent string    `json:"userAgent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// pushStore lit et écrit push_subscriptions.json
+type pushStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newPushStore() *pushStore {
+	return &pushStore{path: filepath.Join(filepath.Dir(database.GetDatabasePath()), pushSubscriptionsFile)}
+}
+
+// pushSubscriptions est le fichier des abonnements du serveur web, créé au premier abonnement
+var (
+	pushSubscriptionsOnce sync.Once
+	pushSubscriptions     *pushStore
+)
+
+func serverPushStore() *pushStore {
+	pushSubscriptionsOnce.Do(func() { pushSubscriptions = newPushStore() })
+	return pushSubscriptions
+}
+
+// load retourne les abonnements enregistrés (aucun si le fichier est absent ou illisible)
+func (s *pushStore) load() []pushSubscription {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil
+	}
+	var subscriptions []pushSubscription
+	if err := json.Unmarshal(content, &subscriptions); err != nil {
+		log.Printf("Fichier %s invalide, abonnements push ignorés: %v", pushSubscriptionsFile, err)
+		return nil
+	}
+	return subscriptions
+}
+
+func (s *pushStore) save(subscriptions []pushSubscription) error {
+	content, err := json.MarshalIndent(subscriptions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, content, 0600)
+}
+
+// all retourne les abonnements enregistrés
+func (s *pushStore) all() []pushSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// add enregistre un abonnement, en remplaçant celui du même navigateur (même endpoint)
+func (s *pushStore) add(subscription pushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscriptions := s.load()
+	for i, existing := range subscriptions {
+		if existing.Endpoint == subscription.Endpoint {
+			subscriptions[i] = subscription
+			return s.save(subscriptions)
+		}
+	}
+	return s.save(append(subscriptions, subscription))
+}
+
+// remove supprime l'abonnement d'un navigateur et indique s'il existait
+func (s *pushStore) remove(endpoint string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscriptions := s.load()
+	for i, existing := range subscriptions {
+		if existing.Endpoint == endpoint {
+			return true, s.save(append(subscriptions[:i], subscriptions[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// pushNotifier envoie en Web Push les événements retenus par WEB_PUSH_EVENTS à chaque navigateur
+// abonné. Un abonnement révoqué par le navigateur est supprimé.
+type pushNotifier struct {
+	push   config.WebPush
+	store  *pushStore
+	client *http.Client
+}
+
+func newPushNotifier(c *config.Config) *pushNotifier {
+	return &pushNotifier{
+		push:   c.WebPush,
+		store:  newPushStore(),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *pushNotifier) Name() string {
+	return "push"
+}
+
+func (p *pushNotifier) Notify(n Notification) error {
+	if !p.push.Accepts(n.Event) {
+		return nil
+	}
+	subscriptions := p.store.all()
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(pushPayload(n))
+	if err != nil {
+		return fmt.Errorf("sérialisation de la notification: %v", err)
+	}
+	vapid := webpush.VAPID{PublicKey: p.push.PublicKey, PrivateKey: p.push.PrivateKey, Subject: p.push.Subject}
+
+	var failed []string
+	for _, subscription := range subscriptions {
+		err := webpush.Send(p.client, subscription.Subscription, payload, vapid, pushTTL)
+		if errors.Is(err, webpush.ErrExpired) {
+			if _, err := p.store.remove(subscription.Endpoint); err != nil {
+				failed = append(failed, fmt.Sprintf("suppression de l'abonnement expiré: %v", err))
+			}
+			continue
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", redactURL(subscription.Endpoint), err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// pushPayload est le contenu reçu par le service worker (sw.js): le clic sur la notification
+// ouvre la page du cycle, ou le tableau de bord pour un événement sans cycle
+func pushPayload(n Notification) map[string]string {
+	title := "Bot Spot"
+	if n.Exchange != "" {
+		title += " - " + n.Exchange
+	}
+	url, tag := "/", n.Event
+	if n.Cycle != nil {
+		url = fmt.Sprintf("/cycles/%d", n.Cycle.IdInt)
+		tag = fmt.Sprintf("%s-%d", n.Event, n.Cycle.IdInt)
+	}
+	return map[string]string{"title": title, "body": n.Message, "url": url, "tag": tag}
+}
+
+// pushPublicKey retourne la clé publique VAPID transmise au navigateur, vide si les
+// notifications push ne sont pas configurées
+func pushPublicKey() string {
+	if cfg == nil || !cfg.WebPush.Enabled() {
+		return ""
+	}
+	return cfg.WebPush.PublicKey
+}
+
+// handlePushSubscribe enregistre l'abonnement envoyé par le tableau de bord (PushSubscription en JSON)
+func handlePushSubscribe(w http.ResponseWriter, r *http.Request) {
+	if pushPublicKey() == "" {
+		writeSchedulerError(w, http.StatusNotFound, "Notifications push non configurées (WEB_PUSH_PUBLIC_KEY)")
+		return
+	}
+
+	var subscription webpush.Subscription
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8<<10)).Decode(&subscription); err != nil {
+		writeSchedulerError(w, http.StatusBadRequest, "Abonnement illisible: "+err.Error())
+		return
+	}
+	if err := subscription.Validate(); err != nil {
+		writeSchedulerError(w, http.StatusBadRequest, "Abonnement invalide: "+err.Error())
+		return
+	}
+
+	err := serverPushStore().add(pushSubscription{
+		Subscription: subscription,
+		UserAgent:    r.UserAgent(),
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors de l'enregistrement de l'abonnement: "+err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusCreated, map[string]interface{}{"subscribed": true})
+}
+
+// handlePushUnsubscribe supprime l'abonnement du navigateur ({"endpoint": "..."})
+func handlePushUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8<<10)).Decode(&request); err != nil || request.Endpoint == "" {
+		writeSchedulerError(w, http.StatusBadRequest, "endpoint manquant")
+		return
+	}
+	removed, err := serverPushStore().remove(request.Endpoint)
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors de la suppression de l'abonnement: "+err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusOK, map[string]interface{}{"removed": removed})
+}
+
+// handleStaticFile sert un fichier de l'application installable. Ces fichiers ne contiennent
+// aucune donnée: ils restent accessibles sans connexion, le navigateur chargeant le manifeste
+// sans cookie.
+func handleStaticFile(name, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		content, err := fs.ReadFile(web.Static(), name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		// Le service worker doit être revalidé pour que ses mises à jour soient prises en compte
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(content)
+	}
+}
+
+// PushKeys crée une paire de clés VAPID pour les notifications Web Push (--push-keys)
+func PushKeys() {
+	publicKey, privateKey, err := webpush.GenerateKeys()
+	if err != nil {
+		color.Red("Erreur lors de la création des clés: %v", err)
+		os.Exit(1)
+	}
+	color.Green("Clés VAPID créées, à copier dans %s:", config.ConfigFilename)
+	fmt.Printf("WEB_PUSH_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("WEB_PUSH_PRIVATE_KEY=%s\n", privateKey)
+	if cfg == nil || cfg.WebPush.Subject == "" {
+		fmt.Println("WEB_PUSH_SUBJECT=mailto:vous@example.com")
+	}
+	color.Yellow("La clé privée peut aussi être rangée dans le magasin d'identifiants (keychain:).")
+	color.Yellow("Changer de clés invalide les abonnements existants: réactiver les notifications sur chaque appareil.")
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/webpush"
+)
+
+// testPushSubscription crée l'abonnement d'un navigateur dont le serveur de push est endpoint
+func testPushSubscription(t *testing.T, endpoint string) pushSubscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return pushSubscription{Subscription: webpush.Subscription{
+		Endpoint: endpoint,
+		Keys: webpush.Keys{
+			P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}}
+}
+
+func TestPushNotifierRemovesExpiredSubscriptions(t *testing.T) {
+	var delivered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/revoked" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		delivered = append(delivered, r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := webpush.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &pushStore{path: filepath.Join(t.TempDir(), pushSubscriptionsFile)}
+	for _, path := range []string{"/phone", "/revoked"} {
+		if err := store.add(testPushSubscription(t, server.URL+path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &pushNotifier{
+		push:   config.WebPush{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:bot@example.com"},
+		store:  store,
+		client: server.Client(),
+	}
+
+	// Seuls les cycles complétés sont envoyés par défaut
+	if err := p.Notify(Notification{Event: "buy_filled", Message: "achat exécuté", Timestamp: time.Now()}); err != nil || len(delivered) != 0 {
+		t.Fatalf("buy_filled ne devrait pas être envoyé: %v, %v", delivered, err)
+	}
+
+	cycle := &database.Cycle{IdInt: 12, Exchange: "BINANCE"}
+	err = p.Notify(Notification{Event: "sell_filled", Exchange: "BINANCE", Message: "Cycle 12 complété", Timestamp: time.Now(), Cycle: cycle})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(delivered) != 1 || delivered[0] != "/phone" {
+		t.Errorf("notifications livrées: %v, want /phone", delivered)
+	}
+	if remaining := store.all(); len(remaining) != 1 || remaining[0].Endpoint != server.URL+"/phone" {
+		t.Errorf("l'abonnement révoqué devrait être supprimé: %+v", remaining)
+	}
+
+	if payload := pushPayload(Notification{Event: "sell_filled", Exchange: "BINANCE", Cycle: cycle}); payload["url"] != "/cycles/12" || payload["tag"] != "sell_filled-12" {
+		t.Errorf("contenu de la notification: %v", payload)
+	}
+}
@@ -0,0 +1,26 @@
+package commands
+
+import "main/internal/i18n"
+
+// reportRateLimits résume en fin de mise à jour la consommation de la limite de requêtes de
+// chaque exchange qui la communique, et avertit lorsque le pic relevé atteint warnPercent
+func reportRateLimits(exchanges []string, warnPercent float64) {
+	for _, exchangeName := range exchanges {
+		client := GetClientByExchange(exchangeName)
+		if client == nil {
+			continue
+		}
+		status := client.GetRateLimitStatus()
+		if !status.Known {
+			continue
+		}
+
+		ev := exchangeEvent(exchangeName, "rate_limit").
+			with("used", status.Used).with("peak", status.Peak).with("limit", status.Limit)
+		if warnPercent > 0 && status.PeakPercent() >= warnPercent {
+			ev.warn(i18n.T("update.rate_limit_warning"), exchangeName, status.PeakPercent(), status.Peak, status.Limit, status.Window)
+			continue
+		}
+		ev.info(i18n.T("update.rate_limit_usage"), exchangeName, status.Used, status.Limit, status.Window, status.PeakPercent())
+	}
+}
@@ -0,0 +1,344 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// reportExchangeActivity est l'activité d'un exchange sur la période du rapport. Les champs de
+// ExchangeStats portent sur les cycles complétés dans la période (calculateExchangeStats, comme le
+// serveur de statistiques); ses compteurs d'accumulations, cumulés depuis le début, sont remplacés
+// par ceux de la période.
+type reportExchangeActivity struct {
+	ExchangeStats
+	Opened            int     // cycles créés dans la période
+	Cancelled         int     // cycles annulés dans la période
+	NetProfit         float64 // profit net de frais (calculateProfitByPeriod, comme --update)
+	Fees              float64 // frais des cycles complétés, estimés lorsque l'exchange ne les a pas communiqués
+	AccumulationsSold int     // accumulations remises en vente dans la période
+}
+
+// reportPeriod est l'activité de tous les exchanges sur une période
+type reportPeriod struct {
+	Start, End time.Time
+	Global     CompleteGlobalStats // cycles complétés dans la période (calculateGlobalStats)
+	Exchanges  []reportExchangeActivity
+
+	Opened            int
+	Cancelled         int
+	NetProfit         float64
+	Fees              float64
+	Accumulations     int
+	AccumulatedBTC    float64
+	AccumulationsSold int
+
+	// Meilleur et pire cycle complétés dans la période, d'après leur profit net
+	Best, Worst             *database.Cycle
+	BestProfit, WorstProfit float64
+}
+
+// performanceReport compare une période à la précédente, de même durée
+type performanceReport struct {
+	Days     int
+	Current  reportPeriod
+	Previous reportPeriod
+}
+
+// cycleCancelledInPeriod indique si un cycle a été annulé dans la période. Les cycles annulés
+// avant l'enregistrement de la date d'annulation sont datés de leur création.
+func cycleCancelledInPeriod(cycle *database.Cycle, start, end time.Time) bool {
+	if cycle.Status != "cancelled" {
+		return false
+	}
+	date := cycle.CancelledAt
+	if date.IsZero() {
+		date = cycle.CreatedAt
+	}
+	return !date.Before(start) && !date.After(end)
+}
+
+// cycleNetProfit retourne le profit net d'un cycle complété dans la période, calculé comme le
+// profit par période de --update
+func cycleNetProfit(cycle *database.Cycle, start, end time.Time) float64 {
+	return calculateProfitByPeriod([]*database.Cycle{cycle}, cycle.Exchange, start, end)
+}
+
+// buildReportPeriod calcule l'activité des cycles et des accumulations entre start et end
+func buildReportPeriod(cycles []*database.Cycle, accumulations []*database.Accumulation, start, end time.Time) reportPeriod {
+	period := reportPeriod{Start: start, End: end}
+
+	completed := filterCyclesByPeriod(cycles, &start, &end, dateFieldCompleted)
+	period.Global = calculateGlobalStats(completed)
+
+	activity := make(map[string]*reportExchangeActivity)
+	exchange := func(name string) *reportExchangeActivity {
+		if _, ok := activity[name]; !ok {
+			activity[name] = &reportExchangeActivity{ExchangeStats: ExchangeStats{Name: name}}
+		}
+		return activity[name]
+	}
+
+	for _, stats := range calculateExchangeStats(completed) {
+		entry := exchange(stats.Name)
+		entry.ExchangeStats = stats
+		entry.AccumulationCount, entry.AccumulatedBTC = 0, 0
+		entry.NetProfit = calculateProfitByPeriod(completed, stats.Name, start, end)
+		// Les frais sont l'écart entre le profit brut du serveur de statistiques et le profit net
+		entry.Fees = stats.TotalProfit - entry.NetProfit
+	}
+
+	for _, cycle := range filterCyclesByPeriod(cycles, &start, &end, dateFieldCreated) {
+		exchange(cycle.Exchange).Opened++
+	}
+	for _, cycle := range cycles {
+		if cycleCancelledInPeriod(cycle, start, end) {
+			exchange(cycle.Exchange).Cancelled++
+		}
+	}
+
+	for _, accumulation := range accumulations {
+		if !accumulation.CreatedAt.Before(start) && !accumulation.CreatedAt.After(end) {
+			entry := exchange(accumulation.Exchange)
+			entry.AccumulationCount++
+			entry.AccumulatedBTC += accumulation.Quantity
+		}
+		if accumulation.Converted() && !accumulation.ConvertedAt.Before(start) && !accumulation.ConvertedAt.After(end) {
+			exchange(accumulation.Exchange).AccumulationsSold++
+		}
+	}
+
+	for _, cycle := range completed {
+		profit := cycleNetProfit(cycle, start, end)
+		if period.Best == nil || profit > period.BestProfit {
+			period.Best, period.BestProfit = cycle, profit
+		}
+		if period.Worst == nil || profit < period.WorstProfit {
+			period.Worst, period.WorstProfit = cycle, profit
+		}
+	}
+
+	for _, entry := range activity {
+		period.Exchanges = append(period.Exchanges, *entry)
+		period.Opened += entry.Opened
+		period.Cancelled += entry.Cancelled
+		period.NetProfit += entry.NetProfit
+		period.Fees += entry.Fees
+		period.Accumulations += entry.AccumulationCount
+		period.AccumulatedBTC += entry.AccumulatedBTC
+		period.AccumulationsSold += entry.AccumulationsSold
+	}
+	sort.Slice(period.Exchanges, func(i, j int) bool {
+		return period.Exchanges[i].Name < period.Exchanges[j].Name
+	})
+
+	return period
+}
+
+// buildPerformanceReport calcule le rapport des days derniers jours et de la période précédente
+func buildPerformanceReport(cycles []*database.Cycle, accumulations []*database.Accumulation, days int, now time.Time) performanceReport {
+	start := now.AddDate(0, 0, -days)
+	previousStart := start.AddDate(0, 0, -days)
+	return performanceReport{
+		Days:     days,
+		Current:  buildReportPeriod(cycles, accumulations, start, now),
+		Previous: buildReportPeriod(cycles, accumulations, previousStart, start.Add(-time.Nanosecond)),
+	}
+}
+
+// parseReportPeriod lit une durée de rapport en jours: 7j, 30j... (7d accepté)
+func parseReportPeriod(value string) (int, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(value), "j"), "d")
+	days, err := strconv.Atoi(trimmed)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("période invalide: %s. Utilisez --period=7j", value)
+	}
+	return days, nil
+}
+
+// formatReportDelta présente l'écart avec la période précédente
+func formatReportDelta(current, previous float64, decimals int) string {
+	delta := current - previous
+	sign := "+"
+	if delta < 0 {
+		sign = ""
+	}
+	return fmt.Sprintf("%s%.*f", sign, decimals, delta)
+}
+
+// formatReportCycle présente un cycle du rapport et son profit net
+func formatReportCycle(cycle *database.Cycle, profit float64) string {
+	if cycle == nil {
+		return "-"
+	}
+	return fmt.Sprintf("cycle %d (%s), %.2f USDC", cycle.IdInt, cycle.Exchange, profit)
+}
+
+// writeReportMarkdown écrit le rapport au format Markdown (fichier --output, notification)
+func writeReportMarkdown(w io.Writer, report performanceReport) error {
+	current, previous := report.Current, report.Previous
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", i18n.T("report.title", report.Days))
+	b.WriteString(i18n.T("report.range_compared",
+		i18n.FormatDateTime(current.Start), i18n.FormatDateTime(current.End),
+		i18n.FormatDate(previous.Start), i18n.FormatDate(previous.End)) + "\n\n")
+
+	fmt.Fprintf(&b, "## %s\n\n", i18n.T("report.summary"))
+	fmt.Fprintf(&b, "| | %s | %s | %s |\n|---|---:|---:|---:|\n", i18n.T("report.period"), i18n.T("report.previous"), i18n.T("report.delta"))
+	row := func(label string, cur, prev float64, decimals int) {
+		fmt.Fprintf(&b, "| %s | %.*f | %.*f | %s |\n", label, decimals, cur, decimals, prev, formatReportDelta(cur, prev, decimals))
+	}
+	row(i18n.T("report.opened"), float64(current.Opened), float64(previous.Opened), 0)
+	row(i18n.T("report.completed"), float64(current.Global.CompletedCycles), float64(previous.Global.CompletedCycles), 0)
+	row(i18n.T("report.cancelled"), float64(current.Cancelled), float64(previous.Cancelled), 0)
+	row(i18n.T("report.net_profit"), current.NetProfit, previous.NetProfit, 2)
+	row(i18n.T("report.fees"), current.Fees, previous.Fees, 2)
+	row(i18n.T("report.average_duration"), current.Global.AverageCycleDuration, previous.Global.AverageCycleDuration, 1)
+	row(i18n.T("report.accumulations"), float64(current.Accumulations), float64(previous.Accumulations), 0)
+	row(i18n.T("report.accumulated_btc"), current.AccumulatedBTC, previous.AccumulatedBTC, 8)
+
+	fmt.Fprintf(&b, "\n## %s\n\n", i18n.T("report.by_exchange"))
+	if len(current.Exchanges) == 0 {
+		b.WriteString(i18n.T("report.no_activity") + ".\n")
+	} else {
+		fmt.Fprintf(&b, "| Exchange | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			i18n.T("report.col_opened"), i18n.T("report.col_completed"), i18n.T("report.col_cancelled"),
+			i18n.T("report.col_net_profit"), i18n.T("report.col_fees"), i18n.T("report.col_average_duration"),
+			i18n.T("report.accumulations"), i18n.T("report.accumulated_btc"), i18n.T("report.col_resold"))
+		b.WriteString("|---|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+		for _, entry := range current.Exchanges {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %.2f | %.2f | %.1f | %d | %.8f | %d |\n",
+				entry.Name, entry.Opened, entry.CompletedCycles, entry.Cancelled, entry.NetProfit, entry.Fees,
+				entry.AverageCycleDuration, entry.AccumulationCount, entry.AccumulatedBTC, entry.AccumulationsSold)
+		}
+	}
+
+	b.WriteString("\n## Cycles\n\n")
+	fmt.Fprintf(&b, "- %s\n", i18n.T("report.best", formatReportCycle(current.Best, current.BestProfit)))
+	fmt.Fprintf(&b, "- %s\n", i18n.T("report.worst", formatReportCycle(current.Worst, current.WorstProfit)))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// printReport affiche le rapport dans le terminal
+func printReport(report performanceReport) {
+	current, previous := report.Current, report.Previous
+
+	color.Cyan("=== %s ===", i18n.T("report.title", report.Days))
+	fmt.Printf(i18n.T("report.range")+"\n\n",
+		i18n.FormatDateTime(current.Start), i18n.FormatDateTime(current.End),
+		i18n.FormatDate(previous.Start), i18n.FormatDate(previous.End))
+
+	fmt.Printf("%-22s %14s %14s %14s\n", "", i18n.T("report.period"), i18n.T("report.previous"), i18n.T("report.delta"))
+	line := func(label string, cur, prev float64, decimals int) {
+		fmt.Printf("%-22s %14.*f %14.*f %14s\n", label, decimals, cur, decimals, prev, formatReportDelta(cur, prev, decimals))
+	}
+	line(i18n.T("report.opened"), float64(current.Opened), float64(previous.Opened), 0)
+	line(i18n.T("report.completed"), float64(current.Global.CompletedCycles), float64(previous.Global.CompletedCycles), 0)
+	line(i18n.T("report.cancelled"), float64(current.Cancelled), float64(previous.Cancelled), 0)
+	profitLine := color.GreenString
+	if current.NetProfit < previous.NetProfit {
+		profitLine = color.YellowString
+	}
+	fmt.Println(profitLine("%-22s %14.2f %14.2f %14s", i18n.T("report.net_profit"), current.NetProfit, previous.NetProfit,
+		formatReportDelta(current.NetProfit, previous.NetProfit, 2)))
+	line(i18n.T("report.fees"), current.Fees, previous.Fees, 2)
+	line(i18n.T("report.average_duration"), current.Global.AverageCycleDuration, previous.Global.AverageCycleDuration, 1)
+	line(i18n.T("report.accumulations"), float64(current.Accumulations), float64(previous.Accumulations), 0)
+	line(i18n.T("report.accumulated_btc"), current.AccumulatedBTC, previous.AccumulatedBTC, 8)
+
+	fmt.Println("")
+	color.Cyan(i18n.T("report.by_exchange"))
+	if len(current.Exchanges) == 0 {
+		fmt.Println(i18n.T("report.no_activity"))
+	} else {
+		fmt.Printf("%-8s %7s %9s %7s %12s %10s %10s %6s %12s %8s\n",
+			"Exchange", i18n.T("report.col_opened"), i18n.T("report.col_completed"), i18n.T("report.col_cancelled"),
+			i18n.T("report.col_net_profit"), i18n.T("report.col_fees"), i18n.T("report.col_duration"), i18n.T("report.col_accumulations"),
+			i18n.T("report.accumulated_btc"), i18n.T("report.col_resold"))
+		for _, entry := range current.Exchanges {
+			fmt.Printf("%-8s %7d %9d %7d %12.2f %10.2f %10.1f %6d %12.8f %8d\n",
+				entry.Name, entry.Opened, entry.CompletedCycles, entry.Cancelled, entry.NetProfit, entry.Fees,
+				entry.AverageCycleDuration, entry.AccumulationCount, entry.AccumulatedBTC, entry.AccumulationsSold)
+		}
+	}
+
+	fmt.Println("")
+	color.Green(i18n.T("report.best_cycle"), formatReportCycle(current.Best, current.BestProfit))
+	color.Red(i18n.T("report.worst_cycle"), formatReportCycle(current.Worst, current.WorstProfit))
+}
+
+// Report affiche le rapport de performance d'une période comparée à la précédente, et
+// l'enregistre ou le transmet aux canaux de notification si demandé:
+// --report --period=7j [--output=rapport.md] [--notify]
+func Report() {
+	days := 7
+	output := ""
+	sendNotification := false
+
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--period="):
+			parsed, err := parseReportPeriod(strings.TrimPrefix(arg, "--period="))
+			if err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			days = parsed
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		case arg == "--notify":
+			sendNotification = true
+		}
+	}
+
+	// Les cycles archivés comptent: un cycle complété peut l'être dans la période
+	cycles, accumulations, err := loadTaxData()
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+
+	report := buildPerformanceReport(cycles, accumulations, days, time.Now())
+	printReport(report)
+
+	if output == "" && !sendNotification {
+		return
+	}
+	var markdown strings.Builder
+	if err := writeReportMarkdown(&markdown, report); err != nil {
+		color.Red(i18n.T("report.format_error"), err)
+		os.Exit(1)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, []byte(markdown.String()), 0o644); err != nil {
+			color.Red(i18n.T("report.write_error"), output, err)
+			os.Exit(1)
+		}
+		color.Green(i18n.T("report.saved"), output)
+	}
+
+	if sendNotification {
+		notifiersMu.Lock()
+		configured := len(notifiers)
+		notifiersMu.Unlock()
+		if configured == 0 {
+			color.Yellow(i18n.T("report.no_notifier"))
+			return
+		}
+		exchangeEvent("", "report").with("period_days", days).notify(nil, "%s", markdown.String())
+		color.Green(i18n.T("report.sent"))
+	}
+}
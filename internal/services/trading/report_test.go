@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"main/internal/database"
+)
+
+// reportScenario couvre deux semaines: des cycles complétés, ouverts et annulés sur deux
+// exchanges, un cycle acheté la semaine précédente et vendu cette semaine, et une accumulation
+func reportScenario(now time.Time) ([]*database.Cycle, []*database.Accumulation) {
+	day := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+
+	cycles := []*database.Cycle{
+		{
+			IdInt: 1, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+			BuyPrice: 60000, SellPrice: 61200, TotalFees: 1.21, SellFees: 0.61,
+			CreatedAt: day(5), CompletedAt: day(3),
+		},
+		{
+			// Acheté la semaine précédente: ouvert alors, complété cette semaine
+			IdInt: 2, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+			BuyPrice: 62000, SellPrice: 61800, TotalFees: 1.24, SellFees: 0.62,
+			CreatedAt: day(10), CompletedAt: day(2),
+		},
+		{
+			// Frais non communiqués: estimés comme dans --update
+			IdInt: 3, Exchange: "KRAKEN", Status: "completed", Quantity: 0.02,
+			BuyPrice: 59000, SellPrice: 60500,
+			CreatedAt: day(6), CompletedAt: day(1),
+		},
+		{
+			IdInt: 4, Exchange: "KRAKEN", Status: "cancelled", Quantity: 0.02,
+			BuyPrice: 58000, SellPrice: 59500, CancelReason: "max_age",
+			CreatedAt: day(9), CancelledAt: day(4),
+		},
+		{
+			IdInt: 5, Exchange: "BINANCE", Status: "sell", Quantity: 0.01,
+			BuyPrice: 61000, SellPrice: 62200,
+			CreatedAt: day(1),
+		},
+		{
+			IdInt: 6, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+			BuyPrice: 55000, SellPrice: 56500, TotalFees: 1.12, SellFees: 0.57,
+			CreatedAt: day(12), CompletedAt: day(11),
+		},
+	}
+
+	accumulations := []*database.Accumulation{
+		{
+			IdInt: 1, Exchange: "KRAKEN", CycleIdInt: 7, Quantity: 0.004,
+			OriginalBuyPrice: 57000, TargetSellPrice: 58500, CancelPrice: 61000,
+			CreatedAt: day(2),
+		},
+	}
+
+	return cycles, accumulations
+}
+
+func TestPerformanceReport(t *testing.T) {
+	now := time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)
+	cycles, accumulations := reportScenario(now)
+	report := buildPerformanceReport(cycles, accumulations, 7, now)
+	current, previous := report.Current, report.Previous
+
+	if current.Opened != 3 || current.Global.CompletedCycles != 3 || current.Cancelled != 1 {
+		t.Fatalf("période: %d ouverts, %d complétés, %d annulés, attendu 3, 3, 1",
+			current.Opened, current.Global.CompletedCycles, current.Cancelled)
+	}
+	if previous.Opened != 3 || previous.Global.CompletedCycles != 1 || previous.Cancelled != 0 {
+		t.Fatalf("période précédente: %d ouverts, %d complétés, %d annulés, attendu 3, 1, 0",
+			previous.Opened, previous.Global.CompletedCycles, previous.Cancelled)
+	}
+
+	// Les profits sont ceux de --update et du serveur de statistiques
+	wantNet := calculateProfitByPeriod(cycles, "BINANCE", current.Start, current.End) +
+		calculateProfitByPeriod(cycles, "KRAKEN", current.Start, current.End)
+	if math.Abs(current.NetProfit-wantNet) > 1e-9 {
+		t.Fatalf("profit net %.4f, attendu %.4f", current.NetProfit, wantNet)
+	}
+	if fees := current.Global.TotalProfit - current.NetProfit; math.Abs(current.Fees-fees) > 1e-9 {
+		t.Fatalf("frais %.4f, attendu %.4f", current.Fees, fees)
+	}
+	if current.Best.IdInt != 3 || current.Worst.IdInt != 2 {
+		t.Fatalf("meilleur cycle %d, pire cycle %d, attendu 3 et 2", current.Best.IdInt, current.Worst.IdInt)
+	}
+	if current.Accumulations != 1 || current.AccumulatedBTC != 0.004 {
+		t.Fatalf("accumulations: %d, %.8f BTC, attendu 1, 0.004", current.Accumulations, current.AccumulatedBTC)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReportMarkdown(&buf, report); err != nil {
+		t.Fatalf("écriture du rapport: %v", err)
+	}
+	golden := filepath.Join("testdata", "report_7j.golden.md")
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("écriture de %s: %v", golden, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("lecture de %s: %v", golden, err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("rapport différent de %s:\n--- obtenu\n%s\n--- attendu\n%s", golden, buf.String(), want)
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+)
+
+// buyOrderId retourne l'ID d'un ordre d'achat créé tel qu'enregistré dans le cycle, sans le
+// préfixe C02__ de MEXC
+func buyOrderId(created common.OrderCreateResult, exchange string) string {
+	if exchange == "MEXC" {
+		return strings.TrimPrefix(created.OrderID, "C02__")
+	}
+	return created.OrderID
+}
+
+// repriceBuyOrder replace l'ordre d'achat d'un cycle au prix actuel + BUY_OFFSET après
+// dépassement de BuyMaxPriceDeviation. L'ancien ordre doit déjà être annulé. Le montant
+// en USDC et l'écart entre prix d'achat et prix de vente du cycle sont conservés.
+func repriceBuyOrder(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle,
+	lastPrice float64, exchangeConfig config.ExchangeConfig) error {
+	// L'offset d'achat est négatif après validation de la configuration
+	buyPrice := lastPrice + exchangeConfig.BuyOffsetAt(lastPrice)
+	if buyPrice <= 0 {
+		return fmt.Errorf("prix d'achat calculé invalide: %.2f", buyPrice)
+	}
+
+	spread := cycle.SellPrice - cycle.BuyPrice
+	quantityStr := client.FormatQuantity(CalcAmountBTC(cycle.BuyPrice*cycle.Quantity, buyPrice))
+	quantity, err := strconv.ParseFloat(quantityStr, 64)
+	if err != nil || quantity <= 0 {
+		return fmt.Errorf("quantité calculée invalide: %s", quantityStr)
+	}
+
+	// Chaque replacement a son propre identifiant client: un ordre replacé avant une
+	// interruption est repris au lieu d'être doublé
+	clientOrderID := common.ClientOrderID(cycle.IdInt, fmt.Sprintf("buy-r%d", cycle.RepriceCount+1))
+	var orderId string
+	placedPrice := buyPrice
+	if order, found := findClientOrder(client, clientOrderID); found {
+		orderId, placedPrice, quantity = order.ID, order.Price, order.Quantity
+	} else {
+		created, price, err := createBuyOrder(client, cycle.Exchange, buyPrice, quantityStr, clientOrderID, cycle.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("création du nouvel ordre d'achat: %w", err)
+		}
+		orderId, placedPrice = buyOrderId(created, cycle.Exchange), price
+	}
+
+	update := map[string]interface{}{
+		"buyPrice":         placedPrice,
+		"buyId":            orderId,
+		"buyClientOrderId": clientOrderID,
+		"quantity":         quantity,
+		"sellPrice":        placedPrice + spread,
+		"repriceCount":     cycle.RepriceCount + 1,
+	}
+	if err := repo.UpdateByIdInt(cycle.IdInt, update); err != nil {
+		// Sans mise à jour du cycle, le nouvel ordre ne serait suivi par aucun cycle
+		if _, cancelErr := client.CancelOrder(cleanOrderId(orderId, cycle.Exchange)); cancelErr != nil {
+			return fmt.Errorf("mise à jour du cycle: %v (le nouvel ordre %s n'a pas pu être annulé: %v)", err, orderId, cancelErr)
+		}
+		return fmt.Errorf("mise à jour du cycle: %v", err)
+	}
+
+	cycle.BuyPrice = placedPrice
+	cycle.BuyId = orderId
+	cycle.BuyClientOrderId = clientOrderID
+	cycle.Quantity = quantity
+	cycle.SellPrice = placedPrice + spread
+	cycle.RepriceCount++
+	return nil
+}
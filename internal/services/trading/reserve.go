@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"main/internal/exchanges/common"
+)
+
+// exchangeReserve retourne les soldes qu'un exchange ne doit jamais engager
+// (<EXCHANGE>_RESERVE_BTC et <EXCHANGE>_RESERVE_USDC)
+func exchangeReserve(exchange string) common.Reserve {
+	if cfg == nil {
+		return common.Reserve{}
+	}
+	exchangeConfig := cfg.Exchanges[exchange]
+	return common.Reserve{BTC: exchangeConfig.ReserveBTC, USDC: exchangeConfig.ReserveUSDC}
+}
+
+// usableBalance retourne le solde libre d'un actif diminué de la réserve de l'exchange
+func usableBalance(exchange string, balances map[string]common.DetailedBalance, asset string) float64 {
+	return exchangeReserve(exchange).Usable(balances, asset)
+}
+
+// usableUSDC retourne le solde USDC libre d'un exchange diminué de sa réserve
+func usableUSDC(client common.Exchange, exchange string) float64 {
+	return max(0, client.GetBalanceUSD()-exchangeReserve(exchange).USDC)
+}
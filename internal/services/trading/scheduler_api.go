@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/internal/scheduler"
+	"main/internal/web"
+	"main/pkg/logger"
+)
+
+// loadSchedulerTasks relit tasks.conf dans une instance locale du planificateur.
+// Le daemon, qui tourne dans un autre processus, détecte les modifications du fichier.
+func loadSchedulerTasks() (*scheduler.Scheduler, error) {
+	sched := scheduler.NewScheduler(cfg, logger.NewLogger(logger.LogConfig{
+		Level:  "error",
+		Format: "text",
+	}))
+	if err := sched.LoadTasksFromConfig(); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// schedulerTaskDTOs construit la liste des tâches, complétée par l'état publié par le daemon
+func schedulerTaskDTOs(sched *scheduler.Scheduler, status scheduler.DaemonStatus) []map[string]interface{} {
+	daemonTasks := make(map[string]scheduler.TaskStatus, len(status.Tasks))
+	if status.Running {
+		for _, task := range status.Tasks {
+			daemonTasks[task.Name] = task
+		}
+	}
+
+	var dtos []map[string]interface{}
+	for _, task := range sched.GetAllTasks() {
+		nextRun := task.NextScheduledAt
+		lastRun := task.LastRunTime
+		lastError := ""
+		if daemonTask, ok := daemonTasks[task.Name]; ok {
+			nextRun = daemonTask.NextScheduledAt
+			lastRun = daemonTask.LastRunTime
+			lastError = daemonTask.LastError
+		}
+
+		dtos = append(dtos, map[string]interface{}{
+			"name":             task.Name,
+			"type":             task.Type,
+			"exchange":         task.Exchange,
+			"enabled":          task.Enabled,
+			"interval":         scheduler.FormatIntervalToString(task.IntervalValue, task.IntervalUnit),
+			"specificTime":     task.SpecificTime,
+			"maxAgeDays":       task.MaxAgeDays,
+			"nextRun":          nextRun,
+			"nextRunFormatted": formatSchedulerTime(nextRun),
+			"lastRun":          lastRun,
+			"lastRunFormatted": formatSchedulerTime(lastRun),
+			"lastError":        lastError,
+		})
+	}
+	return dtos
+}
+
+// schedulerHistoryLimit est le nombre d'exécutions affichées dans l'onglet Planificateur
+const schedulerHistoryLimit = 20
+
+// schedulerRunDTOs construit la liste des exécutions pour l'affichage et l'API
+func schedulerRunDTOs(runs []scheduler.TaskRun) []map[string]interface{} {
+	dtos := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		dtos = append(dtos, map[string]interface{}{
+			"task":               run.Task,
+			"type":               run.Type,
+			"exchange":           run.Exchange,
+			"startedAt":          run.StartedAt,
+			"startedAtFormatted": formatSchedulerTime(run.StartedAt),
+			"durationMs":         run.Duration.Milliseconds(),
+			"duration":           run.Duration.Round(100 * time.Millisecond).String(),
+			"success":            run.Success,
+			"skipped":            run.Skipped,
+			"outcome":            run.Outcome(),
+			"error":              run.Error,
+			"cycleIds":           run.CycleIDs,
+			"cancelledCycleIds":  run.CancelledIDs,
+		})
+	}
+	return dtos
+}
+
+// formatSchedulerTime formate une date d'exécution pour l'affichage
+func formatSchedulerTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02/01/2006 15:04:05")
+}
+
+// writeSchedulerJSON envoie une réponse JSON de l'API du planificateur
+func writeSchedulerJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(payload)
+}
+
+// writeSchedulerError envoie une erreur JSON de l'API du planificateur
+func writeSchedulerError(w http.ResponseWriter, code int, message string) {
+	writeSchedulerJSON(w, code, map[string]interface{}{"error": message})
+}
+
+// handleSchedulerPage affiche l'onglet Planificateur du tableau de bord
+func handleSchedulerPage(w http.ResponseWriter, r *http.Request) {
+	sched, err := loadSchedulerTasks()
+	if err != nil {
+		http.Error(w, "Erreur lors du chargement des tâches: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	status, err := scheduler.ReadDaemonStatus()
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'état du planificateur: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	runs, err := scheduler.ReadTaskHistory("", schedulerHistoryLimit)
+	if err != nil {
+		http.Error(w, "Erreur lors de la lecture de l'historique du planificateur: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, web.SchedulerTemplate, map[string]interface{}{
+		"tasks":         schedulerTaskDTOs(sched, status),
+		"history":       schedulerRunDTOs(runs),
+		"daemonRunning": status.Running,
+		"daemonPID":     status.PID,
+		"daemonSince":   formatSchedulerTime(status.StartedAt),
+		"readOnly":      readOnly(),
+		"currentTime":   time.Now().Format("02/01/2006 15:04:05"),
+	})
+}
+
+// handleSchedulerTasks liste les tâches planifiées et leur prochaine exécution
+func handleSchedulerTasks(w http.ResponseWriter, r *http.Request) {
+	sched, err := loadSchedulerTasks()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors du chargement des tâches: "+err.Error())
+		return
+	}
+	status, err := scheduler.ReadDaemonStatus()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeSchedulerJSON(w, http.StatusOK, map[string]interface{}{
+		"daemonRunning": status.Running,
+		"tasks":         schedulerTaskDTOs(sched, status),
+	})
+}
+
+// handleSchedulerStatus retourne l'état du daemon du planificateur
+func handleSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := scheduler.ReadDaemonStatus()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusOK, status)
+}
+
+// handleSchedulerHistory retourne les dernières exécutions des tâches, de la plus récente à la
+// plus ancienne (?task= pour une seule tâche, ?limit= pour leur nombre, 50 par défaut)
+func handleSchedulerHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeSchedulerError(w, http.StatusBadRequest, "Paramètre limit invalide: "+value)
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := scheduler.ReadTaskHistory(r.URL.Query().Get("task"), limit)
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusOK, map[string]interface{}{
+		"runs": schedulerRunDTOs(runs),
+	})
+}
+
+// handleSchedulerRunNow demande au daemon d'exécuter immédiatement une tâche
+func handleSchedulerRunNow(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	sched, err := loadSchedulerTasks()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors du chargement des tâches: "+err.Error())
+		return
+	}
+	if !schedulerHasTask(sched, name) {
+		writeSchedulerError(w, http.StatusNotFound, "Tâche non trouvée: "+name)
+		return
+	}
+
+	status, err := scheduler.ReadDaemonStatus()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !status.Running {
+		writeSchedulerError(w, http.StatusConflict, "Le planificateur n'est pas démarré (bot-spot -plan start)")
+		return
+	}
+
+	if err := scheduler.RequestRunNow(name); err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusAccepted, map[string]interface{}{
+		"task":    name,
+		"message": "Exécution demandée au planificateur",
+	})
+}
+
+// handleSchedulerEnable active une tâche planifiée
+func handleSchedulerEnable(w http.ResponseWriter, r *http.Request) {
+	setSchedulerTaskEnabled(w, r, true)
+}
+
+// handleSchedulerDisable désactive une tâche planifiée
+func handleSchedulerDisable(w http.ResponseWriter, r *http.Request) {
+	setSchedulerTaskEnabled(w, r, false)
+}
+
+// setSchedulerTaskEnabled modifie tasks.conf; le daemon recharge le fichier de lui-même
+func setSchedulerTaskEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	name := r.PathValue("name")
+
+	sched, err := loadSchedulerTasks()
+	if err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, "Erreur lors du chargement des tâches: "+err.Error())
+		return
+	}
+	if !schedulerHasTask(sched, name) {
+		writeSchedulerError(w, http.StatusNotFound, "Tâche non trouvée: "+name)
+		return
+	}
+
+	if err := sched.SetTaskEnabled(name, enabled); err != nil {
+		writeSchedulerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSchedulerJSON(w, http.StatusOK, map[string]interface{}{
+		"task":    name,
+		"enabled": enabled,
+	})
+}
+
+// schedulerHasTask indique si une tâche portant ce nom existe
+func schedulerHasTask(sched *scheduler.Scheduler, name string) bool {
+	for _, task := range sched.GetAllTasks() {
+		if task.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/exchanges/binance"
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/kraken"
+	"main/internal/exchanges/kucoin"
+	"main/internal/exchanges/mexc"
+
+	"github.com/fatih/color"
+)
+
+// SetSecret demande les clés API d'un exchange, les enregistre dans le magasin d'identifiants
+// du système et remplace leurs valeurs dans bot.conf par des références keychain:
+// (--set-secret binance). Les clés saisies ne sont jamais affichées.
+func SetSecret(exchange string) {
+	exchange = strings.ToUpper(exchange)
+	switch exchange {
+	case "BINANCE", "MEXC", "KUCOIN", "KRAKEN":
+	default:
+		color.Red("Exchange invalide %q. Utilisation: --set-secret binance|mexc|kucoin|kraken", exchange)
+		os.Exit(1)
+	}
+
+	if _, err := config.CreateConfigFileIfNotExists(); err != nil {
+		color.Red("Erreur lors de la création de %s: %v", config.ConfigFilename, err)
+		os.Exit(1)
+	}
+
+	secretPrompt := fmt.Sprintf("%s_SECRET_KEY: ", exchange)
+	if exchange == "KUCOIN" {
+		secretPrompt = "KUCOIN_SECRET_KEY (format SECRET_KEY:PassPhrase): "
+	}
+
+	prompts := []struct {
+		name   string
+		prompt string
+	}{
+		{fmt.Sprintf("%s_API_KEY", exchange), fmt.Sprintf("%s_API_KEY: ", exchange)},
+		{fmt.Sprintf("%s_SECRET_KEY", exchange), secretPrompt},
+	}
+
+	color.Cyan("Les clés saisies ne s'affichent pas et sont enregistrées dans le magasin d'identifiants du système")
+	for _, p := range prompts {
+		value, err := config.ReadSecretInput(p.prompt)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		if value == "" {
+			color.Red("%s vide: aucune modification", p.name)
+			os.Exit(1)
+		}
+
+		if err := config.WriteKeychain(p.name, value); err != nil {
+			color.Red("Enregistrement de %s dans le magasin d'identifiants impossible: %v", p.name, err)
+			os.Exit(1)
+		}
+		if err := config.SetConfigValue(p.name, config.KeychainReference(p.name)); err != nil {
+			color.Red("%s enregistrée, mais %v", p.name, err)
+			os.Exit(1)
+		}
+		color.Green("%s enregistrée (%s=%s)", p.name, p.name, config.KeychainReference(p.name))
+	}
+}
+
+// CheckExchangeKeys vérifie en direct des clés API avant leur enregistrement (--setup): connexion
+// à l'exchange, puis lecture des soldes qui exige une signature acceptée
+func CheckExchangeKeys(exchange, apiKey, secretKey string) error {
+	var client common.Exchange
+	switch strings.ToUpper(exchange) {
+	case "BINANCE":
+		client = binance.NewClient(apiKey, secretKey)
+	case "MEXC":
+		client = mexc.NewClient(apiKey, secretKey)
+	case "KUCOIN":
+		client = kucoin.NewClient(apiKey, secretKey)
+	case "KRAKEN":
+		client = kraken.NewClient(apiKey, secretKey)
+	default:
+		return fmt.Errorf("exchange non supporté: %s", exchange)
+	}
+
+	if err := client.CheckConnection(); err != nil {
+		return fmt.Errorf("connexion à %s impossible: %w", exchange, err)
+	}
+	if _, err := client.GetDetailedBalances(); err != nil {
+		return fmt.Errorf("clés refusées par %s: %w", exchange, err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+
+	"github.com/fatih/color"
+)
+
+// SetSellPrice remplace le prix de vente d'un cycle: --set-sell-price --id=123 --price=98000
+func SetSellPrice() {
+	var idStr, priceStr string
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--id="):
+			idStr = strings.TrimPrefix(arg, "--id=")
+		case strings.HasPrefix(arg, "--price="):
+			priceStr = strings.TrimPrefix(arg, "--price=")
+		}
+	}
+
+	if idStr == "" || priceStr == "" {
+		color.Red("Utilisation: --set-sell-price --id=123 --price=98000")
+		os.Exit(1)
+	}
+
+	idInt, err := strconv.Atoi(idStr)
+	if err != nil {
+		color.Red("ID invalide: %s", idStr)
+		os.Exit(1)
+	}
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		color.Red("Prix invalide: %s", priceStr)
+		os.Exit(1)
+	}
+
+	cycle, err := overrideSellPrice(int32(idInt), price)
+	if err != nil {
+		color.Red("Modification du prix de vente impossible: %v", err)
+		os.Exit(1)
+	}
+
+	color.Green("Cycle %d: nouvel ordre de vente %s à %.2f USDC (montant prévu: %.2f USDC)",
+		cycle.IdInt, cycle.SellId, cycle.SellPrice, cycle.SaleAmountUSDC)
+}
+
+// overrideSellPrice annule l'ordre de vente d'un cycle et le remplace par un ordre
+// limite au prix donné. Le cycle mis à jour est retourné.
+func overrideSellPrice(idInt int32, price float64) (*database.Cycle, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("le prix doit être positif")
+	}
+
+	repo := database.GetRepository()
+	cycle, err := repo.FindByIdInt(idInt)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération du cycle: %w", err)
+	}
+	if cycle == nil {
+		return nil, fmt.Errorf("cycle avec ID %d introuvable", idInt)
+	}
+	if cycle.Status != "sell" {
+		return nil, fmt.Errorf("le cycle %d a le statut '%s', seul un cycle en vente peut être modifié", idInt, cycle.Status)
+	}
+
+	// Ne pas laisser GetClientByExchange arrêter le processus (serveur web)
+	exchangeConfig := cfg.Exchanges[cycle.Exchange]
+	if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+		return nil, fmt.Errorf("clés API %s non configurées", cycle.Exchange)
+	}
+	client := GetClientByExchange(cycle.Exchange)
+
+	ev := cycleEvent(cycle, "sell_price_override").with("order_id", cycle.SellId).with("price", price)
+
+	// Le prix doit respecter le pas de prix du symbole
+	tickSize := client.Precision().PriceTick
+	ticks := math.Round(price / tickSize)
+	if math.Abs(ticks*tickSize-price) > tickSize*1e-6 {
+		return nil, fmt.Errorf("le prix %v n'est pas un multiple du pas de prix %v (ex: %s)",
+			price, tickSize, common.FormatPrice(ticks*tickSize, tickSize))
+	}
+	if price < cycle.EffectiveBuyPrice() {
+		ev.warn("Cycle %d: le prix de vente %.2f est inférieur au prix d'achat %.2f, le cycle sera vendu à perte",
+			cycle.IdInt, price, cycle.EffectiveBuyPrice())
+	}
+
+	// Le BTC du cycle doit être présent (libre ou bloqué par l'ordre de vente actuel)
+	balances, err := client.GetDetailedBalances()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des soldes: %w", err)
+	}
+	if held := balances["BTC"].Free + balances["BTC"].Locked; held < cycle.Quantity*0.95 {
+		return nil, fmt.Errorf("solde BTC insuffisant (%.8f) pour la quantité du cycle (%.8f)", held, cycle.Quantity)
+	}
+
+	// Annuler l'ordre de vente existant
+	if cycle.SellId != "" {
+		orderId := cleanOrderId(cycle.SellId, cycle.Exchange)
+		result, err := safeOrderCancel(client, orderId, cycle.IdInt)
+		switch result {
+		case common.AlreadyGone:
+			return nil, fmt.Errorf("l'ordre de vente %s n'est plus ouvert (probablement exécuté): lancez une mise à jour avant de modifier le prix", cycle.SellId)
+		case common.CancelFailed:
+			return nil, fmt.Errorf("échec de l'annulation de l'ordre de vente %s: %v", cycle.SellId, err)
+		}
+		ev.info("Cycle %d: ordre de vente %s annulé", cycle.IdInt, cycle.SellId)
+	}
+
+	orderIdStr, quantityToSell, err := placeSellOrder(client, cycle, price)
+	if err != nil {
+		// Un cycle en vente sans ordre serait supprimé au démarrage: replacer l'ordre précédent
+		restoredId, _, restoreErr := placeSellOrder(client, cycle, cycle.SellPrice)
+		if restoreErr != nil {
+			return nil, fmt.Errorf("%v; l'ordre précédent n'a pas pu être replacé (%v), replacez la vente manuellement", err, restoreErr)
+		}
+		restored := database.SellOrder{OrderId: restoredId, Price: cycle.SellPrice, SaleAmountUSDC: cycle.SaleAmountUSDC}
+		if updateErr := saveReplacedSell(repo, cycle, restored); updateErr != nil {
+			return nil, fmt.Errorf("%v; ordre précédent replacé (%s) mais non enregistré: %v", err, restoredId, updateErr)
+		}
+		return nil, fmt.Errorf("%v; ordre précédent replacé à %.2f USDC (%s)", err, cycle.SellPrice, restoredId)
+	}
+
+	saleAmountUSDC := price * quantityToSell
+	err = saveReplacedSell(repo, cycle, database.SellOrder{OrderId: orderIdStr, Price: price, SaleAmountUSDC: saleAmountUSDC})
+	if err != nil {
+		return nil, fmt.Errorf("ordre %s placé mais erreur lors de la mise à jour du cycle: %w", orderIdStr, err)
+	}
+
+	cycle.SellPrice = price
+	cycle.SellId = orderIdStr
+	cycle.SellClientOrderId = ""
+	cycle.StopId, cycle.StopPrice = "", 0
+	cycle.SaleAmountUSDC = saleAmountUSDC
+	ev.with("order_id", orderIdStr).success("Cycle %d: prix de vente modifié manuellement à %.2f USDC", cycle.IdInt, price)
+
+	return cycle, nil
+}
+
+// saveReplacedSell enregistre la vente qui remplace celle d'un cycle en vente: placée si le cycle
+// n'avait pas d'ordre (vente en attente), remplacée sinon, sous réserve que la vente enregistrée
+// soit toujours celle que le cycle a annulée
+func saveReplacedSell(repo *database.CycleRepository, cycle *database.Cycle, order database.SellOrder) error {
+	if cycle.SellId == "" {
+		return repo.MarkSellPlaced(cycle.IdInt, order)
+	}
+	return repo.ReplaceSell(cycle.IdInt, cycle.SellId, order)
+}
+
+// placeSellOrder place un ordre de vente limite pour la quantité du cycle, une fois
+// le BTC bloqué par l'ancien ordre libéré. L'ID de l'ordre et la quantité sont retournés.
+func placeSellOrder(client common.Exchange, cycle *database.Cycle, price float64) (string, float64, error) {
+	availableBTC, err := waitForFreeBTC(client, cycle.Exchange, cycle.Quantity)
+	if err != nil {
+		return "", 0, err
+	}
+
+	quantityToSell := math.Min(cycle.Quantity, availableBTC)
+	created, err := client.CreateOrder("SELL", client.FormatPrice(price), client.FormatQuantity(quantityToSell))
+	if err != nil {
+		return "", 0, fmt.Errorf("erreur lors de la création de l'ordre de vente: %w", err)
+	}
+	return created.OrderID, quantityToSell, nil
+}
+
+// waitForFreeBTC attend que le BTC bloqué par un ordre annulé soit libéré et retourne le solde
+// BTC libre hors réserve, qui doit couvrir au moins 95 % de la quantité à vendre
+func waitForFreeBTC(client common.Exchange, exchange string, quantity float64) (float64, error) {
+	availableBTC := 0.0
+	for attempt := 0; attempt < 5; attempt++ {
+		balances, err := client.GetDetailedBalances()
+		if err == nil {
+			availableBTC = usableBalance(exchange, balances, "BTC")
+			if availableBTC >= quantity*0.95 {
+				break
+			}
+		}
+		time.Sleep(2 * time.Second)
+	}
+	if availableBTC < quantity*0.95 {
+		return 0, fmt.Errorf("BTC disponible insuffisant (%.8f pour %.8f)", availableBTC, quantity)
+	}
+	return availableBTC, nil
+}
@@ -1,601 +1,114 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
 	"main/internal/config"
 	"main/internal/database"
+	"main/internal/i18n"
+	"main/internal/web"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// Template HTML intégré directement dans le code - version améliorée avec accumulation
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="fr">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Cryptomancien - Neodream Bot - Tableau de bord</title>
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bootstrap@5.2.3/dist/css/bootstrap.min.css">
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/flatpickr/dist/flatpickr.min.css">
-    <script src="https://cdn.jsdelivr.net/npm/flatpickr"></script>
-    <script src="https://cdn.jsdelivr.net/npm/flatpickr/dist/l10n/fr.js"></script>
-    
-    <style>
-        body {
-            padding-top: 20px;
-            background-color: #f8f9fa;
-        }
-        .status-buy {
-            color: #28a745;
-            font-weight: bold;
-        }
-        .status-sell {
-            color: #ffc107;
-            font-weight: bold;
-        }
-        .status-completed {
-            color: #0275d8;
-            font-weight: bold;
-        }
-        .status-cancelled {
-            color: #d9534f;
-            font-weight: bold;
-        }
-        .profit-positive {
-            color: #28a745;
-        }
-        .profit-negative {
-            color: #d9534f;
-        }
-        .header-buttons {
-            margin-bottom: 20px;
-        }
-        .filter-card {
-            background-color: #fff;
-            border-radius: 0.5rem;
-            box-shadow: 0 0.125rem 0.25rem rgba(0, 0, 0, 0.075);
-            margin-bottom: 1.5rem;
-            padding: 1rem;
-        }
-        .nav-pills .nav-link {
-            margin-right: 0.5rem;
-        }
-        .tax-important {
-            background-color: #fff3cd;
-            padding: 0.5rem;
-            border-radius: 0.25rem;
-            font-weight: bold;
-        }
-        .tax-badge {
-            padding: 0.35em 0.65em;
-            font-size: 0.75em;
-            font-weight: 700;
-            border-radius: 0.25rem;
-            margin-left: 0.5rem;
-        }
-		.exchange-order-id {
-			word-wrap: break-word;  /* Permettre le retour à la ligne */
-			font-size: 0.4em;  /* Réduire la taille de police */
-			overflow: hidden;  /* Cacher le contenu qui dépasse */
-			text-overflow: ellipsis;  /* Ajouter des points de suspension (...) si trop long */
-			white-space: normal;  /* Autoriser le retour à la ligne */
-		}	
-    </style>
-</head>
-<body>
-<input type="hidden" id="accumulationField" name="accumulation" value="{{ if .showAccumulation }}true{{ else }}false{{ end }}">
-    <div class="container">
-        <h1 class="mb-4">Cryptomancien - Neodream - Bot - Tableau de bord</h1>
-        
-        <!-- Filtres améliorés -->
-        <div class="filter-card">
-            <form id="filtersForm" method="get" action="/">
-                <div class="row g-3 align-items-end">
-                    <!-- Vue -->
-                    <div class="col-md-3">
-                        <label class="form-label">Vue</label>
-                        <div class="btn-group w-100" role="group">
-                            <input type="radio" class="btn-check" name="complete" id="allCycles" value="false" autocomplete="off" {{ if not .showCompleted }}checked{{ end }}>
-                            <label class="btn btn-outline-primary" for="allCycles">Tous les cycles</label>
-                            
-                            <input type="radio" class="btn-check" name="complete" id="completedCycles" value="true" autocomplete="off" {{ if .showCompleted }}checked{{ end }}>
-                            <label class="btn btn-outline-primary" for="completedCycles">Complétés</label>
-                        </div>
-                    </div>
-                    
-                    <!-- Exchange -->
-                    <div class="col-md-3">
-                        <label for="exchangeFilter" class="form-label">Exchange</label>
-                        <select id="exchangeFilter" name="exchange" class="form-select">
-                            <option value="">Tous les exchanges</option>
-                            {{ range .exchanges }}
-                                <option value="{{ . }}" {{ if eq $.exchangeFilter . }}selected{{ end }}>{{ . }}</option>
-                            {{ end }}
-                        </select>
-                    </div>
-                    
-                    <!-- Période -->
-                    <div class="col-md-3">
-                        <label for="periodFilter" class="form-label">Période</label>
-                        <select id="periodFilter" name="period" class="form-select">
-                            <option value="">Toutes les périodes</option>
-                            {{ range .periodOptions }}
-                                <option value="{{ .value }}" {{ if eq $.periodFilter .value }}selected{{ end }}>{{ .label }}</option>
-                            {{ end }}
-                        </select>
-                    </div>
-                    
-                    <div class="col-md-3">
-                        <label class="form-label">Vue spéciale</label>
-                        <select id="viewMode" name="view_mode" class="form-select" onchange="toggleViewMode(this.value)">
-                            <option value="cycles" {{ if not .showAccumulation }}selected{{ end }}>Cycles de trading</option>
-                            <option value="accumulation" {{ if .showAccumulation }}selected{{ end }}>Accumulations</option>
-                        </select>
-                    </div>
-                </div>
-                
-                <!-- Dates personnalisées - affichées uniquement si aucune période n'est sélectionnée -->
-                <div class="row g-3 mt-2" id="customDatesRow">
-                    <div class="col-md-4">
-                        <label for="startDate" class="form-label">Date de début</label>
-                        <input type="date" id="startDate" name="start_date" class="form-control" value="{{ .startDate }}">
-                    </div>
-                    <div class="col-md-4">
-                        <label for="endDate" class="form-label">Date de fin</label>
-                        <input type="date" id="endDate" name="end_date" class="form-control" value="{{ .endDate }}">
-                    </div>
-                    <div class="col-md-4 d-flex align-items-end">
-                        <button type="submit" class="btn btn-primary me-2">Filtrer</button>
-                        <a href="/" class="btn btn-outline-secondary">Réinitialiser</a>
-                    </div>
-                </div>
-            </form>
-        </div>
-
-        <!-- Statistiques générales -->
-        <div class="row mb-4">
-            <div class="col-md-3">
-                <div class="card bg-light">
-                    <div class="card-body">
-                        <h5 class="card-title">Cycles totaux</h5>
-                        <p class="card-text fs-4">{{ .cyclesCount }}</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card bg-success text-white">
-                    <div class="card-body">
-                        <h5 class="card-title">Cycles d'achat</h5>
-                        <p class="card-text fs-4">{{ .buyCycles }}</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card bg-warning">
-                    <div class="card-body">
-                        <h5 class="card-title">Cycles de vente</h5>
-                        <p class="card-text fs-4">{{ .sellCycles }}</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card bg-primary text-white">
-                    <div class="card-body">
-                        <h5 class="card-title">Cycles complétés</h5>
-                        <p class="card-text fs-4">{{ .cyclesCompleted }}</p>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <div class="row mb-4">
-            <div class="col-md-4">
-                <div class="card bg-light">
-                    <div class="card-body">
-                        <h5 class="card-title">Volume total d'achat</h5>
-                        <p class="card-text fs-4">{{ printf "%.2f" .totalBuy }} USDC</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-4">
-                <div class="card bg-light">
-                    <div class="card-body">
-                        <h5 class="card-title">Volume total de vente</h5>
-                        <p class="card-text fs-4">{{ printf "%.2f" .totalSell }} USDC</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-4">
-                <div class="card {{ if gt .gainAbs 0.0 }}bg-success text-white{{ else }}bg-danger text-white{{ end }}">
-                    <div class="card-body">
-                        <h5 class="card-title">Gain total</h5>
-                        <p class="card-text fs-4">
-                            {{ printf "%.2f" .gainAbs }} USDC ({{ printf "%.2f" .gainPercent }}%)
-                        </p>
-                    </div>
-                </div>
-            </div>
-        </div>
-		
-
-        {{ if .showAccumulation }}
-        <!-- Début de la section à remplacer pour les cycles (pas les accumulations) -->
-
-        <h2 class="mb-3">
-            {{ if .showCompleted }}
-                Cycles complétés
-            {{ else }}
-                {{ if .showAll }}Tous les cycles{{ else }}Cycles actifs{{ end }}
-            {{ end }}
-            {{ if .exchangeFilter }} - {{ .exchangeFilter }}{{ end }}
-            {{ if .periodFilter }} - {{ .periodFilter }}{{ end }}
-            {{ if .startDate }} - Du {{ .startDate }}{{ end }}
-            {{ if .endDate }} au {{ .endDate }}{{ end }}
-        </h2>
-
-        <div class="table-responsive">
-            <table class="table table-striped">
-                <thead>
-					<tr>
-						<th>ID</th>
-						<th>Exchange</th>
-						<th>Statut</th>
-						<th>Date achat</th>
-						<th>Date vente</th>
-						<th>Quantité BTC</th>
-						<th>Montant USDC</th>
-						<th>Montant vente</th>
-						<th>Gains</th>
-						<!-- Suppression de la colonne "Frais" -->
-						<th>Année fiscale</th>
-						<th>Durée</th>
-						<th>ID Exchange Ordre Achat</th>
-						<th>ID Exchange Ordre Vente</th>
-					</tr>
-				</thead>
-				<tbody>
-					{{ range .Cycles }}
-					<tr>
-						<td>{{ .idInt }}</td>
-						<td>{{ .exchange }}</td>
-						<td class="status-{{ .status }}">{{ .formattedStatus }}</td>
-						<td>{{ .buyDate }}</td>
-						<td>{{ .sellDateFormatted }}</td>
-						<td>{{ printf "%.8f" .quantity }}</td>
-						<td>{{ printf "%.8f" .buyTotal }}</td>
-						<td>
-							{{ if eq .status "completed" }}{{ printf "%.8f" .sellTotal }}
-							{{ else if eq .status "sell" }}{{ printf "%.8f" .sellTotal }}
-							{{ else }}-{{ end }}
-						</td>
-						<td class="{{ if gt .profit 0.0 }}profit-positive{{ else if lt .profit 0.0 }}profit-negative{{ end }}">
-							{{ if eq .status "completed" }}
-								{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-							{{ else if eq .status "sell" }}
-								{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-							{{ else }}
-								-
-							{{ end }}
-						</td>
-						<!-- Suppression de l'affichage des frais -->
-						<td>
-							{{ .taxYear }}
-							{{ if eq .status "completed" }}
-								{{ if .declareThisYear }}
-								<span class="badge bg-danger tax-badge">À déclarer</span>
-								{{ end }}
-							{{ end }}
-						</td>
-						<td>{{ if .formattedDuration }}{{ .formattedDuration }}{{ else }}{{ formatAge .age }}{{ end }}</td>
-						<td><small class="exchange-order-id">{{ .buyId }}</small></td>
-						<td><small class="exchange-order-id">{{ .sellId }}</small></td>
-					</tr>
-					{{ end }}
-				</tbody>
-            </table>
-        </div>
-
-        {{ if .hasAccumulations }}
-        <div class="row mb-4">
-            <div class="col-12">
-                <h3 class="mb-3">Détail des accumulations</h3>
-                <div class="table-responsive">
-                    <table class="table table-striped small">
-                        <thead>
-							<tr>
-								<th>ID</th>
-								<th>Exchange</th>
-								<th>Statut</th>
-								<th>Date achat</th>
-								<th>Date vente</th>
-								<th>Quantité BTC</th>
-								<th>Montant USDC</th>
-								<th>Montant vente</th>
-								<th>Gains</th>
-								<!-- Suppression de la colonne "Frais" -->
-								<th>Année fiscale</th>
-								<th>Durée</th>
-								<th>ID Exchange Ordre Achat</th>
-								<th>ID Exchange Ordre Vente</th>
-							</tr>
-						</thead>
-						<tbody>
-							{{ range .Cycles }}
-							<tr>
-								<td>{{ .idInt }}</td>
-								<td>{{ .exchange }}</td>
-								<td class="status-{{ .status }}">{{ .formattedStatus }}</td>
-								<td>{{ .buyDate }}</td>
-								<td>{{ .sellDateFormatted }}</td>
-								<td>{{ printf "%.8f" .quantity }}</td>
-								<td>{{ printf "%.8f" .buyTotal }}</td>
-								<td>
-									{{ if eq .status "completed" }}{{ printf "%.8f" .sellTotal }}
-									{{ else if eq .status "sell" }}{{ printf "%.8f" .sellTotal }}
-									{{ else }}-{{ end }}
-								</td>
-								<td class="{{ if gt .profit 0.0 }}profit-positive{{ else if lt .profit 0.0 }}profit-negative{{ end }}">
-									{{ if eq .status "completed" }}
-										{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-									{{ else if eq .status "sell" }}
-										{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-									{{ else }}
-										-
-									{{ end }}
-								</td>
-								<!-- Suppression de l'affichage des frais -->
-								<td>
-									{{ .taxYear }}
-									{{ if eq .status "completed" }}
-										{{ if .declareThisYear }}
-										<span class="badge bg-danger tax-badge">À déclarer</span>
-										{{ end }}
-									{{ end }}
-								</td>
-								<td>{{ if .formattedDuration }}{{ .formattedDuration }}{{ else }}{{ formatAge .age }}{{ end }}</td>
-								<td><small class="exchange-order-id">{{ .buyId }}</small></td>
-								<td><small class="exchange-order-id">{{ .sellId }}</small></td>
-							</tr>
-							{{ end }}
-						</tbody>
-                    </table>
-                </div>
-            </div>
-        </div>
-        {{ end }}
-        {{ else }}
-        <h2 class="mb-3">
-            {{ if .showCompleted }}
-                Cycles complétés
-            {{ else }}
-                {{ if .showAll }}Tous les cycles{{ else }}Cycles actifs{{ end }}
-            {{ end }}
-            {{ if .exchangeFilter }} - {{ .exchangeFilter }}{{ end }}
-            {{ if .periodFilter }} - {{ .periodFilter }}{{ end }}
-            {{ if .startDate }} - Du {{ .startDate }}{{ end }}
-            {{ if .endDate }} au {{ .endDate }}{{ end }}
-        </h2>
-
-        <div class="table-responsive">
-            <table class="table table-striped">
-                							<tr>
-								<th>ID</th>
-								<th>Exchange</th>
-								<th>Statut</th>
-								<th>Date achat</th>
-								<th>Date vente</th>
-								<th>Quantité BTC</th>
-								<th>Montant USDC</th>
-								<th>Montant vente</th>
-								<th>Gains</th>
-								<!-- Suppression de la colonne "Frais" -->
-								<th>Année fiscale</th>
-								<th>Durée</th>
-								<th>ID Exchange Ordre Achat</th>
-								<th>ID Exchange Ordre Vente</th>
-							</tr>
-						</thead>
-						<tbody>
-							{{ range .Cycles }}
-							<tr>
-								<td>{{ .idInt }}</td>
-								<td>{{ .exchange }}</td>
-								<td class="status-{{ .status }}">{{ .formattedStatus }}</td>
-								<td>{{ .buyDate }}</td>
-								<td>{{ .sellDateFormatted }}</td>
-								<td>{{ printf "%.8f" .quantity }}</td>
-								<td>{{ printf "%.8f" .buyTotal }}</td>
-								<td>
-									{{ if eq .status "completed" }}{{ printf "%.8f" .sellTotal }}
-									{{ else if eq .status "sell" }}{{ printf "%.8f" .sellTotal }}
-									{{ else }}-{{ end }}
-								</td>
-								<td class="{{ if gt .profit 0.0 }}profit-positive{{ else if lt .profit 0.0 }}profit-negative{{ end }}">
-									{{ if eq .status "completed" }}
-										{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-									{{ else if eq .status "sell" }}
-										{{ printf "%.8f" .profit }} ({{ printf "%.2f" .profitPercentage }}%)
-									{{ else }}
-										-
-									{{ end }}
-								</td>
-								<!-- Suppression de l'affichage des frais -->
-								<td>
-									{{ .taxYear }}
-									{{ if eq .status "completed" }}
-										{{ if .declareThisYear }}
-										<span class="badge bg-danger tax-badge">À déclarer</span>
-										{{ end }}
-									{{ end }}
-								</td>
-								<td>{{ if .formattedDuration }}{{ .formattedDuration }}{{ else }}{{ formatAge .age }}{{ end }}</td>
-								<td><small class="exchange-order-id">{{ .buyId }}</small></td>
-								<td><small class="exchange-order-id">{{ .sellId }}</small></td>
-							</tr>
-							{{ end }}
-						</tbody>
-            </table>
-        </div>
-
-        <!-- Récapitulatif fiscal -->
-        <div class="row mt-5 mb-4">
-            <div class="col-12">
-                <h3>Récapitulatif fiscal</h3>
-                <div class="alert alert-warning">
-                    <p><strong>Note importante:</strong> Ce récapitulatif est fourni à titre indicatif et ne constitue pas un document fiscal officiel.</p>
-                    <p>Pour la déclaration des plus-values sur actifs numériques (formulaire 2086), merci de consulter un expert-comptable.</p>
-                </div>
-                
-                <div class="card mb-4">
-                    <div class="card-header">
-                        <h5>Profits par année fiscale</h5>
-                    </div>
-                    <div class="card-body">
-                        <table class="table">
-                            <thead>
-                                <tr>
-                                    <th>Année</th>
-                                    <th>Profits totaux (USDC)</th>
-                                    <th>Impôt estimé (30%)</th>
-                                    <th>Statut</th>
-                                </tr>
-                            </thead>
-                            <tbody>
-                                {{ range $year, $profit := .taxYearProfits }}
-                                <tr {{ if eq $year $.currentTaxYear }}class="tax-important"{{ end }}>
-                                    <td><strong>{{ $year }}</strong></td>
-                                    <td class="{{ if gt $profit 0.0 }}profit-positive{{ else if lt $profit 0.0 }}profit-negative{{ end }}">
-                                        {{ printf "%.2f" $profit }}
-                                    </td>
-                                    <td>{{ printf "%.2f" (mul $profit 0.3) }}</td>
-                                    <td>
-                                        {{ if eq $year $.currentTaxYear }}
-                                            <span class="badge bg-danger">À déclarer en {{ add $year 1 }}</span>
-                                        {{ else if lt $year $.currentTaxYear }}
-                                            <span class="badge bg-success">Déclaration passée</span>
-                                        {{ else }}
-                                            <span class="badge bg-info">Année future</span>
-                                        {{ end }}
-                                    </td>
-                                </tr>
-                                {{ end }}
-                                <tr class="table-secondary">
-                                    <td colspan="2"><strong>Total estimé des impôts à payer</strong></td>
-                                    <td><strong>{{ printf "%.2f" .totalTaxEstimate }}</strong></td>
-                                    <td></td>
-                                </tr>
-                            </tbody>
-                        </table>
-                    </div>
-                    <div class="card-footer text-muted">
-                        <p><strong>Rappel</strong> : En France, les plus-values sur actifs numériques sont soumises à un taux forfaitaire de 30% (12,8% d'impôt sur le revenu + 17,2% de prélèvements sociaux) au-delà d'un seuil de cession annuel de 305€.</p>
-                        <p>Le total des frais liés aux transactions peut être déduit du montant imposable. Conservez tous les justificatifs de frais.</p>
-                    </div>
-                </div>
-                
-                <div class="card mb-4">
-                    <div class="card-header">
-                        <h5>Documents à conserver pour le FISC</h5>
-                    </div>
-                    <div class="card-body">
-                        <p>Pour justifier vos opérations sur actifs numériques, conservez les éléments suivants pour chaque transaction :</p>
-                        <ul>
-                            <li><strong>Date et heure</strong> de chaque transaction (achat et vente)</li>
-                            <li><strong>Identifiants de transaction</strong> (ID des ordres)</li>
-                            <li><strong>Nature de l'opération</strong> (achat, vente, échange)</li>
-                            <li><strong>Contreparties utilisées</strong> (crypto/fiat)</li>
-                            <li><strong>Frais de transaction</strong> payés</li>
-                            <li><strong>Relevés de compte</strong> des plateformes d'échange</li>
-                        </ul>
-                        <p>Il est recommandé de conserver ces documents pendant au moins 6 ans, durée pendant laquelle l'administration fiscale peut exercer son droit de contrôle.</p>
-                    </div>
-					<div class="card-footer text-muted">
-						<p><strong>Note</strong> : Les gains fiscaux affichés incluent une déduction supplémentaire de 0.2% pour frais de transaction. Comme les prix d'achat et de vente incluent déjà les frais d'exchange, cette déduction peut être optionnelle selon votre situation.</p>
-					</div>
-                </div>
-            </div>
-        </div>
-        {{ end }}
-
-        <div class="mt-4 text-muted">
-            <p>Dernière mise à jour: {{ .currentTime }}</p>
-        </div>
-    </div>
-
-    <script src="https://cdn.jsdelivr.net/npm/bootstrap@5.2.3/dist/js/bootstrap.bundle.min.js"></script>
-    <script>
-        // Gestion du champ période et dates personnalisées
-        document.addEventListener('DOMContentLoaded', function() {
-            const periodFilter = document.getElementById('periodFilter');
-            const customDatesRow = document.getElementById('customDatesRow');
-            const startDateInput = document.getElementById('startDate');
-            const endDateInput = document.getElementById('endDate');
-            
-            // Fonction pour gérer l'affichage des dates personnalisées
-            function toggleCustomDates() {
-                if (periodFilter.value === '') {
-                    customDatesRow.style.display = 'flex';
-                } else {
-                    // Effacer les dates si une période est sélectionnée
-                    startDateInput.value = '';
-                    endDateInput.value = '';
-                    customDatesRow.style.display = 'flex';
-                }
-            }
-            
-            // Initialiser l'état
-            toggleCustomDates();
-            
-            // Écouter les changements
-            periodFilter.addEventListener('change', toggleCustomDates);
-            
-            // Soumission du formulaire
-            document.getElementById('filtersForm').addEventListener('submit', function(e) {
-                // Si une période est sélectionnée, supprimer les dates de la requête
-                if (periodFilter.value !== '') {
-                    startDateInput.disabled = true;
-                    endDateInput.disabled = true;
-                }
-            });
-        });
-
-        // Fonction pour basculer entre les modes de vue
-        function toggleViewMode(mode) {
-            const accumulationField = document.getElementById('accumulationField');
-            
-            if (mode === 'accumulation') {
-                accumulationField.value = 'true';
-            } else {
-                accumulationField.value = 'false';
-            }
-            
-            // Soumettre le formulaire automatiquement pour changer de vue
-            document.getElementById('filtersForm').submit();
-        }
-    </script>
-</body>
-</html>
-`
+// pageTemplates contient les templates HTML embarqués, compilés une seule fois au démarrage
+var pageTemplates *template.Template
+
+// loadTemplates compile les templates embarqués s'ils ne l'ont pas déjà été
+func loadTemplates() {
+	if pageTemplates != nil {
+		return
+	}
+
+	tmpl, err := web.ParseTemplates()
+	if err != nil {
+		log.Fatal(err)
+	}
+	pageTemplates = tmpl
+}
+
+// renderTemplate exécute un template embarqué avec les données fournies
+func renderTemplate(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	err := pageTemplates.ExecuteTemplate(w, name, data)
+	if err != nil {
+		http.Error(w, "Erreur lors du rendu du template: "+err.Error(), http.StatusInternalServerError)
+	}
+}
 
 // Server démarre un serveur HTTP pour afficher et gérer les cycles
 func Server() {
-	fmt.Println("Démarrage du serveur sur http://localhost:8080")
-	fmt.Println("Appuyez sur Ctrl+C pour arrêter le serveur")
+	// Compiler les templates une seule fois
+	loadTemplates()
 
 	// Initialiser le router
 	mux := http.NewServeMux()
 
 	// Route principale pour afficher les cycles avec tous les filtres possibles
-	mux.HandleFunc("/", handleDashboard)
-
-	// Route pour mettre à jour les cycles
-	mux.HandleFunc("/update", handleUpdate)
-
-	// Démarrer le serveur
-	err := http.ListenAndServe("localhost:8080", mux)
+	mux.HandleFunc("/", requireAuth(handleDashboard))
+
+	// Données du tableau de bord en JSON (rafraîchissement automatique de la page)
+	mux.HandleFunc("/api/dashboard-data", requireAuth(handleDashboardData))
+
+	// Historique du prix affiché en haut du tableau de bord, avec les prix des cycles en cours
+	mux.HandleFunc("/api/price-history", requireAuth(handlePriceHistory))
+
+	// Route pour mettre à jour les cycles (POST + jeton obligatoires)
+	mux.HandleFunc("/update", requireAuthPost(handleUpdate))
+
+	// Formulaire de connexion lorsque SERVER_AUTH_TOKEN est configuré
+	mux.HandleFunc("/login", handleLogin)
+
+	// État de santé (disjoncteurs des exchanges lors de la dernière mise à jour)
+	mux.HandleFunc("/health", requireAuth(handleHealth))
+
+	// Détail d'un cycle, modification manuelle de son prix de vente et pause/reprise
+	mux.HandleFunc("/cycles/{id}", requireAuth(handleCyclePage))
+
+	// Cycles au format JSON, avec le P&L latent des cycles en vente
+	mux.HandleFunc("/api/cycles", requireAuth(handleCyclesAPI))
+	mux.HandleFunc("/cycles/{id}/sell-price", requireAuthPost(handleSetSellPrice))
+	mux.HandleFunc("/cycles/{id}/order-snapshots/{side}", requireAuth(handleOrderSnapshotDownload))
+	mux.HandleFunc("/cycles/{id}/pause", requireAuthPost(handleSetPaused(true)))
+	mux.HandleFunc("/cycles/{id}/resume", requireAuthPost(handleSetPaused(false)))
+
+	// Actions groupées sur les cycles cochés ou filtrés (annulation, pause, reprise, étiquette)
+	mux.HandleFunc("/api/cycles/bulk", requireAuthPost(handleBulkCycles))
+
+	// Lignes de cession du formulaire 2086 (?year=2024)
+	mux.HandleFunc("/export/tax-2086.csv", requireAuth(handleTaxExport))
+
+	// Onglet et API du planificateur (le daemon lit tasks.conf et les demandes d'exécution)
+	mux.HandleFunc("/scheduler", requireAuth(handleSchedulerPage))
+	mux.HandleFunc("/api/scheduler/status", requireAuth(handleSchedulerStatus))
+	mux.HandleFunc("/api/scheduler/tasks", requireAuth(handleSchedulerTasks))
+	mux.HandleFunc("/api/scheduler/history", requireAuth(handleSchedulerHistory))
+	mux.HandleFunc("/api/scheduler/tasks/{name}/run-now", requireAuthPost(handleSchedulerRunNow))
+	mux.HandleFunc("/api/scheduler/tasks/{name}/enable", requireAuthPost(handleSchedulerEnable))
+	mux.HandleFunc("/api/scheduler/tasks/{name}/disable", requireAuthPost(handleSchedulerDisable))
+
+	// Onglet Logs: journal du serveur et du daemon, conservé en mémoire (?level=warn&since=)
+	mux.HandleFunc("/logs", requireAuth(handleLogsPage))
+	mux.HandleFunc("/api/logs", requireAuth(handleLogsAPI))
+	followDaemonLogs()
+
+	// Application installable et notifications Web Push des navigateurs abonnés
+	mux.HandleFunc("/manifest.webmanifest", handleStaticFile("manifest.webmanifest", "application/manifest+json"))
+	mux.HandleFunc("/sw.js", handleStaticFile("sw.js", "text/javascript; charset=utf-8"))
+	mux.HandleFunc("/icon.svg", handleStaticFile("icon.svg", "image/svg+xml"))
+	mux.HandleFunc("/api/push/subscribe", requireAuthPost(handlePushSubscribe))
+	mux.HandleFunc("/api/push/unsubscribe", requireAuthPost(handlePushUnsubscribe))
+
+	// Démarrer le serveur (adresse, port et TLS configurables)
+	err := listenAndServe("serveur", cfg.ServerPort, mux)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -605,21 +118,54 @@ func Server() {
 func formatStatus(c *database.Cycle) string {
 	switch c.Status {
 	case "buy":
-		return "Achat en cours"
+		return i18n.T("dash.status_buy")
 	case "sell":
-		return "Vente en cours"
+		return i18n.T("dash.status_sell")
+	case database.StatusCancelPending:
+		return i18n.T("dash.status_cancel_pending")
 	case "completed":
-		return "Complété"
+		return i18n.T("dash.status_completed")
 	case "cancelled":
-		return "Annulé"
+		return i18n.T("dash.status_cancelled")
 	default:
 		return c.Status
 	}
 }
 
+// formatCancelReason retourne le libellé de la cause d'annulation d'un cycle
+func formatCancelReason(reason string) string {
+	switch reason {
+	case database.CancelReasonMaxAge:
+		return i18n.T("dash.cancel_reason_max_age")
+	case database.CancelReasonExpired:
+		return i18n.T("dash.cancel_reason_expired")
+	case database.CancelReasonPriceDeviation:
+		return i18n.T("dash.cancel_reason_price_deviation")
+	case database.CancelReasonManual:
+		return i18n.T("dash.cancel_reason_manual")
+	case database.CancelReasonOrderNotFound:
+		return i18n.T("dash.cancel_reason_order_not_found")
+	case database.CancelReasonDuplicate:
+		return i18n.T("dash.cancel_reason_duplicate")
+	default:
+		return i18n.T("dash.cancel_reason_unknown")
+	}
+}
+
 func handleDashboard(w http.ResponseWriter, r *http.Request) {
-	// Récupérer les paramètres de filtrage
-	queryParams := r.URL.Query()
+	data, err := buildDashboardData(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Exécuter le template compilé au démarrage
+	renderTemplate(w, web.DashboardTemplate, data)
+}
+
+// buildDashboardData construit les données du tableau de bord pour les filtres de la requête.
+// Elles servent au rendu HTML et à /api/dashboard-data.
+func buildDashboardData(queryParams url.Values) (map[string]interface{}, error) {
 
 	// 1. Filtrage par status de complétion
 	showCompletedOnly := queryParams.Get("complete") == "true"
@@ -640,53 +186,30 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 	// Calculer les dates de début et de fin en fonction des filtres
 	startDate, endDate := calculateDateRange(periodFilter, startDateStr, endDateStr)
 
-	// Récupérer le repository
-	repo := database.GetRepository()
-
 	// Récupérer la configuration
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		http.Error(w, "Erreur lors du chargement de la configuration: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("Erreur lors du chargement de la configuration: %w", err)
 	}
 
-	// Récupérer tous les cycles
-	allCycles, err := repo.FindAll()
+	// Filtrer les cycles selon les critères
+	cycles, err := filterDashboardCycles(queryParams)
 	if err != nil {
-		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
-	// Filtrer les cycles selon les critères
-	var cycles []*database.Cycle
-	for _, cycle := range allCycles {
-		// Critère 1: Filtrage par complétion
-		if showCompletedOnly && cycle.Status != "completed" {
-			continue
-		}
-
-		// Critère 2: Filtrage par exchange
-		if exchangeFilter != "" && !strings.EqualFold(cycle.Exchange, exchangeFilter) {
-			continue
-		}
-
-		// Critère 3 & 4: Filtrage par date
-		if !isCycleInDateRange(cycle, startDate, endDate) {
-			continue
-		}
-
-		// Inclure ce cycle dans les résultats filtrés
-		cycles = append(cycles, cycle)
-	}
+	// Prix relevés par la dernière mise à jour, pour le P&L latent des cycles en vente
+	prices := loadLastPrices()
 
 	// Convertir les cycles en DTOs pour l'affichage
 	var cyclesDTO []map[string]interface{}
 	for _, cycle := range cycles {
 		// Créer le DTO de base
 		dto := convertCycleToDTO(cycle)
+		addUnrealizedToDTO(dto, cycle, prices.Prices)
 
 		// Calcul précis des montants d'achat
-		buyTotal := cycle.BuyPrice * cycle.Quantity
+		buyTotal := cycle.EffectiveBuyPrice() * cycle.Quantity
 
 		// Initialiser les valeurs de vente et de profit à zéro par défaut
 		sellTotal := 0.0
@@ -695,7 +218,7 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 
 		// Calculer les montants de vente et profits uniquement pour les cycles complétés ou en vente
 		if cycle.Status == "completed" || cycle.Status == "sell" {
-			sellTotal = cycle.SellPrice * cycle.Quantity
+			sellTotal = cycle.EffectiveSellPrice() * cycle.Quantity
 			grossProfit = sellTotal - buyTotal
 
 			// Calculer le pourcentage de profit seulement si buyTotal est supérieur à zéro
@@ -709,16 +232,33 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		dto["sellTotal"] = sellTotal
 		dto["profit"] = grossProfit
 		dto["profitPercentage"] = grossProfitPercentage
+
+		// Profit dans la devise d'affichage: au taux de la complétion s'il est réalisé,
+		// au dernier taux publié pour un cycle encore en vente
+		dto["profitDisplay"] = nil
+		switch cycle.Status {
+		case "completed":
+			dto["profitDisplay"] = displayValue(grossProfit, realizedAt(cycle))
+		case "sell":
+			dto["profitDisplay"] = displayValue(grossProfit, time.Now())
+		}
 		dto["originalBuyOrderId"] = cycle.BuyId   // L'ID original de l'ordre d'achat
 		dto["originalSellOrderId"] = cycle.SellId // L'ID original de l'ordre de vente
 
-		// Date d'achat formatée au format français
-		dto["buyDate"] = cycle.CreatedAt.Format("02/01/2006 15:04")
+		// Date d'achat formatée selon la langue
+		dto["buyDate"] = i18n.FormatDateTime(cycle.CreatedAt)
+
+		// Durée du cycle (jusqu'à la vente pour les cycles complétés), utilisée pour le tri
+		if completedAt := cycle.EffectiveCompletedAt(); cycle.Status == "completed" && !completedAt.IsZero() {
+			dto["durationDays"] = completedAt.Sub(cycle.CreatedAt).Hours() / 24
+		} else {
+			dto["durationDays"] = cycle.GetAge()
+		}
 
 		// Informations fiscales
 		dto["taxYear"] = cycle.CreatedAt.Year()
 		if cycle.Status == "completed" {
-			sellDate := cycle.CompletedAt
+			sellDate := cycle.EffectiveCompletedAt()
 			if !sellDate.IsZero() {
 				dto["sellTaxYear"] = sellDate.Year()
 				// Indiquer si le profit doit être déclaré cette année
@@ -736,15 +276,71 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		cyclesDTO = append(cyclesDTO, dto)
 	}
 
+	// Trier puis paginer le tableau (les statistiques portent sur tous les cycles filtrés)
+	currentSort := parseDashboardSort(queryParams)
+	sortCycleDTOs(cyclesDTO, currentSort)
+	cyclesDTO = groupCycleDTOs(cyclesDTO)
+
+	// Regroupement par exchange: sous-total sur la dernière ligne de chaque exchange et total
+	// général en fin de tableau, sur tous les cycles filtrés
+	groupBy := ""
+	var grandTotal *exchangeSubtotal
+	if queryParams.Get("groupBy") == groupByExchange {
+		groupBy = groupByExchange
+		var subtotals map[string]*exchangeSubtotal
+		subtotals, grandTotal = subtotalsByExchange(cycles, prices.Prices)
+		if accumulations, err := database.GetAccumulationRepository().FindAll(); err == nil {
+			addAccumulationsToSubtotals(subtotals, grandTotal, accumulations, prices.Prices, exchangeFilter)
+		}
+		groupDTOsByExchange(cyclesDTO, subtotals)
+	}
+
+	pageDTO, page, totalPages := paginateCycleDTOs(cyclesDTO, queryParams.Get("page"), cfg.DashboardPageSize)
+	if page < totalPages {
+		grandTotal = nil // Le total général suit la dernière ligne du tableau
+	}
+	sortLinks, sortArrows := dashboardSortLinks(queryParams, currentSort)
+
+	prevPageURL := ""
+	if page > 1 {
+		prevPageURL = dashboardURL(queryParams, map[string]string{"page": strconv.Itoa(page - 1)})
+	}
+	nextPageURL := ""
+	if page < totalPages {
+		nextPageURL = dashboardURL(queryParams, map[string]string{"page": strconv.Itoa(page + 1)})
+	}
+
 	// Calculer les statistiques pour les cycles filtrés
 	filteredStats := calculateFilteredCycleStatistics(cycles)
 
 	// Calculer les profits par année fiscale
 	taxYearProfits := calculateProfitsByTaxYear(cycles)
+	taxYearProfitsDisplay := calculateDisplayProfitsByTaxYear(cycles)
+
+	// P&L latent par exchange et total
+	unrealizedTotals := unrealizedByExchange(cycles, prices.Prices)
+	var unrealizedTotal float64
+	for _, profit := range unrealizedTotals {
+		unrealizedTotal += profit
+	}
+	pricesUpdatedAt := ""
+	if !prices.UpdatedAt.IsZero() {
+		pricesUpdatedAt = i18n.FormatDateTime(prices.UpdatedAt)
+	}
 
 	// Préparer les données pour le template
 	data := map[string]interface{}{
-		"Cycles":           cyclesDTO,
+		"Cycles":           pageDTO,
+		"sortLinks":        sortLinks,
+		"sortArrows":       sortArrows,
+		"sortColumn":       currentSort.Column,
+		"sortDir":          currentSort.Dir(),
+		"groupBy":          groupBy,
+		"grandTotal":       grandTotal,
+		"page":             page,
+		"totalPages":       totalPages,
+		"prevPageURL":      prevPageURL,
+		"nextPageURL":      nextPageURL,
 		"cyclesCount":      len(cycles),
 		"buyCycles":        filteredStats.buyCycles,
 		"sellCycles":       filteredStats.sellCycles,
@@ -753,19 +349,37 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		"totalSell":        filteredStats.totalSell,
 		"gainAbs":          filteredStats.gainAbs,
 		"gainPercent":      filteredStats.gainPercent,
-		"currentTime":      time.Now().Format("02/01/2006 15:04:05"),
+		"currentTime":      time.Now().Format(i18n.DateTimeLayout() + ":05"),
 		"showAll":          !showCompletedOnly,
 		"showCompleted":    showCompletedOnly,
 		"showAccumulation": showAccumulation,
+		"readOnly":         readOnly(),
 		"exchangeFilter":   exchangeFilter,
 		"periodFilter":     periodFilter,
 		"startDate":        startDateStr,
 		"endDate":          endDateStr,
+		"statusFilter":     queryParams.Get("status"),
+		"minAgeFilter":     queryParams.Get("min_age"),
+		"filterQuery":      dashboardFilterQuery(queryParams),
 		"exchanges":        getAvailableExchanges(cfg),
 		"periodOptions":    getPeriodOptions(),
 		"currentTaxYear":   time.Now().Year(),
 		"taxYearProfits":   taxYearProfits,
 		"totalTaxEstimate": calculateTotalTaxEstimate(taxYearProfits),
+		"refreshSeconds":   cfg.DashboardRefreshSeconds,
+		"pushPublicKey":    pushPublicKey(),
+		"priceHistory":     priceHistoryExchange(exchangeFilter),
+
+		// Conversion dans la devise d'affichage (DISPLAY_CURRENCY), nil sans conversion
+		"displayCurrency":       displayCurrency(),
+		"gainDisplay":           realizedDisplayTotal(cycles, cycleGrossProfit),
+		"unrealizedDisplay":     displayValue(unrealizedTotal, time.Now()),
+		"taxYearProfitsDisplay": taxYearProfitsDisplay,
+
+		"unrealizedByExchange": unrealizedTotals,
+		"unrealizedTotal":      unrealizedTotal,
+		"hasUnrealized":        len(unrealizedTotals) > 0,
+		"pricesUpdatedAt":      pricesUpdatedAt,
 	}
 
 	// Si on affiche les accumulations, récupérer les données d'accumulation
@@ -775,8 +389,7 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		// Récupérer toutes les accumulations
 		allAccumulations, err := accuRepo.FindAll()
 		if err != nil {
-			http.Error(w, "Erreur lors de la récupération des accumulations: "+err.Error(), http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("Erreur lors de la récupération des accumulations: %w", err)
 		}
 
 		// Filtrer les accumulations selon les mêmes critères
@@ -795,9 +408,32 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 			filteredAccumulations = append(filteredAccumulations, accu)
 		}
 
-		// Convertir les accumulations en DTOs pour l'affichage
+		// Convertir les accumulations en DTOs pour l'affichage et calculer les totaux
 		var accumulationsDTO []map[string]interface{}
+		totalAccuQuantity := 0.0
+		totalAccuCost := 0.0
+		totalAccuSaved := 0.0
+		totalAccuValue := 0.0
+		totalAccuGain := 0.0
 		for _, accu := range filteredAccumulations {
+			// Valeur préservée : écart entre le prix de vente visé et le prix d'annulation
+			savedValue := accu.Quantity * (accu.TargetSellPrice - accu.CancelPrice)
+
+			totalAccuQuantity += accu.Quantity
+			totalAccuCost += accu.Quantity * accu.OriginalBuyPrice
+			totalAccuSaved += savedValue
+
+			// Évolution depuis l'annulation, au dernier prix, du BTC encore conservé
+			currentPrice := prices.Prices[accu.Exchange]
+			hasCurrentPrice := currentPrice > 0 && !accu.Converted()
+			currentValue, unrealizedGain := 0.0, 0.0
+			if hasCurrentPrice {
+				currentValue = accu.Quantity * currentPrice
+				unrealizedGain = accu.UnrealizedGain(currentPrice)
+				totalAccuValue += currentValue
+				totalAccuGain += unrealizedGain
+			}
+
 			dto := map[string]interface{}{
 				"idInt":              accu.IdInt,
 				"exchange":           accu.Exchange,
@@ -806,8 +442,13 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 				"targetSellPrice":    accu.TargetSellPrice,
 				"cancelPrice":        accu.CancelPrice,
 				"deviation":          accu.Deviation,
-				"createdAtFormatted": accu.CreatedAt.Format("02/01/2006 15:04:05"),
+				"savedValue":         savedValue,
+				"hasCurrentPrice":    hasCurrentPrice,
+				"currentValue":       currentValue,
+				"unrealizedGain":     unrealizedGain,
+				"createdAtFormatted": accu.CreatedAt.Format(i18n.DateTimeLayout() + ":05"),
 				"taxYear":            accu.CreatedAt.Year(),
+				"convertedCycleId":   accu.ConvertedCycleId,
 			}
 			accumulationsDTO = append(accumulationsDTO, dto)
 		}
@@ -817,17 +458,26 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		for exchangeName, exchangeConfig := range cfg.Exchanges {
 			if exchangeConfig.Enabled {
 				if exchangeFilter == "" || strings.EqualFold(exchangeName, exchangeFilter) {
-					stats, err := accuRepo.GetExchangeAccumulationStats(exchangeName)
+					stats, err := accuRepo.GetExchangeAccumulationStats(exchangeName, prices.Prices[exchangeName])
 					if err != nil {
 						continue
 					}
 
+					// Plafonds configurés et marge restante (nulle si le profit ne peut être calculé)
+					headroom, _ := getAccumulationHeadroom(exchangeName, exchangeConfig, accuRepo)
+
 					accumulationStats[exchangeName] = map[string]interface{}{
 						"enabled":          exchangeConfig.Accumulation,
 						"count":            stats["count"],
 						"totalQuantity":    stats["totalQuantity"],
 						"savedValue":       stats["savedValue"],
+						"hasCurrentPrice":  stats["hasCurrentPrice"],
+						"currentValue":     stats["currentValue"],
+						"unrealizedGain":   stats["unrealizedGain"],
 						"averageDeviation": stats["averageDeviation"],
+						"profitPercent":    headroom.ProfitPercent,
+						"singleCap":        headroom.SingleCapUSDC,
+						"remaining":        headroom.Remaining,
 					}
 				}
 			}
@@ -837,60 +487,120 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 		data["allAccumulations"] = accumulationsDTO
 		data["accumulationStats"] = accumulationStats
 		data["hasAccumulations"] = len(filteredAccumulations) > 0
+
+		// Cartes de synthèse propres à la vue accumulation
+		data["accumulationCount"] = len(filteredAccumulations)
+		data["accumulationTotalQuantity"] = totalAccuQuantity
+		data["accumulationTotalCost"] = totalAccuCost
+		data["accumulationSavedValue"] = totalAccuSaved
+		data["accumulationCurrentValue"] = totalAccuValue
+		data["accumulationUnrealizedGain"] = totalAccuGain
 	}
 
-	// Créer un template avec des fonctions auxiliaires
-	funcMap := template.FuncMap{
-
-		"mul": func(a, b float64) float64 {
-			return a * b
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"formatAge": func(durationInDays float64) string {
-			// Convertir en heures pour faciliter les comparaisons
-			hours := durationInDays * 24
-
-			if hours < 24 {
-				// Moins de 24 heures
-				h := int(hours)
-				m := int((hours - float64(h)) * 60)
-				if h == 0 {
-					// Si moins d'une heure, afficher uniquement les minutes
-					return fmt.Sprintf("%dm", m)
-				}
-				return fmt.Sprintf("%dh %dm", h, m)
-			} else if durationInDays < 7 {
-				// Entre 1 et 7 jours
-				days := int(durationInDays)
-				remainingHours := int(hours) % 24
-				return fmt.Sprintf("%dj %dh", days, remainingHours)
-			} else if durationInDays < 35 {
-				// Entre 7 et 35 jours (5 semaines)
-				weeks := int(durationInDays / 7)
-				remainingDays := int(durationInDays) % 7
-				return fmt.Sprintf("%dsem %dj", weeks, remainingDays)
-			} else {
-				// Plus de 5 semaines
-				months := int(durationInDays / 30)
-				remainingDays := int(durationInDays) % 30
-				return fmt.Sprintf("%dmois %dj", months, remainingDays)
-			}
-		},
+	return data, nil
+}
+
+// Paramètres de filtrage des cycles du tableau de bord, repris par les actions groupées
+var dashboardFilterParams = []string{"complete", "exchange", "period", "start_date", "end_date", "status", "min_age"}
+
+// dashboardFilterQuery retourne les seuls filtres de la requête, encodés, pour désigner
+// l'ensemble des cycles filtrés (sans le tri ni la pagination)
+func dashboardFilterQuery(queryParams url.Values) string {
+	filter := url.Values{}
+	for _, key := range dashboardFilterParams {
+		if value := queryParams.Get(key); value != "" {
+			filter.Set(key, value)
+		}
+	}
+	return filter.Encode()
+}
+
+// filterDashboardCycles retourne les cycles correspondant aux filtres du tableau de bord:
+// complétion, exchange, période ou dates, statut et âge minimal en jours (?min_age=20)
+func filterDashboardCycles(queryParams url.Values) ([]*database.Cycle, error) {
+	showCompletedOnly := queryParams.Get("complete") == "true"
+	exchangeFilter := queryParams.Get("exchange")
+	statusFilter := queryParams.Get("status")
+	startDate, endDate := calculateDateRange(queryParams.Get("period"), queryParams.Get("start_date"), queryParams.Get("end_date"))
+
+	minAge := 0.0
+	if value := queryParams.Get("min_age"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("âge minimal invalide: %s", value)
+		}
+		minAge = parsed
 	}
 
-	// Utiliser le funcMap lors de la création du template
-	tmpl, err := template.New("index").Funcs(funcMap).Parse(htmlTemplate)
+	// Récupérer les cycles (uniquement les complétés si le filtre est actif)
+	repo := database.GetRepository()
+	var allCycles []*database.Cycle
+	var err error
+	if showCompletedOnly {
+		allCycles, err = repo.FindByStatus("completed")
+	} else {
+		allCycles, err = repo.FindAll()
+	}
 	if err != nil {
-		http.Error(w, "Erreur lors de la compilation du template: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("Erreur lors de la récupération des cycles: %w", err)
 	}
 
-	// Exécuter le template
-	err = tmpl.Execute(w, data)
+	var cycles []*database.Cycle
+	for _, cycle := range allCycles {
+		// Critère 1: Filtrage par complétion
+		if showCompletedOnly && cycle.Status != "completed" {
+			continue
+		}
+
+		// Critère 2: Filtrage par exchange
+		if exchangeFilter != "" && !strings.EqualFold(cycle.Exchange, exchangeFilter) {
+			continue
+		}
+
+		// Critère 3 & 4: Filtrage par date
+		if !isCycleInDateRange(cycle, startDate, endDate) {
+			continue
+		}
+
+		// Critère 5: Filtrage par statut
+		if statusFilter != "" && cycle.Status != statusFilter {
+			continue
+		}
+
+		// Critère 6: Filtrage par âge minimal
+		if minAge > 0 && cycle.GetAge() < minAge {
+			continue
+		}
+
+		// Inclure ce cycle dans les résultats filtrés
+		cycles = append(cycles, cycle)
+	}
+	return cycles, nil
+}
+
+// handleDashboardData retourne les données du tableau de bord au format JSON, avec le rendu
+// HTML des cartes de statistiques et des lignes du tableau pour le rafraîchissement automatique
+func handleDashboardData(w http.ResponseWriter, r *http.Request) {
+	data, err := buildDashboardData(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Erreur lors du rendu du template: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fragments := make(map[string]string)
+	for key, name := range map[string]string{"stats": web.DashboardStatsTemplate, "rows": web.DashboardRowsTemplate} {
+		var buf bytes.Buffer
+		if err := pageTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+			http.Error(w, "Erreur lors du rendu du template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fragments[key] = buf.String()
+	}
+	data["html"] = fragments
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Erreur lors de l'envoi des données du tableau de bord: %v", err)
 	}
 }
 
@@ -905,8 +615,8 @@ func calculateProfitsByTaxYear(cycles []*database.Cycle) map[int]float64 {
 			year := cycle.CreatedAt.Year()
 
 			// Calcul des montants et frais
-			buyTotal := cycle.BuyPrice * cycle.Quantity
-			sellTotal := cycle.SellPrice * cycle.Quantity
+			buyTotal := cycle.EffectiveBuyPrice() * cycle.Quantity
+			sellTotal := cycle.EffectiveSellPrice() * cycle.Quantity
 
 			// Calcul des frais (0.1% pour l'achat et 0.1% pour la vente)
 			//buyFees := buyTotal * 0.001
@@ -925,6 +635,31 @@ func calculateProfitsByTaxYear(cycles []*database.Cycle) map[int]float64 {
 	return profitsByYear
 }
 
+// calculateDisplayProfitsByTaxYear retourne les profits par année fiscale dans la devise
+// d'affichage (vide sans conversion)
+func calculateDisplayProfitsByTaxYear(cycles []*database.Cycle) map[int]float64 {
+	profitsByYear := make(map[int]float64)
+	if displayRates() == nil {
+		return profitsByYear
+	}
+
+	// Même année fiscale que calculateProfitsByTaxYear, profit converti au taux de la complétion
+	for _, cycle := range cycles {
+		if cycle.Status != "completed" {
+			continue
+		}
+		if conversion, ok := convertForDisplay(cycleGrossProfit(cycle), realizedAt(cycle)); ok {
+			profitsByYear[cycle.CreatedAt.Year()] += conversion.Amount
+		}
+	}
+	return profitsByYear
+}
+
+// cycleGrossProfit retourne le profit brut d'un cycle: vente moins achat aux prix exécutés
+func cycleGrossProfit(cycle *database.Cycle) float64 {
+	return (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
+}
+
 // Calcule l'estimation des impôts totaux à payer (30% en France)
 func calculateTotalTaxEstimate(profitsByYear map[int]float64) float64 {
 	var totalTax float64
@@ -956,7 +691,11 @@ func handleUpdate(w http.ResponseWriter, r *http.Request) {
 	Update()
 
 	// Rediriger vers la page principale avec les mêmes paramètres de filtre
-	http.Redirect(w, r, "/"+r.URL.RawQuery, http.StatusSeeOther)
+	target := "/"
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
 }
 
 // Calcule les statistiques complètes pour un ensemble de cycles filtrés
@@ -980,14 +719,14 @@ func calculateFilteredCycleStatistics(cycles []*database.Cycle) filteredStatsDat
 		exchangeStats := exchangeTotals[cycle.Exchange]
 
 		switch cycle.Status {
-		case "buy":
+		case "buy", database.StatusCancelPending:
 			stats.buyCycles++
 		case "sell":
 			stats.sellCycles++
 		case "completed":
 			stats.completedCycles++
-			buyValue := cycle.BuyPrice * cycle.Quantity
-			sellValue := cycle.SellPrice * cycle.Quantity
+			buyValue := cycle.EffectiveBuyPrice() * cycle.Quantity
+			sellValue := cycle.EffectiveSellPrice() * cycle.Quantity
 
 			stats.totalBuy += buyValue
 			stats.totalSell += sellValue
@@ -1129,11 +868,11 @@ func getAvailableExchanges(cfg *config.Config) []string {
 // Récupère les options de période disponibles
 func getPeriodOptions() []map[string]string {
 	return []map[string]string{
-		{"value": "7j", "label": "7 derniers jours"},
-		{"value": "30j", "label": "30 derniers jours"},
-		{"value": "90j", "label": "3 derniers mois"},
-		{"value": "180j", "label": "6 derniers mois"},
-		{"value": "365j", "label": "Dernière année"},
+		{"value": "7j", "label": i18n.T("dash.period_7d")},
+		{"value": "30j", "label": i18n.T("dash.period_30d")},
+		{"value": "90j", "label": i18n.T("dash.period_90d")},
+		{"value": "180j", "label": i18n.T("dash.period_180d")},
+		{"value": "365j", "label": i18n.T("dash.period_365d")},
 	}
 }
 
@@ -1183,33 +922,68 @@ func convertCycleToDTO(cycle *database.Cycle) map[string]interface{} {
 		"sellId":    cycle.SellId,
 		"age":       cycle.GetAge(),
 		"taxYear":   cycle.CreatedAt.Year(),
+		"imported":  cycle.Imported,
+		"paused":    cycle.Paused,
+		"tags":      cycle.Tags,
+
+		// Remise maker nette reversée par l'exchange (frais totaux négatifs), 0 sinon
+		"feeRebate": math.Max(-cycle.TotalFees, 0),
+
+		// Replacements de l'achat après dépassement de la déviation de prix
+		"repriceCount": cycle.RepriceCount,
+
+		// Échecs du placement de la vente d'un achat exécuté (0 une fois la vente placée)
+		"sellRetryCount": cycle.SellRetryCount,
+		"sellRetryError": cycle.SellRetryError,
+
+		// Tentatives d'annulation d'un achat en cancel_pending (0 hors de ce statut)
+		"cancelRetryCount": cycle.CancelRetryCount,
+		"cancelRetryError": cycle.CancelRetryError,
+
+		// Prix réellement exécutés (0 si inconnus), affichés en info-bulle
+		"buyFillPrice":  cycle.BuyFillPrice,
+		"sellFillPrice": cycle.SellFillPrice,
+
+		// Cause et date de l'annulation (vides hors statuts cancelled et cancel_pending)
+		"cancelReason":      cycle.CancelReason,
+		"cancelReasonLabel": "",
+		"cancelledAt":       "",
+
+		// Groupe des tranches d'un achat échelonné (0 sans échelonnement)
+		"groupId":       cycle.GroupId,
+		"groupSubtotal": nil,
+
+		// Sous-total de l'exchange, sur sa dernière ligne quand le tableau est groupé (?groupBy=exchange)
+		"exchangeSubtotal": nil,
+	}
+	if cycle.Status == "cancelled" || cycle.Status == database.StatusCancelPending {
+		dto["cancelReasonLabel"] = formatCancelReason(cycle.CancelReason)
+		if !cycle.CancelledAt.IsZero() {
+			dto["cancelledAt"] = i18n.FormatDateTime(cycle.CancelledAt)
+		}
 	}
 
 	// Informations standard
 	dto["formattedStatus"] = formatStatus(cycle)
 	dto["quantity"] = cycle.Quantity // Ajouter la quantité de BTC
 
-	// Date d'achat formatée au format français
-	dto["buyDate"] = cycle.CreatedAt.Format("02/01/2006 15:04")
+	// Date d'achat formatée selon la langue
+	dto["buyDate"] = i18n.FormatDateTime(cycle.CreatedAt)
 
 	// Gestion des dates et informations fiscales
 	switch cycle.Status {
 	case "completed":
-		if !cycle.CompletedAt.IsZero() {
-			// Utiliser CompletedAt pour les années fiscales
-			dto["sellTaxYear"] = cycle.CompletedAt.Year()
+		if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+			// Utiliser la date de complétion pour les années fiscales
+			dto["sellTaxYear"] = completedAt.Year()
 
 			// Vérifier si le profit doit être déclaré cette année
 			currentYear := time.Now().Year()
-			dto["declareThisYear"] = (cycle.CompletedAt.Year() == currentYear)
+			dto["declareThisYear"] = (completedAt.Year() == currentYear)
 		} else {
-			// Si CompletedAt est zéro, utiliser une estimation
-			estimatedSellDate := estimateCompletionTime(cycle)
-			dto["sellTaxYear"] = estimatedSellDate.Year()
-
-			// Vérifier si l'année estimée correspond à l'année actuelle
-			currentYear := time.Now().Year()
-			dto["declareThisYear"] = (estimatedSellDate.Year() == currentYear)
+			// Date de complétion inconnue: aucune année n'est inventée
+			dto["sellTaxYear"] = "-"
+			dto["declareThisYear"] = false
 		}
 	default:
 		// Pour les autres statuts
@@ -1217,20 +991,17 @@ func convertCycleToDTO(cycle *database.Cycle) map[string]interface{} {
 		dto["declareThisYear"] = false
 	}
 
+	// Valeurs vides par défaut : le template les affiche pour tous les cycles
+	dto["sellDateFormatted"] = ""
+	dto["formattedDuration"] = ""
+
 	switch cycle.Status {
 	case "completed":
-		if !cycle.CompletedAt.IsZero() {
-			// Forcer le formatage explicite en français
-			formattedSellDate := cycle.CompletedAt.Format("02/01/2006 15:04")
-			// NOUVEAU : Vérification et correction potentielle
-			if formattedSellDate != cycle.CompletedAt.Format("02/01/2006 15:04") {
-				log.Printf("ALERTE: Incohérence dans le formatage de la date")
-			}
-
-			dto["sellDateFormatted"] = formattedSellDate
+		if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+			dto["sellDateFormatted"] = i18n.FormatDateTime(completedAt)
 
 			// Calculer la durée
-			cycleDuration := cycle.CompletedAt.Sub(cycle.CreatedAt)
+			cycleDuration := completedAt.Sub(cycle.CreatedAt)
 			durationDays := cycleDuration.Hours() / 24
 
 			dto["formattedDuration"] = formatDetailedDuration(durationDays)
@@ -1239,23 +1010,3 @@ func convertCycleToDTO(cycle *database.Cycle) map[string]interface{} {
 
 	return dto
 }
-
-// Fonction pour estimer la date de complétion si elle est manquante
-func estimateCompletionTime(cycle *database.Cycle) time.Time {
-	// Estimer la date de complétion en fonction de l'exchange
-	var estimatedDuration time.Duration
-	switch cycle.Exchange {
-	case "KUCOIN":
-		estimatedDuration = 3 * time.Hour
-	case "MEXC":
-		estimatedDuration = 3 * time.Hour
-	case "BINANCE":
-		estimatedDuration = 3 * time.Hour
-	case "KRAKEN": // Assurez-vous que ce cas existe
-		estimatedDuration = 3 * time.Hour
-	default:
-		estimatedDuration = 3 * time.Hour
-	}
-
-	return cycle.CreatedAt.Add(estimatedDuration)
-}
@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+
+	"github.com/fatih/color"
+)
+
+// simulatedIntent est une action que la mise à jour aurait effectuée (--simulate-update)
+type simulatedIntent struct {
+	CycleId  int32  `json:"cycleId,omitempty"`
+	Exchange string `json:"exchange,omitempty"`
+	Action   string `json:"action"`
+	Details  string `json:"details"`
+}
+
+// simulationRecorder collecte les actions interceptées pendant une mise à jour simulée
+type simulationRecorder struct {
+	mu      sync.Mutex
+	cycle   *database.Cycle // Cycle en cours de traitement (nil hors de la boucle des cycles)
+	intents []simulatedIntent
+	orders  int // Compteur des ordres simulés
+}
+
+// Simulation en cours (nil en fonctionnement normal)
+var (
+	simulationMu sync.Mutex
+	simulation   *simulationRecorder
+)
+
+// currentSimulation retourne la simulation en cours, nil si la mise à jour agit réellement
+func currentSimulation() *simulationRecorder {
+	simulationMu.Lock()
+	defer simulationMu.Unlock()
+	return simulation
+}
+
+// simulating indique si la mise à jour en cours est simulée: aucun état n'est alors publié
+func simulating() bool {
+	return currentSimulation() != nil
+}
+
+// setCycle indique le cycle auquel rattacher les actions suivantes
+func (s *simulationRecorder) setCycle(cycle *database.Cycle) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cycle = cycle
+}
+
+// record ajoute une action, rattachée au cycle en cours si idInt est nul
+func (s *simulationRecorder) record(idInt int32, exchange, action, details string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cycle != nil {
+		if idInt == 0 {
+			idInt = s.cycle.IdInt
+		}
+		if exchange == "" {
+			exchange = s.cycle.Exchange
+		}
+	}
+	s.intents = append(s.intents, simulatedIntent{CycleId: idInt, Exchange: exchange, Action: action, Details: details})
+}
+
+// nextOrderId retourne l'identifiant d'un ordre simulé
+func (s *simulationRecorder) nextOrderId() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders++
+	return fmt.Sprintf("SIMULATED-%d", s.orders)
+}
+
+// recordWrite traduit une écriture interceptée en action lisible
+func (s *simulationRecorder) recordWrite(intent database.WriteIntent) {
+	switch {
+	case intent.Collection == database.AccumulationCollectionName && intent.Op == "save":
+		s.record(0, "", "accumulate", "enregistrement d'une accumulation")
+	case intent.Collection == database.SnapshotCollectionName:
+		s.record(0, "", "snapshot", "instantané du portefeuille")
+	case intent.Collection == database.PriceHistoryCollectionName:
+		s.record(0, "", "price_history", "historique du prix")
+	case intent.Op == "delete":
+		s.record(intent.IdInt, "", "delete_cycle", "suppression du cycle")
+	case intent.Op == "save":
+		s.record(intent.IdInt, "", "save_cycle", "enregistrement du cycle")
+	default:
+		s.record(intent.IdInt, "", cycleUpdateAction(intent.Fields), describeFields(intent.Fields))
+	}
+}
+
+// cycleUpdateAction nomme une mise à jour de cycle d'après son nouveau statut
+func cycleUpdateAction(fields map[string]interface{}) string {
+	switch fields["status"] {
+	case "completed":
+		return "mark_completed"
+	case "cancelled":
+		return "mark_cancelled"
+	case "sell":
+		return "mark_sell"
+	default:
+		return "update_cycle"
+	}
+}
+
+// describeFields liste les champs modifiés, triés par nom
+func describeFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, fields[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// simulatedExchange lit l'exchange réel mais remplace la création et l'annulation d'ordres
+// par des actions enregistrées
+type simulatedExchange struct {
+	common.Exchange
+	exchange string
+	rec      *simulationRecorder
+}
+
+func (s *simulatedExchange) simulatedOrder(side, price, quantity string) common.OrderCreateResult {
+	orderId := s.rec.nextOrderId()
+	body, _ := json.Marshal(map[string]string{
+		"orderId": orderId,
+		"status":  "NEW",
+		"side":    side,
+		"price":   price,
+		"origQty": quantity,
+	})
+	return common.OrderCreateResult{OrderID: orderId, Raw: body}
+}
+
+func (s *simulatedExchange) CreateOrder(side, price, quantity string, opts ...common.OrderOptions) (common.OrderCreateResult, error) {
+	s.rec.record(0, s.exchange, "create_order", fmt.Sprintf("%s %s BTC à %s", side, quantity, price))
+	return s.simulatedOrder(side, price, quantity), nil
+}
+
+func (s *simulatedExchange) CreateMakerOrder(side string, price float64, quantity string) (common.OrderCreateResult, error) {
+	priceStr := s.FormatPrice(price)
+	s.rec.record(0, s.exchange, "create_order", fmt.Sprintf("%s %s BTC à %s (maker)", side, quantity, priceStr))
+	return s.simulatedOrder(side, priceStr, quantity), nil
+}
+
+func (s *simulatedExchange) CancelOrder(orderID string) ([]byte, error) {
+	s.rec.record(0, s.exchange, "cancel_order", fmt.Sprintf("annulation de l'ordre %s", orderID))
+	return []byte(`{"status":"CANCELED"}`), nil
+}
+
+func (s *simulatedExchange) CancelOrderIdempotent(orderID string) (common.CancelResult, error) {
+	s.rec.record(0, s.exchange, "cancel_order", fmt.Sprintf("annulation de l'ordre %s", orderID))
+	return common.Cancelled, nil
+}
+
+func (s *simulatedExchange) CreateOCOOrder(sellPrice, stopPrice, stopLimitPrice float64, quantity string) (common.OCOOrder, error) {
+	s.rec.record(0, s.exchange, "create_oco", fmt.Sprintf("SELL %s BTC à %.2f, stop %.2f (limite %.2f)",
+		quantity, sellPrice, stopPrice, stopLimitPrice))
+	return common.OCOOrder{ListID: s.rec.nextOrderId(), LimitID: s.rec.nextOrderId(), StopID: s.rec.nextOrderId()}, nil
+}
+
+// SupportsOrderExpiry transmet la capacité de l'exchange simulé: l'achat simulé porte la même échéance
+func (s *simulatedExchange) SupportsOrderExpiry() bool {
+	return common.SupportsOrderExpiry(s.Exchange)
+}
+
+// SimulateUpdate exécute la mise à jour en lecture seule: prix, soldes et états des ordres sont
+// lus, mais chaque annulation, création d'ordre ou écriture en base est remplacée par une action
+// enregistrée puis affichée (--simulate-update, --json pour une sortie JSON)
+func SimulateUpdate() {
+	jsonOutput := false
+	for _, arg := range GetAllArgs() {
+		if arg == "--json" {
+			jsonOutput = true
+		}
+	}
+
+	// En JSON, les messages de la mise à jour passent sur la sortie d'erreur
+	stdout, colorOutput := os.Stdout, color.Output
+	if jsonOutput {
+		os.Stdout, color.Output = os.Stderr, os.Stderr
+		initTradeLogger(cfg)
+	}
+
+	intents := runSimulatedUpdate()
+
+	if jsonOutput {
+		os.Stdout, color.Output = stdout, colorOutput
+		initTradeLogger(cfg)
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(intents); err != nil {
+			color.Red("Erreur lors de l'encodage JSON: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printSimulatedIntents(intents)
+}
+
+// runSimulatedUpdate exécute Update() en interceptant toutes ses actions et les retourne
+func runSimulatedUpdate() []simulatedIntent {
+	rec := &simulationRecorder{}
+	simulationMu.Lock()
+	simulation = rec
+	simulationMu.Unlock()
+	restore := database.SimulateWrites(rec.recordWrite)
+
+	defer func() {
+		restore()
+		simulationMu.Lock()
+		simulation = nil
+		simulationMu.Unlock()
+	}()
+
+	Update()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	intents := make([]simulatedIntent, len(rec.intents))
+	copy(intents, rec.intents)
+	return intents
+}
+
+// printSimulatedIntents affiche le tableau des actions simulées
+func printSimulatedIntents(intents []simulatedIntent) {
+	fmt.Println("")
+	color.Cyan("=== Simulation de la mise à jour (aucune action effectuée) ===")
+	fmt.Println("")
+
+	if len(intents) == 0 {
+		color.Green("Aucune action: la mise à jour ne modifierait rien.")
+		return
+	}
+
+	color.Cyan("%-7s %-9s %-16s %s", "Cycle", "Exchange", "Action", "Détails")
+	for _, intent := range intents {
+		cycleId := "-"
+		if intent.CycleId != 0 {
+			cycleId = fmt.Sprint(intent.CycleId)
+		}
+		color.White("%-7s %-9s %-16s %s", cycleId, intent.Exchange, intent.Action, intent.Details)
+	}
+	fmt.Println("")
+	color.Yellow("%d action(s) simulée(s)", len(intents))
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// snapshotExchanges est l'ordre dans lequel les exchanges sont interrogés pour un instantané
+var snapshotExchanges = []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"}
+
+// collectPortfolioSnapshot relève les soldes BTC/USDC et le prix du BTC de chaque exchange activé.
+// Un exchange injoignable fait échouer l'instantané: une valeur partielle fausserait la courbe.
+func collectPortfolioSnapshot(cfg *config.Config) (*database.Snapshot, error) {
+	snapshot := &database.Snapshot{
+		Timestamp: time.Now(),
+		Exchanges: make(map[string]database.SnapshotBalance),
+	}
+
+	var priceSum float64
+	for _, exchangeName := range snapshotExchanges {
+		exchangeConfig, exists := cfg.Exchanges[exchangeName]
+		if !exists || !exchangeConfig.Enabled || exchangeConfig.APIKey == "" {
+			continue
+		}
+
+		client := guardedClient(exchangeName)
+		if client == nil {
+			return nil, fmt.Errorf("client non initialisé pour %s", exchangeName)
+		}
+
+		price := client.GetLastPriceBTC()
+		if price <= 0 {
+			return nil, fmt.Errorf("prix BTC indisponible sur %s", exchangeName)
+		}
+
+		balances, err := client.GetDetailedBalances()
+		if err != nil {
+			return nil, fmt.Errorf("soldes indisponibles sur %s: %w", exchangeName, err)
+		}
+
+		balance := database.SnapshotBalance{
+			BTC:      balances["BTC"].Total,
+			USDC:     balances["USDC"].Total,
+			BTCPrice: price,
+		}
+		balance.Value = balance.BTC*price + balance.USDC
+
+		snapshot.Exchanges[exchangeName] = balance
+		snapshot.TotalValue += balance.Value
+		priceSum += price
+	}
+
+	if len(snapshot.Exchanges) == 0 {
+		return nil, fmt.Errorf("aucun exchange activé")
+	}
+	snapshot.BTCPrice = priceSum / float64(len(snapshot.Exchanges))
+	return snapshot, nil
+}
+
+// savePortfolioSnapshot enregistre l'instantané puis applique la rétention configurée
+func savePortfolioSnapshot(cfg *config.Config, snapshot *database.Snapshot) error {
+	repo := database.GetSnapshotRepository()
+	if err := repo.Save(snapshot); err != nil {
+		return err
+	}
+
+	if cfg.SnapshotFullResolutionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -cfg.SnapshotFullResolutionDays)
+		removed, err := repo.ThinBefore(cutoff)
+		if err != nil {
+			return fmt.Errorf("erreur lors de l'éclaircissement des instantanés: %w", err)
+		}
+		if removed > 0 {
+			exchangeEvent("", "snapshot").with("removed", removed).
+				detail("%d instantané(s) de plus de %d jours regroupé(s) à un par jour", removed, cfg.SnapshotFullResolutionDays)
+		}
+	}
+	return nil
+}
+
+// recordPortfolioSnapshot enregistre un instantané à la fin d'une mise à jour (SNAPSHOT_ON_UPDATE)
+func recordPortfolioSnapshot(cfg *config.Config) {
+	if !cfg.SnapshotOnUpdate {
+		return
+	}
+
+	ev := exchangeEvent("", "snapshot")
+	snapshot, err := collectPortfolioSnapshot(cfg)
+	if err != nil {
+		ev.with("error", err).warn("Instantané du portefeuille non enregistré: %v", err)
+		return
+	}
+	if err := savePortfolioSnapshot(cfg, snapshot); err != nil {
+		ev.with("error", err).fail("Erreur lors de l'enregistrement de l'instantané: %v", err)
+		return
+	}
+	ev.with("total_value", snapshot.TotalValue).info("Valeur du portefeuille: %.2f USDC (BTC à %.2f)",
+		snapshot.TotalValue, snapshot.BTCPrice)
+}
+
+// Snapshot enregistre immédiatement un instantané du portefeuille (--snapshot)
+func Snapshot() {
+	cfg, err := config.Get()
+	if err != nil {
+		color.Red("Erreur de configuration: %v", err)
+		os.Exit(1)
+	}
+
+	snapshot, err := collectPortfolioSnapshot(cfg)
+	if err != nil {
+		color.Red("Instantané impossible: %v", err)
+		os.Exit(1)
+	}
+	if err := savePortfolioSnapshot(cfg, snapshot); err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+
+	for _, exchangeName := range snapshotExchanges {
+		if balance, ok := snapshot.Exchanges[exchangeName]; ok {
+			color.White("%-8s %.8f BTC + %.2f USDC = %.2f USDC", exchangeName, balance.BTC, balance.USDC, balance.Value)
+		}
+	}
+	color.Green("Valeur totale du portefeuille: %.2f USDC (BTC à %.2f)", snapshot.TotalValue, snapshot.BTCPrice)
+}
+
+// handleEquityCurveAPI retourne la série des instantanés du portefeuille (?period=30j)
+func handleEquityCurveAPI(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshots, err := database.GetSnapshotRepository().FindBetween(startDate, endDate)
+	if err != nil {
+		http.Error(w, "Erreur lors de la récupération des instantanés: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"math"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+)
+
+// volatilityWindow est la période d'instantanés utilisée pour estimer la volatilité récente
+const volatilityWindow = 7 * 24 * time.Hour
+
+// pricePoint est un prix BTC observé à une date donnée
+type pricePoint struct {
+	At    time.Time
+	Price float64
+}
+
+// orderBookReader retourne le lecteur de carnet du client, sous le disjoncteur et la simulation
+func orderBookReader(client common.Exchange) (common.OrderBookReader, bool) {
+	if simulated, ok := client.(*simulatedExchange); ok {
+		client = simulated.Exchange
+	}
+	if guarded, ok := client.(*common.GuardedExchange); ok {
+		client = guarded.Exchange
+	}
+	reader, ok := client.(common.OrderBookReader)
+	return reader, ok
+}
+
+// checkSellAgainstBook compare le prix de vente calculé au haut du carnet avant de le placer.
+// L'écart achat/vente est journalisé; un prix à plus de <EXCHANGE>_SELL_MAX_ABOVE_ASK_PERCENT %
+// au-dessus de la meilleure vente est ramené à la meilleure vente plus un tick, sans descendre
+// sous feeAdjustedPrice. Si les frais ne peuvent pas être couverts dans cette limite, le prix
+// couvrant les frais est conservé et l'attente estimée d'après la volatilité récente est signalée.
+func checkSellAgainstBook(client common.Exchange, cycle *database.Cycle, ev *tradeEvent, sellPrice, feeAdjustedPrice float64) float64 {
+	reader, ok := orderBookReader(client)
+	if !ok {
+		return sellPrice
+	}
+	book, err := reader.GetOrderBook()
+	if err != nil {
+		ev.with("error", err).warn(i18n.T("spread.book_unavailable"), cycle.IdInt, err)
+		return sellPrice
+	}
+	if book.Ask <= 0 {
+		return sellPrice
+	}
+	ev.with("bid", book.Bid).with("ask", book.Ask).info(i18n.T("spread.book"),
+		cycle.IdInt, cycle.Exchange, book.Bid, book.Ask, book.Spread(), book.SpreadPercent())
+
+	maxAbovePercent := cfg.Exchanges[cycle.Exchange].SellMaxAboveAskPercent
+	if maxAbovePercent <= 0 || sellPrice <= book.Ask*(1+maxAbovePercent/100) {
+		return sellPrice
+	}
+
+	clamped := book.Ask + client.Precision().PriceTick
+	if clamped >= feeAdjustedPrice {
+		ev.warn(i18n.T("spread.clamped"),
+			cycle.IdInt, sellPrice, maxAbovePercent, book.Ask, clamped)
+		return clamped
+	}
+
+	// Les frais ne peuvent pas être couverts près du marché: garder le prix qui les couvre
+	kept := math.Min(sellPrice, feeAdjustedPrice)
+	if wait := expectedWait(kept, book.Ask, hourlyVolatility(recentPrices(cycle.Exchange))); wait > 0 {
+		ev.warn(i18n.T("spread.fees_kept_wait"),
+			cycle.IdInt, kept, (kept/book.Ask-1)*100, book.Ask, formatDetailedDuration(wait.Hours()/24))
+	} else {
+		ev.warn(i18n.T("spread.fees_kept_unknown"),
+			cycle.IdInt, kept, (kept/book.Ask-1)*100, book.Ask)
+	}
+	return kept
+}
+
+// recentPrices retourne les prix BTC de l'exchange relevés par les instantanés récents
+func recentPrices(exchange string) []pricePoint {
+	start := time.Now().Add(-volatilityWindow)
+	snapshots, err := database.GetSnapshotRepository().FindBetween(&start, nil)
+	if err != nil {
+		return nil
+	}
+
+	points := make([]pricePoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if balance, ok := snapshot.Exchanges[exchange]; ok && balance.BTCPrice > 0 {
+			points = append(points, pricePoint{At: snapshot.Timestamp, Price: balance.BTCPrice})
+		}
+	}
+	return points
+}
+
+// hourlyVolatility estime l'écart-type des variations logarithmiques du prix sur une heure,
+// à partir de prix triés par date (0 si moins de trois prix sont disponibles)
+func hourlyVolatility(points []pricePoint) float64 {
+	if len(points) < 3 {
+		return 0
+	}
+
+	var squares, hours float64
+	for i := 1; i < len(points); i++ {
+		elapsed := points[i].At.Sub(points[i-1].At).Hours()
+		if elapsed <= 0 || points[i-1].Price <= 0 {
+			continue
+		}
+		change := math.Log(points[i].Price / points[i-1].Price)
+		squares += change * change
+		hours += elapsed
+	}
+	if hours <= 0 {
+		return 0
+	}
+	return math.Sqrt(squares / hours)
+}
+
+// expectedWait estime le temps nécessaire pour que le prix monte de from à target avec la
+// volatilité horaire indiquée: pour une marche aléatoire, l'écart attendu croît comme √t
+func expectedWait(target, from, hourlyVol float64) time.Duration {
+	if hourlyVol <= 0 || from <= 0 || target <= from {
+		return 0
+	}
+	distance := math.Log(target / from)
+	hours := math.Pow(distance/hourlyVol, 2)
+	return time.Duration(hours * float64(time.Hour))
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHourlyVolatilityAndExpectedWait(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Variations alternées de ±1% par heure: volatilité horaire d'environ 1%
+	points := []pricePoint{
+		{At: start, Price: 60000},
+		{At: start.Add(time.Hour), Price: 60600},
+		{At: start.Add(2 * time.Hour), Price: 60000},
+		{At: start.Add(3 * time.Hour), Price: 60600},
+	}
+	vol := hourlyVolatility(points)
+	if math.Abs(vol-math.Log(1.01)) > 1e-9 {
+		t.Fatalf("volatilité horaire %.6f, attendu %.6f", vol, math.Log(1.01))
+	}
+
+	// Un écart de 2% demande environ 4 heures à 1% par heure
+	wait := expectedWait(60000*1.02, 60000, vol)
+	if wait < 3*time.Hour+50*time.Minute || wait > 4*time.Hour+10*time.Minute {
+		t.Errorf("attente estimée %s, attendu environ 4h", wait)
+	}
+
+	if hourlyVolatility(points[:2]) != 0 {
+		t.Error("deux prix ne suffisent pas à estimer la volatilité")
+	}
+	if expectedWait(59000, 60000, vol) != 0 {
+		t.Error("un prix cible sous le marché ne demande aucune attente")
+	}
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/internal/database"
+)
+
+// Jeux de données exportables en CSV par /api/stats/export.csv?dataset=
+const (
+	statsDatasetDailyProfits  = "dailyProfits"
+	statsDatasetProfitHistory = "profitHistory"
+	statsDatasetExchanges     = "exchanges"
+)
+
+// handleStatsExportCSV télécharge au format CSV les données d'un graphique des statistiques,
+// avec les mêmes filtres que /api/stats (?period= ou ?start=&end=, dateField, includeArchived)
+func handleStatsExportCSV(w http.ResponseWriter, r *http.Request) {
+	dataset := r.URL.Query().Get("dataset")
+	switch dataset {
+	case statsDatasetDailyProfits, statsDatasetProfitHistory, statsDatasetExchanges:
+	default:
+		http.Error(w, fmt.Sprintf("dataset invalide: %q (attendu: %s, %s ou %s)", dataset,
+			statsDatasetDailyProfits, statsDatasetProfitHistory, statsDatasetExchanges), http.StatusBadRequest)
+		return
+	}
+
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dateField, err := parseDateField(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	allCycles, err := statsCycles(r)
+	if err != nil {
+		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cycles := filterCyclesByPeriod(allCycles, startDate, endDate, dateField)
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=stats-%s.csv", dataset))
+	if err := writeStatsCSV(w, dataset, cycles); err != nil {
+		// Les en-têtes sont déjà envoyés: l'erreur ne peut être que journalisée
+		log.Printf("Erreur lors de l'export CSV des statistiques (%s): %v", dataset, err)
+	}
+}
+
+// writeStatsCSV écrit le jeu de données demandé, calculé comme pour /api/stats et
+// /api/exchanges-comparison. Les lignes sont transmises au fil de l'écriture.
+func writeStatsCSV(w io.Writer, dataset string, cycles []*database.Cycle) error {
+	writer := csv.NewWriter(w)
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 8, 64)
+	}
+
+	var header []string
+	var rows [][]string
+	switch dataset {
+	case statsDatasetDailyProfits:
+		header = []string{"date", "profit_usdc"}
+		for _, day := range calculateDailyProfits(cycles) {
+			rows = append(rows, []string{day.Date, amount(day.Profit)})
+		}
+	case statsDatasetProfitHistory:
+		header = []string{"date", "exchange", "profit_cumule_usdc"}
+		for _, point := range calculateProfitHistory(cycles) {
+			rows = append(rows, []string{point.Date.Format(time.RFC3339), point.Exchange, amount(point.Profit)})
+		}
+	case statsDatasetExchanges:
+		header = []string{
+			"exchange", "cycles", "completes", "achats", "ventes",
+			"volume_achat_usdc", "volume_vente_usdc", "profit_usdc", "profit_pct",
+			"duree_moyenne_heures", "taux_reussite_pct", "accumulations", "btc_accumule",
+		}
+		for _, stats := range calculateExchangeStats(cycles) {
+			rows = append(rows, []string{
+				stats.Name, strconv.Itoa(stats.TotalCycles), strconv.Itoa(stats.CompletedCycles),
+				strconv.Itoa(stats.BuyCycles), strconv.Itoa(stats.SellCycles),
+				amount(stats.TotalBuyVolume), amount(stats.TotalSellVolume), amount(stats.TotalProfit),
+				strconv.FormatFloat(stats.ProfitPercentage, 'f', 4, 64),
+				strconv.FormatFloat(stats.AverageCycleDuration, 'f', 2, 64),
+				strconv.FormatFloat(stats.SuccessRate, 'f', 2, 64),
+				strconv.Itoa(stats.AccumulationCount), amount(stats.AccumulatedBTC),
+			})
+		}
+	default:
+		return fmt.Errorf("dataset invalide: %q", dataset)
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		// Transmettre les lignes par blocs plutôt qu'en une seule réponse
+		if i%500 == 499 {
+			writer.Flush()
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
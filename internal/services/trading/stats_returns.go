@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"sort"
+	"time"
+
+	"main/internal/database"
+)
+
+// ReturnMetrics regroupe les indicateurs de rendement et de risque de /api/stats
+type ReturnMetrics struct {
+	AnnualizedReturn  float64        `json:"annualizedReturn"`  // % par an sur le capital engagé
+	MaxDrawdown       float64        `json:"maxDrawdown"`       // USDC, sur le profit réalisé cumulé
+	LongestWinStreak  int            `json:"longestWinStreak"`  // cycles gagnants consécutifs
+	LongestLossStreak int            `json:"longestLossStreak"` // cycles perdants consécutifs
+	CurrentStreak     int            `json:"currentStreak"`     // > 0 gains, < 0 pertes
+	Benchmark         *HodlBenchmark `json:"hodlBenchmark,omitempty"`
+}
+
+// HodlBenchmark compare le bot à un achat unique de BTC conservé depuis le premier cycle
+type HodlBenchmark struct {
+	StartDate        time.Time         `json:"startDate"`
+	StartPrice       float64           `json:"startPrice"`
+	CurrentPrice     float64           `json:"currentPrice"`
+	Capital          float64           `json:"capital"` // capital maximal engagé simultanément par le bot
+	Quantity         float64           `json:"quantity"`
+	Profit           float64           `json:"profit"`
+	ProfitPercentage float64           `json:"profitPercentage"`
+	History          []ProfitTimePoint `json:"history"`
+}
+
+// cycleCapital retourne le montant USDC engagé à l'achat d'un cycle
+func cycleCapital(cycle *database.Cycle) float64 {
+	if cycle.PurchaseAmountUSDC > 0 {
+		return cycle.PurchaseAmountUSDC
+	}
+	return cycle.EffectiveBuyPrice() * cycle.Quantity
+}
+
+// calculateReturnMetrics calcule le rendement annualisé, le drawdown maximal, les séries de gains et
+// de pertes et, si currentPrice est connu, la comparaison avec la simple conservation de BTC
+func calculateReturnMetrics(cycles []*database.Cycle, currentPrice float64, now time.Time) ReturnMetrics {
+	var metrics ReturnMetrics
+
+	var completed []*database.Cycle
+	for _, cycle := range cycles {
+		if cycle.Status == "completed" {
+			completed = append(completed, cycle)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return cyclePeriodDate(completed[i], dateFieldAuto).Before(cyclePeriodDate(completed[j], dateFieldAuto))
+	})
+
+	// Rendement annualisé: profit rapporté au capital pondéré par sa durée d'immobilisation.
+	// Les cycles sans date de complétion connue n'ont pas de durée et sont écartés.
+	var timedProfit, capitalYears float64
+	var cumulative, peak float64
+	var winStreak, lossStreak int
+	for _, cycle := range completed {
+		profit := (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
+
+		if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+			years := completedAt.Sub(cycle.CreatedAt).Hours() / (24 * 365)
+			if years > 0 {
+				capitalYears += cycleCapital(cycle) * years
+				timedProfit += profit
+			}
+		}
+
+		// Drawdown: plus forte baisse du profit cumulé depuis son plus haut
+		cumulative += profit
+		if cumulative > peak {
+			peak = cumulative
+		}
+		if peak-cumulative > metrics.MaxDrawdown {
+			metrics.MaxDrawdown = peak - cumulative
+		}
+
+		// Séries: un cycle sans profit compte comme une perte, comme pour le taux de réussite
+		if profit > 0 {
+			winStreak++
+			lossStreak = 0
+		} else {
+			lossStreak++
+			winStreak = 0
+		}
+		if winStreak > metrics.LongestWinStreak {
+			metrics.LongestWinStreak = winStreak
+		}
+		if lossStreak > metrics.LongestLossStreak {
+			metrics.LongestLossStreak = lossStreak
+		}
+	}
+	metrics.CurrentStreak = winStreak - lossStreak
+	if capitalYears > 0 {
+		metrics.AnnualizedReturn = timedProfit / capitalYears * 100
+	}
+
+	if currentPrice > 0 {
+		metrics.Benchmark = calculateHodlBenchmark(cycles, completed, currentPrice, now)
+	}
+	return metrics
+}
+
+// calculateHodlBenchmark simule l'achat, au prix d'achat du premier cycle, d'autant de BTC que le
+// capital maximal engagé simultanément par le bot. L'historique valorise cette position au prix de
+// vente de chaque cycle complété, puis au prix actuel.
+func calculateHodlBenchmark(cycles, completed []*database.Cycle, currentPrice float64, now time.Time) *HodlBenchmark {
+	type capitalEvent struct {
+		at     time.Time
+		amount float64
+	}
+
+	var first *database.Cycle
+	var events []capitalEvent
+	for _, cycle := range cycles {
+		if cycle.Status == "cancelled" || cycle.EffectiveBuyPrice() <= 0 || cycle.CreatedAt.IsZero() {
+			continue
+		}
+		if first == nil || cycle.CreatedAt.Before(first.CreatedAt) {
+			first = cycle
+		}
+
+		capital := cycleCapital(cycle)
+		end := now
+		if cycle.Status == "completed" {
+			if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+				end = completedAt
+			}
+		}
+		events = append(events, capitalEvent{cycle.CreatedAt, capital}, capitalEvent{end, -capital})
+	}
+	if first == nil {
+		return nil
+	}
+
+	// Capital maximal engagé: les sorties d'une même date passent avant les entrées
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].amount < events[j].amount
+		}
+		return events[i].at.Before(events[j].at)
+	})
+	var deployed, capital float64
+	for _, event := range events {
+		deployed += event.amount
+		if deployed > capital {
+			capital = deployed
+		}
+	}
+	if capital <= 0 {
+		return nil
+	}
+
+	startPrice := first.EffectiveBuyPrice()
+	benchmark := &HodlBenchmark{
+		StartDate:    first.CreatedAt,
+		StartPrice:   startPrice,
+		CurrentPrice: currentPrice,
+		Capital:      capital,
+		Quantity:     capital / startPrice,
+	}
+	benchmark.Profit = benchmark.Quantity*currentPrice - capital
+	benchmark.ProfitPercentage = benchmark.Profit / capital * 100
+
+	benchmark.History = append(benchmark.History, ProfitTimePoint{Date: first.CreatedAt, Exchange: "HODL"})
+	for _, cycle := range completed {
+		date := cyclePeriodDate(cycle, dateFieldAuto)
+		if date.Before(first.CreatedAt) {
+			continue
+		}
+		benchmark.History = append(benchmark.History, ProfitTimePoint{
+			Date:     date,
+			Profit:   benchmark.Quantity*cycle.EffectiveSellPrice() - capital,
+			Exchange: "HODL",
+		})
+	}
+	benchmark.History = append(benchmark.History, ProfitTimePoint{Date: now, Profit: benchmark.Profit, Exchange: "HODL"})
+
+	return benchmark
+}
+
+// statsBTCPrice retourne le prix actuel du BTC sur le premier exchange activé qui répond, 0 sinon
+func statsBTCPrice() float64 {
+	for _, exchangeName := range snapshotExchanges {
+		exchangeConfig, exists := cfg.Exchanges[exchangeName]
+		if !exists || !exchangeConfig.Enabled || exchangeConfig.APIKey == "" || breakerFor(exchangeName).IsOpen() {
+			continue
+		}
+		client := guardedClient(exchangeName)
+		if client == nil {
+			continue
+		}
+		if price := client.GetLastPriceBTC(); price > 0 {
+			return price
+		}
+	}
+	return 0
+}
@@ -3,10 +3,11 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"log"
 	"main/internal/config"
 	"main/internal/database"
+	"main/internal/web"
+	"math"
 	"net/http"
 	"sort"
 	"time"
@@ -14,29 +15,41 @@ import (
 
 // StatsServer démarre un serveur HTTP dédié aux statistiques avancées
 func StatsServer() {
-	fmt.Println("Démarrage du serveur de statistiques sur http://localhost:8081")
-	fmt.Println("Appuyez sur Ctrl+C pour arrêter le serveur")
+	// Compiler les templates une seule fois
+	loadTemplates()
 
 	// Initialiser le router
 	mux := http.NewServeMux()
 
 	// Route principale pour afficher les statistiques
-	mux.HandleFunc("/", handleStatsPage)
+	mux.HandleFunc("/", requireAuth(handleStatsPage))
 
 	// Route API pour obtenir les données JSON pour les graphiques
-	mux.HandleFunc("/api/stats", handleStatsAPI)
+	mux.HandleFunc("/api/stats", requireAuth(handleStatsAPI))
+
+	// Export CSV des données des graphiques (?dataset=dailyProfits|profitHistory|exchanges)
+	mux.HandleFunc("/api/stats/export.csv", requireAuth(handleStatsExportCSV))
 
 	// Route API pour les données de comparaison d'exchanges
-	mux.HandleFunc("/api/exchanges-comparison", handleExchangesComparisonAPI)
+	mux.HandleFunc("/api/exchanges-comparison", requireAuth(handleExchangesComparisonAPI))
 
 	// Route API pour les données de performance par période
-	mux.HandleFunc("/api/period-performance", handlePeriodPerformanceAPI)
+	mux.HandleFunc("/api/period-performance", requireAuth(handlePeriodPerformanceAPI))
 
 	// Route API pour les données d'accumulation
-	mux.HandleFunc("/api/accumulation-stats", handleAccumulationStatsAPI)
+	mux.HandleFunc("/api/accumulation-stats", requireAuth(handleAccumulationStatsAPI))
+
+	// Route API pour la courbe de valeur du portefeuille (instantanés)
+	mux.HandleFunc("/api/equity-curve", requireAuth(handleEquityCurveAPI))
+
+	// Formulaire de connexion lorsque SERVER_AUTH_TOKEN est configuré
+	mux.HandleFunc("/login", handleLogin)
+
+	// État de santé (disjoncteurs des exchanges lors de la dernière mise à jour)
+	mux.HandleFunc("/health", requireAuth(handleHealth))
 
 	// Démarrer le serveur sur un port différent pour éviter les conflits
-	err := http.ListenAndServe("localhost:8081", mux)
+	err := listenAndServe("serveur de statistiques", cfg.StatsPort, mux)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -55,6 +68,10 @@ type GlobalStats struct {
 	AverageCycleDuration float64   `json:"averageCycleDuration"` // En heures
 	SuccessRate          float64   `json:"successRate"`          // % de cycles complétés avec profit
 	LastUpdate           time.Time `json:"lastUpdate"`
+
+	// Cycles annulés, par cause (max_age, price_deviation, manual, order_not_found, duplicate, unknown)
+	CancelledCycles       int            `json:"cancelledCycles"`
+	CancellationsByReason map[string]int `json:"cancellationsByReason"`
 }
 
 // Structure pour les statistiques par exchange
@@ -96,737 +113,61 @@ type ProfitTimePoint struct {
 type DailyProfitData struct {
 	Date   string  `json:"date"`
 	Profit float64 `json:"profit"`
+	// Profit du jour dans la devise d'affichage, au taux publié ce jour-là (absent sans conversion)
+	Display *displayConversion `json:"display,omitempty"`
 }
 
 // handleStatsPage gère l'affichage de la page de statistiques avancées
 func handleStatsPage(w http.ResponseWriter, r *http.Request) {
-	// Définir le template HTML avec le support des graphiques
-	statsTemplate := `<!DOCTYPE html>
-<html lang="fr">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Cryptomancien - Statistiques Avancées</title>
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bootstrap@5.2.3/dist/css/bootstrap.min.css">
-    <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
-    <script src="https://cdn.jsdelivr.net/npm/moment@2.29.4/moment.min.js"></script>
-    <script src="https://cdn.jsdelivr.net/npm/chartjs-adapter-moment@1.0.1/dist/chartjs-adapter-moment.min.js"></script>
-    <style>
-        body {
-            padding-top: 20px;
-            background-color: #f8f9fa;
-        }
-        .header {
-            margin-bottom: 30px;
-        }
-        .stats-card {
-            margin-bottom: 20px;
-            transition: transform 0.3s;
-            height: 100%;
-        }
-        .stats-card:hover {
-            transform: translateY(-5px);
-        }
-        .profit-positive {
-            color: #28a745;
-        }
-        .profit-negative {
-            color: #dc3545;
-        }
-        .chart-container {
-            position: relative;
-            height: 400px;
-            width: 100%;
-            margin-bottom: 30px;
-        }
-        .period-selector {
-            margin-bottom: 20px;
-        }
-        .nav-tabs .nav-link {
-            cursor: pointer;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1 class="text-center mb-4">Cryptomancien - Statistiques Avancées</h1>
-            <div class="row">
-                <div class="col-md-12">
-                    <div class="card">
-                        <div class="card-body">
-                            <div class="period-selector d-flex justify-content-center">
-                                <div class="btn-group" role="group">
-                                    <button type="button" class="btn btn-outline-primary" data-period="7j">7 jours</button>
-                                    <button type="button" class="btn btn-outline-primary" data-period="30j">30 jours</button>
-                                    <button type="button" class="btn btn-outline-primary" data-period="90j">3 mois</button>
-                                    <button type="button" class="btn btn-outline-primary" data-period="180j">6 mois</button>
-                                    <button type="button" class="btn btn-outline-primary" data-period="365j">1 an</button>
-                                    <button type="button" class="btn btn-outline-primary active" data-period="all">Tout</button>
-                                </div>
-                            </div>
-                        </div>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <!-- Statistiques globales -->
-        <div class="row mb-4">
-            <div class="col-12">
-                <h2 class="mb-3">Statistiques Globales</h2>
-            </div>
-            <div class="col-md-3">
-                <div class="card stats-card bg-light">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Cycles Totaux</h5>
-                        <p class="card-text fs-2" id="total-cycles">-</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card stats-card bg-primary text-white">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Cycles Complétés</h5>
-                        <p class="card-text fs-2" id="completed-cycles">-</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card stats-card bg-light">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Volume Total</h5>
-                        <p class="card-text fs-2" id="total-volume">-</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-3">
-                <div class="card stats-card bg-success text-white">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Profit Total</h5>
-                        <p class="card-text fs-2" id="total-profit">-</p>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <div class="row mb-4">
-            <div class="col-md-4">
-                <div class="card stats-card">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Taux de Réussite</h5>
-                        <p class="card-text fs-2" id="success-rate">-</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-4">
-                <div class="card stats-card">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Durée Moyenne du Cycle</h5>
-                        <p class="card-text fs-2" id="avg-duration">-</p>
-                    </div>
-                </div>
-            </div>
-            <div class="col-md-4">
-                <div class="card stats-card">
-                    <div class="card-body text-center">
-                        <h5 class="card-title">Rentabilité Moyenne</h5>
-                        <p class="card-text fs-2" id="avg-profitability">-</p>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <!-- Navigation par onglets -->
-        <ul class="nav nav-tabs" id="myTab" role="tablist">
-            <li class="nav-item" role="presentation">
-                <button class="nav-link active" id="profit-history-tab" data-bs-toggle="tab" data-bs-target="#profit-history" type="button" role="tab">Historique des Profits</button>
-            </li>
-            <li class="nav-item" role="presentation">
-                <button class="nav-link" id="exchange-comparison-tab" data-bs-toggle="tab" data-bs-target="#exchange-comparison" type="button" role="tab">Comparaison des Exchanges</button>
-            </li>
-            <li class="nav-item" role="presentation">
-                <button class="nav-link" id="period-performance-tab" data-bs-toggle="tab" data-bs-target="#period-performance" type="button" role="tab">Performance par Période</button>
-            </li>
-            <li class="nav-item" role="presentation">
-                <button class="nav-link" id="accumulation-tab" data-bs-toggle="tab" data-bs-target="#accumulation" type="button" role="tab">Accumulation</button>
-            </li>
-        </ul>
-
-        <!-- Contenu des onglets -->
-        <div class="tab-content mt-4" id="myTabContent">
-            <!-- Onglet Historique des Profits -->
-            <div class="tab-pane fade show active" id="profit-history" role="tabpanel">
-                <div class="chart-container">
-                    <canvas id="profit-history-chart"></canvas>
-                </div>
-                <div class="chart-container">
-                    <canvas id="daily-profit-chart"></canvas>
-                </div>
-            </div>
-            
-            <!-- Onglet Comparaison des Exchanges -->
-            <div class="tab-pane fade" id="exchange-comparison" role="tabpanel">
-                <div class="row">
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="exchange-profit-chart"></canvas>
-                        </div>
-                    </div>
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="exchange-volume-chart"></canvas>
-                        </div>
-                    </div>
-                </div>
-                <div class="row mt-4">
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="exchange-success-chart"></canvas>
-                        </div>
-                    </div>
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="exchange-duration-chart"></canvas>
-                        </div>
-                    </div>
-                </div>
-            </div>
-            
-            <!-- Onglet Performance par Période -->
-            <div class="tab-pane fade" id="period-performance" role="tabpanel">
-                <div class="row">
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="period-profit-chart"></canvas>
-                        </div>
-                    </div>
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="period-success-chart"></canvas>
-                        </div>
-                    </div>
-                </div>
-            </div>
-            
-            <!-- Onglet Accumulation -->
-            <div class="tab-pane fade" id="accumulation" role="tabpanel">
-                <div class="row">
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="accumulation-volume-chart"></canvas>
-                        </div>
-                    </div>
-                    <div class="col-md-6">
-                        <div class="chart-container">
-                            <canvas id="accumulation-savings-chart"></canvas>
-                        </div>
-                    </div>
-                </div>
-            </div>
-        </div>
-
-        <div class="mt-4 text-muted">
-            <p>Dernière mise à jour: <span id="last-update"></span></p>
-            <p><a href="/" class="btn btn-outline-secondary">Retour au tableau de bord principal</a></p>
-        </div>
-    </div>
-
-    <script src="https://cdn.jsdelivr.net/npm/bootstrap@5.2.3/dist/js/bootstrap.bundle.min.js"></script>
-    <script>
-        // Fonction pour formater les durées
-        function formatDuration(hours) {
-            if (hours < 1) {
-                return Math.round(hours * 60) + ' min';
-            } else if (hours < 24) {
-                const h = Math.floor(hours);
-                const m = Math.round((hours - h) * 60);
-                return h + 'h ' + (m > 0 ? m + 'm' : '');
-            } else {
-                const days = Math.floor(hours / 24);
-                const h = Math.floor(hours % 24);
-                return days + 'j ' + (h > 0 ? h + 'h' : '');
-            }
-        }
-
-        // Fonction pour charger les statistiques globales
-        async function loadGlobalStats(period = 'all') {
-            try {
-                const response = await fetch('/api/stats?period=' + period);
-                const data = await response.json();
-                
-                // Mettre à jour les cartes de statistiques
-                document.getElementById('total-cycles').textContent = data.totalCycles;
-                document.getElementById('completed-cycles').textContent = data.completedCycles;
-                document.getElementById('total-volume').textContent = data.totalBuyVolume.toFixed(2) + ' USDC';
-                
-                const profitElement = document.getElementById('total-profit');
-                profitElement.textContent = data.totalProfit.toFixed(2) + ' USDC (' + data.profitPercentage.toFixed(2) + '%)';
-                profitElement.className = data.totalProfit >= 0 ? 'card-text fs-2' : 'card-text fs-2 text-danger';
-                
-                document.getElementById('success-rate').textContent = data.successRate.toFixed(2) + '%';
-                document.getElementById('avg-duration').textContent = formatDuration(data.averageCycleDuration);
-                document.getElementById('avg-profitability').textContent = data.profitPercentage.toFixed(2) + '%';
-                
-                document.getElementById('last-update').textContent = new Date().toLocaleString();
-                
-                // Charger les graphiques
-                loadProfitHistoryChart(period);
-                loadDailyProfitChart(period);
-            } catch (error) {
-                console.error('Erreur lors du chargement des statistiques:', error);
-            }
-        }
-
-        // Fonction pour charger le graphique d'historique des profits
-        async function loadProfitHistoryChart(period = 'all') {
-            try {
-                const response = await fetch('/api/stats?period=' + period);
-                const globalData = await response.json();
-                
-                // Récupérer les données de l'historique des profits
-                const profitPoints = globalData.profitHistory || [];
-                
-                // Créer des ensembles de données par exchange
-                const exchanges = [...new Set(profitPoints.map(point => point.exchange))];
-                const datasets = exchanges.map((exchange, index) => {
-                    const colors = ['#28a745', '#007bff', '#fd7e14', '#6f42c1', '#e83e8c'];
-                    return {
-                        label: exchange,
-                        data: profitPoints
-                            .filter(point => point.exchange === exchange)
-                            .map(point => ({
-                                x: new Date(point.date),
-                                y: point.profit
-                            })),
-                        borderColor: colors[index % colors.length],
-                        backgroundColor: colors[index % colors.length] + '33',
-                        fill: false,
-                        tension: 0.1
-                    };
-                });
-                
-                // Créer le graphique
-                const ctx = document.getElementById('profit-history-chart').getContext('2d');
-                
-                // Détruire le graphique existant s'il existe
-                if (window.profitHistoryChart) {
-                    window.profitHistoryChart.destroy();
-                }
-                
-                window.profitHistoryChart = new Chart(ctx, {
-                    type: 'line',
-                    data: {
-                        datasets: datasets
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        plugins: {
-                            title: {
-                                display: true,
-                                text: 'Évolution du Profit par Exchange au fil du temps',
-                                font: {
-                                    size: 16
-                                }
-                            },
-                            tooltip: {
-                                mode: 'index',
-                                intersect: false
-                            },
-                            legend: {
-                                position: 'top'
-                            }
-                        },
-                        scales: {
-                            x: {
-                                type: 'time',
-                                time: {
-                                    unit: 'day',
-                                    tooltipFormat: 'DD MMM YYYY'
-                                },
-                                title: {
-                                    display: true,
-                                    text: 'Date'
-                                }
-                            },
-                            y: {
-                                title: {
-                                    display: true,
-                                    text: 'Profit (USDC)'
-                                }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Erreur lors du chargement du graphique d\'historique des profits:', error);
-            }
-        }
-
-        // Fonction pour charger le graphique des profits journaliers
-        async function loadDailyProfitChart(period = 'all') {
-            try {
-                const response = await fetch('/api/stats?period=' + period);
-                const globalData = await response.json();
-                
-                // Récupérer les données des profits journaliers
-                const dailyProfits = globalData.dailyProfits || [];
-                
-                // Créer le graphique
-                const ctx = document.getElementById('daily-profit-chart').getContext('2d');
-                
-                // Détruire le graphique existant s'il existe
-                if (window.dailyProfitChart) {
-                    window.dailyProfitChart.destroy();
-                }
-                
-                window.dailyProfitChart = new Chart(ctx, {
-                    type: 'bar',
-                    data: {
-                        labels: dailyProfits.map(day => day.date),
-                        datasets: [{
-                            label: 'Profit Journalier',
-                            data: dailyProfits.map(day => day.profit),
-                            backgroundColor: function(context) {
-                                const value = context.dataset.data[context.dataIndex];
-                                return value >= 0 ? 'rgba(40, 167, 69, 0.6)' : 'rgba(220, 53, 69, 0.6)';
-                            },
-                            borderColor: function(context) {
-                                const value = context.dataset.data[context.dataIndex];
-                                return value >= 0 ? 'rgb(40, 167, 69)' : 'rgb(220, 53, 69)';
-                            },
-                            borderWidth: 1
-                        }]
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        plugins: {
-                            title: {
-                                display: true,
-                                text: 'Profits Journaliers',
-                                font: {
-                                    size: 16
-                                }
-                            },
-                            legend: {
-                                display: false
-                            }
-                        },
-                        scales: {
-                            x: {
-                                title: {
-                                    display: true,
-                                    text: 'Date'
-                                }
-                            },
-                            y: {
-                                title: {
-                                    display: true,
-                                    text: 'Profit (USDC)'
-                                }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Erreur lors du chargement du graphique des profits journaliers:', error);
-            }
-        }
-
-        // Fonction pour charger les graphiques de comparaison d'exchanges
-        async function loadExchangeComparisonCharts(period = 'all') {
-            try {
-                const response = await fetch('/api/exchanges-comparison?period=' + period);
-                const data = await response.json();
-                
-                const exchangeNames = data.map(exchange => exchange.name);
-                const profits = data.map(exchange => exchange.totalProfit);
-                const volumes = data.map(exchange => exchange.totalBuyVolume);
-                const successRates = data.map(exchange => exchange.successRate);
-                const durations = data.map(exchange => exchange.averageCycleDuration);
-                
-                // Graphique de comparaison des profits par exchange
-                createExchangeComparisonChart('exchange-profit-chart', exchangeNames, profits, 'Profit Total par Exchange', 'Profit (USDC)', 'bar');
-                
-                // Graphique de comparaison des volumes par exchange
-                createExchangeComparisonChart('exchange-volume-chart', exchangeNames, volumes, 'Volume Total par Exchange', 'Volume (USDC)', 'bar');
-                
-                // Graphique de comparaison des taux de réussite par exchange
-                createExchangeComparisonChart('exchange-success-chart', exchangeNames, successRates, 'Taux de Réussite par Exchange', 'Taux de Réussite (%)', 'bar');
-                
-                // Graphique de comparaison des durées moyennes de cycle par exchange
-                createExchangeComparisonChart('exchange-duration-chart', exchangeNames, durations, 'Durée Moyenne des Cycles par Exchange', 'Durée (heures)', 'bar');
-            } catch (error) {
-                console.error('Erreur lors du chargement des graphiques de comparaison d\'exchanges:', error);
-            }
-        }
-
-        // Fonction pour créer un graphique de comparaison d'exchanges
-        function createExchangeComparisonChart(canvasId, labels, data, title, yAxisTitle, type = 'bar') {
-            const colors = ['#28a745', '#007bff', '#fd7e14', '#6f42c1', '#e83e8c'];
-            
-            const ctx = document.getElementById(canvasId).getContext('2d');
-            
-            // Détruire le graphique existant s'il existe
-            if (window[canvasId + 'Chart']) {
-                window[canvasId + 'Chart'].destroy();
-            }
-            
-            window[canvasId + 'Chart'] = new Chart(ctx, {
-                type: type,
-                data: {
-                    labels: labels,
-                    datasets: [{
-                        label: title,
-                        data: data,
-                        backgroundColor: colors.map(color => color + '80'),
-                        borderColor: colors,
-                        borderWidth: 1
-                    }]
-                },
-                options: {
-                    responsive: true,
-                    maintainAspectRatio: false,
-                    plugins: {
-                        title: {
-                            display: true,
-                            text: title,
-                            font: {
-                                size: 16
-                            }
-                        },
-                        legend: {
-                            display: false
-                        }
-                    },
-                    scales: {
-                        y: {
-                            title: {
-                                display: true,
-                                text: yAxisTitle
-                            }
-                        }
-                    }
-                }
-            });
-        }
-
-        // Fonction pour charger les graphiques de performance par période
-        async function loadPeriodPerformanceCharts(period = 'all') {
-            try {
-                const response = await fetch('/api/period-performance?period=' + period);
-                const data = await response.json();
-                
-                const periods = data.map(period => period.period);
-                const profits = data.map(period => period.totalProfit);
-                const successRates = data.map(period => period.successRate);
-                
-                // Graphique de profit par période
-                createPeriodPerformanceChart('period-profit-chart', periods, profits, 'Profit Total par Période', 'Profit (USDC)');
-                
-                // Graphique de taux de réussite par période
-                createPeriodPerformanceChart('period-success-chart', periods, successRates, 'Taux de Réussite par Période', 'Taux de Réussite (%)');
-            } catch (error) {
-                console.error('Erreur lors du chargement des graphiques de performance par période:', error);
-            }
-        }
-
-        // Fonction pour créer un graphique de performance par période
-        function createPeriodPerformanceChart(canvasId, labels, data, title, yAxisTitle) {
-            const ctx = document.getElementById(canvasId).getContext('2d');
-            
-            // Détruire le graphique existant s'il existe
-            if (window[canvasId + 'Chart']) {
-                window[canvasId + 'Chart'].destroy();
-            }
-            
-            window[canvasId + 'Chart'] = new Chart(ctx, {
-                type: 'line',
-                data: {
-                    labels: labels,
-                    datasets: [{
-                        label: title,
-                        data: data,
-                        backgroundColor: 'rgba(40, 167, 69, 0.2)',
-                        borderColor: 'rgb(40, 167, 69)',
-                        borderWidth: 2,
-                        fill: true,
-                        tension: 0.1
-                    }]
-                },
-                options: {
-                    responsive: true,
-                    maintainAspectRatio: false,
-                    plugins: {
-                        title: {
-                            display: true,
-                            text: title,
-                            font: {
-                                size: 16
-                            }
-                        },
-                        legend: {
-                            display: false
-                        }
-                    },
-                    scales: {
-                        y: {
-                            title: {
-                                display: true,
-                                text: yAxisTitle
-                            }
-                        }
-                    }
-                }
-            });
-        }
-
-        // Fonction pour charger les graphiques d'accumulation
-        async function loadAccumulationCharts(period = 'all') {
-            try {
-                const response = await fetch('/api/accumulation-stats?period=' + period);
-                const data = await response.json();
-                
-                const exchangeNames = data.map(exchange => exchange.name);
-                const btcVolumes = data.map(exchange => exchange.accumulatedBTC);
-                const savingsValues = data.map(exchange => exchange.savedValue);
-                
-                // Graphique des volumes de BTC accumulés
-                createAccumulationChart('accumulation-volume-chart', exchangeNames, btcVolumes, 'Volume BTC Accumulé par Exchange', 'BTC');
-                
-                // Graphique des économies réalisées grâce à l'accumulation
-                createAccumulationChart('accumulation-savings-chart', exchangeNames, savingsValues, 'Économies Réalisées par Exchange', 'USDC');
-            } catch (error) {
-                console.error('Erreur lors du chargement des graphiques d\'accumulation:', error);
-            }
-        }
-
-        // Fonction pour créer un graphique d'accumulation
-        function createAccumulationChart(canvasId, labels, data, title, yAxisTitle) {
-            const colors = ['#28a745', '#007bff', '#fd7e14', '#6f42c1', '#e83e8c'];
-            
-            const ctx = document.getElementById(canvasId).getContext('2d');
-            
-            // Détruire le graphique existant s'il existe
-            if (window[canvasId + 'Chart']) {
-                window[canvasId + 'Chart'].destroy();
-            }
-            
-            window[canvasId + 'Chart'] = new Chart(ctx, {
-                type: 'bar',
-                data: {
-                    labels: labels,
-                    datasets: [{
-                        label: title,
-                        data: data,
-                        backgroundColor: colors.map(color => color + '80'),
-                        borderColor: colors,
-                        borderWidth: 1
-                    }]
-                },
-                options: {
-                    responsive: true,
-                    maintainAspectRatio: false,
-                    plugins: {
-                        title: {
-                            display: true,
-                            text: title,
-                            font: {
-                                size: 16
-                            }
-                        },
-                        legend: {
-                            display: false
-                        }
-                    },
-                    scales: {
-                        y: {
-                            title: {
-                                display: true,
-                                text: yAxisTitle
-                            }
-                        }
-                    }
-                }
-            });
-        }
-
-        // Une fois que tout est chargé
-        document.addEventListener('DOMContentLoaded', function() {
-            // Charger les statistiques initiales avec tous les données
-            loadGlobalStats('all');
-            
-            // Charger les différents graphiques
-            loadExchangeComparisonCharts('all');
-            loadPeriodPerformanceCharts('all');
-            loadAccumulationCharts('all');
-            
-            // Gestion des sélecteurs de période
-            document.querySelectorAll('.period-selector button').forEach(button => {
-                button.addEventListener('click', function() {
-                    // Mettre à jour la classe active
-                    document.querySelectorAll('.period-selector button').forEach(btn => {
-                        btn.classList.remove('active');
-                    });
-                    this.classList.add('active');
-                    
-                    // Récupérer la période sélectionnée
-                    const period = this.getAttribute('data-period');
-                    
-                    // Charger les données pour cette période
-                    loadGlobalStats(period);
-                    loadExchangeComparisonCharts(period);
-                    loadPeriodPerformanceCharts(period);
-                    loadAccumulationCharts(period);
-                });
-            });
-        });
-    </script>
-</body>
-</html>`
-
-	// Exécuter le template
-	tmpl, err := template.New("statsPage").Parse(statsTemplate)
-	if err != nil {
-		http.Error(w, "Erreur lors de la compilation du template: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	// Données à passer au template
 	data := map[string]interface{}{}
 
-	err = tmpl.Execute(w, data)
+	renderTemplate(w, web.StatsTemplate, data)
+}
+
+// statsCycles retourne les cycles actifs, complétés des cycles archivés lorsque
+// le paramètre includeArchived=true est fourni
+func statsCycles(r *http.Request) ([]*database.Cycle, error) {
+	cycles, err := database.GetRepository().FindAll()
 	if err != nil {
-		http.Error(w, "Erreur lors du rendu du template: "+err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+	if r.URL.Query().Get("includeArchived") != "true" {
+		return cycles, nil
 	}
+
+	archived, err := database.GetArchiveRepository().FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return append(cycles, archived...), nil
 }
 
 // handleStatsAPI gère les requêtes API pour les statistiques globales et historiques
 func handleStatsAPI(w http.ResponseWriter, r *http.Request) {
-	// Récupérer le paramètre de période
-	period := r.URL.Query().Get("period")
+	// Plage de dates: ?start=&end= ou période prédéfinie (?period=)
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Calculer les dates de début et de fin en fonction de la période
-	startDate, endDate := calculateDateRangeFromPeriod(period)
+	// Date qui situe un cycle dans la période (?dateField=created|completed)
+	dateField, err := parseDateField(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Récupérer tous les cycles
-	repo := database.GetRepository()
-	allCycles, err := repo.FindAll()
+	// Récupérer tous les cycles (archives comprises si demandé)
+	allCycles, err := statsCycles(r)
 	if err != nil {
 		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Filtrer les cycles en fonction de la période
-	var filteredCycles []*database.Cycle
-	for _, cycle := range allCycles {
-		if (startDate == nil || !cycle.CreatedAt.Before(*startDate)) &&
-			(endDate == nil || !cycle.CreatedAt.After(*endDate)) {
-			filteredCycles = append(filteredCycles, cycle)
-		}
-	}
+	filteredCycles := filterCyclesByPeriod(allCycles, startDate, endDate, dateField)
 
 	// Calculer les statistiques globales
 	stats := calculateGlobalStats(filteredCycles)
@@ -839,6 +180,17 @@ func handleStatsAPI(w http.ResponseWriter, r *http.Request) {
 	dailyProfits := calculateDailyProfits(filteredCycles)
 	stats.DailyProfits = dailyProfits
 
+	// Profits convertis dans la devise d'affichage (DISPLAY_CURRENCY)
+	stats.Display = realizedDisplayTotal(filteredCycles, cycleGrossProfit)
+	for i, day := range stats.DailyProfits {
+		if date, err := time.ParseInLocation("2006-01-02", day.Date, time.Local); err == nil {
+			stats.DailyProfits[i].Display = displayValue(day.Profit, date)
+		}
+	}
+
+	// Ajouter le rendement, le drawdown et la comparaison avec la conservation de BTC
+	stats.Returns = calculateReturnMetrics(filteredCycles, statsBTCPrice(), time.Now())
+
 	// Retourner les statistiques au format JSON
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
@@ -846,28 +198,29 @@ func handleStatsAPI(w http.ResponseWriter, r *http.Request) {
 
 // handleExchangesComparisonAPI gère les requêtes API pour les données de comparaison d'exchanges
 func handleExchangesComparisonAPI(w http.ResponseWriter, r *http.Request) {
-	// Récupérer le paramètre de période
-	period := r.URL.Query().Get("period")
+	// Plage de dates: ?start=&end= ou période prédéfinie (?period=)
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Calculer les dates de début et de fin en fonction de la période
-	startDate, endDate := calculateDateRangeFromPeriod(period)
+	// Date qui situe un cycle dans la période (?dateField=created|completed)
+	dateField, err := parseDateField(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Récupérer tous les cycles
-	repo := database.GetRepository()
-	allCycles, err := repo.FindAll()
+	// Récupérer tous les cycles (archives comprises si demandé)
+	allCycles, err := statsCycles(r)
 	if err != nil {
 		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Filtrer les cycles en fonction de la période
-	var filteredCycles []*database.Cycle
-	for _, cycle := range allCycles {
-		if (startDate == nil || !cycle.CreatedAt.Before(*startDate)) &&
-			(endDate == nil || !cycle.CreatedAt.After(*endDate)) {
-			filteredCycles = append(filteredCycles, cycle)
-		}
-	}
+	filteredCycles := filterCyclesByPeriod(allCycles, startDate, endDate, dateField)
 
 	// Calculer les statistiques par exchange
 	exchangeStats := calculateExchangeStats(filteredCycles)
@@ -879,28 +232,29 @@ func handleExchangesComparisonAPI(w http.ResponseWriter, r *http.Request) {
 
 // handlePeriodPerformanceAPI gère les requêtes API pour les données de performance par période
 func handlePeriodPerformanceAPI(w http.ResponseWriter, r *http.Request) {
-	// Récupérer le paramètre de période globale
-	globalPeriod := r.URL.Query().Get("period")
+	// Plage de dates: ?start=&end= ou période prédéfinie (?period=)
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Calculer les dates de début et de fin en fonction de la période globale
-	startDate, endDate := calculateDateRangeFromPeriod(globalPeriod)
+	// Date qui situe un cycle dans la période (?dateField=created|completed)
+	dateField, err := parseDateField(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Récupérer tous les cycles
-	repo := database.GetRepository()
-	allCycles, err := repo.FindAll()
+	// Récupérer tous les cycles (archives comprises si demandé)
+	allCycles, err := statsCycles(r)
 	if err != nil {
 		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Filtrer les cycles en fonction de la période globale
-	var filteredCycles []*database.Cycle
-	for _, cycle := range allCycles {
-		if (startDate == nil || !cycle.CreatedAt.Before(*startDate)) &&
-			(endDate == nil || !cycle.CreatedAt.After(*endDate)) {
-			filteredCycles = append(filteredCycles, cycle)
-		}
-	}
+	filteredCycles := filterCyclesByPeriod(allCycles, startDate, endDate, dateField)
 
 	// Définir les périodes d'analyse
 	periods := []string{"7j", "30j", "90j", "180j", "365j"}
@@ -913,12 +267,7 @@ func handlePeriodPerformanceAPI(w http.ResponseWriter, r *http.Request) {
 		pStartDate, _ := calculateDateRangeFromPeriod(p)
 		if pStartDate != nil {
 			// Filtrer les cycles pour cette période spécifique
-			var periodCycles []*database.Cycle
-			for _, cycle := range filteredCycles {
-				if !cycle.CreatedAt.Before(*pStartDate) {
-					periodCycles = append(periodCycles, cycle)
-				}
-			}
+			periodCycles := filterCyclesByPeriod(filteredCycles, pStartDate, nil, dateField)
 
 			// Calculer les statistiques pour cette période
 			totalCycles := len(periodCycles)
@@ -928,14 +277,14 @@ func handlePeriodPerformanceAPI(w http.ResponseWriter, r *http.Request) {
 
 			for _, cycle := range periodCycles {
 				if cycle.Status == "completed" {
-					profit := (cycle.SellPrice - cycle.BuyPrice) * cycle.Quantity
+					profit := (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
 					totalProfit += profit
 
 					if profit > 0 {
 						successCount++
 					}
 
-					volumeTraded += cycle.BuyPrice * cycle.Quantity
+					volumeTraded += cycle.EffectiveBuyPrice() * cycle.Quantity
 				}
 			}
 
@@ -965,11 +314,12 @@ func handlePeriodPerformanceAPI(w http.ResponseWriter, r *http.Request) {
 
 // handleAccumulationStatsAPI gère les requêtes API pour les données d'accumulation
 func handleAccumulationStatsAPI(w http.ResponseWriter, r *http.Request) {
-	// Récupérer le paramètre de période
-	period := r.URL.Query().Get("period")
-
-	// Calculer les dates de début et de fin en fonction de la période
-	startDate, endDate := calculateDateRangeFromPeriod(period)
+	// Plage de dates: ?start=&end= ou période prédéfinie (?period=)
+	startDate, endDate, err := statsDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Récupérer le repository d'accumulations
 	accuRepo := database.GetAccumulationRepository()
@@ -991,14 +341,16 @@ func handleAccumulationStatsAPI(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Récupérer la configuration pour obtenir la liste des exchanges
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
 		http.Error(w, "Erreur lors du chargement de la configuration: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Calculer les statistiques d'accumulation par exchange
+	// Calculer les statistiques d'accumulation par exchange, le BTC conservé étant valorisé
+	// aux prix de la dernière mise à jour
 	accuStats := make([]map[string]interface{}, 0)
+	prices := loadLastPrices()
 
 	for exchangeName, exchangeConfig := range cfg.Exchanges {
 		if exchangeConfig.Enabled {
@@ -1013,22 +365,46 @@ func handleAccumulationStatsAPI(w http.ResponseWriter, r *http.Request) {
 			// Calculer les statistiques pour cet exchange
 			accumulatedBTC := 0.0
 			savedValue := 0.0
+			convertedCount := 0
+			convertedBTC := 0.0
+			cancelValue := 0.0
 
 			for _, accu := range exchangeAccu {
+				// BTC remis en vente (ACCU_SELL_TRIGGER_PRICE): compté à part, suivi par son cycle
+				if accu.Converted() {
+					convertedCount++
+					convertedBTC += accu.Quantity
+					continue
+				}
 				accumulatedBTC += accu.Quantity
+				cancelValue += accu.Quantity * accu.CancelPrice
 
 				// Calcul de la valeur économisée (différence entre le prix de vente cible et le prix d'annulation)
 				savedPerBTC := accu.TargetSellPrice - accu.CancelPrice
 				savedValue += savedPerBTC * accu.Quantity
 			}
 
+			// Valeur actuelle et gain latent depuis l'annulation (nuls si le prix est inconnu)
+			currentPrice := prices.Prices[exchangeName]
+			currentValue, unrealizedGain := 0.0, 0.0
+			if currentPrice > 0 {
+				currentValue = accumulatedBTC * currentPrice
+				unrealizedGain = currentValue - cancelValue
+			}
+
 			// Ajouter les statistiques de cet exchange
 			accuStats = append(accuStats, map[string]interface{}{
-				"name":           exchangeName,
-				"enabled":        exchangeConfig.Accumulation,
-				"count":          len(exchangeAccu),
-				"accumulatedBTC": accumulatedBTC,
-				"savedValue":     savedValue,
+				"name":            exchangeName,
+				"enabled":         exchangeConfig.Accumulation,
+				"count":           len(exchangeAccu) - convertedCount,
+				"accumulatedBTC":  accumulatedBTC,
+				"savedValue":      savedValue,
+				"hasCurrentPrice": currentPrice > 0,
+				"currentPrice":    currentPrice,
+				"currentValue":    currentValue,
+				"unrealizedGain":  unrealizedGain,
+				"convertedCount":  convertedCount,
+				"convertedBTC":    convertedBTC,
 			})
 		}
 	}
@@ -1043,6 +419,10 @@ type CompleteGlobalStats struct {
 	GlobalStats
 	ProfitHistory []ProfitTimePoint `json:"profitHistory"`
 	DailyProfits  []DailyProfitData `json:"dailyProfits"`
+	Returns       ReturnMetrics     `json:"returns"`
+	// Profit total dans la devise d'affichage, chaque cycle converti au taux de sa date de
+	// complétion; le taux indiqué est le dernier publié (absent sans conversion)
+	Display *displayConversion `json:"display,omitempty"`
 }
 
 // Calcule les statistiques globales pour un ensemble de cycles
@@ -1059,49 +439,47 @@ func calculateGlobalStats(cycles []*database.Cycle) CompleteGlobalStats {
 	stats.TotalProfit = 0
 
 	var totalDuration float64
-	var profitableCycles int
+	var timedCycles, profitableCycles int
+
+	stats.CancellationsByReason = make(map[string]int)
+	for _, reason := range database.CancelReasons {
+		stats.CancellationsByReason[reason] = 0
+	}
 
 	// Calculer les statistiques
 	for _, cycle := range cycles {
 		switch cycle.Status {
-		case "buy":
+		case "buy", database.StatusCancelPending:
 			stats.BuyCycles++
 		case "sell":
 			stats.SellCycles++
+		case "cancelled":
+			stats.CancelledCycles++
+
+			// Les cycles annulés avant l'enregistrement de la cause sont comptés à part
+			reason := cycle.CancelReason
+			if reason == "" {
+				reason = "unknown"
+			}
+			stats.CancellationsByReason[reason]++
 		case "completed":
 			stats.CompletedCycles++
 
 			// Calculer les volumes et profits
-			buyVolume := cycle.BuyPrice * cycle.Quantity
-			sellVolume := cycle.SellPrice * cycle.Quantity
+			buyVolume := cycle.EffectiveBuyPrice() * cycle.Quantity
+			sellVolume := cycle.EffectiveSellPrice() * cycle.Quantity
 			profit := sellVolume - buyVolume
 
 			stats.TotalBuyVolume += buyVolume
 			stats.TotalSellVolume += sellVolume
 			stats.TotalProfit += profit
 
-			// Calculer la durée du cycle
-			var duration float64
-			if !cycle.CompletedAt.IsZero() {
-				duration = cycle.CompletedAt.Sub(cycle.CreatedAt).Hours()
-			} else {
-				// Estimer la durée si la date de complétion n'est pas définie
-				switch cycle.Exchange {
-				case "KUCOIN":
-					duration = 6
-				case "MEXC":
-					duration = 2
-				case "BINANCE":
-					duration = 4
-				case "KRAKEN":
-					duration = 4
-				default:
-					duration = 3
-				}
+			// Durée du cycle, seulement si sa date de complétion est connue
+			if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+				totalDuration += completedAt.Sub(cycle.CreatedAt).Hours()
+				timedCycles++
 			}
 
-			totalDuration += duration
-
 			// Compter les cycles profitables
 			if profit > 0 {
 				profitableCycles++
@@ -1110,8 +488,10 @@ func calculateGlobalStats(cycles []*database.Cycle) CompleteGlobalStats {
 	}
 
 	// Calculer les statistiques dérivées
+	if timedCycles > 0 {
+		stats.AverageCycleDuration = totalDuration / float64(timedCycles)
+	}
 	if stats.CompletedCycles > 0 {
-		stats.AverageCycleDuration = totalDuration / float64(stats.CompletedCycles)
 		stats.SuccessRate = float64(profitableCycles) / float64(stats.CompletedCycles) * 100
 	}
 
@@ -1153,14 +533,16 @@ func calculateExchangeStats(cycles []*database.Cycle) []ExchangeStats {
 		}
 	}
 
-	// Calculer les statistiques pour chaque cycle
+	// Calculer les statistiques pour chaque cycle (les durées moyennes ne portent que sur
+	// les cycles dont la date de complétion est connue)
+	timedCycles := make(map[string]int)
 	for _, cycle := range cycles {
 		stats := statsMap[cycle.Exchange]
 
 		stats.TotalCycles++
 
 		switch cycle.Status {
-		case "buy":
+		case "buy", database.StatusCancelPending:
 			stats.BuyCycles++
 		case "sell":
 			stats.SellCycles++
@@ -1168,34 +550,20 @@ func calculateExchangeStats(cycles []*database.Cycle) []ExchangeStats {
 			stats.CompletedCycles++
 
 			// Calculer les volumes et profits
-			buyVolume := cycle.BuyPrice * cycle.Quantity
-			sellVolume := cycle.SellPrice * cycle.Quantity
+			buyVolume := cycle.EffectiveBuyPrice() * cycle.Quantity
+			sellVolume := cycle.EffectiveSellPrice() * cycle.Quantity
 			profit := sellVolume - buyVolume
 
 			stats.TotalBuyVolume += buyVolume
 			stats.TotalSellVolume += sellVolume
 			stats.TotalProfit += profit
 
-			// Calculer la durée du cycle
-			var duration float64
-			if !cycle.CompletedAt.IsZero() {
-				duration = cycle.CompletedAt.Sub(cycle.CreatedAt).Hours()
-			} else {
-				// Estimer la durée si la date de complétion n'est pas définie
-				switch cycle.Exchange {
-				case "KUCOIN":
-					duration = 6
-				case "MEXC":
-					duration = 2
-				case "BINANCE":
-					duration = 4
-				default:
-					duration = 3
-				}
+			// Durée du cycle, seulement si sa date de complétion est connue
+			if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+				stats.AverageCycleDuration += completedAt.Sub(cycle.CreatedAt).Hours()
+				timedCycles[cycle.Exchange]++
 			}
 
-			stats.AverageCycleDuration += duration
-
 			// Compter les cycles profitables
 			if profit > 0 {
 				stats.SuccessRate++
@@ -1205,8 +573,10 @@ func calculateExchangeStats(cycles []*database.Cycle) []ExchangeStats {
 
 	// Calculer les statistiques moyennes et pourcentages
 	for _, stats := range statsMap {
+		if timedCycles[stats.Name] > 0 {
+			stats.AverageCycleDuration /= float64(timedCycles[stats.Name])
+		}
 		if stats.CompletedCycles > 0 {
-			stats.AverageCycleDuration /= float64(stats.CompletedCycles)
 			stats.SuccessRate = (stats.SuccessRate / float64(stats.CompletedCycles)) * 100
 		}
 
@@ -1242,14 +612,14 @@ func calculateProfitHistory(cycles []*database.Cycle) []ProfitTimePoint {
 	// Trier les cycles par date de complétion
 	sort.Slice(completedCycles, func(i, j int) bool {
 		// Utiliser la date de création si la date de complétion n'est pas définie
-		dateI := completedCycles[i].CreatedAt
-		if !completedCycles[i].CompletedAt.IsZero() {
-			dateI = completedCycles[i].CompletedAt
+		dateI := completedCycles[i].EffectiveCompletedAt()
+		if dateI.IsZero() {
+			dateI = completedCycles[i].CreatedAt
 		}
 
-		dateJ := completedCycles[j].CreatedAt
-		if !completedCycles[j].CompletedAt.IsZero() {
-			dateJ = completedCycles[j].CompletedAt
+		dateJ := completedCycles[j].EffectiveCompletedAt()
+		if dateJ.IsZero() {
+			dateJ = completedCycles[j].CreatedAt
 		}
 
 		return dateI.Before(dateJ)
@@ -1261,15 +631,15 @@ func calculateProfitHistory(cycles []*database.Cycle) []ProfitTimePoint {
 
 	for _, cycle := range completedCycles {
 		// Calculer le profit de ce cycle
-		profit := (cycle.SellPrice - cycle.BuyPrice) * cycle.Quantity
+		profit := (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
 
 		// Cumuler le profit pour cet exchange
 		cumulativeProfitByExchange[cycle.Exchange] += profit
 
 		// Déterminer la date à utiliser (date de complétion ou date de création)
-		date := cycle.CreatedAt
-		if !cycle.CompletedAt.IsZero() {
-			date = cycle.CompletedAt
+		date := cycle.EffectiveCompletedAt()
+		if date.IsZero() {
+			date = cycle.CreatedAt
 		}
 
 		// Ajouter un point de données pour cet exchange
@@ -1309,12 +679,12 @@ func calculateDailyProfits(cycles []*database.Cycle) []DailyProfitData {
 
 	for _, cycle := range completedCycles {
 		// Calculer le profit de ce cycle
-		profit := (cycle.SellPrice - cycle.BuyPrice) * cycle.Quantity
+		profit := (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
 
 		// Déterminer la date à utiliser (date de complétion ou date de création)
-		date := cycle.CreatedAt
-		if !cycle.CompletedAt.IsZero() {
-			date = cycle.CompletedAt
+		date := cycle.EffectiveCompletedAt()
+		if date.IsZero() {
+			date = cycle.CreatedAt
 		}
 
 		// Formater la date au format YYYY-MM-DD
@@ -1341,6 +711,125 @@ func calculateDailyProfits(cycles []*database.Cycle) []DailyProfitData {
 	return result
 }
 
+// Dates qui situent un cycle dans une période (paramètre dateField)
+const (
+	// Date de complétion des cycles complétés, date de création des autres (par défaut)
+	dateFieldAuto = ""
+	// Date de création (achat) de tous les cycles
+	dateFieldCreated = "created"
+	// Date de complétion (vente): les cycles non complétés sont exclus
+	dateFieldCompleted = "completed"
+)
+
+// parseDateField lit le paramètre dateField d'une requête de statistiques
+func parseDateField(r *http.Request) (string, error) {
+	switch field := r.URL.Query().Get("dateField"); field {
+	case dateFieldAuto, dateFieldCreated, dateFieldCompleted:
+		return field, nil
+	default:
+		return "", fmt.Errorf("dateField invalide: %q (attendu: created ou completed)", field)
+	}
+}
+
+// cyclePeriodDate retourne la date qui situe un cycle dans une période, zéro s'il n'en a pas.
+// Un cycle complété sans date de complétion connue est daté de sa création, comme dans le CLI.
+func cyclePeriodDate(cycle *database.Cycle, dateField string) time.Time {
+	if dateField == dateFieldCreated {
+		return cycle.CreatedAt
+	}
+	if cycle.Status != "completed" {
+		if dateField == dateFieldCompleted {
+			return time.Time{}
+		}
+		return cycle.CreatedAt
+	}
+	if completedAt := cycle.EffectiveCompletedAt(); !completedAt.IsZero() {
+		return completedAt
+	}
+	return cycle.CreatedAt
+}
+
+// cycleInPeriod indique si la date du cycle est comprise dans la période (bornes incluses,
+// une borne nulle n'est pas appliquée)
+func cycleInPeriod(cycle *database.Cycle, start, end *time.Time, dateField string) bool {
+	date := cyclePeriodDate(cycle, dateField)
+	if date.IsZero() {
+		return false
+	}
+	return (start == nil || !date.Before(*start)) && (end == nil || !date.After(*end))
+}
+
+// filterCyclesByPeriod retourne les cycles dont la date est comprise dans la période
+func filterCyclesByPeriod(cycles []*database.Cycle, start, end *time.Time, dateField string) []*database.Cycle {
+	if start == nil && end == nil {
+		return cycles
+	}
+	var filtered []*database.Cycle
+	for _, cycle := range cycles {
+		if cycleInPeriod(cycle, start, end, dateField) {
+			filtered = append(filtered, cycle)
+		}
+	}
+	return filtered
+}
+
+// maxStatsRangeDays limite la plage ?start=&end= des statistiques, en jours
+const maxStatsRangeDays = 3660
+
+// statsDateRange retourne la plage de dates d'une requête de statistiques: ?start=&end= (dates
+// ISO AAAA-MM-JJ) si l'une d'elles est fournie, sinon la période prédéfinie ?period=
+func statsDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	query := r.URL.Query()
+	startStr, endStr := query.Get("start"), query.Get("end")
+	if startStr == "" && endStr == "" {
+		start, end := calculateDateRangeFromPeriod(query.Get("period"))
+		return start, end, nil
+	}
+	return parseStatsDateRange(startStr, endStr, time.Now())
+}
+
+// parseStatsDateRange lit une plage de dates ISO, bornes incluses: le jour de fin compte en
+// entier. Sans date de fin, la plage s'arrête à maintenant; sans date de début, elle remonte
+// de maxStatsRangeDays jours. La plage ne peut pas dépasser maxStatsRangeDays jours.
+func parseStatsDateRange(startStr, endStr string, now time.Time) (*time.Time, *time.Time, error) {
+	parseDay := func(name, value string) (time.Time, error) {
+		day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date de %s invalide: %q (attendu: AAAA-MM-JJ)", name, value)
+		}
+		return day, nil
+	}
+
+	endDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := now
+	if endStr != "" {
+		day, err := parseDay("fin", endStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		endDay = day
+		end = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	start := endDay.AddDate(0, 0, 1-maxStatsRangeDays)
+	if startStr != "" {
+		day, err := parseDay("début", startStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = day
+	}
+
+	if start.After(end) {
+		return nil, nil, fmt.Errorf("la date de début %s est postérieure à la date de fin %s",
+			start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	if days := int(math.Round(endDay.Sub(start).Hours()/24)) + 1; days > maxStatsRangeDays {
+		return nil, nil, fmt.Errorf("plage de %d jours trop longue (maximum: %d jours)", days, maxStatsRangeDays)
+	}
+	return &start, &end, nil
+}
+
 // Calcule la plage de dates en fonction d'une période spécifiée
 func calculateDateRangeFromPeriod(period string) (*time.Time, *time.Time) {
 	now := time.Now()
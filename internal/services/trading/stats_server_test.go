@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/database"
+)
+
+// Un cycle acheté il y a huit mois et vendu la semaine dernière compte dans les 30 derniers jours:
+// la période se lit sur la date de complétion des cycles complétés, comme dans le CLI
+func TestStatsPeriodUsesCompletionDate(t *testing.T) {
+	now := time.Now()
+	longHeld := &database.Cycle{
+		IdInt: 1, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+		BuyPrice: 30000, SellPrice: 33000, TotalFees: 0.6,
+		CreatedAt: now.AddDate(0, -8, 0), CompletedAt: now.AddDate(0, 0, -7),
+	}
+	open := &database.Cycle{
+		IdInt: 2, Exchange: "BINANCE", Status: "sell", Quantity: 0.01,
+		BuyPrice: 60000, SellPrice: 61000,
+		CreatedAt: now.AddDate(0, 0, -7),
+	}
+	old := &database.Cycle{
+		IdInt: 3, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+		BuyPrice: 50000, SellPrice: 51000, TotalFees: 1,
+		CreatedAt: now.AddDate(0, 0, -70), CompletedAt: now.AddDate(0, 0, -60),
+	}
+	cycles := []*database.Cycle{longHeld, open, old}
+	start, end := calculateDateRangeFromPeriod("30j")
+
+	ids := func(cycles []*database.Cycle) []int32 {
+		var result []int32
+		for _, cycle := range cycles {
+			result = append(result, cycle.IdInt)
+		}
+		return result
+	}
+	for _, tc := range []struct {
+		dateField string
+		want      []int32
+	}{
+		{dateFieldAuto, []int32{1, 2}},
+		{dateFieldCreated, []int32{2}},
+		{dateFieldCompleted, []int32{1}},
+	} {
+		got := ids(filterCyclesByPeriod(cycles, start, end, tc.dateField))
+		if len(got) != len(tc.want) {
+			t.Fatalf("dateField=%q: cycles %v, attendu %v", tc.dateField, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("dateField=%q: cycles %v, attendu %v", tc.dateField, got, tc.want)
+			}
+		}
+	}
+
+	// Le profit par période du CLI retient les mêmes cycles que le serveur
+	profit := calculateProfitByPeriod(cycles, "BINANCE", *start, *end)
+	if want := 33000*0.01 - 30000*0.01 - 0.6; profit < want-1e-9 || profit > want+1e-9 {
+		t.Fatalf("profit sur 30 jours: %.4f, attendu %.4f", profit, want)
+	}
+}
+
+func TestStatsAPIRejectsUnknownDateField(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleStatsAPI(rec, httptest.NewRequest(http.MethodGet, "/api/stats?period=30j&dateField=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("statut %d, attendu %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// Trois cycles d'un an chacun: un gain de 100, une perte de 50 puis un gain de 20 sur 1000 USDC
+func TestReturnMetricsAndHodlBenchmark(t *testing.T) {
+	start := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(3, 0, 0)
+	cycle := func(id int32, from time.Time, buy, sell float64) *database.Cycle {
+		return &database.Cycle{
+			IdInt: id, Exchange: "BINANCE", Status: "completed", Quantity: 1000 / buy,
+			BuyPrice: buy, SellPrice: sell, PurchaseAmountUSDC: 1000,
+			CreatedAt: from, CompletedAt: from.Add(365 * 24 * time.Hour),
+		}
+	}
+	cycles := []*database.Cycle{
+		cycle(1, start, 20000, 22000),
+		cycle(2, start.Add(365*24*time.Hour), 25000, 23750),
+		cycle(3, start.Add(2*365*24*time.Hour), 40000, 40800),
+	}
+
+	metrics := calculateReturnMetrics(cycles, 60000, now)
+	approx := func(name string, got, want float64) {
+		t.Helper()
+		if got < want-1e-6 || got > want+1e-6 {
+			t.Fatalf("%s: %.6f, attendu %.6f", name, got, want)
+		}
+	}
+	// 70 USDC de profit pour 3000 USDC·an de capital engagé
+	approx("rendement annualisé", metrics.AnnualizedReturn, 70.0/3000*100)
+	approx("drawdown maximal", metrics.MaxDrawdown, 50)
+	if metrics.LongestWinStreak != 1 || metrics.LongestLossStreak != 1 || metrics.CurrentStreak != 1 {
+		t.Fatalf("séries: %+v", metrics)
+	}
+
+	// Les cycles se succèdent: 1000 USDC engagés au plus, soit 0.05 BTC au prix du premier achat
+	benchmark := metrics.Benchmark
+	if benchmark == nil {
+		t.Fatal("comparaison HODL absente")
+	}
+	approx("capital", benchmark.Capital, 1000)
+	approx("profit HODL", benchmark.Profit, 0.05*60000-1000)
+	if len(benchmark.History) != 5 || benchmark.History[1].Profit != 0.05*22000-1000 {
+		t.Fatalf("historique HODL: %+v", benchmark.History)
+	}
+
+	if calculateReturnMetrics(cycles, 0, now).Benchmark != nil {
+		t.Fatal("comparaison HODL sans prix actuel")
+	}
+}
+
+func TestStatsDateRange(t *testing.T) {
+	now := time.Date(2025, time.March, 16, 15, 30, 0, 0, time.UTC)
+
+	start, end, err := parseStatsDateRange("2025-03-01", "2025-03-10", now)
+	if err != nil {
+		t.Fatalf("plage valide refusée: %v", err)
+	}
+	if !start.Equal(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)) ||
+		!end.Equal(time.Date(2025, time.March, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)) {
+		t.Fatalf("plage %v - %v, attendu du 1er au 10 mars inclus", start, end)
+	}
+
+	// Sans date de fin, la plage s'arrête à maintenant
+	if _, end, err := parseStatsDateRange("2025-03-01", "", now); err != nil || !end.Equal(now) {
+		t.Fatalf("fin %v (%v), attendu %v", end, err, now)
+	}
+
+	for _, tc := range []struct{ start, end string }{
+		{"2025-03-10", "2025-03-01"}, // début après la fin
+		{"2025-03-17", ""},           // début après maintenant
+		{"2010-01-01", "2025-03-01"}, // plage au-delà de maxStatsRangeDays
+		{"01/03/2025", "2025-03-10"}, // format non ISO
+	} {
+		if _, _, err := parseStatsDateRange(tc.start, tc.end, now); err == nil {
+			t.Errorf("plage %q - %q acceptée", tc.start, tc.end)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handleExchangesComparisonAPI(rec, httptest.NewRequest(http.MethodGet, "/api/exchanges-comparison?start=2025-03-10&end=2025-03-01", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("statut %d, attendu %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestStatsExportCSV(t *testing.T) {
+	day := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	cycles := []*database.Cycle{
+		{IdInt: 1, Exchange: "BINANCE", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 61000,
+			CreatedAt: day.AddDate(0, 0, -2), CompletedAt: day},
+		{IdInt: 2, Exchange: "KRAKEN", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 60500,
+			CreatedAt: day.AddDate(0, 0, -1), CompletedAt: day.Add(time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	if err := writeStatsCSV(&buf, statsDatasetDailyProfits, cycles); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if want := "date,profit_usdc\n2025-03-10,15.00000000\n"; buf.String() != want {
+		t.Fatalf("export des profits journaliers:\n%s\nattendu:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeStatsCSV(&buf, statsDatasetProfitHistory, cycles); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[2], "KRAKEN,5.00000000") {
+		t.Fatalf("export de l'historique des profits:\n%s", buf.String())
+	}
+
+	rec := httptest.NewRecorder()
+	handleStatsExportCSV(rec, httptest.NewRequest(http.MethodGet, "/api/stats/export.csv?dataset=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("dataset inconnu: statut %d, attendu %d", rec.Code, http.StatusBadRequest)
+	}
+}
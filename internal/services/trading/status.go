@@ -0,0 +1,325 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/scheduler"
+
+	"github.com/fatih/color"
+)
+
+// StatusReport est le document imprimé par --status --json pour les scripts de supervision.
+// Ses noms de champs sont stables: un champ peut être ajouté, jamais renommé ni retiré.
+//
+//	generatedAt      date du rapport
+//	exchanges        état de chaque exchange activé disposant de clés API (ExchangeStatus)
+//	cycles           nombre de cycles par statut (buy, sell, cancel_pending, completed, cancelled), tous exchanges
+//	todayProfitUSDC  profit réalisé net des frais des cycles complétés pendant la journée UTC
+//	issues           problèmes en attente d'une intervention ou d'une mise à jour (StatusIssue)
+//	scheduler        état du planificateur en mode daemon (SchedulerState)
+type StatusReport struct {
+	GeneratedAt time.Time        `json:"generatedAt"`
+	Exchanges   []ExchangeStatus `json:"exchanges"`
+	Cycles      map[string]int   `json:"cycles"`
+	TodayProfit float64          `json:"todayProfitUSDC"`
+	Issues      []StatusIssue    `json:"issues"`
+	Scheduler   SchedulerState   `json:"scheduler"`
+}
+
+// ExchangeStatus est l'état d'un exchange dans StatusReport. Un exchange en maintenance n'est pas
+// interrogé: connected vaut false et les soldes 0.
+type ExchangeStatus struct {
+	Exchange           string         `json:"exchange"`
+	Connected          bool           `json:"connected"`
+	Error              string         `json:"error,omitempty"`
+	Maintenance        bool           `json:"maintenance"`
+	CircuitBreakerOpen bool           `json:"circuitBreakerOpen"` // disjoncteur ouvert par la dernière mise à jour
+	BTCPrice           float64        `json:"btcPrice"`
+	BTCFree            float64        `json:"btcFree"`
+	BTCTotal           float64        `json:"btcTotal"`
+	USDCFree           float64        `json:"usdcFree"`
+	USDCTotal          float64        `json:"usdcTotal"`
+	Cycles             map[string]int `json:"cycles"`
+	TodayProfit        float64        `json:"todayProfitUSDC"`
+}
+
+// Types de StatusIssue.Kind
+const (
+	IssueOrphanOrders   = "orphan_orders"   // ordres ouverts qu'aucun cycle ne suit (--orphans)
+	IssuePendingSell    = "pending_sell"    // achat exécuté dont la vente n'a pas pu être placée
+	IssuePendingCancel  = "pending_cancel"  // ordre d'achat dont l'annulation n'a pas pu être confirmée
+	IssueMaintenance    = "maintenance"     // exchange en maintenance
+	IssueCircuitBreaker = "circuit_breaker" // disjoncteur ouvert par la dernière mise à jour
+	IssueLossLimit      = "loss_limit"      // limite de pertes quotidienne atteinte
+	IssueTradingHalt    = "trading_halt"    // paire BTC/USDC suspendue par l'exchange
+)
+
+// StatusIssue est un problème signalé par StatusReport: kind (Issue*), exchange et cycleId quand
+// il porte sur un exchange ou un cycle, et un message lisible
+type StatusIssue struct {
+	Kind     string `json:"kind"`
+	Exchange string `json:"exchange,omitempty"`
+	CycleId  int32  `json:"cycleId,omitempty"`
+	Count    int    `json:"count,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SchedulerState est l'état du planificateur dans StatusReport, tel que publié par le daemon
+type SchedulerState struct {
+	Running   bool                   `json:"running"`
+	PID       int                    `json:"pid,omitempty"`
+	StartedAt time.Time              `json:"startedAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+	Tasks     []scheduler.TaskStatus `json:"tasks"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Status affiche l'état du bot sans rien modifier: exchanges, cycles, profit du jour, problèmes en
+// attente et planificateur. Avec --json, le rapport est imprimé en un seul document JSON (--status --json).
+func Status() {
+	jsonOutput := false
+	for _, arg := range GetAllArgs() {
+		if arg == "--json" {
+			jsonOutput = true
+		}
+	}
+
+	report, err := buildStatusReport(time.Now())
+	if err != nil {
+		color.Red("Erreur lors de la lecture des cycles: %v", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			color.Red("Erreur lors de l'encodage JSON: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printStatusReport(report)
+}
+
+// buildStatusReport rassemble l'état du bot à la date now. Seuls les fichiers d'état publiés par
+// les mises à jour sont lus: les maintenances et disjoncteurs ne sont ni sondés ni modifiés.
+func buildStatusReport(now time.Time) (StatusReport, error) {
+	report := StatusReport{
+		GeneratedAt: now,
+		Cycles:      make(map[string]int),
+		Issues:      []StatusIssue{},
+	}
+
+	repo := database.GetRepository()
+	cycles, err := repo.FindAll()
+	if err != nil {
+		return report, err
+	}
+
+	exchanges := orphanExchanges("")
+	_, maintenance, err := activeMaintenance()
+	if err != nil {
+		maintenance = map[string]maintenanceEntry{}
+	}
+	breakers, err := loadCircuitBreakers()
+	if err != nil {
+		breakers = &breakerSnapshot{Exchanges: map[string]common.BreakerState{}}
+	}
+
+	// Interroger les exchanges en parallèle, sauf ceux en maintenance
+	report.Exchanges = make([]ExchangeStatus, len(exchanges))
+	var wg sync.WaitGroup
+	for i, name := range exchanges {
+		report.Exchanges[i] = ExchangeStatus{Exchange: name, Cycles: make(map[string]int)}
+		if _, inMaintenance := maintenance[name]; inMaintenance {
+			report.Exchanges[i].Maintenance = true
+			continue
+		}
+		wg.Add(1)
+		go func(status *ExchangeStatus) {
+			defer wg.Done()
+			balance := fetchExchangeBalance(status.Exchange)
+			status.Connected = balance.Error == ""
+			status.Error = balance.Error
+			status.BTCPrice = balance.BTCPrice
+			status.BTCFree, status.BTCTotal = balance.BTCFree, balance.BTCTotal
+			status.USDCFree, status.USDCTotal = balance.USDCFree, balance.USDCTotal
+		}(&report.Exchanges[i])
+	}
+	wg.Wait()
+
+	byExchange := make(map[string]*ExchangeStatus, len(report.Exchanges))
+	for i := range report.Exchanges {
+		byExchange[report.Exchanges[i].Exchange] = &report.Exchanges[i]
+	}
+
+	// Cycles par statut, profit réalisé de la journée UTC (calculé comme la limite de pertes)
+	today := utcDay(now)
+	for _, cycle := range cycles {
+		report.Cycles[cycle.Status]++
+		status := byExchange[cycle.Exchange]
+		if status != nil {
+			status.Cycles[cycle.Status]++
+		}
+		if completedAt := cycle.EffectiveCompletedAt(); cycle.Status == "completed" && !completedAt.IsZero() && utcDay(completedAt) == today {
+			profit := cycle.CalculateProfit() - cycle.TotalFees
+			report.TodayProfit += profit
+			if status != nil {
+				status.TodayProfit += profit
+			}
+		}
+		if sellPending(cycle) {
+			report.Issues = append(report.Issues, StatusIssue{
+				Kind: IssuePendingSell, Exchange: cycle.Exchange, CycleId: cycle.IdInt,
+				Message: fmt.Sprintf("Cycle %d: vente non placée après %d échec(s): %s", cycle.IdInt, cycle.SellRetryCount, cycle.SellRetryError),
+			})
+		}
+		if cycle.Status == database.StatusCancelPending {
+			report.Issues = append(report.Issues, StatusIssue{
+				Kind: IssuePendingCancel, Exchange: cycle.Exchange, CycleId: cycle.IdInt,
+				Message: fmt.Sprintf("Cycle %d: annulation de l'achat non confirmée après %d tentative(s): %s", cycle.IdInt, cycle.CancelRetryCount, cycle.CancelRetryError),
+			})
+		}
+	}
+
+	report.Issues = append(report.Issues, exchangeIssues(report.Exchanges, cycles, maintenance, breakers)...)
+	if lossLimit, err := currentLossLimits(); err == nil && !lossLimit.Overridden {
+		for _, scope := range lossLimit.breachedScopes() {
+			loss, limit := lossLimit.scopeLoss(scope)
+			report.Issues = append(report.Issues, StatusIssue{
+				Kind: IssueLossLimit, Exchange: scope,
+				Message: fmt.Sprintf("%s: pertes du jour %.2f / %.2f USDC, nouveaux ordres suspendus", scope, loss, limit),
+			})
+		}
+	}
+
+	if halts, err := loadTradingHalts(); err == nil {
+		for _, status := range report.Exchanges {
+			if entry, halted := halts[status.Exchange]; halted {
+				report.Issues = append(report.Issues, StatusIssue{
+					Kind: IssueTradingHalt, Exchange: status.Exchange,
+					Message: fmt.Sprintf("%s: paire BTC/USDC suspendue (état %s) depuis %s, cycles laissés en l'état", status.Exchange, entry.Status, entry.Since.Format("2006-01-02 15:04:05")),
+				})
+			}
+		}
+	}
+
+	daemon, err := scheduler.ReadDaemonStatus()
+	report.Scheduler = SchedulerState{
+		Running: daemon.Running, StartedAt: daemon.StartedAt, UpdatedAt: daemon.UpdatedAt, Tasks: daemon.Tasks,
+	}
+	if daemon.Running {
+		report.Scheduler.PID = daemon.PID
+	}
+	if err != nil {
+		report.Scheduler.Error = err.Error()
+	}
+	if report.Scheduler.Tasks == nil {
+		report.Scheduler.Tasks = []scheduler.TaskStatus{}
+	}
+	return report, nil
+}
+
+// exchangeIssues retourne les maintenances, disjoncteurs ouverts et ordres orphelins des exchanges
+func exchangeIssues(exchanges []ExchangeStatus, cycles []*database.Cycle, maintenance map[string]maintenanceEntry,
+	breakers *breakerSnapshot) []StatusIssue {
+	var issues []StatusIssue
+	ignoredRepo := database.GetIgnoredOrderRepository()
+	for i := range exchanges {
+		status := &exchanges[i]
+		if entry, inMaintenance := maintenance[status.Exchange]; inMaintenance {
+			issues = append(issues, StatusIssue{
+				Kind: IssueMaintenance, Exchange: status.Exchange,
+				Message: fmt.Sprintf("%s en maintenance depuis %s: %s", status.Exchange, entry.Since.Format("2006-01-02 15:04:05"), entry.Reason),
+			})
+			continue
+		}
+		if breaker := breakers.Exchanges[status.Exchange]; breaker.Open {
+			status.CircuitBreakerOpen = true
+			issues = append(issues, StatusIssue{
+				Kind: IssueCircuitBreaker, Exchange: status.Exchange,
+				Message: fmt.Sprintf("%s: disjoncteur ouvert: %s", status.Exchange, breaker.LastError),
+			})
+		}
+		if !status.Connected {
+			continue
+		}
+
+		ignored, err := ignoredRepo.FindByExchange(status.Exchange)
+		if err != nil {
+			continue
+		}
+		orphans, err := findOrphanOrders(GetClientByExchange(status.Exchange), status.Exchange, openCycles(cycles), ignored)
+		if err != nil || len(orphans) == 0 {
+			continue
+		}
+		issues = append(issues, StatusIssue{
+			Kind: IssueOrphanOrders, Exchange: status.Exchange, Count: len(orphans),
+			Message: fmt.Sprintf("%s: %d ordre(s) ouvert(s) inconnu(s) du bot (--orphans)", status.Exchange, len(orphans)),
+		})
+	}
+	return issues
+}
+
+// openCycles retourne les cycles en achat, en vente ou dont l'annulation est en attente
+func openCycles(cycles []*database.Cycle) []*database.Cycle {
+	var open []*database.Cycle
+	for _, cycle := range cycles {
+		if cycle.Status == "buy" || cycle.Status == "sell" || cycle.Status == database.StatusCancelPending {
+			open = append(open, cycle)
+		}
+	}
+	return open
+}
+
+// printStatusReport affiche le rapport d'état de façon compacte
+func printStatusReport(report StatusReport) {
+	color.Cyan("=== État du bot (%s) ===", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	for _, status := range report.Exchanges {
+		switch {
+		case status.Maintenance:
+			color.Yellow("%-8s en maintenance", status.Exchange)
+		case !status.Connected:
+			color.Red("%-8s injoignable: %s", status.Exchange, status.Error)
+		default:
+			color.Green("%-8s BTC %.2f | %.8f BTC, %.2f USDC | achat %d, vente %d | profit du jour %.2f USDC",
+				status.Exchange, status.BTCPrice, status.BTCTotal, status.USDCTotal,
+				status.Cycles["buy"], status.Cycles["sell"], status.TodayProfit)
+		}
+	}
+
+	statuses := make([]string, 0, len(report.Cycles))
+	for status := range report.Cycles {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	fmt.Print("Cycles:")
+	for _, status := range statuses {
+		fmt.Printf(" %s %d", status, report.Cycles[status])
+	}
+	fmt.Printf(" | profit réalisé du jour (UTC): %.2f USDC\n", report.TodayProfit)
+
+	if report.Scheduler.Running {
+		color.Green("Planificateur: actif (PID %d, %d tâche(s))", report.Scheduler.PID, len(report.Scheduler.Tasks))
+	} else {
+		color.Yellow("Planificateur: arrêté")
+	}
+
+	if len(report.Issues) == 0 {
+		color.Green("Aucun problème en attente")
+		return
+	}
+	color.Yellow("%d problème(s) en attente:", len(report.Issues))
+	for _, issue := range report.Issues {
+		color.Yellow("  [%s] %s", issue.Kind, issue.Message)
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestStatusReport(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, APIKey: "key", SecretKey: "secret"}, 60100)
+	mock.SetBalance("BTC", 0.003)
+	mock.SetBalance("USDC", 500)
+	repo := database.GetRepository()
+	now := time.Now()
+
+	saveBuyCycle(t, mock, 60000, 0.0015)
+	for _, cycle := range []*database.Cycle{
+		{Exchange: "BINANCE", Status: "sell", Quantity: 0.0015, BuyPrice: 59000, SellRetryCount: 2, SellRetryError: "Oversold"},
+		{Exchange: "BINANCE", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 61000, TotalFees: 1,
+			CreatedAt: now.Add(-time.Hour), CompletedAt: now},
+	} {
+		if _, err := repo.Save(cycle); err != nil {
+			t.Fatal(err)
+		}
+		id := cycle.IdInt
+		t.Cleanup(func() { repo.DeleteByIdInt(id) })
+	}
+	// Ordre placé à la main, qu'aucun cycle ne suit
+	mock.AddOrder("555", "SELL", 70000, 0.001)
+
+	report, err := buildStatusReport(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Exchanges) != 1 || !report.Exchanges[0].Connected || report.Exchanges[0].BTCPrice != 60100 ||
+		report.Exchanges[0].USDCTotal != 500 {
+		t.Fatalf("exchanges: %+v", report.Exchanges)
+	}
+	if report.Cycles["buy"] != 1 || report.Cycles["sell"] != 1 || report.Cycles["completed"] != 1 ||
+		report.Exchanges[0].Cycles["sell"] != 1 {
+		t.Errorf("cycles par statut: %v", report.Cycles)
+	}
+	if report.TodayProfit != 9 || report.Exchanges[0].TodayProfit != 9 {
+		t.Errorf("profit du jour %.2f, attendu 9", report.TodayProfit)
+	}
+
+	kinds := make(map[string]int)
+	for _, issue := range report.Issues {
+		kinds[issue.Kind]++
+	}
+	if kinds[IssuePendingSell] != 1 || kinds[IssueOrphanOrders] != 1 || len(report.Issues) != 2 {
+		t.Errorf("problèmes: %+v", report.Issues)
+	}
+
+	// Rien n'est modifié: aucun ordre créé ni annulé
+	if calls := append(mock.CallsTo("CreateOrder"), mock.CallsTo("CancelOrder")...); len(calls) != 0 {
+		t.Errorf("appels modifiant l'exchange: %+v", calls)
+	}
+
+	// Les noms de champs lus par les scripts restent stables
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"generatedAt", "exchanges", "cycles", "todayProfitUSDC", "issues", "scheduler"} {
+		if _, ok := document[field]; !ok {
+			t.Errorf("champ %q absent du document JSON", field)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// tax2086Line est une ligne de cession du formulaire 2086. Les montants sont en USDC,
+// les numéros correspondent aux lignes du formulaire.
+type tax2086Line struct {
+	Date             time.Time // 211: date de la cession
+	Exchange         string
+	CycleIdInt       int32
+	PortfolioValue   float64 // 212: valeur globale du portefeuille au moment de la cession
+	DisposalPrice    float64 // 213: prix de cession
+	DisposalFees     float64 // 214: frais de cession
+	NetDisposalPrice float64 // 218: prix de cession net des frais
+	TotalAcquisition float64 // 220: prix total d'acquisition du portefeuille
+	CapitalFractions float64 // 221: fractions de capital initial des cessions antérieures
+	NetAcquisition   float64 // 223: prix total d'acquisition net
+	Gain             float64 // 224: plus-value ou moins-value
+	FeesEstimated    bool    // frais estimés et non communiqués par l'exchange
+}
+
+// taxEvent est une acquisition ou une cession de BTC du portefeuille
+type taxEvent struct {
+	Date          time.Time
+	Disposal      bool
+	Quantity      float64
+	Price         float64 // prix unitaire, utilisé pour valoriser le portefeuille lors d'une cession
+	Amount        float64 // coût d'acquisition frais inclus, ou prix de cession brut
+	Fees          float64 // frais de cession
+	Exchange      string
+	CycleIdInt    int32
+	FeesEstimated bool
+}
+
+// cycleTaxFees répartit les frais d'un cycle entre l'achat et la vente. Sans frais enregistrés,
+// ils sont estimés au taux standard de l'exchange et le cycle est signalé.
+func cycleTaxFees(cycle *database.Cycle) (buyFees, sellFees float64, estimated bool) {
+	rate := getFeeRateForExchange(cycle.Exchange)
+	if cycle.TotalFees == 0 && rate > 0 {
+		buyFees = cycle.EffectiveBuyPrice() * cycle.Quantity * rate
+		if cycle.Status == "completed" {
+			sellFees = cycle.EffectiveSellPrice() * cycle.Quantity * rate
+		}
+		return buyFees, sellFees, true
+	}
+
+	sellFees = cycle.SellFees
+	buyFees = math.Max(0, cycle.TotalFees-cycle.SellFees)
+	return buyFees, sellFees, cycle.FeesEstimated
+}
+
+// taxEvents reconstitue les mouvements du portefeuille à partir des cycles et des accumulations
+func taxEvents(cycles []*database.Cycle, accumulations []*database.Accumulation) []taxEvent {
+	events := make([]taxEvent, 0, len(cycles)*2+len(accumulations))
+
+	for _, cycle := range cycles {
+		// Seuls les cycles dont l'achat est exécuté ont fait entrer du BTC dans le portefeuille
+		if cycle.Status != "sell" && cycle.Status != "completed" {
+			continue
+		}
+
+		buyFees, sellFees, estimated := cycleTaxFees(cycle)
+		events = append(events, taxEvent{
+			Date:       cycle.CreatedAt,
+			Quantity:   cycle.Quantity,
+			Price:      cycle.EffectiveBuyPrice(),
+			Amount:     cycle.EffectiveBuyPrice()*cycle.Quantity + buyFees,
+			Exchange:   cycle.Exchange,
+			CycleIdInt: cycle.IdInt,
+		})
+
+		if cycle.Status != "completed" {
+			continue
+		}
+
+		disposalDate := cycle.EffectiveCompletedAt()
+		if disposalDate.IsZero() {
+			disposalDate = cycle.CreatedAt
+		}
+		events = append(events, taxEvent{
+			Date:          disposalDate,
+			Disposal:      true,
+			Quantity:      cycle.Quantity,
+			Price:         cycle.EffectiveSellPrice(),
+			Amount:        cycle.EffectiveSellPrice() * cycle.Quantity,
+			Fees:          sellFees,
+			Exchange:      cycle.Exchange,
+			CycleIdInt:    cycle.IdInt,
+			FeesEstimated: estimated,
+		})
+	}
+
+	// Le BTC accumulé reste dans le portefeuille. Le cycle d'origine étant supprimé,
+	// l'acquisition est datée de l'accumulation et ses frais ne sont plus connus.
+	// Une accumulation remise en vente est reprise par son cycle, à la même date.
+	for _, accumulation := range accumulations {
+		if accumulation.Converted() {
+			continue
+		}
+		events = append(events, taxEvent{
+			Date:       accumulation.CreatedAt,
+			Quantity:   accumulation.Quantity,
+			Price:      accumulation.OriginalBuyPrice,
+			Amount:     accumulation.OriginalBuyPrice * accumulation.Quantity,
+			Exchange:   accumulation.Exchange,
+			CycleIdInt: accumulation.CycleIdInt,
+		})
+	}
+
+	// Ordre chronologique, les acquisitions avant les cessions à date égale
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.Before(events[j].Date)
+		}
+		return !events[i].Disposal && events[j].Disposal
+	})
+
+	return events
+}
+
+// buildTax2086 calcule les lignes de cession d'une année selon la méthode du prix moyen
+// pondéré d'acquisition du portefeuille. Tout l'historique est parcouru car le prix total
+// d'acquisition et les fractions de capital se cumulent d'une année sur l'autre.
+func buildTax2086(cycles []*database.Cycle, accumulations []*database.Accumulation, year int) []tax2086Line {
+	var (
+		holdings         float64 // BTC détenus
+		totalAcquisition float64 // 220
+		capitalFractions float64 // 221
+		lines            []tax2086Line
+	)
+
+	for _, event := range taxEvents(cycles, accumulations) {
+		if !event.Disposal {
+			holdings += event.Quantity
+			totalAcquisition += event.Amount
+			continue
+		}
+
+		// Le portefeuille est valorisé au prix de la cession, BTC cédé compris
+		portfolioValue := math.Max(holdings*event.Price, event.Amount)
+		netAcquisition := totalAcquisition - capitalFractions
+		fraction := 0.0
+		if portfolioValue > 0 {
+			fraction = netAcquisition * event.Amount / portfolioValue
+		}
+		netDisposal := event.Amount - event.Fees
+
+		if event.Date.Year() == year {
+			lines = append(lines, tax2086Line{
+				Date:             event.Date,
+				Exchange:         event.Exchange,
+				CycleIdInt:       event.CycleIdInt,
+				PortfolioValue:   portfolioValue,
+				DisposalPrice:    event.Amount,
+				DisposalFees:     event.Fees,
+				NetDisposalPrice: netDisposal,
+				TotalAcquisition: totalAcquisition,
+				CapitalFractions: capitalFractions,
+				NetAcquisition:   netAcquisition,
+				Gain:             netDisposal - fraction,
+				FeesEstimated:    event.FeesEstimated,
+			})
+		}
+
+		holdings = math.Max(0, holdings-event.Quantity)
+		capitalFractions += fraction
+	}
+
+	return lines
+}
+
+// writeTax2086CSV écrit les lignes de cession au format CSV
+func writeTax2086CSV(w io.Writer, lines []tax2086Line) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"211 date_cession", "exchange", "cycle",
+		"212 valeur_portefeuille", "213 prix_cession", "214 frais_cession", "218 prix_cession_net",
+		"220 prix_total_acquisition", "221 fractions_capital_initial", "223 prix_total_acquisition_net",
+		"224 plus_value", "frais_estimes",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+
+	for _, line := range lines {
+		estimated := "non"
+		if line.FeesEstimated {
+			estimated = "oui"
+		}
+		record := []string{
+			line.Date.Format("2006-01-02 15:04:05"),
+			line.Exchange,
+			strconv.Itoa(int(line.CycleIdInt)),
+			amount(line.PortfolioValue),
+			amount(line.DisposalPrice),
+			amount(line.DisposalFees),
+			amount(line.NetDisposalPrice),
+			amount(line.TotalAcquisition),
+			amount(line.CapitalFractions),
+			amount(line.NetAcquisition),
+			amount(line.Gain),
+			estimated,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// loadTaxData charge tous les cycles (archivés compris) et les accumulations
+func loadTaxData() ([]*database.Cycle, []*database.Accumulation, error) {
+	cycles, err := database.GetRepository().FindAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erreur lors de la récupération des cycles: %w", err)
+	}
+
+	archived, err := database.GetArchiveRepository().FindAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erreur lors de la récupération des cycles archivés: %w", err)
+	}
+	cycles = append(cycles, archived...)
+
+	accumulations, err := database.GetAccumulationRepository().FindAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erreur lors de la récupération des accumulations: %w", err)
+	}
+
+	return cycles, accumulations, nil
+}
+
+// TaxReport génère les lignes de cession du formulaire 2086 d'une année:
+// --tax-report --year=2024 [--output=fichier.csv]
+func TaxReport() {
+	year := time.Now().Year() - 1
+	output := ""
+
+	for _, arg := range GetAllArgs() {
+		switch {
+		case strings.HasPrefix(arg, "--year="):
+			value := strings.TrimPrefix(arg, "--year=")
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 2000 {
+				color.Red("Année invalide: %s. Utilisez --year=2024", value)
+				os.Exit(1)
+			}
+			year = parsed
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		}
+	}
+	if output == "" {
+		output = fmt.Sprintf("tax-2086-%d.csv", year)
+	}
+
+	cycles, accumulations, err := loadTaxData()
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+
+	lines := buildTax2086(cycles, accumulations, year)
+	if len(lines) == 0 {
+		color.Yellow("Aucune cession en %d", year)
+		return
+	}
+
+	color.Cyan("Cessions %d (formulaire 2086, montants en USDC)", year)
+	fmt.Printf("%-19s %-8s %6s %12s %12s %10s %12s %12s\n",
+		"Date", "Exchange", "Cycle", "Portefeuille", "Cession", "Frais", "Acq. net", "Plus-value")
+
+	var totalGain, totalDisposals, displayGain float64
+	estimatedCount, displayCount := 0, 0
+	for _, line := range lines {
+		marker := ""
+		if line.FeesEstimated {
+			marker = " *"
+			estimatedCount++
+		}
+		fmt.Printf("%-19s %-8s %6d %12.2f %12.2f %10.2f %12.2f %12.2f%s\n",
+			line.Date.Format("02/01/2006 15:04"), line.Exchange, line.CycleIdInt,
+			line.PortfolioValue, line.DisposalPrice, line.DisposalFees, line.NetAcquisition, line.Gain, marker)
+		totalGain += line.Gain
+		totalDisposals += line.DisposalPrice
+
+		// Plus-value convertie au taux publié le jour de la cession
+		if conversion, ok := convertForDisplay(line.Gain, line.Date); ok {
+			displayGain += conversion.Amount
+			displayCount++
+		}
+	}
+
+	color.White("%d cession(s), total des cessions: %.2f USDC", len(lines), totalDisposals)
+	if totalGain >= 0 {
+		color.Green("Plus-value nette %d: %.2f USDC", year, totalGain)
+	} else {
+		color.Red("Moins-value nette %d: %.2f USDC", year, totalGain)
+	}
+	if displayCount == len(lines) {
+		color.White("  soit %.2f %s au taux de référence BCE de chaque date de cession", displayGain, displayCurrency())
+	} else if displayCurrency() != "" {
+		color.Yellow("  Conversion en %s indisponible: taux de change manquants (FX_SOURCE_URL)", displayCurrency())
+	}
+	if estimatedCount > 0 {
+		color.Yellow("* %d cession(s) avec des frais estimés: vérifiez-les sur les relevés de l'exchange", estimatedCount)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		color.Red("Erreur lors de la création du fichier %s: %v", output, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	if err := writeTax2086CSV(file, lines); err != nil {
+		color.Red("Erreur lors de l'écriture du fichier %s: %v", output, err)
+		os.Exit(1)
+	}
+	color.Green("Rapport enregistré dans %s", output)
+}
+
+// handleTaxExport télécharge les lignes de cession du formulaire 2086 (?year=2024)
+func handleTaxExport(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year() - 1
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 2000 {
+			http.Error(w, "Année invalide: "+value, http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+
+	cycles, accumulations, err := loadTaxData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=tax-2086-%d.csv", year))
+	if err := writeTax2086CSV(w, buildTax2086(cycles, accumulations, year)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"main/internal/database"
+)
+
+var updateGolden = flag.Bool("update", false, "réécrire les fichiers de référence de testdata")
+
+// taxScenario est un petit historique connu: deux années de cessions, une accumulation,
+// un cycle dont les frais n'ont pas été communiqués et des cycles encore ouverts
+func taxScenario() ([]*database.Cycle, []*database.Accumulation) {
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	cycles := []*database.Cycle{
+		{
+			IdInt: 1, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+			BuyPrice: 30000, SellPrice: 31000,
+			CreatedAt: at(2023, time.November, 2, 10, 0), CompletedAt: at(2023, time.November, 5, 12, 0),
+			TotalFees: 0.61, SellFees: 0.31,
+		},
+		{
+			// Frais inconnus: estimés au taux standard de Kraken
+			IdInt: 3, Exchange: "KRAKEN", Status: "completed", Quantity: 0.02,
+			BuyPrice: 42000, BuyFillPrice: 41950, SellPrice: 43000,
+			CreatedAt: at(2024, time.January, 15, 9, 0), CompletedAt: at(2024, time.January, 20, 14, 30),
+		},
+		{
+			IdInt: 4, Exchange: "BINANCE", Status: "completed", Quantity: 0.01,
+			BuyPrice: 60000, SellPrice: 62000,
+			CreatedAt: at(2024, time.March, 1, 8, 0), CompletedAt: at(2024, time.March, 4, 8, 0),
+			TotalFees: 1.22, SellFees: 0.62,
+		},
+		{
+			// Vente en cours: le BTC fait partie du portefeuille lors de la cession du cycle 4
+			IdInt: 5, Exchange: "MEXC", Status: "sell", Quantity: 0.01,
+			BuyPrice: 61000, SellPrice: 62500,
+			CreatedAt: at(2024, time.March, 2, 8, 0),
+		},
+		{
+			// Achat non exécuté: ignoré
+			IdInt: 6, Exchange: "BINANCE", Status: "buy", Quantity: 0.01,
+			BuyPrice: 59000, SellPrice: 60500,
+			CreatedAt: at(2024, time.March, 3, 8, 0),
+		},
+		{
+			IdInt: 7, Exchange: "KUCOIN", Status: "completed", Quantity: 0.01,
+			BuyPrice: 90000, SellPrice: 93000,
+			CreatedAt: at(2024, time.December, 28, 8, 0), CompletedAt: at(2025, time.January, 3, 8, 0),
+			TotalFees: 1.83, SellFees: 0.93, FeesEstimated: true,
+		},
+	}
+
+	accumulations := []*database.Accumulation{
+		{
+			IdInt: 1, Exchange: "BINANCE", CycleIdInt: 2, Quantity: 0.005,
+			OriginalBuyPrice: 40000, TargetSellPrice: 41000, CancelPrice: 44000,
+			CreatedAt: at(2023, time.December, 10, 18, 0),
+		},
+	}
+
+	return cycles, accumulations
+}
+
+func TestTax2086Golden(t *testing.T) {
+	cycles, accumulations := taxScenario()
+
+	for _, year := range []int{2023, 2024, 2025} {
+		var buf bytes.Buffer
+		if err := writeTax2086CSV(&buf, buildTax2086(cycles, accumulations, year)); err != nil {
+			t.Fatalf("écriture du CSV %d: %v", year, err)
+		}
+
+		golden := filepath.Join("testdata", fmt.Sprintf("tax_2086_%d.golden.csv", year))
+		if *updateGolden {
+			if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+				t.Fatalf("écriture de %s: %v", golden, err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("lecture de %s: %v", golden, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("rapport %d différent de %s:\n--- obtenu\n%s\n--- attendu\n%s", year, golden, buf.String(), want)
+		}
+	}
+}
+
+func TestTax2086CapitalFractions(t *testing.T) {
+	cycles, accumulations := taxScenario()
+
+	// Les fractions de capital de 2024 reprennent celles des cessions de 2023
+	lines2023 := buildTax2086(cycles, accumulations, 2023)
+	lines2024 := buildTax2086(cycles, accumulations, 2024)
+	if len(lines2023) != 1 || len(lines2024) != 2 {
+		t.Fatalf("cessions: %d en 2023, %d en 2024; attendu 1 et 2", len(lines2023), len(lines2024))
+	}
+
+	first := lines2023[0]
+	consumed := first.NetAcquisition * first.DisposalPrice / first.PortfolioValue
+	if diff := lines2024[0].CapitalFractions - consumed; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("fractions de capital 2024 = %.8f, attendu %.8f", lines2024[0].CapitalFractions, consumed)
+	}
+
+	if !lines2024[0].FeesEstimated || lines2024[1].FeesEstimated {
+		t.Errorf("seule la cession du cycle 3 doit être signalée avec des frais estimés")
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"main/internal/exchanges/common"
+
+	"github.com/fatih/color"
+)
+
+// Seuils d'affichage du décalage d'horloge: au-delà de timeSkewRejected, les requêtes
+// signées sortent de la fenêtre de validité par défaut (recvWindow de 5 secondes)
+const (
+	timeSkewWarning  = time.Second
+	timeSkewRejected = 5 * time.Second
+)
+
+// exchangeSkew est le décalage mesuré sur un exchange
+type exchangeSkew struct {
+	Exchange string
+	Skew     time.Duration
+	Err      error
+}
+
+// TimeCheck mesure le décalage entre l'horloge locale et celle de chaque exchange activé
+// (--time-check). Le décalage est ensuite corrigé automatiquement pendant les sessions.
+func TimeCheck() {
+	var exchanges []string
+	for _, name := range []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"} {
+		exchangeConfig, exists := cfg.Exchanges[name]
+		if !exists || !exchangeConfig.Enabled {
+			continue
+		}
+		if exchangeConfig.APIKey == "" || exchangeConfig.SecretKey == "" {
+			continue
+		}
+		exchanges = append(exchanges, name)
+	}
+
+	if len(exchanges) == 0 {
+		color.Yellow("Aucun exchange activé avec des clés API")
+		return
+	}
+
+	results := make([]exchangeSkew, len(exchanges))
+	var wg sync.WaitGroup
+	for i, name := range exchanges {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = measureExchangeSkew(name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	color.Cyan("Décalage d'horloge (heure de l'exchange - heure locale)")
+	for _, result := range results {
+		skew := result.Skew.Round(time.Millisecond)
+		magnitude := skew
+		if magnitude < 0 {
+			magnitude = -magnitude
+		}
+
+		switch {
+		case result.Err != nil:
+			color.Red("  %-8s erreur: %v", result.Exchange, result.Err)
+		case magnitude >= timeSkewRejected:
+			color.Red("  %-8s %+.3f s: requêtes signées rejetées sans correction (corrigé automatiquement pendant les sessions)", result.Exchange, skew.Seconds())
+		case magnitude >= timeSkewWarning:
+			color.Yellow("  %-8s %+.3f s: synchronisez l'horloge du système", result.Exchange, skew.Seconds())
+		default:
+			color.Green("  %-8s %+.3f s", result.Exchange, skew.Seconds())
+		}
+	}
+	fmt.Println("")
+}
+
+// measureExchangeSkew mesure le décalage d'horloge d'un exchange
+func measureExchangeSkew(exchange string) exchangeSkew {
+	result := exchangeSkew{Exchange: exchange}
+
+	clock, ok := GetClientByExchange(exchange).(common.ServerClock)
+	if !ok {
+		result.Err = fmt.Errorf("heure du serveur non disponible pour cet exchange")
+		return result
+	}
+	result.Skew, result.Err = common.MeasureSkew(clock.ServerTime)
+	return result
+}
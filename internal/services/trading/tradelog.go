@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"main/internal/config"
+	"main/internal/database"
+	"main/pkg/logger"
+
+	"github.com/fatih/color"
+)
+
+// tradeLogger reçoit les décisions de trading. En format texte, la sortie
+// colorée habituelle est conservée ; en JSON, chaque décision devient une ligne
+// structurée exploitable par un agrégateur de logs.
+var tradeLogger = logger.NewLogger(logger.LogConfig{Level: "info", Format: "text"})
+
+// initTradeLogger configure le logger de trading depuis LOG_LEVEL et LOG_FORMAT
+func initTradeLogger(c *config.Config) {
+	tradeLogger = logger.NewLogger(logger.LogConfig{
+		Level:  c.LogLevel,
+		Format: c.LogFormat,
+	})
+}
+
+// tradeEvent décrit une décision de trading et ses champs structurés
+type tradeEvent struct {
+	fields logger.Fields
+}
+
+// cycleEvent crée un événement rattaché à un cycle
+func cycleEvent(cycle *database.Cycle, action string) *tradeEvent {
+	return &tradeEvent{fields: logger.Fields{
+		"cycle_id": cycle.IdInt,
+		"exchange": cycle.Exchange,
+		"action":   action,
+	}}
+}
+
+// exchangeEvent crée un événement rattaché à un exchange
+func exchangeEvent(exchange, action string) *tradeEvent {
+	return &tradeEvent{fields: logger.Fields{
+		"exchange": exchange,
+		"action":   action,
+	}}
+}
+
+// with ajoute un champ structuré (price, order_id, error...) à l'événement
+func (e *tradeEvent) with(key string, value interface{}) *tradeEvent {
+	fields := make(logger.Fields, len(e.fields)+1)
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return &tradeEvent{fields: fields}
+}
+
+// emit écrit l'événement au niveau demandé, en couleur ou en JSON selon LOG_FORMAT
+func (e *tradeEvent) emit(level logger.LogLevel, colorFn func(format string, a ...interface{}), format string, args ...interface{}) {
+	if tradeLogger.IsJSON() {
+		tradeLogger.Log(level, e.fields, format, args...)
+		return
+	}
+	if tradeLogger.Enabled(level) {
+		colorFn(format, args...)
+		tradeLogger.Capture(level, e.fields, format, args...)
+	}
+}
+
+// detail affiche un détail informatif secondaire (masqué dès LOG_LEVEL=warn)
+func (e *tradeEvent) detail(format string, args ...interface{}) {
+	e.emit(logger.LevelInfo, color.White, format, args...)
+}
+
+// heading affiche un titre de section
+func (e *tradeEvent) heading(format string, args ...interface{}) {
+	e.emit(logger.LevelInfo, color.Cyan, format, args...)
+}
+
+// info affiche une étape du traitement d'un cycle
+func (e *tradeEvent) info(format string, args ...interface{}) {
+	e.emit(logger.LevelInfo, color.Yellow, format, args...)
+}
+
+// success affiche une action aboutie
+func (e *tradeEvent) success(format string, args ...interface{}) {
+	e.emit(logger.LevelInfo, color.Green, format, args...)
+}
+
+// warn signale une situation anormale qui n'interrompt pas le traitement
+func (e *tradeEvent) warn(format string, args ...interface{}) {
+	e.emit(logger.LevelWarn, color.Yellow, format, args...)
+}
+
+// fail signale une erreur, publiée aussi comme événement "error" (hooks et webhooks qui le demandent)
+func (e *tradeEvent) fail(format string, args ...interface{}) {
+	e.emit(logger.LevelError, color.Red, format, args...)
+	e.notifyError(fmt.Sprintf(format, args...))
+}
+
+// logSeparator affiche une ligne vide entre deux sections en format texte
+func logSeparator() {
+	if !tradeLogger.IsJSON() && tradeLogger.Enabled(logger.LevelInfo) {
+		fmt.Println("")
+	}
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/i18n"
+
+	"github.com/fatih/color"
+)
+
+// tradingHaltStateFile conserve entre les exécutions les exchanges dont la paire BTC/USDC est
+// suspendue (migration, retrait de la cote)
+const tradingHaltStateFile = "trading_halts.json"
+
+// tradingHaltEntry décrit la suspension en cours de la paire d'un exchange
+type tradingHaltEntry struct {
+	Since     time.Time `json:"since"`     // Première exécution ayant constaté la suspension
+	CheckedAt time.Time `json:"checkedAt"` // Dernière vérification de l'état de la paire
+	Status    string    `json:"status"`    // État publié par l'exchange (HALT, BREAK, cancel_only...)
+}
+
+// tradingHaltMu protège les lectures-écritures du fichier d'état
+var tradingHaltMu sync.Mutex
+
+// tradingHaltStatePath retourne le chemin du fichier d'état, à côté de la base de données
+func tradingHaltStatePath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), tradingHaltStateFile)
+}
+
+// loadTradingHalts lit les suspensions enregistrées (aucune si le fichier n'existe pas)
+func loadTradingHalts() (map[string]tradingHaltEntry, error) {
+	tradingHaltMu.Lock()
+	defer tradingHaltMu.Unlock()
+	return readTradingHalts()
+}
+
+// readTradingHalts lit le fichier d'état. tradingHaltMu doit être verrouillé.
+func readTradingHalts() (map[string]tradingHaltEntry, error) {
+	content, err := os.ReadFile(tradingHaltStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]tradingHaltEntry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := make(map[string]tradingHaltEntry)
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("fichier %s invalide: %w", tradingHaltStateFile, err)
+	}
+	return entries, nil
+}
+
+// writeTradingHalts publie les suspensions pour --check, --status et /health. tradingHaltMu
+// doit être verrouillé.
+func writeTradingHalts(entries map[string]tradingHaltEntry) {
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des suspensions: %v", err)
+		return
+	}
+	if err := os.WriteFile(tradingHaltStatePath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", tradingHaltStateFile, err)
+	}
+}
+
+// markTradingHalt enregistre que la paire de l'exchange est suspendue
+func markTradingHalt(exchange, status string) tradingHaltEntry {
+	now := time.Now()
+	entry := tradingHaltEntry{Since: now, CheckedAt: now, Status: status}
+	if simulating() {
+		return entry
+	}
+
+	tradingHaltMu.Lock()
+	defer tradingHaltMu.Unlock()
+
+	entries, err := readTradingHalts()
+	if err != nil {
+		log.Printf("Erreur lors de la lecture de %s: %v", tradingHaltStateFile, err)
+		entries = make(map[string]tradingHaltEntry)
+	}
+	// Une suspension déjà connue garde sa date de début
+	if previous, exists := entries[exchange]; exists {
+		entry.Since = previous.Since
+	}
+	entries[exchange] = entry
+	writeTradingHalts(entries)
+	return entry
+}
+
+// clearTradingHalt retire un exchange des suspensions et indique s'il y figurait
+func clearTradingHalt(exchange string) bool {
+	if simulating() {
+		return false
+	}
+
+	tradingHaltMu.Lock()
+	defer tradingHaltMu.Unlock()
+
+	entries, err := readTradingHalts()
+	if err != nil {
+		return false
+	}
+	if _, exists := entries[exchange]; !exists {
+		return false
+	}
+	delete(entries, exchange)
+	writeTradingHalts(entries)
+	return true
+}
+
+// checkTradingHalt lit l'état de la paire BTC/USDC de l'exchange et indique si ses ordres
+// doivent être laissés en l'état: ni création ni annulation tant que la paire n'est pas de
+// nouveau négociable. Si l'état ne peut pas être lu, une suspension déjà constatée est
+// maintenue et aucune nouvelle n'est supposée.
+func checkTradingHalt(exchange string) (tradingHaltEntry, bool) {
+	reader, ok := GetClientByExchange(exchange).(common.PairStatusReader)
+	if !ok {
+		return tradingHaltEntry{}, false
+	}
+
+	status, err := reader.PairStatus()
+	if err != nil {
+		entries, loadErr := loadTradingHalts()
+		if entry, halted := entries[exchange]; loadErr == nil && halted {
+			return entry, true
+		}
+		return tradingHaltEntry{}, false
+	}
+	if !status.Trading {
+		return markTradingHalt(exchange, status.Status), true
+	}
+	if clearTradingHalt(exchange) {
+		exchangeEvent(exchange, "trading_resumed").success(i18n.T("update.trading_resumed"), exchange)
+	}
+	return tradingHaltEntry{}, false
+}
+
+// printTradingHalts affiche les exchanges dont la paire est suspendue (--check)
+func printTradingHalts() {
+	entries, err := loadTradingHalts()
+	if err != nil {
+		color.Red("Erreur lors de la lecture de l'état des suspensions: %v", err)
+		return
+	}
+
+	color.Cyan("Suspensions de la paire BTC/USDC")
+	if len(entries) == 0 {
+		fmt.Println("  Aucune paire suspendue")
+		return
+	}
+	exchanges := make([]string, 0, len(entries))
+	for exchange := range entries {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+	for _, exchange := range exchanges {
+		entry := entries[exchange]
+		color.Yellow("  %-8s état %s depuis %s (vérifié le %s)", exchange, entry.Status,
+			entry.Since.Format("2006-01-02 15:04:05"), entry.CheckedAt.Format("2006-01-02 15:04:05"))
+	}
+}
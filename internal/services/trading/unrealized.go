@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"encoding/json"
+	"log"
+	"main/internal/database"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// lastPricesFile est le fichier où sont publiés les prix BTC relevés par la dernière mise à jour
+const lastPricesFile = "last_prices.json"
+
+// lastPrices contient le dernier prix BTC connu de chaque exchange
+type lastPrices struct {
+	UpdatedAt time.Time          `json:"updatedAt"`
+	Prices    map[string]float64 `json:"prices"`
+}
+
+// lastPricesPath retourne le chemin du fichier des prix, à côté de la base de données
+func lastPricesPath() string {
+	return filepath.Join(filepath.Dir(database.GetDatabasePath()), lastPricesFile)
+}
+
+// saveLastPrices publie les prix relevés pendant la mise à jour, en conservant ceux
+// des exchanges qui n'ont pas répondu
+func saveLastPrices(prices map[string]float64) {
+	if len(prices) == 0 || simulating() {
+		return
+	}
+
+	published := loadLastPrices()
+	for exchange, price := range prices {
+		if price > 0 {
+			published.Prices[exchange] = price
+		}
+	}
+	published.UpdatedAt = time.Now()
+
+	content, err := json.MarshalIndent(published, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation des prix: %v", err)
+		return
+	}
+	if err := os.WriteFile(lastPricesPath(), content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", lastPricesFile, err)
+	}
+}
+
+// loadLastPrices lit les derniers prix publiés (aucun si le fichier est absent ou invalide)
+func loadLastPrices() lastPrices {
+	published := lastPrices{Prices: make(map[string]float64)}
+	content, err := os.ReadFile(lastPricesPath())
+	if err != nil {
+		return published
+	}
+	if err := json.Unmarshal(content, &published); err != nil || published.Prices == nil {
+		return lastPrices{Prices: make(map[string]float64)}
+	}
+	return published
+}
+
+// unrealizedProfit calcule le P&L latent d'un cycle en vente au prix actuel: valeur de la
+// quantité au prix du marché moins le coût d'achat, les frais d'achat et les frais de vente estimés.
+// Le booléen est faux si le cycle n'est pas en vente ou si le prix est inconnu.
+func unrealizedProfit(cycle *database.Cycle, currentPrice float64) (float64, bool) {
+	if cycle.Status != "sell" || currentPrice <= 0 {
+		return 0, false
+	}
+
+	marketValue := currentPrice * cycle.Quantity
+	sellFees := marketValue * getFeeRateForExchange(cycle.Exchange)
+	return (currentPrice-cycle.EffectiveBuyPrice())*cycle.Quantity - cycle.TotalFees - sellFees, true
+}
+
+// unrealizedByExchange totalise le P&L latent des cycles en vente par exchange.
+// Seuls les exchanges dont le prix est connu figurent dans le résultat.
+func unrealizedByExchange(cycles []*database.Cycle, prices map[string]float64) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, cycle := range cycles {
+		if profit, ok := unrealizedProfit(cycle, prices[cycle.Exchange]); ok {
+			totals[cycle.Exchange] += profit
+		}
+	}
+	return totals
+}
+
+// handleCyclesAPI expose les cycles au format JSON, avec le P&L latent des cycles en vente
+// calculé sur les prix de la dernière mise à jour
+func handleCyclesAPI(w http.ResponseWriter, r *http.Request) {
+	cycles, err := database.GetRepository().FindAll()
+	if err != nil {
+		http.Error(w, "Erreur lors de la récupération des cycles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prices := loadLastPrices()
+	dtos := make([]map[string]interface{}, 0, len(cycles))
+	for _, cycle := range cycles {
+		dto := convertCycleToDTO(cycle)
+		addUnrealizedToDTO(dto, cycle, prices.Prices)
+		dtos = append(dtos, dto)
+	}
+
+	response := map[string]interface{}{
+		"pricesUpdatedAt":      prices.UpdatedAt,
+		"prices":               prices.Prices,
+		"unrealizedByExchange": unrealizedByExchange(cycles, prices.Prices),
+		"displayCurrency":      displayCurrency(),
+		"cycles":               dtos,
+	}
+
+	// ?groupBy=exchange: cycles répartis par exchange avec leurs sous-totaux, et total général
+	if r.URL.Query().Get("groupBy") == groupByExchange {
+		subtotals, total := subtotalsByExchange(cycles, prices.Prices)
+		if accumulations, err := database.GetAccumulationRepository().FindAll(); err == nil {
+			addAccumulationsToSubtotals(subtotals, total, accumulations, prices.Prices, "")
+		}
+		response["groups"] = exchangeGroups(dtos, subtotals)
+		response["total"] = total
+		delete(response, "cycles")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// cyclesGroup est un exchange de la réponse groupée de /api/cycles
+type cyclesGroup struct {
+	*exchangeSubtotal
+	Cycles []map[string]interface{} `json:"cycles"`
+}
+
+// exchangeGroups répartit les DTOs des cycles par exchange, dans l'ordre alphabétique
+func exchangeGroups(dtos []map[string]interface{}, subtotals map[string]*exchangeSubtotal) []cyclesGroup {
+	byExchange := make(map[string][]map[string]interface{})
+	for _, dto := range dtos {
+		exchange, _ := dto["exchange"].(string)
+		byExchange[exchange] = append(byExchange[exchange], dto)
+	}
+
+	exchanges := make([]string, 0, len(subtotals))
+	for exchange := range subtotals {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+
+	groups := make([]cyclesGroup, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		// Un exchange peut ne porter que du BTC accumulé
+		cycles := byExchange[exchange]
+		if cycles == nil {
+			cycles = []map[string]interface{}{}
+		}
+		groups = append(groups, cyclesGroup{exchangeSubtotal: subtotals[exchange], Cycles: cycles})
+	}
+	return groups
+}
+
+// addUnrealizedToDTO ajoute le prix actuel et le P&L latent au DTO d'un cycle
+func addUnrealizedToDTO(dto map[string]interface{}, cycle *database.Cycle, prices map[string]float64) {
+	profit, ok := unrealizedProfit(cycle, prices[cycle.Exchange])
+	dto["hasUnrealized"] = ok
+	dto["unrealizedProfit"] = profit
+	dto["unrealizedPercent"] = 0.0
+	dto["currentPrice"] = prices[cycle.Exchange]
+	// P&L latent dans la devise d'affichage, au dernier taux publié
+	dto["unrealizedDisplay"] = nil
+	if ok {
+		dto["unrealizedDisplay"] = displayValue(profit, time.Now())
+	}
+	if buyTotal := cycle.EffectiveBuyPrice() * cycle.Quantity; ok && buyTotal > 0 {
+		dto["unrealizedPercent"] = profit / buyTotal * 100
+	}
+}
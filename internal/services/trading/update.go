@@ -1,118 +1,51 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"main/internal/config"
 	"main/internal/database"
 	"main/internal/exchanges/common"
+	"main/internal/i18n"
 	"math"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/buger/jsonparser"
 	"github.com/fatih/color"
 )
 
-type cycleStatistics struct {
-	totalCycles     int
-	buyCycles       int
-	sellCycles      int
-	completedCycles int
-	totalProfit     float64
+// statsExchanges sont les exchanges du récapitulatif de fin de mise à jour, avec leur nom affiché
+var statsExchanges = []struct{ key, name string }{
+	{"BINANCE", "Binance"},
+	{"MEXC", "MEXC"},
+	{"KUCOIN", "KuCoin"},
+	{"KRAKEN", "Kraken"},
 }
 
-// cleanOrderId nettoie et normalise un ID d'ordre selon l'exchange spécifié
-func cleanOrderId(orderId string, exchange ...string) string {
-	// Si l'ID est vide, retourner une chaîne vide
-	if orderId == "" {
-		return ""
-	}
-
-	// Nettoyer les espaces au début et à la fin
-	orderId = strings.TrimSpace(orderId)
-
-	// Déterminer l'exchange (par défaut: BINANCE)
-	ex := "BINANCE"
-	if len(exchange) > 0 && exchange[0] != "" {
-		ex = strings.ToUpper(exchange[0])
-	}
-
-	// Traitement spécifique par exchange
-	switch ex {
-	case "MEXC":
-		// Pour MEXC, normaliser le préfixe C02__
-		if strings.HasPrefix(orderId, "C02__") {
-			return orderId // Garder l'ID tel quel si le préfixe est déjà présent
-		} else if strings.Contains(orderId, "C02__") {
-			// Extraire la partie après C02__
-			parts := strings.Split(orderId, "C02__")
-			if len(parts) > 1 {
-				return "C02__" + parts[1]
-			}
-		}
-
-		// Supprimer tous les caractères non alphanumériques
-		re := regexp.MustCompile("[^a-zA-Z0-9]")
-		cleanedId := re.ReplaceAllString(orderId, "")
-
-		if cleanedId != "" {
-			// On laisse le préfixe C02__ pour la cohérence
-			return "C02__" + cleanedId
-		}
-		return orderId
-
-	case "BINANCE":
-		// Pour Binance, extraire uniquement les chiffres
-		re := regexp.MustCompile("[^0-9]")
-		cleanId := re.ReplaceAllString(orderId, "")
-
-		// Si l'ID est vide après nettoyage, retourner l'original
-		if cleanId == "" {
-			return orderId
-		}
-
-		return cleanId
-
-	case "KUCOIN":
-		// Pour KuCoin, extraire un motif d'ID typique (24 caractères alphanumériques)
-		if len(orderId) > 24 {
-			re := regexp.MustCompile("[a-zA-Z0-9]{24}")
-			matches := re.FindAllString(orderId, -1)
-			if len(matches) > 0 {
-				return matches[0]
-			}
-		}
-		return orderId
-
-	case "KRAKEN":
-		// Pour Kraken, les IDs sont généralement des chaînes alphanumériques sans préfixe spécifique
-		// Nous nettoyons simplement les espaces et caractères non alphanumériques
-		re := regexp.MustCompile("[^a-zA-Z0-9-]")
-		cleanId := re.ReplaceAllString(orderId, "")
-
-		if cleanId == "" {
-			return orderId
-		}
-
-		return cleanId
-
-	default:
-		// Pour les autres exchanges, retourner l'ID tel quel
-		return orderId
-	}
-}
+// statsPeriods sont les périodes des profits du récapitulatif: 24h, 7 jours, 30 jours et 3 mois
+var statsPeriods = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 90 * 24 * time.Hour}
 
 func Update() {
 	// Récupérer tous les exchanges configurés
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		color.Red("Erreur de configuration: %v", err)
+		exchangeEvent("", "update").with("error", err).fail(i18n.T("update.config_error"), err)
 		return
 	}
 
+	// Refermer les disjoncteurs: chaque exécution repart d'un état sain
+	resetCircuitBreakers()
+	defer saveCircuitBreakers()
+	// Chaque exécution rend sa confiance aux prix des exchanges
+	resetPriceGuard()
+	// Le résumé des limites de requêtes ne porte que sur cette mise à jour
+	common.RateLimits.Reset()
+
+	// Pertes réalisées de la journée: au-delà de DAILY_MAX_LOSS_USDC, plus aucun nouvel ordre
+	refreshLossLimits()
+
 	// Liste des exchanges à traiter
 	exchanges := []string{"BINANCE", "MEXC", "KUCOIN", "KRAKEN"}
 
@@ -120,32 +53,54 @@ func Update() {
 	allBalances := make(map[string]map[string]common.DetailedBalance)
 	allPrices := make(map[string]float64)
 
+	// Exchanges en maintenance et nombre de leurs cycles ignorés, résumés en fin de traitement
+	inMaintenance := make(map[string]maintenanceEntry)
+	maintenanceSkipped := make(map[string]int)
+
+	// Exchanges dont la paire BTC/USDC est suspendue et nombre de leurs cycles laissés en l'état
+	tradingHalted := make(map[string]tradingHaltEntry)
+	haltSkipped := make(map[string]int)
+
 	// Traiter chaque exchange
 	for _, exchangeName := range exchanges {
 		// Vérifier si l'exchange est configuré
 		exchangeConfig, exists := cfg.Exchanges[exchangeName]
 		if !exists || !exchangeConfig.Enabled {
-			color.Yellow("Exchange %s non configuré ou désactivé", exchangeName)
+			exchangeEvent(exchangeName, "skip_exchange").info(i18n.T("update.exchange_disabled"), exchangeName)
+			continue
+		}
+
+		// Un exchange en maintenance n'est pas sollicité: ses cycles reprendront ensuite
+		if entry, skip := checkMaintenance(exchangeName); skip {
+			inMaintenance[exchangeName] = entry
 			continue
 		}
 
+		// Paire suspendue ou retirée de la cote: aucun ordre créé ni annulé jusqu'à sa reprise
+		if entry, halted := checkTradingHalt(exchangeName); halted {
+			tradingHalted[exchangeName] = entry
+			continue
+		}
+
+		ev := exchangeEvent(exchangeName, "balances")
+
 		// Initialiser le client pour cet exchange
 		// Utilisation d'une fonction try/catch pour éviter les panics
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					color.Red("Panic lors de l'initialisation du client pour %s: %v", exchangeName, r)
+					ev.with("error", fmt.Sprint(r)).fail(i18n.T("update.client_init_panic"), exchangeName, r)
 				}
 			}()
 
-			client := GetClientByExchange(exchangeName)
+			client := guardedClient(exchangeName)
 			if client == nil {
-				color.Red("Client nil pour l'exchange %s", exchangeName)
+				ev.fail(i18n.T("update.client_nil"), exchangeName)
 				return
 			}
 
 			// Afficher les informations de l'exchange
-			color.Cyan("=== Informations pour %s ===", exchangeName)
+			ev.heading(i18n.T("update.exchange_heading"), exchangeName)
 
 			// Récupérer le prix actuel du BTC
 			// Protection contre les panics
@@ -153,7 +108,8 @@ func Update() {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						color.Red("Erreur lors de la récupération du prix BTC pour %s: %v", exchangeName, r)
+						breakerFor(exchangeName).RecordFailure(fmt.Errorf("%v", r))
+						ev.with("error", fmt.Sprint(r)).fail(i18n.T("update.price_error"), exchangeName, r)
 					}
 				}()
 				lastPrice = client.GetLastPriceBTC()
@@ -161,12 +117,12 @@ func Update() {
 
 			// Si le prix n'a pas pu être récupéré, passer à l'exchange suivant
 			if lastPrice == 0 {
-				color.Red("Impossible de récupérer le prix BTC pour %s", exchangeName)
+				ev.fail(i18n.T("update.price_unavailable"), exchangeName)
 				return
 			}
 
 			allPrices[exchangeName] = lastPrice
-			color.White("Prix actuel du BTC: %.2f USDC", lastPrice)
+			ev.with("price", lastPrice).detail(i18n.T("update.current_price"), lastPrice)
 
 			// Récupérer les soldes détaillés
 			// Protection contre les panics
@@ -174,20 +130,20 @@ func Update() {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						color.Red("Erreur lors de la récupération des soldes pour %s: %v", exchangeName, r)
+						ev.with("error", fmt.Sprint(r)).fail(i18n.T("update.balances_error"), exchangeName, r)
 					}
 				}()
 				var err error
 				balances, err = client.GetDetailedBalances()
 				if err != nil {
-					color.Red("Erreur lors de la récupération des soldes pour %s: %v", exchangeName, err)
+					ev.with("error", err).fail(i18n.T("update.balances_error"), exchangeName, err)
 					return
 				}
 			}()
 
 			// Si les soldes n'ont pas pu être récupérés, passer à l'exchange suivant
 			if balances == nil {
-				color.Red("Impossible de récupérer les soldes pour %s", exchangeName)
+				ev.fail(i18n.T("update.balances_unavailable"), exchangeName)
 				return
 			}
 
@@ -197,46 +153,92 @@ func Update() {
 			// Afficher les soldes BTC
 			btcBalance, hasBTC := balances["BTC"]
 			if hasBTC {
-				color.Yellow("Solde BTC:")
-				color.White("  Libre:      %.8f BTC (%.2f USDC)", btcBalance.Free, btcBalance.Free*lastPrice)
-				color.White("  Verrouillé: %.8f BTC (%.2f USDC)", btcBalance.Locked, btcBalance.Locked*lastPrice)
-				color.White("  Total:      %.8f BTC (%.2f USDC)", btcBalance.Total, btcBalance.Total*lastPrice)
+				ev.info(i18n.T("update.btc_balance"))
+				ev.detail(i18n.T("update.btc_free"), btcBalance.Free, btcBalance.Free*lastPrice)
+				ev.detail(i18n.T("update.btc_locked"), btcBalance.Locked, btcBalance.Locked*lastPrice)
+				ev.detail(i18n.T("update.btc_total"), btcBalance.Total, btcBalance.Total*lastPrice)
+				if reserve := exchangeReserve(exchangeName).BTC; reserve > 0 {
+					ev.detail(i18n.T("update.btc_reserved"), reserve, reserve*lastPrice)
+				}
 			} else {
-				color.Yellow("Solde BTC: Non disponible")
+				ev.warn(i18n.T("update.btc_balance_unavailable"))
 			}
 
 			// Afficher les soldes USDC
 			usdcBalance, hasUSDC := balances["USDC"]
 			if hasUSDC {
-				color.Yellow("Solde USDC:")
-				color.White("  Libre:      %.2f USDC", usdcBalance.Free)
-				color.White("  Verrouillé: %.2f USDC", usdcBalance.Locked)
-				color.White("  Total:      %.2f USDC", usdcBalance.Total)
+				ev.info(i18n.T("update.usdc_balance"))
+				ev.detail(i18n.T("update.usdc_free"), usdcBalance.Free)
+				ev.detail(i18n.T("update.usdc_locked"), usdcBalance.Locked)
+				ev.detail(i18n.T("update.usdc_total"), usdcBalance.Total)
+				if reserve := exchangeReserve(exchangeName).USDC; reserve > 0 {
+					ev.detail(i18n.T("update.usdc_reserved"), reserve)
+				}
 			} else {
-				color.Yellow("Solde USDC: Non disponible")
+				ev.warn(i18n.T("update.usdc_balance_unavailable"))
 			}
 
-			fmt.Println("") // Ligne vide pour séparer les sections
+			logSeparator() // Ligne vide pour séparer les sections
 		}()
 	}
 
-	// Récupérer tous les cycles depuis le repository
+	// Écarter les prix aberrants (médiane des autres exchanges, dernier prix fiable)
+	guardPrices(cfg, allPrices)
+
+	// Publier les prix pour le P&L latent et l'historique du tableau de bord
+	trusted := trustedPrices(allPrices)
+	saveLastPrices(trusted)
+	recordPriceHistory(trusted)
+
+	// Récupérer les cycles en cours depuis le repository: l'historique complété n'est pas chargé
 	repo := database.GetRepository()
-	cycles, err := repo.FindAll()
+	cycles, err := repo.FindActive()
 	if err != nil {
-		color.Red("Erreur lors de la récupération des cycles: %v", err)
+		exchangeEvent("", "update").with("error", err).fail(i18n.T("update.cycles_error"), err)
 		return
 	}
 
-	// Traiter chaque cycle
+	// Traiter chaque cycle (une simulation rattache les actions interceptées au cycle en cours)
+	sim := currentSimulation()
 	for _, cycle := range cycles {
+		sim.setCycle(cycle)
+
+		// Cycles d'un exchange en maintenance: ignorés sans message, comptés pour le résumé
+		if _, skip := inMaintenance[cycle.Exchange]; skip {
+			maintenanceSkipped[cycle.Exchange]++
+			continue
+		}
+
+		// Cycles d'une paire suspendue: laissés en l'état, comptés pour le résumé
+		if _, halted := tradingHalted[cycle.Exchange]; halted {
+			haltSkipped[cycle.Exchange]++
+			continue
+		}
+
 		// Vérifier que l'exchange du cycle existe dans allPrices et allBalances
 		if _, priceExists := allPrices[cycle.Exchange]; !priceExists {
-			color.Yellow("Prix non disponible pour le cycle %d (Exchange: %s). Le cycle sera ignoré.",
+			cycleEvent(cycle, "skip_cycle").warn(i18n.T("update.cycle_no_price"),
 				cycle.IdInt, cycle.Exchange)
 			continue
 		}
 
+		// Ne plus solliciter un exchange dont le disjoncteur s'est ouvert pendant cette exécution
+		if breaker := breakerFor(cycle.Exchange); breaker.IsOpen() {
+			// Maintenance déclarée en cours d'exécution: enregistrée pour les exécutions suivantes
+			if state := breaker.State(); state.Maintenance {
+				inMaintenance[cycle.Exchange] = markMaintenance(cycle.Exchange, fmt.Errorf("%s", state.LastError))
+				breaker.MarkSkipped(cycle.IdInt)
+				maintenanceSkipped[cycle.Exchange]++
+				continue
+			}
+			if cycle.Status == "buy" || cycle.Status == "sell" || cycle.Status == database.StatusCancelPending {
+				breaker.MarkSkipped(cycle.IdInt)
+				cycleEvent(cycle, "skip_cycle").warn(i18n.T("update.cycle_breaker_open"),
+					cycle.IdInt, cycle.Exchange, breaker.State().LastError)
+			}
+			continue
+		}
+
 		// Déterminer le prix actuel et le client pour cet exchange
 		var lastPrice float64
 		var client common.Exchange
@@ -245,31 +247,31 @@ func Update() {
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					color.Red("Panic lors du traitement du cycle %d: %v", cycle.IdInt, r)
+					cycleEvent(cycle, "process_cycle").with("error", fmt.Sprint(r)).fail(i18n.T("update.cycle_panic"), cycle.IdInt, r)
 				}
 			}()
 
 			switch cycle.Exchange {
 			case "BINANCE":
 				lastPrice = allPrices["BINANCE"]
-				client = GetClientByExchange("BINANCE")
+				client = guardedClient("BINANCE")
 			case "MEXC":
 				lastPrice = allPrices["MEXC"]
-				client = GetClientByExchange("MEXC")
+				client = guardedClient("MEXC")
 			case "KUCOIN":
 				lastPrice = allPrices["KUCOIN"]
-				client = GetClientByExchange("KUCOIN")
+				client = guardedClient("KUCOIN")
 			case "KRAKEN":
 				lastPrice = allPrices["KRAKEN"]
-				client = GetClientByExchange("KRAKEN")
+				client = guardedClient("KRAKEN")
 			default:
-				color.Red("Exchange non supporté: %s", cycle.Exchange)
+				cycleEvent(cycle, "process_cycle").fail(i18n.T("update.exchange_unsupported"), cycle.Exchange)
 				return
 			}
 
 			// Vérifier que le client est bien initialisé
 			if client == nil {
-				color.Red("Client non initialisé pour l'exchange %s", cycle.Exchange)
+				cycleEvent(cycle, "process_cycle").fail(i18n.T("update.client_not_initialized"), cycle.Exchange)
 				return
 			}
 
@@ -278,7 +280,9 @@ func Update() {
 			case "buy":
 				processBuyCycle(client, repo, cycle, lastPrice)
 			case "sell":
-				processSellCycle(client, repo, cycle)
+				processSellCycle(client, repo, cycle, lastPrice)
+			case database.StatusCancelPending:
+				retryPendingCancel(client, repo, cycle, lastPrice)
 			case "completed":
 				// Pas d'action nécessaire pour les cycles complétés
 				return
@@ -286,41 +290,97 @@ func Update() {
 		}()
 	}
 
+	sim.setCycle(nil)
+
+	// Une seule ligne par exchange en maintenance
+	for _, exchangeName := range exchanges {
+		if entry, skip := inMaintenance[exchangeName]; skip {
+			exchangeEvent(exchangeName, "maintenance").with("until", entry.Until).with("skipped", maintenanceSkipped[exchangeName]).
+				warn(i18n.T("update.maintenance_summary"), exchangeName, i18n.FormatDateTime(entry.Until), maintenanceSkipped[exchangeName])
+		}
+		if entry, halted := tradingHalted[exchangeName]; halted {
+			exchangeEvent(exchangeName, "trading_halted").with("pair_status", entry.Status).with("since", entry.Since).with("skipped", haltSkipped[exchangeName]).
+				warn(i18n.T("update.trading_halted"), exchangeName, entry.Status, i18n.FormatDateTime(entry.Since), haltSkipped[exchangeName])
+		}
+	}
+
+	// Revendre le BTC accumulé dont le prix a atteint ACCU_SELL_TRIGGER_PRICE
+	for _, exchangeName := range exchanges {
+		lastPrice, priceExists := allPrices[exchangeName]
+		if _, skip := inMaintenance[exchangeName]; skip || !priceExists || !priceTrusted(exchangeName) || breakerFor(exchangeName).IsOpen() {
+			continue
+		}
+		if client := guardedClient(exchangeName); client != nil {
+			sellAccumulations(client, repo, exchangeName, cfg.Exchanges[exchangeName], lastPrice)
+		}
+	}
+
 	// À ajouter dans la fonction Update après avoir traité tous les cycles
 	// Afficher les informations d'accumulation pour chaque exchange
 	for _, exchangeName := range exchanges {
 		if exchangeConfig, exists := cfg.Exchanges[exchangeName]; exists && exchangeConfig.Enabled {
 			if exchangeConfig.Accumulation {
-				displayAccumulationInfo(exchangeName)
+				// Le BTC accumulé n'est valorisé qu'à un prix fiable
+				currentPrice := 0.0
+				if priceTrusted(exchangeName) {
+					currentPrice = allPrices[exchangeName]
+				}
+				displayAccumulationInfo(exchangeName, currentPrice)
 			}
 		}
 	}
 
-	// Afficher l'historique des cycles à la fin de la mise à jour
-	displayCyclesHistory(cycles, 0)
+	// Enregistrer la valeur du portefeuille pour la courbe du serveur de statistiques
+	if !simulating() {
+		recordPortfolioSnapshot(cfg)
+	}
+
+	// Afficher les cycles en cours et le récapitulatif par exchange à la fin de la mise à jour
+	if err := displayUpdateSummary(repo, allPrices, ""); err != nil {
+		exchangeEvent("", "update").with("error", err).fail(i18n.T("update.cycles_error"), err)
+		return
+	}
+
+	// Ordres ouverts inconnus du bot: signalés seulement, --orphans permet de les traiter
+	warnOrphanOrders(repo, exchanges, allPrices)
+
+	// Consommation des limites de requêtes des exchanges sollicités par la mise à jour
+	var queried []string
+	for _, exchangeName := range exchanges {
+		if _, skip := inMaintenance[exchangeName]; !skip && cfg.Exchanges[exchangeName].Enabled {
+			queried = append(queried, exchangeName)
+		}
+	}
+	reportRateLimits(queried, cfg.RateLimitWarnPercent)
 }
 
 // processBuyCycle traite un cycle en statut "buy" pour n'importe quel exchange
 func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, lastPrice float64) {
+	ev := cycleEvent(cycle, "buy_check").with("order_id", cycle.BuyId)
+	if cycle.Paused {
+		ev.info(i18n.T("update.cycle_paused"), cycle.IdInt, cycle.IdInt)
+		return
+	}
+
 	// Nettoyer l'ID d'ordre d'achat
 	cleanBuyId := cleanOrderId(cycle.BuyId, cycle.Exchange)
 
 	if cleanBuyId == "" {
-		color.Red("ID d'ordre d'achat invalide: %s", cycle.BuyId)
+		ev.fail(i18n.T("update.invalid_buy_id"), cycle.BuyId)
 		return
 	}
 
 	// Charger la configuration pour obtenir les paramètres spécifiques de l'exchange
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		color.Red("Erreur de configuration: %v", err)
+		ev.with("error", err).fail(i18n.T("update.config_error"), err)
 		return
 	}
 
 	// Obtenir la configuration de l'exchange pour ce cycle
 	exchangeConfig, configErr := cfg.GetExchangeConfig(cycle.Exchange)
 	if configErr != nil {
-		color.Red("Erreur lors de la récupération de la configuration de l'exchange: %v", configErr)
+		ev.with("error", configErr).fail(i18n.T("update.exchange_config_error"), configErr)
 		return
 	}
 
@@ -329,75 +389,26 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 	maxPriceDeviation := exchangeConfig.BuyMaxPriceDeviation
 
 	// Vérifier si l'ordre doit être annulé en raison de son âge
-	if maxDays > 0 {
-		age := cycle.GetAge()
-		if age >= float64(maxDays) {
-			color.Yellow("Cycle %d: L'ordre d'achat a dépassé l'âge maximal de %d jours (âge actuel: %.2f jours). Annulation...",
-				cycle.IdInt, maxDays, age)
-
-			// Annuler l'ordre avec la fonction sécurisée
-			success, err := safeOrderCancel(client, cleanBuyId, cycle.IdInt)
-
-			if !success {
-				// Si l'annulation échoue, tenter d'autres méthodes selon l'exchange
-				if cycle.Exchange == "MEXC" {
-					// Logique spécifique pour MEXC...
-					if strings.HasPrefix(cleanBuyId, "C02__") {
-						cleanId := strings.TrimPrefix(cleanBuyId, "C02__")
-						success, _ = safeOrderCancel(client, cleanId, cycle.IdInt)
-					} else {
-						prefixedId := "C02__" + cleanBuyId
-						success, _ = safeOrderCancel(client, prefixedId, cycle.IdInt)
-					}
-
-					// Dernière tentative avec l'extraction des chiffres uniquement
-					if !success {
-						re := regexp.MustCompile("[0-9]+")
-						matches := re.FindAllString(cleanBuyId, -1)
-						if len(matches) > 0 {
-							numericId := matches[0]
-							success, _ = safeOrderCancel(client, numericId, cycle.IdInt)
-						}
-					}
-				}
-
-				// Si toutes les tentatives échouent, informer l'utilisateur mais poursuivre
-				if !success {
-					color.Red("Erreur lors de l'annulation de l'ordre par âge: %v", err)
-					color.Yellow("L'ordre n'a pas pu être annulé sur l'exchange, mais le cycle sera supprimé de la base de données.")
-					color.Yellow("Vous devrez peut-être annuler manuellement l'ordre sur %s", cycle.Exchange)
-				}
-			}
-
-			// Mettre à jour le statut du cycle, MÊME SI l'annulation sur l'exchange a échoué
-			err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-				"status": "cancelled",
-			})
-			if err != nil {
-				color.Red("Erreur lors de la mise à jour du cycle: %v", err)
-			} else {
-				color.Green("Cycle %d: Ordre d'achat annulé avec succès (âge maximal dépassé)", cycle.IdInt)
-			}
+	if maxDays > 0 && cycle.GetAge() >= float64(maxDays) {
+		if cancelStaleBuy(client, repo, cycle, ev, cleanBuyId, maxDays) != staleBuyFilled {
 			return
 		}
 	}
 
-	// Récupérer l'ordre d'achat
-	orderBytes, err := client.GetOrderById(cleanBuyId)
+	// Récupérer l'état de l'ordre d'achat
+	buyStatus, err := client.GetOrderStatus(cleanBuyId)
 	if err != nil {
-		color.Red("Erreur lors de la récupération de l'ordre d'achat %s (nettoyé: %s): %v",
+		ev.with("error", err).fail(i18n.T("update.buy_order_error"),
 			cycle.BuyId, cleanBuyId, err)
 
 		// Si l'erreur suggère que l'ordre n'existe pas, mettre à jour le cycle
 		if strings.Contains(err.Error(), "404") ||
 			strings.Contains(err.Error(), "Not Found") {
-			color.Yellow("Ordre non trouvé, mise à jour potentielle du cycle")
+			ev.warn(i18n.T("update.order_not_found"))
 
-			err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-				"status": "cancelled",
-			})
+			err = markCycleCancelled(repo, cycle, database.CancelReasonOrderNotFound)
 			if err != nil {
-				color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+				ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
 			}
 			return
 		}
@@ -406,30 +417,30 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 	}
 
 	// Vérification spécifique pour MEXC qui peut signaler FILLED avant mise à jour réelle des soldes
-	if cycle.Exchange == "MEXC" && client.IsFilled(string(orderBytes)) {
+	if cycle.Exchange == "MEXC" && buyStatus.Filled() {
 		// Récupérer les soldes pour confirmer que le BTC est disponible
 		balances, balErr := client.GetDetailedBalances()
 		if balErr == nil {
-			availableBTC := balances["BTC"].Free
-			color.Yellow("MEXC: Vérification solde BTC disponible: %.8f BTC pour cycle %.8f BTC",
+			availableBTC := usableBalance(cycle.Exchange, balances, "BTC")
+			ev.info(i18n.T("update.mexc_balance_check"),
 				availableBTC, cycle.Quantity)
 
 			// Si le solde disponible est insuffisant
 			if availableBTC < cycle.Quantity*0.98 {
-				color.Yellow("MEXC: Délai de 5 secondes pour permettre la mise à jour des soldes")
+				ev.info(i18n.T("update.mexc_balance_wait"))
 				time.Sleep(5 * time.Second)
 
 				// Vérifier à nouveau après le délai
 				balances, balErr = client.GetDetailedBalances()
 				if balErr == nil {
-					availableBTC = balances["BTC"].Free
-					color.Yellow("MEXC: Après délai - Solde BTC disponible: %.8f BTC pour cycle %.8f BTC",
+					availableBTC = usableBalance(cycle.Exchange, balances, "BTC")
+					ev.info(i18n.T("update.mexc_balance_after_wait"),
 						availableBTC, cycle.Quantity)
 
 					// Si toujours insuffisant
 					if availableBTC < cycle.Quantity*0.95 {
 						// Ne pas poursuivre la création de l'ordre de vente pour ce cycle
-						color.Yellow("Cycle %d: Solde BTC disponible insuffisant (%.8f) pour vendre %.8f BTC. L'ordre semble ne pas être réellement exécuté.",
+						ev.warn(i18n.T("update.mexc_balance_short"),
 							cycle.IdInt, availableBTC, cycle.Quantity)
 						return
 					}
@@ -438,8 +449,26 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 		}
 	}
 
+	// Achat expiré par l'exchange sans exécution (USE_EXCHANGE_EXPIRY): le cycle est annulé
+	if buyStatus.Expired && buyStatus.ExecutedQty <= 0 {
+		ev = ev.with("action", "buy_expired")
+		if err := markCycleCancelled(repo, cycle, database.CancelReasonExpired); err != nil {
+			ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		} else {
+			ev.success(i18n.T("update.buy_expired"), cycle.IdInt)
+			ev.notify(cycle, "Cycle %d: ordre d'achat expiré sur l'exchange", cycle.IdInt)
+		}
+		return
+	}
+
 	// Vérifier si l'ordre n'est PAS rempli
-	if !client.IsFilled(string(orderBytes)) {
+	if !buyStatus.Filled() {
+		// Un prix non fiable ne peut pas justifier une annulation
+		if maxPriceDeviation > 0 && !priceTrusted(cycle.Exchange) {
+			ev.warn(i18n.T("update.buy_deviation_untrusted"), cycle.IdInt, cycle.Exchange)
+			return
+		}
+
 		// Vérifier si l'ordre devrait être annulé en raison de la déviation de prix
 		if maxPriceDeviation > 0 {
 			// Calculer le seuil d'annulation basé sur le pourcentage configuré
@@ -447,25 +476,51 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 			cancelThreshold := cycle.BuyPrice * deviationFactor
 
 			if lastPrice > cancelThreshold {
-				color.Yellow("Cycle %d: Le prix actuel %.2f dépasse le seuil d'annulation (%.2f, déviation configurée: %.2f%%). Annulation de l'ordre...",
+				ev = ev.with("action", "cancel_buy_deviation").with("price", lastPrice)
+				ev.warn(i18n.T("update.buy_deviation_exceeded"),
 					cycle.IdInt, lastPrice, cancelThreshold, maxPriceDeviation)
 
 				// Utiliser la fonction sécurisée
-				success, err := safeOrderCancel(client, cleanBuyId, cycle.IdInt)
+				result, err := safeOrderCancel(client, cleanBuyId, cycle.IdInt)
 
-				if !success {
-					color.Red("Erreur lors de l'annulation de l'ordre par déviation de prix: %v", err)
+				if !result.Closed() {
+					ev.with("error", err).fail(i18n.T("update.cancel_deviation_error"), err)
+					recordCancelFailure(repo, cycle, ev, database.CancelReasonPriceDeviation, err)
 					return
 				}
+				if result == common.AlreadyGone && orderFilled(client, cleanBuyId) {
+					// Exécuté entre la vérification et l'annulation: la prochaine mise à jour placera la vente
+					ev.warn(i18n.T("update.buy_filled_before_cancel"), cycle.IdInt)
+					return
+				}
+
+				// Replacer l'achat sous le prix actuel plutôt que d'annuler le cycle, dans la limite de MAX_REPRICES
+				if exchangeConfig.RepriceInsteadOfCancel {
+					if lossLimitBlocks(cycle.Exchange) {
+						ev.warn(i18n.T("update.loss_limit_reprice_blocked"), cycle.IdInt, cycle.Exchange)
+					} else if cycle.RepriceCount < exchangeConfig.MaxReprices {
+						previousPrice := cycle.BuyPrice
+						if err := repriceBuyOrder(client, repo, cycle, lastPrice, exchangeConfig); err != nil {
+							ev.with("error", err).fail(i18n.T("update.reprice_failed"), cycle.IdInt, err)
+						} else {
+							rev := ev.with("action", "reprice_buy").with("order_id", cycle.BuyId).with("price", cycle.BuyPrice)
+							rev.success(i18n.T("update.repriced"),
+								cycle.IdInt, cycle.BuyPrice, previousPrice, cycle.SellPrice, cycle.RepriceCount, exchangeConfig.MaxReprices)
+							rev.notify(cycle, "Cycle %d: ordre d'achat replacé de %.2f à %.2f USDC", cycle.IdInt, previousPrice, cycle.BuyPrice)
+							return
+						}
+					} else {
+						ev.warn(i18n.T("update.reprice_limit"), cycle.IdInt, exchangeConfig.MaxReprices)
+					}
+				}
 
 				// Mettre à jour le statut du cycle
-				err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-					"status": "cancelled",
-				})
+				err = markCycleCancelled(repo, cycle, database.CancelReasonPriceDeviation)
 				if err != nil {
-					color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+					ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
 				} else {
-					color.Green("Cycle %d: Ordre d'achat annulé avec succès (déviation de prix maximale dépassée)", cycle.IdInt)
+					ev.success(i18n.T("update.buy_cancelled_deviation"), cycle.IdInt)
+					ev.notify(cycle, "Cycle %d: ordre d'achat annulé (prix %.2f au-delà du seuil de %.2f)", cycle.IdInt, lastPrice, cancelThreshold)
 				}
 				return
 			}
@@ -473,126 +528,248 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 		return
 	}
 
+	// La vente sera placée à la prochaine mise à jour qui suit la levée de la limite de pertes
+	if lossLimitBlocks(cycle.Exchange) {
+		ev.with("action", "loss_limit").warn(i18n.T("update.loss_limit_sell_blocked"), cycle.IdInt, cycle.Exchange)
+		return
+	}
+
+	// Le prix de vente dépend du prix actuel: la vente attend une mise à jour au prix fiable
+	if !priceTrusted(cycle.Exchange) {
+		ev.with("action", "price_untrusted").warn(i18n.T("update.sell_price_untrusted"), cycle.IdInt, cycle.Exchange)
+		return
+	}
+
 	// === L'ORDRE EST REMPLI, RÉCUPÉRER LES FRAIS D'ACHAT DE FAÇON PRÉCISE ===
-	color.Green("Cycle %d: Ordre d'achat exécuté", cycle.IdInt)
+	ev = ev.with("action", "buy_filled").with("price", cycle.BuyPrice)
+	ev.success(i18n.T("update.buy_filled"), cycle.IdInt)
+	ev.notify(cycle, "Cycle %d: achat de %.8f BTC exécuté à %.2f USDC", cycle.IdInt, cycle.Quantity, cycle.BuyPrice)
+	captureOrderSnapshot(client, repo, cycle, ev, database.OrderSnapshotBuy, cleanBuyId)
 
 	// Récupérer les frais d'achat réels
 	var buyFees float64
 	// Tenter de récupérer les frais avec la méthode publique GetOrderFees
 	buyFees, err = client.GetOrderFees(cleanBuyId)
-	if err != nil {
+	if err != nil && buyStatus.Fee != 0 {
+		// Frais fournis avec l'ordre par l'exchange
+		buyFees = buyStatus.Fee
+		logFees(ev, "update.buy_fees", "update.buy_rebate", buyFees)
+	} else if err != nil {
 		// Si on ne peut pas récupérer les frais, estimer avec le taux par défaut
 		feeRate := getFeeRateForExchange(cycle.Exchange)
 		buyFees = cycle.BuyPrice * cycle.Quantity * feeRate
-		color.Yellow("Impossible de récupérer les frais d'achat, estimation selon le taux standard: %.8f USDC (taux: %.4f%%)",
+		cycle.FeesEstimated = true
+		ev.warn(i18n.T("update.buy_fees_estimated"),
 			buyFees, feeRate*100)
 	} else {
-		color.Green("Frais d'achat récupérés: %.8f USDC", buyFees)
+		logFees(ev, "update.buy_fees", "update.buy_rebate", buyFees)
+	}
+	if buyStatus.FeeAssetAmount > 0 {
+		ev.info(i18n.T("update.fee_asset"), cycle.IdInt, buyStatus.FeeAssetAmount, buyStatus.FeeAsset)
 	}
 
-	// Extraire la quantité réellement exécutée depuis l'API
-	var executedQty float64 = 0
-
-	switch cycle.Exchange {
-	case "MEXC":
-		// Format de réponse pour MEXC
-		executedQtyStr, err := jsonparser.GetString(orderBytes, "executedQty")
-		if err == nil && executedQtyStr != "" {
-			parsedQty, parseErr := strconv.ParseFloat(executedQtyStr, 64)
-			if parseErr == nil && parsedQty > 0 {
-				executedQty = parsedQty
-				color.Yellow("MEXC: Quantité exécutée extraite de l'API: %.8f BTC", executedQty)
-			}
-		}
-
-	case "BINANCE":
-		executedQtyStr, err := jsonparser.GetString(orderBytes, "executedQty")
-		if err == nil && executedQtyStr != "" {
-			parsedQty, parseErr := strconv.ParseFloat(executedQtyStr, 64)
-			if parseErr == nil && parsedQty > 0 {
-				executedQty = math.Floor(parsedQty*100000000) / 100000000
-				color.Yellow("BINANCE: Quantité exécutée extraite de l'API: %.8f BTC", executedQty)
-			}
-		}
-
-	case "KUCOIN":
-		// Format de réponse pour KuCoin
-		dealSizeStr, err := jsonparser.GetString(orderBytes, "dealSize")
-		if err == nil && dealSizeStr != "" {
-			parsedQty, parseErr := strconv.ParseFloat(dealSizeStr, 64)
-			if parseErr == nil && parsedQty > 0 {
-				executedQty = parsedQty
-				color.Yellow("KUCOIN: Quantité exécutée extraite de l'API: %.8f BTC", executedQty)
-			}
-		}
+	// Quantité réellement exécutée selon l'exchange
+	executedQty := buyStatus.ExecutedQty
+	if executedQty > 0 {
+		ev.info(i18n.T("update.executed_qty"), cycle.Exchange, executedQty)
+	}
 
-	case "KRAKEN":
-		// Format de réponse pour Kraken
-		var volExecStr string
-		volExecStr, _ = jsonparser.GetString(orderBytes, "vol_exec")
-		if volExecStr == "" {
-			volExecStr, _ = jsonparser.GetString(orderBytes, "executed")
-		}
+	// Prix moyen réellement exécuté (souvent meilleur que le prix limite sur Kraken et KuCoin)
+	if fillPrice := buyStatus.AvgFillPrice; fillPrice > 0 {
+		cycle.BuyFillPrice = fillPrice
+		ev.info(i18n.T("update.buy_fill_price"), cycle.IdInt, fillPrice, cycle.BuyPrice)
+	}
 
-		if volExecStr != "" {
-			parsedQty, parseErr := strconv.ParseFloat(volExecStr, 64)
-			if parseErr == nil && parsedQty > 0 {
-				executedQty = parsedQty
-				color.Yellow("KRAKEN: Quantité exécutée extraite de l'API: %.8f BTC", executedQty)
-			}
-		}
+	// Frais prélevés en BTC sur la quantité achetée (Binance sans paiement en BNB): cette part
+	// ne peut pas être revendue. Sa valeur en USDC est déjà comptée dans buyFees.
+	buyFeeBTC := buyStatus.BaseFee
+	if buyFeeBTC > 0 && buyFeeBTC < executedQty {
+		ev.info(i18n.T("update.buy_fee_btc"), cycle.IdInt, buyFeeBTC)
+	} else {
+		buyFeeBTC = 0
 	}
 
-	// Si nous avons pu extraire une quantité valide et différente de la quantité initiale, mettre à jour
-	if executedQty > 0 && math.Abs(executedQty-cycle.Quantity)/cycle.Quantity > 0.0005 && cycle.Exchange != "BINANCE" {
-		color.Yellow("Cycle %d: Mise à jour de la quantité de %.8f BTC à %.8f BTC (d'après l'API)",
-			cycle.IdInt, cycle.Quantity, executedQty)
+	// Si nous avons pu extraire une quantité valide et différente de la quantité initiale, ou
+	// diminuée des frais prélevés en BTC, mettre à jour
+	if executedQty > 0 && (buyFeeBTC > 0 || math.Abs(executedQty-cycle.Quantity)/cycle.Quantity > 0.0005 && cycle.Exchange != "BINANCE") {
+		heldQty := executedQty - buyFeeBTC
+		ev.info(i18n.T("update.quantity_updated"),
+			cycle.IdInt, cycle.Quantity, heldQty)
 
-		// Calculer le montant d'achat précis (prix * quantité)
-		purchaseAmountUSDC := cycle.BuyPrice * executedQty
+		// Calculer le montant d'achat précis (montant exécuté, ou prix exécuté * quantité), limité
+		// à la quantité détenue: la part prélevée en frais est comptée dans buyFees
+		purchaseAmountUSDC := filledAmount(buyStatus, cycle.EffectiveBuyPrice(), executedQty) * heldQty / executedQty
 
 		// Mettre à jour la quantité et stocker les frais dans la base de données
 		err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-			"quantity":           executedQty,
-			"buyFees":            buyFees,            // Nouveau: stocker les frais d'achat dans un champ dédié
+			"quantity":           heldQty,
+			"buyFees":            buyFees,            // Frais d'achat, relus à la complétion du cycle
+			"buyFeeBTC":          buyFeeBTC,          // Part des frais d'achat prélevée en BTC
 			"totalFees":          buyFees,            // Initialiser totalFees avec buyFees
 			"purchaseAmountUSDC": purchaseAmountUSDC, // Stocker le montant exact d'achat
+			"buyFillPrice":       cycle.BuyFillPrice,
+			"feesEstimated":      cycle.FeesEstimated,
+			"buyFeeAsset":        buyStatus.FeeAsset,       // Frais payés en BNB, tels que prélevés
+			"buyFeeAssetAmount":  buyStatus.FeeAssetAmount, // (comptés en USDC dans buyFees)
 		})
 
 		if err != nil {
-			color.Red("Erreur lors de la mise à jour de la quantité et des frais: %v", err)
+			ev.with("error", err).fail(i18n.T("update.quantity_fees_update_error"), err)
 		} else {
 			// Mettre à jour l'objet cycle local pour la suite du traitement
-			cycle.Quantity = executedQty
+			cycle.Quantity = heldQty
+			cycle.BuyFeeBTC = buyFeeBTC
+			cycle.BuyFees = buyFees
 			cycle.TotalFees = buyFees
 			cycle.PurchaseAmountUSDC = purchaseAmountUSDC
+			cycle.BuyFeeAsset, cycle.BuyFeeAssetAmount = buyStatus.FeeAsset, buyStatus.FeeAssetAmount
 		}
 	} else {
 		// Si la quantité reste inchangée, mettre à jour uniquement les frais
-		// Calculer le montant d'achat précis (prix * quantité)
-		purchaseAmountUSDC := cycle.BuyPrice * cycle.Quantity
+		// Calculer le montant d'achat précis (montant exécuté, ou prix exécuté * quantité)
+		purchaseAmountUSDC := filledAmount(buyStatus, cycle.EffectiveBuyPrice(), cycle.Quantity)
 
 		err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-			"buyFees":            buyFees,            // Nouveau: stocker les frais d'achat dans un champ dédié
+			"buyFees":            buyFees,            // Frais d'achat, relus à la complétion du cycle
 			"totalFees":          buyFees,            // Initialiser totalFees avec buyFees
 			"purchaseAmountUSDC": purchaseAmountUSDC, // Stocker le montant exact d'achat
+			"buyFillPrice":       cycle.BuyFillPrice,
+			"feesEstimated":      cycle.FeesEstimated,
+			"buyFeeAsset":        buyStatus.FeeAsset,       // Frais payés en BNB, tels que prélevés
+			"buyFeeAssetAmount":  buyStatus.FeeAssetAmount, // (comptés en USDC dans buyFees)
 		})
 
 		if err != nil {
-			color.Red("Erreur lors de la mise à jour des frais: %v", err)
+			ev.with("error", err).fail(i18n.T("update.fees_update_error"), err)
 		} else {
+			cycle.BuyFees = buyFees
 			cycle.TotalFees = buyFees
 			cycle.PurchaseAmountUSDC = purchaseAmountUSDC
+			cycle.BuyFeeAsset, cycle.BuyFeeAssetAmount = buyStatus.FeeAsset, buyStatus.FeeAssetAmount
 		}
 	}
 
-	// ========= CALCUL DU PRIX DE VENTE =========
+	finalSellPrice := computeSellPrice(client, cycle, ev, exchangeConfig, lastPrice, buyFees, cleanBuyId)
+
+	// Calculer le montant de vente prévu
+	saleAmountUSDC := finalSellPrice * cycle.Quantity
+
+	// Mise à jour du prix de vente et du montant de vente prévu dans la base de données
+	err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+		"sellPrice":      finalSellPrice,
+		"saleAmountUSDC": saleAmountUSDC, // Nouveau: stocker le montant exact de vente prévu
+	})
+
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.sell_price_update_error"), err)
+		return
+	}
+
+	// Mettre à jour l'objet cycle local
+	cycle.SellPrice = finalSellPrice
+	cycle.SaleAmountUSDC = saleAmountUSDC
+
+	// Vérifier le solde BTC disponible
+	balances, balErr := client.GetDetailedBalances()
+	if balErr != nil {
+		ev.with("error", balErr).fail(i18n.T("update.balance_error"), balErr)
+		return
+	}
+
+	// Vérifier que le BTC est réellement disponible, sans entamer la réserve
+	availableBTC := usableBalance(cycle.Exchange, balances, "BTC")
+
+	// Ajuster la quantité si nécessaire: la quantité détenue (frais en BTC déduits), dans la
+	// limite du solde libre
+	quantityToSell := cycle.Quantity
+	if availableBTC < quantityToSell && availableBTC > quantityToSell*0.95 {
+		ev.info(i18n.T("update.sell_qty_adjusted"),
+			cycle.IdInt, quantityToSell, availableBTC)
+		quantityToSell = availableBTC
+	}
+
+	// Préparer les paramètres de l'ordre de vente: quantité arrondie au pas inférieur
+	quantityStr := client.FormatQuantity(quantityToSell)
+	if rounded, err := strconv.ParseFloat(quantityStr, 64); err == nil && rounded > 0 {
+		quantityToSell = rounded
+	}
+	if quantityToSell != cycle.Quantity {
+		// Mettre à jour le montant de vente prévu avec la nouvelle quantité
+		saleAmountUSDC = finalSellPrice * quantityToSell
+		cycle.SaleAmountUSDC = saleAmountUSDC
+	}
+
+	oco, stopLimitPrice, err := placeOCOSell(client, cycle, ev, exchangeConfig, finalSellPrice, lastPrice, quantityStr)
+	if err != nil {
+		return
+	}
+
+	orderIdStr, placedPrice, sellClientOrderId := oco.LimitID, finalSellPrice, ""
+	if orderIdStr == "" {
+		sellClientOrderId = common.ClientOrderID(cycle.IdInt, "sell")
+		orderIdStr, placedPrice, err = placeLimitSell(client, repo, cycle, ev, finalSellPrice, quantityStr, sellClientOrderId)
+		if err != nil {
+			// L'achat est exécuté: la vente est retentée par processSellCycle plutôt que par un nouveau passage ici
+			recordSellFailure(repo, cycle, ev, sellClientOrderId, err)
+			return
+		}
+	}
+	ev = ev.with("action", "place_sell").with("price", placedPrice)
+
+	if placedPrice != finalSellPrice {
+		ev.info(i18n.T("update.post_only_moved"),
+			cycle.IdInt, placedPrice, finalSellPrice)
+		finalSellPrice = placedPrice
+		cycle.SellPrice = placedPrice
+		cycle.SaleAmountUSDC = placedPrice * quantityToSell
+	}
+
+	// Mettre à jour le cycle
+	err = repo.MarkSellPlaced(cycle.IdInt, database.SellOrder{
+		OrderId:        orderIdStr,
+		ClientOrderId:  sellClientOrderId,
+		Price:          finalSellPrice,
+		SaleAmountUSDC: cycle.SaleAmountUSDC,
+		StopId:         oco.StopID,
+		StopPrice:      stopLimitPrice,
+	})
+	ev = ev.with("order_id", orderIdStr)
+	if err != nil {
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
+		return
+	}
+
+	// Calculer et afficher le profit potentiel
+	profitPercent := ((finalSellPrice - cycle.BuyPrice) / cycle.BuyPrice) * 100
+	ev.success(i18n.T("update.sell_placed"), cycle.IdInt, orderIdStr)
+	ev.success(i18n.T("update.sell_placed_prices"),
+		cycle.IdInt, cycle.BuyPrice, finalSellPrice, profitPercent)
+	ev.success(i18n.T("update.sell_placed_fees"), cycle.IdInt, buyFees)
+
+	cycle.Status = "sell"
+	cycle.SellId = orderIdStr
+	cycle.SellClientOrderId = sellClientOrderId
+	cycle.SellRetryCount, cycle.SellRetryError, cycle.SellRetryAt = 0, "", time.Time{}
+	if oco.StopID != "" {
+		cycle.StopId = oco.StopID
+		cycle.StopPrice = stopLimitPrice
+	}
+	ev.notify(cycle, "Cycle %d: ordre de vente placé à %.2f USDC (profit potentiel: %.2f%%)", cycle.IdInt, finalSellPrice, profitPercent)
+}
+
+// computeSellPrice calcule le prix de vente d'un cycle dont l'achat est exécuté: le plus élevé du
+// prix standard (BuyPrice + SELL_OFFSET, ou SELL_OFFSET_PERCENT % du prix d'achat), du prix minimum pour rester maker et du prix couvrant les
+// frais, relevé au profit net minimum puis contrôlé contre le carnet
+func computeSellPrice(client common.Exchange, cycle *database.Cycle, ev *tradeEvent, exchangeConfig config.ExchangeConfig,
+	lastPrice, buyFees float64, cleanBuyId string) float64 {
 	// 1. Prix de vente standard basé sur la configuration
-	sellOffset := exchangeConfig.SellOffset
+	sellOffset := exchangeConfig.SellOffsetAt(cycle.BuyPrice)
 	standardSellPrice := cycle.BuyPrice + sellOffset
 
-	// 2. Prix minimum pour être maker (légèrement au-dessus du prix actuel)
-	makerMinPrice := lastPrice * 1.001
+	// 2. Prix minimum pour être maker (légèrement au-dessus du prix actuel).
+	// Le prix de vente est envoyé avec 2 décimales: l'écart vaut au moins 0.01
+	makerMinPrice := lastPrice + common.MakerPriceOffset(lastPrice,
+		exchangeConfig.MakerBufferPercent, common.DefaultMakerSellBufferPercent, 0.01)
 
 	// 3. Prix ajusté pour couvrir les frais
 	var feeAdjustedPrice float64
@@ -602,11 +779,11 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 	adjustedPrice, err := client.AdjustSellPriceForFees(cycle.BuyPrice, cycle.Quantity, cleanBuyId)
 	if err == nil {
 		feeAdjustedPrice = adjustedPrice
-		color.Yellow("Cycle %d: Prix de vente ajusté pour les frais via API: %.2f USDC",
+		ev.info(i18n.T("update.sell_price_api"),
 			cycle.IdInt, feeAdjustedPrice)
 	} else {
 		// En cas d'erreur, on retombe sur l'estimation des frais
-		color.Yellow("Erreur lors de l'ajustement du prix via API: %v, utilisation de l'estimation", err)
+		ev.with("error", err).warn(i18n.T("update.sell_price_api_error"), err)
 
 		// Estimer les frais selon l'exchange
 		var feeRate float64 = getFeeRateForExchange(cycle.Exchange)
@@ -617,20 +794,15 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 		// Total des frais estimés (achat déjà récupéré + vente estimée)
 		totalFeesEstimated := buyFees + estimatedSellFees
 
-		// Ajouter une marge de sécurité
+		// Prix minimum pour couvrir les frais estimés, avec une marge de sécurité qui ne
+		// majore pas une remise maker
+		safetyMargin := 0.05
 		if cycle.Exchange == "KRAKEN" {
-			totalFeesEstimated *= 1.1
-		} else {
-			totalFeesEstimated *= 1.05
+			safetyMargin = 0.1
 		}
+		feeAdjustedPrice = common.FeeAdjustedSellPrice(cycle.BuyPrice, cycle.Quantity, buyFees, estimatedSellFees, safetyMargin)
 
-		// Calculer l'ajustement par unité
-		feeAdjustmentPerUnit := totalFeesEstimated / cycle.Quantity
-
-		// Prix minimum pour couvrir les frais estimés
-		feeAdjustedPrice = cycle.BuyPrice + feeAdjustmentPerUnit
-
-		color.Yellow("Cycle %d: Prix de vente ajusté pour frais estimés: %.2f USDC (frais estimés: %.8f USDC)",
+		ev.info(i18n.T("update.sell_price_estimated"),
 			cycle.IdInt, feeAdjustedPrice, totalFeesEstimated)
 	}
 
@@ -640,177 +812,173 @@ func processBuyCycle(client common.Exchange, repo *database.CycleRepository, cyc
 	// a) Si le prix ajusté pour les frais est le plus élevé
 	if feeAdjustedPrice >= standardSellPrice && feeAdjustedPrice >= makerMinPrice {
 		finalSellPrice = feeAdjustedPrice
-		color.Yellow("Cycle %d: Prix de vente déterminé par les frais: %.2f USDC", cycle.IdInt, finalSellPrice)
+		ev.info(i18n.T("update.sell_price_fees"), cycle.IdInt, finalSellPrice)
 	} else if makerMinPrice >= standardSellPrice && makerMinPrice >= feeAdjustedPrice {
 		// b) Si le prix maker minimum est le plus élevé
 		finalSellPrice = makerMinPrice
-		color.Yellow("Cycle %d: Prix de vente déterminé pour être maker: %.2f USDC", cycle.IdInt, finalSellPrice)
+		ev.info(i18n.T("update.sell_price_maker"), cycle.IdInt, finalSellPrice)
 	} else {
 		// c) Si le prix standard est le plus élevé
 		finalSellPrice = standardSellPrice
-		color.Yellow("Cycle %d: Prix de vente standard utilisé: %.2f USDC", cycle.IdInt, finalSellPrice)
-	}
-
-	// Calculer le montant de vente prévu
-	saleAmountUSDC := finalSellPrice * cycle.Quantity
-
-	// Mise à jour du prix de vente et du montant de vente prévu dans la base de données
-	err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-		"sellPrice":      finalSellPrice,
-		"saleAmountUSDC": saleAmountUSDC, // Nouveau: stocker le montant exact de vente prévu
-	})
-
-	if err != nil {
-		color.Red("Erreur lors de la mise à jour du prix de vente: %v", err)
-		return
+		ev.info(i18n.T("update.sell_price_standard"), cycle.IdInt, finalSellPrice)
 	}
 
-	// Mettre à jour l'objet cycle local
-	cycle.SellPrice = finalSellPrice
-	cycle.SaleAmountUSDC = saleAmountUSDC
+	// 5. Profit net minimum (MIN_PROFIT_USDC / MIN_PROFIT_PERCENT), qui sert aussi de plancher au contrôle du carnet
+	finalSellPrice, floorPrice := applyMinProfit(cycle, ev, exchangeConfig, finalSellPrice, feeAdjustedPrice, buyFees)
 
-	// Vérifier le solde BTC disponible
-	balances, balErr := client.GetDetailedBalances()
-	if balErr != nil {
-		color.Red("Erreur lors de la récupération des soldes: %v", balErr)
-		return
-	}
-
-	// Vérifier que le BTC est réellement disponible
-	availableBTC := balances["BTC"].Free
+	// 6. Contrôle du carnet: écart achat/vente et prix trop éloigné de la meilleure vente
+	finalSellPrice = checkSellAgainstBook(client, cycle, ev, finalSellPrice, floorPrice)
 
-	// Ajuster la quantité si nécessaire
-	quantityToSell := cycle.Quantity
-	if availableBTC < quantityToSell && availableBTC > quantityToSell*0.95 {
-		color.Yellow("Cycle %d: Ajustement de la quantité à vendre de %.8f à %.8f (disponible)",
-			cycle.IdInt, quantityToSell, availableBTC)
-		quantityToSell = availableBTC
-
-		// Mettre à jour le montant de vente prévu avec la nouvelle quantité
-		saleAmountUSDC = finalSellPrice * quantityToSell
-		cycle.SaleAmountUSDC = saleAmountUSDC
-	}
+	// 7. Arrondi au pas de prix de l'exchange, sans descendre sous le plancher des frais
+	return common.RoundSellPrice(finalSellPrice, floorPrice, client.Precision().PriceTick)
+}
 
-	if cycle.Exchange == "BINANCE" {
-		quantityToSell = executedQty
-		color.Yellow("Cycle %d: Utilisation de la quantité exacte achetée: %.8f BTC",
-			cycle.IdInt, quantityToSell)
+// placeLimitSell place la vente d'un cycle en ordre limite et retourne l'ID de l'ordre et le
+// prix réellement utilisé. Une vente portant déjà l'identifiant client, placée avant une
+// interruption, est reprise. L'erreur, déjà journalisée, indique que l'ordre n'a pas pu être créé.
+func placeLimitSell(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, ev *tradeEvent,
+	price float64, quantityStr, clientOrderID string) (string, float64, error) {
+	if order, found := findClientOrder(client, clientOrderID); found {
+		return order.ID, order.Price, nil
 	}
 
-	// Préparer les paramètres de l'ordre de vente
-	quantityStr := strconv.FormatFloat(quantityToSell, 'f', 8, 64)
-	sellPriceStr := strconv.FormatFloat(finalSellPrice, 'f', 2, 64)
-
-	// Créer l'ordre de vente
-	sellBytes, err := client.CreateOrder("SELL", sellPriceStr, quantityStr)
+	// Créer l'ordre de vente (post-only si activé: le prix peut être relevé d'un ou plusieurs ticks)
+	created, placedPrice, err := createLimitOrder(client, cycle.Exchange, "SELL", price, quantityStr, clientOrderID)
+	ev = ev.with("action", "place_sell").with("price", placedPrice)
 
 	// Gestion améliorée pour Kraken
 	if err != nil {
 		// Cas spécial pour Kraken: vérifier si l'ordre a été créé malgré l'erreur
 		if cycle.Exchange == "KRAKEN" && strings.Contains(err.Error(), "Insufficient funds") {
-			color.Yellow("Kraken a signalé 'fonds insuffisants', vérification si l'ordre a été créé malgré l'erreur...")
+			ev.warn(i18n.T("update.kraken_insufficient_funds"))
 			time.Sleep(10 * time.Second)
 		}
 
-		color.Red("Erreur lors de la création de l'ordre de vente: %v", err)
+		ev.with("error", err).fail(i18n.T("update.sell_order_error"), err)
 
 		// Si l'erreur est de type "Oversold", donner des instructions spécifiques
 		if strings.Contains(strings.ToLower(err.Error()), "oversold") {
-			color.Yellow("Erreur de type 'Oversold': Cela signifie que vous essayez de vendre plus que ce qui est disponible.")
-			color.Yellow("Vérifiez les points suivants:")
-			color.Yellow("1. Vérifiez si l'ordre de vente n'a pas déjà été créé sur la plateforme")
-			color.Yellow("2. Vérifiez si les fonds sont bien disponibles et non verrouillés")
-			color.Yellow("3. Attendez quelques minutes pour que les soldes se mettent à jour")
-
-			// Mettre quand même à jour le statut pour éviter de perdre l'information que l'achat est complété
-			err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-				"status": "sell",
-				// Pas de SellId car l'ordre n'a pas été créé
-			})
-			if err != nil {
-				color.Red("Erreur lors de la mise à jour du cycle: %v", err)
-			} else {
-				color.Yellow("Cycle %d: Statut mis à jour à 'sell' mais l'ordre de vente n'a pas pu être créé", cycle.IdInt)
-			}
+			ev.warn(i18n.T("update.oversold"))
+			ev.warn(i18n.T("update.oversold_check"))
+			ev.warn(i18n.T("update.oversold_check1"))
+			ev.warn(i18n.T("update.oversold_check2"))
+			ev.warn(i18n.T("update.oversold_check3"))
 		}
 
-		return
+		return "", placedPrice, err
 	}
 
-	// Extraire l'ID de l'ordre de vente
-	orderIdValue, dataType, _, err := jsonparser.Get(sellBytes, "orderId")
-	if err != nil {
-		color.Red("Erreur lors de l'extraction de l'ID d'ordre: %v", err)
-		color.Red("Réponse API complète: %s", string(sellBytes))
-		return
+	return created.OrderID, placedPrice, nil
+}
+
+// filledAmount retourne le montant USDC exécuté d'un ordre: celui de l'exchange s'il le fournit
+// (cummulativeQuoteQty sur Binance), sinon le prix multiplié par la quantité
+func filledAmount(status common.OrderStatus, price, quantity float64) float64 {
+	if status.QuoteAmount > 0 {
+		return status.QuoteAmount
 	}
+	return price * quantity
+}
+
+// ocoStopPrices calcule le prix de déclenchement du stop de protection d'une vente OCO,
+// sous le prix d'achat, et sa limite, sous le déclenchement
+func ocoStopPrices(buyPrice float64, exchangeConfig config.ExchangeConfig) (stopPrice, stopLimitPrice float64) {
+	stopPrice = buyPrice * (1 - exchangeConfig.OCOStopLossPercent/100)
+	stopLimitPrice = stopPrice * (1 - exchangeConfig.OCOStopLimitPercent/100)
+	return math.Round(stopPrice*100) / 100, math.Round(stopLimitPrice*100) / 100
+}
 
-	// Conversion selon le type de données
-	var orderIdStr string
-	switch dataType {
-	case jsonparser.String:
-		orderIdStr = string(orderIdValue)
-	case jsonparser.Number:
-		orderIdStr = string(orderIdValue)
-	default:
-		orderIdStr = string(orderIdValue)
-		color.Yellow("Type de données inattendu pour l'ID d'ordre: %v", dataType)
+// placeOCOSell place la vente d'un achat exécuté en OCO si USE_OCO est activé: la vente limite est
+// accompagnée d'un stop-limit de protection. Un OCO vide est retourné sans erreur pour un repli
+// sur un ordre limite simple, si l'exchange ne le supporte pas ou si le stop serait déjà déclenché.
+func placeOCOSell(client common.Exchange, cycle *database.Cycle, ev *tradeEvent, exchangeConfig config.ExchangeConfig,
+	sellPrice, lastPrice float64, quantityStr string) (common.OCOOrder, float64, error) {
+	if !exchangeConfig.UseOCO {
+		return common.OCOOrder{}, 0, nil
 	}
+	stopPrice, stopLimit := ocoStopPrices(cycle.BuyPrice, exchangeConfig)
+	if stopPrice >= lastPrice {
+		ev.warn(i18n.T("update.oco_stop_above_market"), cycle.IdInt, stopPrice, lastPrice)
+		return common.OCOOrder{}, 0, nil
+	}
+	oco, err := client.CreateOCOOrder(sellPrice, stopPrice, stopLimit, quantityStr)
+	if errors.Is(err, common.ErrOCONotSupported) {
+		ev.warn(i18n.T("update.oco_unsupported"), cycle.IdInt, cycle.Exchange)
+		return common.OCOOrder{}, 0, nil
+	}
+	if err != nil {
+		ev.with("action", "place_sell").with("error", err).fail(i18n.T("update.oco_error"), err)
+		return common.OCOOrder{}, 0, err
+	}
+	ev.info(i18n.T("update.oco_placed"), cycle.IdInt, sellPrice, stopPrice, stopLimit)
+	return oco, stopLimit, nil
+}
 
-	// Vérification supplémentaire pour s'assurer que l'ID n'est pas vide
-	if orderIdStr == "" {
-		color.Red("ID d'ordre vide obtenu de la réponse API")
-		color.Red("Réponse API complète: %s", string(sellBytes))
+// processSellCycle suit la vente d'un cycle. lastPrice est le prix de l'exécution, contrôlé par
+// guardPrices: il n'est pas relu, un second relevé pourrait être aberrant.
+func processSellCycle(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle, lastPrice float64) {
+	ev := cycleEvent(cycle, "sell_check").with("order_id", cycle.SellId)
+	if cycle.Paused {
+		ev.info(i18n.T("update.cycle_paused"), cycle.IdInt, cycle.IdInt)
 		return
 	}
 
-	// Mettre à jour le cycle
-	err = repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
-		"status": "sell",
-		"sellId": orderIdStr,
-	})
-	if err != nil {
-		color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+	// Achat exécuté mais vente jamais placée (solde pas encore crédité...): nouvelle tentative
+	if sellPending(cycle) {
+		retryPendingSell(client, repo, cycle, lastPrice)
 		return
 	}
 
-	// Calculer et afficher le profit potentiel
-	profitPercent := ((finalSellPrice - cycle.BuyPrice) / cycle.BuyPrice) * 100
-	color.Green("Cycle %d: Ordre de vente placé avec succès. ID: %s", cycle.IdInt, orderIdStr)
-	color.Green("Cycle %d: Prix d'achat: %.2f, Prix de vente: %.2f, Profit potentiel: %.2f%%",
-		cycle.IdInt, cycle.BuyPrice, finalSellPrice, profitPercent)
-	color.Green("Cycle %d: Frais d'achat: %.8f USDC", cycle.IdInt, buyFees)
-}
+	// Moyenne à la baisse en cours (--average-down): achat supplémentaire, puis fusion et nouvelle vente
+	if cycle.AverageDownState != "" {
+		advanceAverageDown(client, repo, cycle, lastPrice)
+	}
 
-func processSellCycle(client common.Exchange, repo *database.CycleRepository, cycle *database.Cycle) {
 	// Obtenir le repository d'accumulation
 	accuRepo := database.GetAccumulationRepository()
 
 	// Obtenir la configuration de l'exchange
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
-		color.Red("Erreur lors du chargement de la configuration: %v", err)
+		ev.with("error", err).fail(i18n.T("update.config_load_error"), err)
 		return
 	}
 
 	exchangeConfig, err := cfg.GetExchangeConfig(cycle.Exchange)
 	if err != nil {
-		color.Red("Erreur lors de la récupération de la configuration de l'exchange: %v", err)
+		ev.with("error", err).fail(i18n.T("update.exchange_config_error"), err)
 		return
 	}
 
-	// Obtenir le prix actuel du BTC
-	currentPrice := client.GetLastPriceBTC()
+	// Prix actuel du BTC, celui de l'exécution
+	currentPrice := lastPrice
 	// Vérifier les conditions d'accumulation
 	shouldAccumulate, deviationPercent, err := checkAccumulationConditions(cycle, currentPrice, exchangeConfig, accuRepo)
 	if err != nil {
-		color.Red("Erreur lors de la vérification des conditions d'accumulation: %v", err)
+		ev.with("error", err).fail(i18n.T("update.accumulation_check_error"), err)
+	}
+
+	// Pas d'accumulation sur un prix non fiable
+	if shouldAccumulate && !priceTrusted(cycle.Exchange) {
+		ev.warn(i18n.T("update.accumulation_price_untrusted"), cycle.IdInt, cycle.Exchange)
+		shouldAccumulate = false
+	}
+
+	// Une moyenne à la baisse en cours garde le cycle: son achat supplémentaire doit y être fusionné
+	if shouldAccumulate && cycle.AverageDownState != "" {
+		ev.info(i18n.T("update.accumulation_deferred_average_down"), cycle.IdInt)
+		shouldAccumulate = false
+	}
+
+	if shouldAccumulate {
+		ev = ev.with("action", "accumulate").with("price", currentPrice)
+		ev.info(i18n.T("update.accumulation_met"), cycle.IdInt)
+		ev.info(i18n.T("update.accumulation_deviation"), deviationPercent, exchangeConfig.SellAccuPriceDeviation)
+		ev.info(i18n.T("update.accumulation_cancelling"))
+		shouldAccumulate = cancelSellForAccumulation(client, cycle, ev)
 	}
 
 	if shouldAccumulate {
-		color.Yellow("Conditions d'accumulation remplies pour le cycle %d:", cycle.IdInt)
-		color.Yellow("  - Déviation de prix: %.2f%% (seuil: %.2f%%)", deviationPercent, exchangeConfig.SellAccuPriceDeviation)
-		color.Yellow("  - Annulation de l'ordre de vente pour accumulation...")
 
 		// Créer une nouvelle entrée d'accumulation
 		accumulation := &database.Accumulation{
@@ -822,19 +990,22 @@ func processSellCycle(client common.Exchange, repo *database.CycleRepository, cy
 			CancelPrice:      currentPrice,
 			Deviation:        deviationPercent,
 			CreatedAt:        time.Now(),
+			BuyFees:          cycle.BuyFees,
+			BuyFeeBTC:        cycle.BuyFeeBTC,
+			FeesEstimated:    cycle.FeesEstimated,
 		}
 
 		// Enregistrer l'accumulation
 		_, err = accuRepo.Save(accumulation)
 		if err != nil {
-			color.Red("Erreur lors de l'enregistrement de l'accumulation: %v", err)
+			ev.with("error", err).fail(i18n.T("update.accumulation_save_error"), err)
 
 			// Même si l'enregistrement échoue, essayer de supprimer le cycle
 			deleteErr := repo.DeleteByIdInt(cycle.IdInt)
 			if deleteErr != nil {
-				color.Red("Erreur lors de la suppression du cycle: %v", deleteErr)
+				ev.with("error", deleteErr).fail(i18n.T("update.cycle_delete_error"), deleteErr)
 			} else {
-				color.Yellow("Cycle supprimé malgré l'échec d'enregistrement de l'accumulation.")
+				ev.warn(i18n.T("update.accumulation_cycle_deleted"))
 			}
 			return
 		}
@@ -842,12 +1013,14 @@ func processSellCycle(client common.Exchange, repo *database.CycleRepository, cy
 		// Supprimer le cycle de la base de données
 		err = repo.DeleteByIdInt(cycle.IdInt)
 		if err != nil {
-			color.Red("Erreur lors de la suppression du cycle pour accumulation: %v", err)
-			color.Yellow("Attention: L'accumulation a été enregistrée mais le cycle n'a pas été supprimé. Cycle ID: %d", cycle.IdInt)
+			ev.with("error", err).fail(i18n.T("update.accumulation_delete_error"), err)
+			ev.warn(i18n.T("update.accumulation_cycle_kept"), cycle.IdInt)
 		} else {
-			color.Green("Cycle %d annulé avec succès pour accumulation", cycle.IdInt)
-			color.Green("%.8f BTC accumulés à un prix de %.2f au lieu de %.2f (économie: %.2f%%)",
+			ev.success(i18n.T("update.accumulation_done"), cycle.IdInt)
+			ev.success(i18n.T("update.accumulation_summary"),
 				cycle.Quantity, currentPrice, cycle.SellPrice, deviationPercent)
+			ev.notify(cycle, "Cycle %d: vente annulée, %.8f BTC accumulés à %.2f au lieu de %.2f",
+				cycle.IdInt, cycle.Quantity, currentPrice, cycle.SellPrice)
 		}
 
 		return
@@ -857,195 +1030,240 @@ func processSellCycle(client common.Exchange, repo *database.CycleRepository, cy
 	// Nettoyer l'ID d'ordre de vente en spécifiant l'exchange
 	cleanSellId := cleanOrderId(cycle.SellId, cycle.Exchange)
 	if cleanSellId == "" {
-		color.Red("ID d'ordre de vente invalide: %s", cycle.SellId)
+		ev.fail(i18n.T("update.invalid_sell_id"), cycle.SellId)
 		return
 	}
 
-	// Récupérer l'ordre de vente
-	orderBytes, err := client.GetOrderById(cleanSellId)
+	// Récupérer l'état de l'ordre de vente
+	sellStatus, err := client.GetOrderStatus(cleanSellId)
 	if err != nil {
-		color.Red("Erreur lors de la récupération de l'ordre de vente %s (nettoyé: %s): %v",
+		ev.with("error", err).fail(i18n.T("update.sell_order_fetch_error"),
 			cycle.SellId, cleanSellId, err)
 		return
 	}
 
 	// Vérifier si l'ordre est exécuté
-	isFilled := client.IsFilled(string(orderBytes))
+	isFilled := sellStatus.Filled()
+
+	// Vente OCO: si la vente limite n'est pas exécutée, le stop de protection a pu l'être
+	filledId, filledPrice := cleanSellId, cycle.SellPrice
+	if !isFilled && cycle.StopId != "" {
+		cleanStopId := cleanOrderId(cycle.StopId, cycle.Exchange)
+		stopStatus, stopErr := client.GetOrderStatus(cleanStopId)
+		if stopErr != nil {
+			ev.with("error", stopErr).fail(i18n.T("update.oco_stop_fetch_error"), cycle.StopId, stopErr)
+			return
+		}
+		if stopStatus.Filled() {
+			sellStatus, isFilled = stopStatus, true
+			filledId, filledPrice = cleanStopId, cycle.StopPrice
+			cycle.StoppedOut = true
+			ev.with("order_id", cycle.StopId).warn(i18n.T("update.oco_stop_filled"), cycle.IdInt, cycle.StopPrice)
+		}
+	}
+
 	if !isFilled {
 		// L'ordre n'est pas encore exécuté
 		return
 	}
 
+	ev = ev.with("action", "sell_filled").with("price", filledPrice)
+	captureOrderSnapshot(client, repo, cycle, ev, database.OrderSnapshotSell, filledId)
+
 	// Récupérer les frais de vente réels
 	var sellFees float64
 	// Tenter de récupérer les frais avec la méthode publique GetOrderFees
-	sellFees, err = client.GetOrderFees(cleanSellId)
-	if err != nil {
+	sellFees, err = client.GetOrderFees(filledId)
+	if err != nil && sellStatus.Fee != 0 {
+		// Frais fournis avec l'ordre par l'exchange
+		sellFees = sellStatus.Fee
+		logFees(ev, "update.sell_fees", "update.sell_rebate", sellFees)
+	} else if err != nil {
 		// Si on ne peut pas récupérer les frais, estimer avec le taux par défaut
 		feeRate := getFeeRateForExchange(cycle.Exchange)
-		sellFees = cycle.SellPrice * cycle.Quantity * feeRate
-		color.Yellow("Impossible de récupérer les frais de vente, estimation selon le taux standard: %.8f USDC (taux: %.4f%%)",
+		sellFees = filledPrice * cycle.Quantity * feeRate
+		cycle.FeesEstimated = true
+		ev.warn(i18n.T("update.sell_fees_estimated"),
 			sellFees, feeRate*100)
 	} else {
-		color.Green("Frais de vente récupérés: %.8f USDC", sellFees)
+		logFees(ev, "update.sell_fees", "update.sell_rebate", sellFees)
+	}
+	if sellStatus.FeeAssetAmount > 0 {
+		ev.info(i18n.T("update.fee_asset"), cycle.IdInt, sellStatus.FeeAssetAmount, sellStatus.FeeAsset)
 	}
 
-	// Ajouter directement les frais de vente aux frais totaux déjà enregistrés
-	totalFees := cycle.TotalFees + sellFees
-
-	// Tenter d'extraire la date réelle d'exécution et les frais selon l'exchange
-	completionTime := time.Now() // Valeur par défaut
-	extractionSuccessful := false
-
-	// Extraction spécifique à chaque exchange
-	switch cycle.Exchange {
-	case "BINANCE":
-		updateTimeMs, timeErr := jsonparser.GetInt(orderBytes, "updateTime")
-		if timeErr == nil {
-			extractedTime := time.Unix(0, updateTimeMs*int64(time.Millisecond))
-			if extractedTime.After(cycle.CreatedAt) {
-				completionTime = extractedTime
-				extractionSuccessful = true
-			}
-		}
-
-	case "MEXC":
-		// Utiliser une estimation raisonnable pour MEXC (6h après création)
-		// car ses timestamps sont souvent incorrects
-		now := time.Now()
-		if completionTime.Before(cycle.CreatedAt) {
-			// Si la date de complétion est avant la date de création, utiliser la date de création + une durée raisonnable
-			color.Yellow("Correction de date: CompletedAt était antérieur à CreatedAt pour le cycle %d", cycle.IdInt)
-			completionTime = cycle.CreatedAt.Add(6 * time.Hour) // 6h est une estimation raisonnable pour MEXC
-		} else {
-			completionTime = now.Add(-1 * time.Hour)
-		}
-		extractionSuccessful = true
-
-	case "KUCOIN":
-		// Pour KuCoin, extraire la date si possible
-		createdAtStr, err := jsonparser.GetString(orderBytes, "createdAt")
-		if err == nil && createdAtStr != "" {
-			// KuCoin utilise des timestamps en millisecondes
-			if timestampMs, err := strconv.ParseInt(createdAtStr, 10, 64); err == nil {
-				extractedTime := time.Unix(0, timestampMs*int64(time.Millisecond))
-				if extractedTime.After(cycle.CreatedAt) {
-					completionTime = extractedTime
-					extractionSuccessful = true
-				}
-			}
-		}
-
-	case "KRAKEN":
-		// Pour Kraken, tenter d'extraire le timestamp de fermeture ou utiliser une estimation
-		// Les formats de réponse de Kraken peuvent varier, vérifier plusieurs possibilités
-		closeTimeStr, err := jsonparser.GetString(orderBytes, "closetm")
-		if err == nil && closeTimeStr != "" {
-			// Kraken peut fournir des timestamps sous différents formats
-			closeTime, parseErr := strconv.ParseFloat(closeTimeStr, 64)
-			if parseErr == nil {
-				// Convertir le timestamp en time.Time
-				extractedTime := time.Unix(int64(closeTime), 0)
-				if extractedTime.After(cycle.CreatedAt) {
-					completionTime = extractedTime
-					extractionSuccessful = true
-				}
-			}
-		}
+	// Frais totaux recalculés depuis les frais d'achat enregistrés: TotalFees a pu conserver une
+	// estimation remplacée depuis
+	totalFees := cycle.BuyFees + sellFees
 
-		// Si l'extraction échoue, utiliser une estimation raisonnable (5h après création)
-		if !extractionSuccessful {
-			completionTime = cycle.CreatedAt.Add(5 * time.Hour)
-			extractionSuccessful = true
-		}
+	// Date d'exécution: celle de l'exchange si elle est cohérente, sinon celle où la mise à jour
+	// constate l'exécution (aucune date n'est plus estimée)
+	observedAt := time.Now()
+	completionTime := observedAt
+	if exchangeTime := sellStatus.UpdatedAt; cycle.PlausibleCompletedAt(exchangeTime) {
+		completionTime = exchangeTime
+		ev.success(i18n.T("update.completed_at_extracted"),
+			cycle.IdInt, completionTime.Format(i18n.DateTimeLayout()+":05"))
+	} else {
+		ev.info(i18n.T("update.completed_at_now"), cycle.IdInt)
 	}
 
-	if extractionSuccessful {
-		color.Green("Date de complétion extraite avec succès pour le cycle %d: %s",
-			cycle.IdInt, completionTime.Format("02/01/2006 15:04:05"))
-	} else {
-		color.Yellow("Utilisation de la date actuelle comme date de complétion pour le cycle %d", cycle.IdInt)
+	// Prix moyen réellement exécuté pour la vente
+	if fillPrice := sellStatus.AvgFillPrice; fillPrice > 0 {
+		cycle.SellFillPrice = fillPrice
+		ev.info(i18n.T("update.sell_fill_price"), cycle.IdInt, fillPrice, filledPrice)
+	} else if cycle.StoppedOut {
+		// Sans prix moyen, le stop est au moins exécuté à sa limite, pas au prix de vente cible
+		cycle.SellFillPrice = cycle.StopPrice
 	}
 
 	// Calculer le profit net en tenant compte des frais spécifiques
 	var profit, profitPercent float64
-	buyAmount := cycle.BuyPrice * cycle.Quantity
-	sellAmount := cycle.SellPrice * cycle.Quantity
+	buyAmount := cycle.PurchaseAmountUSDC
+	if buyAmount <= 0 {
+		buyAmount = cycle.EffectiveBuyPrice() * cycle.Quantity
+	}
+	sellAmount := filledAmount(sellStatus, cycle.EffectiveSellPrice(), cycle.Quantity)
 
 	profit = sellAmount - buyAmount - totalFees
 	if buyAmount > 0 {
 		profitPercent = (profit / buyAmount) * 100
 	}
 
-	// Afficher les détails du profit avec les frais
+	// Afficher les détails du profit avec les frais, ou la remise maker nette si l'exchange a
+	// reversé plus qu'il n'a prélevé
 	if totalFees > 0 {
-		color.Green("Cycle %d: COMPLÉTÉ AVEC SUCCÈS! (Profit net: %.2f USDC, %.2f%%)",
+		ev.success(i18n.T("update.completed_profit"),
+			cycle.IdInt, profit, profitPercent)
+		ev.success(i18n.T("update.completed_fees"),
+			totalFees, cycle.BuyFees, sellFees)
+	} else if totalFees < 0 {
+		ev.success(i18n.T("update.completed_profit"),
 			cycle.IdInt, profit, profitPercent)
-		color.Green("Frais totaux: %.8f USDC (Achat: %.8f, Vente: %.8f)",
-			totalFees, sellFees)
+		ev.success(i18n.T("update.completed_rebate"),
+			-totalFees, cycle.BuyFees, sellFees)
 	} else {
-		color.Green("Cycle %d: COMPLÉTÉ AVEC SUCCÈS!", cycle.IdInt)
+		ev.success(i18n.T("update.completed"), cycle.IdInt)
 	}
 
-	// Mettre à jour le cycle dans la base de données
-	// Ajouter les champs de frais dans la mise à jour
-	updateFields := map[string]interface{}{
-		"status":      "completed",
-		"completedAt": completionTime.Format(time.RFC3339),
-		"sellFees":    sellFees,
-		"totalFees":   totalFees,
-	}
+	// Mettre à jour le cycle dans la base de données, avec les montants calculés sur les prix
+	// réellement exécutés
+	err = repo.MarkCompleted(cycle.IdInt, database.Completion{
+		CompletedAt: completionTime,
+		ObservedAt:  observedAt,
+		SellFees:    sellFees,
+		TotalFees:   totalFees,
+
+		// Signalé dans le rapport fiscal (--tax-report) si l'un des frais a été estimé
+		FeesEstimated: cycle.FeesEstimated,
+
+		// Vente exécutée par le stop de protection d'un OCO
+		StoppedOut: cycle.StoppedOut,
 
-	err = repo.UpdateByIdInt(cycle.IdInt, updateFields)
+		SellFillPrice:      cycle.SellFillPrice,
+		PurchaseAmountUSDC: buyAmount,
+		SaleAmountUSDC:     sellAmount,
+
+		// Frais payés en BNB, tels que prélevés (comptés en USDC dans sellFees)
+		SellFeeAsset:       sellStatus.FeeAsset,
+		SellFeeAssetAmount: sellStatus.FeeAssetAmount,
+	})
 	if err != nil {
-		color.Red("Erreur lors de la mise à jour du cycle: %v", err)
+		ev.with("error", err).fail(i18n.T("update.cycle_update_error"), err)
 		return
 	}
 
 	// Mettre à jour l'objet cycle en mémoire également
 	cycle.Status = "completed"
 	cycle.CompletedAt = completionTime
+	cycle.ObservedCompletedAt = observedAt
+	cycle.PurchaseAmountUSDC = buyAmount
+	cycle.SaleAmountUSDC = sellAmount
+	cycle.SellFees = sellFees
+	cycle.TotalFees = totalFees
+	cycle.SellFeeAsset, cycle.SellFeeAssetAmount = sellStatus.FeeAsset, sellStatus.FeeAssetAmount
+
+	ev.success(i18n.T("update.buy_date"), i18n.FormatDateTime(cycle.CreatedAt))
+	ev.success(i18n.T("update.sell_date"), i18n.FormatDateTime(completionTime))
+	ev.success(i18n.T("update.cycle_duration"), formatDetailedDuration(completionTime.Sub(cycle.CreatedAt).Hours()/24))
 
-	color.Green("Date d'achat: %s", cycle.CreatedAt.Format("02/01/2006 15:04"))
-	color.Green("Date de vente: %s", completionTime.Format("02/01/2006 15:04"))
-	color.Green("Durée du cycle: %s", formatDetailedDuration(time.Since(cycle.CreatedAt).Hours()/24))
+	ev.with("profit", profit).notify(cycle, "Cycle %d complété: profit net %.2f USDC (%.2f%%)", cycle.IdInt, profit, profitPercent)
+
+	// Vendu avant la fusion: l'achat supplémentaire devient un cycle à part entière
+	if cycle.AverageDownState != "" {
+		detachAverageDown(repo, cycle)
+	}
 }
 
-func displayCyclesHistory(cycles []*database.Cycle, _ float64) {
-	if len(cycles) == 0 {
-		color.Yellow("Aucun cycle trouvé dans la base de données.")
-		return
+// displayUpdateSummary affiche les cycles en cours et les statistiques par exchange (un seul
+// exchange si exchange n'est pas vide). Les cycles en cours sont relus après leur traitement;
+// les statistiques sont agrégées par le repository, sans charger l'historique complété.
+func displayUpdateSummary(repo *database.CycleRepository, prices map[string]float64, exchange string) error {
+	active, err := repo.FindActive()
+	if err != nil {
+		return err
 	}
 
-	// Compteurs pour les statistiques
-	statsBinance := cycleStatistics{}
-	statsMexc := cycleStatistics{}
-	statsKucoin := cycleStatistics{}
-	statsKraken := cycleStatistics{}
+	now := time.Now()
+	since := make([]time.Time, len(statsPeriods))
+	for i, period := range statsPeriods {
+		since[i] = now.Add(-period)
+	}
+	summaries, err := repo.Summarize(completedNetProfit, since...)
+	if err != nil {
+		return err
+	}
+
+	if exchange != "" {
+		var exchangeCycles []*database.Cycle
+		for _, cycle := range active {
+			if cycle.Exchange == exchange {
+				exchangeCycles = append(exchangeCycles, cycle)
+			}
+		}
+		active = exchangeCycles
+		summaries = map[string]*database.CycleSummary{exchange: summaries[exchange]}
+	}
+
+	displayCyclesHistory(active, summaries, prices)
+	return nil
+}
+
+// displayCyclesHistory affiche les cycles en cours puis les statistiques agrégées de chaque
+// exchange (summaries, par exchange)
+func displayCyclesHistory(cycles []*database.Cycle, summaries map[string]*database.CycleSummary, prices map[string]float64) {
+	total := 0
+	for _, summary := range summaries {
+		if summary != nil {
+			total += summary.Total
+		}
+	}
+	if len(cycles) == 0 && total == 0 {
+		color.Yellow(i18n.T("update.no_cycles"))
+		return
+	}
 
 	fmt.Println("")
-	color.Cyan("===== CYCLES ACTIFS =====")
+	color.Cyan(i18n.T("update.active_cycles"))
 	fmt.Println("")
 
 	// Nouvel en-tête avec les colonnes prix BTC à l'achat et à la vente
-	headerFormat := "%-5s | %-10s | %-12s | %-15s | %-15s | %-15s | %-15s | %-15s\n"
-	rowFormat := "%-5d | %-10s | %-12s | %-15.2f | %-15.2f | %-15.2f | %-15s | %-15s\n"
+	headerFormat := "%-5s | %-10s | %-12s | %-15s | %-15s | %-15s | %-15s | %-17s | %-15s\n"
+	rowFormat := "%-5d | %-10s | %-12s | %-15.2f | %-15.2f | %-15.2f | %-15s | %-17s | %-15s\n"
 
-	fmt.Printf(headerFormat, "ID", "EXCHANGE", "STATUT", "MONTANT USDC", "PRIX BTC ACHAT", "PRIX BTC VENTE", "GAINS PRÉVUS", "DURÉE")
-	fmt.Println("-------+------------+--------------+-----------------+-----------------+-----------------+-----------------+-----------------")
+	fmt.Printf(headerFormat, "ID", "EXCHANGE", i18n.T("update.col_status"), i18n.T("update.col_amount"), i18n.T("update.col_buy_price"),
+		i18n.T("update.col_sell_price"), i18n.T("update.col_expected_gain"), i18n.T("update.col_unrealized"), i18n.T("update.col_duration"))
+	fmt.Println("-------+------------+--------------+-----------------+-----------------+-----------------+-----------------+-------------------+-----------------")
 
 	// Trier les cycles par ID (du plus récent au plus ancien)
 	sort.Slice(cycles, func(i, j int) bool {
 		return cycles[i].IdInt > cycles[j].IdInt
 	})
 
-	// Filtrer et afficher uniquement les cycles non complétés
+	// Afficher uniquement les cycles non complétés
 	activeCycles := 0
 	for _, cycle := range cycles {
-		// Exclure les cycles complétés et annulés
 		if cycle.Status == "completed" || cycle.Status == "cancelled" {
-			// Mettre à jour les statistiques mais ne pas afficher
-			updateStats(cycle, &statsBinance, &statsMexc, &statsKucoin, &statsKraken)
 			continue
 		}
 
@@ -1055,80 +1273,29 @@ func displayCyclesHistory(cycles []*database.Cycle, _ float64) {
 		var status string
 		switch cycle.Status {
 		case "buy":
-			status = color.GreenString("ACHAT")
+			status = color.GreenString(i18n.T("update.status_buy"))
 		case "sell":
-			status = color.YellowString("VENTE")
+			status = color.YellowString(i18n.T("update.status_sell"))
+		case database.StatusCancelPending:
+			status = color.RedString(i18n.T("update.status_cancel_pending"))
 		default:
 			status = cycle.Status
 		}
 
 		// Calculer le montant USDC utilisé pour l'achat
-		usdcAmount := cycle.BuyPrice * cycle.Quantity
+		usdcAmount := cycle.EffectiveBuyPrice() * cycle.Quantity
 
 		// Calculer le montant USDC prévu à la vente
-		usdcSaleAmount := cycle.SellPrice * cycle.Quantity
+		usdcSaleAmount := cycle.EffectiveSellPrice() * cycle.Quantity
 
 		// Calculer les gains prévus (en valeur absolue et en pourcentage)
 		var expectedProfit float64
 
-		// Obtenir un client pour cet exchange afin de pouvoir utiliser GetOrderFees
-		var client common.Exchange
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					color.Red("Erreur lors de l'initialisation du client pour %s: %v", cycle.Exchange, r)
-				}
-			}()
-			client = GetClientByExchange(cycle.Exchange)
-		}()
-
-		// Récupérer les frais d'achat réels si possible sinon estimer les frais
-		var buyFees, sellFees float64
-
-		if client != nil {
-			// Si nous avons un ID d'achat et que l'ordre est déjà exécuté
-			if cycle.BuyId != "" {
-				// Nettoyer l'ID de l'ordre d'achat selon l'exchange
-				cleanBuyId := cleanOrderId(cycle.BuyId, cycle.Exchange)
-				if cleanBuyId != "" {
-					// Tenter de récupérer les frais réels
-					realBuyFees, err := client.GetOrderFees(cleanBuyId)
-					if err == nil && realBuyFees > 0 {
-						buyFees = realBuyFees
-					}
-				} else {
-					// Estimation basique si l'ID n'est pas valide
-					buyFees = usdcAmount * getFeeRateForExchange(cycle.Exchange)
-				}
-			} else {
-				// Si l'ordre d'achat est toujours en cours ou l'ID n'est pas disponible
-				buyFees = usdcAmount * getFeeRateForExchange(cycle.Exchange)
-			}
-
-			// Pour les frais de vente, on doit estimer car l'ordre n'est pas encore exécuté
-			// Appliquer directement le taux de frais (taux maker généralement pour les ventes)
-			sellFees = usdcSaleAmount * getFeeRateForExchange(cycle.Exchange)
-
-			// Calculer le profit en tenant compte des frais
-			expectedProfit = usdcSaleAmount - usdcAmount - (buyFees + sellFees)
-		} else {
-			// Fallback au comportement actuel si on ne peut pas obtenir de client
-			if cycle.Exchange == "KRAKEN" {
-				// Estimer les frais selon le taux maker de Kraken (0.26%)
-				const makerFeeRate = 0.0026
-				buyFees = cycle.BuyPrice * cycle.Quantity * makerFeeRate
-				sellFees = cycle.SellPrice * cycle.Quantity * makerFeeRate
-				expectedProfit = usdcSaleAmount - usdcAmount - (buyFees + sellFees)
-			} else if cycle.Exchange == "BINANCE" {
-				// Binance: 0.1% standard
-				buyFees = usdcAmount * 0.001
-				sellFees = usdcSaleAmount * 0.001
-				expectedProfit = usdcSaleAmount - usdcAmount - (buyFees + sellFees)
-			} else {
-				// Pour les autres exchanges, supposons que les frais sont déjà inclus dans les prix
-				expectedProfit = usdcSaleAmount - usdcAmount
-			}
-		}
+		// Frais d'achat enregistrés ou lus une seule fois sur l'exchange, frais de vente estimés au
+		// taux maker car l'ordre n'est pas encore exécuté
+		buyFees := cycleBuyFees(cycle, usdcAmount)
+		sellFees := usdcSaleAmount * getFeeRateForExchange(cycle.Exchange)
+		expectedProfit = usdcSaleAmount - usdcAmount - (buyFees + sellFees)
 
 		expectedProfitPercent := 0.0
 		if usdcAmount > 0 {
@@ -1138,6 +1305,22 @@ func displayCyclesHistory(cycles []*database.Cycle, _ float64) {
 		// Formater les gains prévus
 		expectedProfitStr := fmt.Sprintf("%.2f (%.2f%%)", expectedProfit, expectedProfitPercent)
 
+		// P&L latent au prix actuel de l'exchange (cycles en vente uniquement)
+		unrealizedStr := "-"
+		if unrealized, ok := unrealizedProfit(cycle, prices[cycle.Exchange]); ok {
+			unrealizedPercent := 0.0
+			if usdcAmount > 0 {
+				unrealizedPercent = unrealized / usdcAmount * 100
+			}
+			// Remplir avant de colorer: les codes couleur fausseraient l'alignement
+			unrealizedStr = fmt.Sprintf("%-17s", fmt.Sprintf("%.2f (%.2f%%)", unrealized, unrealizedPercent))
+			if unrealized >= 0 {
+				unrealizedStr = color.GreenString(unrealizedStr)
+			} else {
+				unrealizedStr = color.RedString(unrealizedStr)
+			}
+		}
+
 		// Calculer la durée depuis la création
 		duration := calculateDuration(cycle.CreatedAt)
 
@@ -1150,41 +1333,53 @@ func displayCyclesHistory(cycles []*database.Cycle, _ float64) {
 			cycle.BuyPrice,  // Prix du BTC à l'achat
 			cycle.SellPrice, // Prix du BTC à la vente
 			expectedProfitStr,
+			unrealizedStr,
 			duration)
-
-		// Mettre à jour les statistiques
-		updateStats(cycle, &statsBinance, &statsMexc, &statsKucoin, &statsKraken)
 	}
 
 	if activeCycles == 0 {
-		color.Yellow("Aucun cycle actif trouvé.")
+		color.Yellow(i18n.T("update.no_active_cycles"))
 	}
 
-	fmt.Println("-------+------------+--------------+-----------------+-----------------+-----------------+-----------------+-----------------")
+	fmt.Println("-------+------------+--------------+-----------------+-----------------+-----------------+-----------------+-------------------+-----------------")
 
-	// Afficher les statistiques par exchange avec les nouvelles informations
-	displayExchangeStats("Binance", statsBinance, cycles)
-	displayExchangeStats("MEXC", statsMexc, cycles)
-	displayExchangeStats("KuCoin", statsKucoin, cycles)
-	displayExchangeStats("Kraken", statsKraken, cycles)
+	// P&L latent total des cycles en vente et statistiques agrégées, par exchange
+	unrealizedTotals := unrealizedByExchange(cycles, prices)
+	for _, exchange := range statsExchanges {
+		summary, ok := summaries[exchange.key]
+		if !ok {
+			continue
+		}
+		if summary == nil {
+			summary = &database.CycleSummary{}
+		}
+		unrealized, hasUnrealized := unrealizedTotals[exchange.key]
+		displayExchangeStats(exchange.name, summary, unrealized, hasUnrealized)
+	}
 }
 
-func displayExchangeStats(exchangeName string, stats cycleStatistics, allCycles []*database.Cycle) {
-	color.Cyan("Statistiques %s:", exchangeName)
-	color.White("  Total des cycles:     %d", stats.totalCycles)
-	color.White("  Cycles d'achat:       %d", stats.buyCycles)
-	color.White("  Cycles de vente:      %d", stats.sellCycles)
-	color.White("  Cycles complétés:     %d", stats.completedCycles)
+// displayExchangeStats affiche les statistiques agrégées d'un exchange et le P&L latent de ses
+// cycles en vente (hasUnrealized faux si le prix est inconnu)
+func displayExchangeStats(exchangeName string, summary *database.CycleSummary, unrealizedProfit float64, hasUnrealized bool) {
+	color.Cyan(i18n.T("update.stats_heading"), exchangeName)
+	color.White(i18n.T("update.stats_total"), summary.Total)
+	color.White(i18n.T("update.stats_buy"), summary.Buy)
+	color.White(i18n.T("update.stats_sell"), summary.Sell)
+	color.White(i18n.T("update.stats_completed"), summary.Completed)
+	if hasUnrealized {
+		if unrealizedProfit >= 0 {
+			color.Green(i18n.T("update.stats_unrealized"), unrealizedProfit)
+		} else {
+			color.Red(i18n.T("update.stats_unrealized"), unrealizedProfit)
+		}
+	}
 
-	if stats.completedCycles > 0 {
-		// Récupérer la date actuelle pour calculer les périodes
-		now := time.Now()
+	if summary.Completed > 0 && len(summary.PeriodProfits) == len(statsPeriods) {
+		totalProfit := summary.Profit
 
-		// Calculer les profits par période
-		profit24h := calculateProfitByPeriod(allCycles, exchangeName, now.Add(-24*time.Hour), now)
-		profit7d := calculateProfitByPeriod(allCycles, exchangeName, now.Add(-7*24*time.Hour), now)
-		profit30d := calculateProfitByPeriod(allCycles, exchangeName, now.Add(-30*24*time.Hour), now)
-		profit3m := calculateProfitByPeriod(allCycles, exchangeName, now.Add(-90*24*time.Hour), now)
+		// Profits par période (24h, 7 jours, 30 jours, 3 mois)
+		profit24h, profit7d := summary.PeriodProfits[0], summary.PeriodProfits[1]
+		profit30d, profit3m := summary.PeriodProfits[2], summary.PeriodProfits[3]
 
 		// Vérifier la cohérence des profits par période
 		// Le profit d'une période plus longue ne devrait pas être inférieur à celui d'une période plus courte
@@ -1199,37 +1394,37 @@ func displayExchangeStats(exchangeName string, stats cycleStatistics, allCycles
 		}
 
 		// S'assurer que le profit total est au moins égal au profit sur 3 mois
-		if stats.totalProfit < profit3m {
+		if totalProfit < profit3m {
 			// Correction statistique
-			stats.totalProfit = profit3m
+			totalProfit = profit3m
 		}
 
 		// Afficher les profits avec un format cohérent
-		color.Green("  Profit total:         %.2f USDC", stats.totalProfit)
+		color.Green(i18n.T("update.stats_profit"), totalProfit)
 
 		// Utiliser une couleur différente selon que le profit est positif ou négatif
 		if profit24h >= 0 {
-			color.Green("  Profit depuis 24h:    %.2f USDC", profit24h)
+			color.Green(i18n.T("update.stats_profit_24h"), profit24h)
 		} else {
-			color.Red("  Profit depuis 24h:    %.2f USDC", profit24h)
+			color.Red(i18n.T("update.stats_profit_24h"), profit24h)
 		}
 
 		if profit7d >= 0 {
-			color.Green("  Profit depuis 7j:     %.2f USDC", profit7d)
+			color.Green(i18n.T("update.stats_profit_7d"), profit7d)
 		} else {
-			color.Red("  Profit depuis 7j:     %.2f USDC", profit7d)
+			color.Red(i18n.T("update.stats_profit_7d"), profit7d)
 		}
 
 		if profit30d >= 0 {
-			color.Green("  Profit depuis 30j:    %.2f USDC", profit30d)
+			color.Green(i18n.T("update.stats_profit_30d"), profit30d)
 		} else {
-			color.Red("  Profit depuis 30j:    %.2f USDC", profit30d)
+			color.Red(i18n.T("update.stats_profit_30d"), profit30d)
 		}
 
 		if profit3m >= 0 {
-			color.Green("  Profit depuis 3 mois: %.2f USDC", profit3m)
+			color.Green(i18n.T("update.stats_profit_3m"), profit3m)
 		} else {
-			color.Red("  Profit depuis 3 mois: %.2f USDC", profit3m)
+			color.Red(i18n.T("update.stats_profit_3m"), profit3m)
 		}
 	}
 	fmt.Println("")
@@ -1242,7 +1437,7 @@ func calculateDuration(startTime time.Time) string {
 	if duration.Hours() > 24 {
 		days := int(duration.Hours() / 24)
 		hours := int(duration.Hours()) % 24
-		return fmt.Sprintf("%d j %d h", days, hours)
+		return i18n.T("update.duration_days", days, hours)
 	} else if duration.Hours() >= 1 {
 		hours := int(duration.Hours())
 		minutes := int(duration.Minutes()) % 60
@@ -1253,61 +1448,6 @@ func calculateDuration(startTime time.Time) string {
 	}
 }
 
-// Fonction améliorée updateStats pour mettre à jour correctement les statistiques
-func updateStats(cycle *database.Cycle, statsBinance, statsMexc, statsKucoin, statsKraken *cycleStatistics) {
-	// Sélectionner les statistiques de l'exchange approprié
-	var stats *cycleStatistics
-	switch cycle.Exchange {
-	case "BINANCE":
-		stats = statsBinance
-	case "MEXC":
-		stats = statsMexc
-	case "KUCOIN":
-		stats = statsKucoin
-	case "KRAKEN":
-		stats = statsKraken
-	default:
-		return // Exchange non supporté
-	}
-
-	// Mettre à jour les statistiques
-	stats.totalCycles++
-	switch cycle.Status {
-	case "buy":
-		stats.buyCycles++
-	case "sell":
-		stats.sellCycles++
-	case "completed":
-		stats.completedCycles++
-
-		// Calculer le profit brut
-		grossProfit := (cycle.SellPrice - cycle.BuyPrice) * cycle.Quantity
-
-		// Soustraire les frais stockés pour obtenir le profit net
-		var totalFees float64
-		if cycle.TotalFees > 0 {
-			totalFees = cycle.TotalFees
-		} else {
-			// Si les frais ne sont pas stockés, utiliser une estimation
-			feeRate := getFeeRateForExchange(cycle.Exchange) * 2 // achat + vente
-			totalFees = grossProfit * feeRate
-		}
-
-		netProfit := grossProfit - totalFees
-
-		// Log pour le débogage si nécessaire
-		if cfg != nil && cfg.Environment == "development" {
-			color.Cyan("Cycle %d (%s) - Frais totaux: %.8f USDC",
-				cycle.IdInt, cycle.Exchange, totalFees)
-			color.Cyan("Profit brut: %.8f, Profit net: %.8f",
-				grossProfit, netProfit)
-		}
-
-		// Ajouter le profit net aux statistiques
-		stats.totalProfit += netProfit
-	}
-}
-
 // Fonction utilitaire pour calculer le profit sur une période donnée
 func calculateProfitByPeriod(cycles []*database.Cycle, exchangeName string, startTime, endTime time.Time) float64 {
 	var periodProfit float64
@@ -1318,33 +1458,9 @@ func calculateProfitByPeriod(cycles []*database.Cycle, exchangeName string, star
 
 		// Ne considérer que les cycles de l'exchange spécifié et complétés
 		if cycleExchangeUpper == exchangeNameUpper && cycle.Status == "completed" {
-			// Utiliser la date de complétion pour déterminer si le cycle appartient à la période
-			completionDate := cycle.CompletedAt
-			if completionDate.IsZero() {
-				// Si la date de complétion n'est pas définie, utiliser la date de création
-				// mais ce n'est pas idéal
-				completionDate = cycle.CreatedAt
-			}
-
-			// Vérifier si le cycle a été complété dans la période spécifiée
-			if completionDate.After(startTime) && completionDate.Before(endTime) {
-				// Calculer le profit net pour ce cycle
-				buyValue := cycle.BuyPrice * cycle.Quantity
-				sellValue := cycle.SellPrice * cycle.Quantity
-				grossProfit := sellValue - buyValue
-
-				// Utiliser les frais stockés ou estimer si nécessaire
-				var totalFees float64
-				if cycle.TotalFees > 0 {
-					totalFees = cycle.TotalFees
-				} else {
-					// Estimer les frais si non disponibles (fallback)
-					feeRate := getFeeRateForExchange(cycle.Exchange) * 2 // achat + vente
-					totalFees = buyValue * feeRate
-				}
-
-				netProfit := grossProfit - totalFees
-				periodProfit += netProfit
+			// Vérifier si le cycle a été complété dans la période spécifiée (même règle que le serveur de statistiques)
+			if cycleInPeriod(cycle, &startTime, &endTime, dateFieldCompleted) {
+				periodProfit += completedNetProfit(cycle)
 			}
 		}
 	}
@@ -1352,6 +1468,48 @@ func calculateProfitByPeriod(cycles []*database.Cycle, exchangeName string, star
 	return periodProfit
 }
 
+// completedNetProfit calcule le profit net d'un cycle complété, avec les frais enregistrés ou,
+// à défaut, estimés au taux de l'exchange sur l'achat et la vente
+func completedNetProfit(cycle *database.Cycle) float64 {
+	buyValue := cycle.EffectiveBuyPrice() * cycle.Quantity
+	sellValue := cycle.EffectiveSellPrice() * cycle.Quantity
+	grossProfit := sellValue - buyValue
+
+	// Utiliser les frais stockés (négatifs pour une remise maker) ou estimer si nécessaire
+	var totalFees float64
+	if cycle.TotalFees != 0 {
+		totalFees = cycle.TotalFees
+	} else {
+		// Estimer les frais si non disponibles (fallback)
+		feeRate := getFeeRateForExchange(cycle.Exchange) * 2 // achat + vente
+		totalFees = buyValue * feeRate
+	}
+
+	return grossProfit - totalFees
+}
+
+// cancelSellForAccumulation annule sur l'exchange la vente d'un cycle accumulé, et son stop pour
+// un OCO, afin de libérer le BTC conservé. Faux si une annulation échoue (nouvel essai à la mise à
+// jour suivante) ou si la vente est déjà exécutée: le cycle poursuit alors son traitement normal.
+func cancelSellForAccumulation(client common.Exchange, cycle *database.Cycle, ev *tradeEvent) bool {
+	for _, orderId := range []string{cycle.SellId, cycle.StopId} {
+		if orderId == "" {
+			continue
+		}
+		cleanId := cleanOrderId(orderId, cycle.Exchange)
+		result, err := safeOrderCancel(client, cleanId, cycle.IdInt)
+		switch {
+		case result == common.CancelFailed:
+			ev.with("error", err).warn(i18n.T("update.accumulation_cancel_failed"), cycle.IdInt, orderId, err)
+			return false
+		case result == common.AlreadyGone && orderFilled(client, cleanId):
+			ev.info(i18n.T("update.accumulation_sell_filled"), cycle.IdInt, orderId)
+			return false
+		}
+	}
+	return true
+}
+
 // checkAccumulationConditions vérifie si les conditions sont remplies pour annuler un ordre de vente pour accumulation
 func checkAccumulationConditions(
 	cycle *database.Cycle,
@@ -1372,64 +1530,95 @@ func checkAccumulationConditions(
 		return false, deviationPercent, nil
 	}
 
-	// Calculer le profit global de l'exchange
-	exchangeProfit, err := calculateExchangeProfit(cycle.Exchange)
-	if err != nil {
-		return false, deviationPercent, err
-	}
-
-	// Calculer la valeur des accumulations déjà effectuées
-	totalAccumulatedValue, err := accuRepo.GetTotalAccumulatedValue(cycle.Exchange)
+	// Vérifier que la valeur de l'ordre actuel reste dans les plafonds d'accumulation
+	headroom, err := getAccumulationHeadroom(cycle.Exchange, exchangeConfig, accuRepo)
 	if err != nil {
 		return false, deviationPercent, err
 	}
 
-	// Calculer la valeur de l'ordre actuel
-	cycleValue := cycle.Quantity * cycle.SellPrice
+	return headroom.allows(cycle.Quantity * cycle.SellPrice), deviationPercent, nil
+}
 
-	// Vérifier si le profit disponible est suffisant pour annuler cet ordre
-	profitAvailable := exchangeProfit - totalAccumulatedValue
+// accumulationHeadroom décrit les plafonds d'accumulation d'un exchange et la marge restante
+type accumulationHeadroom struct {
+	Profit        float64 // Profit réalisé de l'exchange
+	Accumulated   float64 // Valeur déjà accumulée
+	ProfitPercent float64 // Part du profit consacrée aux accumulations (MAX_ACCUMULATION_PERCENT_OF_PROFIT)
+	Remaining     float64 // Part du profit encore disponible pour accumuler
+	SingleCapUSDC float64 // Valeur maximale d'une accumulation (0 = illimitée)
+}
 
-	return profitAvailable >= cycleValue, deviationPercent, nil
+// allows indique si une accumulation de la valeur donnée respecte les plafonds
+func (h accumulationHeadroom) allows(value float64) bool {
+	return value <= h.Remaining && (h.SingleCapUSDC <= 0 || value <= h.SingleCapUSDC)
 }
 
-// calculateExchangeProfit calcule le profit global pour un exchange donné
-func calculateExchangeProfit(exchange string) (float64, error) {
-	repo := database.GetRepository()
-	cycles, err := repo.FindAll()
+// getAccumulationHeadroom calcule la part du profit réalisé de l'exchange encore disponible
+// pour accumuler, après les accumulations déjà effectuées
+func getAccumulationHeadroom(exchange string, exchangeConfig config.ExchangeConfig, accuRepo *database.AccumulationRepository) (accumulationHeadroom, error) {
+	headroom := accumulationHeadroom{
+		ProfitPercent: exchangeConfig.MaxAccumulationPercentOfProfit,
+		SingleCapUSDC: exchangeConfig.MaxSingleAccumulationUSDC,
+	}
+	if headroom.ProfitPercent <= 0 {
+		headroom.ProfitPercent = 100
+	}
+
+	// Calculer le profit global de l'exchange
+	profit, err := calculateExchangeProfit(exchange)
 	if err != nil {
-		return 0, err
+		return headroom, err
+	}
+
+	// Calculer la valeur des accumulations déjà effectuées
+	accumulated, err := accuRepo.GetTotalAccumulatedValue(exchange)
+	if err != nil {
+		return headroom, err
 	}
 
+	headroom.Profit = profit
+	headroom.Accumulated = accumulated
+	headroom.Remaining = profit*headroom.ProfitPercent/100 - accumulated
+	return headroom, nil
+}
+
+// calculateExchangeProfit calcule le profit global pour un exchange donné, en parcourant les
+// cycles complétés des collections active et archivée sans les charger en mémoire
+func calculateExchangeProfit(exchange string) (float64, error) {
 	var totalProfit float64
-	for _, cycle := range cycles {
-		// Ne considérer que les cycles de l'exchange spécifié et complétés
-		if cycle.Exchange == exchange && cycle.Status == "completed" {
-			// Calculer le profit net pour ce cycle
-			buyValue := cycle.BuyPrice * cycle.Quantity
-			sellValue := cycle.SellPrice * cycle.Quantity
-			grossProfit := sellValue - buyValue
-
-			// Utiliser les frais stockés ou estimer si nécessaire
-			fees := cycle.TotalFees
-			if fees <= 0 {
-				// Si aucun frais n'est stocké, utiliser une estimation
-				fees = grossProfit * getFeeRateForExchange(exchange) * 2 // Achat + vente
-			}
+	sumProfit := func(cycle *database.Cycle) error {
+		if cycle.Exchange != exchange {
+			return nil
+		}
+		grossProfit := (cycle.EffectiveSellPrice() - cycle.EffectiveBuyPrice()) * cycle.Quantity
 
-			netProfit := grossProfit - fees
-			totalProfit += netProfit
+		// Utiliser les frais stockés ou estimer si nécessaire
+		fees := cycle.TotalFees
+		if fees == 0 {
+			// Si aucun frais n'est stocké, utiliser une estimation
+			fees = grossProfit * getFeeRateForExchange(exchange) * 2 // Achat + vente
 		}
+
+		totalProfit += grossProfit - fees
+		return nil
+	}
+
+	if err := database.GetRepository().ForEach("completed", sumProfit); err != nil {
+		return 0, err
+	}
+	// Les cycles archivés comptent toujours dans le profit réalisé
+	if err := database.GetArchiveRepository().ForEach("completed", sumProfit); err != nil {
+		return 0, err
 	}
 
 	return totalProfit, nil
 }
 
-func displayAccumulationInfo(exchange string) {
+func displayAccumulationInfo(exchange string, currentPrice float64) {
 	accuRepo := database.GetAccumulationRepository()
 
 	// Vérifier si l'accumulation est activée pour cet exchange
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Get()
 	if err != nil {
 		return
 	}
@@ -1444,121 +1633,74 @@ func displayAccumulationInfo(exchange string) {
 	}
 
 	// Récupérer les statistiques d'accumulation
-	stats, err := accuRepo.GetExchangeAccumulationStats(exchange)
+	stats, err := accuRepo.GetExchangeAccumulationStats(exchange, currentPrice)
 	if err != nil {
-		color.Red("Erreur lors de la récupération des statistiques d'accumulation: %v", err)
+		color.Red(i18n.T("update.accumulation_stats_error"), err)
 		return
 	}
 
 	// Récupérer le profit disponible
 	profit, err := calculateExchangeProfit(exchange)
 	if err != nil {
-		color.Red("Erreur lors du calcul des profits: %v", err)
+		color.Red(i18n.T("update.profit_error"), err)
 		return
 	}
 
 	accuValue, _ := accuRepo.GetTotalAccumulatedValue(exchange)
 	profitAvailable := profit - accuValue
 
+	headroom, err := getAccumulationHeadroom(exchange, exchangeConfig, accuRepo)
+	if err != nil {
+		color.Red(i18n.T("update.profit_error"), err)
+		return
+	}
+	singleCap := i18n.T("update.accumulation_unlimited")
+	if headroom.SingleCapUSDC > 0 {
+		singleCap = fmt.Sprintf("%.2f USDC", headroom.SingleCapUSDC)
+	}
+
 	fmt.Println("")
-	color.Cyan("=== INFORMATIONS D'ACCUMULATION POUR %s ===", exchange)
-	color.White("Accumulation:                  %s", color.GreenString("Activée"))
-	color.White("Déviation minimale configurée: %.2f%%", exchangeConfig.SellAccuPriceDeviation)
-	color.White("Profit total:                  %.2f USDC", profit)
-	color.White("Valeur déjà accumulée:         %.2f USDC", accuValue)
-	color.White("Profit disponible:             %.2f USDC", profitAvailable)
-	color.White("Nombre d'accumulations:        %d", stats["count"])
+	color.Cyan(i18n.T("update.accumulation_heading"), exchange)
+	color.White(i18n.T("update.accumulation_status"), color.GreenString(i18n.T("update.accumulation_enabled")))
+	color.White(i18n.T("update.accumulation_min_deviation"), exchangeConfig.SellAccuPriceDeviation)
+	color.White(i18n.T("update.accumulation_profit"), profit)
+	color.White(i18n.T("update.accumulation_value"), accuValue)
+	color.White(i18n.T("update.accumulation_available"), profitAvailable)
+	color.White(i18n.T("update.accumulation_profit_cap"), headroom.ProfitPercent)
+	color.White(i18n.T("update.accumulation_single_cap"), singleCap)
+	color.White(i18n.T("update.accumulation_headroom"), headroom.Remaining)
+	color.White(i18n.T("update.accumulation_count"), stats["count"])
 
 	if stats["count"].(int) > 0 {
-		color.White("Quantité totale accumulée:     %.8f BTC", stats["totalQuantity"])
-		color.White("Économie réalisée:             %.2f USDC", stats["savedValue"])
-		color.White("Déviation moyenne:             %.2f%%", stats["averageDeviation"])
+		color.White(i18n.T("update.accumulation_quantity"), stats["totalQuantity"])
+		color.White(i18n.T("update.accumulation_saved"), stats["savedValue"])
+		if stats["hasCurrentPrice"].(bool) {
+			color.White(i18n.T("update.accumulation_current_value"), stats["currentValue"], stats["unrealizedGain"])
+		}
+		color.White(i18n.T("update.accumulation_avg_deviation"), stats["averageDeviation"])
 	}
 	fmt.Println("")
 }
 
-// safeOrderCancel tente d'annuler un ordre et gère correctement les erreurs qui indiquent un succès
-func safeOrderCancel(client common.Exchange, orderId string, cycleId int32) (bool, error) {
-	// Vérifier si c'est un ID MEXC et appliquer un traitement spécial si nécessaire
-	if strings.Contains(orderId, "C02__") || strings.HasPrefix(orderId, "C02__") {
-		// Pour MEXC, tenter d'abord avec l'ID tel quel
-		_, err := client.CancelOrder(orderId)
-		if err == nil {
-			return true, nil
-		}
-
-		// Si ça échoue, essayer sans le préfixe
-		cleanId := strings.TrimPrefix(orderId, "C02__")
-		if cleanId != orderId {
-			_, err = client.CancelOrder(cleanId)
-			if err == nil {
-				return true, nil
-			}
-		}
-
-		// Si ça échoue encore, essayer avec le préfixe si l'ID n'en avait pas
-		if !strings.HasPrefix(orderId, "C02__") {
-			prefixedId := "C02__" + orderId
-			_, err = client.CancelOrder(prefixedId)
-			if err == nil {
-				return true, nil
-			}
-		}
-
-		// Si toutes les tentatives ont échoué, retourner l'erreur
-		return false, fmt.Errorf("impossible d'annuler l'ordre MEXC (toutes les méthodes tentées): %v", err)
-	}
-
-	// Tentative d'annulation de l'ordre
-	_, err := client.CancelOrder(orderId)
-
-	// Vérifier si l'erreur est en fait un succès déguisé
-	if err != nil {
-		errMsg := strings.ToLower(err.Error())
-
-		// Liste des messages d'erreur qui indiquent un succès
-		successPhrases := []string{
-			"order cancelled",
-			"canceled",
-			"success",
-			"already closed",
-			"does not exist",
-			"not found",
-			"unknown order", // Pour les ordres déjà exécutés ou annulés
-		}
-
-		// Vérifier si l'un des messages de succès est dans l'erreur
-		for _, phrase := range successPhrases {
-			if strings.Contains(errMsg, phrase) {
-				color.Yellow("Cycle %d: Annulation réussie malgré le message d'erreur: %v", cycleId, err)
-				return true, nil // Considérer comme un succès
-			}
-		}
-
-		// C'est une vraie erreur
-		return false, err
+// safeOrderCancel annule un ordre via CancelOrderIdempotent, qui interprète les erreurs
+// propres à chaque exchange. Un ordre déjà fermé (AlreadyGone) a pu être exécuté:
+// à l'appelant de le vérifier avec orderFilled si nécessaire.
+func safeOrderCancel(client common.Exchange, orderId string, cycleId int32) (common.CancelResult, error) {
+	result, err := client.CancelOrderIdempotent(orderId)
+	if result == common.AlreadyGone {
+		color.Yellow(i18n.T("update.order_already_gone"), cycleId, orderId)
 	}
+	return result, err
+}
 
-	// Aucune erreur, l'annulation a réussi normalement
-	return true, nil
+// orderFilled indique si un ordre qui n'est plus ouvert sur l'exchange a été exécuté
+func orderFilled(client common.Exchange, orderId string) bool {
+	status, err := client.GetOrderStatus(orderId)
+	return err == nil && status.Filled()
 }
 
-// getFeeRateForExchange retourne le taux de frais pour un exchange et un type d'ordre donnés
-func getFeeRateForExchange(exchange string) float64 {
-	switch strings.ToUpper(exchange) {
-	case "KRAKEN":
-		// Kraken: 0.26% frais maker standard
-		return 0.0026
-	case "BINANCE":
-		// Binance: 0.1% standard
-		return 0.001
-	case "MEXC":
-		return 0.0
-	case "KUCOIN":
-		// KuCoin: 0.1% standard
-		return 0.001
-	default:
-		// Valeur par défaut pour les exchanges non reconnus
-		return 0.001
-	}
+// orderExpired indique si l'exchange a annulé l'ordre à sa date d'expiration, sans exécution
+func orderExpired(client common.Exchange, orderId string) bool {
+	status, err := client.GetOrderStatus(orderId)
+	return err == nil && status.Expired && status.ExecutedQty <= 0
 }
@@ -0,0 +1,1324 @@
+package commands
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+	"main/internal/exchanges/common"
+	"main/internal/exchanges/testutil"
+)
+
+// TestMain ouvre une base temporaire: les tests de cycles ne touchent pas à la base du bot
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bot-spot-test")
+	if err != nil {
+		panic(err)
+	}
+	database.InitDatabaseAt(filepath.Join(dir, "db"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// useMockExchange configure BINANCE avec les paramètres indiqués et le remplace par un exchange simulé
+func useMockExchange(t *testing.T, exchangeConfig config.ExchangeConfig, price float64) *testutil.MockExchange {
+	t.Helper()
+
+	exchangeConfig.Name = "BINANCE"
+	exchangeConfig.Enabled = true
+	if exchangeConfig.MakerFeeRate == 0 && exchangeConfig.TakerFeeRate == 0 {
+		exchangeConfig.MakerFeeRate, exchangeConfig.TakerFeeRate = config.DefaultFeeRates("BINANCE")
+	}
+	testConfig := &config.Config{
+		MainExchangeName: "BINANCE",
+		Exchanges:        map[string]config.ExchangeConfig{"BINANCE": exchangeConfig},
+	}
+	SetConfig(testConfig)
+	config.Set(testConfig)
+
+	mock := testutil.NewMockExchange(price)
+	RegisterClient("BINANCE", mock)
+	t.Cleanup(func() { RegisterClient("BINANCE", nil) })
+	return mock
+}
+
+// buyOrderSeq numérote les ordres d'achat des cycles de test: deux cycles ne peuvent pas
+// suivre le même ordre
+var buyOrderSeq = 28457112
+
+// saveBuyCycle enregistre un cycle en attente d'achat sur un ordre ouvert du mock
+func saveBuyCycle(t *testing.T, mock *testutil.MockExchange, buyPrice, quantity float64) *database.Cycle {
+	t.Helper()
+
+	repo := database.GetRepository()
+	buyOrderSeq++
+	cycle := &database.Cycle{
+		Exchange:  "BINANCE",
+		Status:    "buy",
+		Quantity:  quantity,
+		BuyPrice:  buyPrice,
+		BuyId:     mock.AddOrder(strconv.Itoa(buyOrderSeq), "BUY", buyPrice, quantity),
+		SellPrice: buyPrice + 1200,
+	}
+	if _, err := repo.Save(cycle); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+	return cycle
+}
+
+func TestCycleBuyThenSell(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	client := GetClientByExchange("BINANCE")
+	if client != mock {
+		t.Fatalf("GetClientByExchange devrait retourner le client enregistré")
+	}
+
+	// Achat non exécuté: aucun ordre de vente
+	processBuyCycle(client, repo, cycle, 60100)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 0 {
+		t.Fatalf("ordre créé avant l'exécution de l'achat: %+v", calls)
+	}
+
+	// Achat exécuté: la vente est placée à BuyPrice + SellOffset
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(client, repo, cycle, 60100)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu 1", len(calls))
+	}
+	if side, price, quantity := calls[0].Args[0], calls[0].Args[1], calls[0].Args[2]; side != "SELL" || price != "61200.00" || quantity != "0.00150000" {
+		t.Errorf("ordre de vente inattendu: %v %v %v", side, price, quantity)
+	}
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "sell" || stored.SellId == "" || stored.SellPrice != 61200 {
+		t.Fatalf("cycle après l'achat: statut %q, vente %q à %.2f", stored.Status, stored.SellId, stored.SellPrice)
+	}
+
+	// Vente exécutée: le cycle est terminé
+	if err := mock.FillOrder(stored.SellId); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(client, repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "completed" {
+		t.Errorf("statut après la vente = %q, attendu completed", stored.Status)
+	}
+}
+
+func TestOrderSnapshotsKept(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(client, repo, cycle, 60100)
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if err := mock.FillOrder(stored.SellId); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(client, repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	for side, orderId := range map[string]string{database.OrderSnapshotBuy: stored.BuyId, database.OrderSnapshotSell: stored.SellId} {
+		snapshot := stored.OrderSnapshots.Get(side)
+		if snapshot == nil || snapshot.OrderId != orderId || snapshot.Truncated {
+			t.Fatalf("réponse de l'ordre %s: %+v, attendu l'ordre %s", side, snapshot, orderId)
+		}
+		raw, err := snapshot.Raw()
+		if err != nil {
+			t.Fatalf("décompression de la réponse de l'ordre %s: %v", side, err)
+		}
+		want, _ := mock.GetOrderById(orderId)
+		if string(raw) != string(want) || snapshot.Size != len(want) {
+			t.Errorf("réponse de l'ordre %s = %s (%d octets), attendu %s", side, raw, snapshot.Size, want)
+		}
+	}
+
+	// Une réponse trop grande est tronquée
+	large, err := database.NewOrderSnapshot("1", make([]byte, database.MaxOrderSnapshotSize+10), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := large.Raw(); !large.Truncated || len(raw) != database.MaxOrderSnapshotSize || large.Size != database.MaxOrderSnapshotSize+10 {
+		t.Errorf("réponse tronquée: %d octets conservés sur %d, tronquée = %v", len(raw), large.Size, large.Truncated)
+	}
+
+	// La rétention supprime les réponses des cycles plus anciens que la limite
+	stripped, err := repo.StripOrderSnapshotsBefore(time.Now().Add(time.Hour))
+	if err != nil || stripped != 1 {
+		t.Fatalf("%d cycle(s) allégé(s) (%v), attendu 1", stripped, err)
+	}
+	if stored, _ = repo.FindByIdInt(cycle.IdInt); !stored.OrderSnapshots.Empty() {
+		t.Errorf("réponses conservées après la rétention: %+v", stored.OrderSnapshots)
+	}
+}
+
+func TestSellQuantityAfterBTCFees(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	mock.Steps = common.Precision{QuantityStep: 0.00001}
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	// Achat exécuté, 0.1% de frais prélevés en BTC: le solde libre est un peu inférieur à la
+	// quantité achetée diminuée des frais
+	mock.BaseFees[cycle.BuyId] = 0.0000015
+	mock.Fees[cycle.BuyId] = 0.0000015 * 60000
+	mock.SetBalance("BTC", 0.00149849)
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	// La vente porte sur min(quantité achetée - frais en BTC, solde libre), arrondi au pas inférieur
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu 1", len(calls))
+	}
+	if side, quantity := calls[0].Args[0], calls[0].Args[2]; side != "SELL" || quantity != "0.00149" {
+		t.Errorf("ordre de vente %v de %v BTC, attendu SELL de 0.00149 BTC", side, quantity)
+	}
+
+	// Les frais en BTC sont conservés à part: la quantité détenue et son coût les excluent,
+	// leur valeur en USDC reste dans les frais
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if math.Abs(stored.Quantity-0.0014985) > 1e-12 || math.Abs(stored.BuyFeeBTC-0.0000015) > 1e-12 {
+		t.Errorf("quantité %.8f et frais en BTC %.8f, attendu 0.0014985 et 0.0000015", stored.Quantity, stored.BuyFeeBTC)
+	}
+	if math.Abs(stored.PurchaseAmountUSDC-89.91) > 1e-9 || math.Abs(stored.TotalFees-0.09) > 1e-9 {
+		t.Errorf("montant d'achat %.4f et frais %.4f, attendu 89.91 et 0.09", stored.PurchaseAmountUSDC, stored.TotalFees)
+	}
+}
+
+func TestCycleAmountsFromExecutedQuote(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	// Achat exécuté en plusieurs fois sous le prix limite: 89.91 USDC au lieu de 90
+	if err := mock.FillOrderAmount(cycle.BuyId, 89.91); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(mock, repo, cycle, 60100)
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.PurchaseAmountUSDC != 89.91 {
+		t.Errorf("montant d'achat %.4f, attendu 89.91 (cummulativeQuoteQty)", stored.PurchaseAmountUSDC)
+	}
+
+	// Vente exécutée au-dessus du prix limite: 91.85 USDC au lieu de 91.80
+	if err := mock.FillOrderAmount(stored.SellId, 91.85); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(mock, repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "completed" || stored.PurchaseAmountUSDC != 89.91 || stored.SaleAmountUSDC != 91.85 {
+		t.Errorf("cycle %s: achat %.4f, vente %.4f, attendu 89.91 et 91.85", stored.Status, stored.PurchaseAmountUSDC, stored.SaleAmountUSDC)
+	}
+}
+
+func TestCycleBuyCancelledOnPriceDeviation(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, BuyMaxPriceDeviation: 5}, 63500)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	// 63500 dépasse le seuil de 63000 (60000 + 5%): l'achat est annulé
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 63500)
+
+	if status := mock.OrderStatus(cycle.BuyId); status != "CANCELED" {
+		t.Errorf("statut de l'ordre d'achat = %q, attendu CANCELED", status)
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "cancelled" {
+		t.Errorf("statut du cycle = %q, attendu cancelled", stored.Status)
+	}
+	if stored.CancelReason != database.CancelReasonPriceDeviation || stored.CancelledAt.IsZero() {
+		t.Errorf("annulation enregistrée: cause %q, date %v", stored.CancelReason, stored.CancelledAt)
+	}
+
+	stats := calculateGlobalStats([]*database.Cycle{stored, {Status: "cancelled"}})
+	if stats.CancelledCycles != 2 || stats.CancellationsByReason[database.CancelReasonPriceDeviation] != 1 ||
+		stats.CancellationsByReason["unknown"] != 1 || stats.CancellationsByReason[database.CancelReasonManual] != 0 {
+		t.Errorf("annulations par cause: %d %v", stats.CancelledCycles, stats.CancellationsByReason)
+	}
+}
+
+func TestCycleBuyCancelRetried(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, BuyMaxPriceDeviation: 5}, 63500)
+	repo := database.GetRepository()
+	client := GetClientByExchange("BINANCE")
+
+	// L'annulation échoue: l'ordre peut encore s'exécuter, le cycle reste suivi
+	cancelled := saveBuyCycle(t, mock, 60000, 0.0015)
+	mock.Errors["CancelOrderIdempotent"] = errors.New("HTTP status 503 - timeout")
+	processBuyCycle(client, repo, cancelled, 63500)
+
+	stored, err := repo.FindByIdInt(cancelled.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != database.StatusCancelPending || stored.CancelReason != database.CancelReasonPriceDeviation ||
+		stored.CancelRetryCount != 1 || stored.CancelRetryError == "" {
+		t.Fatalf("après l'échec: statut %q, cause %q, tentatives %d, erreur %q",
+			stored.Status, stored.CancelReason, stored.CancelRetryCount, stored.CancelRetryError)
+	}
+
+	// L'exchange répond de nouveau: l'annulation est confirmée à la mise à jour suivante
+	delete(mock.Errors, "CancelOrderIdempotent")
+	retryPendingCancel(client, repo, stored, 63500)
+
+	if status := mock.OrderStatus(stored.BuyId); status != "CANCELED" {
+		t.Errorf("statut de l'ordre d'achat = %q, attendu CANCELED", status)
+	}
+	stored, _ = repo.FindByIdInt(cancelled.IdInt)
+	if stored.Status != "cancelled" || stored.CancelReason != database.CancelReasonPriceDeviation ||
+		stored.CancelRetryCount != 0 || stored.CancelledAt.IsZero() {
+		t.Errorf("après la reprise: statut %q, cause %q, tentatives %d", stored.Status, stored.CancelReason, stored.CancelRetryCount)
+	}
+
+	// L'achat est exécuté avant que l'annulation n'aboutisse: le cycle reprend et la vente est placée
+	filled := saveBuyCycle(t, mock, 60000, 0.0015)
+	mock.Errors["CancelOrderIdempotent"] = errors.New("HTTP status 503 - timeout")
+	processBuyCycle(client, repo, filled, 63500)
+	delete(mock.Errors, "CancelOrderIdempotent")
+	if err := mock.FillOrder(filled.BuyId); err != nil {
+		t.Fatalf("exécution de l'achat: %v", err)
+	}
+
+	pending, _ := repo.FindByIdInt(filled.IdInt)
+	retryPendingCancel(client, repo, pending, 63500)
+
+	stored, _ = repo.FindByIdInt(filled.IdInt)
+	if stored.Status != "sell" || stored.SellId == "" || stored.CancelReason != "" || stored.CancelRetryCount != 0 {
+		t.Errorf("après l'exécution: statut %q, vente %q, cause %q, tentatives %d",
+			stored.Status, stored.SellId, stored.CancelReason, stored.CancelRetryCount)
+	}
+}
+
+// Un achat partiellement exécuté avant la confirmation de son annulation garde la part achetée:
+// le cycle passe en vente de cette seule quantité au lieu d'être annulé
+func TestCycleBuyCancelPartiallyFilled(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, BuyMaxPriceDeviation: 5}, 63500)
+	repo := database.GetRepository()
+	client := GetClientByExchange("BINANCE")
+
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	mock.Errors["CancelOrderIdempotent"] = errors.New("HTTP status 503 - timeout")
+	processBuyCycle(client, repo, cycle, 63500)
+	delete(mock.Errors, "CancelOrderIdempotent")
+	if err := mock.PartiallyFillOrder(cycle.BuyId, 0.0006); err != nil {
+		t.Fatalf("exécution partielle de l'achat: %v", err)
+	}
+	mock.SetBalance("BTC", 0.0006)
+
+	pending, _ := repo.FindByIdInt(cycle.IdInt)
+	retryPendingCancel(client, repo, pending, 63500)
+
+	if status := mock.OrderStatus(cycle.BuyId); status != "CANCELED" {
+		t.Errorf("statut de l'ordre d'achat = %q, attendu CANCELED", status)
+	}
+	stored, _ := repo.FindByIdInt(cycle.IdInt)
+	if stored.Status != "sell" || stored.Quantity != 0.0006 || stored.CancelReason != "" || stored.CancelRetryCount != 0 {
+		t.Fatalf("après l'annulation: statut %q, quantité %.8f, cause %q, tentatives %d",
+			stored.Status, stored.Quantity, stored.CancelReason, stored.CancelRetryCount)
+	}
+	if stored.PurchaseAmountUSDC != 36 || stored.BuyFees <= 0 || !stored.FeesEstimated {
+		t.Errorf("achat de %.2f USDC, frais %.4f (estimés %v), attendu 36 USDC et des frais estimés",
+			stored.PurchaseAmountUSDC, stored.BuyFees, stored.FeesEstimated)
+	}
+	calls := mock.CallsTo("CreateOrder")
+	if stored.SellId == "" || len(calls) == 0 || calls[len(calls)-1].Args[2] != client.FormatQuantity(0.0006) {
+		t.Errorf("vente %q de la part exécutée non placée: %+v", stored.SellId, calls)
+	}
+}
+
+func TestBuyOrderExchangeExpiry(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, BuyMaxDays: 3, UseExchangeExpiry: true}, 60100)
+	repo := database.GetRepository()
+	createdAt := time.Now().Add(-time.Hour)
+
+	// Exchange sans expiration d'ordre: l'achat est placé sans échéance
+	if _, _, err := createBuyOrder(mock, "BINANCE", 60000, "0.00150000", "", createdAt); err != nil {
+		t.Fatalf("createBuyOrder: %v", err)
+	}
+	mock.SupportsExpiry = true
+	if _, _, err := createBuyOrder(mock, "BINANCE", 60000, "0.00150000", "", createdAt); err != nil {
+		t.Fatalf("createBuyOrder: %v", err)
+	}
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 2 || !calls[0].Args[6].(time.Time).IsZero() {
+		t.Fatalf("achat avec échéance sur un exchange qui ne la supporte pas: %+v", calls)
+	}
+	if expireAt := calls[1].Args[6].(time.Time); !expireAt.Equal(createdAt.Add(72 * time.Hour)) {
+		t.Errorf("échéance de l'achat %v, attendu %v", expireAt, createdAt.Add(72*time.Hour))
+	}
+
+	// Un achat replacé passe par le client sous disjoncteur: l'échéance est conservée
+	if _, _, err := createBuyOrder(guardedClient("BINANCE"), "BINANCE", 60000, "0.00150000", "", createdAt); err != nil {
+		t.Fatalf("createBuyOrder: %v", err)
+	}
+	calls = mock.CallsTo("CreateOrder")
+	if len(calls) != 3 || !calls[2].Args[6].(time.Time).Equal(createdAt.Add(72*time.Hour)) {
+		t.Fatalf("achat sous disjoncteur sans échéance: %+v", calls)
+	}
+
+	// L'exchange fait expirer l'achat: le cycle est annulé sans nouvelle annulation d'ordre
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	if err := mock.ExpireOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "cancelled" || stored.CancelReason != database.CancelReasonExpired {
+		t.Errorf("cycle %q (%q), attendu cancelled (expired)", stored.Status, stored.CancelReason)
+	}
+	if calls := mock.CallsTo("CancelOrderIdempotent"); len(calls) != 0 {
+		t.Errorf("annulation d'un ordre déjà expiré: %+v", calls)
+	}
+}
+
+func TestCycleOCOStopFilled(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, UseOCO: true, OCOStopLossPercent: 5, OCOStopLimitPercent: 0.5}, 60100)
+	mock.SupportsOCO = true
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(client, repo, cycle, 60100)
+
+	// Stop déclenché 5% sous l'achat (57000), limite 0.5% plus bas
+	calls := mock.CallsTo("CreateOCOOrder")
+	if len(calls) != 1 || len(mock.CallsTo("CreateOrder")) != 0 {
+		t.Fatalf("%d OCO et %d ordres simples créés, attendu une OCO seule", len(calls), len(mock.CallsTo("CreateOrder")))
+	}
+	if sell, stop, limit := calls[0].Args[0], calls[0].Args[1], calls[0].Args[2]; sell != 61200.0 || stop != 57000.0 || limit != 56715.0 {
+		t.Errorf("prix de l'OCO inattendus: %v %v %v", sell, stop, limit)
+	}
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "sell" || stored.SellId == "" || stored.StopId == "" || stored.StopPrice != 56715 {
+		t.Fatalf("cycle après l'achat: statut %q, vente %q, stop %q à %.2f", stored.Status, stored.SellId, stored.StopId, stored.StopPrice)
+	}
+
+	// Le stop est exécuté: la vente limite expire et le cycle est terminé au prix du stop
+	if err := mock.FillOrder(stored.StopId); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(client, repo, stored, mock.Price)
+
+	if status := mock.OrderStatus(stored.SellId); status != "EXPIRED" {
+		t.Errorf("statut de la vente limite = %q, attendu EXPIRED", status)
+	}
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "completed" || !stored.StoppedOut || stored.SellFillPrice != 56715 {
+		t.Errorf("cycle après le stop: statut %q, stop exécuté %v, prix de vente %.2f", stored.Status, stored.StoppedOut, stored.SellFillPrice)
+	}
+	if stored.CalculateProfit() >= 0 {
+		t.Errorf("une sortie par le stop devrait être à perte: %.2f", stored.CalculateProfit())
+	}
+}
+
+func TestCycleOCOUnsupportedFallsBackToLimitSell(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, UseOCO: true, OCOStopLossPercent: 5}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	if len(mock.CallsTo("CreateOCOOrder")) != 1 || len(mock.CallsTo("CreateOrder")) != 1 {
+		t.Fatalf("attendu une tentative d'OCO puis une vente limite: %+v", mock.Calls())
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "sell" || stored.SellId == "" || stored.StopId != "" {
+		t.Errorf("cycle après l'achat: statut %q, vente %q, stop %q", stored.Status, stored.SellId, stored.StopId)
+	}
+}
+
+func TestUnrealizedProfit(t *testing.T) {
+	cycles := []*database.Cycle{
+		{Exchange: "BINANCE", Status: "sell", Quantity: 0.0015, BuyPrice: 60000, BuyFillPrice: 59950, TotalFees: 0.09},
+		{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyPrice: 63000},
+		{Exchange: "BINANCE", Status: "buy", Quantity: 0.001, BuyPrice: 61000},
+		{Exchange: "KRAKEN", Status: "sell", Quantity: 0.001, BuyPrice: 61000},
+	}
+
+	// (62000 - 59950) * 0.0015 - 0.09 de frais d'achat - 0.093 de frais de vente estimés
+	if profit, ok := unrealizedProfit(cycles[0], 62000); !ok || math.Abs(profit-2.892) > 1e-9 {
+		t.Errorf("unrealizedProfit = %.6f, %v, attendu 2.892", profit, ok)
+	}
+	if _, ok := unrealizedProfit(cycles[2], 62000); ok {
+		t.Errorf("un cycle en achat n'a pas de P&L latent")
+	}
+
+	// Sans prix connu pour KRAKEN, l'exchange est absent des totaux
+	totals := unrealizedByExchange(cycles, map[string]float64{"BINANCE": 62000})
+	if _, ok := totals["KRAKEN"]; ok || len(totals) != 1 || math.Abs(totals["BINANCE"]-(2.892-1.062)) > 1e-9 {
+		t.Errorf("P&L latent par exchange inattendu: %v", totals)
+	}
+}
+
+func TestCycleSummary(t *testing.T) {
+	useMockExchange(t, config.ExchangeConfig{}, 60000)
+	repo := database.GetRepository()
+
+	now := time.Now()
+	cycles := []*database.Cycle{
+		// 10 - 0.2 de frais, complété il y a 2 jours
+		{Status: "completed", Quantity: 0.001, BuyPrice: 60000, SellPrice: 70000, TotalFees: 0.2,
+			CreatedAt: now.AddDate(0, 0, -3), CompletedAt: now.AddDate(0, 0, -2)},
+		// 5 - 0.1 de frais, complété il y a 40 jours
+		{Status: "completed", Quantity: 0.001, BuyPrice: 60000, SellPrice: 65000, TotalFees: 0.1,
+			CreatedAt: now.AddDate(0, 0, -41), CompletedAt: now.AddDate(0, 0, -40)},
+		{Status: "sell", Quantity: 0.001, BuyPrice: 60000, SellPrice: 61200},
+		{Status: database.StatusCancelPending, Quantity: 0.001, BuyPrice: 59000},
+		{Status: "cancelled", Quantity: 0.001, BuyPrice: 58000},
+	}
+	for _, cycle := range cycles {
+		cycle.Exchange = "MEXC"
+		if _, err := repo.Save(cycle); err != nil {
+			t.Fatalf("enregistrement du cycle: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteByIdInt(cycle.IdInt) })
+	}
+
+	summaries, err := repo.Summarize(completedNetProfit, now.AddDate(0, 0, -7), now.AddDate(0, 0, -90))
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	summary := summaries["MEXC"]
+	if summary == nil || summary.Total != 5 || summary.Buy != 1 || summary.Sell != 1 || summary.Completed != 2 {
+		t.Fatalf("récapitulatif MEXC inattendu: %+v", summary)
+	}
+	if math.Abs(summary.Profit-14.7) > 1e-9 || math.Abs(summary.PeriodProfits[0]-9.8) > 1e-9 ||
+		math.Abs(summary.PeriodProfits[1]-14.7) > 1e-9 {
+		t.Errorf("profits %.4f, 7 jours %.4f, 90 jours %.4f, attendu 14.7, 9.8 et 14.7",
+			summary.Profit, summary.PeriodProfits[0], summary.PeriodProfits[1])
+	}
+
+	// Les cycles en cours seuls sont chargés pour la mise à jour
+	active, err := repo.FindActive()
+	if err != nil {
+		t.Fatalf("FindActive: %v", err)
+	}
+	mexcActive := 0
+	for _, cycle := range active {
+		if cycle.Exchange == "MEXC" {
+			mexcActive++
+		}
+	}
+	if mexcActive != 2 {
+		t.Errorf("%d cycles en cours chargés pour MEXC, attendu 2", mexcActive)
+	}
+
+	if profit, err := calculateExchangeProfit("MEXC"); err != nil || math.Abs(profit-14.7) > 1e-9 {
+		t.Errorf("profit MEXC = %.4f (%v), attendu 14.7", profit, err)
+	}
+}
+
+// BenchmarkUpdateCycleLoad compare le chargement des cycles de la mise à jour selon la taille de
+// l'historique: FindActive ne convertit que les cycles en cours, FindAll tout l'historique
+func BenchmarkUpdateCycleLoad(b *testing.B) {
+	repo := database.GetRepository()
+	saved := 0
+	for _, history := range []int{100, 1000} {
+		for ; saved < history; saved++ {
+			cycle := &database.Cycle{Exchange: "MEXC", Status: "completed", Quantity: 0.001, BuyPrice: 60000, SellPrice: 61200}
+			if saved < 10 {
+				cycle.Status = "sell"
+			}
+			if _, err := repo.Save(cycle); err != nil {
+				b.Fatalf("enregistrement du cycle: %v", err)
+			}
+			defer repo.DeleteByIdInt(cycle.IdInt)
+		}
+
+		for _, load := range []struct {
+			name string
+			find func() ([]*database.Cycle, error)
+		}{
+			{"FindAll", repo.FindAll},
+			{"FindActive", repo.FindActive},
+		} {
+			b.Run(load.name+"/"+strconv.Itoa(history), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := load.find(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestExchangeFeeRates(t *testing.T) {
+	useMockExchange(t, config.ExchangeConfig{MakerFeeRate: 0.00075, TakerFeeRate: 0.00095}, 60000)
+
+	// Taux configurés de l'exchange, niveau de base pour un exchange absent de la configuration
+	if rate := getFeeRateForExchange("binance"); rate != 0.00075 {
+		t.Errorf("taux maker BINANCE = %v, attendu 0.00075", rate)
+	}
+	if rate := getFeeRateForExchange("KRAKEN"); rate != 0.0026 {
+		t.Errorf("taux maker KRAKEN = %v, attendu 0.0026", rate)
+	}
+
+	// Le niveau lu sur le compte remplace les taux configurés
+	accountFeeRatesMu.Lock()
+	accountFeeRates["BINANCE"] = common.FeeRates{Maker: 0.0002, Taker: 0.0004}
+	accountFeeRatesMu.Unlock()
+	t.Cleanup(func() {
+		accountFeeRatesMu.Lock()
+		delete(accountFeeRates, "BINANCE")
+		accountFeeRatesMu.Unlock()
+	})
+	if rates := exchangeFeeRates("BINANCE"); rates.Maker != 0.0002 || rates.Taker != 0.0004 {
+		t.Errorf("taux du compte non appliqués: %+v", rates)
+	}
+}
+
+func TestCycleBuyFeesCached(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60000)
+	repo := database.GetRepository()
+
+	// Frais réels enregistrés à l'exécution de l'achat, frais estimés faute de réponse de l'API
+	stored := &database.Cycle{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyPrice: 59000,
+		BuyId: "8101", SellPrice: 60200, SellId: "8102", TotalFees: 0.059}
+	estimated := &database.Cycle{Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyPrice: 59000,
+		BuyId: "8103", SellPrice: 60200, SellId: "8104", TotalFees: 0.0472, FeesEstimated: true}
+	for _, cycle := range []*database.Cycle{stored, estimated} {
+		if _, err := repo.Save(cycle); err != nil {
+			t.Fatalf("enregistrement du cycle: %v", err)
+		}
+		id := cycle.IdInt
+		t.Cleanup(func() { repo.DeleteByIdInt(id) })
+	}
+	mock.Fees["8103"] = 0.055
+
+	if fees := cycleBuyFees(stored, 59); fees != 0.059 || len(mock.CallsTo("GetOrderFees")) != 0 {
+		t.Errorf("frais enregistrés: %.4f avec %d appel(s), attendu 0.059 sans appel", fees, len(mock.CallsTo("GetOrderFees")))
+	}
+
+	// Lus une fois puis enregistrés sur le cycle
+	if fees := cycleBuyFees(estimated, 59); fees != 0.055 {
+		t.Errorf("frais lus %.4f, attendu 0.055", fees)
+	}
+	reloaded, err := repo.FindByIdInt(estimated.IdInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.TotalFees != 0.055 || reloaded.FeesEstimated {
+		t.Errorf("frais enregistrés %.4f (estimés: %v), attendu 0.055 réels", reloaded.TotalFees, reloaded.FeesEstimated)
+	}
+
+	// Les affichages suivants ne sollicitent plus l'exchange
+	cycleBuyFees(reloaded, 59)
+	reloaded.FeesEstimated = true
+	cycleBuyFees(reloaded, 59)
+	if calls := mock.CallsTo("GetOrderFees"); len(calls) != 1 {
+		t.Errorf("%d appel(s) à GetOrderFees, attendu 1", len(calls))
+	}
+}
+
+func TestMakerRebateCycle(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.001)
+
+	// Remises maker reversées à l'achat et à la vente (frais négatifs)
+	mock.Fees[cycle.BuyId] = -0.006
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil || stored.Status != "sell" {
+		t.Fatalf("cycle après l'achat: %+v (%v)", stored, err)
+	}
+	if stored.TotalFees != -0.006 || stored.FeesEstimated {
+		t.Errorf("frais d'achat %.4f (estimés: %v), attendu la remise de -0.006", stored.TotalFees, stored.FeesEstimated)
+	}
+
+	mock.Fees[stored.SellId] = -0.0072
+	if err := mock.FillOrder(stored.SellId); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(GetClientByExchange("BINANCE"), repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil || stored.Status != "completed" {
+		t.Fatalf("cycle après la vente: %+v (%v)", stored, err)
+	}
+	if math.Abs(stored.TotalFees+0.0132) > 1e-12 {
+		t.Errorf("frais totaux %.4f, attendu -0.0132", stored.TotalFees)
+	}
+	// La remise s'ajoute au profit au lieu d'être remplacée par une estimation des frais
+	if profit := completedNetProfit(stored); math.Abs(profit-1.2132) > 1e-9 {
+		t.Errorf("profit net %.4f, attendu 1.2132", profit)
+	}
+}
+
+func TestCompletionFeesFromBuyFees(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.001)
+
+	mock.Fees[cycle.BuyId] = 0.06
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil || stored.Status != "sell" {
+		t.Fatalf("cycle après l'achat: %+v (%v)", stored, err)
+	}
+	if stored.BuyFees != 0.06 {
+		t.Errorf("frais d'achat enregistrés %.4f, attendu 0.06", stored.BuyFees)
+	}
+
+	// Frais totaux restés sur une ancienne estimation: seuls les frais d'achat enregistrés comptent
+	if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{"totalFees": 0.0472}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ = repo.FindByIdInt(cycle.IdInt)
+	mock.Fees[stored.SellId] = 0.0612
+	if err := mock.FillOrder(stored.SellId); err != nil {
+		t.Fatal(err)
+	}
+	processSellCycle(GetClientByExchange("BINANCE"), repo, stored, mock.Price)
+
+	stored, err = repo.FindByIdInt(cycle.IdInt)
+	if err != nil || stored.Status != "completed" {
+		t.Fatalf("cycle après la vente: %+v (%v)", stored, err)
+	}
+	if stored.BuyFees != 0.06 || stored.SellFees != 0.0612 || math.Abs(stored.TotalFees-0.1212) > 1e-12 {
+		t.Errorf("frais achat %.4f, vente %.4f, total %.4f, attendu 0.06 + 0.0612 = 0.1212",
+			stored.BuyFees, stored.SellFees, stored.TotalFees)
+	}
+	if profit := completedNetProfit(stored); math.Abs(profit-1.0788) > 1e-9 {
+		t.Errorf("profit net %.4f, attendu 1.0788", profit)
+	}
+}
+
+func TestSellResumedAfterCrash(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+
+	// Vente placée par une exécution interrompue avant l'enregistrement de son ID dans le cycle
+	clientOrderID := common.ClientOrderID(cycle.IdInt, "sell")
+	created, err := mock.CreateOrder("SELL", "61200.00", "0.00150000", common.OrderOptions{ClientOrderID: clientOrderID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderId := created.OrderID
+
+	// La mise à jour suivante reprend la vente au lieu d'en placer une seconde
+	processBuyCycle(client, repo, cycle, 60100)
+
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu uniquement la vente d'avant l'interruption", len(calls))
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "sell" || stored.SellId != orderId || stored.SellClientOrderId != clientOrderID {
+		t.Errorf("cycle après reprise: statut %q, vente %q (%q)", stored.Status, stored.SellId, stored.SellClientOrderId)
+	}
+}
+
+// Avec SELL_OFFSET_PERCENT, la vente est placée en % au-dessus du prix d'achat, SELL_OFFSET servant
+// de plancher
+func TestSellOffsetPercent(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 300, SellOffsetPercent: 1}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu 1", len(calls))
+	}
+	if price := calls[0].Args[1]; price != "60600.00" {
+		t.Errorf("prix de vente = %v, attendu 60600.00 (1%% au-dessus de l'achat)", price)
+	}
+}
+
+// Le prix de vente est arrondi au pas de prix de l'exchange (0.1 USDC ici)
+func TestSellPriceRoundedToTick(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 600.04}, 60100)
+	mock.Steps = common.Precision{PriceTick: 0.1, QuantityStep: 0.00001}
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu 1", len(calls))
+	}
+	if price, quantity := calls[0].Args[1], calls[0].Args[2]; price != "60600.0" || quantity != "0.00150" {
+		t.Errorf("vente de %v BTC à %v, attendu 0.00150 BTC à 60600.0", quantity, price)
+	}
+}
+
+func TestSellPlacedWithClientOrderID(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(GetClientByExchange("BINANCE"), repo, cycle, 60100)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu 1", len(calls))
+	}
+	if clientOrderID := calls[0].Args[4]; clientOrderID != common.ClientOrderID(cycle.IdInt, "sell") {
+		t.Errorf("identifiant client de la vente = %v", clientOrderID)
+	}
+}
+
+// Sur MEXC, le BTC acheté peut n'apparaître dans le solde que quelques instants après l'exécution:
+// la vente échoue en "Oversold", le cycle attend sa vente puis la place dès que le solde est crédité
+func TestOversoldSellRetried(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	mock.Errors["CreateOrder"] = errors.New(`HTTP status 400 - {"code":30005,"msg":"Oversold"}`)
+	processBuyCycle(client, repo, cycle, 60100)
+
+	reload := func() *database.Cycle {
+		t.Helper()
+		stored, err := repo.FindByIdInt(cycle.IdInt)
+		if err != nil {
+			t.Fatalf("lecture du cycle: %v", err)
+		}
+		return stored
+	}
+	// Rend la tentative suivante immédiate, sans attendre le délai
+	retryNow := func() *database.Cycle {
+		t.Helper()
+		if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+			"sellRetryAt": time.Now().Add(-time.Second).Format(time.RFC3339),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return reload()
+	}
+
+	stored := reload()
+	if !sellPending(stored) || stored.SellRetryCount != 1 || stored.SellClientOrderId == "" ||
+		!stored.SellRetryAt.After(time.Now()) {
+		t.Fatalf("cycle après l'échec: statut %q, vente %q, %d échec(s), prochaine tentative %v",
+			stored.Status, stored.SellId, stored.SellRetryCount, stored.SellRetryAt)
+	}
+
+	// Délai non écoulé: aucune tentative
+	delete(mock.Errors, "CreateOrder")
+	processSellCycle(client, repo, stored, mock.Price)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres tentés pendant le délai, attendu 1", len(calls))
+	}
+
+	// Solde toujours pas crédité: l'échec est compté sans envoyer d'ordre
+	processSellCycle(client, repo, retryNow(), mock.Price)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres tentés sans solde, attendu 1", len(calls))
+	}
+	if stored = reload(); stored.SellRetryCount != 2 || stored.SellRetryError == "" {
+		t.Fatalf("échecs %d (%q), attendu 2", stored.SellRetryCount, stored.SellRetryError)
+	}
+
+	// Solde crédité, marché monté entre-temps: la vente est placée au prix recalculé
+	mock.SetBalance("BTC", 0.0015)
+	mock.Price = 61500
+	processSellCycle(client, repo, retryNow(), mock.Price)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 2 {
+		t.Fatalf("%d ordres tentés, attendu 2", len(calls))
+	}
+	if price, clientOrderID := calls[1].Args[1], calls[1].Args[4]; price == "61200.00" || clientOrderID != stored.SellClientOrderId {
+		t.Errorf("vente replacée à %v (%v), attendu un prix au-dessus du marché", price, clientOrderID)
+	}
+	stored = reload()
+	if stored.Status != "sell" || stored.SellId == "" || stored.SellRetryCount != 0 || !stored.SellRetryAt.IsZero() ||
+		stored.SellPrice <= 61500 {
+		t.Errorf("cycle après la vente: statut %q, vente %q à %.2f, %d échec(s)",
+			stored.Status, stored.SellId, stored.SellPrice, stored.SellRetryCount)
+	}
+
+	if sellRetryDelay(1) != time.Minute || sellRetryDelay(3) != 4*time.Minute || sellRetryDelay(20) != time.Hour {
+		t.Errorf("délais: %v %v %v", sellRetryDelay(1), sellRetryDelay(3), sellRetryDelay(20))
+	}
+}
+
+// Une vente en attente est retentée comme la première tentative: en OCO si USE_OCO est activé
+func TestSellRetryUsesOCO(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, UseOCO: true, OCOStopLossPercent: 5, OCOStopLimitPercent: 0.5}, 60100)
+	mock.SupportsOCO = true
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkBuyFilled(cycle.IdInt, database.SellRetry{
+		ClientOrderId: common.ClientOrderID(cycle.IdInt, "sell"),
+		Count:         1,
+		Error:         "insufficient balance",
+		At:            time.Now().Add(-time.Second),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	mock.SetBalance("BTC", 0.0015)
+
+	// OCO refusée: l'échec est compté, sans repli sur une vente limite
+	mock.Errors["CreateOCOOrder"] = errors.New("HTTP status 503 - service unavailable")
+	stored, _ := repo.FindByIdInt(cycle.IdInt)
+	processSellCycle(client, repo, stored, mock.Price)
+	if stored, _ = repo.FindByIdInt(cycle.IdInt); !sellPending(stored) || stored.SellRetryCount != 2 ||
+		len(mock.CallsTo("CreateOrder")) != 0 {
+		t.Fatalf("après le refus de l'OCO: vente %q, %d échec(s), %d vente(s) limite", stored.SellId,
+			stored.SellRetryCount, len(mock.CallsTo("CreateOrder")))
+	}
+
+	delete(mock.Errors, "CreateOCOOrder")
+	if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+		"sellRetryAt": time.Now().Add(-time.Second).Format(time.RFC3339),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ = repo.FindByIdInt(cycle.IdInt)
+	processSellCycle(client, repo, stored, mock.Price)
+
+	if calls := mock.CallsTo("CreateOCOOrder"); len(calls) != 2 || len(mock.CallsTo("CreateOrder")) != 0 {
+		t.Fatalf("%d OCO et %d ventes limite, attendu une OCO seule", len(calls), len(mock.CallsTo("CreateOrder")))
+	}
+	stored, _ = repo.FindByIdInt(cycle.IdInt)
+	if stored.SellId == "" || stored.StopId == "" || stored.StopPrice != 56715 || stored.SellRetryCount != 0 {
+		t.Errorf("cycle après la reprise: vente %q, stop %q à %.2f, %d échec(s)",
+			stored.SellId, stored.StopId, stored.StopPrice, stored.SellRetryCount)
+	}
+}
+
+// Le BTC détenu hors du bot (<EXCHANGE>_RESERVE_BTC) n'est jamais compté comme disponible pour une vente
+func TestSellRetryKeepsReserve(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, ReserveBTC: 0.001}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	mock.Errors["CreateOrder"] = errors.New(`HTTP status 400 - {"code":30005,"msg":"Oversold"}`)
+	processBuyCycle(client, repo, cycle, 60100)
+	delete(mock.Errors, "CreateOrder")
+
+	retryNow := func() *database.Cycle {
+		t.Helper()
+		if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+			"sellRetryAt": time.Now().Add(-time.Second).Format(time.RFC3339),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		stored, err := repo.FindByIdInt(cycle.IdInt)
+		if err != nil {
+			t.Fatalf("lecture du cycle: %v", err)
+		}
+		return stored
+	}
+
+	// 0.0015 BTC libres, dont 0.001 réservés: la vente n'est pas placée
+	mock.SetBalance("BTC", 0.0015)
+	processSellCycle(client, repo, retryNow(), mock.Price)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres tentés en entamant la réserve, attendu 1", len(calls))
+	}
+
+	// La réserve couverte, seule la quantité du cycle est vendue
+	mock.SetBalance("BTC", 0.0025)
+	processSellCycle(client, repo, retryNow(), mock.Price)
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 2 || calls[1].Args[2] != "0.00150000" {
+		t.Fatalf("ventes tentées: %+v, attendu une vente de 0.00150000 BTC", calls)
+	}
+}
+
+// La vente retentée est calculée sur le prix contrôlé de l'exécution: un second relevé aberrant
+// de l'exchange n'est pas utilisé
+func TestSellRetryIgnoresOutlierRefetch(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	client := GetClientByExchange("BINANCE")
+
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	mock.Errors["CreateOrder"] = errors.New(`HTTP status 400 - {"code":30005,"msg":"Oversold"}`)
+	processBuyCycle(client, repo, cycle, 60100)
+	delete(mock.Errors, "CreateOrder")
+
+	if err := repo.UpdateByIdInt(cycle.IdInt, map[string]interface{}{
+		"sellRetryAt": time.Now().Add(-time.Second).Format(time.RFC3339),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+
+	// L'exchange répond désormais un prix aberrant
+	mock.SetBalance("BTC", 0.0015)
+	mock.Price = 6010000
+	processSellCycle(client, repo, stored, 60100)
+
+	calls := mock.CallsTo("CreateOrder")
+	if len(calls) != 2 || calls[1].Args[1] != "61200.00" {
+		t.Fatalf("ventes tentées: %+v, attendu une vente à 61200.00", calls)
+	}
+}
+
+func TestNewCycleResumedAfterCrash(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{BuyOffset: -700, SellOffset: 700}, 60000)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+
+	// Achat placé par un --new interrompu avant l'enregistrement du cycle
+	cycleId := repo.NextId()
+	created, err := mock.CreateOrder("BUY", "59300.00", "0.00080000", common.OrderOptions{ClientOrderID: common.ClientOrderID(cycleId, "buy")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderId := created.OrderID
+
+	if err := NewWithExchange("BINANCE"); err != nil {
+		t.Fatalf("NewWithExchange: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(cycleId) })
+
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres créés, attendu uniquement l'achat d'avant l'interruption", len(calls))
+	}
+	stored, err := repo.FindByIdInt(cycleId)
+	if err != nil {
+		t.Fatalf("lecture du cycle %d: %v", cycleId, err)
+	}
+	if stored.BuyId != orderId || stored.BuyPrice != 59300 || stored.Quantity != 0.0008 || stored.SellPrice != 60700 {
+		t.Errorf("cycle repris: achat %q à %.2f pour %.8f, vente à %.2f", stored.BuyId, stored.BuyPrice, stored.Quantity, stored.SellPrice)
+	}
+}
+
+func TestNewCyclePair(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{BuyOffset: -700, SellOffset: 700}, 60000)
+	mock.SetBalance("USDC", 1000)
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+
+	// Binance ne négocie que BTC/USDC: une tâche sur BTC/USDT ne crée aucun ordre
+	os.Args = []string{"bot", "-n", "--pair=BTC/USDT"}
+	if err := NewWithExchange("BINANCE"); err == nil {
+		t.Fatal("cycle créé sur une paire non négociée")
+	}
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 0 {
+		t.Fatalf("ordre créé sur une paire non négociée: %+v", calls)
+	}
+
+	os.Args = []string{"bot", "-n", "--pair=btc-usdc"}
+	if err := checkCyclePair("BINANCE"); err != nil {
+		t.Errorf("paire de l'exchange refusée: %v", err)
+	}
+}
+
+func TestDailyLossLimit(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200, DailyMaxLossUSDC: 20}, 60100)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+
+	// Cycles du jour: une perte de 30 USDC sur BINANCE, un gain de 5 USDC sur KRAKEN; la veille ne compte pas
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	cycles := []*database.Cycle{
+		{Exchange: "BINANCE", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 57000, CompletedAt: now.Add(-2 * time.Hour)},
+		{Exchange: "KRAKEN", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 60600, TotalFees: 1, CompletedAt: now.Add(-time.Hour)},
+		{Exchange: "BINANCE", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 50000, CompletedAt: now.Add(-16 * time.Hour)},
+	}
+	state := computeLossLimits(&config.Config{
+		Exchanges:        map[string]config.ExchangeConfig{"BINANCE": {DailyMaxLossUSDC: 20}, "KRAKEN": {}},
+		DailyMaxLossUSDC: 50,
+	}, cycles, now)
+	if state.Day != "2024-03-10" || state.Losses["BINANCE"] != 30 || state.Losses["KRAKEN"] != 0 || state.TotalLoss != 25 {
+		t.Fatalf("pertes inattendues: %+v, total %.2f", state.Losses, state.TotalLoss)
+	}
+	if !state.Blocks("BINANCE") || state.Blocks("KRAKEN") || state.Breached[lossLimitGlobal] {
+		t.Fatalf("périmètres atteints inattendus: %v", state.Breached)
+	}
+
+	// Limite atteinte: l'achat exécuté n'est pas suivi d'une vente
+	lossLimitsMu.Lock()
+	lossLimits = state
+	lossLimitsMu.Unlock()
+	t.Cleanup(func() {
+		lossLimitsMu.Lock()
+		lossLimits = nil
+		lossLimitsMu.Unlock()
+	})
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	processBuyCycle(mock, repo, cycle, 60100)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 0 {
+		t.Fatalf("vente placée malgré la limite de pertes: %+v", calls)
+	}
+
+	// Limite levée (--override-loss-limit): la vente est placée
+	state.Overridden = true
+	processBuyCycle(mock, repo, cycle, 60100)
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 1 {
+		t.Fatalf("%d ordres créés après la levée de la limite, attendu 1", len(calls))
+	}
+}
+
+func TestAccumulationCaps(t *testing.T) {
+	repo := database.GetRepository()
+	accuRepo := database.GetAccumulationRepository()
+
+	// 100 USDC de profit réalisé sur KUCOIN
+	completed := &database.Cycle{Exchange: "KUCOIN", Status: "completed", Quantity: 0.01, BuyPrice: 60000, SellPrice: 70100, TotalFees: 1}
+	if _, err := repo.Save(completed); err != nil {
+		t.Fatalf("enregistrement du cycle: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteByIdInt(completed.IdInt) })
+
+	// Vente de 0.001 BTC à 61200 (61.20 USDC) alors que le prix est tombé à 50000
+	cycle := &database.Cycle{Exchange: "KUCOIN", Status: "sell", Quantity: 0.001, BuyPrice: 60000, SellPrice: 61200}
+
+	tests := []struct {
+		name    string
+		percent float64
+		single  float64
+		want    bool
+	}{
+		{"tout le profit", 100, 0, true},
+		{"part du profit insuffisante", 50, 0, false},
+		{"plafond par accumulation dépassé", 100, 50, false},
+		{"dans les plafonds", 80, 70, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchangeConfig := config.ExchangeConfig{
+				Accumulation:                   true,
+				SellAccuPriceDeviation:         10,
+				MaxAccumulationPercentOfProfit: tt.percent,
+				MaxSingleAccumulationUSDC:      tt.single,
+			}
+			got, _, err := checkAccumulationConditions(cycle, 50000, exchangeConfig, accuRepo)
+			if err != nil {
+				t.Fatalf("checkAccumulationConditions: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("checkAccumulationConditions() = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionTimeFallbacks(t *testing.T) {
+	createdAt := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	observedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	filledAt := createdAt.Add(30 * time.Hour)
+
+	// Date d'exécution fournie par l'exchange (OrderStatus.UpdatedAt), zéro si absente
+	tests := []struct {
+		name         string
+		exchangeTime time.Time
+		want         time.Time
+	}{
+		{"date cohérente", filledAt, filledAt},
+		{"date antérieure à l'achat", createdAt.Add(-time.Hour), observedAt},
+		{"date dans le futur", time.Now().Add(time.Hour), observedAt},
+		{"sans date", time.Time{}, observedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle := &database.Cycle{
+				Status:              "completed",
+				CreatedAt:           createdAt,
+				CompletedAt:         tt.exchangeTime,
+				ObservedCompletedAt: observedAt,
+			}
+			if got := cycle.EffectiveCompletedAt(); !got.Equal(tt.want) {
+				t.Errorf("EffectiveCompletedAt() = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulatedUpdate(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+
+	intents := runSimulatedUpdate()
+
+	// Aucun ordre réel, cycle inchangé en base
+	if calls := mock.CallsTo("CreateOrder"); len(calls) != 0 {
+		t.Fatalf("ordre créé pendant la simulation: %+v", calls)
+	}
+	stored, err := repo.FindByIdInt(cycle.IdInt)
+	if err != nil {
+		t.Fatalf("lecture du cycle: %v", err)
+	}
+	if stored.Status != "buy" || stored.SellId != "" {
+		t.Fatalf("cycle modifié par la simulation: statut %q, vente %q", stored.Status, stored.SellId)
+	}
+
+	// La vente et le passage en statut sell sont enregistrés comme actions du cycle
+	actions := make(map[string]simulatedIntent)
+	for _, intent := range intents {
+		if intent.CycleId == cycle.IdInt {
+			actions[intent.Action] = intent
+		}
+	}
+	if sell, ok := actions["create_order"]; !ok || sell.Exchange != "BINANCE" || sell.Details != "SELL 0.00150000 BTC à 61200.00" {
+		t.Errorf("vente simulée absente ou inattendue: %+v", intents)
+	}
+	if _, ok := actions["mark_sell"]; !ok {
+		t.Errorf("passage en vente absent des actions: %+v", intents)
+	}
+	if simulating() {
+		t.Error("la simulation devrait être terminée")
+	}
+}
+
+func TestTradingHaltLeavesCyclesUntouched(t *testing.T) {
+	mock := useMockExchange(t, config.ExchangeConfig{SellOffset: 1200}, 60100)
+	mock.SetBalance("USDC", 1000)
+	repo := database.GetRepository()
+	cycle := saveBuyCycle(t, mock, 60000, 0.0015)
+	if err := mock.FillOrder(cycle.BuyId); err != nil {
+		t.Fatal(err)
+	}
+	// Les fichiers d'état publiés par la mise à jour sont écrits à côté de la base
+	t.Setenv(config.DirEnv, t.TempDir())
+
+	// Paire suspendue: ni vente ni annulation, la suspension est publiée pour /health
+	mock.PairState = "HALT"
+	Update()
+	if calls := append(mock.CallsTo("CreateOrder"), mock.CallsTo("CancelOrderIdempotent")...); len(calls) != 0 {
+		t.Fatalf("ordres envoyés pendant la suspension: %+v", calls)
+	}
+	if stored, _ := repo.FindByIdInt(cycle.IdInt); stored.Status != "buy" || stored.SellId != "" {
+		t.Fatalf("cycle modifié pendant la suspension: statut %q, vente %q", stored.Status, stored.SellId)
+	}
+	halts, err := loadTradingHalts()
+	if err != nil || halts["BINANCE"].Status != "HALT" {
+		t.Fatalf("suspension non enregistrée: %+v (erreur %v)", halts, err)
+	}
+	if err := NewWithExchange("BINANCE"); !errors.Is(err, ErrCycleSkipped) {
+		t.Errorf("nouveau cycle pendant la suspension: erreur %v, attendu ErrCycleSkipped", err)
+	}
+
+	// Retour de la négociation: la vente est placée et la suspension levée
+	mock.PairState = ""
+	Update()
+	if stored, _ := repo.FindByIdInt(cycle.IdInt); stored.Status != "sell" || stored.SellId == "" {
+		t.Errorf("cycle après la reprise: statut %q, vente %q", stored.Status, stored.SellId)
+	}
+	if halts, _ := loadTradingHalts(); len(halts) != 0 {
+		t.Errorf("suspension non levée: %+v", halts)
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"main/internal/config"
+
+	"github.com/fatih/color"
+)
+
+// ValidateConfig vérifie bot.conf sans rien exécuter (--validate-config): erreurs et
+// avertissements sont listés ensemble avec leur ligne, le code de sortie vaut 1 en cas d'erreur
+func ValidateConfig() {
+	problems, err := config.CheckConfig()
+
+	var validationErr *config.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		color.Red("Impossible de lire la configuration: %v", err)
+		os.Exit(1)
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Warning {
+			color.Yellow("Avertissement: %s", problem)
+		} else {
+			color.Red("Erreur: %s", problem)
+			errorCount++
+		}
+	}
+
+	if errorCount > 0 {
+		color.Red("%d erreur(s) à corriger dans %s", errorCount, config.ConfigFilename)
+		os.Exit(1)
+	}
+	color.Green("Configuration valide (%d avertissement(s))", len(problems))
+}
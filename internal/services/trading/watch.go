@@ -0,0 +1,450 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"main/internal/database"
+	"main/pkg/logger"
+
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// defaultWatchInterval est la fréquence de rafraîchissement de --watch sans --interval
+const defaultWatchInterval = 30 * time.Second
+
+// minWatchInterval borne --interval: chaque rafraîchissement interroge tous les exchanges
+const minWatchInterval = 5 * time.Second
+
+// watchLogInterval est la fréquence de relecture du journal affiché par --watch
+const watchLogInterval = time.Second
+
+// watchLogLines est le nombre de lignes du journal affichées par --watch
+const watchLogLines = 200
+
+// watchCycle est une ligne du tableau des cycles de --watch
+type watchCycle struct {
+	Cycle         *database.Cycle
+	Price         float64 // Prix BTC actuel de l'exchange (0 si inconnu)
+	Unrealized    float64
+	HasUnrealized bool
+}
+
+// watchCycles retourne les cycles en cours triés par exchange puis par ID, avec leur P&L latent
+// au prix relevé sur leur exchange
+func watchCycles(cycles []*database.Cycle, prices map[string]float64) []watchCycle {
+	rows := make([]watchCycle, 0, len(cycles))
+	for _, cycle := range openCycles(cycles) {
+		row := watchCycle{Cycle: cycle, Price: prices[cycle.Exchange]}
+		row.Unrealized, row.HasUnrealized = unrealizedProfit(cycle, row.Price)
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Cycle.Exchange != rows[j].Cycle.Exchange {
+			return rows[i].Cycle.Exchange < rows[j].Cycle.Exchange
+		}
+		return rows[i].Cycle.IdInt < rows[j].Cycle.IdInt
+	})
+	return rows
+}
+
+// watchMonitor est l'écran de --watch: prix et soldes par exchange, cycles en cours, journal
+// récent et barre d'état
+type watchMonitor struct {
+	app       *tview.Application
+	header    *tview.TextView
+	exchanges *tview.Table
+	cycles    *tview.Table
+	logs      *tview.TextView
+	footer    *tview.TextView
+
+	interval   time.Duration
+	rows       []watchCycle
+	lastLogSeq uint64
+	message    string
+	refreshing atomic.Bool
+	updating   atomic.Bool
+}
+
+// Watch affiche un tableau de bord rafraîchi en continu dans le terminal (--watch [--interval=S]).
+// Les données sont lues comme par --status, sans mise à jour des cycles: u lance une mise à jour,
+// p met en pause ou reprend le cycle sélectionné, r rafraîchit, q quitte.
+func Watch() {
+	interval := defaultWatchInterval
+	for _, arg := range GetAllArgs() {
+		if value, ok := strings.CutPrefix(arg, "--interval="); ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				color.Red("Intervalle invalide: %s (nombre de secondes attendu)", value)
+				os.Exit(1)
+			}
+			interval = max(time.Duration(seconds)*time.Second, minWatchInterval)
+		}
+	}
+
+	// L'écran est dessiné directement sur le terminal: les sorties des mises à jour et des clients
+	// sont détournées vers le journal affiché
+	restore, err := redirectWatchOutput()
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+	defer restore()
+	followDaemonLogs()
+
+	monitor := newWatchMonitor(interval)
+	if err := monitor.run(); err != nil {
+		restore()
+		color.Red("Erreur de l'affichage: %v", err)
+		os.Exit(1)
+	}
+}
+
+// redirectWatchOutput détourne la sortie standard et la sortie en couleur (déjà conservées dans
+// le journal par les événements de trading) et envoie le paquet log dans le journal affiché
+func redirectWatchOutput() (func(), error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ouverture de %s: %v", os.DevNull, err)
+	}
+
+	stdout, colorOutput, logOutput, noColor := os.Stdout, color.Output, log.Writer(), color.NoColor
+	os.Stdout, color.Output, color.NoColor = devNull, io.Discard, true
+	log.SetOutput(recentWriter{})
+
+	restored := false
+	return func() {
+		if restored {
+			return
+		}
+		restored = true
+		os.Stdout, color.Output, color.NoColor = stdout, colorOutput, noColor
+		log.SetOutput(logOutput)
+		devNull.Close()
+	}, nil
+}
+
+// recentWriter conserve chaque ligne écrite dans le journal en mémoire (logger.Recent)
+type recentWriter struct{}
+
+func (recentWriter) Write(p []byte) (int, error) {
+	var previous logger.Entry
+	scanner := bufio.NewScanner(strings.NewReader(string(p)))
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			previous = logger.ParseLine(line, previous)
+			logger.Recent.Add(previous)
+		}
+	}
+	return len(p), nil
+}
+
+func newWatchMonitor(interval time.Duration) *watchMonitor {
+	m := &watchMonitor{
+		app:        tview.NewApplication(),
+		header:     tview.NewTextView().SetDynamicColors(true),
+		exchanges:  tview.NewTable(),
+		cycles:     tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		logs:       tview.NewTextView().SetDynamicColors(true).SetMaxLines(watchLogLines),
+		footer:     tview.NewTextView().SetDynamicColors(true),
+		interval:   interval,
+		lastLogSeq: logger.Recent.LastSeq(),
+	}
+	m.exchanges.SetBorder(true).SetTitle(" Exchanges ")
+	m.cycles.SetBorder(true).SetTitle(" Cycles en cours ")
+	m.logs.SetBorder(true).SetTitle(" Journal ")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(m.header, 1, 0, false).
+		AddItem(m.exchanges, 7, 0, false).
+		AddItem(m.cycles, 0, 3, true).
+		AddItem(m.logs, 0, 2, false).
+		AddItem(m.footer, 1, 0, false)
+	m.app.SetRoot(layout, true).SetFocus(m.cycles)
+	m.app.SetInputCapture(m.handleKey)
+	return m
+}
+
+// run affiche l'écran jusqu'à q ou Ctrl+C
+func (m *watchMonitor) run() error {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	m.header.SetText("[::b]Bot spot[::-] - chargement...")
+	m.renderFooter()
+	go m.refresh()
+	go m.loop(stop)
+	return m.app.Run()
+}
+
+// loop rafraîchit les données à l'intervalle choisi et le journal chaque seconde
+func (m *watchMonitor) loop(stop <-chan struct{}) {
+	dataTicker := time.NewTicker(m.interval)
+	logTicker := time.NewTicker(watchLogInterval)
+	defer dataTicker.Stop()
+	defer logTicker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-dataTicker.C:
+			m.refresh()
+		case <-logTicker.C:
+			if logger.Recent.LastSeq() != m.lastLogSeq {
+				m.app.QueueUpdateDraw(m.renderLogs)
+			}
+		}
+	}
+}
+
+// handleKey traite les raccourcis: u (mise à jour), p (pause/reprise), r (rafraîchir), q (quitter)
+func (m *watchMonitor) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'q', 'Q':
+		m.app.Stop()
+		return nil
+	case 'r', 'R':
+		go m.refresh()
+		return nil
+	case 'u', 'U':
+		m.startUpdate()
+		return nil
+	case 'p', 'P':
+		m.togglePause()
+		return nil
+	}
+	return event
+}
+
+// refresh relit l'état du bot comme --status, puis redessine l'écran. Un rafraîchissement déjà
+// en cours n'est pas doublé.
+func (m *watchMonitor) refresh() {
+	if !m.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.refreshing.Store(false)
+
+	now := time.Now()
+	report, err := buildStatusReport(now)
+	if err != nil {
+		m.app.QueueUpdateDraw(func() { m.setMessage("[red]Erreur lors de la lecture des cycles: %v", err) })
+		return
+	}
+	cycles, err := database.GetRepository().FindAll()
+	if err != nil {
+		m.app.QueueUpdateDraw(func() { m.setMessage("[red]Erreur lors de la lecture des cycles: %v", err) })
+		return
+	}
+
+	prices := make(map[string]float64, len(report.Exchanges))
+	for _, status := range report.Exchanges {
+		if status.Connected {
+			prices[status.Exchange] = status.BTCPrice
+		}
+	}
+	rows := watchCycles(cycles, prices)
+
+	m.app.QueueUpdateDraw(func() {
+		m.rows = rows
+		m.renderHeader(report)
+		m.renderExchanges(report)
+		m.renderCycles()
+		m.renderLogs()
+	})
+}
+
+// startUpdate lance une mise à jour des cycles en arrière-plan; sa sortie arrive dans le journal
+func (m *watchMonitor) startUpdate() {
+	if !m.updating.CompareAndSwap(false, true) {
+		m.setMessage("[yellow]Mise à jour déjà en cours")
+		return
+	}
+	m.setMessage("[yellow]Mise à jour en cours...")
+
+	go func() {
+		started := time.Now()
+		Update()
+		m.updating.Store(false)
+		m.app.QueueUpdateDraw(func() {
+			m.setMessage("[green]Mise à jour terminée en %s", time.Since(started).Round(time.Second))
+		})
+		m.refresh()
+	}()
+}
+
+// togglePause met en pause ou reprend le cycle sélectionné
+func (m *watchMonitor) togglePause() {
+	row, _ := m.cycles.GetSelection()
+	if row < 1 || row > len(m.rows) {
+		m.setMessage("[yellow]Aucun cycle sélectionné")
+		return
+	}
+
+	cycle := m.rows[row-1].Cycle
+	updated, err := setCyclePaused(cycle.IdInt, !cycle.Paused)
+	if err != nil {
+		m.setMessage("[red]%v", err)
+		return
+	}
+	if updated.Paused {
+		m.setMessage("[yellow]Cycle %d mis en pause", updated.IdInt)
+	} else {
+		m.setMessage("[green]Cycle %d repris", updated.IdInt)
+	}
+	cycle.Paused = updated.Paused
+	m.renderCycles()
+}
+
+// setMessage affiche un message dans la barre d'état. À appeler depuis la boucle de l'écran.
+func (m *watchMonitor) setMessage(format string, args ...interface{}) {
+	m.message = fmt.Sprintf(format, args...)
+	m.renderFooter()
+}
+
+func (m *watchMonitor) renderFooter() {
+	keys := "[::b]u[::-] mise à jour  [::b]p[::-] pause/reprise  [::b]r[::-] rafraîchir  [::b]q[::-] quitter"
+	if m.message == "" {
+		m.footer.SetText(keys)
+		return
+	}
+	m.footer.SetText(keys + "  |  " + m.message + "[-]")
+}
+
+func (m *watchMonitor) renderHeader(report StatusReport) {
+	scheduler := "[yellow]arrêté[-]"
+	if report.Scheduler.Running {
+		scheduler = fmt.Sprintf("[green]actif[-] (%d tâche(s))", len(report.Scheduler.Tasks))
+	}
+	profitColor := "green"
+	if report.TodayProfit < 0 {
+		profitColor = "red"
+	}
+	issues := "[green]aucun problème[-]"
+	if len(report.Issues) > 0 {
+		issues = fmt.Sprintf("[yellow]%d problème(s) (--status)[-]", len(report.Issues))
+	}
+	m.header.SetText(fmt.Sprintf("[::b]Bot spot[::-] %s | profit du jour (UTC) [%s]%.2f USDC[-] | planificateur %s | %s | rafraîchi toutes les %s",
+		report.GeneratedAt.Format("15:04:05"), profitColor, report.TodayProfit, scheduler, issues, m.interval))
+}
+
+func (m *watchMonitor) renderExchanges(report StatusReport) {
+	m.exchanges.Clear()
+	for column, title := range []string{"EXCHANGE", "PRIX BTC", "BTC", "USDC", "ACHAT", "VENTE", "PROFIT DU JOUR", "ÉTAT"} {
+		m.exchanges.SetCell(0, column, watchHeaderCell(title))
+	}
+
+	for i, status := range report.Exchanges {
+		row := i + 1
+		m.exchanges.SetCell(row, 0, tview.NewTableCell(status.Exchange))
+		switch {
+		case status.Maintenance:
+			m.exchanges.SetCell(row, 7, tview.NewTableCell("maintenance").SetTextColor(tcell.ColorYellow))
+			continue
+		case !status.Connected:
+			m.exchanges.SetCell(row, 7, tview.NewTableCell(tview.Escape(status.Error)).SetTextColor(tcell.ColorRed))
+			continue
+		}
+
+		m.exchanges.SetCell(row, 1, watchNumberCell(fmt.Sprintf("%.2f", status.BTCPrice)))
+		m.exchanges.SetCell(row, 2, watchNumberCell(fmt.Sprintf("%.8f", status.BTCTotal)))
+		m.exchanges.SetCell(row, 3, watchNumberCell(fmt.Sprintf("%.2f", status.USDCTotal)))
+		m.exchanges.SetCell(row, 4, watchNumberCell(strconv.Itoa(status.Cycles["buy"])))
+		m.exchanges.SetCell(row, 5, watchNumberCell(strconv.Itoa(status.Cycles["sell"])))
+		m.exchanges.SetCell(row, 6, watchProfitCell(status.TodayProfit))
+		state := tview.NewTableCell("connecté").SetTextColor(tcell.ColorGreen)
+		if status.CircuitBreakerOpen {
+			state = tview.NewTableCell("disjoncteur ouvert").SetTextColor(tcell.ColorYellow)
+		}
+		m.exchanges.SetCell(row, 7, state)
+	}
+}
+
+func (m *watchMonitor) renderCycles() {
+	selected, _ := m.cycles.GetSelection()
+	m.cycles.Clear()
+	for column, title := range []string{"ID", "EXCHANGE", "STATUT", "QUANTITÉ", "ACHAT", "VENTE", "PRIX BTC", "P&L LATENT", "DURÉE"} {
+		m.cycles.SetCell(0, column, watchHeaderCell(title).SetSelectable(false))
+	}
+
+	for i, row := range m.rows {
+		cycle := row.Cycle
+		status := formatStatus(cycle)
+		if cycle.Paused {
+			status += " (pause)"
+		}
+		price := "-"
+		if row.Price > 0 {
+			price = fmt.Sprintf("%.2f", row.Price)
+		}
+		unrealized := tview.NewTableCell("-").SetAlign(tview.AlignRight)
+		if row.HasUnrealized {
+			unrealized = watchProfitCell(row.Unrealized)
+		}
+
+		line := i + 1
+		m.cycles.SetCell(line, 0, watchNumberCell(strconv.Itoa(int(cycle.IdInt))))
+		m.cycles.SetCell(line, 1, tview.NewTableCell(cycle.Exchange))
+		m.cycles.SetCell(line, 2, tview.NewTableCell(status))
+		m.cycles.SetCell(line, 3, watchNumberCell(fmt.Sprintf("%.8f", cycle.Quantity)))
+		m.cycles.SetCell(line, 4, watchNumberCell(fmt.Sprintf("%.2f", cycle.EffectiveBuyPrice())))
+		m.cycles.SetCell(line, 5, watchNumberCell(fmt.Sprintf("%.2f", cycle.SellPrice)))
+		m.cycles.SetCell(line, 6, watchNumberCell(price))
+		m.cycles.SetCell(line, 7, unrealized)
+		m.cycles.SetCell(line, 8, watchNumberCell(formatDetailedDuration(cycle.GetAge())))
+	}
+
+	// Conserver la sélection d'un rafraîchissement à l'autre
+	if len(m.rows) > 0 {
+		m.cycles.Select(min(max(selected, 1), len(m.rows)), 0)
+	}
+}
+
+func (m *watchMonitor) renderLogs() {
+	m.lastLogSeq = logger.Recent.LastSeq()
+	entries := logger.Recent.Entries(logger.LevelInfo, 0)
+	if len(entries) > watchLogLines {
+		entries = entries[len(entries)-watchLogLines:]
+	}
+
+	var text strings.Builder
+	for _, entry := range entries {
+		tag := ""
+		switch entry.Level {
+		case logger.LevelWarn.String():
+			tag = "[yellow]"
+		case logger.LevelError.String():
+			tag = "[red]"
+		}
+		fmt.Fprintf(&text, "%s%s %s[-]\n", tag, entry.Time.Format("15:04:05"), tview.Escape(entry.Message))
+	}
+	m.logs.SetText(strings.TrimSuffix(text.String(), "\n"))
+	m.logs.ScrollToEnd()
+}
+
+func watchHeaderCell(title string) *tview.TableCell {
+	return tview.NewTableCell(title).SetTextColor(tcell.ColorAqua).SetAttributes(tcell.AttrBold)
+}
+
+func watchNumberCell(text string) *tview.TableCell {
+	return tview.NewTableCell(text).SetAlign(tview.AlignRight)
+}
+
+// watchProfitCell affiche un profit en vert, une perte en rouge
+func watchProfitCell(profit float64) *tview.TableCell {
+	cell := watchNumberCell(fmt.Sprintf("%.2f", profit)).SetTextColor(tcell.ColorGreen)
+	if profit < 0 {
+		cell.SetTextColor(tcell.ColorRed)
+	}
+	return cell
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"math"
+	"testing"
+
+	"main/internal/config"
+	"main/internal/database"
+)
+
+func TestWatchCycles(t *testing.T) {
+	useMockExchange(t, config.ExchangeConfig{}, 61000)
+
+	cycles := []*database.Cycle{
+		{IdInt: 3, Exchange: "KUCOIN", Status: "sell", Quantity: 0.001, BuyPrice: 60000},
+		{IdInt: 2, Exchange: "BINANCE", Status: "sell", Quantity: 0.001, BuyPrice: 60000, BuyFees: 0.06, TotalFees: 0.06},
+		{IdInt: 1, Exchange: "BINANCE", Status: "buy", Quantity: 0.001, BuyPrice: 59000},
+		{IdInt: 4, Exchange: "BINANCE", Status: "completed", Quantity: 0.001, BuyPrice: 58000},
+	}
+	rows := watchCycles(cycles, map[string]float64{"BINANCE": 61000})
+
+	var ids []int32
+	for _, row := range rows {
+		ids = append(ids, row.Cycle.IdInt)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Fatalf("cycles affichés %v, attendu [1 2 3] (en cours, par exchange puis ID)", ids)
+	}
+
+	// P&L latent des seuls cycles en vente dont le prix est connu
+	if rows[0].HasUnrealized || rows[2].HasUnrealized {
+		t.Errorf("P&L latent inattendu: achat %v, prix inconnu %v", rows[0].HasUnrealized, rows[2].HasUnrealized)
+	}
+	want := 1 - 0.06 - 61*getFeeRateForExchange("BINANCE")
+	if !rows[1].HasUnrealized || math.Abs(rows[1].Unrealized-want) > 1e-9 {
+		t.Errorf("P&L latent %.4f (%v), attendu %.4f", rows[1].Unrealized, rows[1].HasUnrealized, want)
+	}
+}
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"main/internal/config"
+	"main/internal/database"
+
+	"github.com/fatih/color"
+)
+
+// webhookStateFile conserve, à côté de la base de données, les échecs de livraison de chaque URL
+const webhookStateFile = "webhooks.json"
+
+// webhookAttempts est le nombre d'envois tentés pour une même notification
+const webhookAttempts = 3
+
+// webhookURLState est l'état de livraison d'une URL dans webhooks.json
+type webhookURLState struct {
+	Failures  int       `json:"failures"` // Livraisons échouées consécutives
+	Disabled  bool      `json:"disabled"`
+	LastError string    `json:"lastError,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// webhookNotifier envoie les notifications en POST JSON signé à une liste d'URLs
+type webhookNotifier struct {
+	urls        []string
+	events      map[string]bool // Événements transmis (vide = tous)
+	secret      string
+	maxFailures int
+	retryDelay  time.Duration
+	statePath   string
+	client      *http.Client
+
+	mu sync.Mutex
+}
+
+// newWebhookNotifier crée le canal webhook depuis WEBHOOK_URLS et WEBHOOK_EVENTS
+func newWebhookNotifier(c *config.Config) *webhookNotifier {
+	events := make(map[string]bool, len(c.WebhookEvents))
+	for _, event := range c.WebhookEvents {
+		events[strings.ToLower(event)] = true
+	}
+
+	return &webhookNotifier{
+		urls:        c.WebhookURLs,
+		events:      events,
+		secret:      c.WebhookSecret,
+		maxFailures: c.WebhookMaxFailures,
+		retryDelay:  2 * time.Second,
+		statePath:   filepath.Join(filepath.Dir(database.GetDatabasePath()), webhookStateFile),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifie le canal dans les logs
+func (w *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+// accepts indique si l'événement passe le filtre WEBHOOK_EVENTS (vide = tous, sauf error)
+func (w *webhookNotifier) accepts(event string) bool {
+	if len(w.events) == 0 {
+		return event != config.ErrorEvent
+	}
+	return w.events[event]
+}
+
+// Notify envoie la notification à chaque URL active. Chaque URL a droit à
+// webhookAttempts essais ; une URL en échec répété est désactivée.
+func (w *webhookNotifier) Notify(n Notification) error {
+	if !w.accepts(n.Event) {
+		return nil
+	}
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("sérialisation de la notification: %v", err)
+	}
+
+	var failed []string
+	for _, target := range w.urls {
+		if w.state(target).Disabled {
+			continue
+		}
+		err := w.deliver(target, n.Event, body)
+		w.record(target, err)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", redactURL(target), err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// deliver envoie le corps à une URL, avec un délai croissant entre les essais
+func (w *webhookNotifier) deliver(target, event string, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if err = w.post(target, event, body); err == nil {
+			return nil
+		}
+		if attempt < webhookAttempts {
+			time.Sleep(w.retryDelay * time.Duration(attempt))
+		}
+	}
+	return fmt.Errorf("%d essais échoués: %v", webhookAttempts, err)
+}
+
+// post effectue un envoi. Le corps est signé en HMAC-SHA256 si WEBHOOK_SECRET est défini.
+func (w *webhookNotifier) post(target, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Bot-Event", event)
+	if w.secret != "" {
+		req.Header.Set("X-Bot-Signature", "sha256="+signWebhookBody(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// L'erreur de net/http cite l'URL complète, qui peut contenir un jeton
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("statut HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookBody calcule la signature hexadécimale HMAC-SHA256 du corps
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// redactURL ne garde que le schéma et l'hôte: le chemin des webhooks (n8n...) sert souvent de jeton
+func redactURL(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return "URL invalide"
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/…"
+}
+
+// loadStates lit webhooks.json (vide s'il n'existe pas)
+func (w *webhookNotifier) loadStates() map[string]webhookURLState {
+	states := make(map[string]webhookURLState)
+	content, err := os.ReadFile(w.statePath)
+	if err != nil {
+		return states
+	}
+	if err := json.Unmarshal(content, &states); err != nil {
+		log.Printf("Fichier %s invalide, état des webhooks réinitialisé: %v", webhookStateFile, err)
+		return make(map[string]webhookURLState)
+	}
+	return states
+}
+
+// saveStates écrit webhooks.json
+func (w *webhookNotifier) saveStates(states map[string]webhookURLState) {
+	content, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		log.Printf("Erreur lors de la sérialisation de l'état des webhooks: %v", err)
+		return
+	}
+	if err := os.WriteFile(w.statePath, content, 0644); err != nil {
+		log.Printf("Erreur lors de l'écriture de %s: %v", webhookStateFile, err)
+	}
+}
+
+// state retourne l'état de livraison d'une URL
+func (w *webhookNotifier) state(target string) webhookURLState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.loadStates()[target]
+}
+
+// record met à jour le compteur d'échecs d'une URL et la désactive au-delà de WEBHOOK_MAX_FAILURES
+func (w *webhookNotifier) record(target string, deliveryErr error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	states := w.loadStates()
+	state, known := states[target]
+	if deliveryErr == nil {
+		if !known {
+			return
+		}
+		delete(states, target)
+		w.saveStates(states)
+		return
+	}
+
+	state.Failures++
+	state.LastError = deliveryErr.Error()
+	state.UpdatedAt = time.Now()
+	if w.maxFailures > 0 && state.Failures >= w.maxFailures && !state.Disabled {
+		state.Disabled = true
+		exchangeEvent("", "webhook_disabled").with("url", redactURL(target)).
+			warn("Webhook %s désactivé après %d livraisons échouées (--webhook-test pour le réactiver)",
+				redactURL(target), state.Failures)
+	}
+	states[target] = state
+	w.saveStates(states)
+}
+
+// WebhookTest envoie un événement "test" à toutes les URLs configurées, y compris celles
+// désactivées: une URL qui répond est réactivée (--webhook-test)
+func WebhookTest() {
+	if len(cfg.WebhookURLs) == 0 {
+		color.Yellow("Aucun webhook configuré (WEBHOOK_URLS dans %s)", config.ConfigFilename)
+		return
+	}
+
+	w := newWebhookNotifier(cfg)
+	n := Notification{
+		Event:     "test",
+		Message:   "Notification de test",
+		Timestamp: time.Now(),
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		color.Red("Erreur lors de la sérialisation de la notification: %v", err)
+		os.Exit(1)
+	}
+
+	failures := 0
+	for _, target := range w.urls {
+		wasDisabled := w.state(target).Disabled
+		err := w.deliver(target, n.Event, body)
+		w.record(target, err)
+		if err != nil {
+			failures++
+			color.Red("%s: %v", redactURL(target), err)
+			continue
+		}
+		if wasDisabled {
+			color.Green("%s: notification reçue, webhook réactivé", redactURL(target))
+		} else {
+			color.Green("%s: notification reçue", redactURL(target))
+		}
+	}
+
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"main/internal/database"
+)
+
+// newTestWebhookNotifier crée un canal webhook sans délai entre les essais
+func newTestWebhookNotifier(t *testing.T, urls []string, events map[string]bool, maxFailures int) *webhookNotifier {
+	return &webhookNotifier{
+		urls:        urls,
+		events:      events,
+		secret:      "cle-de-test",
+		maxFailures: maxFailures,
+		statePath:   filepath.Join(t.TempDir(), webhookStateFile),
+		client:      &http.Client{Timeout: time.Second},
+	}
+}
+
+func TestWebhookNotifySignedPayload(t *testing.T) {
+	var received Notification
+	var signature, event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Bot-Signature")
+		event = r.Header.Get("X-Bot-Event")
+		if signature != "sha256="+signWebhookBody("cle-de-test", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	w := newTestWebhookNotifier(t, []string{server.URL}, nil, 5)
+	cycle := &database.Cycle{IdInt: 12, Exchange: "BINANCE", Status: "sell", BuyPrice: 60000}
+	err := w.Notify(Notification{Event: "buy_filled", Exchange: "BINANCE", Message: "achat exécuté", Timestamp: time.Now(), Cycle: cycle})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if event != "buy_filled" || received.Event != "buy_filled" {
+		t.Errorf("événement reçu %q / %q, attendu buy_filled", event, received.Event)
+	}
+	if received.Cycle == nil || received.Cycle.IdInt != 12 || received.Cycle.BuyPrice != 60000 {
+		t.Errorf("cycle reçu incorrect: %+v", received.Cycle)
+	}
+}
+
+func TestWebhookEventFilter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	w := newTestWebhookNotifier(t, []string{server.URL}, map[string]bool{"sell_filled": true}, 5)
+	w.Notify(Notification{Event: "buy_filled"})
+	w.Notify(Notification{Event: "sell_filled"})
+
+	if calls != 1 {
+		t.Errorf("%d appels, attendu 1 (seul sell_filled est transmis)", calls)
+	}
+}
+
+func TestWebhookRetryAndDisable(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Le premier essai échoue, le deuxième réussit
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhookNotifier(t, []string{server.URL}, nil, 2)
+	if err := w.Notify(Notification{Event: "new_cycle"}); err != nil {
+		t.Fatalf("la livraison devrait réussir au deuxième essai: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("%d essais, attendu 2", calls)
+	}
+
+	// Une URL injoignable est désactivée après maxFailures livraisons de webhookAttempts essais
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downURL := down.URL + "/webhook/jeton-secret"
+	down.Close()
+
+	w = newTestWebhookNotifier(t, []string{downURL}, nil, 2)
+	for i := 0; i < 2; i++ {
+		err := w.Notify(Notification{Event: "new_cycle"})
+		if err == nil {
+			t.Fatal("une erreur était attendue pour une URL injoignable")
+		}
+		if strings.Contains(err.Error(), "jeton-secret") {
+			t.Errorf("l'erreur ne doit pas citer le chemin de l'URL: %v", err)
+		}
+	}
+	if !w.state(downURL).Disabled {
+		t.Error("l'URL devrait être désactivée après 2 livraisons échouées")
+	}
+	if err := w.Notify(Notification{Event: "new_cycle"}); err != nil {
+		t.Errorf("une URL désactivée ne devrait plus être appelée: %v", err)
+	}
+}
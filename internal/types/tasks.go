@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // TimeUnit représente une unité de temps pour les intervalles
 type TimeUnit string
@@ -11,6 +15,22 @@ const (
 	Days    TimeUnit = "days"
 )
 
+// ExitCodeSkipped est le code de sortie d'une commande qui n'a rien fait
+// volontairement (ex: limite d'exposition atteinte). Le planificateur ne le
+// considère pas comme une erreur.
+const ExitCodeSkipped = 3
+
+// TaskRunEnv est défini dans l'environnement des commandes lancées par le planificateur
+const TaskRunEnv = "BOT_SCHEDULED_TASK"
+
+// CreatedCycleMarker préfixe la ligne par laquelle une commande lancée par le planificateur
+// signale un cycle créé (suivi de son identifiant), relevée dans l'historique des exécutions
+const CreatedCycleMarker = "@cycle-created "
+
+// CancelledCycleMarker préfixe la ligne par laquelle une commande lancée par le planificateur
+// signale un cycle annulé (suivi de son identifiant)
+const CancelledCycleMarker = "@cycle-cancelled "
+
 // TaskConfig représente la configuration d'une tâche planifiée
 type TaskConfig struct {
 	Name            string
@@ -26,4 +46,77 @@ type TaskConfig struct {
 	Percent         float64
 	LastRunTime     time.Time
 	NextScheduledAt time.Time
+	// Offsets en % du prix, l'offset absolu servant alors de plancher (0 = non définis)
+	BuyOffsetPercent  float64
+	SellOffsetPercent float64
+	// Délai aléatoire maximum ajouté à chaque exécution planifiée, en secondes (0 = aucun)
+	JitterSeconds int
+	// Paire négociée par une tâche "new" (BTC/USDC) et sa devise de cotation, par défaut
+	// celles de l'exchange de la tâche
+	Pair       string
+	QuoteAsset string
+	// Âge en jours au-delà duquel une tâche "cancel_stale" annule les achats non exécutés
+	MaxAgeDays int
+}
+
+// QuietHours est la plage horaire quotidienne (HH:MM-HH:MM, heure locale) pendant laquelle le
+// planificateur n'exécute pas les tâches "new". La plage peut passer minuit (22:00-06:00).
+type QuietHours struct {
+	Start string
+	End   string
+}
+
+// ParseQuietHours lit une plage HH:MM-HH:MM; une chaîne vide désactive les heures calmes
+func ParseQuietHours(value string) (QuietHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return QuietHours{}, nil
+	}
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("plage %q invalide (attendu: HH:MM-HH:MM)", value)
+	}
+	quiet := QuietHours{Start: strings.TrimSpace(start), End: strings.TrimSpace(end)}
+	for _, bound := range []string{quiet.Start, quiet.End} {
+		if _, err := time.Parse("15:04", bound); err != nil {
+			return QuietHours{}, fmt.Errorf("heure %q invalide dans la plage %q (attendu: HH:MM-HH:MM)", bound, value)
+		}
+	}
+	if quiet.Start == quiet.End {
+		return QuietHours{}, fmt.Errorf("plage %q vide: le début et la fin sont identiques", value)
+	}
+	return quiet, nil
+}
+
+// Enabled indique si une plage est définie
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" && q.End != ""
+}
+
+// Contains indique si l'instant tombe dans la plage (début inclus, fin exclue)
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	start, errStart := time.Parse("15:04", q.Start)
+	end, errEnd := time.Parse("15:04", q.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// String retourne la plage au format HH:MM-HH:MM, vide si elle n'est pas définie
+func (q QuietHours) String() string {
+	if !q.Enabled() {
+		return ""
+	}
+	return q.Start + "-" + q.End
 }
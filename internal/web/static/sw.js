@@ -0,0 +1,39 @@
+// Service worker du tableau de bord: affiche les notifications Web Push envoyées par le bot et
+// ouvre la page du cycle concerné au clic. Aucune page n'est mise en cache: le tableau de bord
+// n'a de sens qu'avec des données à jour.
+
+self.addEventListener('install', function() {
+    self.skipWaiting();
+});
+
+self.addEventListener('activate', function(event) {
+    event.waitUntil(self.clients.claim());
+});
+
+self.addEventListener('push', function(event) {
+    let data = {};
+    try {
+        data = event.data ? event.data.json() : {};
+    } catch (err) {
+        data = { body: event.data.text() };
+    }
+    event.waitUntil(self.registration.showNotification(data.title || 'Bot Spot', {
+        body: data.body || '',
+        tag: data.tag,
+        icon: '/icon.svg',
+        data: { url: data.url || '/' }
+    }));
+});
+
+self.addEventListener('notificationclick', function(event) {
+    event.notification.close();
+    const url = event.notification.data && event.notification.data.url ? event.notification.data.url : '/';
+    event.waitUntil(self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then(function(windows) {
+        for (const client of windows) {
+            if (new URL(client.url).pathname === url && 'focus' in client) {
+                return client.focus();
+            }
+        }
+        return self.clients.openWindow(url);
+    }));
+});
@@ -0,0 +1,104 @@
+// internal/web/templates.go
+package web
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+
+	"main/internal/config"
+	"main/internal/i18n"
+)
+
+// Noms des templates embarqués
+const (
+	DashboardTemplate = "dashboard.html"
+	StatsTemplate     = "stats.html"
+	LoginTemplate     = "login.html"
+	SchedulerTemplate = "scheduler.html"
+	CycleTemplate     = "cycle.html"
+	LogsTemplate      = "logs.html"
+	BulkTemplate      = "bulk.html"
+
+	// Fragments de dashboard.html rendus seuls pour le rafraîchissement automatique
+	DashboardStatsTemplate = "dashboard-stats"
+	DashboardRowsTemplate  = "dashboard-rows"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// Fichiers de l'application installable: manifeste, service worker des notifications et icône
+//
+//go:embed static
+var staticFS embed.FS
+
+// Static retourne les fichiers servis à la racine du tableau de bord (manifest.webmanifest, sw.js, icon.svg)
+func Static() fs.FS {
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	return static
+}
+
+// FuncMap retourne les fonctions auxiliaires disponibles dans les templates
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"mul": func(a, b float64) float64 {
+			return a * b
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"formatAge": formatAge,
+		// t traduit une clé des catalogues i18n dans la langue configurée
+		"t":    i18n.T,
+		"lang": i18n.Language,
+		// Profil actif (--config, --profile) et son répertoire, vides sans profil
+		"profile":   config.ActiveProfile,
+		"configDir": config.Dir,
+	}
+}
+
+// ParseTemplates compile une seule fois l'ensemble des templates embarqués
+func ParseTemplates() (*template.Template, error) {
+	tmpl, err := template.New("").Funcs(FuncMap()).ParseFS(templatesFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la compilation des templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatAge formate une durée exprimée en jours de façon compacte (ex: 2h 5m, 3j 4h)
+func formatAge(durationInDays float64) string {
+	// Convertir en heures pour faciliter les comparaisons
+	hours := durationInDays * 24
+
+	if hours < 24 {
+		// Moins de 24 heures
+		h := int(hours)
+		m := int((hours - float64(h)) * 60)
+		if h == 0 {
+			// Si moins d'une heure, afficher uniquement les minutes
+			return fmt.Sprintf("%dm", m)
+		}
+		return fmt.Sprintf("%dh %dm", h, m)
+	} else if durationInDays < 7 {
+		// Entre 1 et 7 jours
+		days := int(durationInDays)
+		remainingHours := int(hours) % 24
+		return fmt.Sprintf("%dj %dh", days, remainingHours)
+	} else if durationInDays < 35 {
+		// Entre 7 et 35 jours (5 semaines)
+		weeks := int(durationInDays / 7)
+		remainingDays := int(durationInDays) % 7
+		return fmt.Sprintf("%dsem %dj", weeks, remainingDays)
+	}
+
+	// Plus de 5 semaines
+	months := int(durationInDays / 30)
+	remainingDays := int(durationInDays) % 30
+	return fmt.Sprintf("%dmois %dj", months, remainingDays)
+}
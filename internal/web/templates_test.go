@@ -0,0 +1,746 @@
+package web
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// fixtureCycle retourne un cycle au format DTO tel que construit par le tableau de bord
+func fixtureCycle(status string) map[string]interface{} {
+	cycle := map[string]interface{}{
+		"idInt":               int32(42),
+		"exchange":            "BINANCE",
+		"status":              status,
+		"formattedStatus":     status,
+		"quantity":            0.0015,
+		"buyPrice":            60000.0,
+		"buyId":               "123456",
+		"sellPrice":           61000.0,
+		"sellId":              "654321",
+		"age":                 1.5,
+		"taxYear":             2025,
+		"buyDate":             "01/02/2025 10:00",
+		"buyTotal":            90.0,
+		"sellTotal":           91.5,
+		"profit":              1.5,
+		"profitPercentage":    1.66,
+		"originalBuyOrderId":  "123456",
+		"originalSellOrderId": "654321",
+		"sellTaxYear":         "-",
+		"declareThisYear":     false,
+		"sellDateFormatted":   "",
+		"formattedDuration":   "",
+		"imported":            false,
+		"paused":              status == "sell",
+		"tags":                []string{},
+		"feeRebate":           0.0,
+		"repriceCount":        1,
+		"buyFillPrice":        59950.0,
+		"sellFillPrice":       0.0,
+		"cancelReason":        "",
+		"cancelReasonLabel":   "",
+		"cancelledAt":         "",
+		"hasUnrealized":       false,
+		"unrealizedProfit":    0.0,
+		"unrealizedPercent":   0.0,
+		"currentPrice":        58800.0,
+		"groupId":             int32(0),
+		"groupSubtotal":       nil,
+		"exchangeSubtotal":    nil,
+		"profitDisplay":       nil,
+		"unrealizedDisplay":   nil,
+	}
+	if status == "sell" {
+		cycle["hasUnrealized"] = true
+		cycle["unrealizedProfit"] = -1.96
+		cycle["unrealizedPercent"] = -2.18
+	}
+	if status == "cancelled" {
+		cycle["cancelReason"] = "price_deviation"
+		cycle["cancelReasonLabel"] = "Déviation de prix dépassée"
+		cycle["cancelledAt"] = "03/02/2025 10:00"
+	}
+	if status == "completed" {
+		cycle["sellTaxYear"] = 2025
+		cycle["declareThisYear"] = true
+		cycle["sellDateFormatted"] = "02/02/2025 10:00"
+		cycle["formattedDuration"] = "1j 0h"
+	}
+	return cycle
+}
+
+// fixtureDashboard retourne les données minimales attendues par dashboard.html
+func fixtureDashboard() map[string]interface{} {
+	return map[string]interface{}{
+		"Cycles": []map[string]interface{}{
+			fixtureCycle("buy"),
+			fixtureCycle("sell"),
+			fixtureCycle("completed"),
+			fixtureCycle("cancelled"),
+		},
+		"cyclesCount":      3,
+		"buyCycles":        1,
+		"sellCycles":       1,
+		"cyclesCompleted":  1,
+		"totalBuy":         270.0,
+		"totalSell":        274.5,
+		"gainAbs":          4.5,
+		"gainPercent":      1.66,
+		"currentTime":      "01/02/2025 10:00:00",
+		"showAll":          true,
+		"showCompleted":    false,
+		"showAccumulation": false,
+		"readOnly":         false,
+		"exchangeFilter":   "",
+		"periodFilter":     "",
+		"startDate":        "",
+		"endDate":          "",
+		"statusFilter":     "",
+		"minAgeFilter":     "",
+		"filterQuery":      "",
+		"exchanges":        []string{"BINANCE", "MEXC"},
+		"periodOptions": []map[string]string{
+			{"value": "7j", "label": "7 derniers jours"},
+		},
+		"currentTaxYear":   2025,
+		"taxYearProfits":   map[int]float64{2024: 10, 2025: -2},
+		"totalTaxEstimate": 3.0,
+
+		"displayCurrency":       "",
+		"gainDisplay":           nil,
+		"unrealizedDisplay":     nil,
+		"taxYearProfitsDisplay": map[int]float64{},
+		"sortLinks": map[string]string{
+			"id": "/?dir=asc&sort=id", "exchange": "/?dir=asc&sort=exchange", "status": "/?dir=asc&sort=status",
+			"buy_price": "/?dir=desc&sort=buy_price", "profit": "/?dir=desc&sort=profit",
+			"duration": "/?dir=desc&sort=duration", "age": "/?dir=desc&sort=age",
+		},
+		"sortArrows":  map[string]string{"id": "▼"},
+		"sortColumn":  "id",
+		"sortDir":     "desc",
+		"groupBy":     "",
+		"grandTotal":  nil,
+		"page":        1,
+		"totalPages":  2,
+		"prevPageURL": "",
+		"nextPageURL": "/?page=2",
+
+		"unrealizedByExchange": map[string]float64{"BINANCE": -1.96},
+		"unrealizedTotal":      -1.96,
+		"hasUnrealized":        true,
+		"pricesUpdatedAt":      "01/02/2025 09:55",
+		"refreshSeconds":       30,
+		"pushPublicKey":        "",
+		"priceHistory":         "",
+	}
+}
+
+func TestParseTemplates(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	for _, name := range []string{DashboardTemplate, DashboardStatsTemplate, DashboardRowsTemplate, StatsTemplate, LoginTemplate, SchedulerTemplate, CycleTemplate, LogsTemplate, BulkTemplate} {
+		if tmpl.Lookup(name) == nil {
+			t.Errorf("template %s introuvable", name)
+		}
+	}
+}
+
+func TestDashboardTemplateCycles(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, fixtureDashboard())
+	if err != nil {
+		t.Fatalf("rendu du tableau de bord: %v", err)
+	}
+	if !strings.Contains(buf.String(), "654321") {
+		t.Errorf("l'identifiant de l'ordre de vente devrait apparaître dans le rendu")
+	}
+	if !strings.Contains(buf.String(), "Déviation de prix dépassée") {
+		t.Errorf("la cause de l'annulation devrait apparaître dans le rendu")
+	}
+	if !strings.Contains(buf.String(), "-1.96 (-2.18%)") {
+		t.Errorf("le P&L latent du cycle en vente devrait apparaître dans le rendu")
+	}
+}
+
+func TestDashboardTemplateGroupSubtotal(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	data := fixtureDashboard()
+	first, last := fixtureCycle("buy"), fixtureCycle("sell")
+	first["groupId"], last["groupId"] = int32(42), int32(42)
+	last["groupSubtotal"] = map[string]interface{}{
+		"groupId": int32(42), "count": 2, "quantity": 0.003, "buyTotal": 180.0, "sellTotal": 91.5, "profit": 1.5,
+	}
+	data["Cycles"] = []map[string]interface{}{first, last}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardRowsTemplate, data); err != nil {
+		t.Fatalf("rendu des lignes: %v", err)
+	}
+	if strings.Count(buf.String(), ">G42<") != 2 {
+		t.Errorf("les deux tranches devraient porter le badge du groupe")
+	}
+	if strings.Count(buf.String(), "group-subtotal") != 1 || !strings.Contains(buf.String(), "180.00000000") {
+		t.Errorf("le sous-total du groupe devrait suivre la dernière tranche")
+	}
+}
+
+func TestDashboardTemplateExchangeSubtotal(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	type subtotal struct {
+		Exchange                                 string
+		Count                                    int
+		DeployedUSDC, ExpectedProfit, Unrealized float64
+		AccumulatedBTC, AccumulatedValue         float64
+	}
+	data := fixtureDashboard()
+	data["groupBy"] = "exchange"
+	data["grandTotal"] = &subtotal{Count: 2, DeployedUSDC: 180, ExpectedProfit: 1.5, Unrealized: -1.96, AccumulatedBTC: 0.001, AccumulatedValue: 62.5}
+	first, last := fixtureCycle("buy"), fixtureCycle("sell")
+	last["exchangeSubtotal"] = &subtotal{Exchange: "BINANCE", Count: 2, DeployedUSDC: 180, ExpectedProfit: 1.5, Unrealized: -1.96}
+	data["Cycles"] = []map[string]interface{}{first, last}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardRowsTemplate, data); err != nil {
+		t.Fatalf("rendu des lignes: %v", err)
+	}
+	if strings.Count(buf.String(), "exchange-subtotal") != 1 || !strings.Contains(buf.String(), "BINANCE (2 cycles)") {
+		t.Errorf("le sous-total de l'exchange devrait suivre sa dernière ligne")
+	}
+	if strings.Count(buf.String(), "grand-total") != 1 || !strings.Contains(buf.String(), "180.00000000") {
+		t.Errorf("le total général devrait terminer le tableau")
+	}
+	if strings.Count(buf.String(), "62.50 USDC") != 1 {
+		t.Errorf("la valeur du BTC accumulé ne devrait figurer qu'au total général, seul à en porter")
+	}
+}
+
+func TestDashboardTemplateReadOnly(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	data := fixtureDashboard()
+	data["readOnly"] = true
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu en lecture seule: %v", err)
+	}
+	if strings.Contains(buf.String(), `method="post"`) || strings.Contains(buf.String(), `method="POST"`) {
+		t.Errorf("aucun formulaire de modification ne doit être affiché en lecture seule")
+	}
+	if !strings.Contains(buf.String(), "654321") {
+		t.Errorf("les cycles doivent rester affichés en lecture seule")
+	}
+}
+
+func TestDashboardTemplateBulkActions(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	data := fixtureDashboard()
+	data["filterQuery"] = "exchange=MEXC&min_age=20&status=buy"
+	data["statusFilter"], data["minAgeFilter"] = "buy", "20"
+	tagged := fixtureCycle("buy")
+	tagged["tags"] = []string{"vieux-mexc"}
+	data["Cycles"] = []map[string]interface{}{tagged}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu du tableau de bord: %v", err)
+	}
+	page := buf.String()
+	if !strings.Contains(page, `action="/api/cycles/bulk"`) || !strings.Contains(page, `name="filter" value="exchange=MEXC&amp;min_age=20&amp;status=buy"`) {
+		t.Errorf("le formulaire d'action groupée devrait reprendre les filtres courants")
+	}
+	if !strings.Contains(page, `name="ids" value="42" form="bulkForm"`) {
+		t.Errorf("chaque cycle devrait avoir une case à cocher rattachée au formulaire groupé")
+	}
+	if !strings.Contains(page, `<option value="buy" selected>`) || !strings.Contains(page, `name="min_age" class="form-control" value="20"`) {
+		t.Errorf("les filtres de statut et d'âge devraient être conservés")
+	}
+	if !strings.Contains(page, ">vieux-mexc<") {
+		t.Errorf("les étiquettes du cycle devraient être affichées")
+	}
+}
+
+func TestDashboardTemplateDisplayCurrency(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	conversion := func(amount float64) map[string]interface{} {
+		return map[string]interface{}{"Currency": "EUR", "Amount": amount, "Rate": 0.9615, "RateDate": "2025-01-06"}
+	}
+	data := fixtureDashboard()
+	completed := fixtureCycle("completed")
+	completed["profitDisplay"] = conversion(1.44)
+	data["Cycles"] = []map[string]interface{}{completed}
+	data["displayCurrency"] = "EUR"
+	data["gainDisplay"] = conversion(4.33)
+	data["taxYearProfitsDisplay"] = map[int]float64{2024: 9.5, 2025: -1.9}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu avec conversion: %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{"≈ 1.44 EUR", "1 USDC = 0.9615 EUR", "2025-01-06", "≈ 4.33 EUR", "<td>9.50</td>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("%q absent du tableau de bord converti", want)
+		}
+	}
+}
+
+func TestMakerRebateLabels(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	completed := fixtureCycle("completed")
+	completed["feeRebate"] = 0.0125
+	data := fixtureDashboard()
+	data["Cycles"] = []map[string]interface{}{completed}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu du tableau de bord: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Remise maker nette reçue: 0.01250000 USDC") {
+		t.Error("la remise maker devrait être signalée sur le profit du cycle")
+	}
+
+	completed["purchaseAmountUSDC"], completed["saleAmountUSDC"] = 90.0, 91.5
+	completed["totalFees"] = -0.0125
+	buf.Reset()
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, CycleTemplate, map[string]interface{}{
+		"cycle": completed, "orderSnapshots": nil, "message": "", "error": "", "readOnly": false,
+		"currentTime": "01/02/2025 10:00:00",
+	})
+	if err != nil {
+		t.Fatalf("rendu du détail du cycle: %v", err)
+	}
+	if page := buf.String(); !strings.Contains(page, "<th>Remise maker</th>") || strings.Contains(page, "<th>Frais</th>") {
+		t.Error("le détail du cycle devrait afficher la remise maker à la place des frais")
+	}
+}
+
+func TestBulkTemplate(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, BulkTemplate, map[string]interface{}{
+		"summary": map[string]interface{}{
+			"Action": "cancel", "Tag": "", "Done": 1, "Skipped": 1, "Failed": 1,
+			"Results": []map[string]interface{}{
+				{"IdInt": int32(42), "Exchange": "MEXC", "Status": "cancelled", "Outcome": "done", "Message": ""},
+				{"IdInt": int32(43), "Exchange": "MEXC", "Status": "completed", "Outcome": "skipped", "Message": "statut 'completed': aucun ordre à annuler"},
+				{"IdInt": int32(44), "Exchange": "MEXC", "Status": "buy", "Outcome": "failed", "Message": "échec de l'annulation de l'ordre 9"},
+			},
+		},
+		"currentTime": "01/02/2025 10:00:00",
+	})
+	if err != nil {
+		t.Fatalf("rendu du récapitulatif: %v", err)
+	}
+	for _, want := range []string{`href="/cycles/43"`, "1 en échec", "échec de l&#39;annulation de l&#39;ordre 9"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%q absent du récapitulatif", want)
+		}
+	}
+}
+
+func TestDashboardFragments(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	// Les fragments rafraîchis par /api/dashboard-data sont ceux de la page complète
+	var page, rows bytes.Buffer
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&page, DashboardTemplate, fixtureDashboard()); err != nil {
+		t.Fatalf("rendu du tableau de bord: %v", err)
+	}
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&rows, DashboardRowsTemplate, fixtureDashboard()); err != nil {
+		t.Fatalf("rendu des lignes: %v", err)
+	}
+	if !strings.Contains(rows.String(), "654321") || !strings.Contains(page.String(), rows.String()) {
+		t.Errorf("les lignes rendues seules devraient être identiques à celles de la page")
+	}
+	if !strings.Contains(page.String(), "const interval =  30  * 1000;") {
+		t.Errorf("l'intervalle de rafraîchissement devrait être injecté dans le script")
+	}
+
+	data := fixtureDashboard()
+	data["refreshSeconds"] = 0
+	page.Reset()
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&page, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu sans rafraîchissement: %v", err)
+	}
+	if strings.Contains(page.String(), `id="refreshToggle"`) {
+		t.Errorf("le bouton de pause ne doit pas être affiché sans rafraîchissement automatique")
+	}
+	if strings.Contains(page.String(), `id="pushToggle"`) || !strings.Contains(page.String(), `rel="manifest"`) {
+		t.Errorf("le manifeste doit être lié, sans bouton de notifications tant que les clés VAPID sont absentes")
+	}
+
+	if strings.Contains(page.String(), `id="priceHistory"`) {
+		t.Errorf("le graphique du prix ne doit pas être affiché quand l'historique est désactivé")
+	}
+	data["priceHistory"] = "BINANCE"
+	page.Reset()
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&page, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu avec historique du prix: %v", err)
+	}
+	if !strings.Contains(page.String(), `data-exchange="BINANCE"`) {
+		t.Errorf("le graphique du prix devrait interroger l'exchange affiché")
+	}
+
+	data["pushPublicKey"] = "BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"
+	page.Reset()
+	if err := tmpl.Option("missingkey=error").ExecuteTemplate(&page, DashboardTemplate, data); err != nil {
+		t.Fatalf("rendu avec notifications push: %v", err)
+	}
+	if !strings.Contains(page.String(), `id="pushToggle"`) || !strings.Contains(page.String(), `data-key="BNcRdreALRFXTkOOUHK1EtK2`) {
+		t.Errorf("le bouton de notifications devrait porter la clé publique VAPID")
+	}
+}
+
+func TestDashboardTemplateAccumulations(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	data := fixtureDashboard()
+	data["showAccumulation"] = true
+	data["hasAccumulations"] = true
+	data["allAccumulations"] = []map[string]interface{}{
+		{
+			"idInt":              int32(7),
+			"exchange":           "KRAKEN",
+			"quantity":           0.00123456,
+			"originalBuyPrice":   58000.0,
+			"targetSellPrice":    59000.5,
+			"cancelPrice":        53100.25,
+			"deviation":          10.0,
+			"savedValue":         7.28,
+			"hasCurrentPrice":    true,
+			"currentValue":       80.25,
+			"unrealizedGain":     14.69,
+			"createdAtFormatted": "03/03/2025 12:00:00",
+			"taxYear":            2025,
+			"convertedCycleId":   int32(12),
+		},
+	}
+	data["accumulationStats"] = map[string]map[string]interface{}{
+		"KRAKEN": {
+			"enabled":          true,
+			"count":            1,
+			"totalQuantity":    0.00123456,
+			"savedValue":       7.28,
+			"hasCurrentPrice":  true,
+			"currentValue":     80.25,
+			"unrealizedGain":   14.69,
+			"averageDeviation": 10.0,
+			"profitPercent":    50.0,
+			"singleCap":        25.0,
+			"remaining":        12.34,
+		},
+	}
+	data["accumulationCount"] = 1
+	data["accumulationTotalQuantity"] = 0.00123456
+	data["accumulationTotalCost"] = 71.6
+	data["accumulationSavedValue"] = 7.28
+	data["accumulationCurrentValue"] = 80.25
+	data["accumulationUnrealizedGain"] = 14.69
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, DashboardTemplate, data)
+	if err != nil {
+		t.Fatalf("rendu de la vue accumulation: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"0.00123456", "58000.00", "59000.50", "53100.25", "10.00%", "7.28 USDC", "03/03/2025 12:00:00", "50%", "25.00 USDC", "12.34 USDC", "80.25 USDC", "14.69 USDC"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("la vue accumulation devrait contenir %q", want)
+		}
+	}
+
+	// Les cycles de trading ne doivent pas être rendus en mode accumulation
+	if strings.Contains(output, "654321") {
+		t.Errorf("la vue accumulation ne doit pas afficher les cycles de trading")
+	}
+}
+
+func TestStatsTemplate(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, StatsTemplate, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("rendu de la page de statistiques: %v", err)
+	}
+}
+
+func TestLogsTemplate(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, LogsTemplate, map[string]interface{}{
+		"bufferSize":  5000,
+		"currentTime": "01/02/2025 10:00:00",
+	})
+	if err != nil {
+		t.Fatalf("rendu de l'onglet Logs: %v", err)
+	}
+	if !strings.Contains(strings.ReplaceAll(buf.String(), " ", ""), "constmaxEntries=5000;") {
+		t.Error("taille du tampon absente du script de la page")
+	}
+}
+
+func TestSchedulerTemplate(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"tasks": []map[string]interface{}{
+			{
+				"name":             "update-cycles",
+				"type":             "update",
+				"exchange":         "",
+				"enabled":          true,
+				"interval":         "5 minutes",
+				"specificTime":     "",
+				"maxAgeDays":       0,
+				"nextRunFormatted": "01/02/2025 10:05:00",
+				"lastRunFormatted": "01/02/2025 10:00:00",
+				"lastError":        "exit status 1",
+			},
+			{
+				"name":             "create-cycle",
+				"type":             "new",
+				"exchange":         "BINANCE",
+				"enabled":          false,
+				"interval":         "1 jour",
+				"specificTime":     "09:00",
+				"maxAgeDays":       0,
+				"nextRunFormatted": "02/02/2025 09:00:00",
+				"lastRunFormatted": "-",
+				"lastError":        "",
+			},
+			{
+				"name":             "cancel-stale-buys",
+				"type":             "cancel_stale",
+				"exchange":         "KUCOIN",
+				"enabled":          true,
+				"interval":         "1 jour",
+				"specificTime":     "03:00",
+				"maxAgeDays":       5,
+				"nextRunFormatted": "02/02/2025 03:00:00",
+				"lastRunFormatted": "01/02/2025 03:00:00",
+				"lastError":        "",
+			},
+		},
+		"history": []map[string]interface{}{
+			{
+				"task":               "create-cycle",
+				"exchange":           "BINANCE",
+				"startedAtFormatted": "01/02/2025 09:00:00",
+				"duration":           "12.4s",
+				"success":            true,
+				"skipped":            false,
+				"outcome":            "succès",
+				"error":              "",
+				"cycleIds":           []int32{42},
+				"cancelledCycleIds":  []int32(nil),
+			},
+			{
+				"task":               "cancel-stale-buys",
+				"exchange":           "KUCOIN",
+				"startedAtFormatted": "01/02/2025 03:00:00",
+				"duration":           "4.2s",
+				"success":            true,
+				"skipped":            false,
+				"outcome":            "succès",
+				"error":              "",
+				"cycleIds":           []int32(nil),
+				"cancelledCycleIds":  []int32{17, 18},
+			},
+			{
+				"task":               "update-cycles",
+				"exchange":           "",
+				"startedAtFormatted": "01/02/2025 10:00:00",
+				"duration":           "3.1s",
+				"success":            false,
+				"skipped":            false,
+				"outcome":            "échec",
+				"error":              "exit status 1",
+				"cycleIds":           []int32(nil),
+				"cancelledCycleIds":  []int32(nil),
+			},
+		},
+		"daemonRunning": true,
+		"daemonPID":     1234,
+		"daemonSince":   "01/02/2025 08:00:00",
+		"readOnly":      false,
+		"currentTime":   "01/02/2025 10:01:00",
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, SchedulerTemplate, data)
+	if err != nil {
+		t.Fatalf("rendu de l'onglet planificateur: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"update-cycles", "01/02/2025 10:05:00", "exit status 1", "BINANCE", "à 09:00", "PID 1234", "/cycles/42", "12.4s", "(&gt; 5 j)", "Annulés: <a href=\"/cycles/17\">17</a>, <a href=\"/cycles/18\">18</a>"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("l'onglet planificateur devrait contenir %q", want)
+		}
+	}
+}
+
+func TestCycleTemplate(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	for _, status := range []string{"sell", "completed", "cancelled"} {
+		cycle := fixtureCycle(status)
+		cycle["purchaseAmountUSDC"] = 90.0
+		cycle["saleAmountUSDC"] = 91.5
+		cycle["totalFees"] = 0.09
+		cycle["feeAssetNote"] = ""
+		if status == "completed" {
+			cycle["feeAssetNote"] = "0.00007500 BNB à l'achat, 0.00007600 BNB à la vente"
+		}
+
+		// Réponses brutes conservées à l'exécution des ordres d'un cycle complété
+		var orderSnapshots []map[string]interface{}
+		if status == "completed" {
+			orderSnapshots = []map[string]interface{}{{
+				"Side": "sell", "Label": "Vente", "OrderId": "123456", "CapturedAt": "02/02/2025 10:00:00",
+				"Size": 42, "Truncated": false, "Payload": "{\n  \"status\": \"FILLED\"\n}",
+			}}
+		}
+
+		var buf bytes.Buffer
+		err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, CycleTemplate, map[string]interface{}{
+			"cycle":          cycle,
+			"orderSnapshots": orderSnapshots,
+			"message":        "",
+			"error":          "",
+			"readOnly":       false,
+			"currentTime":    "01/02/2025 10:00:00",
+		})
+		if err != nil {
+			t.Fatalf("rendu du détail d'un cycle %s: %v", status, err)
+		}
+
+		// Le formulaire de prix de vente n'est proposé que pour un cycle en vente
+		hasForm := strings.Contains(buf.String(), `action="/cycles/42/sell-price"`)
+		if hasForm != (status == "sell") {
+			t.Errorf("cycle %s: formulaire de prix de vente présent = %v", status, hasForm)
+		}
+
+		// Un cycle en pause propose de reprendre, un cycle terminé n'a pas de bouton
+		hasResume := strings.Contains(buf.String(), `action="/cycles/42/resume"`)
+		if hasResume != (status == "sell") || strings.Contains(buf.String(), `action="/cycles/42/pause"`) {
+			t.Errorf("cycle %s: bouton de reprise présent = %v", status, hasResume)
+		}
+
+		// La cause de l'annulation n'est affichée que pour un cycle annulé
+		hasReason := strings.Contains(buf.String(), "Déviation de prix dépassée le 03/02/2025 10:00")
+		if hasReason != (status == "cancelled") {
+			t.Errorf("cycle %s: cause d'annulation présente = %v", status, hasReason)
+		}
+
+		// Les réponses brutes sont affichées avec leur lien de téléchargement
+		hasSnapshot := strings.Contains(buf.String(), `href="/cycles/42/order-snapshots/sell"`) &&
+			strings.Contains(buf.String(), "&#34;status&#34;: &#34;FILLED&#34;")
+		if hasSnapshot != (status == "completed") {
+			t.Errorf("cycle %s: réponse brute de la vente présente = %v", status, hasSnapshot)
+		}
+
+		// Les frais payés en BNB sont rappelés tels que prélevés à côté de leur valeur en USDC
+		hasFeeAsset := strings.Contains(buf.String(), "(payés 0.00007500 BNB à l&#39;achat, 0.00007600 BNB à la vente)")
+		if hasFeeAsset != (status == "completed") {
+			t.Errorf("cycle %s: frais en BNB présents = %v", status, hasFeeAsset)
+		}
+	}
+}
+
+func TestLoginTemplateEscapesNext(t *testing.T) {
+	tmpl, err := ParseTemplates()
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"next":  `/"><script>`,
+		"error": "Jeton invalide.",
+	}
+	err = tmpl.Option("missingkey=error").ExecuteTemplate(&buf, LoginTemplate, data)
+	if err != nil {
+		t.Fatalf("rendu de la page de connexion: %v", err)
+	}
+	if strings.Contains(buf.String(), "<script>") {
+		t.Errorf("le paramètre next doit être échappé")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		days float64
+		want string
+	}{
+		{0.5 / 24, "30m"},
+		{2.5 / 24, "2h 30m"},
+		{3.25, "3j 6h"},
+		{10, "1sem 3j"},
+		{65, "2mois 5j"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.days); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, attendu %q", tt.days, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// internal/webpush/webpush.go
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Envoi de notifications Web Push sans dépendance externe: le contenu est chiffré selon la
+// RFC 8291 (aes128gcm) et le serveur de push authentifie l'expéditeur par un jeton VAPID
+// (RFC 8292) signé avec la clé privée configurée.
+
+// recordSize est la taille d'enregistrement annoncée dans l'en-tête aes128gcm: les
+// notifications tiennent en un seul enregistrement
+const recordSize = 4096
+
+// MaxPayload est la taille maximale d'une notification acceptée par les serveurs de push
+const MaxPayload = 3993
+
+// vapidValidity est la durée de validité des jetons VAPID (24 h au plus)
+const vapidValidity = 12 * time.Hour
+
+// ErrExpired est retournée par Send lorsque le serveur de push ne connaît plus l'abonnement:
+// le navigateur l'a révoqué et il doit être supprimé
+var ErrExpired = errors.New("abonnement push expiré")
+
+// Keys sont les clés de chiffrement d'un abonnement, fournies par le navigateur
+type Keys struct {
+	P256dh string `json:"p256dh"` // Clé publique P-256 du navigateur (base64url)
+	Auth   string `json:"auth"`   // Secret d'authentification (base64url, 16 octets)
+}
+
+// Subscription est un abonnement Web Push, tel que sérialisé par PushSubscription.toJSON()
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     Keys   `json:"keys"`
+}
+
+// Validate vérifie l'URL du serveur de push et les clés de l'abonnement
+func (s Subscription) Validate() error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("endpoint %q invalide: https attendu", s.Endpoint)
+	}
+	if _, err := s.keys(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// subscriptionKeys sont les clés décodées d'un abonnement
+type subscriptionKeys struct {
+	public *ecdh.PublicKey
+	auth   []byte
+}
+
+func (s Subscription) keys() (subscriptionKeys, error) {
+	raw, err := decode(s.Keys.P256dh)
+	if err != nil {
+		return subscriptionKeys{}, fmt.Errorf("clé p256dh illisible: %w", err)
+	}
+	public, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return subscriptionKeys{}, fmt.Errorf("clé p256dh invalide: %w", err)
+	}
+	auth, err := decode(s.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return subscriptionKeys{}, fmt.Errorf("secret auth invalide (16 octets attendus)")
+	}
+	return subscriptionKeys{public: public, auth: auth}, nil
+}
+
+// VAPID identifie l'expéditeur auprès des serveurs de push
+type VAPID struct {
+	PublicKey  string // Clé publique P-256 non compressée (base64url), transmise au navigateur
+	PrivateKey string // Scalaire de la clé privée (base64url, 32 octets)
+	Subject    string // Contact de l'expéditeur: mailto: ou https:
+}
+
+// GenerateKeys crée une paire de clés VAPID encodée en base64url
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encode(key.PublicKey().Bytes()), encode(key.Bytes()), nil
+}
+
+// ValidateKeys vérifie que la clé privée VAPID correspond à la clé publique
+func ValidateKeys(publicKey, privateKey string) error {
+	key, err := signingKey(privateKey)
+	if err != nil {
+		return err
+	}
+	public, err := decode(publicKey)
+	if err != nil {
+		return fmt.Errorf("clé publique illisible: %w", err)
+	}
+	if !bytes.Equal(public, marshalPublic(&key.PublicKey)) {
+		return fmt.Errorf("la clé publique ne correspond pas à la clé privée")
+	}
+	return nil
+}
+
+// signingKey décode la clé privée VAPID en clé de signature ECDSA
+func signingKey(privateKey string) (*ecdsa.PrivateKey, error) {
+	raw, err := decode(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("clé privée illisible: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("clé privée invalide: %w", err)
+	}
+	public := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// marshalPublic retourne la forme non compressée d'une clé publique P-256
+func marshalPublic(key *ecdsa.PublicKey) []byte {
+	out := make([]byte, 65)
+	out[0] = 4
+	key.X.FillBytes(out[1:33])
+	key.Y.FillBytes(out[33:])
+	return out
+}
+
+// Send chiffre payload pour l'abonnement et le transmet à son serveur de push, qui le conserve
+// au plus ttl si le navigateur n'est pas joignable
+func Send(client *http.Client, sub Subscription, payload []byte, vapid VAPID, ttl time.Duration) error {
+	if len(payload) > MaxPayload {
+		return fmt.Errorf("notification trop longue: %d octets (%d au plus)", len(payload), MaxPayload)
+	}
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := vapidAuthorization(sub.Endpoint, vapid, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// L'URL du serveur de push identifie l'abonnement: elle n'est pas citée
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrExpired
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("statut HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// Encrypt chiffre payload pour l'abonnement (RFC 8291, codage aes128gcm de la RFC 8188):
+// sel, taille d'enregistrement et clé publique éphémère en en-tête, suivis du contenu chiffré
+func Encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	keys, err := sub.keys()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := ephemeral.ECDH(keys.public)
+	if err != nil {
+		return nil, err
+	}
+	cek, nonce := contentKeys(sharedSecret, keys.auth, salt, keys.public.Bytes(), ephemeral.PublicKey().Bytes())
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un seul enregistrement, terminé par le délimiteur 0x02
+	plaintext := append(append([]byte{}, payload...), 2)
+
+	header := make([]byte, 0, 16+4+1+65)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(ephemeral.PublicKey().Bytes())))
+	header = append(header, ephemeral.PublicKey().Bytes()...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// contentKeys dérive la clé de chiffrement et le nonce du contenu (RFC 8291 §3.4)
+func contentKeys(sharedSecret, auth, salt, uaPublic, asPublic []byte) (cek, nonce []byte) {
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdfExpand(hkdfExtract(auth, sharedSecret), keyInfo, 32)
+
+	prk := hkdfExtract(salt, ikm)
+	cek = hkdfExpand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce = hkdfExpand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	return cek, nonce
+}
+
+// hkdfExtract et hkdfExpand implémentent HKDF-SHA256 (RFC 5869) pour des sorties d'au plus
+// un bloc, seules utilisées ici
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+func hkdfExpand(prk, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{1})
+	return mac.Sum(nil)[:length]
+}
+
+// vapidAuthorization construit l'en-tête Authorization d'un envoi: jeton JWT ES256 limité à
+// l'origine du serveur de push et clé publique de l'expéditeur
+func vapidAuthorization(endpoint string, vapid VAPID, now time.Time) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	key, err := signingKey(vapid.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header := encode([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": target.Scheme + "://" + target.Host,
+		"exp": now.Add(vapidValidity).Unix(),
+		"sub": vapid.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + encode(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", unsigned, encode(signature), encode(marshalPublic(&key.PublicKey))), nil
+}
+
+// encode et decode utilisent le base64url sans remplissage des clés Web Push; decode accepte
+// aussi les valeurs complétées par des '='
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(value), "="))
+}
@@ -0,0 +1,163 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// browserSubscription simule l'abonnement d'un navigateur et retourne sa clé privée
+func browserSubscription(t *testing.T, endpoint string) (Subscription, *ecdh.PrivateKey) {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return Subscription{
+		Endpoint: endpoint,
+		Keys:     Keys{P256dh: encode(key.PublicKey().Bytes()), Auth: encode(auth)},
+	}, key
+}
+
+// decrypt déchiffre le contenu comme le ferait le navigateur
+func decrypt(t *testing.T, sub Subscription, key *ecdh.PrivateKey, body []byte) []byte {
+	t.Helper()
+	salt, rs, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	if rs != recordSize || idLen != 65 {
+		t.Fatalf("en-tête aes128gcm: rs=%d idlen=%d", rs, idLen)
+	}
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := key.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, _ := decode(sub.Keys.Auth)
+	cek, nonce := contentKeys(shared, auth, salt, key.PublicKey().Bytes(), asPublic.Bytes())
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("déchiffrement: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 2 {
+		t.Fatalf("délimiteur du dernier enregistrement absent")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	sub, key := browserSubscription(t, "https://push.example.com/send/abc")
+	if err := sub.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"title":"Bot Spot","body":"Cycle 12 complété"}`)
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decrypt(t, sub, key, body); string(got) != string(payload) {
+		t.Errorf("contenu déchiffré %q, want %q", got, payload)
+	}
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateKeys(publicKey, privateKey); err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, _ := GenerateKeys()
+	if err := ValidateKeys(otherPublic, privateKey); err == nil {
+		t.Error("une clé publique d'une autre paire devrait être refusée")
+	}
+
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	vapid := VAPID{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:bot@example.com"}
+	header, err := vapidAuthorization("https://fcm.googleapis.com/fcm/send/xyz", vapid, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, k, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !ok || k != publicKey {
+		t.Fatalf("en-tête %q", header)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jeton %q", token)
+	}
+
+	var claims map[string]interface{}
+	raw, _ := decode(parts[1])
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims["aud"] != "https://fcm.googleapis.com" || claims["sub"] != vapid.Subject ||
+		int64(claims["exp"].(float64)) != now.Add(vapidValidity).Unix() {
+		t.Errorf("revendications %v", claims)
+	}
+
+	key, _ := signingKey(privateKey)
+	signature, _ := decode(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Error("signature ES256 invalide")
+	}
+}
+
+func TestSend(t *testing.T) {
+	publicKey, privateKey, _ := GenerateKeys()
+	vapid := VAPID{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:bot@example.com"}
+
+	status := http.StatusCreated
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") != "3600" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+			t.Errorf("en-têtes %v", r.Header)
+		}
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sub, key := browserSubscription(t, server.URL+"/push/1")
+	if err := Send(server.Client(), sub, []byte("ping"), vapid, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got := decrypt(t, sub, key, received); string(got) != "ping" {
+		t.Errorf("contenu reçu %q", got)
+	}
+
+	status = http.StatusGone
+	if err := Send(server.Client(), sub, []byte("ping"), vapid, time.Hour); !errors.Is(err, ErrExpired) {
+		t.Errorf("abonnement révoqué: %v, want ErrExpired", err)
+	}
+
+	if err := (Subscription{Endpoint: "http://push.example.com", Keys: sub.Keys}).Validate(); err == nil {
+		t.Error("un endpoint http devrait être refusé")
+	}
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// followTailBytes est la fin de fichier relue au démarrage de FollowFile
+const followTailBytes = 256 * 1024
+
+var (
+	// [2006-01-02 15:04:05] [WARN] message, format texte de Logger
+	textLinePattern = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\] \[(DEBUG|INFO|WARN|ERROR)\] (.*)$`)
+	// 2006/01/02 15:04:05 message, format du paquet log
+	stdLinePattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) (.*)$`)
+)
+
+// ParseLine convertit une ligne écrite par un Logger (texte ou JSON) ou par le paquet log en
+// entrée. Une ligne sans horodatage (sortie d'une commande, suite d'un message sur plusieurs
+// lignes) reprend l'heure et le niveau de l'entrée précédente.
+func ParseLine(line string, previous Entry) Entry {
+	if strings.HasPrefix(line, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err == nil {
+			entry := Entry{Level: ParseLevel(stringField(fields, "level")).String(), Message: stringField(fields, "message")}
+			entry.Time, _ = time.ParseInLocation("2006-01-02 15:04:05", stringField(fields, "time"), time.Local)
+			delete(fields, "level")
+			delete(fields, "message")
+			delete(fields, "time")
+			if len(fields) > 0 {
+				entry.Fields = fields
+			}
+			return entry
+		}
+	}
+
+	if match := textLinePattern.FindStringSubmatch(line); match != nil {
+		entry := Entry{Level: match[2], Message: match[3]}
+		entry.Time, _ = time.ParseInLocation("2006-01-02 15:04:05", match[1], time.Local)
+		return entry
+	}
+	if match := stdLinePattern.FindStringSubmatch(line); match != nil {
+		entry := Entry{Level: LevelInfo.String(), Message: match[2]}
+		entry.Time, _ = time.ParseInLocation("2006/01/02 15:04:05", match[1], time.Local)
+		return entry
+	}
+
+	level := previous.Level
+	if level == "" {
+		level = LevelInfo.String()
+	}
+	return Entry{Time: previous.Time, Level: level, Message: line}
+}
+
+// stringField retourne un champ texte d'une ligne JSON, "" s'il est absent
+func stringField(fields map[string]interface{}, key string) string {
+	value, _ := fields[key].(string)
+	return value
+}
+
+// FollowFile ajoute au tampon les lignes écrites dans un fichier de log par un autre processus
+// (le daemon du planificateur), en relisant d'abord sa fin. Le fichier est rouvert à chaque
+// lecture: il peut être absent, tronqué ou remplacé sans interrompre le suivi. FollowFile
+// s'arrête à la fermeture de stop (nil = jamais).
+func FollowFile(path string, ring *RingBuffer, interval time.Duration, stop <-chan struct{}) {
+	follower := &fileFollower{path: path, ring: ring, offset: -1}
+	for {
+		follower.poll()
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// fileFollower mémorise la position de lecture d'un fichier suivi par FollowFile
+type fileFollower struct {
+	path     string
+	ring     *RingBuffer
+	offset   int64 // -1 tant que le fichier n'a pas été lu
+	partial  string
+	previous Entry
+}
+
+// poll lit les lignes ajoutées depuis la lecture précédente
+func (f *fileFollower) poll() {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	skipFirst := false
+	switch {
+	case f.offset < 0:
+		f.offset = max(0, info.Size()-followTailBytes)
+		skipFirst = f.offset > 0 // première ligne probablement coupée
+	case info.Size() < f.offset:
+		// Fichier tronqué ou remplacé: reprendre au début
+		f.offset, f.partial = 0, ""
+	}
+	if info.Size() == f.offset {
+		return
+	}
+
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return
+	}
+	f.offset += int64(len(data))
+
+	lines := strings.Split(f.partial+string(data), "\n")
+	f.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	if skipFirst && len(lines) > 0 {
+		lines = lines[1:]
+	}
+
+	source := filepath.Base(f.path)
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry := ParseLine(line, f.previous)
+		f.previous = entry
+		entry.Source = source
+		f.ring.Add(entry)
+	}
+}
@@ -1,9 +1,11 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
 )
@@ -26,6 +28,9 @@ const (
 	FormatJSON LogFormat = "json"
 )
 
+// Fields contient les champs structurés associés à un message
+type Fields map[string]interface{}
+
 // LogConfig contient la configuration du logger
 type LogConfig struct {
 	Level  string
@@ -42,19 +47,7 @@ type Logger struct {
 // NewLogger crée une nouvelle instance de Logger
 func NewLogger(config LogConfig) *Logger {
 	// Déterminer le niveau de log
-	var level LogLevel
-	switch strings.ToLower(config.Level) {
-	case "debug":
-		level = LevelDebug
-	case "info":
-		level = LevelInfo
-	case "warn":
-		level = LevelWarn
-	case "error":
-		level = LevelError
-	default:
-		level = LevelInfo
-	}
+	level := ParseLevel(config.Level)
 
 	// Déterminer le format
 	format := FormatText
@@ -72,43 +65,122 @@ func NewLogger(config LogConfig) *Logger {
 	}
 }
 
-// formatMessage formate un message selon le format configuré
-func (l *Logger) formatMessage(level, format string, args ...interface{}) string {
+// ParseLevel convertit un niveau textuel (debug, info, warn, error) en LogLevel
+func ParseLevel(level string) LogLevel {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LevelDebug
+	case "info":
+		return LevelInfo
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// String retourne le nom du niveau tel qu'affiché dans les logs
+func (lvl LogLevel) String() string {
+	switch lvl {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// Enabled indique si un message du niveau donné doit être émis
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l.level <= level
+}
+
+// IsJSON indique si le logger produit du JSON
+func (l *Logger) IsJSON() bool {
+	return l.format == FormatJSON
+}
+
+// formatEntry formate un message accompagné de champs structurés
+func (l *Logger) formatEntry(level string, fields Fields, format string, args ...interface{}) string {
 	message := fmt.Sprintf(format, args...)
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 
 	if l.format == FormatJSON {
-		return fmt.Sprintf("{\"time\":\"%s\",\"level\":\"%s\",\"message\":\"%s\"}",
-			timestamp, level, message)
+		entry := make(map[string]interface{}, len(fields)+3)
+		for key, value := range fields {
+			// Les erreurs ne sont pas sérialisables telles quelles
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry[key] = value
+		}
+		entry["time"] = timestamp
+		entry["level"] = level
+		entry["message"] = message
+
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Sprintf("{\"time\":%q,\"level\":%q,\"message\":%q}", timestamp, level, message)
+		}
+		return string(encoded)
+	}
+
+	if len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, fields[key]))
+		}
+		return fmt.Sprintf("[%s] [%s] %s (%s)", timestamp, level, message, strings.Join(parts, " "))
 	}
 
 	return fmt.Sprintf("[%s] [%s] %s", timestamp, level, message)
 }
 
+// Log enregistre un message avec des champs structurés au niveau demandé. Le message est aussi
+// conservé dans Recent.
+func (l *Logger) Log(level LogLevel, fields Fields, format string, args ...interface{}) {
+	if l.Enabled(level) {
+		message := fmt.Sprintf(format, args...)
+		Recent.add(level, fields, message)
+		l.logger.Println(l.formatEntry(level.String(), fields, "%s", message))
+	}
+}
+
+// Capture conserve un message dans Recent sans l'écrire, pour une sortie produite autrement
+// (affichage en couleur des décisions de trading)
+func (l *Logger) Capture(level LogLevel, fields Fields, format string, args ...interface{}) {
+	if l.Enabled(level) {
+		Recent.add(level, fields, fmt.Sprintf(format, args...))
+	}
+}
+
 // Debug enregistre un message de niveau debug
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.level <= LevelDebug {
-		l.logger.Println(l.formatMessage("DEBUG", format, args...))
-	}
+	l.Log(LevelDebug, nil, format, args...)
 }
 
 // Info enregistre un message de niveau info
 func (l *Logger) Info(format string, args ...interface{}) {
-	if l.level <= LevelInfo {
-		l.logger.Println(l.formatMessage("INFO", format, args...))
-	}
+	l.Log(LevelInfo, nil, format, args...)
 }
 
 // Warn enregistre un message de niveau warning
 func (l *Logger) Warn(format string, args ...interface{}) {
-	if l.level <= LevelWarn {
-		l.logger.Println(l.formatMessage("WARN", format, args...))
-	}
+	l.Log(LevelWarn, nil, format, args...)
 }
 
 // Error enregistre un message de niveau error
 func (l *Logger) Error(format string, args ...interface{}) {
-	if l.level <= LevelError {
-		l.logger.Println(l.formatMessage("ERROR", format, args...))
-	}
+	l.Log(LevelError, nil, format, args...)
 }
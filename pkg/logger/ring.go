@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRingSize est le nombre d'entrées conservées en mémoire par Recent
+const DefaultRingSize = 5000
+
+// Recent conserve les dernières entrées émises par tous les loggers du processus, pour
+// l'onglet Logs du tableau de bord
+var Recent = NewRingBuffer(DefaultRingSize)
+
+// Entry est une entrée du journal conservée en mémoire
+type Entry struct {
+	Seq     uint64    `json:"seq"` // numéro croissant, à repasser dans since pour ne lire que la suite
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Fields  Fields    `json:"fields,omitempty"`
+	Source  string    `json:"source,omitempty"` // fichier d'origine pour les lignes relues (FollowFile)
+}
+
+// RingBuffer conserve les dernières entrées du journal dans un tampon circulaire. Les écritures
+// ne prennent aucun verrou: un numéro de séquence atomique attribue sa case à chaque entrée, qui
+// y est publiée par un pointeur atomique. Les écrivains concurrents ne s'attendent donc jamais.
+type RingBuffer struct {
+	slots []atomic.Pointer[Entry]
+	next  atomic.Uint64
+}
+
+// NewRingBuffer crée un tampon conservant les size dernières entrées
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+	return &RingBuffer{slots: make([]atomic.Pointer[Entry], size)}
+}
+
+// Add ajoute une entrée, en écrasant la plus ancienne lorsque le tampon est plein
+func (r *RingBuffer) Add(entry Entry) {
+	entry.Seq = r.next.Add(1)
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	r.slots[(entry.Seq-1)%uint64(len(r.slots))].Store(&entry)
+}
+
+// add enregistre un message émis par un logger; les erreurs des champs sont converties en texte
+// pour rester sérialisables
+func (r *RingBuffer) add(level LogLevel, fields Fields, message string) {
+	var copied Fields
+	if len(fields) > 0 {
+		copied = make(Fields, len(fields))
+		for key, value := range fields {
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			copied[key] = value
+		}
+	}
+	r.Add(Entry{Level: level.String(), Message: message, Fields: copied})
+}
+
+// Entries retourne, dans l'ordre d'émission, les entrées de niveau au moins minLevel dont le
+// numéro de séquence dépasse afterSeq (0 = toutes)
+func (r *RingBuffer) Entries(minLevel LogLevel, afterSeq uint64) []Entry {
+	entries := make([]Entry, 0, len(r.slots))
+	for i := range r.slots {
+		entry := r.slots[i].Load()
+		if entry == nil || entry.Seq <= afterSeq || ParseLevel(entry.Level) < minLevel {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	return entries
+}
+
+// LastSeq retourne le numéro de séquence de la dernière entrée ajoutée
+func (r *RingBuffer) LastSeq() uint64 {
+	return r.next.Load()
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRingBufferConcurrentWriters(t *testing.T) {
+	ring := NewRingBuffer(100)
+
+	var wg sync.WaitGroup
+	for writer := 0; writer < 8; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				level := LevelInfo
+				if i%10 == 0 {
+					level = LevelWarn
+				}
+				ring.add(level, Fields{"writer": writer, "error": fmt.Errorf("échec %d", i)}, fmt.Sprintf("message %d", i))
+			}
+		}(writer)
+	}
+	wg.Wait()
+
+	// Seules les 100 dernières des 400 entrées sont conservées, dans l'ordre d'émission
+	entries := ring.Entries(LevelDebug, 0)
+	if len(entries) != 100 || ring.LastSeq() != 400 {
+		t.Fatalf("%d entrées conservées (dernière %d), attendu 100 (dernière 400)", len(entries), ring.LastSeq())
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Seq <= entries[i-1].Seq {
+			t.Fatalf("entrées non ordonnées: %d puis %d", entries[i-1].Seq, entries[i].Seq)
+		}
+	}
+	if _, ok := entries[0].Fields["error"].(string); !ok {
+		t.Errorf("erreur non convertie en texte: %#v", entries[0].Fields["error"])
+	}
+
+	// Lecture de la suite et filtre par niveau
+	if after := ring.Entries(LevelDebug, 390); len(after) != 10 || after[0].Seq != 391 {
+		t.Errorf("entrées après 390: %d", len(after))
+	}
+	for _, entry := range ring.Entries(LevelWarn, 0) {
+		if entry.Level != "WARN" {
+			t.Fatalf("entrée %s retournée pour le niveau warn", entry.Level)
+		}
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	text := ParseLine("[2025-02-01 10:00:00] [WARN] Tâche en échec (task=update)", Entry{})
+	if text.Level != "WARN" || text.Message != "Tâche en échec (task=update)" || text.Time.Hour() != 10 {
+		t.Errorf("ligne texte: %+v", text)
+	}
+
+	structured := ParseLine(`{"time":"2025-02-01 10:00:05","level":"ERROR","message":"Vente refusée","cycle_id":42}`, text)
+	if structured.Level != "ERROR" || structured.Message != "Vente refusée" || structured.Fields["cycle_id"] != float64(42) {
+		t.Errorf("ligne JSON: %+v", structured)
+	}
+
+	std := ParseLine("2025/02/01 10:00:10 Planificateur démarré avec succès", structured)
+	if std.Level != "INFO" || std.Message != "Planificateur démarré avec succès" {
+		t.Errorf("ligne du paquet log: %+v", std)
+	}
+
+	// La sortie d'une commande reprend l'heure et le niveau de la ligne précédente
+	output := ParseLine("Cycle 42: ordre de vente placé", text)
+	if output.Level != "WARN" || !output.Time.Equal(text.Time) {
+		t.Errorf("ligne de sortie: %+v", output)
+	}
+}